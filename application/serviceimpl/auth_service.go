@@ -2,13 +2,17 @@
 package serviceimpl
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5" // เปลี่ยนเป็น v5
 	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
@@ -16,25 +20,47 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// refreshTokenTTL อายุของ refresh token และ session (ต่ออายุทุกครั้งที่รีเฟรช)
+const refreshTokenTTL = time.Hour * 24 * 30
+
+// twoFactorChallengeTTL เวลาที่ให้ใส่โค้ด 2FA หลังจากรหัสผ่านถูกต้อง
+const twoFactorChallengeTTL = 5 * time.Minute
+
 type authService struct {
 	userRepo           repository.UserRepository
 	refreshTokenRepo   repository.RefreshTokenRepository
 	tokenBlacklistRepo repository.TokenBlacklistRepository
+	sessionRepo        repository.UserSessionRepository
+
+	// ใช้เมื่อพบการใช้ refresh token ซ้ำ (เพิกถอน session และส่ง security alert)
+	sessionService      service.SessionService
+	notificationService service.NotificationService
+
+	// nil = ไม่รองรับ 2FA (เข้าสู่ระบบด้วยรหัสผ่านอย่างเดียว)
+	twoFactorService service.TwoFactorService
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
 	tokenBlacklistRepo repository.TokenBlacklistRepository,
+	sessionRepo repository.UserSessionRepository,
+	sessionService service.SessionService,
+	notificationService service.NotificationService,
+	twoFactorService service.TwoFactorService,
 ) service.AuthService {
 	return &authService{
-		userRepo:           userRepo,
-		refreshTokenRepo:   refreshTokenRepo,
-		tokenBlacklistRepo: tokenBlacklistRepo,
+		userRepo:            userRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		tokenBlacklistRepo:  tokenBlacklistRepo,
+		sessionRepo:         sessionRepo,
+		sessionService:      sessionService,
+		notificationService: notificationService,
+		twoFactorService:    twoFactorService,
 	}
 }
 
-func (s *authService) Register(username, password, email, displayName string) (*models.User, string, string, error) {
+func (s *authService) Register(username, password, email, displayName string, device dto.DeviceInfo) (*models.User, string, string, error) {
 
 	// ตรวจสอบข้อมูลขั้นต่ำ
 	if username == "" || password == "" {
@@ -70,29 +96,16 @@ func (s *authService) Register(username, password, email, displayName string) (*
 		return nil, "", "", errors.New("failed to create user: " + err.Error())
 	}
 
-	// สร้าง tokens
-	accessToken, refreshToken, err := s.generateTokens(user.ID, username)
+	// สร้าง session ของอุปกรณ์นี้พร้อม tokens
+	accessToken, refreshToken, err := s.startSession(user, device)
 	if err != nil {
-		return nil, "", "", errors.New("failed to generate tokens: " + err.Error())
-	}
-
-	// บันทึก refresh token
-	refreshTokenModel := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		CreatedAt: time.Now(),
-		Revoked:   false,
-	}
-
-	if err := s.refreshTokenRepo.Create(refreshTokenModel); err != nil {
-		// อาจบันทึกข้อผิดพลาดที่นี่ แต่ยังคงดำเนินการต่อไป
+		return nil, "", "", err
 	}
 
 	return user, accessToken, refreshToken, nil
 }
 
-func (s *authService) Login(username, password string) (*models.User, string, string, error) {
+func (s *authService) Login(username, password string, device dto.DeviceInfo) (*models.User, string, string, error) {
 	// ตรวจสอบข้อมูลขั้นต่ำ
 	if username == "" || password == "" {
 		return nil, "", "", errors.New("username and password are required")
@@ -109,7 +122,67 @@ func (s *authService) Login(username, password string) (*models.User, string, st
 		return nil, "", "", errors.New("invalid username or password")
 	}
 
-	// อัปเดตเวลาใช้งานล่าสุด
+	// ผู้ใช้ที่เปิด 2FA ได้ challenge token แทน ต้องยืนยันโค้ดก่อนจึงจะได้ session
+	if s.twoFactorService != nil {
+		enabled, err := s.twoFactorService.IsEnabled(user.ID)
+		if err != nil {
+			return nil, "", "", errors.New("failed to check two-factor status: " + err.Error())
+		}
+		if enabled {
+			challenge, expiresAt, err := s.generateTwoFactorChallenge(user.ID, device)
+			if err != nil {
+				return nil, "", "", errors.New("failed to generate two-factor challenge: " + err.Error())
+			}
+			return nil, "", "", &service.TwoFactorRequiredError{ChallengeToken: challenge, ExpiresAt: expiresAt}
+		}
+	}
+
+	return s.completeLogin(user, device)
+}
+
+// VerifyTwoFactorLogin เข้าสู่ระบบขั้นที่สองด้วย challenge token และโค้ด 2FA
+// device_name/platform ที่ไม่ได้ส่งมาใช้ค่าที่ส่งมาตอนใส่รหัสผ่าน
+func (s *authService) VerifyTwoFactorLogin(challengeToken, code string, device dto.DeviceInfo) (*models.User, string, string, error) {
+	if s.twoFactorService == nil {
+		return nil, "", "", errors.New("two-factor authentication is not enabled")
+	}
+	if challengeToken == "" || code == "" {
+		return nil, "", "", errors.New("challenge token and code are required")
+	}
+
+	claims, err := parseTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return nil, "", "", errors.New("invalid or expired challenge")
+	}
+
+	userIDStr, _ := claims["sub"].(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, "", "", errors.New("invalid or expired challenge")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, "", "", errors.New("invalid or expired challenge")
+	}
+
+	if err := s.twoFactorService.VerifyCode(user.ID, code); err != nil {
+		return nil, "", "", err
+	}
+
+	if device.DeviceName == "" {
+		device.DeviceName, _ = claims["device_name"].(string)
+	}
+	if device.Platform == "" {
+		device.Platform, _ = claims["platform"].(string)
+	}
+
+	return s.completeLogin(user, device)
+}
+
+// completeLogin อัปเดตเวลาใช้งานล่าสุดและสร้าง session ใหม่ของอุปกรณ์นี้
+// (session ของอุปกรณ์อื่นยังใช้งานได้ตามเดิม)
+func (s *authService) completeLogin(user *models.User, device dto.DeviceInfo) (*models.User, string, string, error) {
 	now := time.Now()
 	user.LastActiveAt = &now
 	if err := s.userRepo.Update(user); err != nil {
@@ -117,42 +190,74 @@ func (s *authService) Login(username, password string) (*models.User, string, st
 		log.Printf("Failed to update last_active_at: %v", err)
 	}
 
-	// สร้าง tokens
-	accessToken, refreshToken, err := s.generateTokens(user.ID, username)
+	accessToken, refreshToken, err := s.startSession(user, device)
 	if err != nil {
-		return nil, "", "", errors.New("failed to generate tokens: " + err.Error())
+		return nil, "", "", err
 	}
 
-	// ยกเลิก refresh tokens เดิม
-	if err := s.refreshTokenRepo.RevokeByUserID(user.ID); err != nil {
-		// อาจบันทึกข้อผิดพลาดที่นี่ แต่ยังคงดำเนินการต่อไป
+	return user, accessToken, refreshToken, nil
+}
+
+// generateTwoFactorChallenge ออก token อายุสั้นที่ยืนยันว่ารหัสผ่านถูกต้องแล้ว
+// ใช้ sub แทน id เพื่อไม่ให้ middleware.Protected ยอมรับเป็น access token
+func (s *authService) generateTwoFactorChallenge(userID uuid.UUID, device dto.DeviceInfo) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(twoFactorChallengeTTL)
+
+	claims := jwt.MapClaims{
+		"sub":         userID.String(),
+		"type":        "2fa_challenge",
+		"device_name": device.DeviceName,
+		"platform":    device.Platform,
+		"jti":         uuid.New().String(),
+		"exp":         expiresAt.Unix(),
+		"iat":         now.Unix(),
 	}
 
-	// บันทึก refresh token ใหม่
-	refreshTokenModel := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		CreatedAt: time.Now(),
-		Revoked:   false,
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSigningKey())
+	if err != nil {
+		return "", time.Time{}, err
 	}
+	return token, expiresAt, nil
+}
 
-	if err := s.refreshTokenRepo.Create(refreshTokenModel); err != nil {
-		// อาจบันทึกข้อผิดพลาดที่นี่ แต่ยังคงดำเนินการต่อไป
+// parseTwoFactorChallenge ตรวจลายเซ็น วันหมดอายุ และชนิดของ challenge token
+func parseTwoFactorChallenge(tokenString string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"HS256"}))
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwtSigningKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid challenge token")
 	}
 
-	return user, accessToken, refreshToken, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "2fa_challenge" {
+		return nil, errors.New("invalid challenge token")
+	}
+	return claims, nil
 }
 
-func (s *authService) RefreshToken(refreshTokenStr string) (string, string, error) {
-	// ตรวจสอบ refresh token ในฐานข้อมูล
-	refreshTokenModel, err := s.refreshTokenRepo.FindByToken(refreshTokenStr)
+func (s *authService) RefreshToken(refreshTokenStr, ipAddress string) (string, string, error) {
+	now := time.Now()
+
+	// ตรวจสอบ refresh token ในฐานข้อมูล (เก็บเฉพาะ hash)
+	refreshTokenModel, err := s.refreshTokenRepo.FindByTokenHash(hashToken(refreshTokenStr))
 	if err != nil {
 		return "", "", errors.New("invalid refresh token")
 	}
 
+	// token ที่ถูกหมุนออกไปแล้วถูกนำกลับมาใช้ แปลว่า token อาจถูกขโมย
+	if refreshTokenModel.Revoked {
+		if refreshTokenModel.RotatedAt != nil {
+			s.handleRefreshTokenReuse(refreshTokenModel, ipAddress)
+			return "", "", errors.New("refresh token reuse detected")
+		}
+		return "", "", errors.New("invalid refresh token")
+	}
+
 	// ตรวจสอบว่า token หมดอายุหรือไม่
-	if refreshTokenModel.ExpiresAt.Before(time.Now()) {
+	if refreshTokenModel.ExpiresAt.Before(now) {
 		return "", "", errors.New("refresh token expired")
 	}
 
@@ -162,52 +267,211 @@ func (s *authService) RefreshToken(refreshTokenStr string) (string, string, erro
 		return "", "", errors.New("user not found")
 	}
 
-	// สร้าง tokens ใหม่
-	accessToken, newRefreshToken, err := s.generateTokens(user.ID, user.Username)
+	// ดึง session ของ token (token รุ่นเก่าที่ไม่มี session จะถูกย้ายเข้า session ใหม่)
+	var session *models.UserSession
+	if refreshTokenModel.SessionID != nil {
+		session, err = s.sessionRepo.FindByID(*refreshTokenModel.SessionID)
+		if err != nil || session == nil {
+			return "", "", errors.New("invalid refresh token")
+		}
+		if !session.IsActive(now) {
+			return "", "", errors.New("session has been revoked")
+		}
+	}
+
+	// ยกเลิก token เดิม ถ้ามี request อื่นหมุน token นี้ไปก่อนแล้วถือว่าเป็นการใช้ซ้ำ
+	rotated, err := s.refreshTokenRepo.MarkRotated(refreshTokenModel.ID, now)
 	if err != nil {
-		return "", "", errors.New("failed to generate tokens: " + err.Error())
+		return "", "", errors.New("failed to rotate refresh token: " + err.Error())
+	}
+	if !rotated {
+		s.handleRefreshTokenReuse(refreshTokenModel, ipAddress)
+		return "", "", errors.New("refresh token reuse detected")
 	}
 
-	// ยกเลิก refresh token เดิม
-	if err := s.refreshTokenRepo.RevokeByUserID(user.ID); err != nil {
-		// อาจบันทึกข้อผิดพลาดที่นี่ แต่ยังคงดำเนินการต่อไป
+	if session != nil {
+		if err := s.sessionRepo.Touch(session.ID, ipAddress, now, now.Add(refreshTokenTTL)); err != nil {
+			log.Printf("Failed to update session %s: %v", session.ID, err)
+		}
+	} else {
+		session, err = s.createSession(user.ID, dto.DeviceInfo{IPAddress: ipAddress})
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// สร้าง tokens ใหม่
+	accessToken, newRefreshToken, err := s.generateTokens(user.ID, user.Username, session.ID)
+	if err != nil {
+		return "", "", errors.New("failed to generate tokens: " + err.Error())
 	}
 
 	// อัปเดตเวลาใช้งานล่าสุด
-	now := time.Now()
 	user.LastActiveAt = &now
 	s.userRepo.Update(user)
 
-	// บันทึก refresh token ใหม่
-	newRefreshTokenModel := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     newRefreshToken,
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 30),
-		CreatedAt: time.Now(),
-		Revoked:   false,
+	// บันทึก refresh token ใหม่ใน family เดิม
+	if err := s.saveRefreshToken(user.ID, session.ID, refreshTokenModel.FamilyID, newRefreshToken); err != nil {
+		return "", "", err
 	}
 
-	if err := s.refreshTokenRepo.Create(newRefreshTokenModel); err != nil {
-		// อาจบันทึกข้อผิดพลาดที่นี่ แต่ยังคงดำเนินการต่อไป
+	return accessToken, newRefreshToken, nil
+}
+
+// handleRefreshTokenReuse ยกเลิก token ทั้ง family เพิกถอน session และแจ้งเตือนผู้ใช้
+// ทั้งผู้ใช้จริงและผู้ที่ขโมย token ต้องเข้าสู่ระบบใหม่
+func (s *authService) handleRefreshTokenReuse(token *models.RefreshToken, ipAddress string) {
+	log.Printf("Refresh token reuse detected: user=%s family=%s ip=%s", token.UserID, token.FamilyID, ipAddress)
+
+	if err := s.refreshTokenRepo.RevokeFamily(token.FamilyID); err != nil {
+		log.Printf("Failed to revoke refresh token family %s: %v", token.FamilyID, err)
 	}
 
-	return accessToken, newRefreshToken, nil
+	alert := map[string]interface{}{
+		"type":        "refresh_token_reuse",
+		"title":       "Suspicious sign-in activity",
+		"message":     "A previously used sign-in token was presented again. The affected device has been signed out.",
+		"ip_address":  ipAddress,
+		"detected_at": time.Now().Format(time.RFC3339),
+	}
+
+	if token.SessionID != nil {
+		alert["session_id"] = token.SessionID.String()
+
+		// RevokeSession ปิด WebSocket ของ session ด้วย (session ที่ถูกเพิกถอนไปแล้วจะได้ "session not found")
+		if s.sessionService != nil {
+			if err := s.sessionService.RevokeSession(token.UserID, *token.SessionID); err != nil && err.Error() != "session not found" {
+				log.Printf("Failed to revoke session %s: %v", *token.SessionID, err)
+			}
+		}
+	}
+
+	if s.notificationService != nil {
+		s.notificationService.SendAlert(token.UserID, alert)
+	}
 }
 
-func (s *authService) Logout(userID uuid.UUID) error {
-	return s.refreshTokenRepo.RevokeByUserID(userID)
+// Logout ออกจากระบบเฉพาะ session ปัจจุบัน (token รุ่นเก่าที่ไม่มี session จะยกเลิกทุก token ของผู้ใช้)
+func (s *authService) Logout(userID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return s.refreshTokenRepo.RevokeByUserID(userID)
+	}
+
+	// ใช้เส้นทางเดียวกับการเพิกถอน session: ยกเลิก refresh token และปิด WebSocket ของอุปกรณ์นี้
+	if s.sessionService != nil {
+		return s.sessionService.RevokeSession(userID, sessionID)
+	}
+
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil || session == nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID, time.Now()); err != nil {
+		return err
+	}
+	return s.refreshTokenRepo.RevokeBySessionID(sessionID)
 }
 
 func (s *authService) GetUserByID(userID uuid.UUID) (*models.User, error) {
 	return s.userRepo.FindByID(userID)
 }
 
-func (s *authService) generateTokens(userID uuid.UUID, username string) (string, string, error) {
+// startSession สร้าง session ของอุปกรณ์ใหม่ พร้อม access token และ refresh token ของ session นั้น
+func (s *authService) startSession(user *models.User, device dto.DeviceInfo) (string, string, error) {
+	session, err := s.createSession(user.ID, device)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, refreshToken, err := s.generateTokens(user.ID, user.Username, session.ID)
+	if err != nil {
+		return "", "", errors.New("failed to generate tokens: " + err.Error())
+	}
+
+	// การเข้าสู่ระบบแต่ละครั้งเริ่ม token family ใหม่
+	if err := s.saveRefreshToken(user.ID, session.ID, uuid.New(), refreshToken); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// createSession บันทึก session ของอุปกรณ์
+func (s *authService) createSession(userID uuid.UUID, device dto.DeviceInfo) (*models.UserSession, error) {
+	now := time.Now()
+
+	deviceName := strings.TrimSpace(device.DeviceName)
+	if deviceName == "" {
+		deviceName = "Unknown device"
+	}
+	if len(deviceName) > 100 {
+		deviceName = deviceName[:100]
+	}
+
+	session := &models.UserSession{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeviceName: deviceName,
+		Platform:   normalizePlatform(device.Platform),
+		IPAddress:  device.IPAddress,
+		UserAgent:  device.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(refreshTokenTTL),
+	}
+
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, errors.New("failed to create session: " + err.Error())
+	}
+
+	return session, nil
+}
+
+// saveRefreshToken บันทึก hash ของ refresh token ที่ผูกกับ session และ family
+func (s *authService) saveRefreshToken(userID, sessionID, familyID uuid.UUID, token string) error {
+	now := time.Now()
+	refreshTokenModel := &models.RefreshToken{
+		UserID:    userID,
+		SessionID: &sessionID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
+		Revoked:   false,
+	}
+
+	if err := s.refreshTokenRepo.Create(refreshTokenModel); err != nil {
+		return errors.New("failed to save refresh token: " + err.Error())
+	}
+	return nil
+}
+
+// hashToken SHA-256 (hex) ของ token ที่เก็บในฐานข้อมูล (refresh token, โค้ดในอีเมล)
+// token มีส่วนที่สุ่มมาแล้ว (jti / random bytes) จึงไม่ต้องใช้ salt หรือ bcrypt
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizePlatform แปลง platform ที่ client ส่งมาเป็นค่าที่รองรับ
+func normalizePlatform(platform string) string {
+	switch p := strings.ToLower(strings.TrimSpace(platform)); p {
+	case "ios", "android", "web", "desktop":
+		return p
+	default:
+		return "unknown"
+	}
+}
+
+func (s *authService) generateTokens(userID uuid.UUID, username string, sessionID uuid.UUID) (string, string, error) {
 	now := time.Now()
 
 	// สร้าง Access Token (อายุสั้น)
+	// sid ใช้ตรวจว่า session ยังไม่ถูกเพิกถอน และใช้ผูก WebSocket กับอุปกรณ์
 	accessTokenClaims := jwt.MapClaims{
 		"id":       userID,
+		"sid":      sessionID,
 		"username": username,
 		"type":     "access",
 		"exp":      now.Add(time.Hour * 24).Unix(), // หมดอายุใน 24 ชั่วโมง
@@ -218,25 +482,22 @@ func (s *authService) generateTokens(userID uuid.UUID, username string) (string,
 	// สร้าง Refresh Token (อายุยาว)
 	refreshTokenClaims := jwt.MapClaims{
 		"id":       userID,
+		"sid":      sessionID,
 		"username": username,
 		"type":     "refresh",
-		"exp":      now.Add(time.Hour * 24 * 30).Unix(), // หมดอายุใน 30 วัน
-		"iat":      now.Unix(),                          // เวลาที่ออกโทเคน
+		"jti":      uuid.New().String(),             // ทำให้ token ไม่ซ้ำกันแม้ออกในวินาทีเดียวกัน
+		"exp":      now.Add(refreshTokenTTL).Unix(), // หมดอายุใน 30 วัน
+		"iat":      now.Unix(),                      // เวลาที่ออกโทเคน
 	}
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
 
 	// เซ็น tokens
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default-jwt-secret-for-development-only"
-	}
-
-	accessTokenString, err := accessToken.SignedString([]byte(jwtSecret))
+	accessTokenString, err := accessToken.SignedString(jwtSigningKey())
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshTokenString, err := refreshToken.SignedString([]byte(jwtSecret))
+	refreshTokenString, err := refreshToken.SignedString(jwtSigningKey())
 	if err != nil {
 		return "", "", err
 	}
@@ -244,6 +505,15 @@ func (s *authService) generateTokens(userID uuid.UUID, username string) (string,
 	return accessTokenString, refreshTokenString, nil
 }
 
+// jwtSigningKey key สำหรับเซ็น JWT ทุกชนิดที่ออกโดย authService
+func jwtSigningKey() []byte {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "default-jwt-secret-for-development-only"
+	}
+	return []byte(jwtSecret)
+}
+
 // เพิ่มเมธอด BlacklistToken
 func (s *authService) BlacklistToken(userID uuid.UUID, token string) error {
 	// ตรวจสอบว่า token อยู่ใน blacklist แล้วหรือไม่
@@ -0,0 +1,144 @@
+// application/serviceimpl/auth_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+func TestRefreshToken_RotatesOnlyOwnSession(t *testing.T) {
+	f := newFixture(t)
+
+	user, _, phoneToken, err := f.authService.Register("alice", "secret123", "alice@example.com", "Alice", dto.DeviceInfo{DeviceName: "iPhone", Platform: "iOS"})
+	mustNoError(t, err)
+	_, _, laptopToken, err := f.authService.Login("alice", "secret123", dto.DeviceInfo{DeviceName: "MacBook", Platform: "desktop"})
+	mustNoError(t, err)
+
+	_, _, err = f.authService.RefreshToken(phoneToken, "10.0.0.1")
+	mustNoError(t, err)
+
+	// การรีเฟรชบนมือถือต้องไม่กระทบ token ของแล็ปท็อป
+	if _, _, err := f.authService.RefreshToken(laptopToken, "10.0.0.2"); err != nil {
+		t.Fatalf("laptop refresh token should stay valid: %v", err)
+	}
+
+	sessions, err := f.sessionService.ListSessions(user.ID, uuid.Nil)
+	mustNoError(t, err)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	for _, s := range sessions {
+		if s.DeviceName == "iPhone" && s.Platform != "ios" {
+			t.Fatalf("expected normalized platform ios, got %q", s.Platform)
+		}
+	}
+}
+
+func TestRevokeSession_BlocksRefreshAndDisconnects(t *testing.T) {
+	f := newFixture(t)
+
+	user, _, phoneToken, err := f.authService.Register("alice", "secret123", "alice@example.com", "Alice", dto.DeviceInfo{DeviceName: "iPhone"})
+	mustNoError(t, err)
+	_, _, laptopToken, err := f.authService.Login("alice", "secret123", dto.DeviceInfo{DeviceName: "MacBook"})
+	mustNoError(t, err)
+
+	sessions, err := f.sessionService.ListSessions(user.ID, uuid.Nil)
+	mustNoError(t, err)
+
+	var phoneSession uuid.UUID
+	for _, s := range sessions {
+		if s.DeviceName == "iPhone" {
+			phoneSession = s.ID
+		}
+	}
+	mustNoError(t, f.sessionService.RevokeSession(user.ID, phoneSession))
+
+	if _, _, err := f.authService.RefreshToken(phoneToken, ""); err == nil {
+		t.Fatal("expected refresh on revoked session to fail")
+	}
+	if active, _ := f.sessionService.IsSessionActive(phoneSession); active {
+		t.Fatal("expected revoked session to be inactive")
+	}
+	if _, _, err := f.authService.RefreshToken(laptopToken, ""); err != nil {
+		t.Fatalf("other session should stay valid: %v", err)
+	}
+
+	events := f.ws.EventsOfType("session.revoked")
+	if len(events) != 1 || events[0].Data.(map[string]interface{})["session_id"] != phoneSession.String() {
+		t.Fatalf("expected one session.revoked event for %s, got %+v", phoneSession, events)
+	}
+
+	// เพิกถอนซ้ำหรือเพิกถอน session ของคนอื่นไม่ได้
+	expectError(t, f.sessionService.RevokeSession(user.ID, phoneSession), "session not found")
+	bob := f.createUser("bob")
+	expectError(t, f.sessionService.RevokeSession(bob.ID, sessions[0].ID), "session not found")
+}
+
+func TestLogout_RevokesSessionAndDisconnects(t *testing.T) {
+	f := newFixture(t)
+
+	user, _, phoneToken, err := f.authService.Register("alice", "secret123", "alice@example.com", "Alice", dto.DeviceInfo{DeviceName: "iPhone"})
+	mustNoError(t, err)
+
+	sessions, err := f.sessionService.ListSessions(user.ID, uuid.Nil)
+	mustNoError(t, err)
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+
+	mustNoError(t, f.authService.Logout(user.ID, sessions[0].ID))
+
+	if _, _, err := f.authService.RefreshToken(phoneToken, ""); err == nil {
+		t.Fatal("expected refresh after logout to fail")
+	}
+	// WebSocket ของอุปกรณ์ที่ออกจากระบบถูกปิดเช่นเดียวกับการเพิกถอน session
+	if events := f.ws.EventsOfType("session.revoked"); len(events) != 1 {
+		t.Fatalf("expected one session.revoked event, got %+v", events)
+	}
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	f := newFixture(t)
+
+	user, _, stolenToken, err := f.authService.Register("alice", "secret123", "alice@example.com", "Alice", dto.DeviceInfo{DeviceName: "iPhone"})
+	mustNoError(t, err)
+	_, _, otherDeviceToken, err := f.authService.Login("alice", "secret123", dto.DeviceInfo{DeviceName: "MacBook"})
+	mustNoError(t, err)
+
+	_, rotatedToken, err := f.authService.RefreshToken(stolenToken, "10.0.0.1")
+	mustNoError(t, err)
+	if rotatedToken == stolenToken {
+		t.Fatal("expected a new refresh token after rotation")
+	}
+
+	// token ที่ถูกหมุนออกไปแล้วถูกนำกลับมาใช้
+	_, _, err = f.authService.RefreshToken(stolenToken, "203.0.113.9")
+	expectError(t, err, "refresh token reuse detected")
+
+	// token ล่าสุดของ family เดียวกันต้องใช้ไม่ได้ด้วย
+	_, _, err = f.authService.RefreshToken(rotatedToken, "10.0.0.1")
+	if err == nil {
+		t.Fatal("expected the whole token family to be revoked")
+	}
+
+	// อุปกรณ์อื่นไม่ถูกกระทบ
+	if _, _, err := f.authService.RefreshToken(otherDeviceToken, ""); err != nil {
+		t.Fatalf("other device should stay signed in: %v", err)
+	}
+
+	sessions, err := f.sessionService.ListSessions(user.ID, uuid.Nil)
+	mustNoError(t, err)
+	if len(sessions) != 1 || sessions[0].DeviceName != "MacBook" {
+		t.Fatalf("expected only the MacBook session to remain, got %+v", sessions)
+	}
+
+	alerts := f.ws.EventsOfType("alert")
+	if len(alerts) != 1 || alerts[0].Data.(map[string]interface{})["type"] != "refresh_token_reuse" {
+		t.Fatalf("expected one refresh_token_reuse alert, got %+v", alerts)
+	}
+	if len(f.ws.EventsOfType("session.revoked")) != 1 {
+		t.Fatal("expected the compromised session to be disconnected")
+	}
+}
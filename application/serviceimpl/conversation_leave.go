@@ -0,0 +1,100 @@
+// application/serviceimpl/conversation_leave.go
+package serviceimpl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+// leaveGroup ให้ผู้ใช้ออกจากกลุ่มจริง (ลบแถวสมาชิก) ข้อความเก่ายังอยู่ในกลุ่มตามเดิม
+//   - owner ที่ออกจะโอนความเป็นเจ้าของให้แอดมินที่อยู่นานที่สุด ถ้าไม่มีแอดมินให้สมาชิกที่อยู่นานที่สุด
+//   - ไม่มีสมาชิกเหลือหลังลบ กลุ่มจะถูกปิด (is_active = false)
+//   - บันทึกข้อความระบบ "X left the group" (และ "Y is now the group owner" เมื่อมีการโอน)
+func leaveGroup(
+	conversationRepo repository.ConversationRepository,
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+	conversationID, userID uuid.UUID,
+) (*dto.LeaveGroupResultDTO, error) {
+	// 1. ตรวจสอบการสนทนาและสมาชิก
+	conversation, err := conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return nil, errors.New("conversation not found")
+	}
+	if conversation.Type != "group" {
+		return nil, errors.New("only group conversations can be left")
+	}
+
+	// 2. โอน owner, ลบสมาชิก และปิดกลุ่มเมื่อไม่มีสมาชิกเหลือ ใน transaction เดียวที่ล็อกแถวสมาชิก
+	// การออกพร้อมกันหลายคนจึงไม่ทำให้กลุ่มไม่มี owner หรือถูกปิดทั้งที่ยังมีสมาชิก
+	newOwnerID, deactivated, err := conversationRepo.LeaveGroup(conversationID, userID, pickSuccessor)
+	if err != nil {
+		if err.Error() == "conversation member not found" {
+			return nil, errors.New("you are not a member of this conversation")
+		}
+		return nil, fmt.Errorf("failed to leave group: %w", err)
+	}
+
+	result := &dto.LeaveGroupResultDTO{
+		ConversationID:   conversationID,
+		UserID:           userID,
+		NewOwnerID:       newOwnerID,
+		GroupDeactivated: deactivated,
+	}
+
+	// 3. ข้อความระบบ
+	leaverName := memberName(userRepo, userID)
+	systemMessages := []string{leaverName + " left the group"}
+	if newOwnerID != nil {
+		systemMessages = append(systemMessages, memberName(userRepo, *newOwnerID)+" is now the group owner")
+	}
+	for _, content := range systemMessages {
+		now := time.Now()
+		message := &models.Message{
+			ID:             uuid.New(),
+			ConversationID: conversationID,
+			MessageType:    "system",
+			Content:        content,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := messageRepo.Create(message); err != nil {
+			fmt.Printf("Warning: Failed to create leave system message: %v\n", err)
+			continue
+		}
+		conversationRepo.UpdateLastMessage(conversationID, message.ID, content, now)
+	}
+
+	return result, nil
+}
+
+// pickSuccessor เลือก owner คนใหม่: แอดมินที่เข้าร่วมนานที่สุด ถ้าไม่มีแอดมินใช้สมาชิกที่เข้าร่วมนานที่สุด
+func pickSuccessor(members []*models.ConversationMember) *models.ConversationMember {
+	candidates := make([]*models.ConversationMember, len(members))
+	copy(candidates, members)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iAdmin := candidates[i].EffectiveRole() != models.RoleMember
+		jAdmin := candidates[j].EffectiveRole() != models.RoleMember
+		if iAdmin != jAdmin {
+			return iAdmin
+		}
+		return candidates[i].JoinedAt.Before(candidates[j].JoinedAt)
+	})
+	return candidates[0]
+}
+
+// memberName ชื่อที่แสดงของผู้ใช้สำหรับข้อความระบบ
+func memberName(userRepo repository.UserRepository, userID uuid.UUID) string {
+	user, err := userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return "Someone"
+	}
+	return displayNameOf(user)
+}
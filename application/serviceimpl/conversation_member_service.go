@@ -11,12 +11,14 @@ import (
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
 )
 
 type conversationMemberService struct {
 	conversationRepo repository.ConversationRepository
 	userRepo         repository.UserRepository
 	messageRepo      repository.MessageRepository
+	banRepo          repository.GroupBanRepository
 }
 
 // NewConversationMemberService สร้าง service ใหม่
@@ -24,11 +26,13 @@ func NewConversationMemberService(
 	conversationRepo repository.ConversationRepository,
 	userRepo repository.UserRepository,
 	messageRepo repository.MessageRepository,
+	banRepo repository.GroupBanRepository,
 ) service.ConversationMemberService {
 	return &conversationMemberService{
 		conversationRepo: conversationRepo,
 		userRepo:         userRepo,
 		messageRepo:      messageRepo,
+		banRepo:          banRepo,
 	}
 }
 
@@ -42,10 +46,6 @@ func (s *conversationMemberService) AddMember(userID, conversationID, newMemberI
 	if member == nil {
 		return nil, errors.New("you are not a member of this conversation")
 	}
-	if !member.IsAdmin {
-		return nil, errors.New("only admins can add members")
-	}
-
 	// 2. ตรวจสอบประเภทการสนทนาว่าเป็นกลุ่มหรือไม่
 	conversation, err := s.conversationRepo.GetByID(conversationID)
 	if err != nil {
@@ -54,6 +54,9 @@ func (s *conversationMemberService) AddMember(userID, conversationID, newMemberI
 	if conversation.Type == "direct" {
 		return nil, errors.New("cannot add members to direct conversation")
 	}
+	if !conversation.MemberCan(member, models.PermissionAddMember) {
+		return nil, permissionDenied(models.PermissionAddMember)
+	}
 
 	// 3. ตรวจสอบว่าผู้ใช้ที่จะเพิ่มมีอยู่จริงหรือไม่
 	user, err := s.userRepo.FindByID(newMemberID)
@@ -70,6 +73,15 @@ func (s *conversationMemberService) AddMember(userID, conversationID, newMemberI
 		return nil, errors.New("user is already a member of this conversation")
 	}
 
+	// 4.1 ผู้ใช้ที่ถูกแบนเพิ่มกลับเข้ากลุ่มไม่ได้จนกว่าจะปลดแบนหรือแบนหมดอายุ
+	ban, err := s.banRepo.FindActive(conversationID, newMemberID, time.Now())
+	if err != nil {
+		return nil, errors.New("error checking ban list: " + err.Error())
+	}
+	if ban != nil {
+		return nil, errors.New("user is banned from this group")
+	}
+
 	// 5. เพิ่มสมาชิกใหม่
 	now := time.Now()
 	newMember := &models.ConversationMember{
@@ -120,10 +132,6 @@ func (s *conversationMemberService) BulkAddMembers(userID, conversationID uuid.U
 	if member == nil {
 		return nil, nil, errors.New("you are not a member of this conversation")
 	}
-	if !member.IsAdmin {
-		return nil, nil, errors.New("only admins can add members")
-	}
-
 	// 2. ตรวจสอบประเภทการสนทนาว่าเป็นกลุ่มหรือไม่
 	conversation, err := s.conversationRepo.GetByID(conversationID)
 	if err != nil {
@@ -132,6 +140,9 @@ func (s *conversationMemberService) BulkAddMembers(userID, conversationID uuid.U
 	if conversation.Type == "direct" {
 		return nil, nil, errors.New("cannot add members to direct conversation")
 	}
+	if !conversation.MemberCan(member, models.PermissionAddMember) {
+		return nil, nil, permissionDenied(models.PermissionAddMember)
+	}
 
 	// 3. เพิ่มสมาชิกทีละคน
 	addedMembers = []*dto.MemberDTO{}
@@ -172,6 +183,20 @@ func (s *conversationMemberService) BulkAddMembers(userID, conversationID uuid.U
 			continue
 		}
 
+		// ผู้ใช้ที่ถูกแบนเพิ่มกลับเข้ากลุ่มไม่ได้
+		ban, err := s.banRepo.FindActive(conversationID, newMemberID, now)
+		if err != nil || ban != nil {
+			reason := "banned from this group"
+			if err != nil {
+				reason = "error checking ban list"
+			}
+			failed = append(failed, struct {
+				UserID uuid.UUID
+				Reason string
+			}{UserID: newMemberID, Reason: reason})
+			continue
+		}
+
 		// เพิ่มสมาชิกใหม่
 		newMember := &models.ConversationMember{
 			ID:             uuid.New(),
@@ -294,25 +319,19 @@ func (s *conversationMemberService) RemoveMember(userID, conversationID, memberT
 		return errors.New("cannot remove members from direct conversation")
 	}
 
-	// 2. กำหนดสิทธิ์การลบสมาชิก
+	// 2. ผู้ใช้ต้องการออกด้วยตัวเอง - ใช้ขั้นตอนออกจากกลุ่ม (โอน owner ถ้าจำเป็น)
 	if userID == memberToRemoveID {
-		// ผู้ใช้ต้องการออกด้วยตัวเอง - ต้องเป็นสมาชิก
-		isMember, err := s.conversationRepo.IsMember(conversationID, userID)
-		if err != nil {
-			return errors.New("error checking membership: " + err.Error())
-		}
-		if !isMember {
-			return errors.New("you are not a member of this conversation")
-		}
-	} else {
-		// ผู้ใช้ต้องการลบผู้อื่น - ต้องเป็นแอดมิน
-		member, err := s.conversationRepo.GetMember(conversationID, userID)
-		if err != nil {
-			return errors.New("error checking admin status: " + err.Error())
-		}
-		if member == nil || !member.IsAdmin {
-			return errors.New("only admins can remove other members")
-		}
+		_, err := s.LeaveGroup(conversationID, userID)
+		return err
+	}
+
+	// ผู้ใช้ต้องการลบผู้อื่น - ต้องเป็นแอดมิน
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil {
+		return errors.New("error checking admin status: " + err.Error())
+	}
+	if member == nil || !member.IsAdmin {
+		return errors.New("only admins can remove other members")
 	}
 
 	// 3. ตรวจสอบว่าเป้าหมายเป็นสมาชิกอยู่จริง
@@ -322,7 +341,7 @@ func (s *conversationMemberService) RemoveMember(userID, conversationID, memberT
 	}
 
 	// 4. ตรวจสอบกรณีลบแอดมินคนสุดท้าย
-	if targetMember.IsAdmin && userID != memberToRemoveID {
+	if targetMember.IsAdmin {
 		// นับจำนวนแอดมินในการสนทนา
 		members, err := s.conversationRepo.GetMembers(conversationID)
 		if err != nil {
@@ -348,15 +367,9 @@ func (s *conversationMemberService) RemoveMember(userID, conversationID, memberT
 
 	// 6. สร้างข้อความระบบ
 	now := time.Now()
-	var systemMessage string
-	if userID == memberToRemoveID {
-		userName, _ := s.getUserName(userID)
-		systemMessage = userName + " left the group"
-	} else {
-		removerName, _ := s.getUserName(userID)
-		removedName, _ := s.getUserName(memberToRemoveID)
-		systemMessage = removerName + " removed " + removedName + " from the group"
-	}
+	removerName, _ := s.getUserName(userID)
+	removedName, _ := s.getUserName(memberToRemoveID)
+	systemMessage := removerName + " removed " + removedName + " from the group"
 
 	msgID, _ := s.createSystemMessage(conversationID, systemMessage)
 	s.conversationRepo.UpdateLastMessage(conversationID, msgID, systemMessage, now)
@@ -548,38 +561,127 @@ func (s *conversationMemberService) ChangeRole(conversationID, userID uuid.UUID,
 	return member, nil
 }
 
-// HasPermission ตรวจสอบว่าผู้ใช้มีสิทธิ์ทำอะไรใน conversation หรือไม่
+// LeaveGroup ออกจากกลุ่ม (owner โอนความเป็นเจ้าของอัตโนมัติ, คนสุดท้ายออกแล้วกลุ่มถูกปิด)
+func (s *conversationMemberService) LeaveGroup(conversationID, userID uuid.UUID) (*dto.LeaveGroupResultDTO, error) {
+	return leaveGroup(s.conversationRepo, s.userRepo, s.messageRepo, conversationID, userID)
+}
+
+// HasPermission ตรวจสอบว่าผู้ใช้มีสิทธิ์ทำอะไรใน conversation หรือไม่ (ตาม permission policy ของกลุ่ม)
 func (s *conversationMemberService) HasPermission(conversationID, userID uuid.UUID, permission service.Permission) (bool, error) {
-	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if _, ok := permissionActions[permission]; !ok {
+		return false, errors.New("unknown permission")
+	}
+
+	conversation, member, err := s.getConversationAndMember(conversationID, userID)
 	if err != nil {
-		return false, fmt.Errorf("failed to get member: %w", err)
+		return false, err
 	}
-	if member == nil {
-		return false, errors.New("user is not a member of this conversation")
+
+	return conversation.MemberCan(member, permission), nil
+}
+
+// CheckSendAllowed ตรวจว่าผู้ใช้ส่งข้อความประเภทนี้ได้ตอนนี้หรือไม่ (สิทธิ์, การถูกจำกัดการส่ง, slow mode)
+// ใช้ก่อนส่งต่อข้อความที่ไม่ได้บันทึก ผ่านแล้วจึงนับเป็นการส่งหนึ่งครั้งของ slow mode
+func (s *conversationMemberService) CheckSendAllowed(conversationID, userID uuid.UUID, messageType string) error {
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, messageType)
+	if err != nil {
+		return err
+	}
+	return claimSendSlot(s.conversationRepo, conversationID, userID, slowMode)
+}
+
+// GetPermissionPolicy ดึง permission policy ของกลุ่ม พร้อมสิทธิ์ของผู้ใช้เอง
+func (s *conversationMemberService) GetPermissionPolicy(conversationID, userID uuid.UUID) (*dto.PermissionPolicyDTO, error) {
+	conversation, member, err := s.getConversationAndMember(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Type != "group" {
+		return nil, errors.New("permissions are only available for group conversations")
+	}
+
+	return buildPermissionPolicyDTO(conversation, member), nil
+}
+
+// UpdatePermissionPolicy เปลี่ยน role ขั้นต่ำของสิทธิ์ที่ระบุ (owner เท่านั้น)
+// คืนค่า policy ใหม่ และ role เดิมของสิทธิ์ที่เปลี่ยนจริง (permission -> role เดิม)
+func (s *conversationMemberService) UpdatePermissionPolicy(conversationID, userID uuid.UUID, input *dto.UpdatePermissionPolicyRequest) (*dto.PermissionPolicyDTO, map[string]string, error) {
+	if len(input.Permissions) == 0 {
+		return nil, nil, errors.New("no permissions to update")
+	}
+
+	conversation, member, err := s.getConversationAndMember(conversationID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if conversation.Type != "group" {
+		return nil, nil, errors.New("permissions are only available for group conversations")
 	}
+	if !conversation.MemberCan(member, models.PermissionManagePermissions) {
+		return nil, nil, permissionDenied(models.PermissionManagePermissions)
+	}
+
+	policy := conversation.GetPermissionPolicy()
+	oldValues := make(map[string]string)
+	for key, role := range input.Permissions {
+		permission := models.Permission(key)
+		oldRole := policy[permission]
+		if oldRole == models.MemberRole(role) {
+			continue
+		}
+		if err := policy.Set(permission, models.MemberRole(role)); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		oldValues[key] = string(oldRole)
+	}
+
+	if len(oldValues) > 0 {
+		permissions := policy.ToJSONB()
+		if err := s.conversationRepo.UpdateConversation(conversationID, types.JSONB{
+			"permissions": permissions,
+			"updated_at":  time.Now(),
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to update permissions: %w", err)
+		}
+		conversation.Permissions = permissions
+	}
+
+	return buildPermissionPolicyDTO(conversation, member), oldValues, nil
+}
 
-	switch permission {
-	case service.PermissionAddMember:
-		// Owner และ Admin เท่านั้น
-		return member.Role == models.RoleOwner || member.Role == models.RoleAdmin, nil
+// getConversationAndMember ดึงการสนทนาและข้อมูลสมาชิกของผู้ใช้
+func (s *conversationMemberService) getConversationAndMember(conversationID, userID uuid.UUID) (*models.Conversation, *models.ConversationMember, error) {
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return nil, nil, errors.New("conversation not found")
+	}
+
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil || member == nil {
+		return nil, nil, errors.New("user is not a member of this conversation")
+	}
 
-	case service.PermissionRemoveMember:
-		// Owner และ Admin เท่านั้น
-		return member.Role == models.RoleOwner || member.Role == models.RoleAdmin, nil
+	return conversation, member, nil
+}
 
-	case service.PermissionChangeRole:
-		// Owner เท่านั้น
-		return member.Role == models.RoleOwner, nil
+// buildPermissionPolicyDTO แปลง policy ของกลุ่มเป็น DTO พร้อมสิทธิ์ของสมาชิกที่ดึงข้อมูล
+func buildPermissionPolicyDTO(conversation *models.Conversation, member *models.ConversationMember) *dto.PermissionPolicyDTO {
+	policy := conversation.GetPermissionPolicy()
 
-	case service.PermissionUpdateInfo:
-		// Owner และ Admin เท่านั้น
-		return member.Role == models.RoleOwner || member.Role == models.RoleAdmin, nil
+	permissions := make(map[string]string, len(models.ConfigurablePermissions))
+	for _, permission := range models.ConfigurablePermissions {
+		permissions[string(permission)] = string(policy[permission])
+	}
 
-	case service.PermissionDeleteGroup:
-		// Owner เท่านั้น
-		return member.Role == models.RoleOwner, nil
+	myPermissions := make(map[string]bool, len(permissionActions))
+	for permission := range permissionActions {
+		myPermissions[string(permission)] = conversation.MemberCan(member, permission)
+	}
 
-	default:
-		return false, errors.New("unknown permission")
+	return &dto.PermissionPolicyDTO{
+		ConversationID: conversation.ID,
+		Permissions:    permissions,
+		MyRole:         string(member.EffectiveRole()),
+		MyPermissions:  myPermissions,
 	}
 }
@@ -0,0 +1,324 @@
+// application/serviceimpl/conversation_member_service_test.go
+package serviceimpl_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+func TestAddMember(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob)
+
+	_, err := f.memberService.AddMember(bob.ID, group.ID, carol.ID)
+	expectError(t, err, "you do not have permission to add members")
+
+	_, err = f.memberService.AddMember(dave.ID, group.ID, carol.ID)
+	expectError(t, err, "error checking membership")
+
+	member, err := f.memberService.AddMember(alice.ID, group.ID, carol.ID)
+	mustNoError(t, err)
+	if member.UserID != carol.ID.String() {
+		t.Fatalf("unexpected member: %+v", member)
+	}
+
+	isMember, err := f.conversationRepo.IsMember(group.ID, carol.ID)
+	mustNoError(t, err)
+	if !isMember {
+		t.Fatal("carol should be a member")
+	}
+
+	// ข้อความระบบแจ้งการเพิ่มสมาชิกกลายเป็นข้อความล่าสุด
+	last, err := f.messageRepo.GetLastMessageByConversation(group.ID)
+	mustNoError(t, err)
+	if last == nil || last.SenderID != nil || last.MessageType != "system" {
+		t.Fatalf("expected a system message, got %+v", last)
+	}
+
+	_, err = f.memberService.AddMember(alice.ID, group.ID, carol.ID)
+	expectError(t, err, "user is already a member of this conversation")
+
+	direct := f.createConversation("direct", alice, bob)
+	_, err = f.memberService.AddMember(alice.ID, direct.ID, carol.ID)
+	expectError(t, err, "cannot add members to direct conversation")
+}
+
+func TestRemoveMember(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	group := f.createConversation("group", alice, bob, carol)
+
+	err := f.memberService.RemoveMember(bob.ID, group.ID, carol.ID)
+	expectError(t, err, "only admins can remove other members")
+
+	// สมาชิกออกจากกลุ่มเองได้
+	mustNoError(t, f.memberService.RemoveMember(bob.ID, group.ID, bob.ID))
+
+	mustNoError(t, f.memberService.RemoveMember(alice.ID, group.ID, carol.ID))
+	isMember, err := f.conversationRepo.IsMember(group.ID, carol.ID)
+	mustNoError(t, err)
+	if isMember {
+		t.Fatal("carol should have been removed")
+	}
+
+	err = f.memberService.RemoveMember(alice.ID, group.ID, carol.ID)
+	expectError(t, err, "user is not a member of this conversation")
+}
+
+func TestToggleAdminStatus(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	group := f.createConversation("group", alice, bob)
+
+	_, err := f.memberService.ToggleAdminStatus(bob.ID, group.ID, bob.ID, true)
+	expectError(t, err, "only admins can change admin status")
+
+	_, err = f.memberService.ToggleAdminStatus(alice.ID, group.ID, alice.ID, false)
+	expectError(t, err, "cannot remove admin status from the last admin")
+
+	isAdmin, err := f.memberService.ToggleAdminStatus(alice.ID, group.ID, bob.ID, true)
+	mustNoError(t, err)
+	if !isAdmin || !f.member(group.ID, bob.ID).IsAdmin {
+		t.Fatal("bob should be an admin")
+	}
+
+	// มี admin สองคนแล้ว จึงถอด admin ของตัวเองได้
+	_, err = f.memberService.ToggleAdminStatus(bob.ID, group.ID, alice.ID, false)
+	mustNoError(t, err)
+	if f.member(group.ID, alice.ID).IsAdmin {
+		t.Fatal("alice should no longer be an admin")
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	group := f.createConversation("group", alice, bob, carol)
+
+	member, err := f.memberService.ChangeRole(group.ID, bob.ID, models.RoleAdmin)
+	mustNoError(t, err)
+	if !member.IsAdmin || !f.member(group.ID, bob.ID).IsAdmin {
+		t.Fatal("changing role to admin should sync is_admin")
+	}
+
+	cases := []struct {
+		user       string
+		permission service.Permission
+		want       bool
+	}{
+		{"alice", service.PermissionDeleteGroup, true},
+		{"alice", service.PermissionChangeRole, true},
+		{"bob", service.PermissionAddMember, true},
+		{"bob", service.PermissionRemoveMember, true},
+		{"bob", service.PermissionUpdateInfo, true},
+		{"bob", service.PermissionChangeRole, false},
+		{"bob", service.PermissionDeleteGroup, false},
+		{"carol", service.PermissionAddMember, false},
+		{"carol", service.PermissionUpdateInfo, false},
+	}
+	users := map[string]*models.User{"alice": alice, "bob": bob, "carol": carol}
+
+	for _, tc := range cases {
+		got, err := f.memberService.HasPermission(group.ID, users[tc.user].ID, tc.permission)
+		mustNoError(t, err)
+		if got != tc.want {
+			t.Errorf("%s %s: expected %v, got %v", tc.user, tc.permission, tc.want, got)
+		}
+	}
+
+	_, err = f.memberService.HasPermission(group.ID, alice.ID, service.Permission("launch_rockets"))
+	expectError(t, err, "unknown permission")
+}
+
+func TestPermissionPolicyIsEnforcedOnSendAndPin(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	group := f.createConversation("group", alice, bob, carol)
+
+	// ค่าเริ่มต้น: สมาชิกส่งข้อความได้ แต่ปักหมุดไม่ได้
+	message := f.sendText(group.ID, bob.ID, "hello")
+	err := f.messageService.PinMessage(message.ID, group.ID, bob.ID)
+	var permErr *service.PermissionError
+	if !errors.As(err, &permErr) || permErr.Permission != service.PermissionPinMessage {
+		t.Fatalf("expected pin_message permission error, got %v", err)
+	}
+	mustNoError(t, f.messageService.PinMessage(message.ID, group.ID, alice.ID))
+
+	// เฉพาะ owner ตั้งค่าสิทธิ์ได้
+	_, _, err = f.memberService.UpdatePermissionPolicy(group.ID, bob.ID, &dto.UpdatePermissionPolicyRequest{
+		Permissions: map[string]string{"send_message": "admin"},
+	})
+	expectError(t, err, "you do not have permission to change group permissions")
+
+	_, _, err = f.memberService.UpdatePermissionPolicy(group.ID, alice.ID, &dto.UpdatePermissionPolicyRequest{
+		Permissions: map[string]string{"delete_group": "member"},
+	})
+	expectError(t, err, "permission is not configurable")
+
+	_, _, err = f.memberService.UpdatePermissionPolicy(group.ID, alice.ID, &dto.UpdatePermissionPolicyRequest{
+		Permissions: map[string]string{"send_media": "superuser"},
+	})
+	expectError(t, err, "invalid role")
+
+	// ส่งไฟล์ได้เฉพาะแอดมิน, สมาชิกเพิ่มคนได้
+	policy, oldValues, err := f.memberService.UpdatePermissionPolicy(group.ID, alice.ID, &dto.UpdatePermissionPolicyRequest{
+		Permissions: map[string]string{"send_media": "admin", "add_member": "member", "pin_message": "admin"},
+	})
+	mustNoError(t, err)
+	if len(oldValues) != 2 || oldValues["send_media"] != "member" || oldValues["add_member"] != "admin" {
+		t.Fatalf("expected only changed permissions with old roles, got %+v", oldValues)
+	}
+	if policy.Permissions["send_media"] != "admin" || policy.MyRole != "owner" || !policy.MyPermissions["manage_permissions"] {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+
+	f.sendText(group.ID, bob.ID, "text is still fine")
+	_, err = f.messageService.SendFileMessage(group.ID, bob.ID, "https://cdn.example.com/a.pdf", "a.pdf", 10, "application/pdf", nil)
+	expectError(t, err, "you do not have permission to send media")
+
+	dave := f.createUser("dave")
+	_, err = f.memberService.AddMember(bob.ID, group.ID, dave.ID)
+	mustNoError(t, err)
+
+	// กลุ่มประกาศ: ส่งข้อความได้เฉพาะแอดมิน
+	_, _, err = f.memberService.UpdatePermissionPolicy(group.ID, alice.ID, &dto.UpdatePermissionPolicyRequest{
+		Permissions: map[string]string{"send_message": "admin"},
+	})
+	mustNoError(t, err)
+
+	_, err = f.messageService.SendTextMessage(group.ID, carol.ID, "can I talk?", nil)
+	expectError(t, err, "you do not have permission to send messages")
+	_, err = f.messageService.ReplyToMessage(message.ID, carol.ID, "text", "me too", "", "", nil)
+	expectError(t, err, "you do not have permission to send messages")
+	f.sendText(group.ID, alice.ID, "announcement")
+
+	policy, err = f.memberService.GetPermissionPolicy(group.ID, carol.ID)
+	mustNoError(t, err)
+	if policy.MyPermissions["send_message"] || !policy.MyPermissions["add_member"] {
+		t.Fatalf("unexpected member permissions %+v", policy.MyPermissions)
+	}
+}
+
+func TestLeaveGroupHandsOffOwnershipAndDeactivatesWhenEmpty(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob, carol, dave)
+
+	// bob อยู่นานกว่า carol แต่ carol เป็นแอดมิน
+	joined := time.Now().Add(-time.Hour)
+	for i, user := range []*models.User{bob, carol, dave} {
+		member := f.member(group.ID, user.ID)
+		member.JoinedAt = joined.Add(time.Duration(i) * time.Minute)
+		mustNoError(t, f.conversationRepo.UpdateMember(member))
+	}
+	_, err := f.memberService.ChangeRole(group.ID, carol.ID, models.RoleAdmin)
+	mustNoError(t, err)
+
+	history := f.sendText(group.ID, alice.ID, "bye everyone")
+
+	// owner ออก: โอนให้แอดมินที่อยู่นานที่สุด
+	action, err := f.conversationService.DeleteConversation(group.ID, alice.ID)
+	mustNoError(t, err)
+	if action != "left" {
+		t.Fatalf("expected group delete to leave, got %q", action)
+	}
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, alice.ID); isMember {
+		t.Fatal("alice should no longer be a member")
+	}
+	if f.member(group.ID, carol.ID).Role != models.RoleOwner {
+		t.Fatal("expected carol (admin) to become owner")
+	}
+	if _, err := f.messageRepo.GetByID(history.ID); err != nil {
+		t.Fatalf("messages of a leaving member should be kept: %v", err)
+	}
+	conversation, err := f.conversationRepo.GetByID(group.ID)
+	mustNoError(t, err)
+	if *conversation.CreatorID != carol.ID || conversation.LastMessageText != "Carol is now the group owner" {
+		t.Fatalf("unexpected conversation after hand-off: creator=%v last=%q", conversation.CreatorID, conversation.LastMessageText)
+	}
+
+	// ไม่มีแอดมิน: โอนให้สมาชิกที่อยู่นานที่สุด
+	result, err := f.memberService.LeaveGroup(group.ID, carol.ID)
+	mustNoError(t, err)
+	if result.NewOwnerID == nil || *result.NewOwnerID != bob.ID {
+		t.Fatalf("expected bob to become owner, got %+v", result)
+	}
+
+	// สมาชิกธรรมดาออก: ไม่มีการโอน
+	result, err = f.memberService.LeaveGroup(group.ID, dave.ID)
+	mustNoError(t, err)
+	if result.NewOwnerID != nil || result.GroupDeactivated {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// คนสุดท้ายออก: กลุ่มถูกปิด
+	mustNoError(t, f.memberService.RemoveMember(bob.ID, group.ID, bob.ID))
+	conversation, err = f.conversationRepo.GetByID(group.ID)
+	mustNoError(t, err)
+	if conversation.IsActive {
+		t.Fatal("group should be deactivated after the last member leaves")
+	}
+
+	_, err = f.memberService.LeaveGroup(group.ID, bob.ID)
+	expectError(t, err, "you are not a member of this conversation")
+}
+
+func TestLeaveGroupConcurrentlyKeepsOneOwnerAndDeactivatesOnce(t *testing.T) {
+	f := newFixture(t)
+	users := []*models.User{f.createUser("alice"), f.createUser("bob"), f.createUser("carol"), f.createUser("dave")}
+	group := f.createConversation("group", users...)
+
+	results := make(chan *dto.LeaveGroupResultDTO, len(users))
+	var wg sync.WaitGroup
+	for _, user := range users {
+		wg.Add(1)
+		go func(userID uuid.UUID) {
+			defer wg.Done()
+			result, err := f.memberService.LeaveGroup(group.ID, userID)
+			if err != nil {
+				t.Errorf("leave failed: %v", err)
+				return
+			}
+			results <- result
+		}(user.ID)
+	}
+	wg.Wait()
+	close(results)
+
+	deactivated := 0
+	for result := range results {
+		if result.GroupDeactivated {
+			deactivated++
+		}
+	}
+	if deactivated != 1 {
+		t.Fatalf("expected exactly one leave to deactivate the group, got %d", deactivated)
+	}
+
+	conversation, err := f.conversationRepo.GetByID(group.ID)
+	mustNoError(t, err)
+	if conversation.IsActive {
+		t.Fatal("group should be deactivated once everyone has left")
+	}
+}
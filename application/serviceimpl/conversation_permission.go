@@ -0,0 +1,165 @@
+// application/serviceimpl/conversation_permission.go
+package serviceimpl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// permissionActions คำอธิบายการกระทำสำหรับข้อความ error ของแต่ละสิทธิ์
+var permissionActions = map[models.Permission]string{
+	models.PermissionAddMember:         "add members",
+	models.PermissionRemoveMember:      "remove members",
+	models.PermissionChangeRole:        "change member roles",
+	models.PermissionUpdateInfo:        "change group info",
+	models.PermissionDeleteGroup:       "delete the group",
+	models.PermissionManagePermissions: "change group permissions",
+	models.PermissionSendMessage:       "send messages",
+	models.PermissionSendMedia:         "send media",
+	models.PermissionPinMessage:        "pin messages",
+	models.PermissionInvite:            "invite members",
+	models.PermissionModerateMembers:   "moderate members",
+}
+
+// permissionDenied สร้าง service.PermissionError ของสิทธิ์ที่ระบุ
+func permissionDenied(permission models.Permission) error {
+	return &service.PermissionError{
+		Permission: permission,
+		Message:    fmt.Sprintf("you do not have permission to %s in this conversation", permissionActions[permission]),
+	}
+}
+
+// requirePermission ตรวจสิทธิ์ของผู้ใช้ตาม permission policy ของการสนทนา (ต้องผ่านทุกสิทธิ์ที่ระบุ)
+func requirePermission(conversationRepo repository.ConversationRepository, conversationID, userID uuid.UUID, permissions ...models.Permission) error {
+	_, _, err := checkPermission(conversationRepo, conversationID, userID, permissions...)
+	return err
+}
+
+// checkPermission เหมือน requirePermission แต่คืนค่าการสนทนาและสมาชิกที่โหลดมาด้วย
+func checkPermission(conversationRepo repository.ConversationRepository, conversationID, userID uuid.UUID, permissions ...models.Permission) (*models.Conversation, *models.ConversationMember, error) {
+	conversation, err := conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return nil, nil, errors.New("conversation not found")
+	}
+
+	member, err := conversationRepo.GetMember(conversationID, userID)
+	if err != nil || member == nil {
+		return nil, nil, errors.New("user is not a member of this conversation")
+	}
+
+	for _, permission := range permissions {
+		if !conversation.MemberCan(member, permission) {
+			return nil, nil, permissionDenied(permission)
+		}
+	}
+	return conversation, member, nil
+}
+
+// requireSendPermission ตรวจสิทธิ์ส่งข้อความ (และสิทธิ์ส่งไฟล์สำหรับข้อความประเภท media)
+// จากนั้นตรวจการถูกจำกัดการส่งและ slow mode ของกลุ่ม โดยยังไม่นับเป็นการส่ง
+// คืนช่วง slow mode ของผู้ส่ง (0 = ไม่จำกัด) ให้ส่งต่อไปยัง createSentMessages ซึ่งจอง slot ตอนสร้างข้อความ
+func requireSendPermission(conversationRepo repository.ConversationRepository, conversationID, userID uuid.UUID, messageType string) (time.Duration, error) {
+	permissions := []models.Permission{models.PermissionSendMessage}
+	if isMediaMessageType(messageType) {
+		permissions = append(permissions, models.PermissionSendMedia)
+	}
+
+	conversation, member, err := checkPermission(conversationRepo, conversationID, userID, permissions...)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if member.SendRestrictedUntil != nil && member.SendRestrictedUntil.After(now) {
+		return 0, &service.SendRestrictionError{
+			Reason:  service.SendRestrictionRestricted,
+			Until:   *member.SendRestrictedUntil,
+			Message: "you are restricted from sending messages in this conversation until " + member.SendRestrictedUntil.UTC().Format(time.RFC3339),
+		}
+	}
+
+	// slow mode ใช้กับกลุ่มเท่านั้น ผู้ที่มีสิทธิ์ moderate_members ไม่ถูกจำกัด
+	if conversation.Type != "group" || conversation.SlowModeSeconds <= 0 || conversation.MemberCan(member, models.PermissionModerateMembers) {
+		return 0, nil
+	}
+
+	interval := time.Duration(conversation.SlowModeSeconds) * time.Second
+	if member.LastSentAt != nil && member.LastSentAt.After(now.Add(-interval)) {
+		return 0, slowModeError(member.LastSentAt.Add(interval), now)
+	}
+	return interval, nil
+}
+
+// createSentMessages บันทึกข้อความที่ผู้ใช้ส่ง (หลายข้อความในการสนทนาเดียวกันนับเป็นการส่งครั้งเดียว)
+// ถ้ามี slow mode จะจอง slot ใน transaction เดียวกับการสร้างข้อความ สร้างไม่สำเร็จจึงไม่เสีย slot
+func createSentMessages(messageRepo repository.MessageRepository, conversationRepo repository.ConversationRepository, userID uuid.UUID, slowMode time.Duration, messages ...*models.Message) error {
+	if slowMode <= 0 {
+		return messageRepo.BulkCreate(messages)
+	}
+
+	now := time.Now()
+	claimed, err := messageRepo.CreateWithSlowMode(messages, userID, slowMode, now)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return slowModeRejection(conversationRepo, messages[0].ConversationID, userID, now, slowMode)
+	}
+	return nil
+}
+
+// claimSendSlot นับการส่งที่ไม่ได้บันทึกข้อความ (เช่นส่งต่อผ่าน WebSocket) กับ slow mode
+func claimSendSlot(conversationRepo repository.ConversationRepository, conversationID, userID uuid.UUID, slowMode time.Duration) error {
+	if slowMode <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	claimed, err := conversationRepo.ClaimSlowModeSlot(conversationID, userID, now, slowMode)
+	if err != nil {
+		return fmt.Errorf("failed to check slow mode: %w", err)
+	}
+	if !claimed {
+		return slowModeRejection(conversationRepo, conversationID, userID, now, slowMode)
+	}
+	return nil
+}
+
+// slowModeRejection error ของ slow mode เมื่อจอง slot ไม่ได้ (อ่านเวลาส่งล่าสุดที่ชนะการจองไป)
+func slowModeRejection(conversationRepo repository.ConversationRepository, conversationID, userID uuid.UUID, now time.Time, interval time.Duration) error {
+	until := now.Add(interval)
+	if latest, err := conversationRepo.GetMember(conversationID, userID); err == nil && latest != nil && latest.LastSentAt != nil {
+		until = latest.LastSentAt.Add(interval)
+	}
+	return slowModeError(until, now)
+}
+
+// slowModeError สร้าง service.SendRestrictionError ของ slow mode ที่ส่งได้อีกครั้งเมื่อถึง until
+func slowModeError(until, now time.Time) error {
+	wait := int(math.Ceil(until.Sub(now).Seconds()))
+	if wait < 1 {
+		wait = 1
+	}
+	return &service.SendRestrictionError{
+		Reason:  service.SendRestrictionSlowMode,
+		Until:   until,
+		Message: fmt.Sprintf("slow mode is enabled: you can send another message in %d seconds", wait),
+	}
+}
+
+// isMediaMessageType ข้อความประเภทที่ต้องใช้สิทธิ์ send_media
+func isMediaMessageType(messageType string) bool {
+	switch messageType {
+	case "image", "video", "file", "audio", "voice", "album":
+		return true
+	default:
+		return false
+	}
+}
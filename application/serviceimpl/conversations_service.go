@@ -2,6 +2,7 @@
 package serviceimpl
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -15,11 +16,20 @@ import (
 	"github.com/thizplus/gofiber-chat-api/domain/types"
 )
 
+// ช่วงที่อนุญาตของข้อความหายไปอัตโนมัติ
+const (
+	MinMessageTTLSeconds = 60 * 60            // 1 ชั่วโมง
+	MaxMessageTTLSeconds = 365 * 24 * 60 * 60 // 365 วัน
+)
+
 type conversationService struct {
 	conversationRepo repository.ConversationRepository
 	userRepo         repository.UserRepository
 	messageRepo      repository.MessageRepository
 	mentionRepo      repository.MessageMentionRepository
+	reactionRepo     repository.MessageReactionRepository
+	pollRepo         repository.PollRepository
+	mediaAccess      service.MediaAccessService
 }
 
 // NewConversationService สร้าง service ใหม่
@@ -28,12 +38,18 @@ func NewConversationService(
 	userRepo repository.UserRepository,
 	messageRepo repository.MessageRepository,
 	mentionRepo repository.MessageMentionRepository,
+	reactionRepo repository.MessageReactionRepository,
+	pollRepo repository.PollRepository,
+	mediaAccess service.MediaAccessService,
 ) service.ConversationService {
 	return &conversationService{
 		conversationRepo: conversationRepo,
 		userRepo:         userRepo,
 		messageRepo:      messageRepo,
 		mentionRepo:      mentionRepo,
+		reactionRepo:     reactionRepo,
+		pollRepo:         pollRepo,
+		mediaAccess:      mediaAccess,
 	}
 }
 
@@ -170,6 +186,9 @@ func (s *conversationService) convertToConversationDTO(conversation *models.Conv
 		CreatorID:       conversation.CreatorID,
 		IsActive:        conversation.IsActive,
 		Metadata:        conversation.Metadata,
+
+		MessageTTLSeconds: conversation.MessageTTLSeconds,
+		SlowModeSeconds:   conversation.SlowModeSeconds,
 	}
 
 	// ดึงข้อมูลเพิ่มเติมตามประเภทการสนทนา
@@ -218,23 +237,12 @@ func (s *conversationService) convertToConversationDTO(conversation *models.Conv
 		convDTO.IsPinned = member.IsPinned
 		convDTO.IsMuted = member.IsMuted
 
-		// คำนวณ unread_count
+		// คำนวณ unread_count จาก read watermark (O(1) ไม่ต้อง query ข้อความ) หักข้อความที่ถูกลบหรือหมดอายุแล้ว
 		var unreadCount int
-		var lastReadAt *time.Time
-		if member.LastReadAt != nil {
-			lastReadAt = member.LastReadAt
-			messages, err := s.messageRepo.GetMessagesAfterTime(
-				conversation.ID, *member.LastReadAt, userID)
-			if err == nil {
-				unreadCount = len(messages)
-			}
-		} else {
-			messages, err := s.messageRepo.GetAllUnreadMessages(
-				conversation.ID, userID)
-			if err == nil {
-				unreadCount = len(messages)
-			}
+		if unread := conversation.LastMessageSeq - member.LastReadSeq - member.DeletedUnread; unread > 0 {
+			unreadCount = int(unread)
 		}
+		lastReadAt := member.LastReadAt
 
 		convDTO.UnreadCount = unreadCount
 
@@ -473,15 +481,7 @@ func (s *conversationService) GetConversationMessages(conversationID, userID uui
 	}
 
 	// แปลงข้อความเป็น DTOs
-	messageDTOs := make([]*dto.MessageDTO, 0, len(messages))
-	for _, msg := range messages {
-		messageDTO, err := s.ConvertToMessageDTO(msg, userID)
-		if err != nil {
-			// ข้ามข้อความที่มีปัญหา
-			continue
-		}
-		messageDTOs = append(messageDTOs, messageDTO)
-	}
+	messageDTOs := s.convertMessagesToDTOs(messages, userID)
 
 	return messageDTOs, total, nil
 }
@@ -515,7 +515,74 @@ func (s *conversationService) SetMuteStatus(conversationID, userID uuid.UUID, is
 	}
 
 	// อัพเดตสถานะปิดเสียง
-	return s.conversationRepo.SetMuteStatus(conversationID, userID, isMuted)
+	if err := s.conversationRepo.SetMuteStatus(conversationID, userID, isMuted); err != nil {
+		return err
+	}
+
+	// เปิดเสียงแล้วต้องยกเลิกการปิดเสียงชั่วคราวด้วย
+	if !isMuted {
+		member, err := s.conversationRepo.GetMember(conversationID, userID)
+		if err != nil {
+			return err
+		}
+		settings := member.GetNotificationSettings()
+		if settings.MutedUntil != nil {
+			settings.MutedUntil = nil
+			return s.conversationRepo.UpdateNotificationSettings(conversationID, userID, settings.ToJSONB())
+		}
+	}
+	return nil
+}
+
+// GetNotificationSettings ดึงการตั้งค่าการแจ้งเตือนของผู้ใช้ในการสนทนา
+func (s *conversationService) GetNotificationSettings(conversationID, userID uuid.UUID) (*dto.NotificationSettingsDTO, error) {
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil || member == nil {
+		return nil, errors.New("you are not a member of this conversation")
+	}
+
+	return toNotificationSettingsDTO(member, time.Now()), nil
+}
+
+// UpdateNotificationSettings ตั้งค่าการแจ้งเตือน ค่าที่ส่งมาแทนที่ค่าเดิมทั้งหมด
+// การปิดเสียงถาวร (is_muted) ยังตั้งผ่าน SetMuteStatus
+func (s *conversationService) UpdateNotificationSettings(conversationID, userID uuid.UUID, input *dto.NotificationSettingsRequest) (*dto.NotificationSettingsDTO, error) {
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil || member == nil {
+		return nil, errors.New("you are not a member of this conversation")
+	}
+
+	now := time.Now()
+	settings := models.NotificationSettings{
+		Level:      models.NotificationLevel(input.Level),
+		MutedUntil: input.MutedUntil,
+		Keywords:   input.Keywords,
+	}
+	if err := settings.Normalize(now); err != nil {
+		return nil, err
+	}
+
+	member.NotificationSettings = settings.ToJSONB()
+	if err := s.conversationRepo.UpdateNotificationSettings(conversationID, userID, member.NotificationSettings); err != nil {
+		return nil, err
+	}
+
+	return toNotificationSettingsDTO(member, now), nil
+}
+
+func toNotificationSettingsDTO(member *models.ConversationMember, now time.Time) *dto.NotificationSettingsDTO {
+	settings := member.GetNotificationSettings()
+	keywords := settings.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+
+	return &dto.NotificationSettingsDTO{
+		Level:      string(settings.Level),
+		MutedUntil: settings.MutedUntil,
+		Keywords:   keywords,
+		IsMuted:    member.IsMutedAt(now),
+	}
 }
 
 // CheckMembership ตรวจสอบว่าผู้ใช้เป็นสมาชิกของการสนทนาหรือไม่
@@ -529,6 +596,59 @@ func (s *conversationService) ConvertToMessageDTO(msg *models.Message, userID uu
 		return nil, errors.New("message is nil")
 	}
 
+	return s.convertMessagesToDTOs([]*models.Message{msg}, userID)[0], nil
+}
+
+// convertMessagesToDTOs แปลงข้อความทั้งหน้าเป็น DTO
+// ข้อมูลที่ต้องดึงต่อข้อความ (reactions) จะดึงครั้งเดียวต่อหน้า
+func (s *conversationService) convertMessagesToDTOs(messages []*models.Message, userID uuid.UUID) []*dto.MessageDTO {
+	messageDTOs := make([]*dto.MessageDTO, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		messageDTOs = append(messageDTOs, s.buildMessageDTO(msg, userID))
+	}
+
+	// เพิ่มข้อมูล reactions (ข้อความที่ถูกลบไม่แสดง reactions)
+	s.addReactionsToDTOs(messageDTOs, userID)
+
+	// เพิ่มข้อมูลโพลและผลโหวต (เฉพาะข้อความโพล)
+	s.addPollsToDTOs(messageDTOs, userID)
+
+	// แปลง path ของไฟล์เป็น URL ที่มีอายุ (โหมด private media)
+	s.resolveMediaForDTOs(messageDTOs, userID)
+
+	return messageDTOs
+}
+
+// resolveMediaForDTOs ตรวจสมาชิกครั้งเดียวต่อการสนทนา แล้วออก URL ของไฟล์ให้ทุกข้อความ
+// ผู้ที่ไม่ใช่สมาชิกผ่าน ResolveMessageMedia ซึ่งล้าง URL ทิ้ง
+func (s *conversationService) resolveMediaForDTOs(messageDTOs []*dto.MessageDTO, userID uuid.UUID) {
+	if s.mediaAccess == nil || !s.mediaAccess.IsPrivate() {
+		return
+	}
+
+	membership := make(map[uuid.UUID]bool)
+	for _, messageDTO := range messageDTOs {
+		isMember, checked := membership[messageDTO.ConversationID]
+		if !checked {
+			member, err := s.conversationRepo.IsMember(messageDTO.ConversationID, userID)
+			isMember = err == nil && member
+			membership[messageDTO.ConversationID] = isMember
+		}
+
+		if isMember {
+			s.mediaAccess.ResolveMessageMediaForMembers(messageDTO)
+		} else {
+			s.mediaAccess.ResolveMessageMedia(messageDTO, userID)
+		}
+	}
+}
+
+// buildMessageDTO แปลงข้อความเดียวเป็น DTO (ยกเว้นข้อมูลที่ดึงแบบ batch)
+func (s *conversationService) buildMessageDTO(msg *models.Message, userID uuid.UUID) *dto.MessageDTO {
+
 	// ดึง temp_id จาก metadata ถ้ามี (JSONB เป็น map[string]interface{} อยู่แล้ว)
 	tempID := ""
 	if msg.Metadata != nil {
@@ -557,6 +677,11 @@ func (s *conversationService) ConvertToMessageDTO(msg *models.Message, userID uu
 		IsEdited:          msg.IsEdited,
 		EditCount:         msg.EditCount,
 		ReplyToID:         msg.ReplyToID,
+		ThreadRootID:      msg.ThreadRootID,
+		ThreadReplyCount:  msg.ThreadReplyCount,
+		ThreadLastReplyAt: msg.ThreadLastReplyAt,
+		ExpiresAt:         msg.ExpiresAt,
+		Sequence:          msg.Sequence,
 		ReadCount:         0,     // ค่าเริ่มต้น จะอัปเดตทีหลัง
 		IsRead:            false, // ค่าเริ่มต้น จะอัปเดตทีหลัง
 	}
@@ -635,7 +760,15 @@ func (s *conversationService) ConvertToMessageDTO(msg *models.Message, userID uu
 		s.addReplyToInfoToDTO(messageDTO)
 	}
 
-	return messageDTO, nil
+	return messageDTO
+}
+
+// resolveMediaURL แปลง path ของไฟล์เป็น URL (ผู้เรียกตรวจสมาชิกแล้ว)
+func (s *conversationService) resolveMediaURL(ref string) string {
+	if s.mediaAccess == nil {
+		return ref
+	}
+	return s.mediaAccess.ResolveURL(ref)
 }
 
 // addSenderInfoToDTO เพิ่มข้อมูลผู้ส่งใน DTO
@@ -685,6 +818,86 @@ func (s *conversationService) addReadStatusToDTO(msgDTO *dto.MessageDTO, userID
 	}
 }
 
+// addReactionsToDTOs เพิ่มจำนวน reaction ต่อ emoji และ reacted_by_me ใน DTO ทั้งหน้าด้วย query เดียว
+func (s *conversationService) addReactionsToDTOs(msgDTOs []*dto.MessageDTO, userID uuid.UUID) {
+	if s.reactionRepo == nil {
+		return
+	}
+
+	messageIDs := make([]uuid.UUID, 0, len(msgDTOs))
+	for _, msgDTO := range msgDTOs {
+		if !msgDTO.IsDeleted {
+			messageIDs = append(messageIDs, msgDTO.ID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	reactions, err := s.reactionRepo.GetByMessageIDs(context.Background(), messageIDs)
+	if err != nil || len(reactions) == 0 {
+		return
+	}
+
+	byMessage := make(map[uuid.UUID][]*models.MessageReaction)
+	for _, r := range reactions {
+		byMessage[r.MessageID] = append(byMessage[r.MessageID], r)
+	}
+
+	for _, msgDTO := range msgDTOs {
+		if msgReactions, ok := byMessage[msgDTO.ID]; ok && !msgDTO.IsDeleted {
+			msgDTO.Reactions = buildReactionSummary(msgReactions, userID)
+		}
+	}
+}
+
+// addPollsToDTOs เพิ่มข้อมูลโพลพร้อมผลโหวตและตัวเลือกของผู้ใช้ใน DTO
+// ดึงโพลและผลโหวตของทั้งหน้าใน 2 query
+func (s *conversationService) addPollsToDTOs(msgDTOs []*dto.MessageDTO, userID uuid.UUID) {
+	if s.pollRepo == nil {
+		return
+	}
+
+	messageIDs := make([]uuid.UUID, 0)
+	for _, msgDTO := range msgDTOs {
+		if msgDTO.MessageType == "poll" && !msgDTO.IsDeleted {
+			messageIDs = append(messageIDs, msgDTO.ID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	polls, err := s.pollRepo.GetByMessageIDs(ctx, messageIDs)
+	if err != nil || len(polls) == 0 {
+		return
+	}
+
+	pollIDs := make([]uuid.UUID, len(polls))
+	pollByMessage := make(map[uuid.UUID]*models.Poll, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.ID
+		pollByMessage[poll.MessageID] = poll
+	}
+
+	votes, err := s.pollRepo.GetVotesByPollIDs(ctx, pollIDs)
+	if err != nil {
+		return
+	}
+
+	votesByPoll := make(map[uuid.UUID][]*models.PollVote)
+	for _, vote := range votes {
+		votesByPoll[vote.PollID] = append(votesByPoll[vote.PollID], vote)
+	}
+
+	for _, msgDTO := range msgDTOs {
+		if poll, ok := pollByMessage[msgDTO.ID]; ok && !msgDTO.IsDeleted {
+			msgDTO.Poll = buildPollDTO(poll, votesByPoll[poll.ID], userID)
+		}
+	}
+}
+
 // addReplyToInfoToDTO เพิ่มข้อมูลข้อความที่ตอบกลับใน DTO
 func (s *conversationService) addReplyToInfoToDTO(msgDTO *dto.MessageDTO) {
 	if msgDTO.ReplyToID == nil {
@@ -793,22 +1006,7 @@ func (s *conversationService) GetMessageContext(conversationID, userID uuid.UUID
 	})
 
 	// แปลงเป็น DTOs โดยใช้ฟังก์ชันที่มีอยู่แล้ว
-	messageDTOs := make([]*dto.MessageDTO, 0, len(allMessages))
-	for _, msg := range allMessages {
-		messageDTO, err := s.ConvertToMessageDTO(msg, userID)
-		if err != nil {
-			// ข้ามข้อความที่มีปัญหา
-			continue
-		}
-
-		// เพิ่มการเน้นสำหรับข้อความเป้าหมาย (ถ้าต้องการ)
-		if msg.ID == targetUUID {
-			// ถ้ามีฟิลด์ IsHighlighted ใน MessageDTO ให้กำหนดค่าเป็น true
-			// messageDTO.IsHighlighted = true
-		}
-
-		messageDTOs = append(messageDTOs, messageDTO)
-	}
+	messageDTOs := s.convertMessagesToDTOs(allMessages, userID)
 
 	return messageDTOs, hasMoreBefore, hasMoreAfter, nil
 }
@@ -847,15 +1045,7 @@ func (s *conversationService) GetMessagesBeforeID(conversationID, userID uuid.UU
 	}
 
 	// แปลงเป็น DTOs โดยใช้ฟังก์ชันที่มีอยู่แล้ว
-	messageDTOs := make([]*dto.MessageDTO, 0, len(messages))
-	for _, msg := range messages {
-		messageDTO, err := s.ConvertToMessageDTO(msg, userID)
-		if err != nil {
-			// ข้ามข้อความที่มีปัญหา
-			continue
-		}
-		messageDTOs = append(messageDTOs, messageDTO)
-	}
+	messageDTOs := s.convertMessagesToDTOs(messages, userID)
 
 	return messageDTOs, total, nil
 }
@@ -894,15 +1084,7 @@ func (s *conversationService) GetMessagesAfterID(conversationID, userID uuid.UUI
 	}
 
 	// แปลงเป็น DTOs โดยใช้ฟังก์ชันที่มีอยู่แล้ว
-	messageDTOs := make([]*dto.MessageDTO, 0, len(messages))
-	for _, msg := range messages {
-		messageDTO, err := s.ConvertToMessageDTO(msg, userID)
-		if err != nil {
-			// ข้ามข้อความที่มีปัญหา
-			continue
-		}
-		messageDTOs = append(messageDTOs, messageDTO)
-	}
+	messageDTOs := s.convertMessagesToDTOs(messages, userID)
 
 	return messageDTOs, total, nil
 }
@@ -1111,10 +1293,10 @@ func (s *conversationService) GetConversationMediaByType(conversationID, userID
 
 								// ดึง media URLs
 								if mediaURL, ok := fileMap["media_url"].(string); ok {
-									item.MediaURL = mediaURL
+									item.MediaURL = s.resolveMediaURL(mediaURL)
 								}
 								if thumbnailURL, ok := fileMap["media_thumbnail_url"].(string); ok {
-									item.ThumbnailURL = thumbnailURL
+									item.ThumbnailURL = s.resolveMediaURL(thumbnailURL)
 								}
 
 								// เพิ่มข้อมูล file (ถ้ามี)
@@ -1137,8 +1319,8 @@ func (s *conversationService) GetConversationMediaByType(conversationID, userID
 				MessageID:    msg.ID.String(),
 				MessageType:  msg.MessageType,
 				Content:      msg.Content,
-				MediaURL:     msg.MediaURL,
-				ThumbnailURL: msg.MediaThumbnailURL,
+				MediaURL:     s.resolveMediaURL(msg.MediaURL),
+				ThumbnailURL: s.resolveMediaURL(msg.MediaThumbnailURL),
 				CreatedAt:    msg.CreatedAt,
 				IsAlbum:      false,
 			}
@@ -1221,16 +1403,19 @@ func (s *conversationService) DeleteConversation(conversationID, userID uuid.UUI
 		}
 		return "hidden", nil
 	} else {
-		// Group: Leave group - just hide for now (not remove member)
-		// เพื่อความง่ายและปลอดภัย ให้ hide เหมือน direct แทนการ remove member
-		err = s.conversationRepo.SetHiddenStatus(conversationID, userID, true)
-		if err != nil {
+		// Group: ออกจากกลุ่มจริง (ลบสมาชิก, โอน owner ถ้าจำเป็น)
+		if _, err := s.LeaveGroup(conversationID, userID); err != nil {
 			return "", err
 		}
-		return "hidden", nil
+		return "left", nil
 	}
 }
 
+// LeaveGroup ออกจากกลุ่ม (owner โอนความเป็นเจ้าของอัตโนมัติ, คนสุดท้ายออกแล้วกลุ่มถูกปิด)
+func (s *conversationService) LeaveGroup(conversationID, userID uuid.UUID) (*dto.LeaveGroupResultDTO, error) {
+	return leaveGroup(s.conversationRepo, s.userRepo, s.messageRepo, conversationID, userID)
+}
+
 // TransferOwnership โอนความเป็นเจ้าของกลุ่มให้สมาชิกคนอื่น
 func (s *conversationService) TransferOwnership(conversationID, currentOwnerID, newOwnerID uuid.UUID) error {
 	// 1. ตรวจสอบว่าการสนทนานี้มีอยู่จริง
@@ -1336,3 +1521,43 @@ func (s *conversationService) TransferOwnership(conversationID, currentOwnerID,
 // ConvertToBusinessMessageDTO แปลง Message model เป็น DTO สำหรับ business context
 
 // addBusinessReadStatusToDTO เพิ่มข้อมูลสถานะการอ่านสำหรับ business context
+
+// SetMessageTTL ตั้งค่าข้อความหายไปอัตโนมัติของการสนทนา
+// มีผลกับข้อความใหม่เท่านั้น ในกลุ่มต้องเป็น owner หรือ admin
+func (s *conversationService) SetMessageTTL(conversationID, userID uuid.UUID, ttlSeconds int) (int, error) {
+	if ttlSeconds != 0 && (ttlSeconds < MinMessageTTLSeconds || ttlSeconds > MaxMessageTTLSeconds) {
+		return 0, errors.New("message ttl must be between 1 hour and 365 days")
+	}
+
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	if conversation == nil {
+		return 0, errors.New("conversation not found")
+	}
+
+	member, err := s.conversationRepo.GetMember(conversationID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get member: %w", err)
+	}
+	if member == nil {
+		return 0, errors.New("user is not a member of this conversation")
+	}
+	if conversation.Type == "group" && member.Role != models.RoleOwner && member.Role != models.RoleAdmin {
+		return 0, errors.New("only owner or admin can change message ttl")
+	}
+
+	oldTTL := conversation.MessageTTLSeconds
+	if oldTTL == ttlSeconds {
+		return oldTTL, nil
+	}
+
+	if err := s.conversationRepo.UpdateConversation(conversationID, types.JSONB{
+		"message_ttl_seconds": ttlSeconds,
+	}); err != nil {
+		return 0, err
+	}
+
+	return oldTTL, nil
+}
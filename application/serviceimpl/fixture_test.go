@@ -0,0 +1,279 @@
+// application/serviceimpl/fixture_test.go
+package serviceimpl_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/application/serviceimpl"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/adapter"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/persistence/memory"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/local"
+	"github.com/thizplus/gofiber-chat-api/internal/testsupport"
+)
+
+// fixture ต่อ service จริงเข้ากับ repository ในหน่วยความจำและ WebSocket ปลอม
+type fixture struct {
+	t      *testing.T
+	ws     *testsupport.FakeWebSocketAdapter
+	mailer *recordingMailer
+	push   *adapter.FakePushProvider
+
+	// created_at ของข้อความล่าสุดที่ส่งผ่าน sendText
+	lastCreatedAt time.Time
+
+	userRepo         repository.UserRepository
+	friendshipRepo   repository.UserFriendshipRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	messageReadRepo  repository.MessageReadRepository
+	sessionRepo      repository.UserSessionRepository
+	pushDeviceRepo   repository.PushDeviceRepository
+	fileUploadRepo   repository.FileUploadRepository
+	reactionRepo     repository.MessageReactionRepository
+
+	storage service.FileStorageService
+
+	messageService      service.MessageService
+	messageReadService  service.MessageReadService
+	memberService       service.ConversationMemberService
+	conversationService service.ConversationService
+	friendshipService   service.UserFriendshipService
+	authService         service.AuthService
+	sessionService      service.SessionService
+	verificationService service.VerificationService
+	twoFactorService    service.TwoFactorService
+	notificationService service.NotificationService
+	pushService         service.PushService
+	imageService        service.ImageService
+	mediaAccess         service.MediaAccessService
+	inviteService       service.GroupInviteService
+	moderationService   service.GroupModerationService
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	return newFixtureWithMedia(t, false)
+}
+
+// newFixtureWithMedia สร้าง fixture โดยเลือกเปิดโหมด private media (ข้อความเก็บ path และออก URL ที่มีลายเซ็น)
+func newFixtureWithMedia(t *testing.T, privateMedia bool) *fixture {
+	t.Helper()
+
+	store := memory.NewStore()
+	ws := testsupport.NewFakeWebSocketAdapter()
+
+	userRepo := memory.NewUserRepository(store)
+	friendshipRepo := memory.NewUserFriendshipRepository(store)
+	conversationRepo := memory.NewConversationRepository(store)
+	messageRepo := memory.NewMessageRepository(store)
+	messageReadRepo := memory.NewMessageReadRepository(store)
+	mentionRepo := memory.NewMessageMentionRepository(store)
+	refreshTokenRepo := memory.NewRefreshTokenRepository(store)
+	sessionRepo := memory.NewUserSessionRepository(store)
+
+	pushDeviceRepo := memory.NewPushDeviceRepository(store)
+	fileUploadRepo := memory.NewFileUploadRepository(store)
+	banRepo := memory.NewGroupBanRepository(store)
+	reactionRepo := memory.NewMessageReactionRepository(store)
+
+	// local storage ใน temp dir ใช้กับ image pipeline
+	storage, err := local.NewLocalStorage(&local.LocalConfig{RootDir: t.TempDir(), BaseURL: "https://chat.example.com/storage", SigningKey: "test-signing-key"})
+	if err != nil {
+		t.Fatalf("create storage: %v", err)
+	}
+	imageService := serviceimpl.NewImageService(storage, fileUploadRepo)
+	mediaAccess := serviceimpl.NewMediaAccessService(storage, conversationRepo, privateMedia, time.Hour)
+
+	sessionService := serviceimpl.NewSessionService(sessionRepo, refreshTokenRepo, ws)
+	// push ที่รอรวมจะถูกส่งเมื่อเรียก pushService.Flush() เท่านั้น (collapse window ยาวกว่าเวลาทดสอบ)
+	push := adapter.NewFakePushProvider(models.PushProviderFCM)
+	pushService := serviceimpl.NewPushService(pushDeviceRepo, ws, nil, sessionService, []port.PushProvider{push}, time.Hour)
+	notificationService := serviceimpl.NewNotificationService(ws, userRepo, messageRepo, conversationRepo, pushService, mediaAccess)
+	mailer := &recordingMailer{}
+	twoFactorService := serviceimpl.NewTwoFactorService(memory.NewTwoFactorRepository(store), userRepo, "Chat Test")
+
+	return &fixture{
+		t:                  t,
+		ws:                 ws,
+		mailer:             mailer,
+		push:               push,
+		userRepo:           userRepo,
+		friendshipRepo:     friendshipRepo,
+		conversationRepo:   conversationRepo,
+		messageRepo:        messageRepo,
+		messageReadRepo:    messageReadRepo,
+		sessionRepo:        sessionRepo,
+		pushDeviceRepo:     pushDeviceRepo,
+		fileUploadRepo:     fileUploadRepo,
+		reactionRepo:       reactionRepo,
+		storage:            storage,
+		messageService:     serviceimpl.NewMessageService(messageRepo, messageReadRepo, conversationRepo, userRepo, notificationService, mentionRepo, imageService, mediaAccess),
+		messageReadService: serviceimpl.NewMessageReadService(messageRepo, messageReadRepo, conversationRepo),
+		memberService:      serviceimpl.NewConversationMemberService(conversationRepo, userRepo, messageRepo, banRepo),
+		// poll ไม่มี repository ในหน่วยความจำ ใช้ได้เฉพาะเมธอดที่ไม่แตะตารางนี้
+		conversationService: serviceimpl.NewConversationService(conversationRepo, userRepo, messageRepo, mentionRepo, reactionRepo, nil, mediaAccess),
+		friendshipService:   serviceimpl.NewUserFriendshipService(friendshipRepo, userRepo),
+		authService:         serviceimpl.NewAuthService(userRepo, refreshTokenRepo, nil, sessionRepo, sessionService, notificationService, twoFactorService),
+		sessionService:      sessionService,
+		twoFactorService:    twoFactorService,
+		notificationService: notificationService,
+		pushService:         pushService,
+		imageService:        imageService,
+		mediaAccess:         mediaAccess,
+		inviteService:       serviceimpl.NewGroupInviteService(memory.NewGroupInviteRepository(store), conversationRepo, userRepo, messageRepo, banRepo),
+		moderationService:   serviceimpl.NewGroupModerationService(banRepo, conversationRepo, userRepo, messageRepo),
+		verificationService: serviceimpl.NewVerificationService(
+			userRepo, memory.NewVerificationTokenRepository(store), refreshTokenRepo, sessionService, mailer, "https://chat.example.com",
+		),
+	}
+}
+
+// createUser สร้างผู้ใช้ที่ active
+func (f *fixture) createUser(username string) *models.User {
+	f.t.Helper()
+
+	user := &models.User{
+		Username:    username,
+		Email:       username + "@example.com",
+		DisplayName: strings.ToUpper(username[:1]) + username[1:],
+	}
+	if err := f.userRepo.Create(user); err != nil {
+		f.t.Fatalf("create user %s: %v", username, err)
+	}
+	return user
+}
+
+// createConversation สร้างการสนทนา โดยสมาชิกคนแรกเป็น owner (กลุ่ม) หรือ admin (direct)
+func (f *fixture) createConversation(conversationType string, users ...*models.User) *models.Conversation {
+	f.t.Helper()
+
+	creatorID := users[0].ID
+	conversation := &models.Conversation{
+		Type:      conversationType,
+		Title:     "Test " + conversationType,
+		CreatorID: &creatorID,
+	}
+	if err := f.conversationRepo.Create(conversation); err != nil {
+		f.t.Fatalf("create conversation: %v", err)
+	}
+
+	for i, user := range users {
+		member := &models.ConversationMember{
+			ConversationID: conversation.ID,
+			UserID:         user.ID,
+			Role:           models.RoleMember,
+		}
+		if i == 0 {
+			member.IsAdmin = true
+			member.Role = models.RoleOwner
+			if conversationType == "direct" {
+				member.Role = models.RoleAdmin
+			}
+		}
+		if err := f.conversationRepo.AddMember(member); err != nil {
+			f.t.Fatalf("add member %s: %v", user.Username, err)
+		}
+	}
+	return conversation
+}
+
+// sendText ส่งข้อความและกำหนด created_at ให้มากกว่าข้อความก่อนหน้าเสมอ
+// ลำดับของข้อความจึงตรงกับลำดับการส่งโดยไม่ขึ้นกับความละเอียดของนาฬิกา
+func (f *fixture) sendText(conversationID, senderID uuid.UUID, content string) *models.Message {
+	f.t.Helper()
+
+	message, err := f.messageService.SendTextMessage(conversationID, senderID, content, nil)
+	if err != nil {
+		f.t.Fatalf("send %q: %v", content, err)
+	}
+
+	if !message.CreatedAt.After(f.lastCreatedAt) {
+		createdAt := f.lastCreatedAt.Add(time.Microsecond)
+		if err := f.messageRepo.UpdateFields(message.ID, map[string]interface{}{"created_at": createdAt}); err != nil {
+			f.t.Fatalf("set created_at of %q: %v", content, err)
+		}
+		message.CreatedAt = createdAt
+	}
+	f.lastCreatedAt = message.CreatedAt
+	return message
+}
+
+func (f *fixture) member(conversationID, userID uuid.UUID) *models.ConversationMember {
+	f.t.Helper()
+
+	member, err := f.conversationRepo.GetMember(conversationID, userID)
+	if err != nil {
+		f.t.Fatalf("get member: %v", err)
+	}
+	return member
+}
+
+// expectError ตรวจว่า err มีข้อความที่คาดไว้
+func expectError(t *testing.T, err error, want string) {
+	t.Helper()
+
+	if err == nil {
+		t.Fatalf("expected error %q, got nil", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error %q, got %q", want, err.Error())
+	}
+}
+
+func mustNoError(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// recordingMailer เก็บอีเมลที่ส่งไว้ในหน่วยความจำ
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []port.MailMessage
+	fail bool // true = จำลอง SMTP ล่ม (ไม่บันทึกอีเมลและคืน error)
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg port.MailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fail {
+		return errors.New("smtp unavailable")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// lastToken ดึงโค้ดจากลิงก์ในอีเมลฉบับล่าสุดที่ส่งถึง to
+func (m *recordingMailer) lastToken(t *testing.T, to string) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To != to {
+			continue
+		}
+		if match := mailTokenPattern.FindStringSubmatch(m.sent[i].TextBody); match != nil {
+			return match[1]
+		}
+	}
+	t.Fatalf("no email with a token sent to %s", to)
+	return ""
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
@@ -234,3 +234,209 @@ func (s *groupActivityService) LogMemberLeft(conversationID, userID uuid.UUID) e
 
 	return s.activityRepo.Create(activity)
 }
+
+// LogMessageTTLChanged บันทึกการเปลี่ยนการตั้งค่าข้อความหายไปอัตโนมัติ
+func (s *groupActivityService) LogMessageTTLChanged(conversationID, actorID uuid.UUID, oldTTL, newTTL int) error {
+	activity := &models.GroupActivity{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		Type:           models.ActivityMessageTTLChanged,
+		ActorID:        actorID,
+		OldValue:       types.JSONB{"message_ttl_seconds": oldTTL},
+		NewValue:       types.JSONB{"message_ttl_seconds": newTTL},
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.activityRepo.Create(activity); err != nil {
+		return err
+	}
+
+	// Broadcast WebSocket event พร้อม user info
+	activityWithUsers, err := s.activityRepo.GetByID(activity.ID)
+	if err == nil && s.notificationService != nil {
+		activityDTO := s.convertToActivityDTO(activityWithUsers)
+		s.notificationService.NotifyNewActivity(conversationID, activityDTO)
+	}
+
+	return nil
+}
+
+// LogPermissionsChanged บันทึกการเปลี่ยน permission policy ของกลุ่ม (เฉพาะสิทธิ์ที่เปลี่ยน)
+func (s *groupActivityService) LogPermissionsChanged(conversationID, actorID uuid.UUID, oldValues, newValues map[string]string) error {
+	oldValue := types.JSONB{}
+	for permission, role := range oldValues {
+		oldValue[permission] = role
+	}
+	newValue := types.JSONB{}
+	for permission, role := range newValues {
+		newValue[permission] = role
+	}
+
+	activity := &models.GroupActivity{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		Type:           models.ActivityPermissionsChanged,
+		ActorID:        actorID,
+		OldValue:       oldValue,
+		NewValue:       newValue,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.activityRepo.Create(activity); err != nil {
+		return err
+	}
+
+	// Broadcast WebSocket event พร้อม user info
+	activityWithUsers, err := s.activityRepo.GetByID(activity.ID)
+	if err == nil && s.notificationService != nil {
+		activityDTO := s.convertToActivityDTO(activityWithUsers)
+		s.notificationService.NotifyNewActivity(conversationID, activityDTO)
+	}
+
+	return nil
+}
+
+// LogInviteCreated บันทึกการสร้างลิงก์เชิญ (ไม่บันทึก token ลง activity เพราะสมาชิกทุกคนเห็น activity)
+func (s *groupActivityService) LogInviteCreated(conversationID, actorID uuid.UUID, invite *dto.GroupInviteDTO) error {
+	newValue := types.JSONB{
+		"invite_id":        invite.ID.String(),
+		"require_approval": invite.RequireApproval,
+		"max_uses":         invite.MaxUses,
+	}
+	if invite.ExpiresAt != nil {
+		newValue["expires_at"] = invite.ExpiresAt
+	}
+
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityInviteCreated,
+		ActorID:        actorID,
+		NewValue:       newValue,
+	})
+}
+
+// LogInviteRevoked บันทึกการยกเลิกลิงก์เชิญ
+func (s *groupActivityService) LogInviteRevoked(conversationID, actorID, inviteID uuid.UUID) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityInviteRevoked,
+		ActorID:        actorID,
+		OldValue:       types.JSONB{"invite_id": inviteID.String()},
+	})
+}
+
+// LogMemberJoined บันทึกการเข้ากลุ่มด้วยตัวเองผ่านลิงก์เชิญ
+func (s *groupActivityService) LogMemberJoined(conversationID, userID, inviteID uuid.UUID) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityMemberJoined,
+		ActorID:        userID,
+		NewValue:       types.JSONB{"invite_id": inviteID.String()},
+	})
+}
+
+// LogJoinRequested บันทึกคำขอเข้ากลุ่มที่รออนุมัติ
+func (s *groupActivityService) LogJoinRequested(conversationID, userID, requestID uuid.UUID) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityJoinRequested,
+		ActorID:        userID,
+		NewValue:       types.JSONB{"request_id": requestID.String()},
+	})
+}
+
+// LogJoinRequestReviewed บันทึกการอนุมัติหรือปฏิเสธคำขอเข้ากลุ่ม
+func (s *groupActivityService) LogJoinRequestReviewed(conversationID, actorID, targetID, requestID uuid.UUID, approved bool) error {
+	activityType := models.ActivityJoinRequestRejected
+	if approved {
+		activityType = models.ActivityJoinRequestApproved
+	}
+
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           activityType,
+		ActorID:        actorID,
+		TargetID:       &targetID,
+		NewValue:       types.JSONB{"request_id": requestID.String()},
+	})
+}
+
+// LogMemberBanned บันทึกการแบนผู้ใช้ (removed = ถูกนำออกจากกลุ่มด้วย)
+func (s *groupActivityService) LogMemberBanned(conversationID, actorID uuid.UUID, ban *dto.GroupBanDTO, removed bool) error {
+	newValue := types.JSONB{"removed_member": removed}
+	if ban.Reason != "" {
+		newValue["reason"] = ban.Reason
+	}
+	if ban.ExpiresAt != nil {
+		newValue["expires_at"] = ban.ExpiresAt
+	}
+
+	targetID := ban.UserID
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityMemberBanned,
+		ActorID:        actorID,
+		TargetID:       &targetID,
+		NewValue:       newValue,
+	})
+}
+
+// LogMemberUnbanned บันทึกการยกเลิกแบน
+func (s *groupActivityService) LogMemberUnbanned(conversationID, actorID, targetID uuid.UUID) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityMemberUnbanned,
+		ActorID:        actorID,
+		TargetID:       &targetID,
+	})
+}
+
+// LogMemberRestricted บันทึกการจำกัดการส่งข้อความของสมาชิก
+func (s *groupActivityService) LogMemberRestricted(conversationID, actorID, targetID uuid.UUID, until time.Time) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityMemberRestricted,
+		ActorID:        actorID,
+		TargetID:       &targetID,
+		NewValue:       types.JSONB{"send_restricted_until": until},
+	})
+}
+
+// LogMemberUnrestricted บันทึกการยกเลิกการจำกัดการส่งข้อความ
+func (s *groupActivityService) LogMemberUnrestricted(conversationID, actorID, targetID uuid.UUID) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivityMemberUnrestricted,
+		ActorID:        actorID,
+		TargetID:       &targetID,
+	})
+}
+
+// LogSlowModeChanged บันทึกการเปลี่ยน slow mode ของกลุ่ม
+func (s *groupActivityService) LogSlowModeChanged(conversationID, actorID uuid.UUID, oldSeconds, newSeconds int) error {
+	return s.createAndBroadcast(&models.GroupActivity{
+		ConversationID: conversationID,
+		Type:           models.ActivitySlowModeChanged,
+		ActorID:        actorID,
+		OldValue:       types.JSONB{"slow_mode_seconds": oldSeconds},
+		NewValue:       types.JSONB{"slow_mode_seconds": newSeconds},
+	})
+}
+
+// createAndBroadcast บันทึก activity แล้วส่ง WebSocket event พร้อม user info
+func (s *groupActivityService) createAndBroadcast(activity *models.GroupActivity) error {
+	activity.ID = uuid.New()
+	activity.CreatedAt = time.Now()
+
+	if err := s.activityRepo.Create(activity); err != nil {
+		return err
+	}
+
+	activityWithUsers, err := s.activityRepo.GetByID(activity.ID)
+	if err == nil && s.notificationService != nil {
+		activityDTO := s.convertToActivityDTO(activityWithUsers)
+		s.notificationService.NotifyNewActivity(activity.ConversationID, activityDTO)
+	}
+
+	return nil
+}
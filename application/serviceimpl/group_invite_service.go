@@ -0,0 +1,421 @@
+// application/serviceimpl/group_invite_service.go
+package serviceimpl
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+type groupInviteService struct {
+	inviteRepo       repository.GroupInviteRepository
+	conversationRepo repository.ConversationRepository
+	userRepo         repository.UserRepository
+	messageRepo      repository.MessageRepository
+	banRepo          repository.GroupBanRepository
+}
+
+// NewGroupInviteService สร้าง service ใหม่
+func NewGroupInviteService(
+	inviteRepo repository.GroupInviteRepository,
+	conversationRepo repository.ConversationRepository,
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+	banRepo repository.GroupBanRepository,
+) service.GroupInviteService {
+	return &groupInviteService{
+		inviteRepo:       inviteRepo,
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+		messageRepo:      messageRepo,
+		banRepo:          banRepo,
+	}
+}
+
+// CreateInvite สร้างลิงก์เชิญใหม่
+func (s *groupInviteService) CreateInvite(conversationID, userID uuid.UUID, input *dto.CreateGroupInviteRequest) (*dto.GroupInviteDTO, error) {
+	if err := s.requireGroupInvitePermission(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if input.MaxUses < 0 {
+		return nil, errors.New("max_uses cannot be negative")
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &models.GroupInvite{
+		ID:              uuid.New(),
+		ConversationID:  conversationID,
+		Token:           token,
+		CreatedBy:       userID,
+		RequireApproval: input.RequireApproval,
+		MaxUses:         input.MaxUses,
+		ExpiresAt:       input.ExpiresAt,
+		CreatedAt:       now,
+	}
+	if err := s.inviteRepo.CreateInvite(invite); err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return buildGroupInviteDTO(invite, now), nil
+}
+
+// ListInvites ดึงลิงก์เชิญทั้งหมดของกลุ่ม
+func (s *groupInviteService) ListInvites(conversationID, userID uuid.UUID) ([]*dto.GroupInviteDTO, error) {
+	if err := s.requireGroupInvitePermission(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.inviteRepo.ListInvites(conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invites: %w", err)
+	}
+
+	now := time.Now()
+	result := make([]*dto.GroupInviteDTO, 0, len(invites))
+	for _, invite := range invites {
+		result = append(result, buildGroupInviteDTO(invite, now))
+	}
+	return result, nil
+}
+
+// RevokeInvite ยกเลิกลิงก์เชิญ
+func (s *groupInviteService) RevokeInvite(conversationID, inviteID, userID uuid.UUID) (*dto.GroupInviteDTO, error) {
+	if err := s.requireGroupInvitePermission(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	invite, err := s.inviteRepo.FindInviteByID(inviteID)
+	if err != nil || invite == nil || invite.ConversationID != conversationID {
+		return nil, errors.New("invite not found")
+	}
+
+	now := time.Now()
+	revoked, err := s.inviteRepo.RevokeInvite(inviteID, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	if !revoked {
+		return nil, errors.New("invite link has been revoked")
+	}
+
+	invite.RevokedAt = &now
+	invite.RevokedBy = &userID
+	return buildGroupInviteDTO(invite, now), nil
+}
+
+// JoinByToken เข้ากลุ่มผ่านลิงก์เชิญ
+func (s *groupInviteService) JoinByToken(token string, userID uuid.UUID) (*dto.JoinGroupResultDTO, error) {
+	// 1. ตรวจสอบลิงก์และกลุ่ม
+	invite, err := s.inviteRepo.FindInviteByToken(token)
+	if err != nil || invite == nil {
+		return nil, errors.New("invite not found")
+	}
+
+	conversation, err := s.conversationRepo.GetByID(invite.ConversationID)
+	if err != nil || conversation == nil || conversation.Type != "group" || !conversation.IsActive {
+		return nil, errors.New("group is no longer available")
+	}
+
+	isMember, err := s.conversationRepo.IsMember(conversation.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if isMember {
+		return nil, errors.New("you are already a member of this conversation")
+	}
+
+	now := time.Now()
+	if err := inviteUnusableError(invite, now); err != nil {
+		return nil, err
+	}
+
+	// ผู้ที่ถูกแบนใช้ลิงก์ไม่ได้ (ตรวจก่อนนับการใช้ลิงก์)
+	if err := s.requireNotBanned(conversation.ID, userID, now, "you are banned from this group"); err != nil {
+		return nil, err
+	}
+
+	if invite.RequireApproval {
+		pending, err := s.inviteRepo.FindPendingJoinRequest(conversation.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check join requests: %w", err)
+		}
+		if pending != nil {
+			return nil, errors.New("you already have a pending request to join this group")
+		}
+	}
+
+	result := &dto.JoinGroupResultDTO{
+		ConversationID: conversation.ID,
+		InviteID:       invite.ID,
+	}
+
+	// 2a. ต้องรออนุมัติ: สร้างคำขอ (นับการใช้ลิงก์ตอนอนุมัติ)
+	if invite.RequireApproval {
+		inviteID := invite.ID
+		request := &models.GroupJoinRequest{
+			ID:             uuid.New(),
+			ConversationID: conversation.ID,
+			UserID:         userID,
+			InviteID:       &inviteID,
+			Status:         models.JoinRequestStatusPending,
+			CreatedAt:      now,
+		}
+		if err := s.inviteRepo.CreateJoinRequest(request); err != nil {
+			return nil, fmt.Errorf("failed to create join request: %w", err)
+		}
+
+		user, _ := s.userRepo.FindByID(userID)
+		result.Status = dto.JoinStatusPending
+		result.JoinRequest = buildJoinRequestDTO(request, user)
+		return result, nil
+	}
+
+	// 2b. เข้ากลุ่มทันที: ใช้ลิงก์หนึ่งครั้งพร้อมเพิ่มสมาชิก (ตรวจ max_uses แบบ atomic ใน repository)
+	joined, err := s.inviteRepo.JoinWithInvite(invite.ID, newGroupMember(conversation.ID, userID, now), now)
+	if err != nil {
+		return nil, errors.New("error adding member: " + err.Error())
+	}
+	if !joined {
+		return nil, errors.New("invite link has reached its usage limit")
+	}
+	s.postJoinMessage(conversation.ID, memberName(s.userRepo, userID)+" joined the group via invite link", now)
+
+	result.Status = dto.JoinStatusJoined
+	return result, nil
+}
+
+// ListJoinRequests ดึงคำขอเข้ากลุ่มตามสถานะ
+func (s *groupInviteService) ListJoinRequests(conversationID, userID uuid.UUID, status string) ([]*dto.GroupJoinRequestDTO, error) {
+	if err := s.requireGroupInvitePermission(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "":
+		status = models.JoinRequestStatusPending
+	case "all":
+		status = ""
+	case models.JoinRequestStatusPending, models.JoinRequestStatusApproved, models.JoinRequestStatusRejected:
+	default:
+		return nil, errors.New("invalid join request status")
+	}
+
+	requests, err := s.inviteRepo.ListJoinRequests(conversationID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get join requests: %w", err)
+	}
+
+	result := make([]*dto.GroupJoinRequestDTO, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, buildJoinRequestDTO(request, request.User))
+	}
+	return result, nil
+}
+
+// ApproveJoinRequest อนุมัติคำขอและเพิ่มผู้ขอเป็นสมาชิก
+func (s *groupInviteService) ApproveJoinRequest(conversationID, requestID, userID uuid.UUID) (*dto.GroupJoinRequestDTO, error) {
+	request, err := s.findPendingJoinRequest(conversationID, requestID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ผู้ขออาจถูกแบนระหว่างรออนุมัติ
+	now := time.Now()
+	if err := s.requireNotBanned(conversationID, request.UserID, now, "user is banned from this group"); err != nil {
+		return nil, err
+	}
+
+	// อนุมัติ นับการใช้ลิงก์ และเพิ่มสมาชิกใน transaction เดียว
+	approved, joined, err := s.inviteRepo.ApproveJoinRequest(request, newGroupMember(conversationID, request.UserID, now), userID, now)
+	if err != nil {
+		if err.Error() == "invite link has reached its usage limit" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to approve join request: %w", err)
+	}
+	if !approved {
+		return nil, errors.New("join request has already been reviewed")
+	}
+	if joined {
+		content := memberName(s.userRepo, userID) + " approved " + memberName(s.userRepo, request.UserID) + " to join the group"
+		s.postJoinMessage(conversationID, content, now)
+	}
+
+	markReviewed(request, models.JoinRequestStatusApproved, userID, now)
+	user, _ := s.userRepo.FindByID(request.UserID)
+	return buildJoinRequestDTO(request, user), nil
+}
+
+// RejectJoinRequest ปฏิเสธคำขอ
+func (s *groupInviteService) RejectJoinRequest(conversationID, requestID, userID uuid.UUID) (*dto.GroupJoinRequestDTO, error) {
+	request, err := s.findPendingJoinRequest(conversationID, requestID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ป้องกันการพิจารณาซ้ำพร้อมกันด้วยเงื่อนไขใน repository
+	now := time.Now()
+	updated, err := s.inviteRepo.ReviewJoinRequest(requestID, models.JoinRequestStatusRejected, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review join request: %w", err)
+	}
+	if !updated {
+		return nil, errors.New("join request has already been reviewed")
+	}
+
+	markReviewed(request, models.JoinRequestStatusRejected, userID, now)
+	user, _ := s.userRepo.FindByID(request.UserID)
+	return buildJoinRequestDTO(request, user), nil
+}
+
+// Helper functions
+
+// requireGroupInvitePermission ตรวจว่าเป็นกลุ่มและผู้ใช้มีสิทธิ์ invite
+func (s *groupInviteService) requireGroupInvitePermission(conversationID, userID uuid.UUID) error {
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return errors.New("conversation not found")
+	}
+	if conversation.Type != "group" {
+		return errors.New("invite links are only available for group conversations")
+	}
+	return requirePermission(s.conversationRepo, conversationID, userID, models.PermissionInvite)
+}
+
+// findPendingJoinRequest ตรวจสิทธิ์ผู้พิจารณาและดึงคำขอที่ยัง pending ของกลุ่ม
+func (s *groupInviteService) findPendingJoinRequest(conversationID, requestID, userID uuid.UUID) (*models.GroupJoinRequest, error) {
+	if err := s.requireGroupInvitePermission(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	request, err := s.inviteRepo.FindJoinRequestByID(requestID)
+	if err != nil || request == nil || request.ConversationID != conversationID {
+		return nil, errors.New("join request not found")
+	}
+	if request.Status != models.JoinRequestStatusPending {
+		return nil, errors.New("join request has already been reviewed")
+	}
+	return request, nil
+}
+
+// requireNotBanned คืน error ที่ระบุถ้าผู้ใช้ยังถูกแบนจากกลุ่มอยู่
+func (s *groupInviteService) requireNotBanned(conversationID, userID uuid.UUID, now time.Time, message string) error {
+	ban, err := s.banRepo.FindActive(conversationID, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to check ban list: %w", err)
+	}
+	if ban != nil {
+		return errors.New(message)
+	}
+	return nil
+}
+
+// postJoinMessage สร้างข้อความระบบเมื่อมีสมาชิกใหม่ (ไม่สำเร็จก็ไม่กระทบการเข้ากลุ่ม)
+func (s *groupInviteService) postJoinMessage(conversationID uuid.UUID, systemMessage string, now time.Time) {
+	message := &models.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		MessageType:    "system",
+		Content:        systemMessage,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.messageRepo.Create(message); err != nil {
+		fmt.Printf("Warning: Failed to create join system message: %v\n", err)
+		return
+	}
+	s.conversationRepo.UpdateLastMessage(conversationID, message.ID, systemMessage, now)
+}
+
+// newGroupMember สมาชิกใหม่ role member
+func newGroupMember(conversationID, userID uuid.UUID, now time.Time) *models.ConversationMember {
+	return &models.ConversationMember{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		UserID:         userID,
+		Role:           models.RoleMember,
+		JoinedAt:       now,
+	}
+}
+
+// markReviewed บันทึกผลการพิจารณาลงคำขอที่จะคืนให้ client
+func markReviewed(request *models.GroupJoinRequest, status string, reviewedBy uuid.UUID, at time.Time) {
+	request.Status = status
+	request.ReviewedBy = &reviewedBy
+	request.ReviewedAt = &at
+}
+
+// inviteUnusableError เหตุผลที่ลิงก์ใช้ไม่ได้ (nil = ใช้ได้)
+func inviteUnusableError(invite *models.GroupInvite, now time.Time) error {
+	switch {
+	case invite.RevokedAt != nil:
+		return errors.New("invite link has been revoked")
+	case invite.ExpiresAt != nil && !invite.ExpiresAt.After(now):
+		return errors.New("invite link has expired")
+	case invite.MaxUses > 0 && invite.UseCount >= invite.MaxUses:
+		return errors.New("invite link has reached its usage limit")
+	default:
+		return nil
+	}
+}
+
+// generateInviteToken สร้าง token แบบสุ่มสำหรับใส่ใน URL
+func generateInviteToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate invite token: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func buildGroupInviteDTO(invite *models.GroupInvite, now time.Time) *dto.GroupInviteDTO {
+	return &dto.GroupInviteDTO{
+		ID:              invite.ID,
+		ConversationID:  invite.ConversationID,
+		Token:           invite.Token,
+		CreatedBy:       invite.CreatedBy,
+		RequireApproval: invite.RequireApproval,
+		MaxUses:         invite.MaxUses,
+		UseCount:        invite.UseCount,
+		ExpiresAt:       invite.ExpiresAt,
+		RevokedAt:       invite.RevokedAt,
+		IsActive:        invite.IsUsable(now),
+		CreatedAt:       invite.CreatedAt,
+	}
+}
+
+func buildJoinRequestDTO(request *models.GroupJoinRequest, user *models.User) *dto.GroupJoinRequestDTO {
+	result := &dto.GroupJoinRequestDTO{
+		ID:             request.ID,
+		ConversationID: request.ConversationID,
+		UserID:         request.UserID,
+		InviteID:       request.InviteID,
+		Status:         request.Status,
+		ReviewedBy:     request.ReviewedBy,
+		ReviewedAt:     request.ReviewedAt,
+		CreatedAt:      request.CreatedAt,
+	}
+	if user != nil {
+		result.Username = user.Username
+		result.DisplayName = user.DisplayName
+		result.ProfileImageURL = user.ProfileImageURL
+	}
+	return result
+}
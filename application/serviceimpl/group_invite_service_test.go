@@ -0,0 +1,171 @@
+// application/serviceimpl/group_invite_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+func TestJoinByInviteLinkRespectsUsageLimitAndRevocation(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob)
+
+	// สมาชิกธรรมดาสร้างลิงก์ไม่ได้ (invite ค่าเริ่มต้นเป็น admin)
+	_, err := f.inviteService.CreateInvite(group.ID, bob.ID, &dto.CreateGroupInviteRequest{})
+	expectError(t, err, "you do not have permission to invite members")
+
+	invite, err := f.inviteService.CreateInvite(group.ID, alice.ID, &dto.CreateGroupInviteRequest{MaxUses: 1})
+	mustNoError(t, err)
+	if invite.Token == "" || !invite.IsActive {
+		t.Fatalf("unexpected invite %+v", invite)
+	}
+
+	_, err = f.inviteService.JoinByToken(invite.Token, bob.ID)
+	expectError(t, err, "you are already a member of this conversation")
+
+	result, err := f.inviteService.JoinByToken(invite.Token, carol.ID)
+	mustNoError(t, err)
+	if result.Status != dto.JoinStatusJoined || result.ConversationID != group.ID {
+		t.Fatalf("unexpected join result %+v", result)
+	}
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, carol.ID); !isMember {
+		t.Fatal("carol should be a member after joining")
+	}
+	conversation, err := f.conversationRepo.GetByID(group.ID)
+	mustNoError(t, err)
+	if conversation.LastMessageText != "Carol joined the group via invite link" {
+		t.Fatalf("unexpected system message %q", conversation.LastMessageText)
+	}
+
+	_, err = f.inviteService.JoinByToken(invite.Token, dave.ID)
+	expectError(t, err, "invite link has reached its usage limit")
+
+	unlimited, err := f.inviteService.CreateInvite(group.ID, alice.ID, &dto.CreateGroupInviteRequest{})
+	mustNoError(t, err)
+	_, err = f.inviteService.RevokeInvite(group.ID, unlimited.ID, alice.ID)
+	mustNoError(t, err)
+	_, err = f.inviteService.JoinByToken(unlimited.Token, dave.ID)
+	expectError(t, err, "invite link has been revoked")
+
+	invites, err := f.inviteService.ListInvites(group.ID, alice.ID)
+	mustNoError(t, err)
+	if len(invites) != 2 || invites[0].IsActive || invites[1].IsActive {
+		t.Fatalf("expected two inactive invites, got %+v", invites)
+	}
+
+	_, err = f.inviteService.JoinByToken("no-such-token", dave.ID)
+	expectError(t, err, "invite not found")
+}
+
+func TestJoinRequestNeedsApproval(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob)
+
+	invite, err := f.inviteService.CreateInvite(group.ID, alice.ID, &dto.CreateGroupInviteRequest{RequireApproval: true})
+	mustNoError(t, err)
+
+	result, err := f.inviteService.JoinByToken(invite.Token, carol.ID)
+	mustNoError(t, err)
+	if result.Status != dto.JoinStatusPending || result.JoinRequest == nil {
+		t.Fatalf("expected a pending join request, got %+v", result)
+	}
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, carol.ID); isMember {
+		t.Fatal("carol should not be a member before approval")
+	}
+
+	_, err = f.inviteService.JoinByToken(invite.Token, carol.ID)
+	expectError(t, err, "you already have a pending request to join this group")
+
+	// แจ้งเฉพาะผู้ที่อนุมัติได้
+	f.notificationService.NotifyJoinRequestCreated(group.ID, result.JoinRequest)
+	events := f.ws.EventsOfType("join_request.created")
+	if len(events) != 1 || len(events[0].UserIDs) != 1 || events[0].UserIDs[0] != alice.ID {
+		t.Fatalf("expected join_request.created for alice only, got %+v", events)
+	}
+
+	_, err = f.inviteService.ApproveJoinRequest(group.ID, result.JoinRequest.ID, bob.ID)
+	expectError(t, err, "you do not have permission to invite members")
+
+	approved, err := f.inviteService.ApproveJoinRequest(group.ID, result.JoinRequest.ID, alice.ID)
+	mustNoError(t, err)
+	if approved.Status != "approved" || approved.ReviewedBy == nil || *approved.ReviewedBy != alice.ID {
+		t.Fatalf("unexpected approved request %+v", approved)
+	}
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, carol.ID); !isMember {
+		t.Fatal("carol should be a member after approval")
+	}
+
+	_, err = f.inviteService.RejectJoinRequest(group.ID, result.JoinRequest.ID, alice.ID)
+	expectError(t, err, "join request has already been reviewed")
+
+	// ปฏิเสธ: ไม่ได้เป็นสมาชิก และไม่อยู่ในรายการที่รออนุมัติอีก
+	result, err = f.inviteService.JoinByToken(invite.Token, dave.ID)
+	mustNoError(t, err)
+	_, err = f.inviteService.RejectJoinRequest(group.ID, result.JoinRequest.ID, alice.ID)
+	mustNoError(t, err)
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, dave.ID); isMember {
+		t.Fatal("dave should not be a member after rejection")
+	}
+
+	pending, err := f.inviteService.ListJoinRequests(group.ID, alice.ID, "")
+	mustNoError(t, err)
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending requests, got %+v", pending)
+	}
+	all, err := f.inviteService.ListJoinRequests(group.ID, alice.ID, "all")
+	mustNoError(t, err)
+	if len(all) != 2 || all[0].DisplayName != "Carol" {
+		t.Fatalf("unexpected join requests %+v", all)
+	}
+}
+
+func TestApprovalInviteCountsOnlyApprovedRequests(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	erin := f.createUser("erin")
+	group := f.createConversation("group", alice)
+
+	invite, err := f.inviteService.CreateInvite(group.ID, alice.ID, &dto.CreateGroupInviteRequest{RequireApproval: true, MaxUses: 1})
+	mustNoError(t, err)
+
+	// ยื่นคำขอหรือถูกปฏิเสธไม่นับการใช้ลิงก์
+	erinRequest, err := f.inviteService.JoinByToken(invite.Token, erin.ID)
+	mustNoError(t, err)
+	_, err = f.inviteService.RejectJoinRequest(group.ID, erinRequest.JoinRequest.ID, alice.ID)
+	mustNoError(t, err)
+	carolRequest, err := f.inviteService.JoinByToken(invite.Token, carol.ID)
+	mustNoError(t, err)
+	daveRequest, err := f.inviteService.JoinByToken(invite.Token, dave.ID)
+	mustNoError(t, err)
+
+	_, err = f.inviteService.ApproveJoinRequest(group.ID, carolRequest.JoinRequest.ID, alice.ID)
+	mustNoError(t, err)
+
+	_, err = f.inviteService.ApproveJoinRequest(group.ID, daveRequest.JoinRequest.ID, alice.ID)
+	expectError(t, err, "invite link has reached its usage limit")
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, dave.ID); isMember {
+		t.Fatal("dave should not join once the invite is used up")
+	}
+	pending, err := f.inviteService.ListJoinRequests(group.ID, alice.ID, "")
+	mustNoError(t, err)
+	if len(pending) != 1 || pending[0].UserID != dave.ID {
+		t.Fatalf("expected dave's request to stay pending, got %+v", pending)
+	}
+
+	invites, err := f.inviteService.ListInvites(group.ID, alice.ID)
+	mustNoError(t, err)
+	if len(invites) != 1 || invites[0].UseCount != 1 || invites[0].IsActive {
+		t.Fatalf("expected one counted use, got %+v", invites)
+	}
+}
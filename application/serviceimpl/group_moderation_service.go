@@ -0,0 +1,277 @@
+// application/serviceimpl/group_moderation_service.go
+package serviceimpl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+const (
+	MaxSlowModeSeconds = 60 * 60 // 1 ชั่วโมง
+	maxBanReasonLength = 500
+)
+
+type groupModerationService struct {
+	banRepo          repository.GroupBanRepository
+	conversationRepo repository.ConversationRepository
+	userRepo         repository.UserRepository
+	messageRepo      repository.MessageRepository
+}
+
+// NewGroupModerationService สร้าง service ใหม่
+func NewGroupModerationService(
+	banRepo repository.GroupBanRepository,
+	conversationRepo repository.ConversationRepository,
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+) service.GroupModerationService {
+	return &groupModerationService{
+		banRepo:          banRepo,
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// BanMember แบนผู้ใช้ออกจากกลุ่ม ถ้ายังเป็นสมาชิกจะถูกนำออกด้วย
+func (s *groupModerationService) BanMember(conversationID, actorID uuid.UUID, input *dto.BanMemberRequest) (*dto.BanMemberResultDTO, error) {
+	if input.UserID == uuid.Nil {
+		return nil, errors.New("user_id is required")
+	}
+	if len(input.Reason) > maxBanReasonLength {
+		return nil, errors.New("reason must be at most 500 characters")
+	}
+
+	now := time.Now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	actor, err := s.requireModerator(conversationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if input.UserID == actorID {
+		return nil, errors.New("you cannot moderate yourself")
+	}
+
+	user, err := s.userRepo.FindByID(input.UserID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// ผู้ใช้ที่ยังเป็นสมาชิกต้องมี role ต่ำกว่าผู้แบน (ผู้ที่ไม่ใช่สมาชิกแล้วแบนล่วงหน้าได้)
+	target, _ := s.conversationRepo.GetMember(conversationID, input.UserID)
+	if target != nil && !actor.Outranks(target) {
+		return nil, errors.New("you can only moderate members with a lower role")
+	}
+
+	ban := &models.GroupBan{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		UserID:         input.UserID,
+		BannedBy:       actorID,
+		Reason:         input.Reason,
+		ExpiresAt:      input.ExpiresAt,
+		CreatedAt:      now,
+	}
+	if err := s.banRepo.Upsert(ban); err != nil {
+		return nil, fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	removed := false
+	if target != nil {
+		if err := s.conversationRepo.RemoveMember(conversationID, input.UserID); err != nil {
+			return nil, errors.New("error removing member: " + err.Error())
+		}
+		removed = true
+		s.postSystemMessage(conversationID, memberName(s.userRepo, actorID)+" banned "+memberName(s.userRepo, input.UserID)+" from the group", now)
+	}
+
+	return &dto.BanMemberResultDTO{
+		Ban:           buildGroupBanDTO(ban, user),
+		RemovedMember: removed,
+	}, nil
+}
+
+// UnbanMember ยกเลิกแบน
+func (s *groupModerationService) UnbanMember(conversationID, actorID, targetID uuid.UUID) error {
+	if _, err := s.requireModerator(conversationID, actorID); err != nil {
+		return err
+	}
+
+	deleted, err := s.banRepo.Delete(conversationID, targetID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+	if !deleted {
+		return errors.New("ban not found")
+	}
+	return nil
+}
+
+// ListBans ดึงรายการแบนที่ยังมีผล
+func (s *groupModerationService) ListBans(conversationID, actorID uuid.UUID) ([]*dto.GroupBanDTO, error) {
+	if _, err := s.requireModerator(conversationID, actorID); err != nil {
+		return nil, err
+	}
+
+	bans, err := s.banRepo.ListActive(conversationID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bans: %w", err)
+	}
+
+	result := make([]*dto.GroupBanDTO, 0, len(bans))
+	for _, ban := range bans {
+		result = append(result, buildGroupBanDTO(ban, ban.User))
+	}
+	return result, nil
+}
+
+// RestrictMember จำกัดไม่ให้สมาชิกส่งข้อความจนถึงเวลาที่กำหนด
+func (s *groupModerationService) RestrictMember(conversationID, actorID, targetID uuid.UUID, until time.Time) (*dto.MemberRestrictionDTO, error) {
+	if !until.After(time.Now()) {
+		return nil, errors.New("until must be in the future")
+	}
+
+	if _, err := s.requireModeratableMember(conversationID, actorID, targetID); err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationRepo.SetSendRestriction(conversationID, targetID, &until); err != nil {
+		return nil, fmt.Errorf("failed to restrict member: %w", err)
+	}
+
+	return &dto.MemberRestrictionDTO{
+		ConversationID:      conversationID,
+		UserID:              targetID,
+		SendRestrictedUntil: &until,
+	}, nil
+}
+
+// UnrestrictMember ยกเลิกการจำกัดการส่งข้อความ
+func (s *groupModerationService) UnrestrictMember(conversationID, actorID, targetID uuid.UUID) (*dto.MemberRestrictionDTO, error) {
+	target, err := s.requireModeratableMember(conversationID, actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.SendRestrictedUntil == nil || !target.SendRestrictedUntil.After(time.Now()) {
+		return nil, errors.New("member is not restricted")
+	}
+
+	if err := s.conversationRepo.SetSendRestriction(conversationID, targetID, nil); err != nil {
+		return nil, fmt.Errorf("failed to unrestrict member: %w", err)
+	}
+
+	return &dto.MemberRestrictionDTO{
+		ConversationID: conversationID,
+		UserID:         targetID,
+	}, nil
+}
+
+// SetSlowMode ตั้งค่า slow mode ของกลุ่ม คืนค่าเดิม
+func (s *groupModerationService) SetSlowMode(conversationID, actorID uuid.UUID, seconds int) (int, error) {
+	if seconds < 0 || seconds > MaxSlowModeSeconds {
+		return 0, errors.New("slow mode must be between 0 and 3600 seconds")
+	}
+
+	if _, err := s.requireModerator(conversationID, actorID); err != nil {
+		return 0, err
+	}
+
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return 0, errors.New("conversation not found")
+	}
+
+	oldSeconds := conversation.SlowModeSeconds
+	if oldSeconds == seconds {
+		return oldSeconds, nil
+	}
+
+	if err := s.conversationRepo.UpdateConversation(conversationID, types.JSONB{
+		"slow_mode_seconds": seconds,
+	}); err != nil {
+		return 0, err
+	}
+
+	return oldSeconds, nil
+}
+
+// Helper functions
+
+// requireModerator ตรวจว่าเป็นกลุ่มและผู้ใช้มีสิทธิ์ moderate_members คืนค่าสมาชิกของผู้ใช้
+func (s *groupModerationService) requireModerator(conversationID, actorID uuid.UUID) (*models.ConversationMember, error) {
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return nil, errors.New("conversation not found")
+	}
+	if conversation.Type != "group" {
+		return nil, errors.New("moderation is only available for group conversations")
+	}
+
+	_, actor, err := checkPermission(s.conversationRepo, conversationID, actorID, models.PermissionModerateMembers)
+	return actor, err
+}
+
+// requireModeratableMember ตรวจสิทธิ์ของผู้ดูแล และว่าเป้าหมายเป็นสมาชิกที่มี role ต่ำกว่า
+func (s *groupModerationService) requireModeratableMember(conversationID, actorID, targetID uuid.UUID) (*models.ConversationMember, error) {
+	actor, err := s.requireModerator(conversationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if targetID == actorID {
+		return nil, errors.New("you cannot moderate yourself")
+	}
+
+	target, err := s.conversationRepo.GetMember(conversationID, targetID)
+	if err != nil || target == nil {
+		return nil, errors.New("member not found")
+	}
+	if !actor.Outranks(target) {
+		return nil, errors.New("you can only moderate members with a lower role")
+	}
+	return target, nil
+}
+
+// postSystemMessage สร้างข้อความระบบและอัปเดตข้อความล่าสุดของการสนทนา
+func (s *groupModerationService) postSystemMessage(conversationID uuid.UUID, content string, now time.Time) {
+	message := &models.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		MessageType:    "system",
+		Content:        content,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.messageRepo.Create(message); err != nil {
+		fmt.Printf("Warning: Failed to create moderation system message: %v\n", err)
+		return
+	}
+	s.conversationRepo.UpdateLastMessage(conversationID, message.ID, content, now)
+}
+
+func buildGroupBanDTO(ban *models.GroupBan, user *models.User) *dto.GroupBanDTO {
+	result := &dto.GroupBanDTO{
+		ConversationID: ban.ConversationID,
+		UserID:         ban.UserID,
+		BannedBy:       ban.BannedBy,
+		Reason:         ban.Reason,
+		ExpiresAt:      ban.ExpiresAt,
+		CreatedAt:      ban.CreatedAt,
+	}
+	if user != nil {
+		result.Username = user.Username
+		result.DisplayName = user.DisplayName
+		result.ProfileImageURL = user.ProfileImageURL
+	}
+	return result
+}
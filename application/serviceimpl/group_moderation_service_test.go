@@ -0,0 +1,170 @@
+// application/serviceimpl/group_moderation_service_test.go
+package serviceimpl_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+func TestBanBlocksReAddAndInviteJoin(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob, carol)
+	_, err := f.memberService.ChangeRole(group.ID, bob.ID, models.RoleAdmin)
+	mustNoError(t, err)
+
+	// จัดการได้เฉพาะ role ที่ต่ำกว่า
+	_, err = f.moderationService.BanMember(group.ID, carol.ID, &dto.BanMemberRequest{UserID: bob.ID})
+	expectError(t, err, "you do not have permission to moderate members")
+	_, err = f.moderationService.BanMember(group.ID, bob.ID, &dto.BanMemberRequest{UserID: alice.ID})
+	expectError(t, err, "you can only moderate members with a lower role")
+
+	result, err := f.moderationService.BanMember(group.ID, bob.ID, &dto.BanMemberRequest{UserID: carol.ID, Reason: "spam"})
+	mustNoError(t, err)
+	if !result.RemovedMember || result.Ban.Reason != "spam" || result.Ban.ExpiresAt != nil {
+		t.Fatalf("unexpected ban result %+v", result)
+	}
+	if isMember, _ := f.conversationRepo.IsMember(group.ID, carol.ID); isMember {
+		t.Fatal("carol should be removed when banned")
+	}
+
+	_, err = f.memberService.AddMember(alice.ID, group.ID, carol.ID)
+	expectError(t, err, "user is banned from this group")
+
+	invite, err := f.inviteService.CreateInvite(group.ID, alice.ID, &dto.CreateGroupInviteRequest{})
+	mustNoError(t, err)
+	_, err = f.inviteService.JoinByToken(invite.Token, carol.ID)
+	expectError(t, err, "you are banned from this group")
+
+	// แบนผู้ที่ไม่ใช่สมาชิกล่วงหน้าได้ และแบนหมดอายุแล้วเข้ากลุ่มได้
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	result, err = f.moderationService.BanMember(group.ID, bob.ID, &dto.BanMemberRequest{UserID: dave.ID, ExpiresAt: &expiresAt})
+	mustNoError(t, err)
+	if result.RemovedMember {
+		t.Fatal("dave was not a member")
+	}
+
+	bans, err := f.moderationService.ListBans(group.ID, alice.ID)
+	mustNoError(t, err)
+	if len(bans) != 2 || bans[0].UserID != dave.ID || bans[1].DisplayName != "Carol" {
+		t.Fatalf("unexpected bans %+v", bans)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = f.inviteService.JoinByToken(invite.Token, dave.ID)
+	mustNoError(t, err)
+
+	mustNoError(t, f.moderationService.UnbanMember(group.ID, bob.ID, carol.ID))
+	expectError(t, f.moderationService.UnbanMember(group.ID, bob.ID, carol.ID), "ban not found")
+	_, err = f.memberService.AddMember(alice.ID, group.ID, carol.ID)
+	mustNoError(t, err)
+}
+
+func TestSendRestrictionAndSlowMode(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	group := f.createConversation("group", alice, bob, carol)
+
+	until := time.Now().Add(time.Hour)
+	_, err := f.moderationService.RestrictMember(group.ID, alice.ID, carol.ID, until)
+	mustNoError(t, err)
+
+	_, err = f.messageService.SendTextMessage(group.ID, carol.ID, "hello", nil)
+	var restrictErr *service.SendRestrictionError
+	if !errors.As(err, &restrictErr) || restrictErr.Reason != service.SendRestrictionRestricted || !restrictErr.Until.Equal(until) {
+		t.Fatalf("expected restricted error, got %v", err)
+	}
+	// WebSocket ใช้การตรวจเดียวกัน
+	expectError(t, f.memberService.CheckSendAllowed(group.ID, carol.ID, "text"), "you are restricted from sending messages")
+
+	_, err = f.moderationService.UnrestrictMember(group.ID, alice.ID, carol.ID)
+	mustNoError(t, err)
+	f.sendText(group.ID, carol.ID, "back again")
+
+	_, err = f.moderationService.SetSlowMode(group.ID, bob.ID, 30)
+	expectError(t, err, "you do not have permission to moderate members")
+	_, err = f.moderationService.SetSlowMode(group.ID, alice.ID, 7200)
+	expectError(t, err, "slow mode must be between 0 and 3600 seconds")
+	oldSeconds, err := f.moderationService.SetSlowMode(group.ID, alice.ID, 30)
+	mustNoError(t, err)
+	if oldSeconds != 0 {
+		t.Fatalf("expected slow mode to be off before, got %d", oldSeconds)
+	}
+
+	// ข้อความแรกหลังเปิด slow mode ส่งได้ ข้อความถัดไปต้องรอ
+	f.sendText(group.ID, bob.ID, "first")
+	_, err = f.messageService.SendTextMessage(group.ID, bob.ID, "second", nil)
+	if !errors.As(err, &restrictErr) || restrictErr.Reason != service.SendRestrictionSlowMode {
+		t.Fatalf("expected slow mode error, got %v", err)
+	}
+	expectError(t, f.memberService.CheckSendAllowed(group.ID, bob.ID, "text"), "slow mode is enabled")
+
+	// ผู้ที่มีสิทธิ์ moderate_members ไม่ถูกจำกัด
+	f.sendText(group.ID, alice.ID, "one")
+	f.sendText(group.ID, alice.ID, "two")
+
+	_, err = f.moderationService.SetSlowMode(group.ID, alice.ID, 0)
+	mustNoError(t, err)
+	f.sendText(group.ID, bob.ID, "slow mode off")
+}
+
+func TestSlowModeCountsOnlyMessagesThatAreSent(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	dave := f.createUser("dave")
+	group := f.createConversation("group", alice, bob, carol, dave)
+	direct := f.createConversation("direct", carol, alice)
+
+	_, err := f.moderationService.SetSlowMode(group.ID, alice.ID, 30)
+	mustNoError(t, err)
+
+	// ข้อความที่ไม่ผ่านการตรวจไม่นับเป็นการส่ง
+	_, err = f.messageService.SendTextMessage(group.ID, bob.ID, "   ", nil)
+	expectError(t, err, "message content cannot be empty")
+	f.sendText(group.ID, bob.ID, "hello")
+
+	// ส่งต่อหลายข้อความพร้อมกันนับเป็นการส่งครั้งเดียว
+	first := f.sendText(direct.ID, alice.ID, "first")
+	second := f.sendText(direct.ID, alice.ID, "second")
+	forwarded, err := f.messageService.ForwardMessages([]uuid.UUID{first.ID, second.ID}, []uuid.UUID{group.ID}, carol.ID)
+	mustNoError(t, err)
+	if len(forwarded[group.ID]) != 2 {
+		t.Fatalf("expected both messages to be forwarded, got %+v", forwarded)
+	}
+	_, err = f.messageService.SendTextMessage(group.ID, carol.ID, "after forward", nil)
+	expectError(t, err, "slow mode is enabled")
+
+	// ส่งพร้อมกันได้เพียงข้อความเดียว
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sent := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.messageService.SendTextMessage(group.ID, dave.ID, "race", nil); err == nil {
+				mu.Lock()
+				sent++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if sent != 1 {
+		t.Fatalf("expected exactly one message during slow mode, got %d", sent)
+	}
+}
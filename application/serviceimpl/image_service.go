@@ -0,0 +1,233 @@
+// application/serviceimpl/image_service.go
+package serviceimpl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/pkg/imageproc"
+)
+
+// maxProcessImageBytes ขนาดไฟล์สูงสุดที่อ่านเข้าหน่วยความจำเพื่อประมวลผล
+const maxProcessImageBytes = 40 * 1024 * 1024
+
+// stagingPrefix โฟลเดอร์พักรูปจาก presigned upload จนกว่าจะยืนยันและลบ metadata แล้ว
+// path จริงจะถูกเปิดเผยหลังประมวลผลเสร็จเท่านั้น และ PUT URL เดิมเขียนได้แค่ไฟล์พักที่ไม่มีใครอ้างอิง
+const stagingPrefix = "pending/"
+
+type imageService struct {
+	storageService service.FileStorageService
+	fileUploadRepo repository.FileUploadRepository
+	options        imageproc.Options
+}
+
+// NewImageService สร้าง ImageService
+// storage ต้อง implement service.ObjectStore ไม่งั้นทุกการเรียกจะคืน "image processing is not supported by storage"
+func NewImageService(storageService service.FileStorageService, fileUploadRepo repository.FileUploadRepository) service.ImageService {
+	return &imageService{
+		storageService: storageService,
+		fileUploadRepo: fileUploadRepo,
+		options:        imageproc.DefaultOptions(),
+	}
+}
+
+// ProcessUploadedImage ประมวลผลรูปที่อัปโหลดผ่าน multipart แล้วสร้าง FileUpload record ที่ completed
+func (s *imageService) ProcessUploadedImage(userID uuid.UUID, filename, contentType string, result *service.FileUploadResult) (*dto.ProcessedImageDTO, error) {
+	processed, err := s.process(result.Path, result.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	upload := &models.FileUpload{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        processed.Size,
+		Status:      models.FileUploadStatusCompleted,
+		Path:        result.Path,
+		URL:         result.URL,
+		ExpiresAt:   now,
+		CompletedAt: &now,
+	}
+	applyProcessedImage(upload, processed)
+
+	if err := s.fileUploadRepo.Create(upload); err != nil {
+		return nil, fmt.Errorf("failed to save processed image: %w", err)
+	}
+	return processed, nil
+}
+
+// StagingPath path ที่ให้ client PUT รูปเข้ามาก่อนยืนยัน (storage ที่ประมวลผลไม่ได้ใช้ path เดิม)
+func (s *imageService) StagingPath(filePath string) string {
+	if _, ok := s.storageService.(service.ObjectStore); !ok {
+		return filePath
+	}
+	return stagingPrefix + filePath
+}
+
+// ProcessConfirmedUpload ประมวลผลรูปของ upload ที่ยืนยันแล้ว (presigned flow) และอัปเดต record
+// upload ที่พักไว้ใน staging: เขียนผลไปที่ path จริง ลบไฟล์พัก แล้วชี้ Path/URL ไปที่ path จริง
+// (ไฟล์พักถูกลบแม้ประมวลผลไม่สำเร็จ เพราะเป็นไฟล์ที่ยังมี metadata อยู่)
+func (s *imageService) ProcessConfirmedUpload(upload *models.FileUpload) (*dto.ProcessedImageDTO, error) {
+	if !strings.HasPrefix(upload.Path, stagingPrefix) {
+		processed, err := s.process(upload.Path, upload.Path)
+		if err != nil {
+			return nil, err
+		}
+		return processed, s.saveConfirmedUpload(upload, processed)
+	}
+
+	stagedPath := upload.Path
+	finalPath := strings.TrimPrefix(stagedPath, stagingPrefix)
+	processed, err := s.process(stagedPath, finalPath)
+	if delErr := s.storageService.DeleteFile(stagedPath); delErr != nil {
+		log.Printf("Failed to delete staged upload %s: %v", stagedPath, delErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload.Path = finalPath
+	upload.URL = s.storageService.GetPublicURL(finalPath)
+	return processed, s.saveConfirmedUpload(upload, processed)
+}
+
+// saveConfirmedUpload บันทึกผลการประมวลผลลง record ของ presigned upload
+func (s *imageService) saveConfirmedUpload(upload *models.FileUpload, processed *dto.ProcessedImageDTO) error {
+	upload.Size = processed.Size
+	applyProcessedImage(upload, processed)
+	if err := s.fileUploadRepo.Update(upload); err != nil {
+		return fmt.Errorf("failed to save processed image: %w", err)
+	}
+	return nil
+}
+
+// FindProcessedImage ดึงผลการประมวลผลของรูปจาก public URL
+func (s *imageService) FindProcessedImage(url string) (*dto.ProcessedImageDTO, error) {
+	upload, err := s.fileUploadRepo.FindByURL(url)
+	if err != nil || upload.ThumbnailURL == "" {
+		return nil, errors.New("processed image not found")
+	}
+
+	return &dto.ProcessedImageDTO{
+		Width:        upload.Width,
+		Height:       upload.Height,
+		Size:         upload.Size,
+		ThumbnailURL: upload.ThumbnailURL,
+		MediumURL:    upload.MediumURL,
+		BlurHash:     upload.BlurHash,
+	}, nil
+}
+
+// process อ่านรูปจาก srcPath, เขียนต้นฉบับที่ลบ metadata ไปที่ dstPath และอัปโหลด variants ไว้ข้างกัน
+// (photo_ab12cd34.jpg -> photo_ab12cd34_thumb.jpg, photo_ab12cd34_medium.jpg)
+// webp ที่ decode ไม่ได้ถูกตัด EXIF/XMP แบบ lossless โดยไม่มี variants; รูปแบบอื่น (เช่น HEIC) ถูกปฏิเสธ
+func (s *imageService) process(srcPath, dstPath string) (*dto.ProcessedImageDTO, error) {
+	store, ok := s.storageService.(service.ObjectStore)
+	if !ok {
+		return nil, errors.New("image processing is not supported by storage")
+	}
+
+	body, err := store.GetObject(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxProcessImageBytes+1))
+	body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxProcessImageBytes {
+		return nil, errors.New("image is too large to process")
+	}
+
+	result, err := imageproc.Process(data, s.options)
+	if err != nil {
+		switch {
+		case errors.Is(err, imageproc.ErrUnsupportedFormat):
+			return s.stripOnly(store, data, dstPath)
+		case errors.Is(err, imageproc.ErrImageTooLarge):
+			return nil, errors.New("image is too large to process")
+		}
+		return nil, err
+	}
+
+	processed := &dto.ProcessedImageDTO{
+		Width:    result.Width,
+		Height:   result.Height,
+		Size:     int64(len(data)),
+		BlurHash: result.BlurHash,
+	}
+
+	// variants ก่อน ต้นฉบับทีหลัง: ถ้าเขียน variant ไม่สำเร็จ ไฟล์เดิมยังไม่ถูกแตะ
+	thumbnailPath := variantPath(dstPath, "thumb", result.Thumbnail.Ext)
+	if err := store.PutObject(thumbnailPath, result.Thumbnail.ContentType, result.Thumbnail.Data); err != nil {
+		return nil, err
+	}
+	processed.ThumbnailURL = s.storageService.GetPublicURL(thumbnailPath)
+
+	if result.Medium != nil {
+		mediumPath := variantPath(dstPath, "medium", result.Medium.Ext)
+		if err := store.PutObject(mediumPath, result.Medium.ContentType, result.Medium.Data); err != nil {
+			return nil, err
+		}
+		processed.MediumURL = s.storageService.GetPublicURL(mediumPath)
+	}
+
+	if result.Original != nil {
+		if err := store.PutObject(dstPath, result.Original.ContentType, result.Original.Data); err != nil {
+			return nil, err
+		}
+		processed.Size = int64(len(result.Original.Data))
+	} else if srcPath != dstPath {
+		if err := store.PutObject(dstPath, "image/"+result.Format, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return processed, nil
+}
+
+// stripOnly ตัด metadata ของไฟล์ที่ decode ไม่ได้แต่ตัดแบบ lossless ได้ (webp)
+// container ที่ตัดไม่ได้หรือไม่รู้จักคืน "unsupported image format" ให้ผู้เรียกปฏิเสธไฟล์
+func (s *imageService) stripOnly(store service.ObjectStore, data []byte, dstPath string) (*dto.ProcessedImageDTO, error) {
+	format := imageproc.DetectContainer(data)
+	if format != "webp" {
+		return nil, errors.New("unsupported image format")
+	}
+
+	stripped, _, err := imageproc.StripMetadata(data, format)
+	if err != nil {
+		return nil, errors.New("unsupported image format")
+	}
+	if err := store.PutObject(dstPath, "image/webp", stripped); err != nil {
+		return nil, err
+	}
+	return &dto.ProcessedImageDTO{Size: int64(len(stripped))}, nil
+}
+
+// variantPath path ของ variant ในโฟลเดอร์เดียวกับต้นฉบับ
+func variantPath(filePath, suffix, ext string) string {
+	return strings.TrimSuffix(filePath, path.Ext(filePath)) + "_" + suffix + ext
+}
+
+// applyProcessedImage คัดลอกผลการประมวลผลลง FileUpload record
+func applyProcessedImage(upload *models.FileUpload, processed *dto.ProcessedImageDTO) {
+	upload.Width = processed.Width
+	upload.Height = processed.Height
+	upload.ThumbnailURL = processed.ThumbnailURL
+	upload.MediumURL = processed.MediumURL
+	upload.BlurHash = processed.BlurHash
+}
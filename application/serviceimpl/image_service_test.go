@@ -0,0 +1,137 @@
+// application/serviceimpl/image_service_test.go
+package serviceimpl_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// storeJPEG เขียน JPEG ที่มี APP1 EXIF (พร้อมข้อความแทนพิกัด GPS) ลง storage แล้วคืนผลแบบเดียวกับ UploadImage
+func (f *fixture) storeJPEG(path string, width, height int) *service.FileUploadResult {
+	f.t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	mustNoError(f.t, jpeg.Encode(&buf, img, nil))
+
+	payload := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00GPS 13.7563N 100.5018E")
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	data := append(append(append([]byte{}, buf.Bytes()[:2]...), append(segment, payload...)...), buf.Bytes()[2:]...)
+
+	mustNoError(f.t, f.storage.(service.ObjectStore).PutObject(path, "image/jpeg", data))
+	return &service.FileUploadResult{URL: f.storage.GetPublicURL(path), Path: path, Size: len(data)}
+}
+
+// readStored อ่านไฟล์จาก storage ตาม public URL
+func (f *fixture) readStored(url string) []byte {
+	f.t.Helper()
+
+	path := url[len(f.storage.GetPublicURL("")):]
+	body, err := f.storage.(service.ObjectStore).GetObject(path)
+	mustNoError(f.t, err)
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	mustNoError(f.t, err)
+	return data
+}
+
+func TestImage_UploadIsProcessedAndUsedForImageMessages(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	direct := f.createConversation("direct", alice, bob)
+
+	uploaded := f.storeJPEG("images/photo_ab12cd34.jpg", 1600, 1200)
+	processed, err := f.imageService.ProcessUploadedImage(alice.ID, "photo.jpg", "image/jpeg", uploaded)
+	mustNoError(t, err)
+
+	if processed.Width != 1600 || processed.Height != 1200 || processed.BlurHash == "" {
+		t.Fatalf("unexpected pipeline result %+v", processed)
+	}
+	if processed.ThumbnailURL != "https://chat.example.com/storage/images/photo_ab12cd34_thumb.jpg" ||
+		processed.MediumURL != "https://chat.example.com/storage/images/photo_ab12cd34_medium.jpg" {
+		t.Fatalf("unexpected variant URLs %+v", processed)
+	}
+
+	if original := f.readStored(uploaded.URL); bytes.Contains(original, []byte("GPS")) || bytes.Contains(original, []byte("Exif")) {
+		t.Fatal("expected EXIF to be stripped from the stored original")
+	}
+	thumbnail, err := jpeg.DecodeConfig(bytes.NewReader(f.readStored(processed.ThumbnailURL)))
+	mustNoError(t, err)
+	if thumbnail.Width != 320 || thumbnail.Height != 240 {
+		t.Fatalf("thumbnail = %dx%d, want 320x240", thumbnail.Width, thumbnail.Height)
+	}
+
+	// client ไม่ส่ง thumbnail: ใช้ของ server พร้อมขนาดภาพและ blurhash
+	message, err := f.messageService.SendImageMessage(direct.ID, alice.ID, uploaded.URL, "", "", nil)
+	mustNoError(t, err)
+	if message.MediaThumbnailURL != processed.ThumbnailURL {
+		t.Fatalf("expected server thumbnail, got %q", message.MediaThumbnailURL)
+	}
+	if message.Metadata["width"] != 1600 || message.Metadata["blurhash"] != processed.BlurHash || message.Metadata["medium_url"] != processed.MediumURL {
+		t.Fatalf("expected image info in metadata, got %+v", message.Metadata)
+	}
+
+	// ค่าที่ client ส่งมาเองมาก่อนเสมอ
+	message, err = f.messageService.SendImageMessage(direct.ID, alice.ID, uploaded.URL, "https://cdn.example.com/t.jpg", "", map[string]interface{}{"width": 10})
+	mustNoError(t, err)
+	if message.MediaThumbnailURL != "https://cdn.example.com/t.jpg" || message.Metadata["width"] != 10 {
+		t.Fatalf("expected client values to win, got %q %+v", message.MediaThumbnailURL, message.Metadata)
+	}
+}
+
+func TestImage_StagedUploadIsPublishedOnlyAfterStripping(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+
+	staged := f.imageService.StagingPath("uploads/photo_ab12cd34.jpg")
+	if staged == "uploads/photo_ab12cd34.jpg" {
+		t.Fatal("expected images to be staged outside the final path")
+	}
+	f.storeJPEG(staged, 400, 300)
+
+	upload := &models.FileUpload{ID: uuid.New(), UserID: alice.ID, ContentType: "image/jpeg", Status: models.FileUploadStatusPending, Path: staged}
+	mustNoError(t, f.fileUploadRepo.Create(upload))
+
+	_, err := f.imageService.ProcessConfirmedUpload(upload)
+	mustNoError(t, err)
+	if upload.Path != "uploads/photo_ab12cd34.jpg" || upload.URL != f.storage.GetPublicURL(upload.Path) {
+		t.Fatalf("expected upload to point at the final path, got %q %q", upload.Path, upload.URL)
+	}
+	if bytes.Contains(f.readStored(upload.URL), []byte("GPS")) {
+		t.Fatal("expected EXIF to be stripped from the published original")
+	}
+	if _, err := f.storage.(service.ObjectStore).GetObject(staged); err == nil {
+		t.Fatal("expected the staged original to be deleted")
+	}
+}
+
+func TestImage_HEICIsRejected(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+
+	heic := append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heicExifGPS 13.7563N")...)
+	mustNoError(t, f.storage.(service.ObjectStore).PutObject("images/photo.heic", "image/heic", heic))
+	result := &service.FileUploadResult{URL: f.storage.GetPublicURL("images/photo.heic"), Path: "images/photo.heic", Size: len(heic)}
+
+	if _, err := f.imageService.ProcessUploadedImage(alice.ID, "photo.heic", "image/heic", result); err == nil || err.Error() != "unsupported image format" {
+		t.Fatalf("expected HEIC to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// application/serviceimpl/media_access_service.go
+package serviceimpl
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// DefaultMediaURLTTL อายุของ presigned URL ที่ออกให้ client ในโหมด private
+const DefaultMediaURLTTL = time.Hour
+
+type mediaAccessService struct {
+	storageService   service.FileStorageService
+	conversationRepo repository.ConversationRepository
+	private          bool
+	urlTTL           time.Duration
+}
+
+// NewMediaAccessService สร้าง MediaAccessService
+// private = false ทุกเมธอดคืนค่าเดิม (URL ถาวรจาก GetPublicURL แบบที่ผ่านมา)
+func NewMediaAccessService(
+	storageService service.FileStorageService,
+	conversationRepo repository.ConversationRepository,
+	private bool,
+	urlTTL time.Duration,
+) service.MediaAccessService {
+	if urlTTL <= 0 {
+		urlTTL = DefaultMediaURLTTL
+	}
+	return &mediaAccessService{
+		storageService:   storageService,
+		conversationRepo: conversationRepo,
+		private:          private,
+		urlTTL:           urlTTL,
+	}
+}
+
+// IsPrivate เปิดโหมด private media อยู่หรือไม่
+func (s *mediaAccessService) IsPrivate() bool {
+	return s.private
+}
+
+// StorageRef แปลง URL ของ storage เป็น path (ตัด query string ของ presigned URL ออกด้วย)
+// URL ภายนอก เช่น sticker หรือลิงก์ที่ไม่ได้อยู่ใน storage นี้ เก็บตามเดิม
+func (s *mediaAccessService) StorageRef(mediaURL string) string {
+	if !s.private || mediaURL == "" {
+		return mediaURL
+	}
+
+	prefix := s.storageService.GetPublicURL("")
+	if prefix == "" || !strings.HasPrefix(mediaURL, prefix) {
+		return mediaURL
+	}
+
+	path := strings.TrimPrefix(mediaURL, prefix)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return mediaURL
+	}
+	return path
+}
+
+// ResolveURL สร้าง presigned download URL ของ path (ค่าที่เป็น URL อยู่แล้วคืนตามเดิม)
+func (s *mediaAccessService) ResolveURL(ref string) string {
+	if !s.private || !isStorageRef(ref) {
+		return ref
+	}
+
+	signedURL, err := s.storageService.GeneratePresignedDownloadURL(ref, s.urlTTL)
+	if err != nil {
+		log.Printf("Failed to sign media URL for %s: %v", ref, err)
+		return ""
+	}
+	return signedURL
+}
+
+// ResolveMessageMedia ตรวจสมาชิกก่อนออก URL ให้ผู้ใช้
+func (s *mediaAccessService) ResolveMessageMedia(message *dto.MessageDTO, userID uuid.UUID) {
+	if !s.private || message == nil {
+		return
+	}
+
+	isMember, err := s.conversationRepo.IsMember(message.ConversationID, userID)
+	if err != nil || !isMember {
+		s.rewriteMessageMedia(message, func(string) string { return "" })
+		return
+	}
+	s.rewriteMessageMedia(message, s.ResolveURL)
+}
+
+// ResolveMessageMediaForMembers ออก URL โดยไม่ตรวจสมาชิก (payload ถึงเฉพาะสมาชิกอยู่แล้ว)
+func (s *mediaAccessService) ResolveMessageMediaForMembers(message *dto.MessageDTO) {
+	if !s.private || message == nil {
+		return
+	}
+	s.rewriteMessageMedia(message, s.ResolveURL)
+}
+
+// rewriteMessageMedia แทนค่าทุก field ที่อ้างถึงไฟล์ใน storage
+// album_files และ metadata ถูกคัดลอกใหม่ เพราะ DTO ใช้ map ร่วมกับ model
+func (s *mediaAccessService) rewriteMessageMedia(message *dto.MessageDTO, rewrite func(string) string) {
+	message.MediaURL = rewrite(message.MediaURL)
+	message.MediaThumbnailURL = rewrite(message.MediaThumbnailURL)
+
+	if mediumURL, ok := message.Metadata["medium_url"].(string); ok {
+		metadata := make(types.JSONB, len(message.Metadata))
+		for k, v := range message.Metadata {
+			metadata[k] = v
+		}
+		metadata["medium_url"] = rewrite(mediumURL)
+		message.Metadata = metadata
+	}
+
+	// album_files เป็น []map[string]interface{} ตอนสร้าง และ []interface{} หลังอ่านจากฐานข้อมูล
+	var files []map[string]interface{}
+	switch albumFiles := message.AlbumFiles.(type) {
+	case []map[string]interface{}:
+		files = albumFiles
+	case []interface{}:
+		for _, file := range albumFiles {
+			if fileMap, ok := file.(map[string]interface{}); ok {
+				files = append(files, fileMap)
+			}
+		}
+	default:
+		return
+	}
+
+	rewritten := make([]interface{}, 0, len(files))
+	for _, file := range files {
+		copied := make(map[string]interface{}, len(file))
+		for k, v := range file {
+			copied[k] = v
+		}
+		for _, key := range []string{"media_url", "media_thumbnail_url"} {
+			if url, ok := copied[key].(string); ok {
+				copied[key] = rewrite(url)
+			}
+		}
+		rewritten = append(rewritten, copied)
+	}
+	message.AlbumFiles = rewritten
+}
+
+// isStorageRef ค่าที่บันทึกเป็น path ใน storage (ไม่มี scheme)
+func isStorageRef(ref string) bool {
+	return ref != "" && !strings.Contains(ref, "://")
+}
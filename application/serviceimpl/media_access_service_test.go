@@ -0,0 +1,128 @@
+// application/serviceimpl/media_access_service_test.go
+package serviceimpl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/application/serviceimpl"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+func TestMediaAccess_PrivateModeStoresPathAndSignsForMembers(t *testing.T) {
+	f := newFixtureWithMedia(t, true)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	direct := f.createConversation("direct", alice, bob)
+
+	uploaded := f.storeJPEG("images/private_ab12cd34.jpg", 1600, 1200)
+	_, err := f.imageService.ProcessUploadedImage(alice.ID, "photo.jpg", "image/jpeg", uploaded)
+	mustNoError(t, err)
+
+	// ข้อความเก็บเฉพาะ path ไม่มี URL ถาวร
+	message, err := f.messageService.SendImageMessage(direct.ID, alice.ID, uploaded.URL, "", "", nil)
+	mustNoError(t, err)
+	if message.MediaURL != "images/private_ab12cd34.jpg" ||
+		message.MediaThumbnailURL != "images/private_ab12cd34_thumb.jpg" ||
+		message.Metadata["medium_url"] != "images/private_ab12cd34_medium.jpg" {
+		t.Fatalf("expected storage paths, got %q %q %+v", message.MediaURL, message.MediaThumbnailURL, message.Metadata)
+	}
+
+	// สมาชิกได้ URL ที่มีลายเซ็นและอายุ
+	memberView, err := f.conversationService.ConvertToMessageDTO(message, bob.ID)
+	mustNoError(t, err)
+	for _, url := range []string{memberView.MediaURL, memberView.MediaThumbnailURL, memberView.Metadata["medium_url"].(string)} {
+		if !strings.HasPrefix(url, "https://chat.example.com/storage/images/private_ab12cd34") ||
+			!strings.Contains(url, "signature=") || !strings.Contains(url, "expires=") {
+			t.Fatalf("expected signed URL, got %q", url)
+		}
+	}
+	if message.Metadata["medium_url"] != "images/private_ab12cd34_medium.jpg" {
+		t.Fatal("signing must not modify the stored metadata")
+	}
+
+	// ไม่ใช่สมาชิกไม่ได้ URL ใด ๆ
+	outsiderView, err := f.conversationService.ConvertToMessageDTO(message, carol.ID)
+	mustNoError(t, err)
+	if outsiderView.MediaURL != "" || outsiderView.MediaThumbnailURL != "" || outsiderView.Metadata["medium_url"] != "" {
+		t.Fatalf("non-member received media URLs: %q %q %+v", outsiderView.MediaURL, outsiderView.MediaThumbnailURL, outsiderView.Metadata)
+	}
+
+	// อัลบั้มและ payload ของ WebSocket ก็ได้ URL ที่มีลายเซ็น
+	album, err := f.messageService.SendBulkMessages(direct.ID, alice.ID, "", []map[string]interface{}{
+		{"message_type": "image", "media_url": uploaded.URL},
+	})
+	mustNoError(t, err)
+	f.notificationService.NotifyNewMessage(direct.ID, album)
+
+	events := f.ws.EventsOfType("message.receive")
+	if len(events) != 1 {
+		t.Fatalf("expected one message.receive, got %d", len(events))
+	}
+	files, ok := events[0].Data.(*dto.MessageDTO).AlbumFiles.([]interface{})
+	if !ok || len(files) != 1 {
+		t.Fatalf("unexpected album files %#v", events[0].Data.(*dto.MessageDTO).AlbumFiles)
+	}
+	if url := files[0].(map[string]interface{})["media_url"].(string); !strings.Contains(url, "signature=") {
+		t.Fatalf("expected signed album URL, got %q", url)
+	}
+}
+
+func TestMediaAccess_PublicModeKeepsURLs(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	direct := f.createConversation("direct", alice, bob)
+
+	uploaded := f.storeJPEG("images/public_ab12cd34.jpg", 64, 64)
+	message, err := f.messageService.SendImageMessage(direct.ID, alice.ID, uploaded.URL, "", "", nil)
+	mustNoError(t, err)
+
+	view, err := f.conversationService.ConvertToMessageDTO(message, bob.ID)
+	mustNoError(t, err)
+	if message.MediaURL != uploaded.URL || view.MediaURL != uploaded.URL {
+		t.Fatalf("public mode should keep permanent URLs, got %q %q", message.MediaURL, view.MediaURL)
+	}
+}
+
+// countingConversationRepo นับจำนวนครั้งที่ตรวจสมาชิก
+type countingConversationRepo struct {
+	repository.ConversationRepository
+	isMemberCalls int
+}
+
+func (r *countingConversationRepo) IsMember(conversationID, userID uuid.UUID) (bool, error) {
+	r.isMemberCalls++
+	return r.ConversationRepository.IsMember(conversationID, userID)
+}
+
+func TestMediaAccess_PageChecksMembershipOnce(t *testing.T) {
+	f := newFixtureWithMedia(t, true)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	direct := f.createConversation("direct", alice, bob)
+
+	for i := 0; i < 3; i++ {
+		_, err := f.messageService.SendImageMessage(direct.ID, alice.ID, f.storage.GetPublicURL("images/photo.jpg"), "", "", nil)
+		mustNoError(t, err)
+	}
+
+	repo := &countingConversationRepo{ConversationRepository: f.conversationRepo}
+	conversationService := serviceimpl.NewConversationService(repo, f.userRepo, f.messageRepo, nil, nil, nil, f.mediaAccess)
+
+	messages, _, err := conversationService.GetConversationMessages(direct.ID, bob.ID, 20, 0)
+	mustNoError(t, err)
+	if len(messages) != 3 || !strings.Contains(messages[2].MediaURL, "signature=") {
+		t.Fatalf("expected three signed messages, got %d", len(messages))
+	}
+	// หนึ่งครั้งตอนตรวจสิทธิ์อ่านการสนทนา และหนึ่งครั้งก่อนออก URL ของทั้งหน้า
+	if repo.isMemberCalls != 2 {
+		t.Fatalf("IsMember called %d times, want 2", repo.isMemberCalls)
+	}
+}
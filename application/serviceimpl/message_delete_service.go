@@ -2,6 +2,7 @@
 package serviceimpl
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -46,14 +47,63 @@ func (s *messageService) DeleteMessage(messageID, userID uuid.UUID) error {
 	}
 
 	// สร้าง metadata สำหรับประวัติการลบ
-	now := time.Now()
 	metadataObj := map[string]interface{}{
 		"deleted_by_id": userID,
-		"deleted_at":    now.Format(time.RFC3339),
 		"message_type":  message.MessageType,
 	}
 
-	// บันทึกประวัติการลบ
+	if err := s.softDeleteMessage(message, &userID, metadataObj); err != nil {
+		if errors.Is(err, errMessageAlreadyDeleted) {
+			return fmt.Errorf("message is already deleted")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredMessage ลบข้อความที่หมดอายุ (ข้อความหายไปอัตโนมัติ) โดยไม่ตรวจสิทธิ์ผู้ใช้
+func (s *messageService) DeleteExpiredMessage(messageID uuid.UUID) error {
+	message, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return fmt.Errorf("error fetching message: %w", err)
+	}
+
+	if message == nil {
+		return fmt.Errorf("message not found")
+	}
+
+	if message.IsDeleted {
+		return nil
+	}
+
+	if message.ExpiresAt == nil || message.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("message has not expired")
+	}
+
+	metadataObj := map[string]interface{}{
+		"reason":       "expired",
+		"expires_at":   message.ExpiresAt.Format(time.RFC3339),
+		"message_type": message.MessageType,
+	}
+
+	// ระบบเป็นผู้ลบ จึงไม่มีผู้ลบในประวัติ (เหตุผลอยู่ใน metadata)
+	if err := s.softDeleteMessage(message, nil, metadataObj); err != nil && !errors.Is(err, errMessageAlreadyDeleted) {
+		return err
+	}
+	return nil
+}
+
+// errMessageAlreadyDeleted ข้อความถูกลบไปแล้ว (เช่น instance อื่นลบข้อความที่หมดอายุไปก่อน)
+var errMessageAlreadyDeleted = errors.New("message already deleted")
+
+// softDeleteMessage ล้างเนื้อหาข้อความ บันทึกประวัติการลบ อัปเดตข้อความล่าสุดของการสนทนา
+// และส่ง WebSocket event message.deleted
+// deletedBy เป็น nil เมื่อระบบเป็นผู้ลบ คืนค่า errMessageAlreadyDeleted ถ้ามีผู้อื่นลบไปก่อน
+func (s *messageService) softDeleteMessage(message *models.Message, deletedBy *uuid.UUID, metadataObj map[string]interface{}) error {
+	messageID := message.ID
+	now := time.Now()
+	metadataObj["deleted_at"] = now.Format(time.RFC3339)
+
 	deleteHistory := &models.MessageDeleteHistory{
 		ID:                uuid.New(),
 		MessageID:         messageID,
@@ -62,11 +112,7 @@ func (s *messageService) DeleteMessage(messageID, userID uuid.UUID) error {
 		MediaThumbnailURL: message.MediaThumbnailURL,
 		Metadata:          s.convertMetadataToJSON(metadataObj),
 		DeletedAt:         now,
-		DeletedBy:         userID,
-	}
-
-	if err := s.messageRepo.CreateDeleteHistory(deleteHistory); err != nil {
-		fmt.Printf("Failed to save delete history: %v\n", err)
+		DeletedBy:         deletedBy,
 	}
 
 	// "ลบ" ข้อความ (soft delete)
@@ -75,11 +121,31 @@ func (s *messageService) DeleteMessage(messageID, userID uuid.UUID) error {
 	message.MediaURL = ""
 	message.MediaThumbnailURL = ""
 	message.Metadata = types.JSONB{} // empty JSONB
+	message.AlbumFiles = nil
 	message.UpdatedAt = now
 
-	if err := s.messageRepo.Update(message); err != nil {
+	// ใช้ MarkDeleted (ไม่ใช่ Update ที่ข้ามค่าว่าง) และเป็น claim แบบ atomic
+	// เมื่อ sweeper หลาย instance ลบข้อความเดียวกัน มีเพียงรายเดียวที่บันทึกประวัติและส่ง event
+	deleted, err := s.messageRepo.MarkDeleted(messageID, map[string]interface{}{
+		"is_deleted":          true,
+		"content":             "",
+		"media_url":           "",
+		"media_thumbnail_url": "",
+		"metadata":            message.Metadata,
+		"album_files":         nil,
+		"updated_at":          now,
+	})
+	if err != nil {
 		return fmt.Errorf("error updating message: %w", err)
 	}
+	if !deleted {
+		return errMessageAlreadyDeleted
+	}
+
+	// บันทึกประวัติการลบ
+	if err := s.messageRepo.CreateDeleteHistory(deleteHistory); err != nil {
+		fmt.Printf("Failed to save delete history: %v\n", err)
+	}
 
 	// ตรวจสอบว่าเป็นข้อความล่าสุดของการสนทนาหรือไม่ และอัพเดทหากจำเป็น
 	lastMessage, err := s.messageRepo.GetLastMessageByConversation(message.ConversationID)
@@ -164,8 +230,13 @@ func (s *messageService) GetMessageDeleteHistory(messageID, userID uuid.UUID) ([
 
 	// เพิ่มข้อมูลเพิ่มเติมให้แต่ละรายการ
 	for _, deletion := range history {
+		// ระบบเป็นผู้ลบ (ข้อความหมดอายุ) ไม่มีข้อมูลผู้ลบ
+		if deletion.DeletedBy == nil {
+			continue
+		}
+
 		// ดึงข้อมูลผู้ลบ
-		deleter, err := s.userRepo.FindByID(deletion.DeletedBy)
+		deleter, err := s.userRepo.FindByID(*deletion.DeletedBy)
 		if err == nil && deleter != nil {
 			// สร้าง metadata ใหม่ที่มีข้อมูลเพิ่มเติม
 			metadataMap := types.JSONB{}
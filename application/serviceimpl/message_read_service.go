@@ -59,61 +59,13 @@ func (s *messageReadService) MarkMessageAsRead(messageID, userID uuid.UUID) (uui
 		return uuid.Nil, errors.New("you are not a member of this conversation")
 	}
 
-	// ตรวจสอบว่าอ่านแล้วหรือยัง
-	isRead, err := s.messageRepo.IsMessageRead(messageID, userID)
-	if err != nil {
-		return uuid.Nil, err
-	}
-
-	if isRead {
+	// ข้อความระบบ/ข้อความใน thread ไม่มี sequence ไม่ต้องเลื่อน watermark
+	if message.Sequence == 0 {
 		return message.ConversationID, nil
 	}
 
-	// สร้างบันทึกการอ่าน
-	now := time.Now()
-	read := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: messageID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	if err := s.messageReadRepo.CreateRead(read); err != nil {
-		return uuid.Nil, err
-	}
-
-	// อ่านข้อความเก่ากว่าทั้งหมดในการสนทนาเดียวกัน
-	unreadMessages, err := s.messageReadRepo.GetUnreadMessageIDs(message.ConversationID, userID)
-	if err == nil && len(unreadMessages) > 0 {
-		// กรองเอาเฉพาะข้อความที่เก่ากว่า
-		olderMessages := make([]uuid.UUID, 0)
-		for _, msgID := range unreadMessages {
-			msg, err := s.messageRepo.GetByID(msgID)
-			if err != nil {
-				continue
-			}
-
-			if msg.CreatedAt.Before(message.CreatedAt) || msg.CreatedAt.Equal(message.CreatedAt) {
-				olderMessages = append(olderMessages, msgID)
-			}
-		}
-
-		// มาร์คข้อความที่เก่ากว่าทั้งหมดเป็นอ่านแล้ว
-		for _, msgID := range olderMessages {
-			// สร้างบันทึกการอ่าน
-			read := &models.MessageRead{
-				ID:        uuid.New(),
-				MessageID: msgID,
-				UserID:    userID,
-				ReadAt:    now,
-			}
-			s.messageReadRepo.CreateRead(read)
-		}
-	}
-
-	// อัปเดตเวลาอ่านล่าสุดในตาราง conversation_members
-	err = s.conversationRepo.UpdateMemberLastRead(message.ConversationID, userID, message.CreatedAt)
-	if err != nil {
+	// เลื่อน watermark มาที่ข้อความนี้ = อ่านข้อความที่เก่ากว่าทั้งหมดด้วย (ถ้าอ่านแล้วจะไม่ถอยหลัง)
+	if _, err := s.messageReadRepo.AdvanceWatermark(message.ConversationID, userID, message.ID, message.Sequence, message.CreatedAt); err != nil {
 		return uuid.Nil, err
 	}
 
@@ -161,37 +113,17 @@ func (s *messageReadService) MarkAllMessagesAsRead(conversationID, userID uuid.U
 		return 0, errors.New("you are not a member of this conversation")
 	}
 
-	// อัปเดต last_read_at ก่อน
-	now := time.Now()
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		// บันทึกข้อผิดพลาดแต่ไม่หยุดการทำงาน
-	}
-
-	// ดึงข้อความทั้งหมดที่ยังไม่ได้อ่าน
-	unreadMessageIDs, err := s.messageReadRepo.GetUnreadMessageIDs(conversationID, userID)
+	unread, err := s.messageReadRepo.CountUnread(conversationID, userID)
 	if err != nil {
 		return 0, err
 	}
 
-	if len(unreadMessageIDs) == 0 {
-		return 0, nil
-	}
-
-	// มาร์คข้อความทั้งหมดเป็นอ่านแล้ว
-	for _, messageID := range unreadMessageIDs {
-		// สร้างบันทึกการอ่าน
-		read := &models.MessageRead{
-			ID:        uuid.New(),
-			MessageID: messageID,
-			UserID:    userID,
-			ReadAt:    now,
-		}
-		if err := s.messageReadRepo.CreateRead(read); err != nil {
-			// บันทึกข้อผิดพลาดแต่ไม่หยุดการทำงาน
-		}
+	// เลื่อน watermark ไปที่ข้อความล่าสุดของการสนทนา
+	if err := s.messageRepo.MarkAllAsRead(conversationID, userID, time.Now()); err != nil {
+		return 0, err
 	}
 
-	return len(unreadMessageIDs), nil
+	return unread, nil
 }
 
 // GetUnreadCount ดึงจำนวนข้อความที่ยังไม่ได้อ่านในการสนทนา
@@ -207,13 +139,7 @@ func (s *messageReadService) GetUnreadCount(conversationID, userID uuid.UUID) (i
 		return 0, errors.New("you are not a member of this conversation")
 	}
 
-	// ดึงข้อความทั้งหมดที่ยังไม่ได้อ่าน
-	unreadMessageIDs, err := s.messageReadRepo.GetUnreadMessageIDs(conversationID, userID)
-	if err != nil {
-		return 0, err
-	}
-
-	return len(unreadMessageIDs), nil
+	return s.messageReadRepo.CountUnread(conversationID, userID)
 }
 
 // MarkConversationAsRead ทำเครื่องหมายข้อความทั้งหมดจนถึง lastReadMessageID ว่าอ่านแล้ว
@@ -243,93 +169,27 @@ func (s *messageReadService) MarkConversationAsRead(conversationID, userID, last
 		return 0, errors.New("message does not belong to this conversation")
 	}
 
-	// ดึงข้อความทั้งหมดที่ยังไม่ได้อ่าน
-	unreadMessageIDs, err := s.messageReadRepo.GetUnreadMessageIDs(conversationID, userID)
-	if err != nil {
-		return 0, err
-	}
-
-	// มาร์คข้อความที่เก่ากว่าหรือเท่ากับ lastReadMessage เป็นอ่านแล้ว
-	now := time.Now()
-	markedCount := 0
-
-	for _, msgID := range unreadMessageIDs {
-		msg, err := s.messageRepo.GetByID(msgID)
-		if err != nil {
-			continue
-		}
-
-		// ข้ามข้อความของตัวเอง
-		if msg.SenderID != nil && *msg.SenderID == userID {
-			continue
-		}
-
-		// มาร์คเฉพาะข้อความที่เก่ากว่าหรือเท่ากับ lastReadMessage
-		if msg.CreatedAt.Before(lastReadMessage.CreatedAt) || msg.CreatedAt.Equal(lastReadMessage.CreatedAt) {
-			read := &models.MessageRead{
-				ID:        uuid.New(),
-				MessageID: msgID,
-				UserID:    userID,
-				ReadAt:    now,
-			}
-			if err := s.messageReadRepo.CreateRead(read); err == nil {
-				markedCount++
-			}
+	// เลื่อน watermark มาที่ lastReadMessage (ข้อความระบบใช้ sequence ของข้อความก่อนหน้า)
+	if lastReadMessage.ThreadRootID == nil && lastReadMessage.Sequence > 0 {
+		if _, err := s.messageReadRepo.AdvanceWatermark(conversationID, userID, lastReadMessage.ID, lastReadMessage.Sequence, lastReadMessage.CreatedAt); err != nil {
+			return 0, err
 		}
 	}
 
-	// อัปเดต last_read_at
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, lastReadMessage.CreatedAt); err != nil {
-		// บันทึกข้อผิดพลาดแต่ไม่หยุดการทำงาน
-	}
-
 	// คำนวณ unread count ที่เหลือ
-	remainingUnread := len(unreadMessageIDs) - markedCount
-	if remainingUnread < 0 {
-		remainingUnread = 0
-	}
-
-	return remainingUnread, nil
+	return s.messageReadRepo.CountUnread(conversationID, userID)
 }
 
 // GetUnreadCounts ดึงจำนวนข้อความที่ยังไม่ได้อ่านในทุกการสนทนา
 func (s *messageReadService) GetUnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, int, error) {
-	// ดึงการสนทนาทั้งหมดที่ user เป็นสมาชิก (ใช้ limit สูงเพื่อดึงทั้งหมด)
-	conversations, _, err := s.conversationRepo.GetUserConversations(userID, 1000, 0)
+	unreadCounts, err := s.messageReadRepo.GetUnreadCounts(userID)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	unreadCounts := make(map[uuid.UUID]int)
 	totalUnread := 0
-
-	for _, conversation := range conversations {
-		// ดึงจำนวนข้อความที่ยังไม่ได้อ่านในแต่ละการสนทนา
-		unreadMessageIDs, err := s.messageReadRepo.GetUnreadMessageIDs(conversation.ID, userID)
-		if err != nil {
-			continue
-		}
-
-		// นับเฉพาะข้อความที่ไม่ใช่ของตัวเอง
-		count := 0
-		for _, msgID := range unreadMessageIDs {
-			msg, err := s.messageRepo.GetByID(msgID)
-			if err != nil {
-				continue
-			}
-
-			// ข้ามข้อความของตัวเอง
-			if msg.SenderID != nil && *msg.SenderID == userID {
-				continue
-			}
-
-			count++
-		}
-
-		if count > 0 {
-			unreadCounts[conversation.ID] = count
-			totalUnread += count
-		}
+	for _, count := range unreadCounts {
+		totalUnread += count
 	}
 
 	return unreadCounts, totalUnread, nil
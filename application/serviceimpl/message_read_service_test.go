@@ -0,0 +1,201 @@
+// application/serviceimpl/message_read_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+func TestMessageReadReceipts(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	conv := f.createConversation("group", alice, bob)
+
+	first := f.sendText(conv.ID, alice.ID, "one")
+	second := f.sendText(conv.ID, alice.ID, "two")
+	third := f.sendText(conv.ID, alice.ID, "three")
+
+	count, err := f.messageReadService.GetUnreadCount(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if count != 3 {
+		t.Fatalf("expected 3 unread, got %d", count)
+	}
+
+	// ผู้ส่งไม่มีข้อความค้างอ่านของตัวเอง
+	count, err = f.messageReadService.GetUnreadCount(conv.ID, alice.ID)
+	mustNoError(t, err)
+	if count != 0 {
+		t.Fatalf("sender should have 0 unread, got %d", count)
+	}
+
+	// อ่านข้อความที่สองแล้วข้อความที่เก่ากว่าถูกนับว่าอ่านด้วย
+	convID, err := f.messageReadService.MarkMessageAsRead(second.ID, bob.ID)
+	mustNoError(t, err)
+	if convID != conv.ID {
+		t.Fatalf("expected conversation %s, got %s", conv.ID, convID)
+	}
+	for _, message := range []*models.Message{first, second} {
+		read, err := f.messageRepo.IsMessageRead(message.ID, bob.ID)
+		mustNoError(t, err)
+		if !read {
+			t.Fatalf("message %q should be read", message.Content)
+		}
+	}
+
+	ids, err := f.messageReadService.GetUnreadMessageIDs(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if len(ids) != 1 || ids[0] != third.ID {
+		t.Fatalf("expected only the third message unread, got %v", ids)
+	}
+
+	_, err = f.messageReadService.MarkMessageAsRead(second.ID, carol.ID)
+	expectError(t, err, "you are not a member of this conversation")
+
+	marked, err := f.messageReadService.MarkAllMessagesAsRead(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if marked != 1 {
+		t.Fatalf("expected 1 message marked, got %d", marked)
+	}
+
+	counts, total, err := f.messageReadService.GetUnreadCounts(bob.ID)
+	mustNoError(t, err)
+	if total != 0 || counts[conv.ID] != 0 {
+		t.Fatalf("expected no unread left, got %v (total %d)", counts, total)
+	}
+}
+
+func TestMarkConversationAsReadUpToMessage(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	conv := f.createConversation("direct", alice, bob)
+
+	f.sendText(conv.ID, alice.ID, "one")
+	upTo := f.sendText(conv.ID, alice.ID, "two")
+	f.sendText(conv.ID, alice.ID, "three")
+
+	remaining, err := f.messageReadService.MarkConversationAsRead(conv.ID, bob.ID, upTo.ID)
+	mustNoError(t, err)
+	if remaining != 1 {
+		t.Fatalf("expected 1 remaining unread, got %d", remaining)
+	}
+
+	member := f.member(conv.ID, bob.ID)
+	if member.LastReadAt == nil || !member.LastReadAt.Equal(upTo.CreatedAt) {
+		t.Fatalf("last_read_at should follow the last read message, got %v", member.LastReadAt)
+	}
+}
+
+func TestSystemMessagesAreNotCountedAsUnread(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	dave := f.createUser("dave")
+	conv := f.createConversation("group", alice, bob)
+
+	// AddMember สร้างข้อความระบบที่ไม่มีผู้ส่ง
+	_, err := f.memberService.AddMember(alice.ID, conv.ID, dave.ID)
+	mustNoError(t, err)
+
+	count, err := f.messageReadService.GetUnreadCount(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if count != 0 {
+		t.Fatalf("system messages should not be unread, got %d", count)
+	}
+}
+
+func TestReadWatermarkDerivesSeenByAndNeverMovesBack(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	conv := f.createConversation("group", alice, bob, carol)
+
+	first := f.sendText(conv.ID, alice.ID, "one")
+	second := f.sendText(conv.ID, alice.ID, "two")
+	third := f.sendText(conv.ID, alice.ID, "three")
+	if first.Sequence != 1 || third.Sequence != 3 {
+		t.Fatalf("expected sequences 1..3, got %d and %d", first.Sequence, third.Sequence)
+	}
+
+	_, err := f.messageReadService.MarkMessageAsRead(second.ID, bob.ID)
+	mustNoError(t, err)
+	_, err = f.messageReadService.MarkMessageAsRead(third.ID, carol.ID)
+	mustNoError(t, err)
+
+	// อ่านข้อความเก่าซ้ำ watermark ไม่ถอยหลัง
+	_, err = f.messageReadService.MarkMessageAsRead(first.ID, bob.ID)
+	mustNoError(t, err)
+	if member := f.member(conv.ID, bob.ID); member.LastReadSeq != second.Sequence || *member.LastReadMessageID != second.ID {
+		t.Fatalf("watermark should stay at the second message, got seq %d", member.LastReadSeq)
+	}
+
+	// seen by: ผู้ส่ง + สมาชิกที่ watermark ผ่านข้อความแล้ว
+	for message, want := range map[*models.Message]int{first: 3, second: 3, third: 2} {
+		reads, err := f.messageReadService.GetMessageReads(message.ID, alice.ID)
+		mustNoError(t, err)
+		if len(reads) != want {
+			t.Fatalf("message %q: expected %d readers, got %d", message.Content, want, len(reads))
+		}
+	}
+
+	// ส่งข้อความเอง = อ่านทุกข้อความก่อนหน้าแล้ว
+	f.sendText(conv.ID, bob.ID, "four")
+	counts, total, err := f.messageReadService.GetUnreadCounts(bob.ID)
+	mustNoError(t, err)
+	if total != 0 || len(counts) != 0 {
+		t.Fatalf("sender should have no unread, got %v", counts)
+	}
+	count, err := f.messageReadService.GetUnreadCount(conv.ID, alice.ID)
+	mustNoError(t, err)
+	if count != 1 {
+		t.Fatalf("expected 1 unread for alice, got %d", count)
+	}
+}
+
+func TestUnreadCountExcludesDeletedAndExpiredMessages(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	conv := f.createConversation("group", alice, bob)
+
+	first := f.sendText(conv.ID, alice.ID, "one")
+	second := f.sendText(conv.ID, alice.ID, "two")
+	third := f.sendText(conv.ID, alice.ID, "three")
+	fourth := f.sendText(conv.ID, alice.ID, "four")
+
+	_, err := f.messageReadService.MarkMessageAsRead(first.ID, bob.ID)
+	mustNoError(t, err)
+
+	// ข้อความที่อ่านแล้วถูกลบไม่กระทบ unread, ข้อความที่ยังไม่อ่านถูกลบหรือหมดอายุถูกหักออก
+	mustNoError(t, f.messageService.DeleteMessage(first.ID, alice.ID))
+	mustNoError(t, f.messageService.DeleteMessage(second.ID, alice.ID))
+	expiredAt := time.Now().Add(-time.Minute)
+	mustNoError(t, f.messageRepo.UpdateFields(third.ID, map[string]interface{}{"expires_at": &expiredAt}))
+	mustNoError(t, f.messageService.DeleteExpiredMessage(third.ID))
+
+	count, err := f.messageReadService.GetUnreadCount(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if count != 1 {
+		t.Fatalf("expected 1 unread after deletes, got %d", count)
+	}
+	counts, total, err := f.messageReadService.GetUnreadCounts(bob.ID)
+	mustNoError(t, err)
+	if total != 1 || counts[conv.ID] != 1 {
+		t.Fatalf("expected 1 unread in summary, got %v (total %d)", counts, total)
+	}
+
+	// เลื่อน watermark ผ่านข้อความที่ถูกลบแล้วไม่หักซ้ำ
+	fifth := f.sendText(conv.ID, alice.ID, "five")
+	_, err = f.messageReadService.MarkMessageAsRead(fourth.ID, bob.ID)
+	mustNoError(t, err)
+	count, err = f.messageReadService.GetUnreadCount(conv.ID, bob.ID)
+	mustNoError(t, err)
+	if count != 1 {
+		t.Fatalf("expected only %q unread, got %d", fifth.Content, count)
+	}
+}
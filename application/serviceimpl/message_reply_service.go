@@ -38,6 +38,12 @@ func (s *messageService) ReplyToMessage(replyToID, userID uuid.UUID, messageType
 		return nil, fmt.Errorf("you are not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, replyToMessage.ConversationID, userID, messageType)
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบตามประเภทข้อความ
 	switch messageType {
 	case "text":
@@ -69,8 +75,8 @@ func (s *messageService) ReplyToMessage(replyToID, userID uuid.UUID, messageType
 		SenderType:        senderType, // ใช้ค่าที่กำหนดจากเงื่อนไข
 		MessageType:       messageType,
 		Content:           content,
-		MediaURL:          mediaURL,
-		MediaThumbnailURL: thumbnailURL,
+		MediaURL:          s.storageRef(mediaURL),
+		MediaThumbnailURL: s.storageRef(thumbnailURL),
 		ReplyToID:         &replyToID,
 		Metadata:          s.convertMetadataToJSON(metadata),
 		CreatedAt:         now,
@@ -79,7 +85,7 @@ func (s *messageService) ReplyToMessage(replyToID, userID uuid.UUID, messageType
 
 
 	// บันทึกข้อความ
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating message: %w", err)
 	}
 
@@ -111,15 +117,5 @@ func (s *messageService) ReplyToMessage(replyToID, userID uuid.UUID, messageType
 		s.notifyConversationUpdated(replyToMessage.ConversationID, lastMessageText, now, message.ID)
 	}
 
-	// อัปเดตเวลาอ่านล่าสุดของผู้ส่ง
-	if err := s.updateConversationLastRead(replyToMessage.ConversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, replyToMessage.ConversationID.String(), userID)
-	}
-
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	if err := s.createMessageRead(message.ID, userID); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
-	}
-
 	return message, nil
 }
@@ -24,6 +24,12 @@ func (s *messageService) SendTextMessage(conversationID, userID uuid.UUID, conte
 		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "text")
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบเนื้อหาข้อความ
 	if strings.TrimSpace(content) == "" {
 		return nil, fmt.Errorf("message content cannot be empty")
@@ -82,27 +88,10 @@ func (s *messageService) SendTextMessage(conversationID, userID uuid.UUID, conte
 
 
 	// บันทึกข้อความลงในฐานข้อมูล
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating message: %w", err)
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: message.ID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	if err := s.messageReadRepo.CreateRead(messageRead); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
-	}
-
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, conversationID, userID)
-	}
-
 	// อัปเดตข้อความล่าสุดของการสนทนา
 	if err := s.messageRepo.UpdateConversationLastMessage(conversationID, content, now, message.ID); err != nil {
 		fmt.Printf("Error updating conversation last message: %v, conversationID: %s", err, conversationID)
@@ -132,6 +121,12 @@ func (s *messageService) SendStickerMessage(conversationID, userID, stickerID, s
 		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "sticker")
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบ URL สติกเกอร์
 	if mediaURL == "" {
 		return nil, fmt.Errorf("sticker URL is required")
@@ -177,27 +172,10 @@ func (s *messageService) SendStickerMessage(conversationID, userID, stickerID, s
 
 
 	// บันทึกข้อความลงในฐานข้อมูล
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating message: %w", err)
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: message.ID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	if err := s.messageReadRepo.CreateRead(messageRead); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
-	}
-
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, conversationID, userID)
-	}
-
 	// อัปเดตข้อความล่าสุดของการสนทนา
 	if err := s.messageRepo.UpdateConversationLastMessage(conversationID, "[Sticker]", now, message.ID); err != nil {
 		fmt.Printf("Error updating conversation last message: %v, conversationID: %s", err, conversationID)
@@ -222,11 +200,26 @@ func (s *messageService) SendImageMessage(conversationID, userID uuid.UUID, medi
 		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "image")
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบ URL รูปภาพ
 	if mediaURL == "" {
 		return nil, fmt.Errorf("image URL is required")
 	}
 
+	// รูปที่ผ่าน image pipeline แล้ว: ใช้ thumbnail, ขนาดภาพ และ blurhash ของ server เมื่อ client ไม่ได้ส่งมา
+	thumbnailURL, metadata = s.withProcessedImage(mediaURL, thumbnailURL, metadata)
+
+	// โหมด private media: เก็บเฉพาะ path ใน storage
+	mediaURL, thumbnailURL = s.storageRef(mediaURL), s.storageRef(thumbnailURL)
+	if mediumURL, ok := metadata["medium_url"].(string); ok {
+		metadata["medium_url"] = s.storageRef(mediumURL)
+	}
+
 	// ดึงข้อมูลการสนทนา (เพื่อตรวจสอบประเภทการสนทนา)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching conversation: %w", err)
@@ -251,27 +244,10 @@ func (s *messageService) SendImageMessage(conversationID, userID uuid.UUID, medi
 
 
 	// บันทึกข้อความลงในฐานข้อมูล
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating message: %w", err)
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: message.ID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	if err := s.messageReadRepo.CreateRead(messageRead); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
-	}
-
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, conversationID, userID)
-	}
-
 	// อัปเดตข้อความล่าสุดของการสนทนา
 	lastMsgText := "[Image]"
 	if caption != "" {
@@ -301,6 +277,12 @@ func (s *messageService) SendFileMessage(conversationID, userID uuid.UUID, media
 		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "file")
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบ URL ไฟล์
 	if mediaURL == "" {
 		return nil, fmt.Errorf("file URL is required")
@@ -341,7 +323,7 @@ func (s *messageService) SendFileMessage(conversationID, userID uuid.UUID, media
 		SenderType:     "user",
 		MessageType:    "file",
 		Content:        fileName,
-		MediaURL:       mediaURL,
+		MediaURL:       s.storageRef(mediaURL),
 		Metadata:       s.convertMetadataToJSON(fileMetadata),
 		CreatedAt:      now,
 		UpdatedAt:      now,
@@ -350,27 +332,10 @@ func (s *messageService) SendFileMessage(conversationID, userID uuid.UUID, media
 
 
 	// บันทึกข้อความลงในฐานข้อมูล
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating message: %w", err)
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: message.ID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	if err := s.messageReadRepo.CreateRead(messageRead); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
-	}
-
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, conversationID, userID)
-	}
-
 	// อัปเดตข้อความล่าสุดของการสนทนา
 	lastMsgText := "[File]"
 	if fileName != "" {
@@ -400,6 +365,12 @@ func (s *messageService) SendBulkMessages(conversationID, userID uuid.UUID, capt
 		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "album")
+	if err != nil {
+		return nil, err
+	}
+
 	// ตรวจสอบจำนวนไฟล์ (สูงสุด 10 ไฟล์)
 	if len(items) == 0 {
 		return nil, fmt.Errorf("at least one file is required")
@@ -435,8 +406,8 @@ func (s *messageService) SendBulkMessages(conversationID, userID uuid.UUID, capt
 		albumFile := map[string]interface{}{
 			"id":                   uuid.New().String(),
 			"file_type":            fileType,
-			"media_url":            mediaURL,
-			"media_thumbnail_url":  mediaThumbnailURL,
+			"media_url":            s.storageRef(mediaURL),
+			"media_thumbnail_url":  s.storageRef(mediaThumbnailURL),
 			"position":             i,
 		}
 
@@ -499,33 +470,72 @@ func (s *messageService) SendBulkMessages(conversationID, userID uuid.UUID, capt
 	}
 
 	// บันทึก message ลงในฐานข้อมูล
-	if err := s.messageRepo.Create(message); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
 		return nil, fmt.Errorf("error creating album message: %w", err)
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: message.ID,
-		UserID:    userID,
-		ReadAt:    now,
+	// อัปเดตข้อความล่าสุดของการสนทนา
+	lastMsgText := fmt.Sprintf("[Album: %d files]", len(items))
+	if caption != "" {
+		lastMsgText = caption
+	}
+
+	if err := s.messageRepo.UpdateConversationLastMessage(conversationID, lastMsgText, now, message.ID); err != nil {
+		fmt.Printf("Error updating conversation last message: %v, conversationID: %s", err, conversationID)
+	}
+
+	// ส่ง WebSocket event แจ้งการอัปเดต conversation พร้อม mention data
+	s.notifyConversationUpdated(conversationID, lastMsgText, now, message.ID)
+
+	return message, nil
+}
+
+// SendPollMessage ส่งข้อความประเภทโพล (content = คำถาม, ข้อมูลโพลเก็บในตาราง polls)
+func (s *messageService) SendPollMessage(conversationID, userID uuid.UUID, question string, metadata map[string]interface{}) (*models.Message, error) {
+
+	// ตรวจสอบว่าผู้ใช้เป็นสมาชิกของการสนทนา
+	isMember, err := s.conversationRepo.IsMember(conversationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking conversation membership: %w", err)
+	}
+
+	if !isMember {
+		return nil, fmt.Errorf("user is not a member of this conversation")
 	}
 
-	if err := s.messageReadRepo.CreateRead(messageRead); err != nil {
-		fmt.Printf("Error creating read record: %v, messageID: %s, userID: %s", err, message.ID.String(), userID)
+	// ตรวจสอบสิทธิ์ส่งข้อความตาม permission policy ของกลุ่ม
+	slowMode, err := requireSendPermission(s.conversationRepo, conversationID, userID, "poll")
+	if err != nil {
+		return nil, err
 	}
 
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	if err := s.conversationRepo.UpdateMemberLastRead(conversationID, userID, now); err != nil {
-		fmt.Printf("Error updating last read time: %v, conversationID: %s, userID: %s", err, conversationID, userID)
+	if strings.TrimSpace(question) == "" {
+		return nil, fmt.Errorf("poll question is required")
 	}
 
-	// อัปเดตข้อความล่าสุดของการสนทนา
-	lastMsgText := fmt.Sprintf("[Album: %d files]", len(items))
-	if caption != "" {
-		lastMsgText = caption
+	// สร้าง message
+	now := time.Now()
+	message := &models.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       &userID,
+		SenderType:     "user",
+		MessageType:    "poll",
+		Content:        question,
+		Metadata:       s.convertMetadataToJSON(metadata),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		IsDeleted:      false,
 	}
 
+	// บันทึกข้อความลงในฐานข้อมูล
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, message); err != nil {
+		return nil, fmt.Errorf("error creating message: %w", err)
+	}
+
+	// อัปเดตข้อความล่าสุดของการสนทนา
+	lastMsgText := "[Poll] " + question
+
 	if err := s.messageRepo.UpdateConversationLastMessage(conversationID, lastMsgText, now, message.ID); err != nil {
 		fmt.Printf("Error updating conversation last message: %v, conversationID: %s", err, conversationID)
 	}
@@ -535,3 +545,40 @@ func (s *messageService) SendBulkMessages(conversationID, userID uuid.UUID, capt
 
 	return message, nil
 }
+
+// withProcessedImage เติม thumbnail และข้อมูลรูปจาก image pipeline (ค่าที่ client ส่งมาเองมาก่อนเสมอ)
+func (s *messageService) withProcessedImage(mediaURL, thumbnailURL string, metadata map[string]interface{}) (string, map[string]interface{}) {
+	if s.imageService == nil {
+		return thumbnailURL, metadata
+	}
+
+	image, err := s.imageService.FindProcessedImage(mediaURL)
+	if err != nil {
+		return thumbnailURL, metadata
+	}
+
+	if thumbnailURL == "" {
+		thumbnailURL = image.ThumbnailURL
+	}
+
+	merged := map[string]interface{}{
+		"width":    image.Width,
+		"height":   image.Height,
+		"blurhash": image.BlurHash,
+	}
+	if image.MediumURL != "" {
+		merged["medium_url"] = image.MediumURL
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	return thumbnailURL, merged
+}
+
+// storageRef แปลง URL ของไฟล์เป็นค่าที่บันทึกในข้อความ (โหมด private media เก็บเฉพาะ path)
+func (s *messageService) storageRef(mediaURL string) string {
+	if s.mediaAccess == nil {
+		return mediaURL
+	}
+	return s.mediaAccess.StorageRef(mediaURL)
+}
@@ -25,6 +25,8 @@ type messageService struct {
 	userRepo            repository.UserRepository
 	notificationService service.NotificationService
 	mentionRepo         repository.MessageMentionRepository
+	imageService        service.ImageService
+	mediaAccess         service.MediaAccessService
 }
 
 // NewMessageService สร้าง instance ใหม่ของ MessageService
@@ -35,6 +37,8 @@ func NewMessageService(
 	userRepo repository.UserRepository,
 	notificationService service.NotificationService,
 	mentionRepo repository.MessageMentionRepository,
+	imageService service.ImageService,
+	mediaAccess service.MediaAccessService,
 ) service.MessageService {
 	return &messageService{
 		messageRepo:         messageRepo,
@@ -43,42 +47,13 @@ func NewMessageService(
 		userRepo:            userRepo,
 		notificationService: notificationService,
 		mentionRepo:         mentionRepo,
+		imageService:        imageService,
+		mediaAccess:         mediaAccess,
 	}
 }
 
 // CheckBusinessAdmin ตรวจสอบว่าผู้ใช้เป็นแอดมินของธุรกิจหรือไม่
 
-// createMessageRead สร้างบันทึกการอ่านข้อความ
-func (s *messageService) createMessageRead(messageID, userID uuid.UUID) error {
-	// ตรวจสอบว่ามีบันทึกการอ่านแล้วหรือไม่
-
-	isRead, err := s.messageRepo.IsMessageRead(messageID, userID)
-	if err != nil {
-		return err
-	}
-
-	if isRead {
-		return nil // ถ้าอ่านแล้ว ไม่ต้องทำอะไร
-	}
-
-	// สร้างบันทึกการอ่าน
-	now := time.Now()
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: messageID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-
-	return s.messageReadRepo.CreateRead(messageRead)
-}
-
-// updateConversationLastRead อัปเดต last_read_at ในข้อมูลสมาชิกการสนทนา
-func (s *messageService) updateConversationLastRead(conversationID, userID uuid.UUID, readTime time.Time) error {
-
-	return s.conversationRepo.UpdateMemberLastRead(conversationID, userID, readTime)
-}
-
 func (s *messageService) convertMetadataToJSON(metadata map[string]interface{}) types.JSONB {
 	if metadata == nil {
 		return types.JSONB{} // คืนค่า JSONB ที่เป็น empty map
@@ -118,7 +93,7 @@ func (s *messageService) extractLinks(content string) []string {
 	return result
 }
 
-// PinMessage ปักหมุดข้อความ (เฉพาะ owner/admin ที่ pin ได้สำหรับกลุ่ม)
+// PinMessage ปักหมุดข้อความ (ในกลุ่มต้องมีสิทธิ์ pin_message)
 func (s *messageService) PinMessage(messageID, conversationID, userID uuid.UUID) error {
 	// ตรวจสอบว่า message อยู่ในการสนทนานี้
 	message, err := s.messageRepo.GetByID(messageID)
@@ -141,26 +116,11 @@ func (s *messageService) PinMessage(messageID, conversationID, userID uuid.UUID)
 		return errors.New("user is not a member of this conversation")
 	}
 
-	// ดึงข้อมูลการสนทนา
-	conversation, err := s.conversationRepo.GetByID(conversationID)
-	if err != nil {
+	// ตรวจสอบสิทธิ์ปักหมุดตาม permission policy ของกลุ่ม
+	if err := requirePermission(s.conversationRepo, conversationID, userID, models.PermissionPinMessage); err != nil {
 		return err
 	}
 
-	// ถ้าเป็นกลุ่ม ต้องเป็น owner/admin เท่านั้น
-	if conversation.Type == "group" {
-		member, err := s.conversationRepo.GetMember(conversationID, userID)
-		if err != nil {
-			return err
-		}
-		if member == nil {
-			return errors.New("user is not a member of this conversation")
-		}
-		if member.Role != "owner" && member.Role != "admin" {
-			return errors.New("only owner/admin can pin messages in group conversations")
-		}
-	}
-
 	// ปักหมุดข้อความ
 	return s.messageRepo.PinMessage(messageID, userID)
 }
@@ -188,29 +148,11 @@ func (s *messageService) UnpinMessage(messageID, conversationID, userID uuid.UUI
 		return errors.New("user is not a member of this conversation")
 	}
 
-	// ดึงข้อมูลการสนทนา
-	conversation, err := s.conversationRepo.GetByID(conversationID)
-	if err != nil {
-		return err
-	}
-
-	// ถ้าเป็นกลุ่ม ต้องเป็น owner/admin หรือคนที่ pin เอง
-	if conversation.Type == "group" {
-		member, err := s.conversationRepo.GetMember(conversationID, userID)
-		if err != nil {
+	// อนุญาตให้ unpin ได้ถ้ามีสิทธิ์ปักหมุด หรือเป็นคนที่ pin เอง
+	if message.PinnedBy == nil || *message.PinnedBy != userID {
+		if err := requirePermission(s.conversationRepo, conversationID, userID, models.PermissionPinMessage); err != nil {
 			return err
 		}
-		if member == nil {
-			return errors.New("user is not a member of this conversation")
-		}
-
-		// อนุญาตให้ unpin ได้ถ้าเป็น owner/admin หรือคนที่ pin เอง
-		isAuthorized := member.Role == "owner" || member.Role == "admin" ||
-			(message.PinnedBy != nil && *message.PinnedBy == userID)
-
-		if !isAuthorized {
-			return errors.New("only owner/admin or the user who pinned can unpin messages")
-		}
 	}
 
 	// ยกเลิกการปักหมุด
@@ -285,6 +227,7 @@ func (s *messageService) SearchMessages(
 	limit int,
 	cursor *string,
 	direction string,
+	sortBy string,
 ) ([]*models.Message, *string, bool, error) {
 	// ถ้าระบุ conversation_id ให้ตรวจสอบว่า user เป็นสมาชิกหรือไม่
 	if conversationID != nil {
@@ -298,7 +241,7 @@ func (s *messageService) SearchMessages(
 	}
 
 	// ค้นหาข้อความ - ส่ง userID ไปเพื่อ filter เฉพาะ conversations ที่เป็นสมาชิก
-	return s.messageRepo.SearchMessages(query, conversationID, userID, limit, cursor, direction)
+	return s.messageRepo.SearchMessages(query, conversationID, userID, limit, cursor, direction, sortBy)
 }
 
 // notifyMentionedUsers ส่งการแจ้งเตือนและบันทึกลง database
@@ -393,7 +336,7 @@ func (s *messageService) notifyMentionedUsers(message *models.Message, mentions
 			"message_preview": truncateString(message.Content, 100),
 		}
 
-		s.notificationService.SendNotification(mentionedUserIDs, notificationData)
+		s.notificationService.NotifyMention(message.ConversationID, mentionedUserIDs, notificationData)
 	}
 }
 
@@ -407,138 +350,167 @@ func truncateString(s string, maxLen int) string {
 
 // ForwardMessage ส่งต่อข้อความไปยังการสนทนาอื่น
 func (s *messageService) ForwardMessage(messageID, targetConversationID, userID uuid.UUID) (*models.Message, error) {
+	originalMsg, slowMode, err := s.prepareForward(messageID, targetConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	forwarded, err := s.createForwardedMessages([]*models.Message{originalMsg}, targetConversationID, userID, slowMode)
+	if err != nil {
+		return nil, err
+	}
+	return forwarded[0], nil
+}
+
+// ForwardMessages ส่งต่อหลายข้อความไปยังหลายการสนทนา
+// ข้อความทั้งชุดที่ส่งต่อไปยังการสนทนาหนึ่งนับเป็นการส่งครั้งเดียวของ slow mode
+func (s *messageService) ForwardMessages(messageIDs []uuid.UUID, targetConversationIDs []uuid.UUID, userID uuid.UUID) (map[uuid.UUID][]*models.Message, error) {
+	if len(messageIDs) == 0 {
+		return nil, errors.New("no messages to forward")
+	}
+	if len(targetConversationIDs) == 0 {
+		return nil, errors.New("no target conversations specified")
+	}
+
+	// Map สำหรับเก็บผลลัพธ์ [conversationID] => [messages]
+	results := make(map[uuid.UUID][]*models.Message)
+
+	for _, targetConvID := range targetConversationIDs {
+		var originals []*models.Message
+		var slowMode time.Duration
+		for _, msgID := range messageIDs {
+			originalMsg, interval, err := s.prepareForward(msgID, targetConvID, userID)
+			if err != nil {
+				// ถ้า forward ไม่สำเร็จก็ข้ามไป (fail silently หรือจะ log error ก็ได้)
+				continue
+			}
+			originals = append(originals, originalMsg)
+			slowMode = interval
+		}
+		if len(originals) == 0 {
+			continue
+		}
+
+		forwarded, err := s.createForwardedMessages(originals, targetConvID, userID, slowMode)
+		if err != nil {
+			continue
+		}
+
+		// เก็บผลลัพธ์
+		results[targetConvID] = forwarded
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("failed to forward any messages")
+	}
+
+	return results, nil
+}
+
+// prepareForward ดึงข้อความต้นฉบับและตรวจสิทธิ์ของผู้ส่งต่อ คืนช่วง slow mode ของการสนทนาปลายทาง
+func (s *messageService) prepareForward(messageID, targetConversationID, userID uuid.UUID) (*models.Message, time.Duration, error) {
 	// ดึงข้อความต้นฉบับ
 	originalMsg, err := s.messageRepo.GetByID(messageID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if originalMsg == nil {
-		return nil, errors.New("message not found")
+		return nil, 0, errors.New("message not found")
 	}
 
 	// ตรวจสอบว่า user เป็นสมาชิกของการสนทนาต้นทาง
 	isMember, err := s.conversationRepo.IsMember(originalMsg.ConversationID, userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if !isMember {
-		return nil, errors.New("user is not a member of the source conversation")
+		return nil, 0, errors.New("user is not a member of the source conversation")
 	}
 
 	// ตรวจสอบว่า user เป็นสมาชิกของการสนทนาปลายทาง
 	isMember, err = s.conversationRepo.IsMember(targetConversationID, userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if !isMember {
-		return nil, errors.New("user is not a member of the target conversation")
+		return nil, 0, errors.New("user is not a member of the target conversation")
 	}
 
-	// สร้างข้อมูล forwarded_from
-	forwardedFrom := types.JSONB{
-		"message_id":         originalMsg.ID.String(),
-		"conversation_id":    originalMsg.ConversationID.String(),
-		"original_timestamp": originalMsg.CreatedAt.Format(time.RFC3339),
+	// ตรวจสอบสิทธิ์ส่งข้อความในการสนทนาปลายทาง
+	slowMode, err := requireSendPermission(s.conversationRepo, targetConversationID, userID, originalMsg.MessageType)
+	if err != nil {
+		return nil, 0, err
 	}
-	if originalMsg.SenderID != nil {
-		forwardedFrom["sender_id"] = originalMsg.SenderID.String()
+	return originalMsg, slowMode, nil
+}
 
-		// ดึงข้อมูลผู้ส่งต้นฉบับเพื่อเอา sender_name
-		if s.userRepo != nil {
-			originalSender, err := s.userRepo.FindByID(*originalMsg.SenderID)
-			if err == nil && originalSender != nil {
-				senderName := originalSender.DisplayName
-				if senderName == "" {
-					senderName = originalSender.Username
+// createForwardedMessages บันทึกสำเนาของข้อความต้นฉบับในการสนทนาปลายทางพร้อมกัน (นับเป็นการส่งครั้งเดียว)
+func (s *messageService) createForwardedMessages(originals []*models.Message, targetConversationID, userID uuid.UUID, slowMode time.Duration) ([]*models.Message, error) {
+	now := time.Now()
+	forwarded := make([]*models.Message, 0, len(originals))
+	for i, originalMsg := range originals {
+		// สร้างข้อมูล forwarded_from
+		forwardedFrom := types.JSONB{
+			"message_id":         originalMsg.ID.String(),
+			"conversation_id":    originalMsg.ConversationID.String(),
+			"original_timestamp": originalMsg.CreatedAt.Format(time.RFC3339),
+		}
+		if originalMsg.SenderID != nil {
+			forwardedFrom["sender_id"] = originalMsg.SenderID.String()
+
+			// ดึงข้อมูลผู้ส่งต้นฉบับเพื่อเอา sender_name
+			if s.userRepo != nil {
+				originalSender, err := s.userRepo.FindByID(*originalMsg.SenderID)
+				if err == nil && originalSender != nil {
+					senderName := originalSender.DisplayName
+					if senderName == "" {
+						senderName = originalSender.Username
+					}
+					forwardedFrom["sender_name"] = senderName
 				}
-				forwardedFrom["sender_name"] = senderName
 			}
 		}
-	}
 
-	// สร้างข้อความใหม่
-	now := time.Now()
-	forwardedMsg := &models.Message{
-		ID:                uuid.New(),
-		ConversationID:    targetConversationID,
-		SenderID:          &userID,
-		SenderType:        "user",
-		MessageType:       originalMsg.MessageType,
-		Content:           originalMsg.Content,
-		MediaURL:          originalMsg.MediaURL,
-		MediaThumbnailURL: originalMsg.MediaThumbnailURL,
-		AlbumFiles:        originalMsg.AlbumFiles, // Copy album files for album messages
-		Metadata:          originalMsg.Metadata,
-		IsForwarded:       true,
-		ForwardedFrom:     forwardedFrom,
-		CreatedAt:         now,
-		UpdatedAt:         now,
-		IsDeleted:         false,
+		// สร้างข้อความใหม่ (created_at เรียงตามลำดับที่ส่งต่อ)
+		createdAt := now.Add(time.Duration(i) * time.Microsecond)
+		forwarded = append(forwarded, &models.Message{
+			ID:                uuid.New(),
+			ConversationID:    targetConversationID,
+			SenderID:          &userID,
+			SenderType:        "user",
+			MessageType:       originalMsg.MessageType,
+			Content:           originalMsg.Content,
+			MediaURL:          originalMsg.MediaURL,
+			MediaThumbnailURL: originalMsg.MediaThumbnailURL,
+			AlbumFiles:        originalMsg.AlbumFiles, // Copy album files for album messages
+			Metadata:          originalMsg.Metadata,
+			IsForwarded:       true,
+			ForwardedFrom:     forwardedFrom,
+			CreatedAt:         createdAt,
+			UpdatedAt:         createdAt,
+			IsDeleted:         false,
+		})
 	}
 
 	// บันทึกข้อความ
-	if err := s.messageRepo.Create(forwardedMsg); err != nil {
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, forwarded...); err != nil {
 		return nil, err
 	}
 
-	// สร้างบันทึกการอ่านสำหรับผู้ส่ง
-	messageRead := &models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: forwardedMsg.ID,
-		UserID:    userID,
-		ReadAt:    now,
-	}
-	_ = s.messageReadRepo.CreateRead(messageRead)
-
-	// อัปเดต last_read_at สำหรับผู้ส่ง
-	_ = s.conversationRepo.UpdateMemberLastRead(targetConversationID, userID, now)
-
 	// อัปเดตข้อความล่าสุดของการสนทนา
+	last := forwarded[len(forwarded)-1]
 	lastMsgText := "[Forwarded] "
-	if originalMsg.MessageType == "text" {
-		lastMsgText += originalMsg.Content
+	if last.MessageType == "text" {
+		lastMsgText += last.Content
 	} else {
-		lastMsgText += "[" + originalMsg.MessageType + "]"
+		lastMsgText += "[" + last.MessageType + "]"
 	}
-	_ = s.messageRepo.UpdateConversationLastMessage(targetConversationID, lastMsgText, now, forwardedMsg.ID)
+	_ = s.messageRepo.UpdateConversationLastMessage(targetConversationID, lastMsgText, last.CreatedAt, last.ID)
 
 	// ส่ง WebSocket event แจ้งการอัปเดต conversation พร้อม mention data
-	s.notifyConversationUpdated(targetConversationID, lastMsgText, now, forwardedMsg.ID)
-
-	return forwardedMsg, nil
-}
+	s.notifyConversationUpdated(targetConversationID, lastMsgText, last.CreatedAt, last.ID)
 
-// ForwardMessages ส่งต่อหลายข้อความไปยังหลายการสนทนา
-func (s *messageService) ForwardMessages(messageIDs []uuid.UUID, targetConversationIDs []uuid.UUID, userID uuid.UUID) (map[uuid.UUID][]*models.Message, error) {
-	if len(messageIDs) == 0 {
-		return nil, errors.New("no messages to forward")
-	}
-	if len(targetConversationIDs) == 0 {
-		return nil, errors.New("no target conversations specified")
-	}
-
-	// Map สำหรับเก็บผลลัพธ์ [conversationID] => [messages]
-	results := make(map[uuid.UUID][]*models.Message)
-
-	// Forward แต่ละข้อความไปยังทุกการสนทนาปลายทาง
-	for _, msgID := range messageIDs {
-		for _, targetConvID := range targetConversationIDs {
-			forwardedMsg, err := s.ForwardMessage(msgID, targetConvID, userID)
-			if err != nil {
-				// ถ้า forward ไม่สำเร็จก็ข้ามไป (fail silently หรือจะ log error ก็ได้)
-				continue
-			}
-
-			// เก็บผลลัพธ์
-			results[targetConvID] = append(results[targetConvID], forwardedMsg)
-		}
-	}
-
-	if len(results) == 0 {
-		return nil, errors.New("failed to forward any messages")
-	}
-
-	return results, nil
+	return forwarded, nil
 }
 
 // notifyConversationUpdated ส่ง WebSocket event แจ้งการอัปเดต conversation พร้อม mention data
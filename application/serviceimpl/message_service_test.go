@@ -0,0 +1,166 @@
+// application/serviceimpl/message_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSendTextMessage(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	conv := f.createConversation("group", alice, bob)
+
+	message := f.sendText(conv.ID, alice.ID, "hello bob")
+
+	stored, err := f.messageRepo.GetByID(message.ID)
+	mustNoError(t, err)
+	if stored == nil || stored.Content != "hello bob" || stored.Status != "sent" {
+		t.Fatalf("message not stored correctly: %+v", stored)
+	}
+
+	updated, err := f.conversationRepo.GetByID(conv.ID)
+	mustNoError(t, err)
+	if updated.LastMessageID == nil || *updated.LastMessageID != message.ID || updated.LastMessageText != "hello bob" {
+		t.Fatalf("conversation last message not updated: %+v", updated)
+	}
+
+	// ผู้ส่งถือว่าอ่านข้อความของตัวเองแล้ว
+	read, err := f.messageRepo.IsMessageRead(message.ID, alice.ID)
+	mustNoError(t, err)
+	if !read {
+		t.Fatal("sender should have a read receipt for their own message")
+	}
+
+	// conversation.update ส่งให้สมาชิกทุกคนแบบรายบุคคล
+	for _, user := range []uuid.UUID{alice.ID, bob.ID} {
+		found := false
+		for _, event := range f.ws.EventsForUser(user) {
+			if event.Type == "conversation.update" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("member %s did not receive conversation.update", user)
+		}
+	}
+	if len(f.ws.EventsForUser(carol.ID)) != 0 {
+		t.Fatal("non-member should not receive events")
+	}
+
+	_, err = f.messageService.SendTextMessage(conv.ID, carol.ID, "let me in", nil)
+	expectError(t, err, "user is not a member of this conversation")
+
+	_, err = f.messageService.SendTextMessage(conv.ID, alice.ID, "   ", nil)
+	expectError(t, err, "message content cannot be empty")
+}
+
+func TestEditMessage(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	conv := f.createConversation("group", alice, bob)
+
+	message := f.sendText(conv.ID, alice.ID, "first draft")
+
+	_, err := f.messageService.EditMessage(message.ID, bob.ID, "hijacked", nil)
+	expectError(t, err, "only message owner can edit messages")
+
+	edited, err := f.messageService.EditMessage(message.ID, alice.ID, "second draft", nil)
+	mustNoError(t, err)
+	if !edited.IsEdited || edited.EditCount != 1 || edited.Content != "second draft" {
+		t.Fatalf("unexpected edited message: %+v", edited)
+	}
+
+	stored, err := f.messageRepo.GetByID(message.ID)
+	mustNoError(t, err)
+	if stored.Content != "second draft" || !stored.IsEdited {
+		t.Fatalf("edit not persisted: %+v", stored)
+	}
+
+	history, err := f.messageService.GetMessageEditHistory(message.ID, bob.ID)
+	mustNoError(t, err)
+	if len(history) != 1 || history[0].PreviousContent != "first draft" {
+		t.Fatalf("unexpected edit history: %+v", history)
+	}
+
+	_, err = f.messageService.EditMessage(uuid.New(), alice.ID, "ghost", nil)
+	expectError(t, err, "message not found")
+}
+
+func TestDeleteMessage(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	conv := f.createConversation("group", alice, bob, carol)
+
+	first := f.sendText(conv.ID, bob.ID, "keep me")
+	second := f.sendText(conv.ID, bob.ID, "delete me")
+
+	// สมาชิกทั่วไปลบข้อความของผู้อื่นไม่ได้
+	err := f.messageService.DeleteMessage(second.ID, carol.ID)
+	expectError(t, err, "only message owner or conversation admin can delete messages")
+
+	f.ws.Reset()
+	mustNoError(t, f.messageService.DeleteMessage(second.ID, bob.ID))
+
+	stored, err := f.messageRepo.GetByID(second.ID)
+	mustNoError(t, err)
+	if !stored.IsDeleted || stored.Content != "" {
+		t.Fatalf("message not soft deleted: %+v", stored)
+	}
+
+	// ข้อความล่าสุดของการสนทนาย้อนกลับไปเป็นข้อความก่อนหน้า
+	updated, err := f.conversationRepo.GetByID(conv.ID)
+	mustNoError(t, err)
+	if updated.LastMessageID == nil || *updated.LastMessageID != first.ID || updated.LastMessageText != "keep me" {
+		t.Fatalf("last message not restored: %+v", updated)
+	}
+
+	if events := f.ws.EventsOfType("message.delete"); len(events) != 1 || events[0].ConversationID != conv.ID {
+		t.Fatalf("expected one message.delete event, got %+v", events)
+	}
+
+	err = f.messageService.DeleteMessage(second.ID, bob.ID)
+	expectError(t, err, "message is already deleted")
+
+	// admin ลบข้อความของผู้อื่นได้ และมีประวัติการลบ
+	mustNoError(t, f.messageService.DeleteMessage(first.ID, alice.ID))
+	history, err := f.messageService.GetMessageDeleteHistory(first.ID, alice.ID)
+	mustNoError(t, err)
+	if len(history) != 1 || history[0].Content != "keep me" || history[0].DeletedBy == nil || *history[0].DeletedBy != alice.ID {
+		t.Fatalf("unexpected delete history: %+v", history)
+	}
+}
+
+func TestDeleteExpiredMessage(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	conv := f.createConversation("group", alice, bob)
+
+	msg := f.sendText(conv.ID, bob.ID, "disappearing")
+	expiredAt := time.Now().Add(-time.Minute)
+	mustNoError(t, f.messageRepo.UpdateFields(msg.ID, map[string]interface{}{"expires_at": &expiredAt}))
+
+	f.ws.Reset()
+	mustNoError(t, f.messageService.DeleteExpiredMessage(msg.ID))
+	// sweeper ของ instance อื่นลบซ้ำ ไม่ error และไม่ส่ง event ซ้ำ
+	mustNoError(t, f.messageService.DeleteExpiredMessage(msg.ID))
+
+	if events := f.ws.EventsOfType("message.delete"); len(events) != 1 {
+		t.Fatalf("expected one message.delete event, got %+v", events)
+	}
+
+	// ระบบเป็นผู้ลบ ไม่ใช่ผู้ส่ง
+	history, err := f.messageService.GetMessageDeleteHistory(msg.ID, alice.ID)
+	mustNoError(t, err)
+	if len(history) != 1 || history[0].DeletedBy != nil || history[0].Metadata["reason"] != "expired" {
+		t.Fatalf("unexpected delete history: %+v", history)
+	}
+}
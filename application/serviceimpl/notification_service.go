@@ -2,7 +2,6 @@
 package serviceimpl
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -20,6 +19,8 @@ type notificationService struct {
 	userRepo            repository.UserRepository
 	messageRepo         repository.MessageRepository
 	conversationRepo    repository.ConversationRepository
+	pushService         service.PushService // ส่ง push ให้ผู้รับที่ออฟไลน์ (nil = ปิด)
+	mediaAccess         service.MediaAccessService // ออก URL ของไฟล์ในโหมด private media (nil = ปิด)
 }
 
 // NewNotificationService สร้าง instance ใหม่ของ NotificationService
@@ -28,12 +29,16 @@ func NewNotificationService(
 	userRepo repository.UserRepository,
 	messageRepo repository.MessageRepository,
 	conversationRepo repository.ConversationRepository,
+	pushService service.PushService,
+	mediaAccess service.MediaAccessService,
 ) service.NotificationService {
 	return &notificationService{
 		wsPort:              wsPort,
 		userRepo:            userRepo,
 		messageRepo:         messageRepo,
 		conversationRepo:    conversationRepo,
+		pushService:         pushService,
+		mediaAccess:         mediaAccess,
 	}
 }
 
@@ -94,6 +99,11 @@ func (s *notificationService) NotifyNewMessage(conversationID uuid.UUID, message
 		IsEdited:          message.IsEdited,
 		EditCount:         message.EditCount,
 		ReplyToID:         message.ReplyToID,
+		ThreadRootID:      message.ThreadRootID,
+		ThreadReplyCount:  message.ThreadReplyCount,
+		ThreadLastReplyAt: message.ThreadLastReplyAt,
+		ExpiresAt:         message.ExpiresAt,
+		Sequence:          message.Sequence,
 		IsRead:            readCount >= 1,
 		ReadCount:         readCount,
 		Status:            status,
@@ -144,9 +154,12 @@ func (s *notificationService) NotifyNewMessage(conversationID uuid.UUID, message
 
 
 	// เพิ่มข้อมูลการตอบกลับ (ถ้ามี)
+	var repliedToUserID *uuid.UUID
 	if message.ReplyToID != nil {
 		replyMsg, err := s.messageRepo.GetByID(*message.ReplyToID)
 		if err == nil && replyMsg != nil {
+			repliedToUserID = replyMsg.SenderID
+
 			replyInfo := &dto.ReplyInfoDTO{
 				ID:          replyMsg.ID.String(),
 				MessageType: replyMsg.MessageType,
@@ -196,11 +209,168 @@ func (s *notificationService) NotifyNewMessage(conversationID uuid.UUID, message
 		messageDTO.ForwardedFrom = forwardedFrom
 	}
 
-	data, _ := json.MarshalIndent(messageDTO, "", "  ")
-	fmt.Println("[DEBUGXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX] CHECK REPLY TO MESSAGE messageDTO:", string(data))
+	// payload ถึงเฉพาะสมาชิกของการสนทนา จึงออก URL ของไฟล์ได้โดยไม่ต้องตรวจซ้ำ
+	if s.mediaAccess != nil {
+		s.mediaAccess.ResolveMessageMediaForMembers(messageDTO)
+	}
 
-	// ส่งแจ้งเตือนผ่าน WebSocket
+	// ส่งแจ้งเตือนผ่าน WebSocket (ทุกคนได้รับ message.receive สำหรับ sync)
 	s.wsPort.BroadcastNewMessage(message.ConversationID, messageDTO)
+
+	// แจ้งเตือนแบบ alert เฉพาะสมาชิกที่การตั้งค่าการแจ้งเตือนอนุญาต
+	s.sendMessageAlerts(message, messageDTO.SenderName, repliedToUserID)
+}
+
+// sendMessageAlerts ส่ง notification (type "message") ให้สมาชิกที่ควรได้รับ alert ตาม NotificationSettings
+// สมาชิกที่ถูก mention ไม่รวมในที่นี้ เพราะได้รับ notification type "mention" ผ่าน NotifyMention แล้ว
+func (s *notificationService) sendMessageAlerts(message *models.Message, senderName string, repliedToUserID *uuid.UUID) {
+	// ข้อความระบบไม่ต้องแจ้งเตือน
+	if message.SenderID == nil || s.conversationRepo == nil {
+		return
+	}
+
+	members, err := s.conversationRepo.GetMembers(message.ConversationID)
+	if err != nil {
+		return
+	}
+
+	mentioned := mentionedUserIDs(message.Metadata)
+	now := time.Now()
+
+	recipients := make(map[string][]uuid.UUID)
+	for _, member := range members {
+		if member.UserID == *message.SenderID || mentioned[member.UserID] {
+			continue
+		}
+
+		repliedTo := repliedToUserID != nil && *repliedToUserID == member.UserID
+		reason := member.AlertReason(now, false, repliedTo, message.Content)
+		if reason == "" {
+			continue
+		}
+		recipients[reason] = append(recipients[reason], member.UserID)
+	}
+
+	for reason, userIDs := range recipients {
+		s.wsPort.BroadcastNotification(userIDs, map[string]interface{}{
+			"type":            "message",
+			"reason":          reason,
+			"message_id":      message.ID.String(),
+			"conversation_id": message.ConversationID.String(),
+			"sender_id":       message.SenderID.String(),
+			"sender_name":     senderName,
+			"message_type":    message.MessageType,
+			"message_preview": truncateString(message.Content, 100),
+		})
+	}
+
+	// ผู้รับที่ไม่ได้เชื่อมต่อ WebSocket จะได้รับ push แทน
+	if s.pushService != nil && len(recipients) > 0 {
+		push := s.messagePush(message, senderName, "message")
+		for _, userIDs := range recipients {
+			s.pushService.NotifyOffline(userIDs, push)
+		}
+	}
+}
+
+// messagePush สร้าง push ของข้อความ (รวมตาม conversation ด้วย CollapseKey)
+// กลุ่มใช้ชื่อกลุ่มเป็นหัวข้อ ส่วนแชทส่วนตัวใช้ชื่อผู้ส่ง
+func (s *notificationService) messagePush(message *models.Message, senderName, pushType string) *dto.PushNotification {
+	preview := truncateString(message.Content, 100)
+	if preview == "" {
+		preview = "[" + message.MessageType + "]"
+	}
+
+	title, body := senderName, preview
+	if conversation, err := s.conversationRepo.GetByID(message.ConversationID); err == nil && conversation != nil &&
+		conversation.Type == "group" && conversation.Title != "" {
+		title, body = conversation.Title, senderName+": "+preview
+	}
+	if pushType == "mention" {
+		body = senderName + " mentioned you: " + preview
+	}
+
+	return &dto.PushNotification{
+		Title: title,
+		Body:  body,
+		Data: map[string]string{
+			"type":            pushType,
+			"conversation_id": message.ConversationID.String(),
+			"message_id":      message.ID.String(),
+		},
+		CollapseKey: "conv:" + message.ConversationID.String(),
+	}
+}
+
+// NotifyMention ส่ง notification การถูก mention เฉพาะสมาชิกที่ไม่ได้ปิดเสียงหรือปิดการแจ้งเตือน
+func (s *notificationService) NotifyMention(conversationID uuid.UUID, userIDs []uuid.UUID, notification interface{}) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	allowed := make([]uuid.UUID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		member, err := s.conversationRepo.GetMember(conversationID, userID)
+		if err != nil || member == nil {
+			continue
+		}
+		if member.AlertReason(now, true, false, "") != "" {
+			allowed = append(allowed, userID)
+		}
+	}
+
+	if len(allowed) > 0 {
+		s.wsPort.BroadcastNotification(allowed, notification)
+
+		if s.pushService != nil {
+			s.pushService.NotifyOffline(allowed, s.mentionPush(conversationID, notification))
+		}
+	}
+}
+
+// mentionPush สร้าง push จากข้อมูล notification ของ mention (map จาก messageService.notifyMentionedUsers)
+func (s *notificationService) mentionPush(conversationID uuid.UUID, notification interface{}) *dto.PushNotification {
+	data, _ := notification.(map[string]interface{})
+	senderName, _ := data["sender_name"].(string)
+	preview, _ := data["message_preview"].(string)
+	messageID, _ := data["message_id"].(string)
+
+	message := &models.Message{ConversationID: conversationID, Content: preview, MessageType: "text"}
+	if id, err := uuid.Parse(messageID); err == nil {
+		message.ID = id
+	}
+	return s.messagePush(message, senderName, "mention")
+}
+
+// mentionedUserIDs อ่าน user_id ของผู้ที่ถูก mention จาก metadata["mentions"]
+// (รองรับทั้ง array และ {"data": array} แบบเดียวกับ messageService.notifyMentionedUsers)
+func mentionedUserIDs(metadata map[string]interface{}) map[uuid.UUID]bool {
+	result := make(map[uuid.UUID]bool)
+	if metadata == nil {
+		return result
+	}
+
+	var mentions []interface{}
+	switch v := metadata["mentions"].(type) {
+	case []interface{}:
+		mentions = v
+	case map[string]interface{}:
+		mentions, _ = v["data"].([]interface{})
+	}
+
+	for _, mention := range mentions {
+		mentionMap, ok := mention.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if userIDStr, ok := mentionMap["user_id"].(string); ok {
+			if userID, err := uuid.Parse(userIDStr); err == nil {
+				result[userID] = true
+			}
+		}
+	}
+	return result
 }
 
 // NotifyMessageRead แจ้งเตือนการอ่านข้อความ (เก่า - broadcast ไปทุกคน)
@@ -248,6 +418,19 @@ func (s *notificationService) NotifyMessageReaction(conversationID uuid.UUID, re
 	s.wsPort.BroadcastMessageReaction(conversationID, reaction)
 }
 
+// NotifyThreadReply แจ้งเตือนข้อความตอบกลับใน thread (เฉพาะผู้ติดตาม thread)
+func (s *notificationService) NotifyThreadReply(followerIDs []uuid.UUID, reply interface{}) {
+	if len(followerIDs) == 0 {
+		return
+	}
+	s.wsPort.BroadcastThreadReply(followerIDs, reply)
+}
+
+// NotifyPollUpdated แจ้งเตือนผลโหวตล่าสุดของโพล
+func (s *notificationService) NotifyPollUpdated(conversationID uuid.UUID, poll interface{}) {
+	s.wsPort.BroadcastPollUpdated(conversationID, poll)
+}
+
 // =========== Conversation Notifications ===========
 
 // NotifyConversationCreated แจ้งเตือนการสร้างการสนทนาใหม่
@@ -618,6 +801,35 @@ func (s *notificationService) NotifyOwnershipTransferred(conversationID, previou
 	s.wsPort.BroadcastOwnershipTransferred(conversationID, notificationData)
 }
 
+// =========== Group Invite Notifications ===========
+
+// NotifyJoinRequestCreated แจ้งคำขอเข้ากลุ่มใหม่ไปยังสมาชิกที่อนุมัติคำขอได้ (ตามสิทธิ์ invite ของกลุ่ม)
+func (s *notificationService) NotifyJoinRequestCreated(conversationID uuid.UUID, request interface{}) {
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation == nil {
+		return
+	}
+	members, err := s.conversationRepo.GetMembers(conversationID)
+	if err != nil {
+		return
+	}
+
+	adminIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		if conversation.MemberCan(member, models.PermissionInvite) {
+			adminIDs = append(adminIDs, member.UserID)
+		}
+	}
+	if len(adminIDs) > 0 {
+		s.wsPort.BroadcastJoinRequestCreated(adminIDs, request)
+	}
+}
+
+// NotifyJoinRequestReviewed แจ้งผลการพิจารณาคำขอเข้ากลุ่มไปยังผู้ขอ
+func (s *notificationService) NotifyJoinRequestReviewed(userID uuid.UUID, request interface{}) {
+	s.wsPort.BroadcastJoinRequestReviewed(userID, request)
+}
+
 // NotifyNewActivity แจ้งเตือน activity ใหม่ในกลุ่ม
 func (s *notificationService) NotifyNewActivity(conversationID uuid.UUID, activity *dto.ActivityDTO) {
 	// ส่ง activity ไปยังสมาชิกทุกคนในกลุ่ม
@@ -0,0 +1,166 @@
+// application/serviceimpl/notification_service_test.go
+package serviceimpl_test
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// alertedUsers รวม user ที่ได้รับ notification ตาม type ("message" / "mention") และ reason
+func (f *fixture) alertedUsers(notificationType string) map[uuid.UUID]string {
+	f.t.Helper()
+
+	result := make(map[uuid.UUID]string)
+	for _, e := range f.ws.EventsOfType("notification") {
+		data, ok := e.Data.(map[string]interface{})
+		if !ok || data["type"] != notificationType {
+			continue
+		}
+		reason, _ := data["reason"].(string)
+		for _, userID := range e.UserIDs {
+			result[userID] = reason
+		}
+	}
+	return result
+}
+
+func TestNotifyNewMessage_HonoursNotificationSettings(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")     // ค่าเริ่มต้น: แจ้งเตือนทุกข้อความ
+	carol := f.createUser("carol") // mentions only
+	dave := f.createUser("dave")   // mentions only + keyword
+	erin := f.createUser("erin")   // ปิดเสียงชั่วคราว
+	frank := f.createUser("frank") // ปิดการแจ้งเตือน
+	group := f.createConversation("group", alice, bob, carol, dave, erin, frank)
+
+	mutedUntil := time.Now().Add(time.Hour)
+	settings := map[uuid.UUID]*dto.NotificationSettingsRequest{
+		carol.ID: {Level: "mentions"},
+		dave.ID:  {Level: "mentions", Keywords: []string{" Deploy "}},
+		erin.ID:  {MutedUntil: &mutedUntil},
+		frank.ID: {Level: "none"},
+	}
+	for userID, input := range settings {
+		_, err := f.conversationService.UpdateNotificationSettings(group.ID, userID, input)
+		mustNoError(t, err)
+	}
+
+	message := f.sendText(group.ID, alice.ID, "Ready to DEPLOY?")
+	f.notificationService.NotifyNewMessage(group.ID, message)
+
+	// ทุกคนยังได้รับ message.receive สำหรับ sync
+	if len(f.ws.EventsOfType("message.receive")) != 1 {
+		t.Fatal("expected message.receive to be broadcast to the conversation")
+	}
+
+	alerted := f.alertedUsers("message")
+	want := map[uuid.UUID]string{bob.ID: "message", dave.ID: "keyword"}
+	if len(alerted) != len(want) {
+		t.Fatalf("expected alerts for %v, got %v", want, alerted)
+	}
+	for userID, reason := range want {
+		if alerted[userID] != reason {
+			t.Fatalf("expected %s alert for %s, got %q", reason, userID, alerted[userID])
+		}
+	}
+}
+
+func TestNotifyMention_SkipsMutedMembers(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+	group := f.createConversation("group", alice, bob, carol)
+
+	_, err := f.conversationService.UpdateNotificationSettings(group.ID, bob.ID, &dto.NotificationSettingsRequest{Level: "mentions"})
+	mustNoError(t, err)
+	mustNoError(t, f.conversationService.SetMuteStatus(group.ID, carol.ID, true))
+
+	metadata := map[string]interface{}{
+		"mentions": []interface{}{
+			map[string]interface{}{"user_id": bob.ID.String()},
+			map[string]interface{}{"user_id": carol.ID.String()},
+		},
+	}
+	message, err := f.messageService.SendTextMessage(group.ID, alice.ID, "@bob @carol lunch?", metadata)
+	mustNoError(t, err)
+	f.notificationService.NotifyNewMessage(group.ID, message)
+
+	mentioned := f.alertedUsers("mention")
+	if _, ok := mentioned[bob.ID]; !ok || len(mentioned) != 1 {
+		t.Fatalf("expected only bob to get a mention alert, got %v", mentioned)
+	}
+	// ผู้ที่ถูก mention ไม่ได้รับ alert ซ้ำแบบ "message"
+	if alerted := f.alertedUsers("message"); len(alerted) != 0 {
+		t.Fatalf("expected no message alerts, got %v", alerted)
+	}
+
+	// เปิดเสียงแล้วการปิดเสียงชั่วคราวถูกล้างด้วย
+	mustNoError(t, f.conversationService.SetMuteStatus(group.ID, carol.ID, false))
+	status, err := f.conversationService.GetNotificationSettings(group.ID, carol.ID)
+	mustNoError(t, err)
+	if status.IsMuted {
+		t.Fatal("expected carol to be unmuted")
+	}
+}
+
+// captureOutput เก็บทุกอย่างที่ fn เขียนลง stdout และ log
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	mustNoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer func() {
+		os.Stdout = stdout
+		log.SetOutput(os.Stderr)
+	}()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-done + logs.String()
+}
+
+func TestNotifyNewMessage_DoesNotPrintSignedURLs(t *testing.T) {
+	f := newFixtureWithMedia(t, true)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	direct := f.createConversation("direct", alice, bob)
+
+	message, err := f.messageService.SendImageMessage(direct.ID, alice.ID, f.storage.GetPublicURL("images/photo.jpg"), "", "", nil)
+	mustNoError(t, err)
+
+	output := captureOutput(t, func() {
+		f.notificationService.NotifyNewMessage(direct.ID, message)
+	})
+
+	events := f.ws.EventsOfType("message.receive")
+	if len(events) != 1 || !strings.Contains(events[0].Data.(*dto.MessageDTO).MediaURL, "signature=") {
+		t.Fatal("expected message.receive with a signed media URL")
+	}
+	if strings.Contains(output, "signature=") || strings.Contains(output, "images/photo") {
+		t.Fatalf("signed URL written to process output: %s", output)
+	}
+}
@@ -22,6 +22,7 @@ type pinnedMessageService struct {
 	messageRepo      repository.MessageRepository
 	conversationRepo repository.ConversationRepository
 	wsPort           port.WebSocketPort
+	mediaAccess      service.MediaAccessService
 }
 
 // NewPinnedMessageService creates a new pinned message service
@@ -30,12 +31,14 @@ func NewPinnedMessageService(
 	messageRepo repository.MessageRepository,
 	conversationRepo repository.ConversationRepository,
 	wsPort port.WebSocketPort,
+	mediaAccess service.MediaAccessService,
 ) service.PinnedMessageService {
 	return &pinnedMessageService{
 		pinnedRepo:       pinnedRepo,
 		messageRepo:      messageRepo,
 		conversationRepo: conversationRepo,
 		wsPort:           wsPort,
+		mediaAccess:      mediaAccess,
 	}
 }
 
@@ -70,23 +73,12 @@ func (s *pinnedMessageService) PinMessage(ctx context.Context, conversationID, m
 		return nil, errors.New("user is not a member of this conversation")
 	}
 
-	// For public pins, check permissions (only owner/admin in group)
+	// For public pins, check the group's pin_message permission
 	if pinType == models.PinTypePublic {
-		conversation, err := s.conversationRepo.GetByID(conversationID)
-		if err != nil {
+		if err := requirePermission(s.conversationRepo, conversationID, userID, models.PermissionPinMessage); err != nil {
 			return nil, err
 		}
 
-		if conversation.Type == "group" {
-			member, err := s.conversationRepo.GetMember(conversationID, userID)
-			if err != nil {
-				return nil, err
-			}
-			if member == nil || (member.Role != "owner" && member.Role != "admin") {
-				return nil, errors.New("only owner/admin can create public pins in group conversations")
-			}
-		}
-
 		// Check max public pins limit
 		publicCount, err := s.pinnedRepo.GetPublicPinnedCount(ctx, conversationID)
 		if err != nil {
@@ -160,22 +152,11 @@ func (s *pinnedMessageService) UnpinMessage(ctx context.Context, conversationID,
 		return s.pinnedRepo.Delete(ctx, messageID, userID, pinType)
 	}
 
-	// For public pins, check permissions
-	conversation, err := s.conversationRepo.GetByID(conversationID)
-	if err != nil {
+	// For public pins, check the group's pin_message permission
+	if err := requirePermission(s.conversationRepo, conversationID, userID, models.PermissionPinMessage); err != nil {
 		return err
 	}
 
-	if conversation.Type == "group" {
-		member, err := s.conversationRepo.GetMember(conversationID, userID)
-		if err != nil {
-			return err
-		}
-		if member == nil || (member.Role != "owner" && member.Role != "admin") {
-			return errors.New("only owner/admin can remove public pins in group conversations")
-		}
-	}
-
 	// Delete the public pin (any user who created it)
 	if err := s.pinnedRepo.Delete(ctx, messageID, userID, pinType); err != nil {
 		return err
@@ -270,6 +251,11 @@ func (s *pinnedMessageService) toPinnedMessageDTO(pm *models.PinnedMessage) *dto
 				ProfileImageURL: pm.Message.Sender.ProfileImageURL,
 			}
 		}
+
+		// callers check membership before building the DTO
+		if s.mediaAccess != nil {
+			s.mediaAccess.ResolveMessageMediaForMembers(result.Message)
+		}
 	}
 
 	return result
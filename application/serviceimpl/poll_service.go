@@ -0,0 +1,452 @@
+// application/serviceimpl/poll_service.go
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// Poll limits
+const (
+	minPollOptions        = 2
+	maxPollOptions        = 10
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 100
+	pollCloseBatchSize    = 100
+)
+
+type pollService struct {
+	pollRepo            repository.PollRepository
+	messageRepo         repository.MessageRepository
+	conversationRepo    repository.ConversationRepository
+	messageService      service.MessageService
+	conversationService service.ConversationService
+	notificationService service.NotificationService
+}
+
+// NewPollService creates a new poll service
+func NewPollService(
+	pollRepo repository.PollRepository,
+	messageRepo repository.MessageRepository,
+	conversationRepo repository.ConversationRepository,
+	messageService service.MessageService,
+	conversationService service.ConversationService,
+	notificationService service.NotificationService,
+) service.PollService {
+	return &pollService{
+		pollRepo:            pollRepo,
+		messageRepo:         messageRepo,
+		conversationRepo:    conversationRepo,
+		messageService:      messageService,
+		conversationService: conversationService,
+		notificationService: notificationService,
+	}
+}
+
+// CreatePoll creates a poll message and its options
+func (s *pollService) CreatePoll(ctx context.Context, conversationID, userID uuid.UUID, req *dto.CreatePollRequest) (*dto.MessageDTO, error) {
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, errors.New("poll question is required")
+	}
+	if utf8.RuneCountInString(question) > maxPollQuestionLength {
+		return nil, errors.New("poll question is too long")
+	}
+
+	if len(req.Options) < minPollOptions || len(req.Options) > maxPollOptions {
+		return nil, errors.New("poll must have between 2 and 10 options")
+	}
+
+	pollID := uuid.New()
+	options := make([]*models.PollOption, 0, len(req.Options))
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, errors.New("poll options must not be empty")
+		}
+		if utf8.RuneCountInString(text) > maxPollOptionLength {
+			return nil, errors.New("poll option is too long")
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return nil, errors.New("poll options must be unique")
+		}
+		seen[key] = true
+
+		options = append(options, &models.PollOption{
+			ID:       uuid.New(),
+			PollID:   pollID,
+			Text:     text,
+			Position: i,
+		})
+	}
+
+	now := time.Now()
+	if req.ClosesAt != nil && !req.ClosesAt.After(now) {
+		return nil, errors.New("poll close time must be in the future")
+	}
+
+	metadata := map[string]interface{}{"poll_id": pollID.String()}
+	if req.TempID != "" {
+		metadata["temp_id"] = req.TempID
+	}
+
+	message, err := s.messageService.SendPollMessage(conversationID, userID, question, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	poll := &models.Poll{
+		ID:             pollID,
+		MessageID:      message.ID,
+		ConversationID: conversationID,
+		CreatorID:      userID,
+		Question:       question,
+		AllowMultiple:  req.AllowMultiple,
+		IsAnonymous:    req.IsAnonymous,
+		ClosesAt:       req.ClosesAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Options:        options,
+	}
+	if err := s.pollRepo.Create(ctx, poll); err != nil {
+		// ไม่ให้เหลือข้อความโพลที่ไม่มีข้อมูลโพล
+		if delErr := s.messageService.DeleteMessage(message.ID, userID); delErr != nil {
+			log.Printf("Failed to delete poll message %s after poll creation failed: %v", message.ID, delErr)
+		}
+		return nil, err
+	}
+
+	messageDTO, err := s.conversationService.ConvertToMessageDTO(message, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.notificationService != nil {
+		s.notificationService.NotifyNewMessage(conversationID, messageDTO)
+	}
+
+	return messageDTO, nil
+}
+
+// GetPoll gets a poll with its current tally
+func (s *pollService) GetPoll(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error) {
+	poll, err := s.getPollForMember(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.pollRepo.GetVotes(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPollDTO(poll, votes, userID), nil
+}
+
+// Vote replaces the user's votes on a poll and broadcasts the new tally
+func (s *pollService) Vote(ctx context.Context, pollID, userID uuid.UUID, optionIDs []uuid.UUID) (*dto.PollDTO, error) {
+	if len(optionIDs) == 0 {
+		return nil, errors.New("at least one option is required")
+	}
+
+	poll, err := s.getOpenPoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := make(map[uuid.UUID]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+
+	selected := make([]uuid.UUID, 0, len(optionIDs))
+	seen := make(map[uuid.UUID]bool, len(optionIDs))
+	for _, optionID := range optionIDs {
+		if !valid[optionID] {
+			return nil, errors.New("invalid poll option")
+		}
+		if seen[optionID] {
+			continue
+		}
+		seen[optionID] = true
+		selected = append(selected, optionID)
+	}
+
+	if !poll.AllowMultiple && len(selected) > 1 {
+		return nil, errors.New("poll allows only one option")
+	}
+
+	open, err := s.pollRepo.SetVotes(ctx, poll.ID, userID, selected)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, errors.New("poll is closed")
+	}
+
+	return s.refreshTally(ctx, poll, userID)
+}
+
+// RetractVote removes the user's votes on a poll and broadcasts the new tally
+func (s *pollService) RetractVote(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error) {
+	poll, err := s.getOpenPoll(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.pollRepo.DeleteVotes(ctx, poll.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, errors.New("poll is closed")
+	}
+
+	return s.refreshTally(ctx, poll, userID)
+}
+
+// ClosePoll closes a poll and stores its final results
+func (s *pollService) ClosePoll(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error) {
+	poll, err := s.getPollForMember(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.CreatorID != userID {
+		member, err := s.conversationRepo.GetMember(poll.ConversationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil || (member.Role != models.RoleOwner && member.Role != models.RoleAdmin) {
+			return nil, errors.New("only the poll creator or an admin can close this poll")
+		}
+	}
+
+	result, err := s.closePoll(ctx, poll, &userID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errors.New("poll is already closed")
+	}
+
+	votes, err := s.pollRepo.GetVotes(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	// result ถูกส่งผ่าน WebSocket ไปแล้ว จึงคัดลอกก่อนเพิ่ม my_votes
+	mine := *result
+	mine.MyVotes = myPollVotes(votes, userID)
+
+	return &mine, nil
+}
+
+// CloseExpiredPolls closes polls whose close time has passed
+func (s *pollService) CloseExpiredPolls(ctx context.Context) (int, error) {
+	polls, err := s.pollRepo.FindDueForClose(ctx, time.Now(), pollCloseBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, due := range polls {
+		poll, err := s.pollRepo.GetByID(ctx, due.ID)
+		if err != nil || poll == nil {
+			log.Printf("Failed to load poll %s for closing: %v", due.ID, err)
+			continue
+		}
+
+		result, err := s.closePoll(ctx, poll, nil)
+		if err != nil {
+			log.Printf("Failed to close poll %s: %v", poll.ID, err)
+			continue
+		}
+		if result != nil {
+			closed++
+		}
+	}
+
+	return closed, nil
+}
+
+// closePoll marks the poll closed, stores the result snapshot and broadcasts it.
+// Returns nil if another request closed the poll first.
+func (s *pollService) closePoll(ctx context.Context, poll *models.Poll, closedBy *uuid.UUID) (*dto.PollDTO, error) {
+	now := time.Now()
+	closed := *poll
+	closed.ClosedAt = &now
+	closed.ClosedBy = closedBy
+
+	// การปิดโพลและ snapshot ผลลัพธ์อยู่ใน transaction เดียวกัน ผลโหวตที่ได้คือผลสุดท้าย
+	var result *dto.PollDTO
+	ok, err := s.pollRepo.Close(ctx, poll.ID, closedBy, now, func(votes []*models.PollVote) types.JSONB {
+		result = buildPollDTO(&closed, votes, uuid.Nil)
+		result.FinalResults = pollResultSnapshot(result, now)
+		return result.FinalResults
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	*poll = closed
+
+	if s.notificationService != nil {
+		s.notificationService.NotifyPollUpdated(poll.ConversationID, result)
+	}
+
+	return result, nil
+}
+
+// refreshTally broadcasts the current tally and returns it for the voter
+func (s *pollService) refreshTally(ctx context.Context, poll *models.Poll, userID uuid.UUID) (*dto.PollDTO, error) {
+	votes, err := s.pollRepo.GetVotes(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// poll.updated ส่งให้ทุกคนในการสนทนา จึงไม่มี my_votes
+	if s.notificationService != nil {
+		s.notificationService.NotifyPollUpdated(poll.ConversationID, buildPollDTO(poll, votes, uuid.Nil))
+	}
+
+	return buildPollDTO(poll, votes, userID), nil
+}
+
+// getOpenPoll loads a poll that the user can vote on
+func (s *pollService) getOpenPoll(ctx context.Context, pollID, userID uuid.UUID) (*models.Poll, error) {
+	poll, err := s.getPollForMember(ctx, pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageRepo.GetByID(poll.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.IsDeleted {
+		return nil, errors.New("poll not found")
+	}
+
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("poll is closed")
+	}
+
+	return poll, nil
+}
+
+// getPollForMember loads a poll and checks that the user is a member of its conversation
+func (s *pollService) getPollForMember(ctx context.Context, pollID, userID uuid.UUID) (*models.Poll, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, errors.New("poll not found")
+	}
+
+	isMember, err := s.conversationRepo.IsMember(poll.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("user is not a member of this conversation")
+	}
+
+	return poll, nil
+}
+
+// buildPollDTO tallies votes per option. viewerID = uuid.Nil omits my_votes.
+func buildPollDTO(poll *models.Poll, votes []*models.PollVote, viewerID uuid.UUID) *dto.PollDTO {
+	voters := make(map[uuid.UUID][]uuid.UUID, len(poll.Options))
+	distinct := make(map[uuid.UUID]bool)
+	for _, vote := range votes {
+		voters[vote.OptionID] = append(voters[vote.OptionID], vote.UserID)
+		distinct[vote.UserID] = true
+	}
+
+	options := make([]dto.PollOptionDTO, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		optionDTO := dto.PollOptionDTO{
+			ID:        option.ID,
+			Text:      option.Text,
+			Position:  option.Position,
+			VoteCount: len(voters[option.ID]),
+		}
+		if !poll.IsAnonymous {
+			optionDTO.VoterIDs = voters[option.ID]
+		}
+		options = append(options, optionDTO)
+	}
+
+	result := &dto.PollDTO{
+		ID:             poll.ID,
+		MessageID:      poll.MessageID,
+		ConversationID: poll.ConversationID,
+		CreatorID:      poll.CreatorID,
+		Question:       poll.Question,
+		Options:        options,
+		AllowMultiple:  poll.AllowMultiple,
+		IsAnonymous:    poll.IsAnonymous,
+		ClosesAt:       poll.ClosesAt,
+		IsClosed:       poll.IsClosed(time.Now()),
+		ClosedAt:       poll.ClosedAt,
+		ClosedBy:       poll.ClosedBy,
+		TotalVoters:    len(distinct),
+	}
+	if len(poll.FinalResults) > 0 {
+		result.FinalResults = poll.FinalResults
+	}
+	if viewerID != uuid.Nil {
+		result.MyVotes = myPollVotes(votes, viewerID)
+	}
+
+	return result
+}
+
+// myPollVotes returns the option IDs chosen by a user
+func myPollVotes(votes []*models.PollVote, userID uuid.UUID) []uuid.UUID {
+	mine := make([]uuid.UUID, 0)
+	for _, vote := range votes {
+		if vote.UserID == userID {
+			mine = append(mine, vote.OptionID)
+		}
+	}
+	return mine
+}
+
+// pollResultSnapshot builds the final_results stored when a poll closes
+func pollResultSnapshot(poll *dto.PollDTO, closedAt time.Time) types.JSONB {
+	options := make([]map[string]interface{}, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		entry := map[string]interface{}{
+			"option_id":  option.ID,
+			"text":       option.Text,
+			"vote_count": option.VoteCount,
+		}
+		if !poll.IsAnonymous {
+			entry["voter_ids"] = option.VoterIDs
+		}
+		options = append(options, entry)
+	}
+
+	return types.JSONB{
+		"total_voters": poll.TotalVoters,
+		"closed_at":    closedAt,
+		"options":      options,
+	}
+}
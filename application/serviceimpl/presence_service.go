@@ -24,10 +24,39 @@ const (
 	onlineKeyPrefix = "user:online:"
 	lastSeenPrefix  = "user:lastseen:"
 
-	// TTL for online status (5 minutes)
+	// Sorted set of nodes holding connections of a user (score = unix time the entry expires)
+	connsKeyPrefix = "user:conns:"
+
+	// TTL for online status (5 minutes), refreshed by every node that still holds a connection
 	onlineTTL = 5 * time.Minute
 )
 
+// connectScript drops expired nodes, adds this node and returns how many other nodes were connected
+// KEYS: conns, online  ARGV: node, now, expires_at, ttl_seconds
+var connectScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+local others = redis.call('ZCARD', KEYS[1])
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	others = others - 1
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+redis.call('SET', KEYS[2], '1', 'EX', ARGV[4])
+return others
+`)
+
+// disconnectScript removes this node and expired nodes; deletes presence and returns 1 when none is left
+// KEYS: conns, online  ARGV: node, now
+var disconnectScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+if redis.call('ZCARD', KEYS[1]) > 0 then
+	return 0
+end
+redis.call('DEL', KEYS[1], KEYS[2])
+return 1
+`)
+
 // NewPresenceService creates a new PresenceService
 func NewPresenceService(
 	redis *redis.Client,
@@ -42,40 +71,65 @@ func NewPresenceService(
 	}
 }
 
-// SetUserOnline marks a user as online in Redis
-func (s *presenceService) SetUserOnline(userID uuid.UUID) error {
-	key := onlineKeyPrefix + userID.String()
-
-	// Set with TTL
-	err := s.redis.Set(s.ctx, key, "1", onlineTTL).Err()
+// UserConnected adds the node to the user's connection set and marks the user online
+func (s *presenceService) UserConnected(userID uuid.UUID, nodeID string) (bool, error) {
+	now := time.Now()
+	others, err := connectScript.Run(s.ctx, s.redis,
+		[]string{connsKeyPrefix + userID.String(), onlineKeyPrefix + userID.String()},
+		nodeID, now.Unix(), now.Add(onlineTTL).Unix(), int(onlineTTL.Seconds()),
+	).Int64()
 	if err != nil {
-		return fmt.Errorf("failed to set user online: %w", err)
+		return false, fmt.Errorf("failed to set user online: %w", err)
 	}
 
 	// Update last active in database
-	return s.UpdateLastActive(userID)
+	return others == 0, s.UpdateLastActive(userID)
 }
 
-// SetUserOffline marks a user as offline
-func (s *presenceService) SetUserOffline(userID uuid.UUID) error {
-	key := onlineKeyPrefix + userID.String()
-
-	// Delete online key
-	err := s.redis.Del(s.ctx, key).Err()
+// UserDisconnected removes the node from the user's connection set
+// The online key is deleted only when no live node is left
+func (s *presenceService) UserDisconnected(userID uuid.UUID, nodeID string) (bool, error) {
+	now := time.Now()
+	offline, err := disconnectScript.Run(s.ctx, s.redis,
+		[]string{connsKeyPrefix + userID.String(), onlineKeyPrefix + userID.String()},
+		nodeID, now.Unix(),
+	).Int64()
 	if err != nil {
-		return fmt.Errorf("failed to set user offline: %w", err)
+		return false, fmt.Errorf("failed to set user offline: %w", err)
+	}
+	if offline == 0 {
+		return false, nil
 	}
 
 	// Store last seen time
 	lastSeenKey := lastSeenPrefix + userID.String()
-	now := time.Now().Unix()
-	err = s.redis.Set(s.ctx, lastSeenKey, now, 0).Err() // No expiry
+	err = s.redis.Set(s.ctx, lastSeenKey, now.Unix(), 0).Err() // No expiry
 	if err != nil {
-		return fmt.Errorf("failed to store last seen: %w", err)
+		return true, fmt.Errorf("failed to store last seen: %w", err)
 	}
 
 	// Update last active in database
-	return s.UpdateLastActive(userID)
+	return true, s.UpdateLastActive(userID)
+}
+
+// RefreshConnections extends the node's entry and the online key of each user
+func (s *presenceService) RefreshConnections(userIDs []uuid.UUID, nodeID string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	expiresAt := float64(time.Now().Add(onlineTTL).Unix())
+	pipe := s.redis.Pipeline()
+	for _, userID := range userIDs {
+		connsKey := connsKeyPrefix + userID.String()
+		pipe.ZAdd(s.ctx, connsKey, &redis.Z{Score: expiresAt, Member: nodeID})
+		pipe.Expire(s.ctx, connsKey, onlineTTL)
+		pipe.Set(s.ctx, onlineKeyPrefix+userID.String(), "1", onlineTTL)
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to refresh presence: %w", err)
+	}
+	return nil
 }
 
 // UpdateLastActive updates user's last active timestamp in database
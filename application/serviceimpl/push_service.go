@@ -0,0 +1,336 @@
+// application/serviceimpl/push_service.go
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+const (
+	pushSendTimeout   = 15 * time.Second
+	maxPushTokenBytes = 4096
+)
+
+// pushKey push ที่รอรวมของผู้ใช้หนึ่งคนต่อ collapse key หนึ่งค่า
+type pushKey struct {
+	userID      uuid.UUID
+	collapseKey string
+}
+
+// pendingPush push ล่าสุดที่รอส่ง และจำนวนที่ถูกรวมไว้
+type pendingPush struct {
+	notification dto.PushNotification
+	count        int
+	timer        *time.Timer
+}
+
+type pushService struct {
+	pushDeviceRepo  repository.PushDeviceRepository
+	wsPort          port.WebSocketPort
+	presenceService service.PresenceService
+	sessionService  service.SessionService
+	providers       map[string]port.PushProvider
+	collapseWindow  time.Duration
+
+	mu      sync.Mutex
+	pending map[pushKey]*pendingPush
+}
+
+// NewPushService สร้าง PushService
+// collapseWindow คือเวลาที่รอรวม push ของการสนทนาเดียวกันก่อนส่ง (0 = ส่งทันที)
+func NewPushService(
+	pushDeviceRepo repository.PushDeviceRepository,
+	wsPort port.WebSocketPort,
+	presenceService service.PresenceService,
+	sessionService service.SessionService,
+	providers []port.PushProvider,
+	collapseWindow time.Duration,
+) service.PushService {
+	providerMap := make(map[string]port.PushProvider, len(providers))
+	for _, p := range providers {
+		providerMap[p.Name()] = p
+	}
+	return &pushService{
+		pushDeviceRepo:  pushDeviceRepo,
+		wsPort:          wsPort,
+		presenceService: presenceService,
+		sessionService:  sessionService,
+		providers:       providerMap,
+		collapseWindow:  collapseWindow,
+		pending:         make(map[pushKey]*pendingPush),
+	}
+}
+
+// RegisterDevice ลงทะเบียนอุปกรณ์ (token เดิมจะถูกอัปเดตแทนการสร้างซ้ำ)
+func (s *pushService) RegisterDevice(userID, sessionID uuid.UUID, req *dto.RegisterPushDeviceRequest) (*dto.PushDeviceDTO, error) {
+	provider := strings.ToLower(strings.TrimSpace(req.Provider))
+	if _, ok := s.providers[provider]; !ok {
+		return nil, errors.New("push provider is not configured")
+	}
+
+	device := &models.PushDevice{
+		UserID:     userID,
+		Provider:   provider,
+		Platform:   normalizePlatform(req.Platform),
+		DeviceName: truncateString(strings.TrimSpace(req.DeviceName), 100),
+	}
+
+	if provider == models.PushProviderWebPush {
+		if req.Endpoint == "" || req.Keys == nil || req.Keys.P256dh == "" || req.Keys.Auth == "" {
+			return nil, errors.New("web push subscription requires endpoint and keys")
+		}
+		endpoint, err := url.Parse(req.Endpoint)
+		if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+			return nil, errors.New("invalid web push endpoint")
+		}
+		// server จะ POST ไปยัง endpoint นี้ จึงรับเฉพาะ push service ของ browser (กัน SSRF ไปยังระบบภายใน)
+		if !isWebPushServiceURL(endpoint) {
+			return nil, errors.New("web push endpoint is not a supported push service")
+		}
+		device.Token = req.Endpoint
+		device.P256dh = req.Keys.P256dh
+		device.Auth = req.Keys.Auth
+		if req.Platform == "" {
+			device.Platform = "web"
+		}
+	} else {
+		device.Token = strings.TrimSpace(req.Token)
+		if device.Token == "" {
+			return nil, errors.New("device token is required")
+		}
+	}
+
+	if sessionID != uuid.Nil {
+		device.SessionID = &sessionID
+	}
+
+	if len(device.Token) > maxPushTokenBytes {
+		return nil, errors.New("device token is too long")
+	}
+
+	if err := s.pushDeviceRepo.Upsert(device); err != nil {
+		return nil, err
+	}
+	return toPushDeviceDTO(device, sessionID), nil
+}
+
+// webPushServiceHosts push service ของ browser หลัก (Chrome/Edge ใช้ FCM, Firefox, Safari, Windows)
+// รับทั้ง host นี้และ subdomain เช่น updates.push.services.mozilla.com, web.push.apple.com
+var webPushServiceHosts = []string{
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	"push.services.mozilla.com",
+	"push.apple.com",
+	"notify.windows.com",
+}
+
+// isWebPushServiceURL ตรวจว่า endpoint ชี้ไปยัง push service ที่รู้จักบน port มาตรฐาน
+func isWebPushServiceURL(endpoint *url.URL) bool {
+	if endpoint.User != nil || (endpoint.Port() != "" && endpoint.Port() != "443") {
+		return false
+	}
+	host := strings.ToLower(endpoint.Hostname())
+	for _, allowed := range webPushServiceHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDevices ดึงอุปกรณ์ที่ลงทะเบียนของผู้ใช้
+func (s *pushService) ListDevices(userID, currentSessionID uuid.UUID) ([]*dto.PushDeviceDTO, error) {
+	devices, err := s.pushDeviceRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.PushDeviceDTO, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, toPushDeviceDTO(device, currentSessionID))
+	}
+	return result, nil
+}
+
+// UnregisterDevice ลบอุปกรณ์ของผู้ใช้
+func (s *pushService) UnregisterDevice(userID, deviceID uuid.UUID) error {
+	device, err := s.pushDeviceRepo.FindByID(deviceID)
+	if err != nil {
+		return err
+	}
+	if device == nil || device.UserID != userID {
+		return errors.New("push device not found")
+	}
+	return s.pushDeviceRepo.Delete(deviceID)
+}
+
+// VAPIDPublicKey public key ของ Web Push provider
+func (s *pushService) VAPIDPublicKey() string {
+	if p, ok := s.providers[models.PushProviderWebPush].(interface{ PublicKey() string }); ok {
+		return p.PublicKey()
+	}
+	return ""
+}
+
+// NotifyOffline ส่ง push ให้ผู้ใช้ที่ไม่ได้เชื่อมต่อ
+// การตรวจว่าออนไลน์ทำอีกครั้งตอนส่งจริง เพราะผู้ใช้อาจกลับมาออนไลน์ระหว่างรอรวม
+func (s *pushService) NotifyOffline(userIDs []uuid.UUID, notification *dto.PushNotification) {
+	if len(s.providers) == 0 || notification == nil {
+		return
+	}
+
+	for _, userID := range userIDs {
+		// ตัดผู้ใช้ที่เชื่อมต่อกับ instance นี้ออกก่อน (ไม่ต้องเรียก Redis)
+		if s.wsPort != nil && s.wsPort.IsUserConnected(userID) {
+			continue
+		}
+
+		if notification.CollapseKey == "" || s.collapseWindow <= 0 {
+			n := *notification
+			go s.deliver(userID, &n, 1)
+			continue
+		}
+
+		s.enqueue(pushKey{userID: userID, collapseKey: notification.CollapseKey}, notification)
+	}
+}
+
+// enqueue เก็บ push ไว้รอรวม push แรกของแต่ละ key เริ่มนับเวลา collapseWindow
+func (s *pushService) enqueue(key pushKey, notification *dto.PushNotification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pending[key]; ok {
+		p.notification = *notification
+		p.count++
+		return
+	}
+
+	p := &pendingPush{notification: *notification, count: 1}
+	p.timer = time.AfterFunc(s.collapseWindow, func() {
+		s.flushKey(key)
+	})
+	s.pending[key] = p
+}
+
+// flushKey ส่ง push ที่รอรวมของ key นี้
+func (s *pushService) flushKey(key pushKey) {
+	s.mu.Lock()
+	p, ok := s.pending[key]
+	if ok {
+		delete(s.pending, key)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.deliver(key.userID, &p.notification, p.count)
+	}
+}
+
+// Flush ส่ง push ที่รอรวมอยู่ทั้งหมดทันที
+func (s *pushService) Flush() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[pushKey]*pendingPush)
+	s.mu.Unlock()
+
+	for key, p := range pending {
+		if p.timer != nil {
+			p.timer.Stop()
+		}
+		s.deliver(key.userID, &p.notification, p.count)
+	}
+}
+
+// deliver ส่ง push ไปยังทุกอุปกรณ์ของผู้ใช้ ถ้าผู้ใช้ยังออฟไลน์อยู่
+func (s *pushService) deliver(userID uuid.UUID, notification *dto.PushNotification, count int) {
+	if s.isOnline(userID) {
+		return
+	}
+
+	devices, err := s.pushDeviceRepo.FindByUserID(userID)
+	if err != nil {
+		log.Printf("Failed to load push devices for user %s: %v", userID, err)
+		return
+	}
+	if len(devices) == 0 {
+		return
+	}
+
+	if count > 1 {
+		collapsed := *notification
+		collapsed.Body = fmt.Sprintf("%d new messages", count)
+		collapsed.Data = make(map[string]string, len(notification.Data)+1)
+		for k, v := range notification.Data {
+			collapsed.Data[k] = v
+		}
+		collapsed.Data["count"] = fmt.Sprint(count)
+		notification = &collapsed
+	}
+
+	for _, device := range devices {
+		provider, ok := s.providers[device.Provider]
+		if !ok {
+			continue
+		}
+
+		// อุปกรณ์ของ session ที่ออกจากระบบหรือถูกเพิกถอนแล้วไม่ควรได้รับข้อความอีก
+		if device.SessionID != nil && s.sessionService != nil {
+			if active, err := s.sessionService.IsSessionActive(*device.SessionID); err == nil && !active {
+				_ = s.pushDeviceRepo.Delete(device.ID)
+				continue
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), pushSendTimeout)
+		err := provider.Send(ctx, device, notification)
+		cancel()
+
+		switch {
+		case err == nil:
+			_ = s.pushDeviceRepo.MarkUsed(device.ID, time.Now())
+		case errors.Is(err, port.ErrPushDeviceGone):
+			_ = s.pushDeviceRepo.Delete(device.ID)
+		default:
+			log.Printf("Failed to send %s push to device %s: %v", device.Provider, device.ID, err)
+		}
+	}
+}
+
+// isOnline ผู้ใช้เชื่อมต่อ WebSocket อยู่บน instance นี้หรือ instance อื่น (ผ่าน presence ใน Redis)
+func (s *pushService) isOnline(userID uuid.UUID) bool {
+	if s.wsPort != nil && s.wsPort.IsUserConnected(userID) {
+		return true
+	}
+	if s.presenceService != nil {
+		if online, err := s.presenceService.IsUserOnline(userID); err == nil && online {
+			return true
+		}
+	}
+	return false
+}
+
+func toPushDeviceDTO(device *models.PushDevice, currentSessionID uuid.UUID) *dto.PushDeviceDTO {
+	return &dto.PushDeviceDTO{
+		ID:         device.ID,
+		Provider:   device.Provider,
+		Platform:   device.Platform,
+		DeviceName: device.DeviceName,
+		CreatedAt:  device.CreatedAt,
+		LastUsedAt: device.LastUsedAt,
+		IsCurrent:  currentSessionID != uuid.Nil && device.SessionID != nil && *device.SessionID == currentSessionID,
+	}
+}
@@ -0,0 +1,90 @@
+// application/serviceimpl/push_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+func (f *fixture) registerPushDevice(userID uuid.UUID, token string) *dto.PushDeviceDTO {
+	f.t.Helper()
+
+	device, err := f.pushService.RegisterDevice(userID, uuid.Nil, &dto.RegisterPushDeviceRequest{
+		Provider: "fcm",
+		Token:    token,
+		Platform: "android",
+	})
+	mustNoError(f.t, err)
+	return device
+}
+
+func TestPush_CollapsesMessagesForOfflineMembers(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")     // ออฟไลน์
+	carol := f.createUser("carol") // เชื่อมต่อ WebSocket อยู่
+	group := f.createConversation("group", alice, bob, carol)
+
+	f.registerPushDevice(bob.ID, "bob-phone")
+	f.registerPushDevice(carol.ID, "carol-phone")
+	f.ws.SetUserConnected(carol.ID, true)
+
+	for _, text := range []string{"one", "two", "three"} {
+		f.notificationService.NotifyNewMessage(group.ID, f.sendText(group.ID, alice.ID, text))
+	}
+	if len(f.push.Deliveries()) != 0 {
+		t.Fatal("expected pushes to wait for the collapse window")
+	}
+
+	f.pushService.Flush()
+
+	deliveries := f.push.Deliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("expected one collapsed push, got %+v", deliveries)
+	}
+	push := deliveries[0]
+	if push.Device.Token != "bob-phone" {
+		t.Fatalf("expected push to the offline member only, got %s", push.Device.Token)
+	}
+	if push.Notification.CollapseKey != "conv:"+group.ID.String() {
+		t.Fatalf("expected collapse key per conversation, got %q", push.Notification.CollapseKey)
+	}
+	if push.Notification.Body != "3 new messages" || push.Notification.Data["count"] != "3" {
+		t.Fatalf("expected a summary of 3 messages, got %+v", push.Notification)
+	}
+}
+
+func TestPush_RemovesDevicesRejectedByProvider(t *testing.T) {
+	f := newFixture(t)
+
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+
+	f.registerPushDevice(alice.ID, "old-phone")
+	current := f.registerPushDevice(alice.ID, "new-phone")
+	f.push.MarkGone("old-phone")
+
+	f.pushService.NotifyOffline([]uuid.UUID{alice.ID}, &dto.PushNotification{
+		Title:       "Bob",
+		Body:        "hello",
+		CollapseKey: "conv:test",
+	})
+	f.pushService.Flush()
+
+	if deliveries := f.push.Deliveries(); len(deliveries) != 1 || deliveries[0].Device.Token != "new-phone" {
+		t.Fatalf("expected one push to new-phone, got %+v", deliveries)
+	}
+	devices, err := f.pushService.ListDevices(alice.ID, uuid.Nil)
+	mustNoError(t, err)
+	if len(devices) != 1 || devices[0].ID != current.ID {
+		t.Fatalf("expected the rejected device to be removed, got %+v", devices)
+	}
+
+	// ลบอุปกรณ์ของคนอื่นไม่ได้ และ provider ที่ไม่ได้ตั้งค่าลงทะเบียนไม่ได้
+	expectError(t, f.pushService.UnregisterDevice(bob.ID, current.ID), "push device not found")
+	_, err = f.pushService.RegisterDevice(bob.ID, uuid.Nil, &dto.RegisterPushDeviceRequest{Provider: "apns", Token: "x"})
+	expectError(t, err, "push provider is not configured")
+}
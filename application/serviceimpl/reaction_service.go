@@ -0,0 +1,262 @@
+// application/serviceimpl/reaction_service.go
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// Maximum distinct emojis a user can put on one message
+const MaxReactionsPerUserPerMessage = 10
+
+// Maximum length (in characters) of a reaction emoji
+const maxReactionEmojiLength = 16
+
+type reactionService struct {
+	reactionRepo        repository.MessageReactionRepository
+	messageRepo         repository.MessageRepository
+	conversationRepo    repository.ConversationRepository
+	notificationService service.NotificationService
+}
+
+// NewReactionService creates a new reaction service
+func NewReactionService(
+	reactionRepo repository.MessageReactionRepository,
+	messageRepo repository.MessageRepository,
+	conversationRepo repository.ConversationRepository,
+	notificationService service.NotificationService,
+) service.ReactionService {
+	return &reactionService{
+		reactionRepo:        reactionRepo,
+		messageRepo:         messageRepo,
+		conversationRepo:    conversationRepo,
+		notificationService: notificationService,
+	}
+}
+
+// AddReaction adds a reaction to a message
+func (s *reactionService) AddReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*dto.MessageReactionsDTO, error) {
+	emoji, err := normalizeReactionEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.getAccessibleMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.reactionRepo.Exists(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adding the same emoji twice is a no-op
+	if !exists {
+		count, err := s.reactionRepo.CountByUser(ctx, messageID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= MaxReactionsPerUserPerMessage {
+			return nil, errors.New("maximum reactions per message reached")
+		}
+
+		reaction := &models.MessageReaction{
+			ID:             uuid.New(),
+			MessageID:      messageID,
+			ConversationID: message.ConversationID,
+			UserID:         userID,
+			Emoji:          emoji,
+			CreatedAt:      time.Now(),
+		}
+		// A concurrent request may have added the same reaction since Exists
+		created, err := s.reactionRepo.Create(ctx, reaction)
+		if err != nil {
+			return nil, err
+		}
+		exists = !created
+	}
+
+	reactions, err := s.reactionRepo.GetByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		s.broadcastReaction(message, userID, emoji, "added", reactions)
+	}
+
+	return &dto.MessageReactionsDTO{
+		MessageID: messageID,
+		Summary:   buildReactionSummary(reactions, userID),
+	}, nil
+}
+
+// RemoveReaction removes the user's own reaction from a message
+func (s *reactionService) RemoveReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*dto.MessageReactionsDTO, error) {
+	emoji, err := normalizeReactionEmoji(emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.getAccessibleMessage(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.reactionRepo.Exists(ctx, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("reaction not found")
+	}
+
+	if err := s.reactionRepo.Delete(ctx, messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.GetByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcastReaction(message, userID, emoji, "removed", reactions)
+
+	return &dto.MessageReactionsDTO{
+		MessageID: messageID,
+		Summary:   buildReactionSummary(reactions, userID),
+	}, nil
+}
+
+// GetReactions lists all reactions on a message
+func (s *reactionService) GetReactions(ctx context.Context, messageID, userID uuid.UUID) (*dto.MessageReactionsDTO, error) {
+	if _, err := s.getAccessibleMessage(messageID, userID); err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.reactionRepo.GetByMessageIDWithUsers(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionDTOs := make([]dto.MessageReactionDTO, 0, len(reactions))
+	for _, r := range reactions {
+		item := dto.MessageReactionDTO{
+			ID:        r.ID,
+			MessageID: r.MessageID,
+			UserID:    r.UserID,
+			Emoji:     r.Emoji,
+			CreatedAt: r.CreatedAt,
+		}
+		if r.User != nil {
+			item.User = &dto.UserBasicDTO{
+				ID:              r.User.ID,
+				Username:        r.User.Username,
+				DisplayName:     r.User.DisplayName,
+				ProfileImageURL: r.User.ProfileImageURL,
+			}
+		}
+		reactionDTOs = append(reactionDTOs, item)
+	}
+
+	return &dto.MessageReactionsDTO{
+		MessageID: messageID,
+		Summary:   buildReactionSummary(reactions, userID),
+		Reactions: reactionDTOs,
+	}, nil
+}
+
+// getAccessibleMessage loads a message and checks that the user can see it
+func (s *reactionService) getAccessibleMessage(messageID, userID uuid.UUID) (*models.Message, error) {
+	message, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, errors.New("message not found")
+	}
+	if message.IsDeleted {
+		return nil, errors.New("cannot react to deleted message")
+	}
+
+	isMember, err := s.conversationRepo.IsMember(message.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("user is not a member of this conversation")
+	}
+
+	return message, nil
+}
+
+// broadcastReaction sends message.reaction to conversation members
+func (s *reactionService) broadcastReaction(message *models.Message, userID uuid.UUID, emoji, action string, reactions []*models.MessageReaction) {
+	if s.notificationService == nil {
+		return
+	}
+
+	summary := buildReactionSummary(reactions, uuid.Nil)
+	counts := make([]dto.ReactionCountDTO, len(summary))
+	for i, item := range summary {
+		counts[i] = dto.ReactionCountDTO{Emoji: item.Emoji, Count: item.Count}
+	}
+
+	s.notificationService.NotifyMessageReaction(message.ConversationID, &dto.MessageReactionEventDTO{
+		MessageID:      message.ID,
+		ConversationID: message.ConversationID,
+		UserID:         userID,
+		Emoji:          emoji,
+		Action:         action,
+		Reactions:      counts,
+	})
+}
+
+// normalizeReactionEmoji trims and validates a reaction emoji
+func normalizeReactionEmoji(emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" {
+		return "", errors.New("emoji is required")
+	}
+	if utf8.RuneCountInString(emoji) > maxReactionEmojiLength {
+		return "", errors.New("emoji is too long")
+	}
+	return emoji, nil
+}
+
+// buildReactionSummary groups reactions by emoji, most used first.
+// Ties keep the order in which each emoji was first used.
+func buildReactionSummary(reactions []*models.MessageReaction, userID uuid.UUID) []dto.ReactionSummaryDTO {
+	summary := make([]dto.ReactionSummaryDTO, 0)
+	index := make(map[string]int)
+
+	for _, r := range reactions {
+		i, ok := index[r.Emoji]
+		if !ok {
+			i = len(summary)
+			index[r.Emoji] = i
+			summary = append(summary, dto.ReactionSummaryDTO{Emoji: r.Emoji})
+		}
+		summary[i].Count++
+		if userID != uuid.Nil && r.UserID == userID {
+			summary[i].ReactedByMe = true
+		}
+	}
+
+	sort.SliceStable(summary, func(i, j int) bool {
+		return summary[i].Count > summary[j].Count
+	})
+
+	return summary
+}
@@ -0,0 +1,43 @@
+// application/serviceimpl/reaction_service_test.go
+package serviceimpl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/application/serviceimpl"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+// staleReactionRepo จำลองคำขอที่ตรวจ Exists ก่อนอีกคำขอบันทึก reaction เดียวกันเสร็จ
+type staleReactionRepo struct {
+	repository.MessageReactionRepository
+}
+
+func (r staleReactionRepo) Exists(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error) {
+	return false, nil
+}
+
+func TestAddReactionConflictIsIdempotent(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	group := f.createConversation("group", alice, bob)
+	message := f.sendText(group.ID, alice.ID, "hello")
+
+	reactions := serviceimpl.NewReactionService(staleReactionRepo{f.reactionRepo}, f.messageRepo, f.conversationRepo, f.notificationService)
+
+	_, err := reactions.AddReaction(context.Background(), message.ID, bob.ID, "👍")
+	mustNoError(t, err)
+
+	// insert ชน unique index: ไม่ error และไม่ broadcast ซ้ำ
+	result, err := reactions.AddReaction(context.Background(), message.ID, bob.ID, "👍")
+	mustNoError(t, err)
+	if len(result.Summary) != 1 || result.Summary[0].Count != 1 || !result.Summary[0].ReactedByMe {
+		t.Fatalf("expected a single reaction, got %+v", result.Summary)
+	}
+	if events := f.ws.EventsOfType("message.reaction"); len(events) != 1 {
+		t.Fatalf("expected one message.reaction event, got %d", len(events))
+	}
+}
@@ -5,20 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/pkg/cron"
 )
 
+// การส่งซ้ำเมื่อส่งไม่สำเร็จ
+const (
+	MaxScheduledMessageAttempts = 5                // จำนวนครั้งสูงสุดก่อนเปลี่ยนเป็น failed
+	scheduledRetryBaseDelay     = 30 * time.Second // delay ครั้งแรก (เพิ่มเป็นสองเท่าทุกครั้ง)
+	scheduledRetryMaxDelay      = 30 * time.Minute
+	scheduledMessageLease       = 2 * time.Minute // instance อื่น claim ใหม่ได้เมื่อ lease หมดอายุ
+)
+
+// timezone เริ่มต้นของข้อความตั้งเวลาแบบซ้ำ
+const defaultRecurrenceTimezone = "UTC"
+
 type scheduledMessageService struct {
 	scheduledMessageRepo    repository.ScheduledMessageRepository
 	conversationRepo        repository.ConversationRepository
 	messageService          service.MessageService
 	notificationService     service.NotificationService
 	processor               service.ScheduledMessageProcessor
+	workerID                string // ใช้ระบุ instance ที่ claim ข้อความ
 }
 
 // NewScheduledMessageService สร้าง instance ใหม่ของ ScheduledMessageService
@@ -33,7 +48,17 @@ func NewScheduledMessageService(
 		conversationRepo:        conversationRepo,
 		messageService:          messageService,
 		notificationService:     notificationService,
+		workerID:                newScheduledWorkerID(),
+	}
+}
+
+// newScheduledWorkerID สร้าง ID ของ instance (hostname + random)
+func newScheduledWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "worker"
 	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
 }
 
 // SetProcessor ตั้งค่า processor reference (เรียกหลังจากสร้าง processor แล้ว)
@@ -154,8 +179,8 @@ func (s *scheduledMessageService) CancelScheduledMessage(id, userID uuid.UUID) e
 		return errors.New("unauthorized to cancel this scheduled message")
 	}
 
-	// ตรวจสอบสถานะ
-	if scheduledMsg.Status != "pending" {
+	// ตรวจสอบสถานะ (รอบที่ถูก pause ของ series ยกเลิกได้เช่นกัน)
+	if scheduledMsg.Status != "pending" && scheduledMsg.Status != "paused" {
 		return errors.New("can only cancel pending scheduled messages")
 	}
 
@@ -194,8 +219,9 @@ func (s *scheduledMessageService) UpdateScheduledTime(id, userID uuid.UUID, newS
 		return nil, errors.New("scheduled_at must be in the future")
 	}
 
-	// อัปเดตในฐานข้อมูล
+	// อัปเดตในฐานข้อมูล (เวลาใหม่แทนเวลา retry เดิม)
 	scheduledMsg.ScheduledAt = newScheduledAt
+	scheduledMsg.NextAttemptAt = nil
 	scheduledMsg.UpdatedAt = time.Now()
 	if err := s.scheduledMessageRepo.Update(scheduledMsg); err != nil {
 		return nil, err
@@ -210,14 +236,334 @@ func (s *scheduledMessageService) UpdateScheduledTime(id, userID uuid.UUID, newS
 	return scheduledMsg, nil
 }
 
+// ScheduleRecurringMessage สร้างข้อความตั้งเวลาแบบซ้ำตาม cron expression
+// รอบแรกคือเวลาแรกที่ตรงกับ rule หลังจาก startAt (หรือหลังจากตอนนี้ ถ้า startAt อยู่ในอดีต)
+func (s *scheduledMessageService) ScheduleRecurringMessage(
+	conversationID, userID uuid.UUID,
+	messageType, content, mediaURL string,
+	metadata map[string]interface{},
+	recurrenceRule, timezone string,
+	startAt time.Time,
+	until *time.Time,
+) (*models.ScheduledMessage, error) {
+	// ตรวจสอบว่า user เป็นสมาชิกของการสนทนา
+	isMember, err := s.conversationRepo.IsMember(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("user is not a member of this conversation")
+	}
+
+	if timezone == "" {
+		timezone = defaultRecurrenceTimezone
+	}
+	if until != nil && until.Before(time.Now()) {
+		return nil, errors.New("recurrence_until must be in the future")
+	}
+
+	after := time.Now()
+	if start := startAt.Add(-time.Minute); start.After(after) {
+		after = start
+	}
+	firstAt, err := nextOccurrence(recurrenceRule, timezone, after, until)
+	if err != nil {
+		return nil, err
+	}
+
+	// สร้าง metadata JSONB
+	metadataJSON := make(map[string]interface{})
+	for k, v := range metadata {
+		metadataJSON[k] = v
+	}
+
+	// รอบแรกใช้ ID ของตัวเองเป็น SeriesID
+	id := uuid.New()
+	scheduledMsg := &models.ScheduledMessage{
+		ID:              id,
+		ConversationID:  conversationID,
+		SenderID:        userID,
+		MessageType:     messageType,
+		Content:         content,
+		MediaURL:        mediaURL,
+		Metadata:        metadataJSON,
+		ScheduledAt:     firstAt,
+		Status:          "pending",
+		SeriesID:        &id,
+		RecurrenceRule:  recurrenceRule,
+		Timezone:        timezone,
+		RecurrenceUntil: until,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := s.scheduledMessageRepo.Create(scheduledMsg); err != nil {
+		return nil, err
+	}
+
+	if s.processor != nil {
+		s.processor.ScheduleMessage(scheduledMsg.ID, firstAt)
+		log.Printf("[ScheduledMessageService] Created series %s (%s %s), first at %s", id, recurrenceRule, timezone, firstAt.Format(time.RFC3339))
+	}
+
+	return scheduledMsg, nil
+}
+
+// GetSeries ดึงทุกรอบของ series (ล่าสุดก่อน)
+func (s *scheduledMessageService) GetSeries(seriesID, userID uuid.UUID, limit, offset int) ([]*models.ScheduledMessage, int64, error) {
+	occurrences, total, err := s.scheduledMessageRepo.FindBySeriesID(seriesID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, errors.New("scheduled series not found")
+	}
+
+	// ทุกรอบมีผู้ส่งคนเดียวกัน
+	if len(occurrences) > 0 && occurrences[0].SenderID != userID {
+		return nil, 0, errors.New("unauthorized to access this scheduled series")
+	}
+
+	return occurrences, total, nil
+}
+
+// PauseSeries หยุด series ชั่วคราว (รอบถัดไปจะไม่ถูกส่งจนกว่าจะ resume)
+func (s *scheduledMessageService) PauseSeries(seriesID, userID uuid.UUID) (*models.ScheduledMessage, error) {
+	occurrence, err := s.getUpcomingOccurrence(seriesID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if occurrence.Status == "paused" {
+		return nil, errors.New("series is already paused")
+	}
+
+	paused, err := s.scheduledMessageRepo.PauseScheduledMessage(occurrence.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !paused {
+		// รอบนี้ถูก claim ไปส่งระหว่างนั้น
+		return nil, errors.New("series occurrence is being sent, try again shortly")
+	}
+	occurrence.Status = "paused"
+
+	if s.processor != nil {
+		s.processor.CancelMessage(occurrence.ID)
+	}
+	log.Printf("[ScheduledMessageService] Paused series %s", seriesID)
+
+	return occurrence, nil
+}
+
+// ResumeSeries กลับมาทำงานต่อ โดยรอบถัดไปคือเวลาแรกที่ตรงกับ rule หลังจากตอนนี้
+func (s *scheduledMessageService) ResumeSeries(seriesID, userID uuid.UUID) (*models.ScheduledMessage, error) {
+	occurrence, err := s.getUpcomingOccurrence(seriesID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if occurrence.Status != "paused" {
+		return nil, errors.New("series is not paused")
+	}
+
+	nextAt, err := nextOccurrence(occurrence.RecurrenceRule, occurrence.Timezone, time.Now(), occurrence.RecurrenceUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence.Status = "pending"
+	occurrence.ScheduledAt = nextAt
+	occurrence.NextAttemptAt = nil
+	occurrence.Attempts = 0
+	occurrence.ErrorReason = ""
+	occurrence.UpdatedAt = time.Now()
+	if err := s.scheduledMessageRepo.Update(occurrence); err != nil {
+		return nil, err
+	}
+
+	if s.processor != nil {
+		s.processor.ScheduleMessage(occurrence.ID, nextAt)
+	}
+	log.Printf("[ScheduledMessageService] Resumed series %s, next at %s", seriesID, nextAt.Format(time.RFC3339))
+
+	return occurrence, nil
+}
+
+// UpdateSeries แก้ไขเนื้อหาหรือ rule ของ series (มีผลกับรอบถัดไปเป็นต้นไป)
+func (s *scheduledMessageService) UpdateSeries(seriesID, userID uuid.UUID, req *dto.UpdateScheduledSeriesRequest) (*models.ScheduledMessage, error) {
+	occurrence, err := s.getUpcomingOccurrence(seriesID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Content != nil {
+		occurrence.Content = *req.Content
+	}
+	if req.MediaURL != nil {
+		occurrence.MediaURL = *req.MediaURL
+	}
+	if req.Metadata != nil {
+		metadataJSON := make(map[string]interface{})
+		for k, v := range req.Metadata {
+			metadataJSON[k] = v
+		}
+		occurrence.Metadata = metadataJSON
+	}
+
+	timingChanged := false
+	if req.RecurrenceRule != nil && *req.RecurrenceRule != occurrence.RecurrenceRule {
+		occurrence.RecurrenceRule = *req.RecurrenceRule
+		timingChanged = true
+	}
+	if req.Timezone != nil && *req.Timezone != occurrence.Timezone {
+		occurrence.Timezone = *req.Timezone
+		timingChanged = true
+	}
+	if req.RecurrenceUntil != nil {
+		if req.RecurrenceUntil.Before(time.Now()) {
+			return nil, errors.New("recurrence_until must be in the future")
+		}
+		occurrence.RecurrenceUntil = req.RecurrenceUntil
+		timingChanged = true
+	}
+
+	// ตรวจสอบ rule ใหม่และคำนวณเวลารอบถัดไป (series ที่ pause อยู่จะคำนวณใหม่ตอน resume)
+	if timingChanged {
+		nextAt, err := nextOccurrence(occurrence.RecurrenceRule, occurrence.Timezone, time.Now(), occurrence.RecurrenceUntil)
+		if err != nil {
+			return nil, err
+		}
+		if occurrence.Status == "pending" {
+			occurrence.ScheduledAt = nextAt
+			occurrence.NextAttemptAt = nil
+		}
+	}
+
+	occurrence.UpdatedAt = time.Now()
+	if err := s.scheduledMessageRepo.Update(occurrence); err != nil {
+		return nil, err
+	}
+
+	if timingChanged && occurrence.Status == "pending" && s.processor != nil {
+		s.processor.RescheduleMessage(occurrence.ID, occurrence.ScheduledAt)
+	}
+
+	return occurrence, nil
+}
+
+// getUpcomingOccurrence ดึงรอบถัดไปของ series และตรวจสอบสิทธิ์
+func (s *scheduledMessageService) getUpcomingOccurrence(seriesID, userID uuid.UUID) (*models.ScheduledMessage, error) {
+	occurrence, err := s.scheduledMessageRepo.FindUpcomingInSeries(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if occurrence == nil {
+		return nil, errors.New("scheduled series not found")
+	}
+	if occurrence.SenderID != userID {
+		return nil, errors.New("unauthorized to update this scheduled series")
+	}
+	return occurrence, nil
+}
+
+// scheduleNextOccurrence สร้างรอบถัดไปของ series หลังจากรอบปัจจุบันส่งแล้ว (หรือล้มเหลวถาวร)
+func (s *scheduledMessageService) scheduleNextOccurrence(current *models.ScheduledMessage) {
+	if !current.IsRecurring() {
+		return
+	}
+
+	// มีรอบถัดไปอยู่แล้ว (เช่นถูกประมวลผลซ้ำหลัง lease หมดอายุ)
+	upcoming, err := s.scheduledMessageRepo.FindUpcomingInSeries(*current.SeriesID)
+	if err != nil {
+		log.Printf("[ScheduledMessageService] Failed to check series %s: %v", *current.SeriesID, err)
+		return
+	}
+	if upcoming != nil {
+		return
+	}
+
+	// ข้ามรอบที่พลาดไป (เช่น server หยุดทำงาน) ไปยังรอบแรกในอนาคต
+	after := current.ScheduledAt
+	if now := time.Now(); now.After(after) {
+		after = now
+	}
+	nextAt, err := nextOccurrence(current.RecurrenceRule, current.Timezone, after, current.RecurrenceUntil)
+	if err != nil {
+		log.Printf("[ScheduledMessageService] Series %s finished: %v", *current.SeriesID, err)
+		return
+	}
+
+	metadataJSON := make(map[string]interface{})
+	for k, v := range current.Metadata {
+		metadataJSON[k] = v
+	}
+
+	next := &models.ScheduledMessage{
+		ID:              uuid.New(),
+		ConversationID:  current.ConversationID,
+		SenderID:        current.SenderID,
+		MessageType:     current.MessageType,
+		Content:         current.Content,
+		MediaURL:        current.MediaURL,
+		Metadata:        metadataJSON,
+		ScheduledAt:     nextAt,
+		Status:          "pending",
+		SeriesID:        current.SeriesID,
+		RecurrenceRule:  current.RecurrenceRule,
+		Timezone:        current.Timezone,
+		RecurrenceUntil: current.RecurrenceUntil,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := s.scheduledMessageRepo.Create(next); err != nil {
+		log.Printf("[ScheduledMessageService] Failed to create next occurrence of series %s: %v", *current.SeriesID, err)
+		return
+	}
+
+	if s.processor != nil {
+		s.processor.ScheduleMessage(next.ID, nextAt)
+	}
+	log.Printf("[ScheduledMessageService] Next occurrence of series %s at %s", *current.SeriesID, nextAt.Format(time.RFC3339))
+}
+
+// nextOccurrence คำนวณเวลารอบถัดไปหลัง after ตาม cron expression ใน timezone ที่กำหนด
+func nextOccurrence(rule, timezone string, after time.Time, until *time.Time) (time.Time, error) {
+	schedule, err := cron.Parse(rule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid recurrence rule: %v", err)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timezone")
+	}
+
+	nextAt := schedule.Next(after.In(loc))
+	if nextAt.IsZero() || (until != nil && nextAt.After(*until)) {
+		return time.Time{}, errors.New("recurrence rule has no upcoming occurrence")
+	}
+
+	return nextAt, nil
+}
+
 // GetPendingMessagesForProcessor ดึง pending messages สำหรับ processor
 func (s *scheduledMessageService) GetPendingMessagesForProcessor(beforeTime time.Time, limit int) ([]*models.ScheduledMessage, error) {
 	return s.scheduledMessageRepo.FindPendingMessages(beforeTime, limit)
 }
 
 // ProcessSingleScheduledMessage ประมวลผลข้อความเดียว (เรียกจาก timer callback)
+// ต้อง claim ข้อความก่อนส่ง ทำให้ส่งได้เพียงครั้งเดียวแม้มีหลาย instance หรือ timer ซ้ำ
 func (s *scheduledMessageService) ProcessSingleScheduledMessage(messageID uuid.UUID) error {
-	// ดึงข้อมูล scheduled message
+	claimed, err := s.scheduledMessageRepo.Claim(messageID, s.workerID, time.Now().Add(scheduledMessageLease))
+	if err != nil {
+		return fmt.Errorf("failed to claim scheduled message: %w", err)
+	}
+	if !claimed {
+		log.Printf("[ScheduledMessageService] Message %s is not due or already claimed, skipping", messageID)
+		return nil
+	}
+
+	// ดึงข้อมูล scheduled message (หลัง claim เพื่อได้ attempts ล่าสุด)
 	scheduledMsg, err := s.scheduledMessageRepo.GetByID(messageID)
 	if err != nil {
 		return fmt.Errorf("failed to get scheduled message: %w", err)
@@ -226,28 +572,62 @@ func (s *scheduledMessageService) ProcessSingleScheduledMessage(messageID uuid.U
 		return errors.New("scheduled message not found")
 	}
 
-	// ตรวจสอบสถานะ
-	if scheduledMsg.Status != "pending" {
-		log.Printf("[ScheduledMessageService] Message %s is not pending (status: %s), skipping", messageID, scheduledMsg.Status)
-		return nil
-	}
-
 	// ส่งข้อความ
 	if err := s.sendScheduledMessage(scheduledMsg); err != nil {
-		// อัปเดตสถานะเป็น failed
-		_ = s.scheduledMessageRepo.UpdateStatus(
-			scheduledMsg.ID,
-			"failed",
-			nil,
-			nil,
-			err.Error(),
-		)
+		s.handleSendFailure(scheduledMsg, err)
 		return err
 	}
 
+	// สร้างรอบถัดไปของ series
+	s.scheduleNextOccurrence(scheduledMsg)
+
 	return nil
 }
 
+// handleSendFailure ตั้งเวลาส่งใหม่แบบ exponential backoff หรือเปลี่ยนเป็น failed เมื่อครบจำนวนครั้ง
+func (s *scheduledMessageService) handleSendFailure(scheduledMsg *models.ScheduledMessage, sendErr error) {
+	if scheduledMsg.Attempts >= MaxScheduledMessageAttempts {
+		log.Printf("[ScheduledMessageService] Message %s failed after %d attempts: %v", scheduledMsg.ID, scheduledMsg.Attempts, sendErr)
+		completed, err := s.scheduledMessageRepo.CompleteClaim(scheduledMsg.ID, s.workerID, "failed", nil, nil, sendErr.Error())
+		if err != nil {
+			log.Printf("[ScheduledMessageService] Failed to mark message %s as failed: %v", scheduledMsg.ID, err)
+		} else if !completed {
+			// lease หมดอายุ instance อื่นรับไปทำต่อแล้ว ไม่เขียนทับผลของ instance นั้น
+			log.Printf("[ScheduledMessageService] Lost lease on message %s, leaving status to the new owner", scheduledMsg.ID)
+			return
+		}
+		// รอบนี้ล้มเหลว แต่ series ยังทำงานต่อในรอบถัดไป
+		s.scheduleNextOccurrence(scheduledMsg)
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(scheduledRetryDelay(scheduledMsg.Attempts))
+	if err := s.scheduledMessageRepo.ScheduleRetry(scheduledMsg.ID, s.workerID, nextAttemptAt, sendErr.Error()); err != nil {
+		// lease จะหมดอายุและ fallback poll จะ claim ใหม่
+		log.Printf("[ScheduledMessageService] Failed to schedule retry for message %s: %v", scheduledMsg.ID, err)
+		return
+	}
+
+	log.Printf("[ScheduledMessageService] Message %s attempt %d failed, retrying at %s: %v",
+		scheduledMsg.ID, scheduledMsg.Attempts, nextAttemptAt.Format(time.RFC3339), sendErr)
+
+	if s.processor != nil {
+		s.processor.ScheduleMessage(scheduledMsg.ID, nextAttemptAt)
+	}
+}
+
+// scheduledRetryDelay คำนวณ delay ก่อนส่งใหม่ (30s, 1m, 2m, ... สูงสุด 30m)
+func scheduledRetryDelay(attempts int) time.Duration {
+	delay := scheduledRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= scheduledRetryMaxDelay {
+			return scheduledRetryMaxDelay
+		}
+	}
+	return delay
+}
+
 // ProcessScheduledMessages ประมวลผลข้อความที่ถึงเวลาส่ง (legacy method - kept for compatibility)
 func (s *scheduledMessageService) ProcessScheduledMessages() error {
 	// ดึงข้อความที่ถึงเวลาส่งแล้ว
@@ -263,18 +643,10 @@ func (s *scheduledMessageService) ProcessScheduledMessages() error {
 
 	log.Printf("[ScheduledMessageService] Processing %d scheduled messages (legacy mode)", len(scheduledMessages))
 
-	// ส่งแต่ละข้อความ
+	// ส่งแต่ละข้อความ (ผ่าน claim เหมือน timer callback)
 	for _, scheduledMsg := range scheduledMessages {
-		if err := s.sendScheduledMessage(scheduledMsg); err != nil {
+		if err := s.ProcessSingleScheduledMessage(scheduledMsg.ID); err != nil {
 			log.Printf("[ScheduledMessageService] Failed to send message %s: %v", scheduledMsg.ID, err)
-			// อัปเดตสถานะเป็น failed
-			_ = s.scheduledMessageRepo.UpdateStatus(
-				scheduledMsg.ID,
-				"failed",
-				nil,
-				nil,
-				err.Error(),
-			)
 		}
 	}
 
@@ -387,13 +759,22 @@ func (s *scheduledMessageService) sendScheduledMessage(scheduledMsg *models.Sche
 		log.Printf("[ScheduledMessage] WebSocket notification sent for message %s", message.ID)
 	}
 
-	// อัปเดตสถานะเป็น sent
+	// อัปเดตสถานะเป็น sent (เฉพาะเมื่อยังถือ claim อยู่)
+	// ข้อความถูกส่งไปแล้ว ถ้าอัปเดตไม่สำเร็จจะไม่คืน error เพื่อไม่ให้ retry ส่งซ้ำ
 	now := time.Now()
-	return s.scheduledMessageRepo.UpdateStatus(
+	completed, err := s.scheduledMessageRepo.CompleteClaim(
 		scheduledMsg.ID,
+		s.workerID,
 		"sent",
 		&now,
 		&message.ID,
 		"",
 	)
+	if err != nil {
+		log.Printf("[ScheduledMessageService] Message %s was sent but status update failed: %v", scheduledMsg.ID, err)
+	} else if !completed {
+		log.Printf("[ScheduledMessageService] Message %s was sent after its lease expired, status left to the new owner", scheduledMsg.ID)
+	}
+
+	return nil
 }
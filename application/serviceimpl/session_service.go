@@ -0,0 +1,115 @@
+// application/serviceimpl/session_service.go
+package serviceimpl
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+type sessionService struct {
+	sessionRepo      repository.UserSessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	wsPort           port.WebSocketPort
+}
+
+// NewSessionService สร้าง SessionService
+func NewSessionService(
+	sessionRepo repository.UserSessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	wsPort port.WebSocketPort,
+) service.SessionService {
+	return &sessionService{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		wsPort:           wsPort,
+	}
+}
+
+// ListSessions ดึง session ที่ยังใช้งานได้ของผู้ใช้ (ใช้งานล่าสุดก่อน)
+func (s *sessionService) ListSessions(userID, currentSessionID uuid.UUID) ([]*dto.SessionDTO, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, &dto.SessionDTO{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			Platform:   session.Platform,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			IsCurrent:  session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSession เพิกถอน session ของผู้ใช้ ยกเลิก refresh token และปิด WebSocket ของอุปกรณ์นั้น
+func (s *sessionService) RevokeSession(userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// ไม่เปิดเผยว่ามี session ของผู้ใช้อื่นอยู่
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.New("session not found")
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID, time.Now()); err != nil {
+		return err
+	}
+
+	s.closeSession(userID, sessionID)
+	return nil
+}
+
+// RevokeOtherSessions เพิกถอนทุก session ของผู้ใช้ยกเว้น session ปัจจุบัน
+func (s *sessionService) RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int, error) {
+	revokedIDs, err := s.sessionRepo.RevokeAllByUserID(userID, currentSessionID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sessionID := range revokedIDs {
+		s.closeSession(userID, sessionID)
+	}
+
+	return len(revokedIDs), nil
+}
+
+// IsSessionActive ตรวจว่า session ยังใช้งานได้ (ไม่พบ = ไม่ active)
+func (s *sessionService) IsSessionActive(sessionID uuid.UUID) (bool, error) {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil {
+		return false, nil
+	}
+
+	return session.IsActive(time.Now()), nil
+}
+
+// closeSession ยกเลิก refresh token ของ session และปิด WebSocket ของอุปกรณ์นั้น
+func (s *sessionService) closeSession(userID, sessionID uuid.UUID) {
+	if err := s.refreshTokenRepo.RevokeBySessionID(sessionID); err != nil {
+		log.Printf("Failed to revoke refresh tokens of session %s: %v", sessionID, err)
+	}
+
+	if s.wsPort != nil {
+		s.wsPort.DisconnectSession(userID, sessionID)
+	}
+}
@@ -0,0 +1,142 @@
+// application/serviceimpl/sync_service.go
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// Default and maximum number of events returned per sync request
+const (
+	defaultSyncEventLimit = 100
+	maxSyncEventLimit     = 500
+)
+
+// How long a conversation's member list is reused when recording events.
+// Membership events invalidate the entry earlier; the TTL covers changes made
+// on another instance before its invalidation arrives through the backplane.
+const syncMemberCacheTTL = 10 * time.Second
+
+type syncService struct {
+	eventLog         port.EventLogPort
+	conversationRepo repository.ConversationRepository
+
+	memberCache   map[uuid.UUID]syncMemberCacheEntry
+	memberCacheMu sync.Mutex
+}
+
+type syncMemberCacheEntry struct {
+	userIDs   []uuid.UUID
+	expiresAt time.Time
+}
+
+// NewSyncService creates a new sync service
+// eventLog may be nil (no Redis), in which case events are not recorded
+func NewSyncService(
+	eventLog port.EventLogPort,
+	conversationRepo repository.ConversationRepository,
+) service.SyncService {
+	return &syncService{
+		eventLog:         eventLog,
+		conversationRepo: conversationRepo,
+		memberCache:      make(map[uuid.UUID]syncMemberCacheEntry),
+	}
+}
+
+// RecordUserEvent records an event for the given users
+func (s *syncService) RecordUserEvent(ctx context.Context, userIDs []uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error) {
+	if s.eventLog == nil || len(userIDs) == 0 {
+		return nil, nil
+	}
+	return s.eventLog.Append(ctx, uniqueUserIDs(userIDs), eventType, data)
+}
+
+// RecordConversationEvent records an event for every member of a conversation
+func (s *syncService) RecordConversationEvent(ctx context.Context, conversationID uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error) {
+	if s.eventLog == nil {
+		return nil, nil
+	}
+
+	userIDs, err := s.conversationMemberIDs(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.RecordUserEvent(ctx, userIDs, eventType, data)
+}
+
+// InvalidateConversationMembers drops the cached member list of a conversation
+func (s *syncService) InvalidateConversationMembers(conversationID uuid.UUID) {
+	s.memberCacheMu.Lock()
+	delete(s.memberCache, conversationID)
+	s.memberCacheMu.Unlock()
+}
+
+// conversationMemberIDs returns the member IDs of a conversation, cached for syncMemberCacheTTL
+func (s *syncService) conversationMemberIDs(conversationID uuid.UUID) ([]uuid.UUID, error) {
+	now := time.Now()
+
+	s.memberCacheMu.Lock()
+	entry, ok := s.memberCache[conversationID]
+	s.memberCacheMu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.userIDs, nil
+	}
+
+	members, err := s.conversationRepo.GetMembers(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+
+	s.memberCacheMu.Lock()
+	s.memberCache[conversationID] = syncMemberCacheEntry{userIDs: userIDs, expiresAt: now.Add(syncMemberCacheTTL)}
+	s.memberCacheMu.Unlock()
+
+	return userIDs, nil
+}
+
+// GetEventsSince returns the user's events after the given sequence
+func (s *syncService) GetEventsSince(ctx context.Context, userID uuid.UUID, since int64, limit int) (*dto.SyncEventsDTO, error) {
+	if s.eventLog == nil {
+		return nil, errors.New("event sync is not available")
+	}
+	if since < 0 {
+		return nil, errors.New("since must not be negative")
+	}
+
+	if limit <= 0 {
+		limit = defaultSyncEventLimit
+	}
+	if limit > maxSyncEventLimit {
+		limit = maxSyncEventLimit
+	}
+
+	return s.eventLog.Since(ctx, userID, since, limit)
+}
+
+// uniqueUserIDs removes duplicate user IDs, keeping the original order
+func uniqueUserIDs(userIDs []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	result := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
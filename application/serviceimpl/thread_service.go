@@ -0,0 +1,308 @@
+// application/serviceimpl/thread_service.go
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// Page size limits for thread replies
+const (
+	defaultThreadReplyLimit = 50
+	maxThreadReplyLimit     = 100
+)
+
+type threadService struct {
+	messageRepo         repository.MessageRepository
+	conversationRepo    repository.ConversationRepository
+	subscriptionRepo    repository.ThreadSubscriptionRepository
+	conversationService service.ConversationService
+	notificationService service.NotificationService
+	mediaAccess         service.MediaAccessService
+}
+
+// NewThreadService creates a new thread service
+func NewThreadService(
+	messageRepo repository.MessageRepository,
+	conversationRepo repository.ConversationRepository,
+	subscriptionRepo repository.ThreadSubscriptionRepository,
+	conversationService service.ConversationService,
+	notificationService service.NotificationService,
+	mediaAccess service.MediaAccessService,
+) service.ThreadService {
+	return &threadService{
+		messageRepo:         messageRepo,
+		conversationRepo:    conversationRepo,
+		subscriptionRepo:    subscriptionRepo,
+		conversationService: conversationService,
+		notificationService: notificationService,
+		mediaAccess:         mediaAccess,
+	}
+}
+
+// ReplyInThread creates a reply in the thread of a root message
+func (s *threadService) ReplyInThread(ctx context.Context, messageID, userID uuid.UUID, messageType, content, mediaURL, thumbnailURL string, metadata map[string]interface{}) (*dto.MessageDTO, error) {
+	root, err := s.getThreadRoot(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if root.IsDeleted {
+		return nil, errors.New("cannot reply to deleted message")
+	}
+
+	switch messageType {
+	case "text":
+		if strings.TrimSpace(content) == "" {
+			return nil, errors.New("message content is required")
+		}
+	case "sticker":
+		if mediaURL == "" {
+			return nil, errors.New("sticker URL is required")
+		}
+	case "image", "file":
+		if mediaURL == "" {
+			return nil, errors.New("media URL is required")
+		}
+	default:
+		return nil, errors.New("invalid message type")
+	}
+
+	// group permission policy applies to thread replies as well
+	slowMode, err := requireSendPermission(s.conversationRepo, root.ConversationID, userID, messageType)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonMetadata := types.JSONB{}
+	for k, v := range metadata {
+		jsonMetadata[k] = v
+	}
+
+	// private media mode stores only the storage path
+	if s.mediaAccess != nil {
+		mediaURL = s.mediaAccess.StorageRef(mediaURL)
+		thumbnailURL = s.mediaAccess.StorageRef(thumbnailURL)
+	}
+
+	// ข้อความใน thread ไม่อัปเดตข้อความล่าสุดของการสนทนา และไม่นับเป็น unread ใน timeline หลัก
+	now := time.Now()
+	reply := &models.Message{
+		ID:                uuid.New(),
+		ConversationID:    root.ConversationID,
+		SenderID:          &userID,
+		SenderType:        "user",
+		MessageType:       messageType,
+		Content:           content,
+		MediaURL:          mediaURL,
+		MediaThumbnailURL: thumbnailURL,
+		ThreadRootID:      &root.ID,
+		Metadata:          jsonMetadata,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := createSentMessages(s.messageRepo, s.conversationRepo, userID, slowMode, reply); err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.IncrementThreadReplyCount(root.ID, now); err != nil {
+		return nil, err
+	}
+	root.ThreadReplyCount++
+	root.ThreadLastReplyAt = &now
+
+	// ผู้ตอบติดตาม thread อัตโนมัติ และเจ้าของ root message ติดตามตั้งแต่มีการตอบกลับครั้งแรก
+	followers := []uuid.UUID{userID}
+	if root.ThreadReplyCount == 1 && root.SenderID != nil && *root.SenderID != userID {
+		followers = append(followers, *root.SenderID)
+	}
+	for _, followerID := range followers {
+		if err := s.subscribe(ctx, root, followerID); err != nil {
+			log.Printf("Failed to subscribe user %s to thread %s: %v", followerID, root.ID, err)
+		}
+	}
+
+	replyDTO, err := s.conversationService.ConvertToMessageDTO(reply, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broadcastThreadReply(ctx, root, replyDTO, now)
+
+	return replyDTO, nil
+}
+
+// GetThreadReplies lists replies under a root message with cursor pagination
+func (s *threadService) GetThreadReplies(ctx context.Context, messageID, userID uuid.UUID, cursor string, limit int) (*dto.ThreadRepliesDTO, error) {
+	root, err := s.getThreadRoot(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultThreadReplyLimit
+	}
+	if limit > maxThreadReplyLimit {
+		limit = maxThreadReplyLimit
+	}
+
+	var afterID *uuid.UUID
+	if cursor != "" {
+		id, err := uuid.Parse(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		cursorMessage, err := s.messageRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if cursorMessage == nil || cursorMessage.ThreadRootID == nil || *cursorMessage.ThreadRootID != root.ID {
+			return nil, errors.New("invalid cursor")
+		}
+		afterID = &id
+	}
+
+	// ดึงเกิน 1 รายการเพื่อตรวจว่ายังมีหน้าถัดไปหรือไม่
+	replies, err := s.messageRepo.GetThreadReplies(root.ID, afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(replies) > limit
+	if hasMore {
+		replies = replies[:limit]
+	}
+
+	rootDTO, err := s.conversationService.ConvertToMessageDTO(root, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	replyDTOs := make([]*dto.MessageDTO, 0, len(replies))
+	for _, reply := range replies {
+		replyDTO, err := s.conversationService.ConvertToMessageDTO(reply, userID)
+		if err != nil {
+			return nil, err
+		}
+		replyDTOs = append(replyDTOs, replyDTO)
+	}
+
+	isFollowing, err := s.subscriptionRepo.IsSubscribed(ctx, root.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.ThreadRepliesDTO{
+		Root:        rootDTO,
+		Replies:     replyDTOs,
+		HasMore:     hasMore,
+		IsFollowing: isFollowing,
+	}
+	if hasMore {
+		next := replies[len(replies)-1].ID.String()
+		result.NextCursor = &next
+	}
+
+	return result, nil
+}
+
+// FollowThread subscribes the user to thread.reply events of a thread
+func (s *threadService) FollowThread(ctx context.Context, messageID, userID uuid.UUID) (*dto.ThreadFollowDTO, error) {
+	root, err := s.getThreadRoot(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.subscribe(ctx, root, userID); err != nil {
+		return nil, err
+	}
+
+	return &dto.ThreadFollowDTO{RootMessageID: root.ID, IsFollowing: true}, nil
+}
+
+// UnfollowThread unsubscribes the user from a thread
+func (s *threadService) UnfollowThread(ctx context.Context, messageID, userID uuid.UUID) (*dto.ThreadFollowDTO, error) {
+	root, err := s.getThreadRoot(messageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Unsubscribe(ctx, root.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return &dto.ThreadFollowDTO{RootMessageID: root.ID, IsFollowing: false}, nil
+}
+
+// getThreadRoot loads the root message of a thread and checks that the user can see it.
+// A thread reply resolves to its root, so threads are never nested.
+func (s *threadService) getThreadRoot(messageID, userID uuid.UUID) (*models.Message, error) {
+	message, err := s.messageRepo.GetByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, errors.New("message not found")
+	}
+
+	if message.ThreadRootID != nil {
+		message, err = s.messageRepo.GetByID(*message.ThreadRootID)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil {
+			return nil, errors.New("message not found")
+		}
+	}
+
+	isMember, err := s.conversationRepo.IsMember(message.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, errors.New("user is not a member of this conversation")
+	}
+
+	return message, nil
+}
+
+// subscribe adds a thread subscription for the user
+func (s *threadService) subscribe(ctx context.Context, root *models.Message, userID uuid.UUID) error {
+	return s.subscriptionRepo.Subscribe(ctx, &models.ThreadSubscription{
+		ID:             uuid.New(),
+		MessageID:      root.ID,
+		ConversationID: root.ConversationID,
+		UserID:         userID,
+		CreatedAt:      time.Now(),
+	})
+}
+
+// broadcastThreadReply sends thread.reply to thread followers only
+func (s *threadService) broadcastThreadReply(ctx context.Context, root *models.Message, reply *dto.MessageDTO, replyAt time.Time) {
+	if s.notificationService == nil {
+		return
+	}
+
+	followerIDs, err := s.subscriptionRepo.GetSubscriberIDs(ctx, root.ID)
+	if err != nil {
+		log.Printf("Failed to get followers of thread %s: %v", root.ID, err)
+		return
+	}
+
+	s.notificationService.NotifyThreadReply(followerIDs, &dto.ThreadReplyEventDTO{
+		RootMessageID:    root.ID,
+		ConversationID:   root.ConversationID,
+		Message:          reply,
+		ThreadReplyCount: root.ThreadReplyCount,
+		LastReplyAt:      replyAt,
+	})
+}
@@ -0,0 +1,282 @@
+// application/serviceimpl/two_factor_service.go
+package serviceimpl
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/pkg/totp"
+)
+
+const (
+	recoveryCodeCount      = 10
+	recoveryCodeBytes      = 5 // 8 ตัวอักษร base32 แสดงเป็น xxxx-xxxx
+	totpAllowedSkew        = 1 // ยอมรับโค้ดของช่วงก่อนหน้า/ถัดไป (นาฬิกาเครื่องผู้ใช้คลาดเคลื่อน)
+	twoFactorMaxAttempts   = 5
+	twoFactorLockoutPeriod = 5 * time.Minute
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type twoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	issuer        string // ชื่อที่แสดงในแอป authenticator
+}
+
+// NewTwoFactorService สร้าง TwoFactorService
+func NewTwoFactorService(
+	twoFactorRepo repository.TwoFactorRepository,
+	userRepo repository.UserRepository,
+	issuer string,
+) service.TwoFactorService {
+	if strings.TrimSpace(issuer) == "" {
+		issuer = "Chat"
+	}
+	return &twoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+	}
+}
+
+// BeginEnrollment สร้าง secret ใหม่ การลงทะเบียนที่ยังไม่ยืนยันก่อนหน้าจะถูกแทนที่
+func (s *twoFactorService) BeginEnrollment(userID uuid.UUID) (*dto.TwoFactorEnrollmentResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	existing, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret: " + err.Error())
+	}
+
+	twoFactor := &models.UserTwoFactor{
+		UserID: userID,
+		Secret: secret,
+	}
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+
+	return &dto.TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.KeyURI(s.issuer, account, secret),
+	}, nil
+}
+
+// ConfirmEnrollment เปิดใช้ 2FA เมื่อโค้ดจาก authenticator ถูกต้อง
+func (s *twoFactorService) ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, errors.New("two-factor enrollment not started")
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(twoFactor.Secret, code, now, totpAllowedSkew)
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	twoFactor.LastUsedStep = step
+	twoFactor.FailedAttempts = 0
+	twoFactor.LockedUntil = nil
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(userID)
+}
+
+// Disable ปิด 2FA และลบโค้ดสำรองทั้งหมด
+func (s *twoFactorService) Disable(userID uuid.UUID, code string) error {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.Delete(userID)
+}
+
+// RegenerateRecoveryCodes โค้ดสำรองชุดเดิม (รวมที่ยังไม่ได้ใช้) จะใช้ไม่ได้อีก
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(userID)
+}
+
+func (s *twoFactorService) GetStatus(userID uuid.UUID) (*dto.TwoFactorStatusResponse, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return &dto.TwoFactorStatusResponse{Enabled: false}, nil
+	}
+
+	remaining, err := s.twoFactorRepo.CountUnusedRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorStatusResponse{
+		Enabled:                true,
+		EnabledAt:              twoFactor.EnabledAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+func (s *twoFactorService) IsEnabled(userID uuid.UUID) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor != nil && twoFactor.Enabled, nil
+}
+
+// VerifyCode โค้ด 6 หลักตรวจด้วย TOTP (แต่ละ time step ใช้ได้ครั้งเดียว) นอกนั้นตรวจเป็นโค้ดสำรอง
+// ใส่ผิดครบ twoFactorMaxAttempts ครั้งจะถูกล็อก twoFactorLockoutPeriod
+func (s *twoFactorService) VerifyCode(userID uuid.UUID, code string) error {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	now := time.Now()
+	if twoFactor.IsLocked(now) {
+		return errors.New("too many failed attempts, try again later")
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.New("code is required")
+	}
+
+	verified := false
+	if isTOTPCode(code) {
+		if step, ok := totp.Validate(twoFactor.Secret, code, now, totpAllowedSkew); ok {
+			verified, err = s.twoFactorRepo.MarkStepUsed(userID, step)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		verified, err = s.twoFactorRepo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)), now)
+		if err != nil {
+			return err
+		}
+		if verified {
+			log.Printf("Recovery code used: user=%s", userID)
+		}
+	}
+
+	if !verified {
+		s.recordFailure(twoFactor, now)
+		return errors.New("invalid two-factor code")
+	}
+
+	if twoFactor.FailedAttempts > 0 {
+		if err := s.twoFactorRepo.UpdateFailures(userID, 0, nil); err != nil {
+			log.Printf("Failed to reset 2FA attempts for user %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+// recordFailure นับครั้งที่ใส่โค้ดผิด เมื่อครบกำหนดจะล็อกและเริ่มนับใหม่
+// จำนวนครั้งเพิ่มใน database แบบ atomic การเดาพร้อมกันหลาย request จึงนับครบทุกครั้ง
+func (s *twoFactorService) recordFailure(twoFactor *models.UserTwoFactor, now time.Time) {
+	attempts, err := s.twoFactorRepo.IncrementFailures(twoFactor.UserID)
+	if err != nil {
+		log.Printf("Failed to record 2FA failure for user %s: %v", twoFactor.UserID, err)
+		return
+	}
+	if attempts < twoFactorMaxAttempts {
+		return
+	}
+
+	until := now.Add(twoFactorLockoutPeriod)
+	if err := s.twoFactorRepo.UpdateFailures(twoFactor.UserID, 0, &until); err != nil {
+		log.Printf("Failed to lock 2FA for user %s: %v", twoFactor.UserID, err)
+		return
+	}
+	log.Printf("2FA locked for user %s until %s", twoFactor.UserID, until.Format(time.RFC3339))
+}
+
+// issueRecoveryCodes สร้างโค้ดสำรองชุดใหม่ เก็บเฉพาะ hash และคืนโค้ดจริงให้ผู้ใช้
+func (s *twoFactorService) issueRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*models.UserRecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, errors.New("failed to generate recovery codes: " + err.Error())
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		records = append(records, &models.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashToken(raw),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// isTOTPCode โค้ดจาก authenticator เป็นตัวเลข 6 หลัก (อนุญาตช่องว่างตรงกลาง)
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRecoveryCode ตัดขีด/ช่องว่างและเปลี่ยนเป็นตัวพิมพ์เล็ก ผู้ใช้พิมพ์ได้ทั้ง "ABCD-EFGH" และ "abcdefgh"
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
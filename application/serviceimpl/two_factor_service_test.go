@@ -0,0 +1,160 @@
+// application/serviceimpl/two_factor_service_test.go
+package serviceimpl_test
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/pkg/totp"
+)
+
+// enableTwoFactor ลงทะเบียนและยืนยัน 2FA คืน secret และโค้ดสำรอง
+func (f *fixture) enableTwoFactor(user *models.User) (string, []string) {
+	f.t.Helper()
+
+	enrollment, err := f.twoFactorService.BeginEnrollment(user.ID)
+	mustNoError(f.t, err)
+
+	uri, err := url.Parse(enrollment.OTPAuthURI)
+	mustNoError(f.t, err)
+	if uri.Scheme != "otpauth" || uri.Query().Get("secret") != enrollment.Secret {
+		f.t.Fatalf("unexpected otpauth uri %s", enrollment.OTPAuthURI)
+	}
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	mustNoError(f.t, err)
+	recoveryCodes, err := f.twoFactorService.ConfirmEnrollment(user.ID, code)
+	mustNoError(f.t, err)
+
+	return enrollment.Secret, recoveryCodes
+}
+
+func TestLogin_TwoFactorChallenge(t *testing.T) {
+	f := newFixture(t)
+
+	user, _, _, err := f.authService.Register("alice", "secret123", "alice@example.com", "Alice", dto.DeviceInfo{})
+	mustNoError(t, err)
+	secret, _ := f.enableTwoFactor(user)
+
+	// รหัสผ่านถูกต้องแต่ยังไม่ได้ token
+	_, accessToken, _, err := f.authService.Login("alice", "secret123", dto.DeviceInfo{DeviceName: "MacBook", Platform: "desktop"})
+	challenge, ok := err.(*service.TwoFactorRequiredError)
+	if !ok {
+		t.Fatalf("expected TwoFactorRequiredError, got %v", err)
+	}
+	if accessToken != "" || challenge.ChallengeToken == "" {
+		t.Fatal("expected challenge token only")
+	}
+
+	// ใช้โค้ดของ time step ถัดไป (step ปัจจุบันถูกใช้ตอนยืนยันการลงทะเบียนแล้ว)
+	code, err := totp.Code(secret, totp.Step(time.Now())+1)
+	mustNoError(t, err)
+
+	_, _, _, err = f.authService.VerifyTwoFactorLogin(challenge.ChallengeToken, "000000", dto.DeviceInfo{})
+	expectError(t, err, "invalid two-factor code")
+
+	loggedIn, accessToken, refreshToken, err := f.authService.VerifyTwoFactorLogin(challenge.ChallengeToken, code, dto.DeviceInfo{IPAddress: "10.0.0.1"})
+	mustNoError(t, err)
+	if loggedIn.ID != user.ID || accessToken == "" || refreshToken == "" {
+		t.Fatal("expected tokens after second factor")
+	}
+
+	// โค้ดเดิมใช้ซ้ำไม่ได้
+	_, _, _, err = f.authService.VerifyTwoFactorLogin(challenge.ChallengeToken, code, dto.DeviceInfo{})
+	expectError(t, err, "invalid two-factor code")
+
+	// session ใช้ชื่ออุปกรณ์จากขั้นตอนใส่รหัสผ่าน
+	sessions, err := f.sessionService.ListSessions(user.ID, uuid.Nil)
+	mustNoError(t, err)
+	found := false
+	for _, s := range sessions {
+		if s.DeviceName == "MacBook" && s.Platform == "desktop" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected session with device info from the challenge")
+	}
+
+	// challenge token ใช้แทน access token ไม่ได้ และ access token ใช้เป็น challenge ไม่ได้
+	_, _, _, err = f.authService.VerifyTwoFactorLogin(accessToken, code, dto.DeviceInfo{})
+	expectError(t, err, "invalid or expired challenge")
+}
+
+func TestTwoFactor_RecoveryCodeSingleUse(t *testing.T) {
+	f := newFixture(t)
+
+	user := f.createUser("alice")
+	_, recoveryCodes := f.enableTwoFactor(user)
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("expected 10 recovery codes, got %d", len(recoveryCodes))
+	}
+
+	// รับโค้ดได้ทั้งตัวพิมพ์ใหญ่และไม่มีขีด
+	code := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	mustNoError(t, f.twoFactorService.VerifyCode(user.ID, code))
+	expectError(t, f.twoFactorService.VerifyCode(user.ID, recoveryCodes[0]), "invalid two-factor code")
+
+	status, err := f.twoFactorService.GetStatus(user.ID)
+	mustNoError(t, err)
+	if !status.Enabled || status.RecoveryCodesRemaining != 9 {
+		t.Fatalf("expected enabled with 9 codes remaining, got %+v", status)
+	}
+
+	// สร้างชุดใหม่แล้วชุดเดิมใช้ไม่ได้
+	newCodes, err := f.twoFactorService.RegenerateRecoveryCodes(user.ID, recoveryCodes[1])
+	mustNoError(t, err)
+	expectError(t, f.twoFactorService.VerifyCode(user.ID, recoveryCodes[2]), "invalid two-factor code")
+
+	mustNoError(t, f.twoFactorService.Disable(user.ID, newCodes[0]))
+	enabled, err := f.twoFactorService.IsEnabled(user.ID)
+	mustNoError(t, err)
+	if enabled {
+		t.Fatal("expected two-factor to be disabled")
+	}
+}
+
+func TestTwoFactor_LocksAfterRepeatedFailures(t *testing.T) {
+	f := newFixture(t)
+
+	user := f.createUser("alice")
+	secret, _ := f.enableTwoFactor(user)
+
+	for i := 0; i < 5; i++ {
+		expectError(t, f.twoFactorService.VerifyCode(user.ID, "000000"), "invalid two-factor code")
+	}
+
+	// ล็อกแล้วแม้โค้ดถูกก็ถูกปฏิเสธ
+	code, err := totp.Code(secret, totp.Step(time.Now())+1)
+	mustNoError(t, err)
+	expectError(t, f.twoFactorService.VerifyCode(user.ID, code), "too many failed attempts")
+}
+
+func TestTwoFactor_ConcurrentGuessesAreAllCounted(t *testing.T) {
+	f := newFixture(t)
+
+	user := f.createUser("alice")
+	secret, _ := f.enableTwoFactor(user)
+
+	// เดาพร้อมกันครบจำนวนครั้งที่กำหนด ทุกครั้งต้องถูกนับ (ไม่ใช่อ่านค่าเดิมแล้วเขียนทับกัน)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = f.twoFactorService.VerifyCode(user.ID, "000000")
+		}()
+	}
+	wg.Wait()
+
+	code, err := totp.Code(secret, totp.Step(time.Now())+1)
+	mustNoError(t, err)
+	expectError(t, f.twoFactorService.VerifyCode(user.ID, code), "too many failed attempts")
+}
@@ -0,0 +1,112 @@
+// application/serviceimpl/user_friendship_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFriendRequestLifecycle(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+	carol := f.createUser("carol")
+
+	_, err := f.friendshipService.SendFriendRequest(alice.ID, alice.ID)
+	expectError(t, err, "cannot send friend request to yourself")
+
+	_, err = f.friendshipService.SendFriendRequest(alice.ID, uuid.New())
+	expectError(t, err, "user not found")
+
+	request, err := f.friendshipService.SendFriendRequest(alice.ID, bob.ID)
+	mustNoError(t, err)
+
+	_, err = f.friendshipService.SendFriendRequest(alice.ID, bob.ID)
+	expectError(t, err, "friend request already exists")
+
+	pending, direction, err := f.friendshipService.HasPendingRequest(bob.ID, alice.ID)
+	mustNoError(t, err)
+	if !pending || direction != "received" {
+		t.Fatalf("bob should have a received request, got %v %q", pending, direction)
+	}
+
+	// ผู้ส่งคำขอตอบรับคำขอของตัวเองไม่ได้
+	_, err = f.friendshipService.AcceptFriendRequest(request.ID, alice.ID)
+	expectError(t, err, "friend request not found or already processed")
+
+	_, err = f.friendshipService.AcceptFriendRequest(request.ID, bob.ID)
+	mustNoError(t, err)
+
+	isFriend, err := f.friendshipService.IsFriend(alice.ID, bob.ID)
+	mustNoError(t, err)
+	if !isFriend {
+		t.Fatal("alice and bob should be friends")
+	}
+
+	friends, err := f.friendshipService.GetFriends(bob.ID)
+	mustNoError(t, err)
+	if len(friends) != 1 || friends[0].ID != alice.ID {
+		t.Fatalf("unexpected friends: %+v", friends)
+	}
+
+	// คำขอที่ถูกปฏิเสธส่งใหม่ได้
+	rejected, err := f.friendshipService.SendFriendRequest(carol.ID, alice.ID)
+	mustNoError(t, err)
+	_, err = f.friendshipService.RejectFriendRequest(rejected.ID, alice.ID)
+	mustNoError(t, err)
+
+	resent, err := f.friendshipService.SendFriendRequest(carol.ID, alice.ID)
+	mustNoError(t, err)
+	if resent.ID != rejected.ID || resent.Status != "pending" {
+		t.Fatalf("rejected request should be reused, got %+v", resent)
+	}
+
+	err = f.friendshipService.CancelFriendRequest(resent.ID, alice.ID)
+	expectError(t, err, "you can only cancel your own friend requests")
+
+	mustNoError(t, f.friendshipService.CancelFriendRequest(resent.ID, carol.ID))
+	status, _, err := f.friendshipService.GetFriendshipStatus(carol.ID, alice.ID)
+	mustNoError(t, err)
+	if status != "none" {
+		t.Fatalf("expected no relationship after cancel, got %q", status)
+	}
+}
+
+func TestBlockAndUnblock(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+	bob := f.createUser("bob")
+
+	request, err := f.friendshipService.SendFriendRequest(alice.ID, bob.ID)
+	mustNoError(t, err)
+	_, err = f.friendshipService.AcceptFriendRequest(request.ID, bob.ID)
+	mustNoError(t, err)
+
+	mustNoError(t, f.friendshipService.BlockUser(bob.ID, alice.ID))
+
+	// การบล็อกลบความเป็นเพื่อนเดิม
+	isFriend, err := f.friendshipService.IsFriend(alice.ID, bob.ID)
+	mustNoError(t, err)
+	if isFriend {
+		t.Fatal("blocking should remove the friendship")
+	}
+
+	isBlocked, isBlockedBy, err := f.friendshipService.CheckBlockStatus(alice.ID, bob.ID)
+	mustNoError(t, err)
+	if isBlocked || !isBlockedBy {
+		t.Fatalf("alice should be blocked by bob, got blocked=%v blockedBy=%v", isBlocked, isBlockedBy)
+	}
+
+	err = f.friendshipService.UnblockUser(alice.ID, bob.ID)
+	if err == nil {
+		t.Fatal("alice has not blocked bob, unblock should fail")
+	}
+
+	mustNoError(t, f.friendshipService.UnblockUser(bob.ID, alice.ID))
+	blocked, err := f.friendshipService.IsBlocked(bob.ID, alice.ID)
+	mustNoError(t, err)
+	if blocked {
+		t.Fatal("bob should no longer block alice")
+	}
+}
@@ -142,5 +142,12 @@ func (s *userService) SearchUsersExact(query string, limit, offset int) ([]*mode
 
 // เพิ่มเมธอดนี้
 func (s *userService) GetUserByEmail(email string) (*models.User, error) {
-	return s.userRepo.FindByEmail(email)
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
 }
@@ -0,0 +1,277 @@
+// application/serviceimpl/verification_service.go
+package serviceimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = time.Hour * 24
+	minPasswordLength    = 8
+	mailSendTimeout      = 15 * time.Second
+
+	// จำนวนอีเมลรีเซ็ตรหัสผ่านสูงสุดต่อบัญชีใน passwordResetWindow (กันการยิงอีเมลใส่ผู้ใช้)
+	maxPasswordResetsPerWindow = 3
+	passwordResetWindow        = time.Hour
+)
+
+type verificationService struct {
+	userRepo         repository.UserRepository
+	tokenRepo        repository.VerificationTokenRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionService   service.SessionService
+	mailer           port.MailerPort
+	appURL           string // URL ของ frontend สำหรับสร้างลิงก์ในอีเมล
+}
+
+// NewVerificationService สร้าง VerificationService
+func NewVerificationService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.VerificationTokenRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionService service.SessionService,
+	mailer port.MailerPort,
+	appURL string,
+) service.VerificationService {
+	return &verificationService{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionService:   sessionService,
+		mailer:           mailer,
+		appURL:           strings.TrimRight(appURL, "/"),
+	}
+}
+
+// RequestPasswordReset ส่งลิงก์รีเซ็ตรหัสผ่าน โค้ดเดิมที่ยังไม่ได้ใช้จะถูกยกเลิก
+// คืนผลสำเร็จเหมือนกันทุกกรณีที่เกี่ยวกับบัญชี (ไม่พบอีเมล เกินจำนวนที่จำกัด ส่งอีเมลไม่สำเร็จ)
+// เพื่อไม่ให้ใช้ endpoint นี้ตรวจว่าอีเมลใดมีบัญชีอยู่
+func (s *verificationService) RequestPasswordReset(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Status != "active" {
+		return nil
+	}
+
+	issued, err := s.tokenRepo.CountIssuedSince(user.ID, models.TokenPurposePasswordReset, time.Now().Add(-passwordResetWindow))
+	if err != nil {
+		return err
+	}
+	if issued >= maxPasswordResetsPerWindow {
+		log.Printf("Password reset limit reached for user %s", user.ID)
+		return nil
+	}
+
+	token, err := s.issueToken(user, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	// send บันทึก log เมื่อส่งไม่สำเร็จแล้ว ผู้เรียกได้ผลเหมือนกรณีไม่พบอีเมล
+	_ = s.send(port.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		TextBody: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThis link expires in %d minutes and can only be used once. If you did not request a reset, you can ignore this email.",
+			displayNameOf(user), link, int(passwordResetTTL.Minutes()),
+		),
+	})
+	return nil
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ แล้วเพิกถอนทุก session และ refresh token ของผู้ใช้
+func (s *verificationService) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	verification, err := s.consumeToken(models.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(verification.UserID)
+	if err != nil {
+		return errors.New("invalid or expired token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password: " + err.Error())
+	}
+	user.PasswordHash = string(hashedPassword)
+
+	// ลิงก์รีเซ็ตถูกส่งไปยังอีเมลนี้ การรีเซ็ตสำเร็จจึงยืนยันอีเมลไปด้วย
+	if user.EmailVerifiedAt == nil && user.Email == verification.Email {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	// โค้ดรีเซ็ตอื่นที่ยังค้างอยู่ใช้ไม่ได้อีก
+	if err := s.tokenRepo.InvalidateByUserID(user.ID, models.TokenPurposePasswordReset, time.Now()); err != nil {
+		log.Printf("Failed to invalidate password reset tokens of user %s: %v", user.ID, err)
+	}
+
+	// ออกจากระบบทุกอุปกรณ์ (รวม token รุ่นเก่าที่ไม่มี session)
+	if s.sessionService != nil {
+		if _, err := s.sessionService.RevokeOtherSessions(user.ID, uuid.Nil); err != nil {
+			log.Printf("Failed to revoke sessions of user %s: %v", user.ID, err)
+		}
+	}
+	if err := s.refreshTokenRepo.RevokeByUserID(user.ID); err != nil {
+		log.Printf("Failed to revoke refresh tokens of user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// SendEmailVerification ส่งลิงก์ยืนยันอีเมล
+func (s *verificationService) SendEmailVerification(userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if user.Email == "" {
+		return errors.New("user has no email")
+	}
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email already verified")
+	}
+
+	token, err := s.issueToken(user, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.send(port.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		TextBody: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that %s is your email address by opening the link below:\n\n%s\n\nThis link expires in %d hours.",
+			displayNameOf(user), user.Email, link, int(emailVerificationTTL.Hours()),
+		),
+	})
+}
+
+// VerifyEmail ยืนยันอีเมล (ใช้ไม่ได้ถ้าผู้ใช้เปลี่ยนอีเมลหลังจากได้รับลิงก์)
+func (s *verificationService) VerifyEmail(token string) error {
+	verification, err := s.consumeToken(models.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(verification.UserID)
+	if err != nil || user.Email != verification.Email {
+		return errors.New("invalid or expired token")
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// issueToken สร้างโค้ดใหม่ (ยกเลิกโค้ดเดิมที่ยังไม่ได้ใช้) คืนโค้ดจริงสำหรับใส่ในอีเมล
+func (s *verificationService) issueToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	if err := s.tokenRepo.InvalidateByUserID(user.ID, purpose, now); err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate token: " + err.Error())
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := s.tokenRepo.Create(&models.VerificationToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}); err != nil {
+		return "", errors.New("failed to save token: " + err.Error())
+	}
+
+	return token, nil
+}
+
+// consumeToken ตรวจและใช้โค้ด (ใช้ได้ครั้งเดียว)
+func (s *verificationService) consumeToken(purpose, token string) (*models.VerificationToken, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+
+	verification, err := s.tokenRepo.FindByTokenHash(purpose, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if verification == nil || !verification.IsUsable(now) {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	used, err := s.tokenRepo.MarkUsed(verification.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	return verification, nil
+}
+
+func (s *verificationService) send(msg port.MailMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send email to %s: %v", msg.To, err)
+		return errors.New("failed to send email")
+	}
+	return nil
+}
+
+func displayNameOf(user *models.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
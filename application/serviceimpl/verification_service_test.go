@@ -0,0 +1,101 @@
+// application/serviceimpl/verification_service_test.go
+package serviceimpl_test
+
+import (
+	"testing"
+
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+func TestPasswordReset_SingleUseAndSignsOutEverywhere(t *testing.T) {
+	f := newFixture(t)
+
+	_, _, refreshToken, err := f.authService.Register("alice", "old-password", "alice@example.com", "Alice", dto.DeviceInfo{DeviceName: "iPhone"})
+	mustNoError(t, err)
+
+	mustNoError(t, f.verificationService.RequestPasswordReset("alice@example.com"))
+	token := f.mailer.lastToken(t, "alice@example.com")
+
+	expectError(t, f.verificationService.ResetPassword(token, "short"), "password must be at least 8 characters")
+	mustNoError(t, f.verificationService.ResetPassword(token, "new-password"))
+	expectError(t, f.verificationService.ResetPassword(token, "another-password"), "invalid or expired token")
+
+	if _, _, _, err := f.authService.Login("alice", "old-password", dto.DeviceInfo{}); err == nil {
+		t.Fatal("expected the old password to be rejected")
+	}
+	if _, _, _, err := f.authService.Login("alice", "new-password", dto.DeviceInfo{}); err != nil {
+		t.Fatalf("login with new password: %v", err)
+	}
+	if _, _, err := f.authService.RefreshToken(refreshToken, ""); err == nil {
+		t.Fatal("expected sessions from before the reset to be revoked")
+	}
+}
+
+func TestPasswordReset_NewRequestInvalidatesOlderCode(t *testing.T) {
+	f := newFixture(t)
+	f.createUser("alice")
+
+	// อีเมลที่ไม่มีในระบบไม่ทำให้เกิด error และไม่ส่งอีเมล
+	mustNoError(t, f.verificationService.RequestPasswordReset("nobody@example.com"))
+	if len(f.mailer.sent) != 0 {
+		t.Fatalf("expected no email for unknown address, got %d", len(f.mailer.sent))
+	}
+
+	mustNoError(t, f.verificationService.RequestPasswordReset("alice@example.com"))
+	first := f.mailer.lastToken(t, "alice@example.com")
+	mustNoError(t, f.verificationService.RequestPasswordReset("alice@example.com"))
+	second := f.mailer.lastToken(t, "alice@example.com")
+
+	expectError(t, f.verificationService.ResetPassword(first, "new-password"), "invalid or expired token")
+	mustNoError(t, f.verificationService.ResetPassword(second, "new-password"))
+}
+
+func TestPasswordReset_LimitAndMailFailureLookLikeSuccess(t *testing.T) {
+	f := newFixture(t)
+	f.createUser("alice")
+
+	// ส่งอีเมลไม่สำเร็จต้องได้ผลเหมือนอีเมลที่ไม่มีในระบบ
+	f.mailer.fail = true
+	mustNoError(t, f.verificationService.RequestPasswordReset("alice@example.com"))
+	f.mailer.fail = false
+
+	// รวมคำขอข้างบน บัญชีเดียวขอได้ 3 ครั้งต่อชั่วโมง เกินจากนั้นไม่ส่งอีเมลแต่ยังตอบสำเร็จ
+	for i := 0; i < 4; i++ {
+		mustNoError(t, f.verificationService.RequestPasswordReset("alice@example.com"))
+	}
+	if len(f.mailer.sent) != 2 {
+		t.Fatalf("expected 2 reset emails within the limit, got %d", len(f.mailer.sent))
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+
+	mustNoError(t, f.verificationService.SendEmailVerification(alice.ID))
+	token := f.mailer.lastToken(t, "alice@example.com")
+
+	expectError(t, f.verificationService.VerifyEmail("not-a-token"), "invalid or expired token")
+	mustNoError(t, f.verificationService.VerifyEmail(token))
+
+	user, err := f.userRepo.FindByID(alice.ID)
+	mustNoError(t, err)
+	if user.EmailVerifiedAt == nil {
+		t.Fatal("expected email to be verified")
+	}
+
+	expectError(t, f.verificationService.SendEmailVerification(alice.ID), "email already verified")
+}
+
+func TestVerifyEmail_RejectsCodeForPreviousEmail(t *testing.T) {
+	f := newFixture(t)
+	alice := f.createUser("alice")
+
+	mustNoError(t, f.verificationService.SendEmailVerification(alice.ID))
+	token := f.mailer.lastToken(t, "alice@example.com")
+
+	alice.Email = "alice@new.example.com"
+	mustNoError(t, f.userRepo.Update(alice))
+
+	expectError(t, f.verificationService.VerifyEmail(token), "invalid or expired token")
+}
@@ -55,8 +55,20 @@ func main() {
 	}
 	log.Println("Connected to Redis successfully")
 
-	// สร้าง container โดยส่ง storageService และ redisClient เข้าไป
-	container, err := di.NewContainer(database.DB, storageService, redisClient)
+	// ตั้งค่าการส่งอีเมล (รีเซ็ตรหัสผ่าน / ยืนยันอีเมล)
+	mailer, err := configs.SetupMailer()
+	if err != nil {
+		log.Fatalf("Mailer error: %v", err)
+	}
+
+	// ตั้งค่า push provider (Web Push / FCM / APNs) สำหรับผู้ใช้ที่ออฟไลน์
+	pushProviders, err := configs.SetupPushProviders()
+	if err != nil {
+		log.Fatalf("Push provider error: %v", err)
+	}
+
+	// สร้าง container โดยส่ง storageService, redisClient, mailer และ push providers เข้าไป
+	container, err := di.NewContainer(database.DB, storageService, redisClient, mailer, pushProviders)
 	if err != nil {
 		log.Fatalf("ไม่สามารถสร้าง DI container ได้: %v", err)
 	}
@@ -79,6 +91,12 @@ func main() {
 	go container.WebSocketHub.Run(ctx)
 	log.Println("WebSocket Hub started successfully")
 
+	// เริ่ม WebSocket backplane (Redis pub/sub สำหรับ multi-instance)
+	if container.WebSocketBackplane != nil {
+		go container.WebSocketBackplane.Start(ctx)
+		log.Println("WebSocket backplane started successfully")
+	}
+
 	// เริ่ม Typing Cache Cleanup Routine
 	websocket.StartTypingCacheCleanup()
 	log.Println("Typing cache cleanup routine started successfully")
@@ -87,6 +105,14 @@ func main() {
 	go container.FileCleanupScheduler.Start(ctx)
 	log.Println("File cleanup scheduler started successfully")
 
+	// เริ่ม Disappearing Message Sweeper
+	go container.DisappearingMessageSweeper.Start(ctx)
+	log.Println("Disappearing message sweeper started successfully")
+
+	// เริ่ม Poll Closer
+	go container.PollCloser.Start(ctx)
+	log.Println("Poll closer started successfully")
+
 	// เริ่ม Scheduled Message Processor
 	go container.ScheduledMessageProcessor.Start(ctx)
 	log.Println("Scheduled message processor started successfully")
@@ -131,6 +157,9 @@ func main() {
 		log.Fatalf("ผิดพลาดในการปิดเซิร์ฟเวอร์: %v", err)
 	}
 
+	// ส่ง push ที่ยังรอรวมอยู่ก่อนปิดการเชื่อมต่อฐานข้อมูล
+	container.PushService.Flush()
+
 	if err := database.Close(); err != nil {
 		log.Fatalf("ผิดพลาดในการปิดการเชื่อมต่อฐานข้อมูล: %v", err)
 	}
@@ -29,6 +29,35 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// PasswordResetRequest สำหรับขอลิงก์รีเซ็ตรหัสผ่าน
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ConfirmPasswordResetRequest สำหรับตั้งรหัสผ่านใหม่ด้วยโค้ดจากอีเมล
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// VerifyEmailRequest สำหรับยืนยันอีเมลด้วยโค้ดจากอีเมล
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// TwoFactorCodeRequest สำหรับรับโค้ด 2FA (โค้ดจาก authenticator หรือโค้ดสำรอง)
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorLoginRequest สำหรับเข้าสู่ระบบขั้นที่สองด้วย challenge token จาก login
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	DeviceName     string `json:"device_name,omitempty"`
+	Platform       string `json:"platform,omitempty"`
+}
+
 // ============ Response DTOs ============
 
 // UserResponse สำหรับข้อมูลผู้ใช้ที่ส่งกลับ (ใช้ร่วมกันในหลาย endpoint)
@@ -69,3 +98,16 @@ type UserProfileResponse struct {
 	Success bool         `json:"success"`
 	User    UserResponse `json:"user"`
 }
+
+// TwoFactorEnrollmentResponse secret และ otpauth URI สำหรับเพิ่มบัญชีในแอป authenticator
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorStatusResponse สถานะ 2FA ของผู้ใช้
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
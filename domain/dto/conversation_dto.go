@@ -72,13 +72,33 @@ type ConversationMuteRequest struct {
 	IsMuted bool `json:"is_muted" validate:"required"`
 }
 
+// NotificationSettingsRequest สำหรับตั้งค่าการแจ้งเตือนของการสนทนา (แทนที่ค่าเดิมทั้งหมด)
+type NotificationSettingsRequest struct {
+	Level      string     `json:"level" validate:"omitempty,oneof=all mentions none"` // ค่าว่าง = all
+	MutedUntil *time.Time `json:"muted_until,omitempty"`                              // ปิดเสียงชั่วคราวถึงเวลานี้ (nil = ไม่ปิด)
+	Keywords   []string   `json:"keywords,omitempty" validate:"omitempty,max=20"`
+}
+
 // ConversationHideRequest สำหรับการซ่อน/แสดงการสนทนา
 type ConversationHideRequest struct {
 	IsHidden bool `json:"is_hidden" validate:"required"`
 }
 
+// ConversationMessageTTLRequest สำหรับตั้งค่าข้อความหายไปอัตโนมัติ (0 = ปิด)
+type ConversationMessageTTLRequest struct {
+	TTLSeconds *int `json:"ttl_seconds" validate:"required,min=0"`
+}
+
 // ============ Response DTOs ============
 
+// NotificationSettingsDTO การตั้งค่าการแจ้งเตือนของผู้ใช้ในการสนทนา
+type NotificationSettingsDTO struct {
+	Level      string     `json:"level"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+	Keywords   []string   `json:"keywords"`
+	IsMuted    bool       `json:"is_muted"` // ปิดเสียงอยู่ ณ ตอนนี้ (ปิดเสียงถาวรหรือยังไม่ถึง muted_until)
+}
+
 // ConversationDTO โครงสร้างข้อมูลสำหรับส่งกลับข้อมูลการสนทนา
 type ConversationDTO struct {
 	ID              uuid.UUID   `json:"id"`
@@ -94,6 +114,11 @@ type ConversationDTO struct {
 	IsActive        bool        `json:"is_active"`
 	Metadata        types.JSONB `json:"metadata,omitempty"` // เพิ่มฟิลด์นี้
 	MemberCount     int         `json:"member_count"`
+
+	// ข้อความหายไปอัตโนมัติ (วินาที, 0 = ปิด)
+	MessageTTLSeconds int `json:"message_ttl_seconds"`
+	// slow mode ของกลุ่ม (วินาทีระหว่างข้อความของสมาชิกแต่ละคน, 0 = ปิด)
+	SlowModeSeconds int `json:"slow_mode_seconds"`
 	UnreadCount     int         `json:"unread_count"`
 
 	// Mention-related fields
@@ -29,6 +29,13 @@ type MemberQueryRequest struct {
 	Limit int `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
 }
 
+// UpdatePermissionPolicyRequest สำหรับเปลี่ยน role ขั้นต่ำของสิทธิ์ในกลุ่ม (เฉพาะ key ที่ส่งมา)
+//
+//	{"permissions": {"send_message": "admin", "pin_message": "member"}}
+type UpdatePermissionPolicyRequest struct {
+	Permissions map[string]string `json:"permissions" validate:"required,min=1"`
+}
+
 // ============ Response DTOs ============
 
 // MemberDTO โครงสร้างข้อมูลสมาชิกในการสนทนา
@@ -43,6 +50,22 @@ type MemberDTO struct {
 	IsOnline       bool      `json:"is_online"`
 }
 
+// PermissionPolicyDTO permission policy ของกลุ่ม
+type PermissionPolicyDTO struct {
+	ConversationID uuid.UUID         `json:"conversation_id"`
+	Permissions    map[string]string `json:"permissions"`    // สิทธิ์ที่ตั้งค่าได้ -> role ขั้นต่ำ (owner, admin, member)
+	MyRole         string            `json:"my_role"`        // role ของผู้ใช้ที่ดึงข้อมูล
+	MyPermissions  map[string]bool   `json:"my_permissions"` // สิทธิ์ทั้งหมดของผู้ใช้ที่ดึงข้อมูล
+}
+
+// LeaveGroupResultDTO ผลการออกจากกลุ่ม
+type LeaveGroupResultDTO struct {
+	ConversationID   uuid.UUID  `json:"conversation_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	NewOwnerID       *uuid.UUID `json:"new_owner_id,omitempty"` // owner คนใหม่ เมื่อผู้ที่ออกเป็น owner
+	GroupDeactivated bool       `json:"group_deactivated"`      // true เมื่อสมาชิกคนสุดท้ายออกจากกลุ่ม
+}
+
 // ConversationMemberDTO ข้อมูลสมาชิกในการสนทนา
 type ConversationMemberDTO struct {
 	ID                   uuid.UUID   `json:"id"`
@@ -156,3 +156,15 @@ type ConfirmUploadResponse struct {
 	GenericResponse
 	Data ConfirmUploadDTO `json:"data"`
 }
+
+// ============ Image Pipeline DTOs ============
+
+// ProcessedImageDTO ผลลัพธ์จาก image pipeline ฝั่ง server
+type ProcessedImageDTO struct {
+	Width        int    `json:"width"` // หลังหมุนตาม EXIF orientation แล้ว
+	Height       int    `json:"height"`
+	Size         int64  `json:"size"` // ขนาดต้นฉบับหลังลบ metadata
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url,omitempty"` // ว่างเมื่อต้นฉบับเล็กกว่าขนาด medium
+	BlurHash     string `json:"blurhash"`
+}
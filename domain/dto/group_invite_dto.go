@@ -0,0 +1,63 @@
+// domain/dto/group_invite_dto.go
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ Request DTOs ============
+
+// CreateGroupInviteRequest สำหรับการสร้างลิงก์เชิญเข้ากลุ่ม
+type CreateGroupInviteRequest struct {
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"` // ไม่ระบุ = ไม่หมดอายุ
+	MaxUses         int        `json:"max_uses"`             // 0 = ไม่จำกัด
+	RequireApproval bool       `json:"require_approval"`     // true = ผู้ใช้ลิงก์ต้องรอแอดมินอนุมัติ
+}
+
+// ============ Response DTOs ============
+
+// GroupInviteDTO ข้อมูลลิงก์เชิญ
+type GroupInviteDTO struct {
+	ID              uuid.UUID  `json:"id"`
+	ConversationID  uuid.UUID  `json:"conversation_id"`
+	Token           string     `json:"token"`
+	CreatedBy       uuid.UUID  `json:"created_by"`
+	RequireApproval bool       `json:"require_approval"`
+	MaxUses         int        `json:"max_uses"`
+	UseCount        int        `json:"use_count"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	IsActive        bool       `json:"is_active"` // ยังใช้เข้ากลุ่มได้ (ไม่ถูกยกเลิก ไม่หมดอายุ ยังไม่ครบจำนวน)
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// GroupJoinRequestDTO ข้อมูลคำขอเข้ากลุ่ม
+type GroupJoinRequestDTO struct {
+	ID              uuid.UUID  `json:"id"`
+	ConversationID  uuid.UUID  `json:"conversation_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Username        string     `json:"username,omitempty"`
+	DisplayName     string     `json:"display_name,omitempty"`
+	ProfileImageURL string     `json:"profile_image_url,omitempty"`
+	InviteID        *uuid.UUID `json:"invite_id,omitempty"`
+	Status          string     `json:"status"`
+	ReviewedBy      *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// สถานะใน JoinGroupResultDTO
+const (
+	JoinStatusJoined  = "joined"
+	JoinStatusPending = "pending"
+)
+
+// JoinGroupResultDTO ผลการเข้ากลุ่มผ่านลิงก์เชิญ
+type JoinGroupResultDTO struct {
+	ConversationID uuid.UUID            `json:"conversation_id"`
+	Status         string               `json:"status"` // "joined" หรือ "pending"
+	InviteID       uuid.UUID            `json:"invite_id"`
+	JoinRequest    *GroupJoinRequestDTO `json:"join_request,omitempty"` // มีเมื่อ status = "pending"
+}
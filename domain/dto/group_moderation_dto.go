@@ -0,0 +1,55 @@
+// domain/dto/group_moderation_dto.go
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ Request DTOs ============
+
+// BanMemberRequest สำหรับการแบนผู้ใช้ออกจากกลุ่ม (ถ้ายังเป็นสมาชิกจะถูกนำออกด้วย)
+type BanMemberRequest struct {
+	UserID    uuid.UUID  `json:"user_id" validate:"required"`
+	Reason    string     `json:"reason,omitempty" validate:"max=500"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // ไม่ระบุ = แบนถาวร
+}
+
+// RestrictMemberRequest สำหรับการจำกัดไม่ให้สมาชิกส่งข้อความจนถึงเวลาที่กำหนด
+type RestrictMemberRequest struct {
+	Until time.Time `json:"until" validate:"required"`
+}
+
+// SlowModeRequest สำหรับการตั้งค่า slow mode ของกลุ่ม
+type SlowModeRequest struct {
+	Seconds *int `json:"seconds" validate:"required"` // 0 = ปิด slow mode
+}
+
+// ============ Response DTOs ============
+
+// GroupBanDTO ข้อมูลการแบน
+type GroupBanDTO struct {
+	ConversationID  uuid.UUID  `json:"conversation_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Username        string     `json:"username,omitempty"`
+	DisplayName     string     `json:"display_name,omitempty"`
+	ProfileImageURL string     `json:"profile_image_url,omitempty"`
+	BannedBy        uuid.UUID  `json:"banned_by"`
+	Reason          string     `json:"reason,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"` // ไม่มี = แบนถาวร
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// BanMemberResultDTO ผลการแบน
+type BanMemberResultDTO struct {
+	Ban           *GroupBanDTO `json:"ban"`
+	RemovedMember bool         `json:"removed_member"` // true = ผู้ใช้เป็นสมาชิกอยู่และถูกนำออกจากกลุ่ม
+}
+
+// MemberRestrictionDTO สถานะการจำกัดการส่งข้อความของสมาชิก
+type MemberRestrictionDTO struct {
+	ConversationID      uuid.UUID  `json:"conversation_id"`
+	UserID              uuid.UUID  `json:"user_id"`
+	SendRestrictedUntil *time.Time `json:"send_restricted_until"` // null = ไม่ถูกจำกัด
+}
@@ -152,8 +152,10 @@ type MessageDTO struct {
 	EditCount int        `json:"edit_count"`
 	DeletedBy *uuid.UUID `json:"deleted_by,omitempty"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // ข้อความหายไปอัตโนมัติ
 
-	// ข้อมูลการอ่าน
+	// ข้อมูลการอ่าน (sequence เทียบกับ read watermark ของสมาชิก: sequence <= last_read_seq = อ่านแล้ว)
+	Sequence       int64       `json:"sequence,omitempty"`
 	IsRead         bool        `json:"is_read"`
 	ReadCount      int         `json:"read_count"`
 	Status         string      `json:"status"` // sent, delivered, read, failed
@@ -164,6 +166,17 @@ type MessageDTO struct {
 	ReplyToID      *uuid.UUID    `json:"reply_to_id,omitempty"`
 	ReplyToMessage *ReplyInfoDTO `json:"reply_to_message,omitempty"`
 
+	// ข้อมูล Thread (thread_reply_count / last_reply_at มีค่าเฉพาะ root message)
+	ThreadRootID      *uuid.UUID `json:"thread_root_id,omitempty"`
+	ThreadReplyCount  int        `json:"thread_reply_count"`
+	ThreadLastReplyAt *time.Time `json:"last_reply_at,omitempty"`
+
+	// ข้อมูล Reactions (จำนวนต่อ emoji และ reacted_by_me ของผู้ใช้ที่ดึงข้อมูล)
+	Reactions []ReactionSummaryDTO `json:"reactions,omitempty"`
+
+	// ข้อมูลโพล (เฉพาะ message_type = poll)
+	Poll *PollDTO `json:"poll,omitempty"`
+
 	// ข้อมูลการ Forward
 	IsForwarded   bool               `json:"is_forwarded"`
 	ForwardedFrom *ForwardedFromDTO `json:"forwarded_from,omitempty"`
@@ -179,6 +192,9 @@ type MessageDTO struct {
 
 	// ข้อมูล Conversation สำหรับ Search Results (Telegram-style)
 	Conversation *ConversationBasicDTO `json:"conversation,omitempty"`
+
+	// Snippet ของผลการค้นหา (คำที่ตรงถูกครอบด้วย <mark>, ส่วนอื่น escape เป็น HTML)
+	Highlight string `json:"highlight,omitempty"`
 }
 
 // ConversationBasicDTO ข้อมูลพื้นฐานของ Conversation สำหรับ search results
@@ -219,7 +235,7 @@ type MessageEditHistoryDTO struct {
 type MessageDeleteHistoryDTO struct {
 	ID          uuid.UUID     `json:"id"`
 	MessageID   uuid.UUID     `json:"message_id"`
-	DeletedBy   uuid.UUID     `json:"deleted_by"`
+	DeletedBy   *uuid.UUID    `json:"deleted_by"`
 	DeleterInfo *UserBasicDTO `json:"deleter_info,omitempty"`
 	DeletedAt   time.Time     `json:"deleted_at"`
 	Reason      string        `json:"reason,omitempty"`
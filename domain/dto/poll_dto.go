@@ -0,0 +1,56 @@
+// domain/dto/poll_dto.go
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// ============ Request DTOs ============
+
+// CreatePollRequest สำหรับการสร้างโพลในการสนทนา
+type CreatePollRequest struct {
+	Question      string     `json:"question" validate:"required"`
+	Options       []string   `json:"options" validate:"required,min=2,max=10"`
+	AllowMultiple bool       `json:"allow_multiple"`
+	IsAnonymous   bool       `json:"is_anonymous"`
+	ClosesAt      *time.Time `json:"closes_at,omitempty"` // ไม่ระบุ = เปิดจนกว่าจะปิดเอง
+	TempID        string     `json:"temp_id,omitempty"`
+}
+
+// PollVoteRequest สำหรับการโหวต (แทนที่ตัวเลือกเดิมของผู้ใช้ทั้งหมด)
+type PollVoteRequest struct {
+	OptionIDs []uuid.UUID `json:"option_ids" validate:"required,min=1"`
+}
+
+// ============ Response DTOs ============
+
+// PollDTO ข้อมูลโพลพร้อมผลโหวตปัจจุบัน
+type PollDTO struct {
+	ID             uuid.UUID       `json:"id"`
+	MessageID      uuid.UUID       `json:"message_id"`
+	ConversationID uuid.UUID       `json:"conversation_id"`
+	CreatorID      uuid.UUID       `json:"creator_id"`
+	Question       string          `json:"question"`
+	Options        []PollOptionDTO `json:"options"`
+	AllowMultiple  bool            `json:"allow_multiple"`
+	IsAnonymous    bool            `json:"is_anonymous"`
+	ClosesAt       *time.Time      `json:"closes_at,omitempty"`
+	IsClosed       bool            `json:"is_closed"`
+	ClosedAt       *time.Time      `json:"closed_at,omitempty"`
+	ClosedBy       *uuid.UUID      `json:"closed_by,omitempty"`
+	TotalVoters    int             `json:"total_voters"`
+	MyVotes        []uuid.UUID     `json:"my_votes,omitempty"`      // ตัวเลือกที่ผู้ใช้ที่ดึงข้อมูลเลือก (ไม่มีใน poll.updated)
+	FinalResults   types.JSONB     `json:"final_results,omitempty"` // snapshot ผลลัพธ์ตอนปิดโพล
+}
+
+// PollOptionDTO ตัวเลือกของโพลพร้อมจำนวนโหวต
+type PollOptionDTO struct {
+	ID        uuid.UUID   `json:"id"`
+	Text      string      `json:"text"`
+	Position  int         `json:"position"`
+	VoteCount int         `json:"vote_count"`
+	VoterIDs  []uuid.UUID `json:"voter_ids,omitempty"` // ไม่แสดงในโพลแบบไม่ระบุตัวตน
+}
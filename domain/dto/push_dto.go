@@ -0,0 +1,56 @@
+// domain/dto/push_dto.go
+
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ Request DTOs ============
+
+// RegisterPushDeviceRequest สำหรับลงทะเบียนอุปกรณ์รับ push notification
+// FCM/APNs ส่ง token ส่วน Web Push ส่ง subscription จาก PushManager.subscribe() (endpoint + keys)
+type RegisterPushDeviceRequest struct {
+	Provider   string            `json:"provider" validate:"required,oneof=webpush fcm apns"`
+	Token      string            `json:"token,omitempty"`
+	Endpoint   string            `json:"endpoint,omitempty"`
+	Keys       *WebPushKeysInput `json:"keys,omitempty"`
+	Platform   string            `json:"platform,omitempty"`
+	DeviceName string            `json:"device_name,omitempty"`
+}
+
+// WebPushKeysInput keys ของ PushSubscription (base64url)
+type WebPushKeysInput struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// ============ Response DTOs ============
+
+// PushDeviceDTO อุปกรณ์ที่ลงทะเบียนรับ push (ไม่เปิดเผย token)
+type PushDeviceDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	Provider   string     `json:"provider"`
+	Platform   string     `json:"platform"`
+	DeviceName string     `json:"device_name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	IsCurrent  bool       `json:"is_current"` // ลงทะเบียนจาก session ปัจจุบัน
+}
+
+// ============ Push payload ============
+
+// PushNotification ข้อมูลที่ส่งผ่าน push provider
+type PushNotification struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+
+	// CollapseKey รวม push ที่มี key เดียวกัน (เช่น ต่อการสนทนา) ให้เหลือรายการล่าสุดบนอุปกรณ์
+	CollapseKey string `json:"collapse_key,omitempty"`
+
+	// TTL เวลาที่ provider เก็บ push ไว้ถ้าอุปกรณ์ออฟไลน์ (0 = ค่าเริ่มต้นของ provider)
+	TTL time.Duration `json:"-"`
+}
@@ -0,0 +1,57 @@
+// domain/dto/reaction_dto.go
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ Request DTOs ============
+
+// AddReactionRequest สำหรับการแสดงความรู้สึกต่อข้อความ
+type AddReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=16"`
+}
+
+// ============ Response DTOs ============
+
+// ReactionSummaryDTO จำนวน reaction ต่อ emoji ของข้อความ
+type ReactionSummaryDTO struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+// ReactionCountDTO จำนวน reaction ต่อ emoji (ไม่ขึ้นกับผู้ใช้ ใช้ใน WebSocket event)
+type ReactionCountDTO struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// MessageReactionDTO ข้อมูล reaction รายการเดียว
+type MessageReactionDTO struct {
+	ID        uuid.UUID     `json:"id"`
+	MessageID uuid.UUID     `json:"message_id"`
+	UserID    uuid.UUID     `json:"user_id"`
+	Emoji     string        `json:"emoji"`
+	CreatedAt time.Time     `json:"created_at"`
+	User      *UserBasicDTO `json:"user,omitempty"`
+}
+
+// MessageReactionsDTO สรุป reaction ของข้อความ
+type MessageReactionsDTO struct {
+	MessageID uuid.UUID            `json:"message_id"`
+	Summary   []ReactionSummaryDTO `json:"summary"`
+	Reactions []MessageReactionDTO `json:"reactions,omitempty"`
+}
+
+// MessageReactionEventDTO ข้อมูลที่ส่งผ่าน WebSocket event message.reaction
+type MessageReactionEventDTO struct {
+	MessageID      uuid.UUID          `json:"message_id"`
+	ConversationID uuid.UUID          `json:"conversation_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Emoji          string             `json:"emoji"`
+	Action         string             `json:"action"` // added, removed
+	Reactions      []ReactionCountDTO `json:"reactions"`
+}
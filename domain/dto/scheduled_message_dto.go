@@ -0,0 +1,17 @@
+// domain/dto/scheduled_message_dto.go
+package dto
+
+import "time"
+
+// ============ Request DTOs ============
+
+// UpdateScheduledSeriesRequest แก้ไขข้อความตั้งเวลาแบบซ้ำ (มีผลกับรอบถัดไปและรอบหลังจากนั้น)
+// field ที่เป็น nil จะไม่ถูกเปลี่ยน
+type UpdateScheduledSeriesRequest struct {
+	Content         *string                `json:"content,omitempty"`
+	MediaURL        *string                `json:"media_url,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	RecurrenceRule  *string                `json:"recurrence_rule,omitempty"`
+	Timezone        *string                `json:"timezone,omitempty"`
+	RecurrenceUntil *time.Time             `json:"recurrence_until,omitempty"`
+}
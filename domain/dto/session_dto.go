@@ -0,0 +1,38 @@
+// domain/dto/session_dto.go
+
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============ Request DTOs ============
+
+// DeviceInfo ข้อมูลอุปกรณ์ที่ใช้เข้าสู่ระบบ (device_name/platform มาจาก body, IP/User-Agent มาจาก request)
+type DeviceInfo struct {
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	IPAddress  string `json:"-"`
+	UserAgent  string `json:"-"`
+}
+
+// ============ Response DTOs ============
+
+// SessionDTO session การเข้าสู่ระบบของอุปกรณ์หนึ่ง
+type SessionDTO struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceName string    `json:"device_name"`
+	Platform   string    `json:"platform"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsCurrent  bool      `json:"is_current"` // session ของ access token ที่ใช้เรียก
+}
+
+// RevokeSessionsDTO ผลลัพธ์การเพิกถอน session อื่นทั้งหมด
+type RevokeSessionsDTO struct {
+	RevokedCount int `json:"revoked_count"`
+}
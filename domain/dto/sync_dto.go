@@ -0,0 +1,34 @@
+// domain/dto/sync_dto.go
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ============ Request DTOs ============
+
+// SyncEventsRequest ขอ event ที่เกิดขึ้นหลัง sequence ที่ระบุ
+type SyncEventsRequest struct {
+	Since int64 `json:"since" query:"since"`
+	Limit int   `json:"limit,omitempty" query:"limit"`
+}
+
+// ============ Response DTOs ============
+
+// SyncEventDTO event หนึ่งรายการใน event log ของผู้ใช้
+type SyncEventDTO struct {
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// SyncEventsDTO ผลลัพธ์ของการ sync event
+// ResetRequired = true เมื่อ event บางส่วนหลุดออกจาก log ไปแล้ว client ต้องโหลดข้อมูลใหม่ทั้งหมด
+type SyncEventsDTO struct {
+	Events        []SyncEventDTO `json:"events"`
+	LatestSeq     int64          `json:"latest_seq"`
+	HasMore       bool           `json:"has_more"`
+	ResetRequired bool           `json:"reset_required"`
+}
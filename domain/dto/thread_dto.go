@@ -0,0 +1,46 @@
+// domain/dto/thread_dto.go
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// ============ Request DTOs ============
+
+// ThreadReplyRequest สำหรับการตอบกลับใน thread
+type ThreadReplyRequest struct {
+	MessageType  string      `json:"message_type" validate:"required,oneof=text image file sticker"`
+	Content      string      `json:"content"`
+	MediaURL     string      `json:"media_url,omitempty"`
+	ThumbnailURL string      `json:"media_thumbnail_url,omitempty"`
+	Metadata     types.JSONB `json:"metadata,omitempty"`
+}
+
+// ============ Response DTOs ============
+
+// ThreadRepliesDTO ข้อความตอบกลับใน thread (cursor pagination จากเก่าไปใหม่)
+type ThreadRepliesDTO struct {
+	Root        *MessageDTO   `json:"root"`
+	Replies     []*MessageDTO `json:"replies"`
+	NextCursor  *string       `json:"next_cursor,omitempty"` // ID ของข้อความสุดท้ายในหน้านี้
+	HasMore     bool          `json:"has_more"`
+	IsFollowing bool          `json:"is_following"`
+}
+
+// ThreadFollowDTO สถานะการติดตาม thread ของผู้ใช้
+type ThreadFollowDTO struct {
+	RootMessageID uuid.UUID `json:"root_message_id"`
+	IsFollowing   bool      `json:"is_following"`
+}
+
+// ThreadReplyEventDTO ข้อมูลที่ส่งผ่าน WebSocket event thread.reply (เฉพาะผู้ติดตาม thread)
+type ThreadReplyEventDTO struct {
+	RootMessageID    uuid.UUID   `json:"root_message_id"`
+	ConversationID   uuid.UUID   `json:"conversation_id"`
+	Message          *MessageDTO `json:"message"`
+	ThreadReplyCount int         `json:"thread_reply_count"`
+	LastReplyAt      time.Time   `json:"last_reply_at"`
+}
@@ -24,6 +24,18 @@ type Conversation struct {
 	IsActive        bool        `json:"is_active" gorm:"default:true"`
 	Metadata        types.JSONB `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
 
+	// ข้อความหายไปอัตโนมัติ: ข้อความใหม่จะถูกลบหลังจากนี้ (วินาที, 0 = ปิด)
+	MessageTTLSeconds int `json:"message_ttl_seconds" gorm:"default:0"`
+
+	// slow mode: สมาชิก (ที่ไม่ใช่แอดมิน) ส่งข้อความได้หนึ่งครั้งต่อช่วงเวลานี้ (วินาที, 0 = ปิด)
+	SlowModeSeconds int `json:"slow_mode_seconds" gorm:"default:0"`
+
+	// สิทธิ์ของสมาชิกในกลุ่ม (role ขั้นต่ำต่อสิทธิ์) ดู PermissionPolicy
+	Permissions types.JSONB `json:"permissions,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+
+	// Sequence ของข้อความผู้ใช้ล่าสุด (unread = LastMessageSeq - ConversationMember.LastReadSeq - ConversationMember.DeletedUnread)
+	LastMessageSeq int64 `json:"last_message_seq" gorm:"not null;default:0"`
+
 	// Associations
 	Creator  *User                 `json:"creator,omitempty" gorm:"foreignkey:CreatorID"`
 	Members  []*ConversationMember `json:"members,omitempty" gorm:"foreignkey:ConversationID"`
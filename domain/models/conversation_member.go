@@ -27,12 +27,17 @@ type ConversationMember struct {
 	IsAdmin              bool        `json:"is_admin" gorm:"default:false"` // Deprecated: use Role instead
 	JoinedAt             time.Time   `json:"joined_at" gorm:"type:timestamp with time zone;default:now()"`
 	LastReadAt           *time.Time  `json:"last_read_at,omitempty" gorm:"type:timestamp with time zone"`
+	LastReadMessageID    *uuid.UUID  `json:"last_read_message_id,omitempty" gorm:"type:uuid"` // read watermark: ข้อความล่าสุดที่อ่านแล้ว
+	LastReadSeq          int64       `json:"last_read_seq" gorm:"not null;default:0"`         // Sequence ของ LastReadMessageID
+	DeletedUnread        int64       `json:"-" gorm:"not null;default:0"`                     // ข้อความหลัง watermark ที่ถูกลบหรือหมดอายุแล้ว (หักออกจาก unread)
 	IsMuted              bool        `json:"is_muted" gorm:"default:false"`
 	IsPinned             bool        `json:"is_pinned" gorm:"default:false"`
 	IsHidden             bool        `json:"is_hidden" gorm:"default:false"`
 	HiddenAt             *time.Time  `json:"hidden_at,omitempty" gorm:"type:timestamp with time zone"`
 	Nickname             string      `json:"nickname,omitempty" gorm:"type:varchar(100)"`
 	NotificationSettings types.JSONB `json:"notification_settings,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+	SendRestrictedUntil  *time.Time  `json:"send_restricted_until,omitempty" gorm:"type:timestamp with time zone"` // แอดมินจำกัดการส่งข้อความถึงเวลานี้
+	LastSentAt           *time.Time  `json:"-" gorm:"type:timestamp with time zone"`                               // เวลาส่งข้อความล่าสุด (ใช้กับ slow mode)
 
 	// Associations
 	Conversation *Conversation `json:"conversation,omitempty" gorm:"foreignkey:ConversationID"`
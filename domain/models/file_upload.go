@@ -32,6 +32,13 @@ type FileUpload struct {
 	CreatedAt   time.Time        `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
 	CompletedAt *time.Time       `json:"completed_at,omitempty" gorm:"type:timestamp with time zone"`
 
+	// ผลจาก image pipeline (เฉพาะรูปภาพ)
+	Width        int    `json:"width,omitempty" gorm:"default:0"`
+	Height       int    `json:"height,omitempty" gorm:"default:0"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" gorm:"type:text"`
+	MediumURL    string `json:"medium_url,omitempty" gorm:"type:text"`
+	BlurHash     string `json:"blurhash,omitempty" gorm:"type:varchar(100)"`
+
 	// Relations
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -40,4 +40,17 @@ const (
 	ActivityMemberRoleChanged    = "member.role_changed"
 	ActivityOwnershipTransferred = "ownership.transferred"
 	ActivityMemberLeft           = "member.left"
+	ActivityMessageTTLChanged    = "conversation.message_ttl_changed"
+	ActivityPermissionsChanged   = "conversation.permissions_changed"
+	ActivityInviteCreated        = "invite.created"
+	ActivityInviteRevoked        = "invite.revoked"
+	ActivityMemberJoined         = "member.joined"
+	ActivityJoinRequested        = "join_request.created"
+	ActivityJoinRequestApproved  = "join_request.approved"
+	ActivityJoinRequestRejected  = "join_request.rejected"
+	ActivityMemberBanned         = "member.banned"
+	ActivityMemberUnbanned       = "member.unbanned"
+	ActivityMemberRestricted     = "member.restricted"
+	ActivityMemberUnrestricted   = "member.unrestricted"
+	ActivitySlowModeChanged      = "conversation.slow_mode_changed"
 )
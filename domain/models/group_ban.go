@@ -0,0 +1,33 @@
+// domain/models/group_ban.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupBan - ผู้ใช้ที่ถูกแบนจากกลุ่ม ถูกเพิ่มกลับหรือเข้าผ่านลิงก์เชิญไม่ได้จนกว่าจะหมดอายุหรือถูกยกเลิกแบน
+type GroupBan struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ConversationID uuid.UUID  `json:"conversation_id" gorm:"type:uuid;not null;uniqueIndex:idx_group_bans_conversation_user"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_group_bans_conversation_user"`
+	BannedBy       uuid.UUID  `json:"banned_by" gorm:"type:uuid;not null"`
+	Reason         string     `json:"reason,omitempty" gorm:"type:varchar(500)"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" gorm:"type:timestamp with time zone"` // nil = ถาวร
+	CreatedAt      time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (GroupBan) TableName() string {
+	return "group_bans"
+}
+
+// IsActive การแบนยังมีผลอยู่หรือไม่
+func (b *GroupBan) IsActive(now time.Time) bool {
+	return b.ExpiresAt == nil || b.ExpiresAt.After(now)
+}
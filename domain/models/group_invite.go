@@ -0,0 +1,71 @@
+// domain/models/group_invite.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GroupInvite - ลิงก์เชิญเข้ากลุ่ม (token ใช้ร่วมกันได้หลายคน จนกว่าจะหมดอายุ ครบจำนวน หรือถูกยกเลิก)
+type GroupInvite struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ConversationID  uuid.UUID  `json:"conversation_id" gorm:"type:uuid;not null;index"`
+	Token           string     `json:"token" gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedBy       uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	RequireApproval bool       `json:"require_approval" gorm:"default:false"` // true = ผู้ใช้ลิงก์ต้องรอแอดมินอนุมัติ
+	MaxUses         int        `json:"max_uses" gorm:"not null;default:0"`    // 0 = ไม่จำกัด
+	UseCount        int        `json:"use_count" gorm:"not null;default:0"`   // ลิงก์แบบรออนุมัตินับเมื่ออนุมัติคำขอ
+	ExpiresAt       *time.Time `json:"expires_at,omitempty" gorm:"type:timestamp with time zone"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" gorm:"type:timestamp with time zone"`
+	RevokedBy       *uuid.UUID `json:"revoked_by,omitempty" gorm:"type:uuid"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	Conversation *Conversation `json:"conversation,omitempty" gorm:"foreignkey:ConversationID"`
+	Creator      *User         `json:"creator,omitempty" gorm:"foreignkey:CreatedBy"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (GroupInvite) TableName() string {
+	return "group_invites"
+}
+
+// IsUsable ยังไม่ถูกยกเลิก ไม่หมดอายุ และยังใช้ไม่ครบจำนวน
+func (i *GroupInvite) IsUsable(now time.Time) bool {
+	if i.RevokedAt != nil {
+		return false
+	}
+	if i.ExpiresAt != nil && !i.ExpiresAt.After(now) {
+		return false
+	}
+	return i.MaxUses == 0 || i.UseCount < i.MaxUses
+}
+
+// สถานะของ GroupJoinRequest
+const (
+	JoinRequestStatusPending  = "pending"
+	JoinRequestStatusApproved = "approved"
+	JoinRequestStatusRejected = "rejected"
+)
+
+// GroupJoinRequest - คำขอเข้ากลุ่มผ่านลิงก์เชิญที่ต้องรอแอดมินอนุมัติ
+type GroupJoinRequest struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ConversationID uuid.UUID  `json:"conversation_id" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	InviteID       *uuid.UUID `json:"invite_id,omitempty" gorm:"type:uuid"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (GroupJoinRequest) TableName() string {
+	return "group_join_requests"
+}
@@ -15,7 +15,7 @@ type Message struct {
 	ConversationID    uuid.UUID   `json:"conversation_id" gorm:"type:uuid;not null"`
 	SenderID          *uuid.UUID  `json:"sender_id,omitempty" gorm:"type:uuid"`
 	SenderType        string      `json:"sender_type" gorm:"type:varchar(20);default:'user'"`
-	MessageType       string      `json:"message_type" gorm:"type:varchar(20);not null"` // text, image, file, sticker, album
+	MessageType       string      `json:"message_type" gorm:"type:varchar(20);not null"` // text, image, file, sticker, album, poll
 	Content           string      `json:"content,omitempty" gorm:"type:text"`
 	MediaURL          string      `json:"media_url,omitempty" gorm:"type:text"`
 	MediaThumbnailURL string      `json:"media_thumbnail_url,omitempty" gorm:"type:text"`
@@ -23,6 +23,9 @@ type Message struct {
 	Metadata          types.JSONB `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
 	Mentions          types.JSONB `json:"mentions,omitempty" gorm:"type:jsonb"` // Format: [{"user_id": "uuid", "start_index": 0, "length": 10}]
 
+	// Full-text search: tsvector literal ที่สร้างจาก pkg/textsearch (trigger คัดลอกไปยัง content_tsvector)
+	SearchDocument string `json:"-" gorm:"type:text"`
+
 	// Status tracking
 	Status      string     `json:"status" gorm:"type:varchar(20);default:'sent'"` // sent, delivered, read
 	DeliveredAt *time.Time `json:"delivered_at,omitempty" gorm:"type:timestamp with time zone"`
@@ -40,6 +43,18 @@ type Message struct {
 	PinnedBy *uuid.UUID  `json:"pinned_by,omitempty" gorm:"type:uuid"`
 	PinnedAt *time.Time  `json:"pinned_at,omitempty" gorm:"type:timestamp with time zone"`
 
+	// ลำดับในการสนทนาสำหรับ read watermark (repository กำหนดตอนสร้าง)
+	// ข้อความของผู้ใช้ใน timeline ได้ค่าใหม่ที่เพิ่มขึ้นทีละ 1, ข้อความระบบใช้ค่าเดียวกับข้อความก่อนหน้า, ข้อความใน thread = 0
+	Sequence int64 `json:"sequence" gorm:"not null;default:0"`
+
+	// ข้อความหายไปอัตโนมัติ (กำหนดตอนสร้างจาก Conversation.MessageTTLSeconds)
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"type:timestamp with time zone;index"`
+
+	// Thread fields
+	ThreadRootID      *uuid.UUID `json:"thread_root_id,omitempty" gorm:"type:uuid;index"` // ข้อความตอบกลับใน thread (ไม่แสดงใน timeline หลัก)
+	ThreadReplyCount  int        `json:"thread_reply_count" gorm:"default:0"`               // จำนวนข้อความตอบกลับ (เฉพาะ root message)
+	ThreadLastReplyAt *time.Time `json:"thread_last_reply_at,omitempty" gorm:"type:timestamp with time zone"`
+
 	// Forward fields
 	IsForwarded   bool        `json:"is_forwarded" gorm:"default:false"`
 	ForwardedFrom types.JSONB `json:"forwarded_from,omitempty" gorm:"type:jsonb"` // Format: {"message_id": "uuid", "sender_id": "uuid", "conversation_id": "uuid", "original_timestamp": "..."}
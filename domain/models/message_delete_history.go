@@ -18,7 +18,7 @@ type MessageDeleteHistory struct {
 	MediaThumbnailURL string      `json:"media_thumbnail_url,omitempty" gorm:"type:text"`
 	Metadata          types.JSONB `json:"metadata,omitempty" gorm:"type:jsonb"`
 	DeletedAt         time.Time   `json:"deleted_at" gorm:"type:timestamp with time zone;default:now()"`
-	DeletedBy         uuid.UUID   `json:"deleted_by" gorm:"type:uuid;not null"`
+	DeletedBy         *uuid.UUID  `json:"deleted_by" gorm:"type:uuid"` // nil = ระบบลบ (เช่น ข้อความหมดอายุ)
 
 	// Associations
 	Message *Message `json:"message,omitempty" gorm:"foreignkey:MessageID"`
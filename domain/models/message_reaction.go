@@ -0,0 +1,27 @@
+// domain/models/message_reaction.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageReaction represents an emoji reaction from a user on a message
+type MessageReaction struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	MessageID      uuid.UUID `json:"message_id" gorm:"type:uuid;not null;uniqueIndex:idx_message_reactions_unique"`
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_message_reactions_unique"`
+	Emoji          string    `json:"emoji" gorm:"type:varchar(64);not null;uniqueIndex:idx_message_reactions_unique"`
+	CreatedAt      time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	Message *Message `json:"message,omitempty" gorm:"foreignkey:MessageID"`
+	User    *User    `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName returns the table name for GORM
+func (MessageReaction) TableName() string {
+	return "message_reactions"
+}
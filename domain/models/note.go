@@ -28,6 +28,7 @@ type Note struct {
 	Tags           types.JSONB    `json:"tags,omitempty" gorm:"type:jsonb;default:'[]'::jsonb"` // Format: ["tag1", "tag2"]
 	IsPinned       bool           `json:"is_pinned" gorm:"default:false"`
 	Visibility     NoteVisibility `json:"visibility" gorm:"type:varchar(20);default:'private'"` // private = เห็นเฉพาะเจ้าของ, shared = เห็นทุกคนใน conversation
+	SearchDocument string         `json:"-" gorm:"type:text"`                                   // tsvector literal จาก pkg/textsearch (title = A, content = B)
 
 	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp with time zone;default:now()"`
@@ -0,0 +1,184 @@
+// domain/models/notification_settings.go
+
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// NotificationLevel ระดับการแจ้งเตือนของสมาชิกในการสนทนา
+type NotificationLevel string
+
+const (
+	NotificationLevelAll      NotificationLevel = "all"      // แจ้งเตือนทุกข้อความ
+	NotificationLevelMentions NotificationLevel = "mentions" // แจ้งเตือนเฉพาะเมื่อถูก mention, ตอบกลับ หรือตรงกับ keyword
+	NotificationLevelNone     NotificationLevel = "none"     // ไม่แจ้งเตือน (ยังได้รับ event สำหรับ sync)
+)
+
+// เหตุผลที่สมาชิกได้รับการแจ้งเตือน
+const (
+	AlertReasonMessage = "message"
+	AlertReasonMention = "mention"
+	AlertReasonReply   = "reply"
+	AlertReasonKeyword = "keyword"
+)
+
+const (
+	maxNotificationKeywords = 20
+	maxNotificationKeyword  = 50
+)
+
+// NotificationSettings โครงสร้างของ ConversationMember.NotificationSettings (JSONB)
+//
+//	{"level": "mentions", "muted_until": "2025-01-01T00:00:00Z", "keywords": ["deploy"]}
+type NotificationSettings struct {
+	Level      NotificationLevel `json:"level"`
+	MutedUntil *time.Time        `json:"muted_until,omitempty"` // ปิดเสียงชั่วคราวถึงเวลานี้
+	Keywords   []string          `json:"keywords,omitempty"`    // แจ้งเตือนเมื่อข้อความมีคำเหล่านี้ (ไม่สนตัวพิมพ์เล็ก/ใหญ่)
+}
+
+// ParseNotificationSettings แปลง JSONB เป็น NotificationSettings
+// ค่าที่ไม่รู้จักหรือผิดรูปแบบใช้ค่าเริ่มต้น (แจ้งเตือนทุกข้อความ)
+func ParseNotificationSettings(raw types.JSONB) NotificationSettings {
+	settings := NotificationSettings{Level: NotificationLevelAll}
+	if raw == nil {
+		return settings
+	}
+
+	if level, ok := raw["level"].(string); ok && isValidNotificationLevel(NotificationLevel(level)) {
+		settings.Level = NotificationLevel(level)
+	}
+
+	if mutedUntil, ok := raw["muted_until"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, mutedUntil); err == nil {
+			settings.MutedUntil = &t
+		}
+	}
+
+	if keywords, ok := raw["keywords"].([]interface{}); ok {
+		for _, k := range keywords {
+			if s, ok := k.(string); ok {
+				settings.Keywords = append(settings.Keywords, s)
+			}
+		}
+	} else if keywords, ok := raw["keywords"].([]string); ok {
+		settings.Keywords = append(settings.Keywords, keywords...)
+	}
+
+	return settings
+}
+
+// Normalize ตรวจสอบค่าและจัดรูปแบบ keyword (ตัดช่องว่าง, ตัวพิมพ์เล็ก, ตัดคำซ้ำ)
+// muted_until ที่ผ่านไปแล้วถูกล้างออก
+func (s *NotificationSettings) Normalize(now time.Time) error {
+	if s.Level == "" {
+		s.Level = NotificationLevelAll
+	}
+	if !isValidNotificationLevel(s.Level) {
+		return errors.New("invalid notification level")
+	}
+
+	if s.MutedUntil != nil && !s.MutedUntil.After(now) {
+		s.MutedUntil = nil
+	}
+
+	seen := make(map[string]bool, len(s.Keywords))
+	keywords := make([]string, 0, len(s.Keywords))
+	for _, k := range s.Keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		if len([]rune(k)) > maxNotificationKeyword {
+			return errors.New("keyword is too long")
+		}
+		seen[k] = true
+		keywords = append(keywords, k)
+	}
+	if len(keywords) > maxNotificationKeywords {
+		return errors.New("too many keywords")
+	}
+	s.Keywords = keywords
+
+	return nil
+}
+
+// ToJSONB แปลงเป็น JSONB สำหรับบันทึกลง ConversationMember.NotificationSettings
+func (s NotificationSettings) ToJSONB() types.JSONB {
+	raw := types.JSONB{"level": string(s.Level)}
+	if s.MutedUntil != nil {
+		raw["muted_until"] = s.MutedUntil.UTC().Format(time.RFC3339)
+	}
+	if len(s.Keywords) > 0 {
+		keywords := make([]interface{}, len(s.Keywords))
+		for i, k := range s.Keywords {
+			keywords[i] = k
+		}
+		raw["keywords"] = keywords
+	}
+	return raw
+}
+
+// IsMutedAt ปิดเสียงชั่วคราวอยู่ ณ เวลา now
+func (s NotificationSettings) IsMutedAt(now time.Time) bool {
+	return s.MutedUntil != nil && s.MutedUntil.After(now)
+}
+
+// MatchesKeyword ข้อความมี keyword ที่ตั้งไว้หรือไม่
+func (s NotificationSettings) MatchesKeyword(content string) bool {
+	if len(s.Keywords) == 0 || content == "" {
+		return false
+	}
+	content = strings.ToLower(content)
+	for _, k := range s.Keywords {
+		if strings.Contains(content, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNotificationSettings การตั้งค่าการแจ้งเตือนแบบ typed ของสมาชิก
+func (m *ConversationMember) GetNotificationSettings() NotificationSettings {
+	return ParseNotificationSettings(m.NotificationSettings)
+}
+
+// IsMutedAt ปิดเสียงอยู่หรือไม่ (IsMuted = ปิดเสียงจนกว่าจะเปิดเอง, muted_until = ปิดชั่วคราว)
+func (m *ConversationMember) IsMutedAt(now time.Time) bool {
+	return m.IsMuted || m.GetNotificationSettings().IsMutedAt(now)
+}
+
+// AlertReason ตัดสินว่าสมาชิกควรได้รับการแจ้งเตือนแบบ alert สำหรับข้อความนี้หรือไม่
+// คืนค่าเหตุผล (AlertReason*) หรือ "" ถ้าควรได้รับเฉพาะ event สำหรับ sync
+func (m *ConversationMember) AlertReason(now time.Time, mentioned, repliedTo bool, content string) string {
+	settings := m.GetNotificationSettings()
+	if m.IsMuted || settings.IsMutedAt(now) || settings.Level == NotificationLevelNone {
+		return ""
+	}
+
+	switch {
+	case mentioned:
+		return AlertReasonMention
+	case repliedTo:
+		return AlertReasonReply
+	case settings.MatchesKeyword(content):
+		return AlertReasonKeyword
+	case settings.Level == NotificationLevelAll:
+		return AlertReasonMessage
+	default:
+		return ""
+	}
+}
+
+func isValidNotificationLevel(level NotificationLevel) bool {
+	switch level {
+	case NotificationLevelAll, NotificationLevelMentions, NotificationLevelNone:
+		return true
+	default:
+		return false
+	}
+}
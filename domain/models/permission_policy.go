@@ -0,0 +1,178 @@
+// domain/models/permission_policy.go
+
+package models
+
+import (
+	"errors"
+
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// Permission สิทธิ์ในการสนทนา
+type Permission string
+
+const (
+	PermissionAddMember         Permission = "add_member"
+	PermissionRemoveMember      Permission = "remove_member"
+	PermissionChangeRole        Permission = "change_role"
+	PermissionUpdateInfo        Permission = "update_info"
+	PermissionDeleteGroup       Permission = "delete_group"
+	PermissionManagePermissions Permission = "manage_permissions"
+	PermissionSendMessage       Permission = "send_message"
+	PermissionSendMedia         Permission = "send_media"
+	PermissionPinMessage        Permission = "pin_message"
+	PermissionInvite            Permission = "invite"
+	PermissionModerateMembers   Permission = "moderate_members"
+)
+
+// errors ของการตั้งค่า permission policy
+var (
+	ErrUnknownPermission         = errors.New("unknown permission")
+	ErrPermissionNotConfigurable = errors.New("permission is not configurable")
+	ErrInvalidPermissionRole     = errors.New("invalid role")
+)
+
+// ConfigurablePermissions สิทธิ์ที่ owner กำหนด role ขั้นต่ำได้เองในแต่ละกลุ่ม (เรียงตามการแสดงผล)
+// สิทธิ์อื่นๆ (remove_member, change_role, delete_group, manage_permissions, moderate_members) ใช้ค่าเริ่มต้นเสมอ
+var ConfigurablePermissions = []Permission{
+	PermissionSendMessage,
+	PermissionSendMedia,
+	PermissionPinMessage,
+	PermissionAddMember,
+	PermissionInvite,
+	PermissionUpdateInfo,
+}
+
+// defaultPermissionRoles role ขั้นต่ำของแต่ละสิทธิ์ เมื่อกลุ่มไม่ได้ตั้งค่าไว้
+var defaultPermissionRoles = map[Permission]MemberRole{
+	PermissionAddMember:         RoleAdmin,
+	PermissionRemoveMember:      RoleAdmin,
+	PermissionChangeRole:        RoleOwner,
+	PermissionUpdateInfo:        RoleAdmin,
+	PermissionDeleteGroup:       RoleOwner,
+	PermissionManagePermissions: RoleOwner,
+	PermissionSendMessage:       RoleMember,
+	PermissionSendMedia:         RoleMember,
+	PermissionPinMessage:        RoleAdmin,
+	PermissionInvite:            RoleAdmin,
+	PermissionModerateMembers:   RoleAdmin,
+}
+
+// PermissionPolicy โครงสร้างของ Conversation.Permissions (JSONB): สิทธิ์ -> role ขั้นต่ำที่ทำได้
+//
+//	{"send_message": "admin", "send_media": "admin", "pin_message": "member"}
+type PermissionPolicy map[Permission]MemberRole
+
+// ParsePermissionPolicy แปลง JSONB เป็น PermissionPolicy ที่มีครบทุกสิทธิ์
+// ค่าที่ไม่รู้จัก, ผิดรูปแบบ หรือเป็นสิทธิ์ที่ตั้งค่าไม่ได้ ใช้ค่าเริ่มต้น
+func ParsePermissionPolicy(raw types.JSONB) PermissionPolicy {
+	policy := make(PermissionPolicy, len(defaultPermissionRoles))
+	for permission, role := range defaultPermissionRoles {
+		policy[permission] = role
+	}
+
+	for _, permission := range ConfigurablePermissions {
+		if role, ok := raw[string(permission)].(string); ok && isValidMemberRole(MemberRole(role)) {
+			policy[permission] = MemberRole(role)
+		}
+	}
+	return policy
+}
+
+// Set กำหนด role ขั้นต่ำของสิทธิ์ที่ตั้งค่าได้
+func (p PermissionPolicy) Set(permission Permission, role MemberRole) error {
+	if _, ok := defaultPermissionRoles[permission]; !ok {
+		return ErrUnknownPermission
+	}
+	if !IsConfigurablePermission(permission) {
+		return ErrPermissionNotConfigurable
+	}
+	if !isValidMemberRole(role) {
+		return ErrInvalidPermissionRole
+	}
+	p[permission] = role
+	return nil
+}
+
+// Allows role นี้มีสิทธิ์หรือไม่ (owner > admin > member)
+func (p PermissionPolicy) Allows(role MemberRole, permission Permission) bool {
+	required, ok := p[permission]
+	if !ok {
+		return false
+	}
+	return roleRank(role) >= roleRank(required)
+}
+
+// ToJSONB แปลงเป็น JSONB สำหรับบันทึกลง Conversation.Permissions (เฉพาะสิทธิ์ที่ตั้งค่าได้)
+func (p PermissionPolicy) ToJSONB() types.JSONB {
+	raw := types.JSONB{}
+	for _, permission := range ConfigurablePermissions {
+		raw[string(permission)] = string(p[permission])
+	}
+	return raw
+}
+
+// IsConfigurablePermission สิทธิ์นี้ owner ตั้งค่าได้หรือไม่
+func IsConfigurablePermission(permission Permission) bool {
+	for _, p := range ConfigurablePermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPermissionPolicy permission policy แบบ typed ของการสนทนา
+func (c *Conversation) GetPermissionPolicy() PermissionPolicy {
+	return ParsePermissionPolicy(c.Permissions)
+}
+
+// MemberCan สมาชิกมีสิทธิ์ทำสิ่งนี้ในการสนทนาหรือไม่
+// แชท direct ไม่มี policy: ส่งข้อความ, ส่งไฟล์ และปักหมุดได้เสมอ สิทธิ์อื่นใช้ค่าเริ่มต้น
+func (c *Conversation) MemberCan(member *ConversationMember, permission Permission) bool {
+	if c.Type != "group" {
+		switch permission {
+		case PermissionSendMessage, PermissionSendMedia, PermissionPinMessage:
+			return true
+		}
+		return ParsePermissionPolicy(nil).Allows(member.EffectiveRole(), permission)
+	}
+	return c.GetPermissionPolicy().Allows(member.EffectiveRole(), permission)
+}
+
+// EffectiveRole role ที่ใช้ตรวจสิทธิ์ (สมาชิกที่ถูกตั้งเป็นแอดมินผ่าน is_admin แบบเดิมนับเป็น admin)
+func (m *ConversationMember) EffectiveRole() MemberRole {
+	switch {
+	case m.Role == RoleOwner:
+		return RoleOwner
+	case m.Role == RoleAdmin || m.IsAdmin:
+		return RoleAdmin
+	default:
+		return RoleMember
+	}
+}
+
+// Outranks สมาชิกนี้มี role สูงกว่าอีกคนหรือไม่ (ใช้ตรวจว่าจัดการสมาชิกอีกคนได้)
+func (m *ConversationMember) Outranks(other *ConversationMember) bool {
+	return roleRank(m.EffectiveRole()) > roleRank(other.EffectiveRole())
+}
+
+func roleRank(role MemberRole) int {
+	switch role {
+	case RoleOwner:
+		return 2
+	case RoleAdmin:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isValidMemberRole(role MemberRole) bool {
+	switch role {
+	case RoleOwner, RoleAdmin, RoleMember:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+// domain/models/poll.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// Poll represents a poll attached to a message of type "poll"
+type Poll struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	MessageID      uuid.UUID  `json:"message_id" gorm:"type:uuid;not null;uniqueIndex"`
+	ConversationID uuid.UUID  `json:"conversation_id" gorm:"type:uuid;not null;index"`
+	CreatorID      uuid.UUID  `json:"creator_id" gorm:"type:uuid;not null"`
+	Question       string     `json:"question" gorm:"type:text;not null"`
+	AllowMultiple  bool       `json:"allow_multiple" gorm:"default:false"`
+	IsAnonymous    bool       `json:"is_anonymous" gorm:"default:false"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty" gorm:"type:timestamp with time zone;index"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty" gorm:"type:timestamp with time zone"`
+	ClosedBy       *uuid.UUID `json:"closed_by,omitempty" gorm:"type:uuid"`
+
+	// ผลลัพธ์สุดท้ายตอนปิดโพล: {"total_voters": n, "options": [{"option_id", "vote_count", "voter_ids"}]}
+	FinalResults types.JSONB `json:"final_results,omitempty" gorm:"type:jsonb"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	Options []*PollOption `json:"options,omitempty" gorm:"foreignkey:PollID"`
+}
+
+// TableName returns the table name for GORM
+func (Poll) TableName() string {
+	return "polls"
+}
+
+// IsClosed reports whether the poll no longer accepts votes
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !p.ClosesAt.After(now))
+}
+
+// PollOption represents one choice of a poll
+type PollOption struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	PollID   uuid.UUID `json:"poll_id" gorm:"type:uuid;not null;index"`
+	Text     string    `json:"text" gorm:"type:varchar(200);not null"`
+	Position int       `json:"position" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for GORM
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+// PollVote represents a user's vote for one option of a poll
+type PollVote struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	PollID    uuid.UUID `json:"poll_id" gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_unique;index:idx_poll_votes_poll_user"`
+	OptionID  uuid.UUID `json:"option_id" gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_unique"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_poll_votes_unique;index:idx_poll_votes_poll_user"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+}
+
+// TableName returns the table name for GORM
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
@@ -0,0 +1,41 @@
+// domain/models/push_device.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ผู้ให้บริการ push ที่รองรับ
+const (
+	PushProviderWebPush = "webpush"
+	PushProviderFCM     = "fcm"
+	PushProviderAPNs    = "apns"
+)
+
+// PushDevice - อุปกรณ์ที่ลงทะเบียนรับ push notification ขณะผู้ใช้ไม่ได้เชื่อมต่อ WebSocket
+// Web Push ใช้ Token เป็น endpoint ของ subscription พร้อม P256dh/Auth สำหรับเข้ารหัส payload
+type PushDevice struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	SessionID  *uuid.UUID `json:"session_id,omitempty" gorm:"type:uuid;index"` // session ที่ลงทะเบียน (ไม่ส่ง push เมื่อ session ถูกเพิกถอน)
+	Provider   string     `json:"provider" gorm:"type:varchar(20);not null"`
+	Token      string     `json:"-" gorm:"type:text;not null;uniqueIndex"`
+	P256dh     string     `json:"-" gorm:"type:varchar(255)"`
+	Auth       string     `json:"-" gorm:"type:varchar(255)"`
+	Platform   string     `json:"platform" gorm:"type:varchar(20)"`
+	DeviceName string     `json:"device_name" gorm:"type:varchar(100)"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"type:timestamp with time zone;default:now()"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" gorm:"type:timestamp with time zone"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (PushDevice) TableName() string {
+	return "push_devices"
+}
@@ -10,12 +10,15 @@ import (
 
 // RefreshToken - โทเคนสำหรับรีเฟรชการเข้าถึง
 type RefreshToken struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
-	Token     string    `json:"token" gorm:"type:text;not null"`
-	ExpiresAt time.Time `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
-	Revoked   bool      `json:"revoked" gorm:"default:false"`
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	SessionID *uuid.UUID `json:"session_id,omitempty" gorm:"type:uuid;index"` // nil = token ที่ออกก่อนมี session ต่ออุปกรณ์
+	FamilyID  uuid.UUID  `json:"family_id" gorm:"type:uuid;index"`            // token ทุกตัวที่หมุนต่อกันมาจากการเข้าสู่ระบบครั้งเดียวกัน
+	TokenHash string     `json:"-" gorm:"type:char(64);uniqueIndex"`          // SHA-256 (hex) ของ token ไม่เก็บ token จริงในฐานข้อมูล
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+	Revoked   bool       `json:"revoked" gorm:"default:false"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty" gorm:"type:timestamp with time zone"` // เวลาที่ถูกแทนด้วย token ใหม่ (token ที่หมุนออกแล้วถูกใช้ซ้ำ = token รั่วไหล)
 
 	// Associations
 	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
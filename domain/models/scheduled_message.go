@@ -20,11 +20,24 @@ type ScheduledMessage struct {
 	Metadata       types.JSONB `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
 
 	ScheduledAt time.Time  `json:"scheduled_at" gorm:"type:timestamp with time zone;not null"`
-	Status      string     `json:"status" gorm:"type:varchar(20);default:'pending'"` // pending, sent, cancelled, failed
+	Status      string     `json:"status" gorm:"type:varchar(20);default:'pending'"` // pending, processing, paused, sent, cancelled, failed
 	SentAt      *time.Time `json:"sent_at,omitempty" gorm:"type:timestamp with time zone"`
 	MessageID   *uuid.UUID `json:"message_id,omitempty" gorm:"type:uuid"` // ID ของข้อความที่ส่งแล้ว
 	ErrorReason string     `json:"error_reason,omitempty" gorm:"type:text"` // เก็บข้อผิดพลาดถ้าส่งไม่สำเร็จ
 
+	// Retry & lease (ป้องกันการส่งซ้ำเมื่อมีหลาย instance)
+	Attempts      int        `json:"attempts" gorm:"default:0"`                                    // จำนวนครั้งที่พยายามส่ง
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"type:timestamp with time zone"` // เวลาที่จะลองส่งใหม่หลังส่งไม่สำเร็จ
+	LockedBy      string     `json:"-" gorm:"type:varchar(100)"`                                   // instance ที่ claim ข้อความอยู่
+	LockedUntil   *time.Time `json:"-" gorm:"type:timestamp with time zone"`                       // lease หมดอายุเมื่อไร (instance ที่ claim อาจ crash)
+
+	// Recurrence (ข้อความตั้งเวลาแบบซ้ำ) แต่ละรอบเป็นหนึ่ง row ที่มี SeriesID เดียวกัน
+	// รอบถัดไปถูกสร้างหลังจากส่งรอบปัจจุบันแล้ว
+	SeriesID        *uuid.UUID `json:"series_id,omitempty" gorm:"type:uuid;index"`
+	RecurrenceRule  string     `json:"recurrence_rule,omitempty" gorm:"type:varchar(100)"` // cron expression เช่น "0 9 * * 1-5"
+	Timezone        string     `json:"timezone,omitempty" gorm:"type:varchar(64)"`         // IANA timezone ที่ใช้ตีความ RecurrenceRule
+	RecurrenceUntil *time.Time `json:"recurrence_until,omitempty" gorm:"type:timestamp with time zone"`
+
 	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp with time zone;default:now()"`
 
@@ -34,6 +47,22 @@ type ScheduledMessage struct {
 	Message      *Message      `json:"message,omitempty" gorm:"foreignkey:MessageID"`
 }
 
+// IsRecurring ตรวจสอบว่าเป็นข้อความตั้งเวลาแบบซ้ำหรือไม่
+func (m *ScheduledMessage) IsRecurring() bool {
+	return m.SeriesID != nil && m.RecurrenceRule != ""
+}
+
+// DueAt เวลาที่ข้อความควรถูกประมวลผลครั้งถัดไป
+func (m *ScheduledMessage) DueAt() time.Time {
+	if m.Status == "processing" && m.LockedUntil != nil {
+		return *m.LockedUntil
+	}
+	if m.NextAttemptAt != nil {
+		return *m.NextAttemptAt
+	}
+	return m.ScheduledAt
+}
+
 // TableName - ระบุชื่อตารางใน database
 func (ScheduledMessage) TableName() string {
 	return "scheduled_messages"
@@ -0,0 +1,26 @@
+// domain/models/thread_subscription.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ThreadSubscription represents a user following a message thread
+type ThreadSubscription struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	MessageID      uuid.UUID `json:"message_id" gorm:"type:uuid;not null;uniqueIndex:idx_thread_subscriptions_unique"` // root message ของ thread
+	ConversationID uuid.UUID `json:"conversation_id" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_thread_subscriptions_unique;index"`
+	CreatedAt      time.Time `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	Message *Message `json:"message,omitempty" gorm:"foreignkey:MessageID"`
+	User    *User    `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName returns the table name for GORM
+func (ThreadSubscription) TableName() string {
+	return "thread_subscriptions"
+}
@@ -0,0 +1,51 @@
+// domain/models/two_factor.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor - การตั้งค่า TOTP 2FA ของผู้ใช้ (หนึ่งแถวต่อผู้ใช้)
+// แถวที่ Enabled = false คือการลงทะเบียนที่ยังไม่ได้ยืนยันด้วยโค้ด
+type UserTwoFactor struct {
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;primary_key"`
+	Secret         string     `json:"-" gorm:"type:varchar(64);not null"` // base32 secret ของ authenticator
+	Enabled        bool       `json:"enabled" gorm:"default:false"`
+	EnabledAt      *time.Time `json:"enabled_at,omitempty" gorm:"type:timestamp with time zone"`
+	LastUsedStep   int64      `json:"-" gorm:"default:0"` // time step ล่าสุดที่ใช้แล้ว ป้องกันการใช้โค้ดเดิมซ้ำ
+	FailedAttempts int        `json:"-" gorm:"default:0"`
+	LockedUntil    *time.Time `json:"-" gorm:"type:timestamp with time zone"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// IsLocked ถูกล็อกชั่วคราวจากการใส่โค้ดผิดหลายครั้ง
+func (t *UserTwoFactor) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
+
+// UserRecoveryCode - โค้ดสำรองใช้ครั้งเดียวสำหรับเข้าสู่ระบบเมื่อไม่มี authenticator
+// เก็บเฉพาะ hash ของโค้ด ผู้ใช้เห็นโค้ดจริงเพียงครั้งเดียวตอนสร้าง
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:char(64);not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -15,6 +15,7 @@ type User struct {
 	ID              uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	Username        string      `json:"username" gorm:"type:varchar(50);not null;unique"`
 	Email           string      `json:"email,omitempty" gorm:"type:varchar(255);unique"`
+	EmailVerifiedAt *time.Time  `json:"email_verified_at,omitempty" gorm:"type:timestamp with time zone"`
 	PasswordHash    string      `json:"-" gorm:"type:text"` // ไม่ส่งกลับในการ response JSON
 	DisplayName     string      `json:"display_name,omitempty" gorm:"type:varchar(100)"`
 	ProfileImageURL string      `json:"profile_image_url,omitempty" gorm:"type:text"`
@@ -0,0 +1,37 @@
+// domain/models/user_session.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession - session การเข้าสู่ระบบของผู้ใช้ต่ออุปกรณ์
+// แต่ละ session มี refresh token ของตัวเอง การรีเฟรชหรือเพิกถอนจึงไม่กระทบอุปกรณ์อื่น
+type UserSession struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	DeviceName string     `json:"device_name" gorm:"type:varchar(100)"`
+	Platform   string     `json:"platform" gorm:"type:varchar(20)"` // ios, android, web, desktop, unknown
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent  string     `json:"user_agent,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+	LastUsedAt time.Time  `json:"last_used_at" gorm:"type:timestamp with time zone;default:now()"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" gorm:"type:timestamp with time zone"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive ตรวจว่า session ยังใช้งานได้ (ยังไม่ถูกเพิกถอนและยังไม่หมดอายุ)
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
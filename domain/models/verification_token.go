@@ -0,0 +1,41 @@
+// domain/models/verification_token.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// วัตถุประสงค์ของ VerificationToken
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// VerificationToken - โค้ดใช้ครั้งเดียวที่ส่งทางอีเมล (รีเซ็ตรหัสผ่าน / ยืนยันอีเมล)
+// เก็บเฉพาะ hash ของโค้ด โค้ดจริงอยู่ในอีเมลเท่านั้น
+type VerificationToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   string     `json:"purpose" gorm:"type:varchar(30);not null"`
+	TokenHash string     `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	Email     string     `json:"email" gorm:"type:varchar(255);not null"` // อีเมลที่ส่งโค้ดไป (ยืนยันได้เฉพาะอีเมลนี้)
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp with time zone;default:now()"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignkey:UserID"`
+}
+
+// TableName - ระบุชื่อตารางใน database
+func (VerificationToken) TableName() string {
+	return "verification_tokens"
+}
+
+// IsUsable ยังไม่ถูกใช้และยังไม่หมดอายุ
+func (t *VerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && t.ExpiresAt.After(now)
+}
@@ -0,0 +1,18 @@
+// domain/port/event_log_port.go
+package port
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// EventLogPort เก็บ event ที่ส่งผ่าน WebSocket ของผู้ใช้แต่ละคนพร้อม sequence number ที่เพิ่มขึ้นเสมอ
+type EventLogPort interface {
+	// Append บันทึก event ให้ผู้ใช้แต่ละคน คืนค่า sequence ที่ได้ของแต่ละคน
+	Append(ctx context.Context, userIDs []uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error)
+
+	// Since ดึง event ที่มี sequence มากกว่า since (สูงสุด limit รายการ)
+	Since(ctx context.Context, userID uuid.UUID, since int64, limit int) (*dto.SyncEventsDTO, error)
+}
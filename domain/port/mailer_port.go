@@ -0,0 +1,17 @@
+// domain/port/mailer_port.go
+package port
+
+import "context"
+
+// MailMessage อีเมลหนึ่งฉบับ (HTMLBody ว่างได้ จะส่งเฉพาะ TextBody)
+type MailMessage struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// MailerPort ช่องทางส่งอีเมล (SMTP สำหรับ production, log/file สำหรับ development)
+type MailerPort interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
@@ -0,0 +1,23 @@
+// domain/port/push_port.go
+package port
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// ErrPushDeviceGone provider แจ้งว่า token/subscription ใช้ไม่ได้แล้ว (ถอนการติดตั้ง, ยกเลิก subscription)
+// ผู้เรียกควรลบอุปกรณ์นั้นออก
+var ErrPushDeviceGone = errors.New("push device is no longer registered")
+
+// PushProvider ส่ง push notification ผ่านบริการภายนอก (Web Push, FCM, APNs)
+type PushProvider interface {
+	// Name ชื่อ provider ตรงกับ models.PushDevice.Provider
+	Name() string
+
+	// Send ส่ง push ไปยังอุปกรณ์ คืน ErrPushDeviceGone (wrap ได้) เมื่ออุปกรณ์ไม่มีอยู่แล้ว
+	Send(ctx context.Context, device *models.PushDevice, notification *dto.PushNotification) error
+}
@@ -19,6 +19,8 @@ type WebSocketPort interface {
 	BroadcastMessageReply(conversationID uuid.UUID, message interface{})
 	BroadcastMessageDeleted(conversationID uuid.UUID, messageID uuid.UUID)
 	BroadcastMessageReaction(conversationID uuid.UUID, reaction interface{})
+	BroadcastThreadReply(userIDs []uuid.UUID, reply interface{}) // ส่ง thread.reply ไปยังผู้ติดตาม thread เท่านั้น
+	BroadcastPollUpdated(conversationID uuid.UUID, poll interface{}) // ส่ง poll.updated เมื่อผลโหวตเปลี่ยนหรือโพลถูกปิด
 
 	// Conversation notifications
 	BroadcastConversationCreated(userIDs []uuid.UUID, conversation interface{}) error
@@ -32,6 +34,10 @@ type WebSocketPort interface {
 	BroadcastMemberRoleChanged(conversationID uuid.UUID, data interface{})
 	BroadcastOwnershipTransferred(conversationID uuid.UUID, data interface{})
 
+	// Group invite notifications
+	BroadcastJoinRequestCreated(adminIDs []uuid.UUID, request interface{}) // ส่ง join_request.created ไปยังผู้ที่อนุมัติคำขอได้
+	BroadcastJoinRequestReviewed(userID uuid.UUID, request interface{})    // ส่ง join_request.reviewed ไปยังผู้ขอเข้ากลุ่ม
+
 	// Activity log notifications
 	BroadcastNewActivity(conversationID uuid.UUID, activity interface{})
 
@@ -69,4 +75,10 @@ type WebSocketPort interface {
 	// Pinned message notifications (for public pins)
 	BroadcastMessagePinned(conversationID uuid.UUID, pinnedMessage interface{})
 	BroadcastMessageUnpinned(conversationID uuid.UUID, messageID uuid.UUID, userID uuid.UUID)
+
+	// Session management
+	DisconnectSession(userID, sessionID uuid.UUID) // ส่ง session.revoked แล้วปิด WebSocket ของ session นั้น (ทุก instance)
+
+	// Connection state
+	IsUserConnected(userID uuid.UUID) bool // ผู้ใช้มี WebSocket เชื่อมต่ออยู่บน instance นี้ (ใช้ตัดสินว่าต้องส่ง push หรือไม่)
 }
@@ -46,6 +46,11 @@ type ConversationRepository interface {
 	// RemoveMember ลบสมาชิกออกจากการสนทนา
 	RemoveMember(conversationID, userID uuid.UUID) error
 
+	// LeaveGroup ลบสมาชิกออกจากกลุ่มใน transaction เดียวที่ล็อกการสนทนาและแถวสมาชิกทั้งหมด
+	// owner ที่ออกโอนความเป็นเจ้าของให้ successor(สมาชิกที่เหลือ) ก่อนลบ
+	// กลุ่มถูกปิดเมื่อไม่มีสมาชิกเหลือหลังลบ คืน owner คนใหม่ (nil = ไม่มีการโอน) และกลุ่มถูกปิดหรือไม่
+	LeaveGroup(conversationID, userID uuid.UUID, successor func(remaining []*models.ConversationMember) *models.ConversationMember) (*uuid.UUID, bool, error)
+
 	// UpdateMemberAdmin อัพเดตสถานะแอดมินของสมาชิก
 	UpdateMemberAdmin(conversationID, userID uuid.UUID, isAdmin bool) error
 
@@ -58,6 +63,16 @@ type ConversationRepository interface {
 	// SetMuteStatus กำหนดสถานะการปิดเสียงของการสนทนา
 	SetMuteStatus(conversationID, userID uuid.UUID, isMuted bool) error
 
+	// SetSendRestriction จำกัดการส่งข้อความของสมาชิกถึงเวลาที่กำหนด (nil = ยกเลิก)
+	SetSendRestriction(conversationID, userID uuid.UUID, until *time.Time) error
+
+	// ClaimSlowModeSlot บันทึกเวลาส่งข้อความเมื่อพ้นช่วง slow mode แล้ว (ตรวจและบันทึกใน statement เดียว)
+	// คืนค่า false ถ้าข้อความล่าสุดของสมาชิกยังไม่พ้น interval
+	ClaimSlowModeSlot(conversationID, userID uuid.UUID, now time.Time, interval time.Duration) (bool, error)
+
+	// UpdateNotificationSettings บันทึกการตั้งค่าการแจ้งเตือนของสมาชิก (models.NotificationSettings.ToJSONB)
+	UpdateNotificationSettings(conversationID, userID uuid.UUID, settings types.JSONB) error
+
 	// SetHiddenStatus กำหนดสถานะการซ่อนการสนทนา
 	SetHiddenStatus(conversationID, userID uuid.UUID, isHidden bool) error
 
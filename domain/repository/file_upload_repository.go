@@ -16,6 +16,9 @@ type FileUploadRepository interface {
 	// FindByID finds a file upload by ID
 	FindByID(id uuid.UUID) (*models.FileUpload, error)
 
+	// FindByURL finds a completed file upload by its public URL
+	FindByURL(url string) (*models.FileUpload, error)
+
 	// FindByUserID finds all file uploads by user ID
 	FindByUserID(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, error)
 
@@ -0,0 +1,24 @@
+// domain/repository/group_ban_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// GroupBanRepository จัดการรายชื่อผู้ถูกแบนของกลุ่ม (หนึ่งแถวต่อผู้ใช้ต่อกลุ่ม)
+type GroupBanRepository interface {
+	// Upsert สร้างหรือแทนที่การแบนเดิมของผู้ใช้ในกลุ่ม
+	Upsert(ban *models.GroupBan) error
+
+	// FindActive การแบนที่ยังมีผลของผู้ใช้ในกลุ่ม (nil, nil ถ้าไม่ถูกแบนหรือหมดอายุแล้ว)
+	FindActive(conversationID, userID uuid.UUID, now time.Time) (*models.GroupBan, error)
+
+	// ListActive การแบนที่ยังมีผลทั้งหมดของกลุ่ม ใหม่สุดก่อน
+	ListActive(conversationID uuid.UUID, now time.Time) ([]*models.GroupBan, error)
+
+	// Delete ยกเลิกการแบน คืนค่า false ถ้าไม่มีการแบนที่ยังมีผล
+	Delete(conversationID, userID uuid.UUID, now time.Time) (bool, error)
+}
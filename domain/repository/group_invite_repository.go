@@ -0,0 +1,48 @@
+// domain/repository/group_invite_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// GroupInviteRepository จัดการลิงก์เชิญเข้ากลุ่มและคำขอเข้ากลุ่ม
+type GroupInviteRepository interface {
+	CreateInvite(invite *models.GroupInvite) error
+
+	// FindInviteByToken ค้นหาลิงก์ตาม token (รวมลิงก์ที่ถูกยกเลิกหรือหมดอายุ)
+	FindInviteByToken(token string) (*models.GroupInvite, error)
+
+	FindInviteByID(id uuid.UUID) (*models.GroupInvite, error)
+
+	// ListInvites ลิงก์ทั้งหมดของกลุ่ม ใหม่สุดก่อน
+	ListInvites(conversationID uuid.UUID) ([]*models.GroupInvite, error)
+
+	// RevokeInvite ยกเลิกลิงก์ คืนค่า false ถ้าถูกยกเลิกไปก่อนแล้ว
+	RevokeInvite(id, revokedBy uuid.UUID, at time.Time) (bool, error)
+
+	// JoinWithInvite เพิ่ม use_count และเพิ่มสมาชิกใน transaction เดียว
+	// คืนค่า false ถ้าลิงก์ใช้ไม่ได้แล้ว (ถูกยกเลิก หมดอายุ หรือครบ max_uses) ถ้าเพิ่มสมาชิกไม่สำเร็จจะไม่นับการใช้
+	JoinWithInvite(inviteID uuid.UUID, member *models.ConversationMember, now time.Time) (bool, error)
+
+	CreateJoinRequest(request *models.GroupJoinRequest) error
+
+	FindJoinRequestByID(id uuid.UUID) (*models.GroupJoinRequest, error)
+
+	// FindPendingJoinRequest คำขอที่รออนุมัติของผู้ใช้ในกลุ่ม (nil, nil ถ้าไม่มี)
+	FindPendingJoinRequest(conversationID, userID uuid.UUID) (*models.GroupJoinRequest, error)
+
+	// ListJoinRequests คำขอของกลุ่มตามสถานะ (ว่าง = ทุกสถานะ) เก่าสุดก่อน
+	ListJoinRequests(conversationID uuid.UUID, status string) ([]*models.GroupJoinRequest, error)
+
+	// ReviewJoinRequest เปลี่ยนสถานะคำขอที่ยัง pending คืนค่า false ถ้าถูกพิจารณาไปก่อนแล้ว
+	ReviewJoinRequest(id uuid.UUID, status string, reviewedBy uuid.UUID, at time.Time) (bool, error)
+
+	// ApproveJoinRequest อนุมัติคำขอที่ยัง pending นับการใช้ลิงก์ของคำขอ และเพิ่มสมาชิกใน transaction เดียว
+	// ลิงก์แบบรออนุมัติจึงนับ max_uses เฉพาะคำขอที่อนุมัติแล้ว (การยกเลิกหรือหมดอายุหลังยื่นคำขอไม่กระทบ)
+	// ถ้าผู้ขอเป็นสมาชิกอยู่แล้วจะอนุมัติโดยไม่นับการใช้และไม่เพิ่มสมาชิก (joined = false)
+	// approved = false ถ้าคำขอถูกพิจารณาไปก่อนแล้ว คืน error "invite link has reached its usage limit" ถ้าลิงก์ครบ max_uses
+	ApproveJoinRequest(request *models.GroupJoinRequest, member *models.ConversationMember, reviewedBy uuid.UUID, at time.Time) (approved bool, joined bool, err error)
+}
@@ -0,0 +1,36 @@
+// domain/repository/message_reaction_repository.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// MessageReactionRepository defines methods for message reaction operations
+type MessageReactionRepository interface {
+	// Create a reaction; returns false when the user already reacted with the emoji (nothing is inserted)
+	Create(ctx context.Context, reaction *models.MessageReaction) (bool, error)
+
+	// Delete a reaction by message, user and emoji
+	Delete(ctx context.Context, messageID, userID uuid.UUID, emoji string) error
+
+	// Check if user already reacted to a message with an emoji
+	Exists(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error)
+
+	// Count distinct emojis a user has put on a message
+	CountByUser(ctx context.Context, messageID, userID uuid.UUID) (int64, error)
+
+	// Get all reactions on a message, oldest first
+	GetByMessageID(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error)
+
+	// Get all reactions on a set of messages (one page), oldest first
+	GetByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]*models.MessageReaction, error)
+
+	// Get all reactions on a message with reacting user preloaded
+	GetByMessageIDWithUsers(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error)
+
+	// Delete all reactions for a message
+	DeleteAllByMessageID(ctx context.Context, messageID uuid.UUID) error
+}
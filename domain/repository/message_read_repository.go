@@ -2,17 +2,32 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 )
 
 // MessageReadRepository เป็น interface สำหรับจัดการข้อมูลการอ่านข้อความ
+// สถานะการอ่านเก็บเป็น read watermark ของสมาชิก (conversation_members.last_read_message_id / last_read_seq)
+// ข้อความที่ Sequence <= last_read_seq ถือว่าสมาชิกคนนั้นอ่านแล้ว
+// ข้อความหลัง watermark ที่ถูกลบหรือหมดอายุนับไว้ใน deleted_unread และไม่นับเป็น unread
 type MessageReadRepository interface {
-	CreateRead(read *models.MessageRead) error
+	// AdvanceWatermark เลื่อน watermark ไปที่ข้อความ (ไม่ถอยหลัง) คืน true ถ้ามีการเลื่อนจริง
+	// readAt ถูกบันทึกเป็น last_read_at
+	AdvanceWatermark(conversationID, userID, messageID uuid.UUID, sequence int64, readAt time.Time) (bool, error)
+
+	// CountUnread จำนวนข้อความที่ยังไม่ได้อ่าน (last_message_seq - last_read_seq - deleted_unread)
+	CountUnread(conversationID, userID uuid.UUID) (int, error)
+
+	// GetUnreadCounts จำนวนข้อความที่ยังไม่ได้อ่านของทุกการสนทนาที่ใช้งานอยู่ (เฉพาะที่มากกว่า 0)
+	GetUnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error)
+
+	// GetByMessageID สมาชิกที่ watermark ผ่านข้อความแล้ว (read_at = last_read_at ของสมาชิก)
 	GetByMessageID(messageID uuid.UUID) ([]*models.MessageRead, error)
+
+	// GetUnreadMessageIDs ข้อความของผู้อื่นหลัง watermark ที่ยังไม่ถูกลบ (ไม่รวมข้อความใน thread)
 	GetUnreadMessageIDs(conversationID, userID uuid.UUID) ([]uuid.UUID, error)
 
-	// อาจเพิ่มเมธอดอื่นๆ ถ้าจำเป็น
-	DeleteRead(messageID, userID uuid.UUID) error
 	CountReads(messageID uuid.UUID) (int, error)
 }
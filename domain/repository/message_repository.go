@@ -15,10 +15,16 @@ type MessageRepository interface {
 	GetMessagesByConversationID(conversationID uuid.UUID, limit, offset int) ([]*models.Message, int64, error)
 
 	// การสร้างและแก้ไขข้อความ
+	// Create/BulkCreate กำหนด Sequence และเลื่อน read watermark ของผู้ส่งไปที่ข้อความของตัวเอง
 	Create(message *models.Message) error
 	BulkCreate(messages []*models.Message) error
+	// CreateWithSlowMode สร้างข้อความของผู้ส่ง (ในการสนทนาเดียวกัน นับเป็นการส่งครั้งเดียว) พร้อมจอง slot ของ slow mode ใน transaction เดียว
+	// คืนค่า false และไม่สร้างข้อความถ้าข้อความล่าสุดของผู้ส่งยังไม่พ้น interval
+	CreateWithSlowMode(messages []*models.Message, senderID uuid.UUID, interval time.Duration, now time.Time) (bool, error)
 	Update(message *models.Message) error
 	UpdateFields(messageID uuid.UUID, updates map[string]interface{}) error
+	// MarkDeleted อัปเดต fields ของการลบเฉพาะเมื่อข้อความยังไม่ถูกลบ คืนค่า true ถ้าเป็นผู้ลบ (มีเพียงผู้เรียกเดียวที่ได้)
+	MarkDeleted(messageID uuid.UUID, updates map[string]interface{}) (bool, error)
 	Delete(id uuid.UUID) error
 
 	// การจัดการประวัติการแก้ไขและลบ
@@ -27,7 +33,7 @@ type MessageRepository interface {
 	CreateDeleteHistory(history *models.MessageDeleteHistory) error
 	GetDeleteHistory(messageID uuid.UUID) ([]*models.MessageDeleteHistory, error)
 
-	// การจัดการการอ่านข้อความ
+	// การจัดการการอ่านข้อความ (คำนวณจาก read watermark ของสมาชิก)
 	MarkAsRead(messageID, userID uuid.UUID, readAt time.Time) error
 	GetReads(messageID uuid.UUID) ([]*models.MessageRead, error)
 	IsMessageRead(messageID, userID uuid.UUID) (bool, error)
@@ -73,9 +79,18 @@ type MessageRepository interface {
 
 	// Search messages (CURSOR-BASED)
 	// userID ใช้สำหรับ filter เฉพาะ conversations ที่ user เป็นสมาชิก
+	// sortBy: "recent" (cursor = message ID) หรือ "relevance" (cursor = offset)
 	// Returns: messages, nextCursor, hasMore, error
-	SearchMessages(searchQuery string, conversationID *uuid.UUID, userID uuid.UUID, limit int, cursor *string, direction string) ([]*models.Message, *string, bool, error)
+	SearchMessages(searchQuery string, conversationID *uuid.UUID, userID uuid.UUID, limit int, cursor *string, direction string, sortBy string) ([]*models.Message, *string, bool, error)
 
 	// Bulk/Album messages
 	GetMessagesByAlbumID(albumID string) ([]*models.Message, error)
+
+	// Threads
+	GetThreadReplies(rootID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Message, error)
+	IncrementThreadReplyCount(rootID uuid.UUID, replyAt time.Time) error
+
+	// Disappearing messages
+	FindExpiredMessages(before time.Time, limit int) ([]*models.Message, error)
+	IsMediaURLReferenced(mediaURL string) (bool, error)
 }
@@ -0,0 +1,45 @@
+// domain/repository/poll_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// PollRepository defines methods for poll operations
+type PollRepository interface {
+	// Create a poll together with its options
+	Create(ctx context.Context, poll *models.Poll) error
+
+	// Get a poll by ID with options ordered by position
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Poll, error)
+
+	// Get the poll of a message with options ordered by position
+	GetByMessageID(ctx context.Context, messageID uuid.UUID) (*models.Poll, error)
+
+	// Get the polls of several messages with options ordered by position
+	GetByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]*models.Poll, error)
+
+	// Replace a user's votes on a poll. Returns false if the poll is closed.
+	SetVotes(ctx context.Context, pollID, userID uuid.UUID, optionIDs []uuid.UUID) (bool, error)
+
+	// Remove all of a user's votes on a poll. Returns false if the poll is closed.
+	DeleteVotes(ctx context.Context, pollID, userID uuid.UUID) (bool, error)
+
+	// Get all votes on a poll, oldest first
+	GetVotes(ctx context.Context, pollID uuid.UUID) ([]*models.PollVote, error)
+
+	// Get all votes on several polls, oldest first
+	GetVotesByPollIDs(ctx context.Context, pollIDs []uuid.UUID) ([]*models.PollVote, error)
+
+	// Mark a poll as closed and store the final result snapshot built by snapshot from
+	// the final votes, in one transaction. Returns false if it was already closed.
+	Close(ctx context.Context, pollID uuid.UUID, closedBy *uuid.UUID, closedAt time.Time, snapshot func(votes []*models.PollVote) types.JSONB) (bool, error)
+
+	// Find open polls whose close time has passed
+	FindDueForClose(ctx context.Context, now time.Time, limit int) ([]*models.Poll, error)
+}
@@ -0,0 +1,22 @@
+// domain/repository/push_device_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// PushDeviceRepository จัดการอุปกรณ์ที่ลงทะเบียนรับ push notification
+type PushDeviceRepository interface {
+	// Upsert ลงทะเบียนอุปกรณ์ ถ้า token เดิมมีอยู่แล้ว (เช่น ผู้ใช้อื่นเคยใช้เครื่องนี้) จะย้ายมาเป็นของผู้ใช้นี้
+	Upsert(device *models.PushDevice) error
+
+	// FindByID ค้นหาอุปกรณ์ตาม ID (nil, nil ถ้าไม่พบ)
+	FindByID(id uuid.UUID) (*models.PushDevice, error)
+	FindByUserID(userID uuid.UUID) ([]*models.PushDevice, error)
+
+	Delete(id uuid.UUID) error
+	MarkUsed(id uuid.UUID, at time.Time) error
+}
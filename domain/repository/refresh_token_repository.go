@@ -10,8 +10,13 @@ import (
 
 type RefreshTokenRepository interface {
 	Create(refreshToken *models.RefreshToken) error
-	FindByToken(token string) (*models.RefreshToken, error)
+	// FindByTokenHash ค้นหาจาก hash ของ token รวมถึง token ที่ถูกยกเลิกแล้ว (ใช้ตรวจการนำ token เก่ากลับมาใช้)
+	FindByTokenHash(tokenHash string) (*models.RefreshToken, error)
 	RevokeByUserID(userID uuid.UUID) error
+	// MarkRotated ยกเลิก token ที่ถูกหมุนออก คืนค่า false ถ้า token ถูกยกเลิกไปก่อนแล้ว (เช่นถูกใช้พร้อมกันสองครั้ง)
+	MarkRotated(id uuid.UUID, rotatedAt time.Time) (bool, error)
+	RevokeBySessionID(sessionID uuid.UUID) error // ยกเลิกเฉพาะ token ของ session (อุปกรณ์) นั้น
+	RevokeFamily(familyID uuid.UUID) error       // ยกเลิก token ทั้ง family เมื่อพบการใช้ token ซ้ำ
 	DeleteExpired(before time.Time) error
 	// เพิ่ม method อื่นๆ ตามที่จำเป็น
 }
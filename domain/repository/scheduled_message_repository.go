@@ -21,7 +21,23 @@ type ScheduledMessageRepository interface {
 	FindByConversationAndUser(conversationID, userID uuid.UUID, limit, offset int) ([]*models.ScheduledMessage, int64, error)
 	FindPendingMessages(beforeTime time.Time, limit int) ([]*models.ScheduledMessage, error)
 
+	// Recurring series
+	FindBySeriesID(seriesID uuid.UUID, limit, offset int) ([]*models.ScheduledMessage, int64, error)
+	FindUpcomingInSeries(seriesID uuid.UUID) (*models.ScheduledMessage, error) // รอบถัดไปที่ยังไม่ส่ง (pending / paused)
+
 	// Status updates
 	UpdateStatus(id uuid.UUID, status string, sentAt *time.Time, messageID *uuid.UUID, errorReason string) error
 	CancelScheduledMessage(id uuid.UUID) error
+	// PauseScheduledMessage เปลี่ยนสถานะเป็น paused เฉพาะเมื่อยัง pending (ยังไม่ถูก claim ไปส่ง)
+	PauseScheduledMessage(id uuid.UUID) (bool, error)
+
+	// Claim / lease
+	// Claim เปลี่ยนสถานะเป็น processing ถ้าถึงเวลาส่งและยังไม่มีใคร claim (หรือ lease หมดอายุ)
+	// คืนค่า true ถ้า claim สำเร็จ (มีเพียง instance เดียวที่ได้)
+	Claim(id uuid.UUID, owner string, leaseUntil time.Time) (bool, error)
+	// ScheduleRetry ปล่อย claim และตั้งเวลาส่งใหม่
+	ScheduleRetry(id uuid.UUID, owner string, nextAttemptAt time.Time, errorReason string) error
+	// CompleteClaim บันทึกผลการส่ง (sent / failed) เฉพาะเมื่อ owner ยังถือ claim อยู่
+	// คืนค่า false ถ้า lease หมดอายุและ instance อื่น claim ไปแล้ว
+	CompleteClaim(id uuid.UUID, owner string, status string, sentAt *time.Time, messageID *uuid.UUID, errorReason string) (bool, error)
 }
@@ -0,0 +1,24 @@
+// domain/repository/thread_subscription_repository.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// ThreadSubscriptionRepository defines methods for thread follow operations
+type ThreadSubscriptionRepository interface {
+	// Subscribe a user to a thread (no-op if already subscribed)
+	Subscribe(ctx context.Context, subscription *models.ThreadSubscription) error
+
+	// Unsubscribe a user from a thread
+	Unsubscribe(ctx context.Context, messageID, userID uuid.UUID) error
+
+	// Check if a user follows a thread
+	IsSubscribed(ctx context.Context, messageID, userID uuid.UUID) (bool, error)
+
+	// Get the IDs of users following a thread who are still conversation members
+	GetSubscriberIDs(ctx context.Context, messageID uuid.UUID) ([]uuid.UUID, error)
+}
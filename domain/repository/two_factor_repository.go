@@ -0,0 +1,38 @@
+// domain/repository/two_factor_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// TwoFactorRepository จัดการการตั้งค่า TOTP 2FA และโค้ดสำรองของผู้ใช้
+type TwoFactorRepository interface {
+	// FindByUserID คืน nil, nil เมื่อผู้ใช้ยังไม่เคยลงทะเบียน 2FA
+	FindByUserID(userID uuid.UUID) (*models.UserTwoFactor, error)
+
+	// Save สร้างหรือแทนที่การตั้งค่าของผู้ใช้
+	Save(twoFactor *models.UserTwoFactor) error
+
+	// Delete ลบการตั้งค่าและโค้ดสำรองทั้งหมดของผู้ใช้ (ปิด 2FA)
+	Delete(userID uuid.UUID) error
+
+	// MarkStepUsed บันทึก time step ที่ใช้แล้ว คืนค่า false ถ้า step นี้หรือใหม่กว่าถูกใช้ไปก่อนแล้ว
+	MarkStepUsed(userID uuid.UUID, step int64) (bool, error)
+
+	// UpdateFailures บันทึกจำนวนครั้งที่ใส่โค้ดผิดและเวลาที่ล็อก (nil = ไม่ล็อก)
+	UpdateFailures(userID uuid.UUID, failedAttempts int, lockedUntil *time.Time) error
+
+	// IncrementFailures เพิ่มจำนวนครั้งที่ใส่โค้ดผิดแบบ atomic และคืนจำนวนหลังเพิ่ม
+	IncrementFailures(userID uuid.UUID) (int, error)
+
+	// ReplaceRecoveryCodes ลบโค้ดสำรองเดิมทั้งหมดแล้วบันทึกชุดใหม่
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []*models.UserRecoveryCode) error
+
+	// UseRecoveryCode ใช้โค้ดสำรอง คืนค่า false ถ้าไม่พบหรือถูกใช้ไปแล้ว
+	UseRecoveryCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error)
+
+	CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error)
+}
@@ -10,7 +10,7 @@ type UserRepository interface {
 	Create(user *models.User) error
 	FindByUsername(username string) (*models.User, error)
 	FindByID(id uuid.UUID) (*models.User, error)    // มีอยู่แล้ว
-	FindByEmail(email string) (*models.User, error) // เพิ่มเมธอดนี้
+	FindByEmail(email string) (*models.User, error) // เพิ่มเมธอดนี้ (nil, nil ถ้าไม่พบ)
 	Update(user *models.User) error
 	SearchUsers(query string, limit, offset int) ([]*models.User, int, error)
 
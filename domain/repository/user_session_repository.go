@@ -0,0 +1,32 @@
+// domain/repository/user_session_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// UserSessionRepository จัดการ session การเข้าสู่ระบบต่ออุปกรณ์
+type UserSessionRepository interface {
+	Create(session *models.UserSession) error
+
+	// FindByID ค้นหา session ตาม ID (nil, nil ถ้าไม่พบ)
+	FindByID(id uuid.UUID) (*models.UserSession, error)
+
+	// FindActiveByUserID ดึง session ที่ยังใช้งานได้ของผู้ใช้ (ใช้งานล่าสุดก่อน)
+	FindActiveByUserID(userID uuid.UUID, now time.Time) ([]*models.UserSession, error)
+
+	// Touch บันทึกการใช้งานล่าสุด (เมื่อรีเฟรช token) พร้อม IP และวันหมดอายุใหม่
+	Touch(id uuid.UUID, ipAddress string, usedAt, expiresAt time.Time) error
+
+	// Revoke เพิกถอน session (ไม่มีผลกับ session ที่ถูกเพิกถอนไปแล้ว)
+	Revoke(id uuid.UUID, revokedAt time.Time) error
+
+	// RevokeAllByUserID เพิกถอนทุก session ของผู้ใช้ยกเว้น exceptID (uuid.Nil = ทั้งหมด)
+	// คืนค่า ID ของ session ที่ถูกเพิกถอน
+	RevokeAllByUserID(userID, exceptID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error)
+
+	DeleteExpired(before time.Time) error
+}
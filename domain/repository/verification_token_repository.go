@@ -0,0 +1,28 @@
+// domain/repository/verification_token_repository.go
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// VerificationTokenRepository จัดการโค้ดใช้ครั้งเดียวสำหรับรีเซ็ตรหัสผ่านและยืนยันอีเมล
+type VerificationTokenRepository interface {
+	Create(token *models.VerificationToken) error
+
+	// FindByTokenHash ค้นหาโค้ดตาม purpose และ hash (รวมโค้ดที่ใช้แล้วหรือหมดอายุ) คืน nil, nil ถ้าไม่พบ
+	FindByTokenHash(purpose, tokenHash string) (*models.VerificationToken, error)
+
+	// MarkUsed ใช้โค้ด คืนค่า false ถ้าโค้ดถูกใช้ไปก่อนแล้ว (ป้องกันการใช้ซ้ำพร้อมกัน)
+	MarkUsed(id uuid.UUID, usedAt time.Time) (bool, error)
+
+	// InvalidateByUserID ยกเลิกโค้ดที่ยังไม่ได้ใช้ของผู้ใช้ตาม purpose (เมื่อออกโค้ดใหม่หรือใช้โค้ดแล้ว)
+	InvalidateByUserID(userID uuid.UUID, purpose string, at time.Time) error
+
+	// CountIssuedSince นับโค้ดที่ออกให้ผู้ใช้ตาม purpose ตั้งแต่เวลาที่กำหนด (ใช้จำกัดจำนวนอีเมลที่ส่ง)
+	CountIssuedSince(userID uuid.UUID, purpose string, since time.Time) (int64, error)
+
+	DeleteExpired(before time.Time) error
+}
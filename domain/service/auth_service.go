@@ -3,15 +3,30 @@
 package service
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 )
 
 type AuthService interface {
-	Register(username, password, email, displayName string) (*models.User, string, string, error)
-	Login(username, password string) (*models.User, string, string, error)
-	RefreshToken(refreshToken string) (string, string, error)
-	Logout(userID uuid.UUID) error                       // เปลี่ยนเป็น UUID
-	BlacklistToken(userID uuid.UUID, token string) error // เปลี่ยนเป็น UUID
-	GetUserByID(userID uuid.UUID) (*models.User, error)  // เปลี่ยนเป็น UUID
+	Register(username, password, email, displayName string, device dto.DeviceInfo) (*models.User, string, string, error)
+	Login(username, password string, device dto.DeviceInfo) (*models.User, string, string, error) // คืน *TwoFactorRequiredError เมื่อเปิด 2FA
+	VerifyTwoFactorLogin(challengeToken, code string, device dto.DeviceInfo) (*models.User, string, string, error)
+	RefreshToken(refreshToken, ipAddress string) (string, string, error) // หมุนเฉพาะ token ของ session เดียวกัน
+	Logout(userID, sessionID uuid.UUID) error                            // sessionID = uuid.Nil สำหรับ token รุ่นเก่าที่ไม่มี session
+	BlacklistToken(userID uuid.UUID, token string) error                 // เปลี่ยนเป็น UUID
+	GetUserByID(userID uuid.UUID) (*models.User, error)                  // เปลี่ยนเป็น UUID
+}
+
+// TwoFactorRequiredError รหัสผ่านถูกต้องแต่ต้องยืนยันโค้ด 2FA ก่อนออก token
+// ใช้ ChallengeToken กับ VerifyTwoFactorLogin ภายใน ExpiresAt
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresAt      time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
 }
@@ -2,22 +2,57 @@
 package service
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 )
 
-// Permission represents permissions in a conversation
-type Permission string
+// Permission represents permissions in a conversation (ดู models.PermissionPolicy)
+type Permission = models.Permission
+
+const (
+	PermissionAddMember         = models.PermissionAddMember
+	PermissionRemoveMember      = models.PermissionRemoveMember
+	PermissionChangeRole        = models.PermissionChangeRole
+	PermissionUpdateInfo        = models.PermissionUpdateInfo
+	PermissionDeleteGroup       = models.PermissionDeleteGroup
+	PermissionManagePermissions = models.PermissionManagePermissions
+	PermissionSendMessage       = models.PermissionSendMessage
+	PermissionSendMedia         = models.PermissionSendMedia
+	PermissionPinMessage        = models.PermissionPinMessage
+	PermissionInvite            = models.PermissionInvite
+	PermissionModerateMembers   = models.PermissionModerateMembers
+)
+
+// PermissionError ผู้ใช้ไม่มีสิทธิ์ตาม permission policy ของการสนทนา
+type PermissionError struct {
+	Permission Permission
+	Message    string
+}
+
+func (e *PermissionError) Error() string {
+	return e.Message
+}
 
+// เหตุผลใน SendRestrictionError
 const (
-	PermissionAddMember    Permission = "add_member"
-	PermissionRemoveMember Permission = "remove_member"
-	PermissionChangeRole   Permission = "change_role"
-	PermissionUpdateInfo   Permission = "update_info"
-	PermissionDeleteGroup  Permission = "delete_group"
+	SendRestrictionRestricted = "restricted" // แอดมินจำกัดการส่งข้อความของสมาชิก
+	SendRestrictionSlowMode   = "slow_mode"  // ยังไม่ครบช่วงเวลาของ slow mode
 )
 
+// SendRestrictionError ผู้ใช้มีสิทธิ์ส่งข้อความ แต่ถูกจำกัดชั่วคราว (ส่งได้อีกครั้งเมื่อถึง Until)
+type SendRestrictionError struct {
+	Reason  string
+	Until   time.Time
+	Message string
+}
+
+func (e *SendRestrictionError) Error() string {
+	return e.Message
+}
+
 // ConversationMemberService interface สำหรับจัดการสมาชิกในการสนทนา
 type ConversationMemberService interface {
 	// AddMember เพิ่มสมาชิกในการสนทนากลุ่ม
@@ -44,9 +79,22 @@ type ConversationMemberService interface {
 	// ChangeRole เปลี่ยน role ของสมาชิก
 	ChangeRole(conversationID, userID uuid.UUID, newRole models.MemberRole) (*models.ConversationMember, error)
 
-	// HasPermission ตรวจสอบว่าผู้ใช้มีสิทธิ์ทำอะไรใน conversation หรือไม่
+	// HasPermission ตรวจสอบว่าผู้ใช้มีสิทธิ์ทำอะไรใน conversation หรือไม่ (ตาม permission policy ของกลุ่ม)
 	HasPermission(conversationID, userID uuid.UUID, permission Permission) (bool, error)
 
+	// CheckSendAllowed ตรวจว่าผู้ใช้ส่งข้อความประเภทนี้ได้ตอนนี้หรือไม่ (สิทธิ์, การถูกจำกัดการส่ง, slow mode)
+	// เมื่อผ่าน slow mode จะนับเป็นการส่งหนึ่งครั้ง
+	CheckSendAllowed(conversationID, userID uuid.UUID, messageType string) error
+
+	// LeaveGroup ออกจากกลุ่ม (owner โอนความเป็นเจ้าของอัตโนมัติ, คนสุดท้ายออกแล้วกลุ่มถูกปิด)
+	LeaveGroup(conversationID, userID uuid.UUID) (*dto.LeaveGroupResultDTO, error)
+
+	// GetPermissionPolicy ดึง permission policy ของกลุ่ม พร้อมสิทธิ์ของผู้ใช้เอง
+	GetPermissionPolicy(conversationID, userID uuid.UUID) (*dto.PermissionPolicyDTO, error)
+
+	// UpdatePermissionPolicy เปลี่ยน role ขั้นต่ำของสิทธิ์ที่ระบุ (owner เท่านั้น) คืนค่า policy ใหม่และ role เดิมของสิทธิ์ที่เปลี่ยน
+	UpdatePermissionPolicy(conversationID, userID uuid.UUID, input *dto.UpdatePermissionPolicyRequest) (*dto.PermissionPolicyDTO, map[string]string, error)
+
 	//ค้นหาการสนทนาแบบ direct ระหว่างผู้ใช้สองคน
 	FindDirectConversationBetweenUsers(userID, friendID uuid.UUID) (uuid.UUID, error)
 }
@@ -4,6 +4,7 @@ package service
 import (
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/types"
 )
 
@@ -26,6 +27,12 @@ type ConversationService interface {
 	// SetMuteStatus กำหนดสถานะการปิดเสียงของการสนทนา
 	SetMuteStatus(conversationID, userID uuid.UUID, isMuted bool) error
 
+	// GetNotificationSettings ดึงการตั้งค่าการแจ้งเตือนของผู้ใช้ในการสนทนา
+	GetNotificationSettings(conversationID, userID uuid.UUID) (*dto.NotificationSettingsDTO, error)
+
+	// UpdateNotificationSettings ตั้งค่าการแจ้งเตือน (ระดับ, ปิดเสียงชั่วคราว, keyword)
+	UpdateNotificationSettings(conversationID, userID uuid.UUID, input *dto.NotificationSettingsRequest) (*dto.NotificationSettingsDTO, error)
+
 	// CheckMembership ตรวจสอบว่าผู้ใช้เป็นสมาชิกของการสนทนาหรือไม่
 	CheckMembership(userID, conversationID uuid.UUID) (bool, error)
 
@@ -68,6 +75,15 @@ type ConversationService interface {
 	// DeleteConversation ลบการสนทนา (smart delete - hide for direct, leave for group)
 	DeleteConversation(conversationID, userID uuid.UUID) (string, error)
 
+	// LeaveGroup ออกจากกลุ่ม (owner โอนความเป็นเจ้าของอัตโนมัติ, คนสุดท้ายออกแล้วกลุ่มถูกปิด)
+	LeaveGroup(conversationID, userID uuid.UUID) (*dto.LeaveGroupResultDTO, error)
+
 	// TransferOwnership โอนความเป็นเจ้าของกลุ่มให้สมาชิกคนอื่น
 	TransferOwnership(conversationID, currentOwnerID, newOwnerID uuid.UUID) error
+
+	// SetMessageTTL ตั้งค่าข้อความหายไปอัตโนมัติ (วินาที, 0 = ปิด) คืนค่าเดิม
+	SetMessageTTL(conversationID, userID uuid.UUID, ttlSeconds int) (int, error)
+
+	// ConvertToMessageDTO แปลง Message model เป็น MessageDTO (ข้อมูลผู้ส่ง การอ่าน reply และ reactions)
+	ConvertToMessageDTO(msg *models.Message, userID uuid.UUID) (*dto.MessageDTO, error)
 }
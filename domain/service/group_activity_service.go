@@ -2,6 +2,8 @@
 package service
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/dto"
 )
@@ -20,4 +22,16 @@ type GroupActivityService interface {
 	LogMemberRoleChanged(conversationID, actorID, targetID uuid.UUID, oldRole, newRole string) error
 	LogOwnershipTransferred(conversationID, oldOwnerID, newOwnerID uuid.UUID) error
 	LogMemberLeft(conversationID, userID uuid.UUID) error
+	LogMessageTTLChanged(conversationID, actorID uuid.UUID, oldTTL, newTTL int) error
+	LogPermissionsChanged(conversationID, actorID uuid.UUID, oldValues, newValues map[string]string) error
+	LogInviteCreated(conversationID, actorID uuid.UUID, invite *dto.GroupInviteDTO) error
+	LogInviteRevoked(conversationID, actorID, inviteID uuid.UUID) error
+	LogMemberJoined(conversationID, userID, inviteID uuid.UUID) error
+	LogJoinRequested(conversationID, userID, requestID uuid.UUID) error
+	LogJoinRequestReviewed(conversationID, actorID, targetID, requestID uuid.UUID, approved bool) error
+	LogMemberBanned(conversationID, actorID uuid.UUID, ban *dto.GroupBanDTO, removed bool) error
+	LogMemberUnbanned(conversationID, actorID, targetID uuid.UUID) error
+	LogMemberRestricted(conversationID, actorID, targetID uuid.UUID, until time.Time) error
+	LogMemberUnrestricted(conversationID, actorID, targetID uuid.UUID) error
+	LogSlowModeChanged(conversationID, actorID uuid.UUID, oldSeconds, newSeconds int) error
 }
@@ -0,0 +1,33 @@
+// domain/service/group_invite_service.go
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// GroupInviteService interface สำหรับลิงก์เชิญเข้ากลุ่มและคำขอเข้ากลุ่ม
+// การสร้าง/ดู/ยกเลิกลิงก์ และการพิจารณาคำขอ ต้องมีสิทธิ์ invite ตาม permission policy ของกลุ่ม
+type GroupInviteService interface {
+	// CreateInvite สร้างลิงก์เชิญใหม่ (กำหนดวันหมดอายุ จำนวนครั้งที่ใช้ได้ และการต้องรออนุมัติได้)
+	CreateInvite(conversationID, userID uuid.UUID, input *dto.CreateGroupInviteRequest) (*dto.GroupInviteDTO, error)
+
+	// ListInvites ดึงลิงก์เชิญทั้งหมดของกลุ่ม (รวมลิงก์ที่ใช้ไม่ได้แล้ว) ใหม่สุดก่อน
+	ListInvites(conversationID, userID uuid.UUID) ([]*dto.GroupInviteDTO, error)
+
+	// RevokeInvite ยกเลิกลิงก์เชิญ
+	RevokeInvite(conversationID, inviteID, userID uuid.UUID) (*dto.GroupInviteDTO, error)
+
+	// JoinByToken เข้ากลุ่มผ่านลิงก์เชิญ ถ้าลิงก์ต้องรออนุมัติจะสร้างคำขอแทน (status "pending")
+	// การเข้ากลุ่มหรือการส่งคำขอนับเป็นการใช้ลิงก์หนึ่งครั้ง
+	JoinByToken(token string, userID uuid.UUID) (*dto.JoinGroupResultDTO, error)
+
+	// ListJoinRequests ดึงคำขอเข้ากลุ่มตามสถานะ (ว่าง = pending)
+	ListJoinRequests(conversationID, userID uuid.UUID, status string) ([]*dto.GroupJoinRequestDTO, error)
+
+	// ApproveJoinRequest อนุมัติคำขอและเพิ่มผู้ขอเป็นสมาชิก
+	ApproveJoinRequest(conversationID, requestID, userID uuid.UUID) (*dto.GroupJoinRequestDTO, error)
+
+	// RejectJoinRequest ปฏิเสธคำขอ
+	RejectJoinRequest(conversationID, requestID, userID uuid.UUID) (*dto.GroupJoinRequestDTO, error)
+}
@@ -0,0 +1,32 @@
+// domain/service/group_moderation_service.go
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// GroupModerationService interface สำหรับเครื่องมือดูแลกลุ่ม: แบน, จำกัดการส่งข้อความ และ slow mode
+// ทุกเมธอดต้องมีสิทธิ์ moderate_members และจัดการได้เฉพาะสมาชิกที่มี role ต่ำกว่าตัวเอง
+type GroupModerationService interface {
+	// BanMember แบนผู้ใช้ออกจากกลุ่ม ถ้ายังเป็นสมาชิกจะถูกนำออกด้วย
+	// ผู้ที่ถูกแบนถูกเพิ่มกลับหรือเข้าผ่านลิงก์เชิญไม่ได้จนกว่าจะหมดอายุหรือยกเลิกแบน
+	BanMember(conversationID, actorID uuid.UUID, input *dto.BanMemberRequest) (*dto.BanMemberResultDTO, error)
+
+	// UnbanMember ยกเลิกแบน
+	UnbanMember(conversationID, actorID, targetID uuid.UUID) error
+
+	// ListBans ดึงรายการแบนที่ยังมีผล ใหม่สุดก่อน
+	ListBans(conversationID, actorID uuid.UUID) ([]*dto.GroupBanDTO, error)
+
+	// RestrictMember จำกัดไม่ให้สมาชิกส่งข้อความจนถึงเวลาที่กำหนด
+	RestrictMember(conversationID, actorID, targetID uuid.UUID, until time.Time) (*dto.MemberRestrictionDTO, error)
+
+	// UnrestrictMember ยกเลิกการจำกัดการส่งข้อความ
+	UnrestrictMember(conversationID, actorID, targetID uuid.UUID) (*dto.MemberRestrictionDTO, error)
+
+	// SetSlowMode ตั้งช่วงเวลาขั้นต่ำระหว่างข้อความของสมาชิกแต่ละคน (0 = ปิด) คืนค่าเดิม
+	SetSlowMode(conversationID, actorID uuid.UUID, seconds int) (int, error)
+}
@@ -0,0 +1,26 @@
+// domain/service/image_service.go
+
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+)
+
+// ImageService ประมวลผลรูปที่อัปโหลดแล้ว: ลบ metadata (รวมพิกัด GPS), หมุนตาม EXIF,
+// สร้าง thumbnail/medium และคำนวณ blurhash แล้วบันทึกผลไว้ให้ค้นจาก URL ตอนส่งข้อความรูปภาพ
+type ImageService interface {
+	// ProcessUploadedImage ประมวลผลรูปที่อัปโหลดผ่าน multipart แล้วสร้าง FileUpload record ที่ completed
+	ProcessUploadedImage(userID uuid.UUID, filename, contentType string, result *FileUploadResult) (*dto.ProcessedImageDTO, error)
+
+	// ProcessConfirmedUpload ประมวลผลรูปของ upload ที่ยืนยันแล้ว (presigned flow) และอัปเดต record
+	ProcessConfirmedUpload(upload *models.FileUpload) (*dto.ProcessedImageDTO, error)
+
+	// StagingPath path ที่ให้ client PUT รูปเข้ามาก่อนยืนยัน (presigned flow)
+	// ไฟล์ถูกย้ายไป path จริงหลังลบ metadata ใน ProcessConfirmedUpload
+	StagingPath(path string) string
+
+	// FindProcessedImage ดึงผลการประมวลผลของรูปจาก public URL
+	FindProcessedImage(url string) (*dto.ProcessedImageDTO, error)
+}
@@ -0,0 +1,30 @@
+// domain/service/media_access_service.go
+
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// MediaAccessService ควบคุมการเข้าถึงไฟล์แนบของข้อความ
+// โหมด private: ข้อความเก็บเฉพาะ path ใน storage และ URL ที่ส่งให้ client เป็น presigned download URL อายุสั้น
+// ที่ออกให้เฉพาะสมาชิกของการสนทนา, โหมดปกติทุกเมธอดคืนค่าเดิมไม่เปลี่ยนแปลง
+type MediaAccessService interface {
+	// IsPrivate เปิดโหมด private media อยู่หรือไม่
+	IsPrivate() bool
+
+	// StorageRef แปลง URL ที่ client ส่งมาเป็นค่าที่บันทึกในข้อความ (private: path ใน storage, URL ภายนอกคงเดิม)
+	StorageRef(mediaURL string) string
+
+	// ResolveURL แปลงค่าที่บันทึกไว้เป็น URL ที่ดาวน์โหลดได้ (ผู้เรียกต้องตรวจสิทธิ์เอง)
+	ResolveURL(ref string) string
+
+	// ResolveMessageMedia แทน path ใน DTO ด้วย presigned URL หลังตรวจว่า userID เป็นสมาชิกของการสนทนา
+	// ไม่ใช่สมาชิก = ล้าง URL ของไฟล์ทิ้งทั้งหมด
+	ResolveMessageMedia(message *dto.MessageDTO, userID uuid.UUID)
+
+	// ResolveMessageMediaForMembers เหมือน ResolveMessageMedia แต่ไม่ตรวจสมาชิก
+	// ใช้กับ payload ที่ส่งถึงเฉพาะสมาชิกของการสนทนาอยู่แล้ว (WebSocket broadcast)
+	ResolveMessageMediaForMembers(message *dto.MessageDTO)
+}
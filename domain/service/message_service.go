@@ -14,6 +14,7 @@ type MessageService interface {
 	SendImageMessage(conversationID uuid.UUID, userID uuid.UUID, mediaURL string, thumbnailURL string, caption string, metadata map[string]interface{}) (*models.Message, error)
 	SendFileMessage(conversationID uuid.UUID, userID uuid.UUID, mediaURL string, fileName string, fileSize int64, fileType string, metadata map[string]interface{}) (*models.Message, error)
 	SendBulkMessages(conversationID uuid.UUID, userID uuid.UUID, caption string, items []map[string]interface{}) (*models.Message, error)
+	SendPollMessage(conversationID uuid.UUID, userID uuid.UUID, question string, metadata map[string]interface{}) (*models.Message, error)
 
 	// ส่งข้อความในนามธุรกิจ
 
@@ -24,6 +25,9 @@ type MessageService interface {
 	// จัดการข้อความ
 	EditMessage(messageID uuid.UUID, userID uuid.UUID, newContent string, metadata map[string]interface{}) (*models.Message, error)
 	DeleteMessage(messageID uuid.UUID, userID uuid.UUID) error
+
+	// DeleteExpiredMessage ลบข้อความที่หมดอายุ (ข้อความหายไปอัตโนมัติ) โดยระบบ
+	DeleteExpiredMessage(messageID uuid.UUID) error
 	ReplyToMessage(replyToID uuid.UUID, userID uuid.UUID, messageType string, content string, mediaURL string, thumbnailURL string, metadata map[string]interface{}) (*models.Message, error)
 
 	// ดูประวัติข้อความ
@@ -39,8 +43,9 @@ type MessageService interface {
 	GetMessagesByDate(conversationID, userID uuid.UUID, date string, limit int) ([]*models.Message, int64, bool, bool, error)
 
 	// Search messages (CURSOR-BASED)
+	// sortBy: "recent" หรือ "relevance"
 	// Returns: messages, nextCursor, hasMore, error
-	SearchMessages(query string, conversationID *uuid.UUID, userID uuid.UUID, limit int, cursor *string, direction string) ([]*models.Message, *string, bool, error)
+	SearchMessages(query string, conversationID *uuid.UUID, userID uuid.UUID, limit int, cursor *string, direction string, sortBy string) ([]*models.Message, *string, bool, error)
 
 	// Forward messages
 	ForwardMessage(messageID, targetConversationID, userID uuid.UUID) (*models.Message, error)
@@ -19,6 +19,9 @@ type NotificationService interface {
 	NotifyMessageReply(conversationID uuid.UUID, message interface{})
 	NotifyMessageDeleted(conversationID uuid.UUID, messageID uuid.UUID)
 	NotifyMessageReaction(conversationID uuid.UUID, reaction interface{})
+	NotifyThreadReply(followerIDs []uuid.UUID, reply interface{})
+	NotifyPollUpdated(conversationID uuid.UUID, poll interface{})
+	NotifyMention(conversationID uuid.UUID, userIDs []uuid.UUID, notification interface{}) // ข้าม user ที่ปิดเสียงหรือปิดการแจ้งเตือน
 
 	// Conversation notifications
 	NotifyConversationCreated(userIDs []uuid.UUID, conversation interface{}) error
@@ -33,6 +36,10 @@ type NotificationService interface {
 	NotifyMemberRoleChanged(conversationID, userID uuid.UUID, oldRole, newRole string, changedByUserID uuid.UUID)
 	NotifyOwnershipTransferred(conversationID, previousOwnerID, newOwnerID uuid.UUID)
 
+	// Group invite notifications
+	NotifyJoinRequestCreated(conversationID uuid.UUID, request interface{}) // ส่งไปยังสมาชิกที่มีสิทธิ์ invite เท่านั้น
+	NotifyJoinRequestReviewed(userID uuid.UUID, request interface{})
+
 	// Activity log notifications
 	NotifyNewActivity(conversationID uuid.UUID, activity *dto.ActivityDTO)
 
@@ -0,0 +1,30 @@
+// domain/service/poll_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// PollService defines methods for poll operations
+type PollService interface {
+	// Create a poll message in a conversation
+	CreatePoll(ctx context.Context, conversationID, userID uuid.UUID, req *dto.CreatePollRequest) (*dto.MessageDTO, error)
+
+	// Get a poll with its current tally
+	GetPoll(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error)
+
+	// Vote on a poll, replacing the user's previous choices
+	Vote(ctx context.Context, pollID, userID uuid.UUID, optionIDs []uuid.UUID) (*dto.PollDTO, error)
+
+	// Retract all of the user's votes on a poll
+	RetractVote(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error)
+
+	// Close a poll (creator, or owner/admin of a group)
+	ClosePoll(ctx context.Context, pollID, userID uuid.UUID) (*dto.PollDTO, error)
+
+	// Close polls whose close time has passed. Returns the number of polls closed.
+	CloseExpiredPolls(ctx context.Context) (int, error)
+}
@@ -17,11 +17,16 @@ type UserPresence struct {
 
 // PresenceService manages user online presence
 type PresenceService interface {
-	// SetUserOnline marks a user as online
-	SetUserOnline(userID uuid.UUID) error
+	// UserConnected records that a node holds connections of the user (called on the node's first connection)
+	// Returns true if no other node had a connection, i.e. the user just came online
+	UserConnected(userID uuid.UUID, nodeID string) (bool, error)
 
-	// SetUserOffline marks a user as offline
-	SetUserOffline(userID uuid.UUID) error
+	// UserDisconnected removes a node from the user's connections (called when the node's last connection closes)
+	// Returns true if no node holds a connection anymore; only then the user is marked offline
+	UserDisconnected(userID uuid.UUID, nodeID string) (bool, error)
+
+	// RefreshConnections keeps the users connected to a node online (nodes that stop refreshing expire)
+	RefreshConnections(userIDs []uuid.UUID, nodeID string) error
 
 	// UpdateLastActive updates user's last active timestamp
 	UpdateLastActive(userID uuid.UUID) error
@@ -0,0 +1,29 @@
+// domain/service/push_service.go
+
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// PushService ส่ง push notification ให้ผู้ใช้ที่ไม่ได้เชื่อมต่อ WebSocket และจัดการอุปกรณ์ที่ลงทะเบียน
+type PushService interface {
+	// RegisterDevice ลงทะเบียน (หรืออัปเดต) อุปกรณ์ของผู้ใช้ sessionID ใช้ผูกอุปกรณ์กับ session ที่ลงทะเบียน (uuid.Nil = ไม่ผูก)
+	RegisterDevice(userID, sessionID uuid.UUID, req *dto.RegisterPushDeviceRequest) (*dto.PushDeviceDTO, error)
+
+	// ListDevices ดึงอุปกรณ์ที่ลงทะเบียนของผู้ใช้
+	ListDevices(userID, currentSessionID uuid.UUID) ([]*dto.PushDeviceDTO, error)
+
+	// UnregisterDevice ยกเลิกการลงทะเบียนอุปกรณ์ (เฉพาะของผู้ใช้เอง)
+	UnregisterDevice(userID, deviceID uuid.UUID) error
+
+	// VAPIDPublicKey public key สำหรับ PushManager.subscribe() (ว่างถ้าไม่ได้เปิด Web Push)
+	VAPIDPublicKey() string
+
+	// NotifyOffline ส่ง push ให้ผู้ใช้ที่ไม่ได้เชื่อมต่อ push ที่มี CollapseKey เดียวกันภายในช่วงเวลาสั้นๆ จะถูกรวมเป็นรายการเดียว
+	NotifyOffline(userIDs []uuid.UUID, notification *dto.PushNotification)
+
+	// Flush ส่ง push ที่รอรวมอยู่ทั้งหมดทันที (ใช้ตอนปิดเซิร์ฟเวอร์)
+	Flush()
+}
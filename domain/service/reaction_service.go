@@ -0,0 +1,21 @@
+// domain/service/reaction_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// ReactionService defines methods for message reaction operations
+type ReactionService interface {
+	// Add a reaction to a message (no-op if already present)
+	AddReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*dto.MessageReactionsDTO, error)
+
+	// Remove own reaction from a message
+	RemoveReaction(ctx context.Context, messageID, userID uuid.UUID, emoji string) (*dto.MessageReactionsDTO, error)
+
+	// List reactions on a message with per-emoji summary
+	GetReactions(ctx context.Context, messageID, userID uuid.UUID) (*dto.MessageReactionsDTO, error)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 )
 
@@ -25,6 +26,13 @@ type ScheduledMessageService interface {
 	CancelScheduledMessage(id, userID uuid.UUID) error
 	UpdateScheduledTime(id, userID uuid.UUID, newScheduledAt time.Time) (*models.ScheduledMessage, error)
 
+	// Recurring series (cron expression + timezone)
+	ScheduleRecurringMessage(conversationID, userID uuid.UUID, messageType, content, mediaURL string, metadata map[string]interface{}, recurrenceRule, timezone string, startAt time.Time, until *time.Time) (*models.ScheduledMessage, error)
+	GetSeries(seriesID, userID uuid.UUID, limit, offset int) ([]*models.ScheduledMessage, int64, error)
+	PauseSeries(seriesID, userID uuid.UUID) (*models.ScheduledMessage, error)
+	ResumeSeries(seriesID, userID uuid.UUID) (*models.ScheduledMessage, error)
+	UpdateSeries(seriesID, userID uuid.UUID, req *dto.UpdateScheduledSeriesRequest) (*models.ScheduledMessage, error)
+
 	// For processor to use
 	GetPendingMessagesForProcessor(beforeTime time.Time, limit int) ([]*models.ScheduledMessage, error)
 	ProcessSingleScheduledMessage(messageID uuid.UUID) error
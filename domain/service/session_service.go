@@ -0,0 +1,23 @@
+// domain/service/session_service.go
+
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// SessionService จัดการ session การเข้าสู่ระบบต่ออุปกรณ์
+type SessionService interface {
+	// ListSessions ดึง session ที่ยังใช้งานได้ของผู้ใช้ (currentSessionID ใช้ระบุ session ปัจจุบัน)
+	ListSessions(userID, currentSessionID uuid.UUID) ([]*dto.SessionDTO, error)
+
+	// RevokeSession เพิกถอน session หนึ่ง: ยกเลิก refresh token และปิด WebSocket ของอุปกรณ์นั้น
+	RevokeSession(userID, sessionID uuid.UUID) error
+
+	// RevokeOtherSessions เพิกถอนทุก session ยกเว้น session ปัจจุบัน คืนจำนวนที่ถูกเพิกถอน
+	RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int, error)
+
+	// IsSessionActive ตรวจว่า session ยังไม่ถูกเพิกถอนและยังไม่หมดอายุ
+	IsSessionActive(sessionID uuid.UUID) (bool, error)
+}
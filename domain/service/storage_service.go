@@ -2,6 +2,7 @@
 package service
 
 import (
+	"io"
 	"mime/multipart"
 	"time"
 )
@@ -43,3 +44,36 @@ type FileStorageService interface {
 	GeneratePresignedUploadURL(path string, contentType string, expiry time.Duration) (*PresignedURLResult, error) // สร้าง URL สำหรับ client upload ตรง
 	GeneratePresignedDownloadURL(path string, expiry time.Duration) (string, error) // สร้าง URL สำหรับ download ไฟล์ private
 }
+
+// SignedFileServer storage ที่ server รับและส่งไฟล์เอง (เช่น local driver) แทนการให้ client คุยกับ cloud โดยตรง
+// URL จาก GeneratePresigned* ชี้กลับมาที่ route ของ server ซึ่งต้องตรวจลายเซ็นก่อนอ่านหรือเขียนไฟล์
+type SignedFileServer interface {
+	// VerifySignedURL ตรวจลายเซ็นและเวลาหมดอายุ (method คือ GET หรือ PUT, contentType ใช้กับ PUT เท่านั้น)
+	VerifySignedURL(method, path, contentType, expires, signature string) error
+
+	// RequiresSignedDownload false = ดาวน์โหลดผ่าน public URL ได้โดยไม่ต้องมีลายเซ็น
+	RequiresSignedDownload() bool
+
+	// OpenFile เปิดไฟล์เพื่ออ่าน คืนขนาดไฟล์ (bytes)
+	OpenFile(path string) (io.ReadCloser, int64, error)
+
+	// WriteFile เขียนไฟล์ทับของเดิม (ใช้กับ presigned upload)
+	WriteFile(path string, r io.Reader) (int64, error)
+}
+
+// ObjectStore storage ที่ server อ่านและเขียนไฟล์ตาม path ได้โดยตรง (R2, S3, local)
+// ใช้กับงานที่ต้องแก้ไฟล์หลังอัปโหลด เช่น image pipeline; Cloudinary ประมวลผลรูปเองจึงไม่ต้อง implement
+type ObjectStore interface {
+	// GetObject เปิดไฟล์เพื่ออ่าน
+	GetObject(path string) (io.ReadCloser, error)
+
+	// PutObject เขียนไฟล์ทับ path เดิม (หรือสร้างใหม่)
+	PutObject(path string, contentType string, data []byte) error
+}
+
+// PrivateStorage storage ที่ตั้งค่าให้ไฟล์ดาวน์โหลดได้เฉพาะผ่าน presigned URL ได้ (S3, local)
+// โหมด private media ใช้ได้เฉพาะ storage ที่ implement และคืน true
+type PrivateStorage interface {
+	// KeepsFilesPrivate true = ไฟล์ไม่เปิด public ต้องดาวน์โหลดผ่าน GeneratePresignedDownloadURL
+	KeepsFilesPrivate() bool
+}
@@ -0,0 +1,24 @@
+// domain/service/sync_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// SyncService defines methods for per-user event sequencing and catch-up
+type SyncService interface {
+	// Record an event for the given users, returns the sequence assigned to each user
+	RecordUserEvent(ctx context.Context, userIDs []uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error)
+
+	// Record an event for every member of a conversation
+	RecordConversationEvent(ctx context.Context, conversationID uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error)
+
+	// Drop the cached member list of a conversation after its membership changed
+	InvalidateConversationMembers(conversationID uuid.UUID)
+
+	// Get the user's events after the given sequence
+	GetEventsSince(ctx context.Context, userID uuid.UUID, since int64, limit int) (*dto.SyncEventsDTO, error)
+}
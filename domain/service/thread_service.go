@@ -0,0 +1,24 @@
+// domain/service/thread_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// ThreadService defines methods for message thread operations
+type ThreadService interface {
+	// Reply in the thread of a root message (a reply to a thread reply goes to the same thread)
+	ReplyInThread(ctx context.Context, messageID, userID uuid.UUID, messageType, content, mediaURL, thumbnailURL string, metadata map[string]interface{}) (*dto.MessageDTO, error)
+
+	// List replies under a root message, oldest first, starting after cursor
+	GetThreadReplies(ctx context.Context, messageID, userID uuid.UUID, cursor string, limit int) (*dto.ThreadRepliesDTO, error)
+
+	// Follow a thread to receive thread.reply events
+	FollowThread(ctx context.Context, messageID, userID uuid.UUID) (*dto.ThreadFollowDTO, error)
+
+	// Stop following a thread
+	UnfollowThread(ctx context.Context, messageID, userID uuid.UUID) (*dto.ThreadFollowDTO, error)
+}
@@ -0,0 +1,29 @@
+// domain/service/two_factor_service.go
+
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+)
+
+// TwoFactorService จัดการ TOTP 2FA (RFC 6238) และโค้ดสำรองใช้ครั้งเดียว
+type TwoFactorService interface {
+	// BeginEnrollment สร้าง secret ใหม่ (ยังไม่เปิดใช้จนกว่าจะยืนยันด้วยโค้ด)
+	BeginEnrollment(userID uuid.UUID) (*dto.TwoFactorEnrollmentResponse, error)
+
+	// ConfirmEnrollment เปิดใช้ 2FA ด้วยโค้ดจาก authenticator คืนโค้ดสำรองชุดแรก (แสดงได้ครั้งเดียว)
+	ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error)
+
+	// Disable ปิด 2FA (ต้องยืนยันด้วยโค้ดหรือโค้ดสำรอง)
+	Disable(userID uuid.UUID, code string) error
+
+	// RegenerateRecoveryCodes สร้างโค้ดสำรองชุดใหม่แทนชุดเดิม
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error)
+
+	GetStatus(userID uuid.UUID) (*dto.TwoFactorStatusResponse, error)
+	IsEnabled(userID uuid.UUID) (bool, error)
+
+	// VerifyCode ตรวจโค้ดจาก authenticator หรือโค้ดสำรอง (ล็อกชั่วคราวเมื่อผิดหลายครั้ง)
+	VerifyCode(userID uuid.UUID, code string) error
+}
@@ -0,0 +1,20 @@
+// domain/service/verification_service.go
+
+package service
+
+import "github.com/google/uuid"
+
+// VerificationService รีเซ็ตรหัสผ่านและยืนยันอีเมลด้วยโค้ดใช้ครั้งเดียวที่ส่งทางอีเมล
+type VerificationService interface {
+	// RequestPasswordReset ส่งลิงก์รีเซ็ตรหัสผ่าน (ไม่คืน error เมื่อไม่พบอีเมล เพื่อไม่เปิดเผยว่ามีบัญชีอยู่)
+	RequestPasswordReset(email string) error
+
+	// ResetPassword ตั้งรหัสผ่านใหม่ด้วยโค้ด แล้วออกจากระบบทุกอุปกรณ์
+	ResetPassword(token, newPassword string) error
+
+	// SendEmailVerification ส่งลิงก์ยืนยันอีเมลไปยังอีเมลปัจจุบันของผู้ใช้
+	SendEmailVerification(userID uuid.UUID) error
+
+	// VerifyEmail ยืนยันอีเมลด้วยโค้ด
+	VerifyEmail(token string) error
+}
@@ -0,0 +1,200 @@
+// infrastructure/adapter/apns_provider.go
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	apnsDefaultTTL     = 24 * time.Hour
+
+	// Apple ให้ใช้ provider token ซ้ำได้ไม่เกิน 1 ชั่วโมง และห้ามสร้างใหม่บ่อยกว่า 20 นาที
+	apnsTokenLifetime = 50 * time.Minute
+	apnsMaxCollapseID = 64
+)
+
+// APNsConfig การตั้งค่า Apple Push Notification service (token-based authentication)
+type APNsConfig struct {
+	KeyFile    string // ไฟล์ .p8 จาก Apple Developer
+	KeyID      string
+	TeamID     string
+	Topic      string // bundle id ของแอป
+	Production bool   // false = sandbox (development build)
+	Timeout    time.Duration
+}
+
+// APNsProvider ส่ง push ไปยังแอป iOS ผ่าน APNs (HTTP/2)
+type APNsProvider struct {
+	config APNsConfig
+	key    *ecdsa.PrivateKey
+	host   string
+	client *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+var _ port.PushProvider = (*APNsProvider)(nil)
+
+// NewAPNsProvider สร้าง APNsProvider จาก signing key (.p8)
+func NewAPNsProvider(config APNsConfig) (*APNsProvider, error) {
+	if config.KeyFile == "" || config.KeyID == "" || config.TeamID == "" || config.Topic == "" {
+		return nil, errors.New("apns key file, key id, team id and topic are required")
+	}
+	raw, err := os.ReadFile(config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apns key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apns key: %w", err)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	host := apnsSandboxHost
+	if config.Production {
+		host = apnsProductionHost
+	}
+
+	// http.Client ปกติเจรจา HTTP/2 ผ่าน TLS ALPN ให้อยู่แล้ว ซึ่ง APNs บังคับใช้
+	return &APNsProvider{
+		config: config,
+		key:    key,
+		host:   host,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name ชื่อ provider
+func (p *APNsProvider) Name() string {
+	return models.PushProviderAPNs
+}
+
+// Send ส่ง alert push ไปยัง device token
+func (p *APNsProvider) Send(ctx context.Context, device *models.PushDevice, notification *dto.PushNotification) error {
+	providerToken, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+
+	aps := map[string]interface{}{
+		"alert": map[string]string{
+			"title": notification.Title,
+			"body":  notification.Body,
+		},
+		"sound": "default",
+	}
+	if notification.CollapseKey != "" {
+		// จัดกลุ่มการแจ้งเตือนของการสนทนาเดียวกันใน Notification Center
+		aps["thread-id"] = notification.CollapseKey
+	}
+	payload := map[string]interface{}{"aps": aps}
+	for k, v := range notification.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ttl := notification.TTL
+	if ttl <= 0 {
+		ttl = apnsDefaultTTL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+device.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", p.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	if collapseID := notification.CollapseKey; collapseID != "" && len(collapseID) <= apnsMaxCollapseID {
+		req.Header.Set("apns-collapse-id", collapseID)
+	}
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	_ = json.Unmarshal(detail, &result)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		result.Reason == "BadDeviceToken",
+		result.Reason == "Unregistered",
+		result.Reason == "DeviceTokenNotForTopic":
+		return port.ErrPushDeviceGone
+	case result.Reason == "ExpiredProviderToken":
+		p.mu.Lock()
+		p.token = ""
+		p.mu.Unlock()
+	}
+	if result.Reason == "" {
+		result.Reason = strings.TrimSpace(string(detail))
+	}
+	return fmt.Errorf("apns send failed: %s: %s", resp.Status, result.Reason)
+}
+
+// providerToken คืน JWT สำหรับ header authorization (สร้างใหม่ทุก apnsTokenLifetime)
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.issuedAt) < apnsTokenLifetime {
+		return p.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.config.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.config.KeyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+	p.token = signed
+	p.issuedAt = now
+	return signed, nil
+}
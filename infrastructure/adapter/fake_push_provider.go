@@ -0,0 +1,80 @@
+// infrastructure/adapter/fake_push_provider.go
+package adapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+// PushDelivery push หนึ่งรายการที่ FakePushProvider บันทึกไว้
+type PushDelivery struct {
+	Device       models.PushDevice
+	Notification dto.PushNotification
+}
+
+// FakePushProvider implements port.PushProvider โดยบันทึก push ไว้ในหน่วยความจำแทนการส่งจริง
+// ใช้ในการทดสอบ service และใน development เมื่อไม่ได้ตั้งค่า provider จริง
+type FakePushProvider struct {
+	name string
+
+	mu         sync.Mutex
+	deliveries []PushDelivery
+	gone       map[string]bool
+}
+
+var _ port.PushProvider = (*FakePushProvider)(nil)
+
+// NewFakePushProvider สร้าง FakePushProvider ที่รับอุปกรณ์ของ provider ตามชื่อที่กำหนด (เช่น "fcm")
+func NewFakePushProvider(name string) *FakePushProvider {
+	return &FakePushProvider{
+		name: name,
+		gone: make(map[string]bool),
+	}
+}
+
+// Name ชื่อ provider
+func (p *FakePushProvider) Name() string {
+	return p.name
+}
+
+// Send บันทึก push หรือคืน ErrPushDeviceGone ถ้า token ถูกกำหนดไว้ด้วย MarkGone
+func (p *FakePushProvider) Send(ctx context.Context, device *models.PushDevice, notification *dto.PushNotification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.gone[device.Token] {
+		return port.ErrPushDeviceGone
+	}
+	p.deliveries = append(p.deliveries, PushDelivery{Device: *device, Notification: *notification})
+	return nil
+}
+
+// MarkGone ทำให้การส่งไปยัง token นี้คืน ErrPushDeviceGone (เลียนแบบแอปที่ถูกถอนการติดตั้ง)
+func (p *FakePushProvider) MarkGone(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gone[token] = true
+}
+
+// Deliveries คืนสำเนาของ push ทั้งหมดตามลำดับที่ส่ง
+func (p *FakePushProvider) Deliveries() []PushDelivery {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	deliveries := make([]PushDelivery, len(p.deliveries))
+	copy(deliveries, p.deliveries)
+	return deliveries
+}
+
+// Reset ล้าง push ที่บันทึกไว้
+func (p *FakePushProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.deliveries = nil
+}
@@ -0,0 +1,239 @@
+// infrastructure/adapter/fcm_provider.go
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+const (
+	fcmScope       = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL     = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmDefaultTTL  = 24 * time.Hour
+	fcmTokenMargin = time.Minute
+)
+
+// FCMConfig การตั้งค่า Firebase Cloud Messaging (HTTP v1 API)
+type FCMConfig struct {
+	CredentialsFile string // ไฟล์ service account JSON จาก Firebase console
+	ProjectID       string // ถ้าว่างใช้ project_id จากไฟล์ credentials
+	Timeout         time.Duration
+}
+
+// fcmServiceAccount ฟิลด์ที่ต้องใช้จากไฟล์ service account
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMProvider ส่ง push ไปยังแอป Android (และ iOS ที่ใช้ FCM) ผ่าน FCM HTTP v1
+type FCMProvider struct {
+	projectID string
+	account   fcmServiceAccount
+	client    *http.Client
+
+	// OAuth access token ที่แลกจาก service account (ใช้ซ้ำจนใกล้หมดอายุ)
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+var _ port.PushProvider = (*FCMProvider)(nil)
+
+// NewFCMProvider สร้าง FCMProvider จากไฟล์ service account
+func NewFCMProvider(config FCMConfig) (*FCMProvider, error) {
+	if config.CredentialsFile == "" {
+		return nil, errors.New("fcm credentials file is required")
+	}
+	raw, err := os.ReadFile(config.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+	}
+
+	var account fcmServiceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("invalid fcm credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("fcm credentials must contain client_email and private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	projectID := config.ProjectID
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" {
+		return nil, errors.New("fcm project id is required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	// ตรวจ private key ตั้งแต่ตอนเริ่ม ไม่ใช่ตอนส่ง push ครั้งแรก
+	if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey)); err != nil {
+		return nil, fmt.Errorf("invalid fcm private key: %w", err)
+	}
+
+	return &FCMProvider{
+		projectID: projectID,
+		account:   account,
+		client:    &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name ชื่อ provider
+func (p *FCMProvider) Name() string {
+	return models.PushProviderFCM
+}
+
+// Send ส่ง push ไปยัง registration token ของอุปกรณ์
+func (p *FCMProvider) Send(ctx context.Context, device *models.PushDevice, notification *dto.PushNotification) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	ttl := notification.TTL
+	if ttl <= 0 {
+		ttl = fcmDefaultTTL
+	}
+
+	android := map[string]interface{}{
+		"priority": "high",
+		"ttl":      strconv.Itoa(int(ttl.Seconds())) + "s",
+	}
+	message := map[string]interface{}{
+		"token": device.Token,
+		"notification": map[string]string{
+			"title": notification.Title,
+			"body":  notification.Body,
+		},
+		"android": android,
+	}
+	if len(notification.Data) > 0 {
+		message["data"] = notification.Data
+	}
+	if notification.CollapseKey != "" {
+		android["collapse_key"] = notification.CollapseKey
+		message["apns"] = map[string]interface{}{
+			"headers": map[string]string{"apns-collapse-id": notification.CollapseKey},
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmSendURL, p.projectID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusUnauthorized {
+		// access token อาจถูกเพิกถอน ให้แลกใหม่ในครั้งถัดไป
+		p.mu.Lock()
+		p.accessToken = ""
+		p.mu.Unlock()
+	}
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(detail), "UNREGISTERED") {
+		return port.ErrPushDeviceGone
+	}
+	return fmt.Errorf("fcm send failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// token คืน OAuth access token โดยแลกใหม่เมื่อใกล้หมดอายุ
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Add(fcmTokenMargin).Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.account.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("fcm token exchange failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("fcm token exchange returned no access token")
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
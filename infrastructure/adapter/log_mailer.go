@@ -0,0 +1,75 @@
+// infrastructure/adapter/log_mailer.go
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+// tokenPattern ค่า token ในลิงก์รีเซ็ตรหัสผ่าน/ยืนยันอีเมล
+var tokenPattern = regexp.MustCompile(`(token=)[^\s&]+`)
+
+// LogMailer ไม่ส่งอีเมลจริง แต่เขียนอีเมลลง log และต่อท้ายไฟล์ (ถ้ากำหนด path)
+// ใช้สำหรับ local development เพื่อเปิดลิงก์รีเซ็ตรหัสผ่าน/ยืนยันอีเมลได้โดยไม่ต้องมี SMTP
+// token ใน log ถูกปิดไว้ (log มักถูกส่งต่อไประบบรวม log) ลิงก์เต็มอยู่ในไฟล์ MAIL_LOG_FILE เท่านั้น
+type LogMailer struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+var _ port.MailerPort = (*LogMailer)(nil)
+
+// NewLogMailer สร้าง LogMailer (filePath ว่าง = เขียนลง log อย่างเดียว)
+func NewLogMailer(filePath string) *LogMailer {
+	return &LogMailer{filePath: filePath}
+}
+
+// Send เขียนอีเมลลง log และไฟล์
+func (m *LogMailer) Send(ctx context.Context, msg port.MailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entry := formatMailEntry(msg, time.Now())
+	log.Printf("LogMailer: email to %s\n%s", msg.To, redactTokens(entry))
+
+	if m.filePath == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open mail log file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry + "\n"); err != nil {
+		return fmt.Errorf("write mail log file: %w", err)
+	}
+	return nil
+}
+
+func formatMailEntry(msg port.MailMessage, at time.Time) string {
+	var b strings.Builder
+	b.WriteString("----- " + at.Format(time.RFC3339) + " -----\n")
+	b.WriteString("To: " + msg.To + "\n")
+	b.WriteString("Subject: " + msg.Subject + "\n\n")
+	b.WriteString(msg.TextBody + "\n")
+	return b.String()
+}
+
+// redactTokens แทนค่า token ในลิงก์ด้วย [redacted]
+func redactTokens(text string) string {
+	return tokenPattern.ReplaceAllString(text, "${1}[redacted]")
+}
@@ -0,0 +1,183 @@
+// infrastructure/adapter/redis_backplane.go
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/interfaces/websocket"
+)
+
+// DefaultBackplaneChannel ชื่อ Redis channel ที่ใช้กระจาย WebSocket broadcast
+const DefaultBackplaneChannel = "ws:broadcast"
+
+// ขนาด buffer ของคิว publish (เท่ากับ broadcast channel ของ Hub)
+const backplaneQueueSize = 1000
+
+// backplaneEnvelope ข้อมูลที่ส่งผ่าน Redis pub/sub
+type backplaneEnvelope struct {
+	NodeID     string              `json:"node_id"`
+	Type       string              `json:"type"`
+	Data       json.RawMessage     `json:"data"`
+	UserIDs    []uuid.UUID         `json:"user_ids,omitempty"`
+	BusinessID *uuid.UUID          `json:"business_id,omitempty"`
+	ConvID     *uuid.UUID          `json:"conversation_id,omitempty"`
+	SessionID  *uuid.UUID          `json:"session_id,omitempty"`
+	StatusOf   *uuid.UUID          `json:"status_of,omitempty"`
+	Seqs       map[uuid.UUID]int64 `json:"seqs,omitempty"`
+}
+
+// RedisBackplane กระจาย broadcast ของ Hub ไปยังทุก API instance ผ่าน Redis pub/sub
+// แต่ละ instance ส่งให้ client ของตัวเองตาม userConnections / conversationSubs ที่มีอยู่ในเครื่อง
+type RedisBackplane struct {
+	client  *redis.Client
+	hub     *websocket.Hub
+	channel string
+	nodeID  string
+	queue   chan []byte
+}
+
+// NewRedisBackplane สร้าง RedisBackplane ตัวใหม่
+// ชื่อ channel อ่านจาก WS_BACKPLANE_CHANNEL (ค่าเริ่มต้น ws:broadcast)
+func NewRedisBackplane(client *redis.Client, hub *websocket.Hub) *RedisBackplane {
+	channel := os.Getenv("WS_BACKPLANE_CHANNEL")
+	if channel == "" {
+		channel = DefaultBackplaneChannel
+	}
+
+	return &RedisBackplane{
+		client:  client,
+		hub:     hub,
+		channel: channel,
+		nodeID:  uuid.New().String(),
+		queue:   make(chan []byte, backplaneQueueSize),
+	}
+}
+
+// Publish ใส่ broadcast ลงคิวเพื่อส่งไปยัง instance อื่น (ไม่ block ผู้เรียก)
+func (b *RedisBackplane) Publish(msg *websocket.BroadcastMessage) {
+	if b == nil || msg == nil {
+		return
+	}
+
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		log.Printf("Backplane: failed to marshal message type %s: %v", msg.Type, err)
+		return
+	}
+
+	payload, err := json.Marshal(backplaneEnvelope{
+		NodeID:     b.nodeID,
+		Type:       string(msg.Type),
+		Data:       data,
+		UserIDs:    msg.UserIDs,
+		BusinessID: msg.BusinessID,
+		ConvID:     msg.ConvID,
+		SessionID:  msg.SessionID,
+		StatusOf:   msg.StatusOf,
+		Seqs:       msg.Seqs,
+	})
+	if err != nil {
+		log.Printf("Backplane: failed to marshal envelope type %s: %v", msg.Type, err)
+		return
+	}
+
+	select {
+	case b.queue <- payload:
+	default:
+		log.Printf("Backplane queue full, dropping message type %s", msg.Type)
+	}
+}
+
+// Start เริ่ม publish loop และ subscribe loop จนกว่า ctx จะถูกยกเลิก
+func (b *RedisBackplane) Start(ctx context.Context) {
+	log.Printf("Backplane started on channel %s (node %s)", b.channel, b.nodeID)
+
+	go b.publishLoop(ctx)
+	b.subscribeLoop(ctx)
+}
+
+// publishLoop ส่งข้อความในคิวไปยัง Redis ตามลำดับ
+func (b *RedisBackplane) publishLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-b.queue:
+			pubCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			if err := b.client.Publish(pubCtx, b.channel, payload).Err(); err != nil {
+				log.Printf("Backplane: publish failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// subscribeLoop รับข้อความจาก instance อื่น และ subscribe ใหม่เมื่อการเชื่อมต่อหลุด
+func (b *RedisBackplane) subscribeLoop(ctx context.Context) {
+	for {
+		pubsub := b.client.Subscribe(ctx, b.channel)
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Backplane: subscribe failed, retrying: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
+			continue
+		}
+
+		b.consume(ctx, pubsub.Channel())
+		pubsub.Close()
+
+		if ctx.Err() != nil {
+			log.Println("Backplane: context cancelled, shutting down")
+			return
+		}
+	}
+}
+
+// consume ส่งต่อข้อความจาก Redis ให้ Hub จน channel ถูกปิดหรือ ctx ถูกยกเลิก
+func (b *RedisBackplane) consume(ctx context.Context, messages <-chan *redis.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var envelope backplaneEnvelope
+			if err := json.Unmarshal([]byte(m.Payload), &envelope); err != nil {
+				log.Printf("Backplane: invalid payload: %v", err)
+				continue
+			}
+
+			// ข้อความจาก instance นี้ถูกส่งให้ client ในเครื่องไปแล้ว
+			if envelope.NodeID == b.nodeID {
+				continue
+			}
+
+			b.hub.DeliverFromBackplane(&websocket.BroadcastMessage{
+				Type:       websocket.MessageType(envelope.Type),
+				Data:       envelope.Data,
+				UserIDs:    envelope.UserIDs,
+				BusinessID: envelope.BusinessID,
+				ConvID:     envelope.ConvID,
+				SessionID:  envelope.SessionID,
+				StatusOf:   envelope.StatusOf,
+				Seqs:       envelope.Seqs,
+			})
+		}
+	}
+}
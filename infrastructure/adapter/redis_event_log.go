@@ -0,0 +1,188 @@
+// infrastructure/adapter/redis_event_log.go
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+// ค่าเริ่มต้นของ event log
+const (
+	DefaultEventLogMaxLen = 1000           // จำนวน event สูงสุดต่อผู้ใช้ (โดยประมาณ)
+	DefaultEventLogTTL    = 72 * time.Hour // event log ของผู้ใช้ที่ไม่มี event ใหม่จะหมดอายุ
+)
+
+const (
+	eventSeqKeyPrefix    = "ws:seq:"
+	eventStreamKeyPrefix = "ws:events:"
+)
+
+// appendEventScript เพิ่ม sequence และเขียน event ลง stream ใน operation เดียว
+// ใช้ sequence เป็น stream ID (<seq>-0) ทำให้อ่านต่อจาก sequence ใดๆ ได้ด้วย XRANGE
+// KEYS[1] = seq key, KEYS[2] = stream key
+// ARGV = maxlen, ttl (วินาที), type, data, timestamp
+var appendEventScript = `
+local seq = redis.call('INCR', KEYS[1])
+redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[1], seq .. '-0', 'type', ARGV[3], 'data', ARGV[4], 'ts', ARGV[5])
+redis.call('EXPIRE', KEYS[2], ARGV[2])
+return seq
+`
+
+// RedisEventLog เก็บ event log ของผู้ใช้แต่ละคนใน Redis stream
+// sequence counter (ws:seq:<userID>) ไม่มีวันหมดอายุ เพื่อให้ sequence เพิ่มขึ้นเสมอ
+type RedisEventLog struct {
+	client *redis.Client
+	maxLen int64
+	ttl    time.Duration
+}
+
+// NewRedisEventLog สร้าง RedisEventLog ตัวใหม่
+// ปรับขนาดได้ด้วย WS_EVENT_LOG_MAX_LEN และ WS_EVENT_LOG_TTL_HOURS
+func NewRedisEventLog(client *redis.Client) port.EventLogPort {
+	maxLen := int64(DefaultEventLogMaxLen)
+	if v, err := strconv.ParseInt(os.Getenv("WS_EVENT_LOG_MAX_LEN"), 10, 64); err == nil && v > 0 {
+		maxLen = v
+	}
+
+	ttl := DefaultEventLogTTL
+	if v, err := strconv.Atoi(os.Getenv("WS_EVENT_LOG_TTL_HOURS")); err == nil && v > 0 {
+		ttl = time.Duration(v) * time.Hour
+	}
+
+	return &RedisEventLog{
+		client: client,
+		maxLen: maxLen,
+		ttl:    ttl,
+	}
+}
+
+// Append บันทึก event ให้ผู้ใช้แต่ละคน (pipeline เดียว)
+func (l *RedisEventLog) Append(ctx context.Context, userIDs []uuid.UUID, eventType string, data interface{}) (map[uuid.UUID]int64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	ttlSeconds := int64(l.ttl / time.Second)
+
+	pipe := l.client.Pipeline()
+	cmds := make([]*redis.Cmd, len(userIDs))
+	for i, userID := range userIDs {
+		cmds[i] = pipe.Eval(ctx, appendEventScript,
+			[]string{eventSeqKey(userID), eventStreamKey(userID)},
+			l.maxLen, ttlSeconds, eventType, string(payload), now,
+		)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	seqs := make(map[uuid.UUID]int64, len(userIDs))
+	for i, userID := range userIDs {
+		seq, err := cmds[i].Int64()
+		if err != nil {
+			return nil, err
+		}
+		seqs[userID] = seq
+	}
+
+	return seqs, nil
+}
+
+// Since ดึง event ที่มี sequence มากกว่า since
+func (l *RedisEventLog) Since(ctx context.Context, userID uuid.UUID, since int64, limit int) (*dto.SyncEventsDTO, error) {
+	latest, err := l.client.Get(ctx, eventSeqKey(userID)).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	result := &dto.SyncEventsDTO{
+		Events:    make([]dto.SyncEventDTO, 0),
+		LatestSeq: latest,
+	}
+
+	// client มี sequence ที่ไม่ตรงกับ server (เช่น counter ถูกล้าง) ต้องโหลดใหม่
+	if since > latest {
+		result.ResetRequired = true
+		return result, nil
+	}
+	if since == latest {
+		return result, nil
+	}
+
+	entries, err := l.client.XRangeN(ctx, eventStreamKey(userID),
+		fmt.Sprintf("%d-0", since+1), "+", int64(limit)+1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) > limit {
+		result.HasMore = true
+		entries = entries[:limit]
+	}
+
+	for _, entry := range entries {
+		event, err := parseEventEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		result.Events = append(result.Events, event)
+	}
+
+	// event ถัดจาก since ถูกตัดออกจาก log แล้ว (เกิน MAXLEN หรือหมดอายุ)
+	if len(result.Events) == 0 || result.Events[0].Seq > since+1 {
+		result.ResetRequired = true
+	}
+
+	return result, nil
+}
+
+// parseEventEntry แปลง stream entry เป็น SyncEventDTO
+func parseEventEntry(entry redis.XMessage) (dto.SyncEventDTO, error) {
+	seqPart := entry.ID
+	if i := strings.IndexByte(seqPart, '-'); i >= 0 {
+		seqPart = seqPart[:i]
+	}
+	seq, err := strconv.ParseInt(seqPart, 10, 64)
+	if err != nil {
+		return dto.SyncEventDTO{}, fmt.Errorf("invalid event id %s: %w", entry.ID, err)
+	}
+
+	event := dto.SyncEventDTO{Seq: seq}
+	if v, ok := entry.Values["type"].(string); ok {
+		event.Type = v
+	}
+	if v, ok := entry.Values["data"].(string); ok {
+		event.Data = json.RawMessage(v)
+	}
+	if v, ok := entry.Values["ts"].(string); ok {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			event.Timestamp = time.UnixMilli(ms)
+		}
+	}
+
+	return event, nil
+}
+
+func eventSeqKey(userID uuid.UUID) string {
+	return eventSeqKeyPrefix + userID.String()
+}
+
+func eventStreamKey(userID uuid.UUID) string {
+	return eventStreamKeyPrefix + userID.String()
+}
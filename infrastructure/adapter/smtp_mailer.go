@@ -0,0 +1,180 @@
+// infrastructure/adapter/smtp_mailer.go
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+// SMTPMailerConfig การตั้งค่า SMTP
+type SMTPMailerConfig struct {
+	Host     string
+	Port     string // 465 = implicit TLS, พอร์ตอื่นใช้ STARTTLS ถ้า server รองรับ
+	Username string
+	Password string
+	From     string // อีเมลผู้ส่ง
+	FromName string
+	Timeout  time.Duration
+}
+
+// SMTPMailer ส่งอีเมลผ่าน SMTP server
+type SMTPMailer struct {
+	config SMTPMailerConfig
+}
+
+var _ port.MailerPort = (*SMTPMailer)(nil)
+
+// NewSMTPMailer สร้าง SMTPMailer
+func NewSMTPMailer(config SMTPMailerConfig) (*SMTPMailer, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("smtp host and from address are required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 15 * time.Second
+	}
+	return &SMTPMailer{config: config}, nil
+}
+
+// Send ส่งอีเมล (ยกเลิกได้ผ่าน ctx)
+func (m *SMTPMailer) Send(ctx context.Context, msg port.MailMessage) error {
+	if msg.To == "" {
+		return errors.New("recipient is required")
+	}
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return errors.New("invalid recipient")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp connect: %w", err)
+	}
+	defer client.Close()
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.config.From); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(m.buildMessage(msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp data close: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial เชื่อมต่อ server และเปิด TLS (implicit TLS บนพอร์ต 465 หรือ STARTTLS)
+func (m *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	tlsConfig := &tls.Config{ServerName: m.config.Host}
+
+	dialer := &net.Dialer{}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if m.config.Port == "465" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if m.config.Port != "465" {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, err
+			}
+		}
+	}
+	return client, nil
+}
+
+// buildMessage สร้างอีเมลแบบ MIME (multipart/alternative เมื่อมี HTMLBody)
+func (m *SMTPMailer) buildMessage(msg port.MailMessage) []byte {
+	var b bytes.Buffer
+
+	from := m.config.From
+	if m.config.FromName != "" {
+		from = mime.QEncoding.Encode("utf-8", m.config.FromName) + " <" + m.config.From + ">"
+	}
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		b.WriteString(normalizeCRLF(msg.TextBody))
+		return b.Bytes()
+	}
+
+	boundary := mimeBoundary()
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(normalizeCRLF(msg.TextBody) + "\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
+	b.WriteString(normalizeCRLF(msg.HTMLBody) + "\r\n")
+	b.WriteString("--" + boundary + "--\r\n")
+	return b.Bytes()
+}
+
+func normalizeCRLF(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+func mimeBoundary() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "boundary-" + hex.EncodeToString(buf)
+}
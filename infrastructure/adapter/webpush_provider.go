@@ -0,0 +1,349 @@
+// infrastructure/adapter/webpush_provider.go
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+const (
+	webPushRecordSize = 4096
+	webPushDefaultTTL = 24 * time.Hour
+	webPushVAPIDTTL   = 12 * time.Hour
+)
+
+// WebPushConfig การตั้งค่า Web Push (VAPID, RFC 8292)
+type WebPushConfig struct {
+	PublicKey  string // base64url ของ public key แบบ uncompressed (65 bytes) ให้ client ใช้เป็น applicationServerKey
+	PrivateKey string // base64url ของ private key (32 bytes)
+	Subject    string // mailto: หรือ https: URL สำหรับให้ push service ติดต่อ
+	Timeout    time.Duration
+}
+
+// WebPushProvider ส่ง push ไปยัง browser ผ่าน push service ของ browser
+// payload เข้ารหัสแบบ aes128gcm ตาม RFC 8291 และยืนยันตัวตนด้วย VAPID
+type WebPushProvider struct {
+	config    WebPushConfig
+	key       *ecdsa.PrivateKey
+	publicKey []byte
+	client    *http.Client
+}
+
+var _ port.PushProvider = (*WebPushProvider)(nil)
+
+// NewWebPushProvider สร้าง WebPushProvider จาก VAPID key pair
+func NewWebPushProvider(config WebPushConfig) (*WebPushProvider, error) {
+	if config.PrivateKey == "" || config.Subject == "" {
+		return nil, errors.New("vapid private key and subject are required")
+	}
+	if !strings.HasPrefix(config.Subject, "mailto:") && !strings.HasPrefix(config.Subject, "https://") {
+		return nil, errors.New("vapid subject must be a mailto: or https: URL")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	raw, err := decodeBase64URL(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+	privateKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+	publicKey := privateKey.PublicKey().Bytes()
+
+	// public key ที่ตั้งไว้ต้องตรงกับ private key ไม่อย่างนั้น subscription ของ client จะใช้ไม่ได้
+	if config.PublicKey != "" {
+		configured, err := decodeBase64URL(config.PublicKey)
+		if err != nil || !bytes.Equal(configured, publicKey) {
+			return nil, errors.New("vapid public key does not match private key")
+		}
+	}
+	config.PublicKey = base64.RawURLEncoding.EncodeToString(publicKey)
+
+	return &WebPushProvider{
+		config: config,
+		key: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(publicKey[1:33]),
+				Y:     new(big.Int).SetBytes(publicKey[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		publicKey: publicKey,
+		client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: publicOnlyTransport(),
+			// push service ไม่ redirect ไม่ตามไปยัง URL อื่น
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// publicOnlyTransport ปฏิเสธการเชื่อมต่อไปยัง IP ภายใน (loopback, private, link-local)
+// ตรวจตอน dial หลัง resolve DNS แล้ว endpoint ที่ชื่อ host ถูกต้องแต่ชี้มาที่ระบบภายในจึงใช้ไม่ได้
+func publicOnlyTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("web push endpoint resolves to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// ส่งตรงไปยัง push service (proxy ภายในจะถูก dialer ปฏิเสธอยู่ดี)
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// sharedAddressSpace 100.64.0.0/10 (carrier-grade NAT, RFC 6598)
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP ตรวจว่าเป็น unicast address ที่ route ได้บน internet
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// GenerateVAPIDKeys สร้าง VAPID key pair ใหม่ (base64url) สำหรับตั้งค่า VAPID_PUBLIC_KEY / VAPID_PRIVATE_KEY
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// Name ชื่อ provider
+func (p *WebPushProvider) Name() string {
+	return models.PushProviderWebPush
+}
+
+// PublicKey VAPID public key (base64url) ที่ client ใช้ตอน subscribe
+func (p *WebPushProvider) PublicKey() string {
+	return p.config.PublicKey
+}
+
+// Send เข้ารหัส payload แล้วส่งไปยัง endpoint ของ subscription
+func (p *WebPushProvider) Send(ctx context.Context, device *models.PushDevice, notification *dto.PushNotification) error {
+	endpoint, err := url.Parse(device.Token)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: invalid web push endpoint", port.ErrPushDeviceGone)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"title": notification.Title,
+		"body":  notification.Body,
+		"data":  notification.Data,
+		"tag":   notification.CollapseKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := encryptWebPushPayload(payload, device.P256dh, device.Auth)
+	if err != nil {
+		return fmt.Errorf("%w: %v", port.ErrPushDeviceGone, err)
+	}
+
+	authorization, err := p.vapidAuthorization(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	ttl := notification.TTL
+	if ttl <= 0 {
+		ttl = webPushDefaultTTL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, device.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "high")
+	if topic := webPushTopic(notification.CollapseKey); topic != "" {
+		// push service เก็บเฉพาะข้อความล่าสุดของแต่ละ Topic ขณะอุปกรณ์ออฟไลน์
+		req.Header.Set("Topic", topic)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return port.ErrPushDeviceGone
+	default:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("web push failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+}
+
+// vapidAuthorization สร้าง header Authorization แบบ vapid (RFC 8292)
+func (p *WebPushProvider) vapidAuthorization(audience string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(webPushVAPIDTTL).Unix(),
+		"sub": p.config.Subject,
+	})
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + p.config.PublicKey, nil
+}
+
+// encryptWebPushPayload เข้ารหัส payload แบบ aes128gcm ด้วย key ของ subscription (RFC 8291)
+func encryptWebPushPayload(plaintext []byte, p256dh, authSecret string) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, errors.New("invalid p256dh key")
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, errors.New("invalid p256dh key")
+	}
+	auth, err := decodeBase64URL(authSecret)
+	if err != nil || len(auth) != 16 {
+		return nil, errors.New("invalid auth secret")
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return encryptWebPushRecord(plaintext, uaPublic, auth, asPrivate, salt)
+}
+
+// encryptWebPushRecord แยกจาก encryptWebPushPayload เพื่อให้กำหนด ephemeral key และ salt ได้
+func encryptWebPushRecord(plaintext []byte, uaPublic *ecdh.PublicKey, auth []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	// record เดียวต้องมีที่ว่างสำหรับ delimiter (1 byte) และ GCM tag (16 bytes)
+	if len(plaintext)+17 > webPushRecordSize {
+		return nil, errors.New("web push payload too large")
+	}
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	keyInfo := "WebPush: info\x00" + string(uaPublic.Bytes()) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// header: salt(16) | rs(4) | idlen(1) | keyid(as_public)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 = delimiter ของ record สุดท้าย
+	record := append(append([]byte{}, plaintext...), 0x02)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// webPushTopic แปลง collapse key ให้อยู่ในรูปแบบที่ header Topic รับได้ (base64url ไม่เกิน 32 ตัวอักษร)
+func webPushTopic(collapseKey string) string {
+	if collapseKey == "" {
+		return ""
+	}
+	topic := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, collapseKey)
+	if topic == collapseKey && len(topic) <= 32 {
+		return topic
+	}
+	sum := sha256.Sum256([]byte(collapseKey))
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:32]
+}
+
+// decodeBase64URL รับทั้งแบบมีและไม่มี padding (browser แต่ละตัวส่งมาไม่เหมือนกัน)
+func decodeBase64URL(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -101,6 +101,26 @@ func (a *WebSocketAdapter) BroadcastMessageReaction(conversationID uuid.UUID, re
 	a.BroadcastToConversation(conversationID, "message.reaction", reaction)
 }
 
+// BroadcastThreadReply ส่งการแจ้งเตือนข้อความตอบกลับใน thread ไปยังผู้ติดตาม thread
+func (a *WebSocketAdapter) BroadcastThreadReply(userIDs []uuid.UUID, reply interface{}) {
+	a.BroadcastToUsers(userIDs, "thread.reply", reply)
+}
+
+// BroadcastPollUpdated ส่งผลโหวตล่าสุดของโพลไปยังสมาชิกในบทสนทนา
+func (a *WebSocketAdapter) BroadcastPollUpdated(conversationID uuid.UUID, poll interface{}) {
+	a.BroadcastToConversation(conversationID, "poll.updated", poll)
+}
+
+// BroadcastJoinRequestCreated ส่งคำขอเข้ากลุ่มใหม่ไปยังแอดมินที่อนุมัติได้
+func (a *WebSocketAdapter) BroadcastJoinRequestCreated(adminIDs []uuid.UUID, request interface{}) {
+	a.BroadcastToUsers(adminIDs, "join_request.created", request)
+}
+
+// BroadcastJoinRequestReviewed ส่งผลการพิจารณาคำขอเข้ากลุ่มไปยังผู้ขอ
+func (a *WebSocketAdapter) BroadcastJoinRequestReviewed(userID uuid.UUID, request interface{}) {
+	a.BroadcastToUser(userID, "join_request.reviewed", request)
+}
+
 // BroadcastConversationCreated ส่งการแจ้งเตือนว่ามีการสร้างบทสนทนาใหม่
 func (a *WebSocketAdapter) BroadcastConversationCreated(userIDs []uuid.UUID, conversation interface{}) error {
 	return a.BroadcastToUsers(userIDs, "conversation.create", conversation)
@@ -380,3 +400,15 @@ func (a *WebSocketAdapter) BroadcastMessageUnpinned(conversationID uuid.UUID, me
 		"unpinned_at":     utils.Now(),
 	})
 }
+
+// =========== Session Management ===========
+
+// DisconnectSession ปิดการเชื่อมต่อ WebSocket ของ session ที่ถูกเพิกถอน
+func (a *WebSocketAdapter) DisconnectSession(userID, sessionID uuid.UUID) {
+	a.hub.DisconnectSession(userID, sessionID)
+}
+
+// IsUserConnected ตรวจว่าผู้ใช้มี WebSocket เชื่อมต่ออยู่บน instance นี้หรือไม่
+func (a *WebSocketAdapter) IsUserConnected(userID uuid.UUID) bool {
+	return a.hub.IsUserConnected(userID)
+}
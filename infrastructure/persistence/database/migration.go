@@ -3,8 +3,11 @@ package database
 
 import (
 	"log"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
 	"gorm.io/gorm"
 )
 
@@ -12,6 +15,9 @@ import (
 func RunMigration(db *gorm.DB) error {
 	log.Println("กำลังทำ Auto Migration...")
 
+	// ตรวจก่อน AutoMigrate เพราะ column จะถูกสร้างด้วยค่า 0 ทั้งหมด
+	needReadWatermarks := !db.Migrator().HasColumn(&models.Message{}, "sequence")
+
 	// ทำการ migrate โมเดลทั้งหมด
 	// การเรียงลำดับมีความสำคัญ - ควรเริ่มจากตารางหลักก่อน แล้วค่อยไปตารางที่มี foreign key
 	err := db.AutoMigrate(
@@ -24,7 +30,12 @@ func RunMigration(db *gorm.DB) error {
 		&models.Sticker{},
 		&models.UserFriendship{},
 		&models.UserStickerSet{},
+		&models.UserSession{},
 		&models.RefreshToken{},
+		&models.VerificationToken{},
+		&models.UserTwoFactor{},
+		&models.UserRecoveryCode{},
+		&models.PushDevice{},
 		&models.TokenBlacklist{},
 		&models.FileUpload{},
 
@@ -43,6 +54,14 @@ func RunMigration(db *gorm.DB) error {
 		&models.Note{},
 		&models.GroupActivity{},
 		&models.PinnedMessage{},
+		&models.MessageReaction{},
+		&models.ThreadSubscription{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.GroupInvite{},
+		&models.GroupJoinRequest{},
+		&models.GroupBan{},
 	)
 
 	if err != nil {
@@ -53,10 +72,84 @@ func RunMigration(db *gorm.DB) error {
 	// เพิ่ม foreign key constraints ที่ไม่ได้ถูกสร้างโดยอัตโนมัติ
 	// ถ้าจำเป็น สามารถเพิ่ม Raw SQL queries ได้ที่นี่
 
+	if err := migrateRefreshTokenHashes(db); err != nil {
+		log.Printf("ย้าย refresh token เป็น hash ล้มเหลว: %v", err)
+		return err
+	}
+
+	if needReadWatermarks {
+		if err := migrateReadWatermarks(db); err != nil {
+			log.Printf("สร้าง read watermark ล้มเหลว: %v", err)
+			return err
+		}
+	}
+
 	log.Println("Auto Migration สำเร็จ")
 	return nil
 }
 
+// migrateRefreshTokenHashes แปลง refresh token เดิมที่เก็บแบบ plain text เป็น SHA-256
+// แล้วลบ column token ทิ้ง (token เดิมแต่ละตัวเป็น family ของตัวเอง)
+// ดู migrations/023_refresh_token_families.sql
+func migrateRefreshTokenHashes(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.RefreshToken{}, "token") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE refresh_tokens
+			SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex')
+			WHERE token_hash IS NULL AND token IS NOT NULL
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE refresh_tokens DROP COLUMN token").Error
+	})
+}
+
+// migrateReadWatermarks กำหนด sequence ให้ข้อความเดิมและคำนวณ read watermark ของสมาชิก
+// จาก last_read_at และ message_reads ที่มีอยู่ ดู migrations/028_read_watermarks.sql
+func migrateReadWatermarks(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			WITH numbered AS (
+				SELECT id, SUM(CASE WHEN sender_id IS NOT NULL THEN 1 ELSE 0 END)
+					OVER (PARTITION BY conversation_id ORDER BY created_at, id) AS seq
+				FROM messages
+				WHERE thread_root_id IS NULL
+			)
+			UPDATE messages m SET sequence = numbered.seq
+			FROM numbered
+			WHERE m.id = numbered.id
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE conversations c SET last_message_seq = COALESCE(
+				(SELECT MAX(m.sequence) FROM messages m WHERE m.conversation_id = c.id), 0)
+		`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			UPDATE conversation_members cm SET last_read_seq = w.seq, last_read_message_id = w.message_id
+			FROM (
+				SELECT DISTINCT ON (cm2.id) cm2.id AS member_id, m.sequence AS seq, m.id AS message_id
+				FROM conversation_members cm2
+				JOIN messages m ON m.conversation_id = cm2.conversation_id AND m.thread_root_id IS NULL AND m.sequence > 0
+				WHERE (cm2.last_read_at IS NOT NULL AND m.created_at <= cm2.last_read_at)
+					OR m.sender_id = cm2.user_id
+					OR EXISTS (SELECT 1 FROM message_reads mr WHERE mr.message_id = m.id AND mr.user_id = cm2.user_id)
+				ORDER BY cm2.id, m.sequence DESC, m.created_at DESC
+			) w
+			WHERE cm.id = w.member_id
+		`).Error
+	})
+}
+
 // CreateIndices สร้าง indices เพื่อเพิ่มประสิทธิภาพในการค้นหา
 func CreateIndices(db *gorm.DB) error {
 	log.Println("กำลังสร้าง indices...")
@@ -78,6 +171,11 @@ func CreateIndices(db *gorm.DB) error {
 		return err
 	}
 
+	// read watermark: หา message ตาม sequence ในการสนทนา
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_messages_conversation_sequence ON messages(conversation_id, sequence)").Error; err != nil {
+		return err
+	}
+
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_conversation_members_user_id ON conversation_members(user_id)").Error; err != nil {
 		return err
 	}
@@ -128,65 +226,172 @@ func CreateIndices(db *gorm.DB) error {
 	return nil
 }
 
-// SetupFullTextSearch ตั้งค่า full-text search สำหรับ messages table
+// SetupFullTextSearch ตั้งค่า full-text search สำหรับ messages และ notes
+// tsvector ถูกสร้างในแอป (pkg/textsearch) เพื่อรองรับภาษาไทย แล้วเก็บใน search_document
+// trigger จะ cast search_document เป็น content_tsvector โดยไม่ผ่าน text parser ของ PostgreSQL
 func SetupFullTextSearch(db *gorm.DB) error {
 	log.Println("กำลังตั้งค่า full-text search...")
 
-	// Step 1: Add content_tsvector column if not exists
-	if err := db.Exec(`
-		ALTER TABLE messages
-		ADD COLUMN IF NOT EXISTS content_tsvector tsvector
-	`).Error; err != nil {
-		return err
+	// table → ชื่อ GIN index เดิม (notes ใช้ชื่อจาก migrations/008_create_notes.sql)
+	indexNames := map[string]string{
+		"messages": "idx_messages_content_tsvector",
+		"notes":    "idx_notes_fulltext",
 	}
 
-	// Step 2: Populate existing data
-	if err := db.Exec(`
-		UPDATE messages
-		SET content_tsvector = to_tsvector('english', COALESCE(content, ''))
-		WHERE content_tsvector IS NULL
-	`).Error; err != nil {
-		return err
+	for _, table := range []string{"messages", "notes"} {
+		// Step 1: Add content_tsvector column if not exists
+		if err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN IF NOT EXISTS content_tsvector tsvector`).Error; err != nil {
+			return err
+		}
+
+		// Step 2: Create GIN index
+		if err := db.Exec(`CREATE INDEX IF NOT EXISTS ` + indexNames[table] + ` ON ` + table + ` USING GIN (content_tsvector)`).Error; err != nil {
+			return err
+		}
+
+		// Step 3: Create trigger function
+		if err := db.Exec(`
+			CREATE OR REPLACE FUNCTION ` + table + `_search_document_update()
+			RETURNS trigger AS $$
+			BEGIN
+			  NEW.content_tsvector := COALESCE(NEW.search_document, '')::tsvector;
+			  RETURN NEW;
+			END;
+			$$ LANGUAGE plpgsql
+		`).Error; err != nil {
+			return err
+		}
+
+		// Step 4: Replace english triggers with search_document trigger
+		for _, trigger := range []string{"tsvector_update", "tsvectorupdate", "search_document_update"} {
+			if err := db.Exec(`DROP TRIGGER IF EXISTS ` + trigger + ` ON ` + table).Error; err != nil {
+				return err
+			}
+		}
+		if err := db.Exec(`
+			CREATE TRIGGER search_document_update
+			BEFORE INSERT OR UPDATE OF search_document ON ` + table + `
+			FOR EACH ROW
+			EXECUTE FUNCTION ` + table + `_search_document_update()
+		`).Error; err != nil {
+			return err
+		}
 	}
 
-	// Step 3: Create GIN index
-	if err := db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_messages_content_tsvector
-		ON messages USING GIN (content_tsvector)
-	`).Error; err != nil {
+	// Step 5: สร้าง search_document ให้ข้อมูลเดิม
+	if err := backfillMessageSearchDocuments(db); err != nil {
 		return err
 	}
-
-	// Step 4: Create trigger function
-	if err := db.Exec(`
-		CREATE OR REPLACE FUNCTION messages_content_tsvector_update()
-		RETURNS trigger AS $$
-		BEGIN
-		  NEW.content_tsvector := to_tsvector('english', COALESCE(NEW.content, ''));
-		  RETURN NEW;
-		END;
-		$$ LANGUAGE plpgsql
-	`).Error; err != nil {
+	if err := backfillNoteSearchDocuments(db); err != nil {
 		return err
 	}
 
-	// Step 5: Create trigger
-	if err := db.Exec(`DROP TRIGGER IF EXISTS tsvector_update ON messages`).Error; err != nil {
-		return err
+	log.Println("ตั้งค่า full-text search สำเร็จ")
+	return nil
+}
+
+// ขนาด batch สำหรับ backfill search_document
+const searchBackfillBatchSize = 500
+
+// backfillMessageSearchDocuments สร้าง search_document ให้ข้อความที่ยังไม่มี
+func backfillMessageSearchDocuments(db *gorm.DB) error {
+	type row struct {
+		ID      uuid.UUID
+		Content string
 	}
-	if err := db.Exec(`
-		CREATE TRIGGER tsvector_update
-		BEFORE INSERT OR UPDATE OF content ON messages
-		FOR EACH ROW
-		EXECUTE FUNCTION messages_content_tsvector_update()
-	`).Error; err != nil {
-		return err
+
+	total := 0
+	for {
+		var rows []row
+		if err := db.Raw(`
+			SELECT id, COALESCE(content, '') AS content FROM messages
+			WHERE search_document IS NULL
+			LIMIT ?`, searchBackfillBatchSize).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(rows))
+		docs := make([]string, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+			docs[i] = textsearch.Document(textsearch.Field{Text: r.Content})
+		}
+		if err := updateSearchDocuments(db, "messages", ids, docs); err != nil {
+			return err
+		}
+		total += len(rows)
 	}
 
-	log.Println("ตั้งค่า full-text search สำเร็จ")
+	if total > 0 {
+		log.Printf("สร้าง search_document ให้ข้อความเดิม %d รายการ", total)
+	}
 	return nil
 }
 
+// backfillNoteSearchDocuments สร้าง search_document ให้บันทึกที่ยังไม่มี
+func backfillNoteSearchDocuments(db *gorm.DB) error {
+	type row struct {
+		ID      uuid.UUID
+		Title   string
+		Content string
+	}
+
+	total := 0
+	for {
+		var rows []row
+		if err := db.Raw(`
+			SELECT id, COALESCE(title, '') AS title, COALESCE(content, '') AS content FROM notes
+			WHERE search_document IS NULL
+			LIMIT ?`, searchBackfillBatchSize).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(rows))
+		docs := make([]string, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+			docs[i] = textsearch.Document(
+				textsearch.Field{Text: r.Title, Weight: textsearch.WeightA},
+				textsearch.Field{Text: r.Content, Weight: textsearch.WeightB},
+			)
+		}
+		if err := updateSearchDocuments(db, "notes", ids, docs); err != nil {
+			return err
+		}
+		total += len(rows)
+	}
+
+	if total > 0 {
+		log.Printf("สร้าง search_document ให้บันทึกเดิม %d รายการ", total)
+	}
+	return nil
+}
+
+// updateSearchDocuments เขียน search_document ของทั้ง batch ด้วย UPDATE ... FROM (VALUES ...) ครั้งเดียว
+func updateSearchDocuments(db *gorm.DB, table string, ids []uuid.UUID, docs []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)*2)
+	for i := range ids {
+		values[i] = "(?::uuid, ?)"
+		args = append(args, ids[i], docs[i])
+	}
+
+	return db.Exec(`
+		UPDATE `+table+` AS t SET search_document = v.doc
+		FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, doc)
+		WHERE t.id = v.id`, args...).Error
+}
+
 // SetupDatabase ตั้งค่าฐานข้อมูลทั้งหมด
 func SetupDatabase(db *gorm.DB) error {
 	// ทำ migration
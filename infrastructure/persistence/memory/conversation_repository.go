@@ -0,0 +1,697 @@
+// infrastructure/persistence/memory/conversation_repository.go
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+	"gorm.io/gorm"
+)
+
+type conversationRepository struct {
+	store *Store
+}
+
+// NewConversationRepository สร้าง ConversationRepository ที่เก็บข้อมูลใน Store
+func NewConversationRepository(store *Store) repository.ConversationRepository {
+	return &conversationRepository{store: store}
+}
+
+func (r *conversationRepository) GetByID(id uuid.UUID) (*models.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conv, ok := r.store.conversations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyConversation(conv), nil
+}
+
+func (r *conversationRepository) Create(conversation *models.Conversation) error {
+	if conversation.ID == uuid.Nil {
+		conversation.ID = uuid.New()
+	}
+	now := time.Now()
+	if conversation.CreatedAt.IsZero() {
+		conversation.CreatedAt = now
+	}
+	if conversation.UpdatedAt.IsZero() {
+		conversation.UpdatedAt = now
+	}
+	// is_active มี default:true ค่า false (zero value) จึงถูกแทนด้วย default เช่นเดียวกับ GORM
+	conversation.IsActive = true
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.conversations[conversation.ID]; ok {
+		return errors.New("duplicate key value violates unique constraint \"conversations_pkey\"")
+	}
+	r.store.conversations[conversation.ID] = copyConversation(conversation)
+
+	// GORM สร้าง association Members ไปพร้อมกัน
+	for _, member := range conversation.Members {
+		member.ConversationID = conversation.ID
+		r.store.addMemberLocked(member)
+	}
+	return nil
+}
+
+// UpdateConversation อัปเดตเฉพาะ column ที่ระบุ (key เป็นชื่อ column)
+func (r *conversationRepository) UpdateConversation(id uuid.UUID, updateData types.JSONB) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	conv, ok := r.store.conversations[id]
+	if !ok {
+		return nil
+	}
+
+	updated := copyConversation(conv)
+	for column, value := range updateData {
+		if err := setConversationColumn(updated, column, value); err != nil {
+			return err
+		}
+	}
+	r.store.conversations[id] = updated
+	return nil
+}
+
+func (r *conversationRepository) Update(conversation *models.Conversation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c := copyConversation(conversation)
+	if existing, ok := r.store.conversations[conversation.ID]; ok {
+		c.LastMessageSeq = existing.LastMessageSeq
+	}
+	r.store.conversations[conversation.ID] = c
+	return nil
+}
+
+// Delete เปลี่ยนสถานะเป็น inactive
+func (r *conversationRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	conv, ok := r.store.conversations[id]
+	if !ok {
+		return errors.New("conversation not found")
+	}
+	conv.IsActive = false
+	return nil
+}
+
+func (r *conversationRepository) FindDirectConversation(user1ID, user2ID uuid.UUID) (*models.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, conv := range r.store.sortedConversationsLocked() {
+		if conv.Type != "direct" {
+			continue
+		}
+		members := r.store.membersOfLocked(conv.ID)
+		if len(members) != 2 {
+			continue
+		}
+		has1, has2 := false, false
+		for _, m := range members {
+			has1 = has1 || m.UserID == user1ID
+			has2 = has2 || m.UserID == user2ID
+		}
+		if has1 && has2 {
+			return copyConversation(conv), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *conversationRepository) GetUserConversations(userID uuid.UUID, limit, offset int) ([]*models.Conversation, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conversations := r.store.userConversationsLocked(userID, false, func(*models.Conversation) bool { return true })
+	sortByActivityDesc(conversations)
+
+	return copyConversations(paginate(conversations, limit, offset)), len(conversations), nil
+}
+
+func (r *conversationRepository) AddMember(member *models.ConversationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.members[memberKey{member.ConversationID, member.UserID}]; ok {
+		return errors.New("duplicate key value violates unique constraint \"conversation_members_pkey\"")
+	}
+	r.store.addMemberLocked(member)
+	return nil
+}
+
+func (r *conversationRepository) GetMember(conversationID, userID uuid.UUID) (*models.ConversationMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyMember(m), nil
+}
+
+func (r *conversationRepository) GetMembers(conversationID uuid.UUID) ([]*models.ConversationMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := r.store.membersOfLocked(conversationID)
+	result := make([]*models.ConversationMember, len(members))
+	for i, m := range members {
+		result[i] = copyMember(m)
+	}
+	return result, nil
+}
+
+// UpdateMember บันทึกข้อมูลสมาชิกทั้งหมด (เหมือน Save)
+func (r *conversationRepository) UpdateMember(member *models.ConversationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := memberKey{member.ConversationID, member.UserID}
+	c := copyMember(member)
+	if existing, ok := r.store.members[key]; ok {
+		c.LastReadMessageID = existing.LastReadMessageID
+		c.LastReadSeq = existing.LastReadSeq
+		c.DeletedUnread = existing.DeletedUnread
+		c.LastSentAt = existing.LastSentAt
+	}
+	r.store.members[key] = c
+	return nil
+}
+
+func (r *conversationRepository) RemoveMember(conversationID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := memberKey{conversationID, userID}
+	if _, ok := r.store.members[key]; !ok {
+		return errors.New("conversation member not found")
+	}
+	delete(r.store.members, key)
+	return nil
+}
+
+// LeaveGroup ลบสมาชิก โอน owner และปิดกลุ่มภายใต้ lock เดียว
+func (r *conversationRepository) LeaveGroup(conversationID, userID uuid.UUID, successor func(remaining []*models.ConversationMember) *models.ConversationMember) (*uuid.UUID, bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	conv, ok := r.store.conversations[conversationID]
+	if !ok {
+		return nil, false, errors.New("conversation not found")
+	}
+	leaving, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return nil, false, errors.New("conversation member not found")
+	}
+
+	remaining := make([]*models.ConversationMember, 0)
+	for key, m := range r.store.members {
+		if key.conversationID == conversationID && key.userID != userID {
+			remaining = append(remaining, copyMember(m))
+		}
+	}
+
+	var newOwnerID *uuid.UUID
+	if leaving.Role == models.RoleOwner && len(remaining) > 0 {
+		next := r.store.members[memberKey{conversationID, successor(remaining).UserID}]
+		next.Role = models.RoleOwner
+		next.IsAdmin = true
+		id := next.UserID
+		conv.CreatorID = &id
+		newOwnerID = &id
+	}
+
+	delete(r.store.members, memberKey{conversationID, userID})
+
+	deactivated := len(remaining) == 0
+	if deactivated {
+		conv.IsActive = false
+		conv.UpdatedAt = time.Now()
+	}
+	return newOwnerID, deactivated, nil
+}
+
+func (r *conversationRepository) UpdateMemberAdmin(conversationID, userID uuid.UUID, isAdmin bool) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.IsAdmin = isAdmin
+	})
+}
+
+func (r *conversationRepository) UpdateLastMessage(conversationID uuid.UUID, messageID uuid.UUID, text string, messageTime time.Time) error {
+	r.store.setLastMessage(conversationID, messageID, text, messageTime)
+	return nil
+}
+
+func (r *conversationRepository) SetPinStatus(conversationID, userID uuid.UUID, isPinned bool) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.IsPinned = isPinned
+	})
+}
+
+func (r *conversationRepository) SetMuteStatus(conversationID, userID uuid.UUID, isMuted bool) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.IsMuted = isMuted
+	})
+}
+
+func (r *conversationRepository) SetSendRestriction(conversationID, userID uuid.UUID, until *time.Time) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.SendRestrictedUntil = nil
+		if until != nil {
+			restrictedUntil := *until
+			m.SendRestrictedUntil = &restrictedUntil
+		}
+	})
+}
+
+func (r *conversationRepository) ClaimSlowModeSlot(conversationID, userID uuid.UUID, now time.Time, interval time.Duration) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	m, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return false, nil
+	}
+	if !slowModeSlotFree(m, now, interval) {
+		return false, nil
+	}
+	sentAt := now
+	m.LastSentAt = &sentAt
+	return true, nil
+}
+
+func (r *conversationRepository) UpdateNotificationSettings(conversationID, userID uuid.UUID, settings types.JSONB) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.NotificationSettings = cloneJSONB(settings)
+	})
+}
+
+func (r *conversationRepository) SetHiddenStatus(conversationID, userID uuid.UUID, isHidden bool) error {
+	return r.updateMember(conversationID, userID, func(m *models.ConversationMember) {
+		m.IsHidden = isHidden
+		m.HiddenAt = nil
+		if isHidden {
+			now := time.Now()
+			m.HiddenAt = &now
+		}
+	})
+}
+
+func (r *conversationRepository) IsHidden(conversationID, userID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return false, errors.New("conversation member not found")
+	}
+	return m.IsHidden, nil
+}
+
+// MarkAllMessagesAsRead อัปเดต last_read_at ของสมาชิกเป็นเวลาปัจจุบัน
+func (r *conversationRepository) MarkAllMessagesAsRead(conversationID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.members[memberKey{conversationID, userID}]; ok {
+		now := time.Now()
+		m.LastReadAt = &now
+	}
+	return nil
+}
+
+// UpdateMemberLastRead อัปเดต last_read_at (สร้างสมาชิกใหม่ถ้ายังไม่มี เช่นเดียวกับ postgres)
+func (r *conversationRepository) UpdateMemberLastRead(conversationID uuid.UUID, userID uuid.UUID, readTime time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.members[memberKey{conversationID, userID}]; ok {
+		m.LastReadAt = &readTime
+		return nil
+	}
+
+	r.store.addMemberLocked(&models.ConversationMember{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		UserID:         userID,
+		LastReadAt:     &readTime,
+		JoinedAt:       time.Now(),
+	})
+	return nil
+}
+
+func (r *conversationRepository) GetUserMemberships(userID uuid.UUID) ([]*models.ConversationMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	memberships := make([]*models.ConversationMember, 0)
+	for key, m := range r.store.members {
+		if key.userID == userID {
+			memberships = append(memberships, copyMember(m))
+		}
+	}
+	sortMembers(memberships)
+	return memberships, nil
+}
+
+func (r *conversationRepository) GetConversationsByIDs(ids []uuid.UUID) ([]*models.Conversation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conversations := make([]*models.Conversation, 0, len(ids))
+	for _, id := range ids {
+		if conv, ok := r.store.conversations[id]; ok {
+			conversations = append(conversations, copyConversation(conv))
+		}
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) IsMember(conversationID, userID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.members[memberKey{conversationID, userID}]
+	return ok, nil
+}
+
+// GetLastMessage ดึงข้อความล่าสุด (รวมข้อความใน thread เช่นเดียวกับ postgres)
+func (r *conversationRepository) GetLastMessage(conversationID uuid.UUID) (*models.Message, error) {
+	return r.store.lastMessage(conversationID, func(*models.Message) bool { return true }), nil
+}
+
+func (r *conversationRepository) GetLastNonDeletedMessage(conversationID uuid.UUID) (*models.Message, error) {
+	return r.store.lastMessage(conversationID, func(m *models.Message) bool { return !m.IsDeleted }), nil
+}
+
+func (r *conversationRepository) GetUserConversationsWithFilter(userID uuid.UUID, limit, offset int, convType string, pinned bool) ([]*models.Conversation, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conversations := r.store.userConversationsLocked(userID, true, r.store.typeAndPinFilterLocked(userID, convType, pinned))
+	sortByActivityDesc(conversations)
+
+	// ถ้าไม่ได้กรองเฉพาะที่ปักหมุด ให้การสนทนาที่ปักหมุดขึ้นก่อน
+	if !pinned {
+		sort.SliceStable(conversations, func(i, j int) bool {
+			pi := r.store.members[memberKey{conversations[i].ID, userID}].IsPinned
+			pj := r.store.members[memberKey{conversations[j].ID, userID}].IsPinned
+			return pi && !pj
+		})
+	}
+
+	return copyConversations(paginate(conversations, limit, offset)), len(conversations), nil
+}
+
+func (r *conversationRepository) GetConversationsBeforeTime(userID uuid.UUID, beforeTime time.Time, limit int, convType string, pinned bool) ([]*models.Conversation, int, error) {
+	return r.cursorPage(userID, limit, convType, pinned, false, func(c *models.Conversation) bool {
+		return activityTime(c).Before(beforeTime)
+	})
+}
+
+func (r *conversationRepository) GetConversationsAfterTime(userID uuid.UUID, afterTime time.Time, limit int, convType string, pinned bool) ([]*models.Conversation, int, error) {
+	return r.cursorPage(userID, limit, convType, pinned, true, func(c *models.Conversation) bool {
+		return activityTime(c).After(afterTime)
+	})
+}
+
+func (r *conversationRepository) GetConversationsBeforeID(userID, beforeID uuid.UUID, limit int, convType string, pinned bool) ([]*models.Conversation, int, error) {
+	target, err := r.GetByID(beforeID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching target conversation: %w", err)
+	}
+	at := activityTime(target)
+	return r.cursorPage(userID, limit, convType, pinned, false, func(c *models.Conversation) bool {
+		t := activityTime(c)
+		return t.Before(at) || (t.Equal(at) && c.ID.String() < beforeID.String())
+	})
+}
+
+func (r *conversationRepository) GetConversationsAfterID(userID, afterID uuid.UUID, limit int, convType string, pinned bool) ([]*models.Conversation, int, error) {
+	target, err := r.GetByID(afterID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching target conversation: %w", err)
+	}
+	at := activityTime(target)
+	return r.cursorPage(userID, limit, convType, pinned, true, func(c *models.Conversation) bool {
+		t := activityTime(c)
+		return t.After(at) || (t.Equal(at) && c.ID.String() > afterID.String())
+	})
+}
+
+func (r *conversationRepository) UnhideForAllMembers(conversationID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for key, m := range r.store.members {
+		if key.conversationID == conversationID && m.IsHidden {
+			m.IsHidden = false
+			m.HiddenAt = nil
+		}
+	}
+	return nil
+}
+
+// cursorPage ดึงการสนทนาก่อน/หลัง cursor ผลลัพธ์เรียงจากใหม่ไปเก่าเสมอ
+// กรณี after จะเลือก limit รายการที่ใกล้ cursor ที่สุดก่อนกลับลำดับ (เหมือน postgres)
+func (r *conversationRepository) cursorPage(userID uuid.UUID, limit int, convType string, pinned, after bool, match func(*models.Conversation) bool) ([]*models.Conversation, int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	filter := r.store.typeAndPinFilterLocked(userID, convType, pinned)
+	conversations := r.store.userConversationsLocked(userID, true, func(c *models.Conversation) bool {
+		return filter(c) && match(c)
+	})
+
+	if after {
+		sortByActivityDesc(conversations)
+		for i, j := 0, len(conversations)-1; i < j; i, j = i+1, j-1 {
+			conversations[i], conversations[j] = conversations[j], conversations[i]
+		}
+		page := paginate(conversations, limit, 0)
+		for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+			page[i], page[j] = page[j], page[i]
+		}
+		return copyConversations(page), len(conversations), nil
+	}
+
+	sortByActivityDesc(conversations)
+	return copyConversations(paginate(conversations, limit, 0)), len(conversations), nil
+}
+
+// updateMember แก้ไขสมาชิก คืน error เมื่อไม่พบ (RowsAffected == 0)
+func (r *conversationRepository) updateMember(conversationID, userID uuid.UUID, apply func(*models.ConversationMember)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	m, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return errors.New("conversation member not found")
+	}
+	apply(m)
+	return nil
+}
+
+// ============ Store helpers (ต้องถือ lock ก่อนเรียก *Locked) ============
+
+func (s *Store) addMemberLocked(member *models.ConversationMember) {
+	if member.ID == uuid.Nil {
+		member.ID = uuid.New()
+	}
+	if member.JoinedAt.IsZero() {
+		member.JoinedAt = time.Now()
+	}
+	if member.Role == "" {
+		member.Role = models.RoleMember
+	}
+	s.members[memberKey{member.ConversationID, member.UserID}] = copyMember(member)
+}
+
+// slowModeSlotFree ข้อความล่าสุดของสมาชิกพ้นช่วง slow mode แล้ว
+func slowModeSlotFree(m *models.ConversationMember, now time.Time, interval time.Duration) bool {
+	return m.LastSentAt == nil || !m.LastSentAt.After(now.Add(-interval))
+}
+
+// membersOfLocked คืนสมาชิกของการสนทนาเรียงตามเวลาที่เข้าร่วม
+func (s *Store) membersOfLocked(conversationID uuid.UUID) []*models.ConversationMember {
+	members := make([]*models.ConversationMember, 0)
+	for key, m := range s.members {
+		if key.conversationID == conversationID {
+			members = append(members, m)
+		}
+	}
+	sortMembers(members)
+	return members
+}
+
+func (s *Store) sortedConversationsLocked() []*models.Conversation {
+	conversations := make([]*models.Conversation, 0, len(s.conversations))
+	for _, c := range s.conversations {
+		conversations = append(conversations, c)
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.Before(conversations[j].CreatedAt)
+	})
+	return conversations
+}
+
+// userConversationsLocked การสนทนาที่ active ซึ่งผู้ใช้เป็นสมาชิก
+func (s *Store) userConversationsLocked(userID uuid.UUID, excludeHidden bool, match func(*models.Conversation) bool) []*models.Conversation {
+	conversations := make([]*models.Conversation, 0)
+	for key, m := range s.members {
+		if key.userID != userID || (excludeHidden && m.IsHidden) {
+			continue
+		}
+		conv, ok := s.conversations[key.conversationID]
+		if !ok || !conv.IsActive || !match(conv) {
+			continue
+		}
+		conversations = append(conversations, conv)
+	}
+	return conversations
+}
+
+func (s *Store) typeAndPinFilterLocked(userID uuid.UUID, convType string, pinned bool) func(*models.Conversation) bool {
+	return func(c *models.Conversation) bool {
+		if convType != "" && c.Type != convType {
+			return false
+		}
+		if pinned && !s.members[memberKey{c.ID, userID}].IsPinned {
+			return false
+		}
+		return true
+	}
+}
+
+func (s *Store) setLastMessage(conversationID, messageID uuid.UUID, text string, messageTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.conversations[conversationID]
+	if !ok {
+		return
+	}
+	id := messageID
+	at := messageTime
+	conv.LastMessageID = &id
+	conv.LastMessageText = text
+	conv.LastMessageAt = &at
+	conv.UpdatedAt = time.Now()
+}
+
+// setConversationColumn ตั้งค่า column ของการสนทนาตามชื่อ column ใน database
+func setConversationColumn(c *models.Conversation, column string, value interface{}) error {
+	switch column {
+	case "title":
+		v, _ := value.(string)
+		c.Title = v
+	case "icon_url":
+		v, _ := value.(string)
+		c.IconURL = v
+	case "type":
+		v, _ := value.(string)
+		c.Type = v
+	case "is_active":
+		v, _ := value.(bool)
+		c.IsActive = v
+	case "message_ttl_seconds":
+		v, ok := toInt(value)
+		if !ok {
+			return fmt.Errorf("memory: invalid message_ttl_seconds value %T", value)
+		}
+		c.MessageTTLSeconds = v
+	case "slow_mode_seconds":
+		v, ok := toInt(value)
+		if !ok {
+			return fmt.Errorf("memory: invalid slow_mode_seconds value %T", value)
+		}
+		c.SlowModeSeconds = v
+	case "metadata":
+		v, err := toJSONB(value)
+		if err != nil {
+			return err
+		}
+		c.Metadata = v
+	case "permissions":
+		v, err := toJSONB(value)
+		if err != nil {
+			return err
+		}
+		c.Permissions = v
+	case "last_message_text":
+		v, _ := value.(string)
+		c.LastMessageText = v
+	case "last_message_at":
+		v, err := toTimePtr(value)
+		if err != nil {
+			return err
+		}
+		c.LastMessageAt = v
+	case "last_message_id":
+		v, err := toUUIDPtr(value)
+		if err != nil {
+			return err
+		}
+		c.LastMessageID = v
+	case "updated_at":
+		v, err := toTimePtr(value)
+		if err != nil || v == nil {
+			return fmt.Errorf("memory: invalid updated_at value %T", value)
+		}
+		c.UpdatedAt = *v
+	default:
+		return fmt.Errorf("memory: unsupported conversation column %q", column)
+	}
+	return nil
+}
+
+// activityTime เทียบเท่า COALESCE(last_message_at, updated_at)
+func activityTime(c *models.Conversation) time.Time {
+	if c.LastMessageAt != nil {
+		return *c.LastMessageAt
+	}
+	return c.UpdatedAt
+}
+
+func sortByActivityDesc(conversations []*models.Conversation) {
+	sort.SliceStable(conversations, func(i, j int) bool {
+		ti, tj := activityTime(conversations[i]), activityTime(conversations[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return conversations[i].ID.String() > conversations[j].ID.String()
+	})
+}
+
+func sortMembers(members []*models.ConversationMember) {
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].ID.String() < members[j].ID.String()
+	})
+}
+
+func copyConversations(conversations []*models.Conversation) []*models.Conversation {
+	result := make([]*models.Conversation, len(conversations))
+	for i, c := range conversations {
+		result[i] = copyConversation(c)
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+// infrastructure/persistence/memory/file_upload_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type fileUploadRepository struct {
+	store *Store
+}
+
+// NewFileUploadRepository สร้าง FileUploadRepository ที่เก็บข้อมูลใน Store
+func NewFileUploadRepository(store *Store) repository.FileUploadRepository {
+	return &fileUploadRepository{store: store}
+}
+
+func (r *fileUploadRepository) Create(upload *models.FileUpload) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if upload.ID == uuid.Nil {
+		upload.ID = uuid.New()
+	}
+	if upload.CreatedAt.IsZero() {
+		upload.CreatedAt = time.Now()
+	}
+	r.store.fileUploads[upload.ID] = copyFileUpload(upload)
+	return nil
+}
+
+func (r *fileUploadRepository) FindByID(id uuid.UUID) (*models.FileUpload, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, ok := r.store.fileUploads[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyFileUpload(u), nil
+}
+
+func (r *fileUploadRepository) FindByURL(url string) (*models.FileUpload, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found *models.FileUpload
+	for _, u := range r.store.fileUploads {
+		if u.URL != url || u.Status != models.FileUploadStatusCompleted {
+			continue
+		}
+		if found == nil || completedAfter(u, found) {
+			found = u
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyFileUpload(found), nil
+}
+
+func (r *fileUploadRepository) FindByUserID(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var uploads []*models.FileUpload
+	for _, u := range r.store.fileUploads {
+		if u.UserID == userID {
+			uploads = append(uploads, copyFileUpload(u))
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return uploads[i].CreatedAt.After(uploads[j].CreatedAt)
+	})
+	return paginate(uploads, limit, offset), nil
+}
+
+func (r *fileUploadRepository) Update(upload *models.FileUpload) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.fileUploads[upload.ID] = copyFileUpload(upload)
+	return nil
+}
+
+func (r *fileUploadRepository) UpdateStatus(id uuid.UUID, status models.FileUploadStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if u, ok := r.store.fileUploads[id]; ok {
+		u.Status = status
+	}
+	return nil
+}
+
+func (r *fileUploadRepository) MarkAsCompleted(id uuid.UUID, url string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if u, ok := r.store.fileUploads[id]; ok {
+		now := time.Now()
+		u.Status = models.FileUploadStatusCompleted
+		u.URL = url
+		u.CompletedAt = &now
+	}
+	return nil
+}
+
+func (r *fileUploadRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.fileUploads, id)
+	return nil
+}
+
+func (r *fileUploadRepository) FindPendingOlderThan(cutoff time.Time) ([]*models.FileUpload, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var uploads []*models.FileUpload
+	for _, u := range r.store.fileUploads {
+		if u.Status == models.FileUploadStatusPending && u.CreatedAt.Before(cutoff) {
+			uploads = append(uploads, copyFileUpload(u))
+		}
+	}
+	return uploads, nil
+}
+
+func (r *fileUploadRepository) CountByUserID(userID uuid.UUID, since time.Time) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, u := range r.store.fileUploads {
+		if u.UserID == userID && u.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// completedAfter a ถูกยืนยันหลัง b หรือไม่ (nil ถือว่าเก่าสุด)
+func completedAfter(a, b *models.FileUpload) bool {
+	if a.CompletedAt == nil {
+		return false
+	}
+	return b.CompletedAt == nil || a.CompletedAt.After(*b.CompletedAt)
+}
+
+func copyFileUpload(u *models.FileUpload) *models.FileUpload {
+	c := *u
+	if u.CompletedAt != nil {
+		completedAt := *u.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	c.User = nil
+	return &c
+}
@@ -0,0 +1,94 @@
+// infrastructure/persistence/memory/group_ban_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type groupBanRepository struct {
+	store *Store
+}
+
+// NewGroupBanRepository สร้าง GroupBanRepository ที่เก็บข้อมูลใน Store
+func NewGroupBanRepository(store *Store) repository.GroupBanRepository {
+	return &groupBanRepository{store: store}
+}
+
+func (r *groupBanRepository) Upsert(ban *models.GroupBan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// แถวเดิมของผู้ใช้ในกลุ่ม: แทนที่ (เทียบเท่า ON CONFLICT (conversation_id, user_id))
+	key := memberKey{ban.ConversationID, ban.UserID}
+	if existing, ok := r.store.groupBans[key]; ok {
+		ban.ID = existing.ID
+	}
+	if ban.ID == uuid.Nil {
+		ban.ID = uuid.New()
+	}
+	if ban.CreatedAt.IsZero() {
+		ban.CreatedAt = time.Now()
+	}
+	r.store.groupBans[key] = copyGroupBan(ban)
+	return nil
+}
+
+func (r *groupBanRepository) FindActive(conversationID, userID uuid.UUID, now time.Time) (*models.GroupBan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ban, ok := r.store.groupBans[memberKey{conversationID, userID}]
+	if !ok || !ban.IsActive(now) {
+		return nil, nil
+	}
+	return copyGroupBan(ban), nil
+}
+
+func (r *groupBanRepository) ListActive(conversationID uuid.UUID, now time.Time) ([]*models.GroupBan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var bans []*models.GroupBan
+	for key, ban := range r.store.groupBans {
+		if key.conversationID != conversationID || !ban.IsActive(now) {
+			continue
+		}
+		c := copyGroupBan(ban)
+		if u, ok := r.store.users[c.UserID]; ok {
+			c.User = copyUser(u)
+		}
+		bans = append(bans, c)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].CreatedAt.After(bans[j].CreatedAt)
+	})
+	return bans, nil
+}
+
+func (r *groupBanRepository) Delete(conversationID, userID uuid.UUID, now time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := memberKey{conversationID, userID}
+	ban, ok := r.store.groupBans[key]
+	if !ok || !ban.IsActive(now) {
+		return false, nil
+	}
+	delete(r.store.groupBans, key)
+	return true, nil
+}
+
+func copyGroupBan(b *models.GroupBan) *models.GroupBan {
+	c := *b
+	if b.ExpiresAt != nil {
+		expiresAt := *b.ExpiresAt
+		c.ExpiresAt = &expiresAt
+	}
+	c.User = nil
+	return &c
+}
@@ -0,0 +1,275 @@
+// infrastructure/persistence/memory/group_invite_repository.go
+package memory
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type groupInviteRepository struct {
+	store *Store
+}
+
+// NewGroupInviteRepository สร้าง GroupInviteRepository ที่เก็บข้อมูลใน Store
+func NewGroupInviteRepository(store *Store) repository.GroupInviteRepository {
+	return &groupInviteRepository{store: store}
+}
+
+func (r *groupInviteRepository) CreateInvite(invite *models.GroupInvite) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// token ซ้ำ (เทียบเท่า unique index)
+	for _, existing := range r.store.groupInvites {
+		if existing.Token == invite.Token {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	if invite.ID == uuid.Nil {
+		invite.ID = uuid.New()
+	}
+	if invite.CreatedAt.IsZero() {
+		invite.CreatedAt = time.Now()
+	}
+	r.store.groupInvites[invite.ID] = copyGroupInvite(invite)
+	return nil
+}
+
+func (r *groupInviteRepository) FindInviteByToken(token string) (*models.GroupInvite, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, invite := range r.store.groupInvites {
+		if invite.Token == token {
+			return copyGroupInvite(invite), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *groupInviteRepository) FindInviteByID(id uuid.UUID) (*models.GroupInvite, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	invite, ok := r.store.groupInvites[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyGroupInvite(invite), nil
+}
+
+func (r *groupInviteRepository) ListInvites(conversationID uuid.UUID) ([]*models.GroupInvite, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var invites []*models.GroupInvite
+	for _, invite := range r.store.groupInvites {
+		if invite.ConversationID == conversationID {
+			invites = append(invites, copyGroupInvite(invite))
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
+
+func (r *groupInviteRepository) RevokeInvite(id, revokedBy uuid.UUID, at time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invite, ok := r.store.groupInvites[id]
+	if !ok || invite.RevokedAt != nil {
+		return false, nil
+	}
+	revokedAt := at
+	invite.RevokedAt = &revokedAt
+	invite.RevokedBy = &revokedBy
+	return true, nil
+}
+
+func (r *groupInviteRepository) JoinWithInvite(inviteID uuid.UUID, member *models.ConversationMember, now time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invite, ok := r.store.groupInvites[inviteID]
+	if !ok || !invite.IsUsable(now) {
+		return false, nil
+	}
+	if _, ok := r.store.members[memberKey{member.ConversationID, member.UserID}]; ok {
+		return false, errors.New("duplicate key value violates unique constraint \"conversation_members_pkey\"")
+	}
+	invite.UseCount++
+	r.store.addMemberLocked(member)
+	return true, nil
+}
+
+func (r *groupInviteRepository) CreateJoinRequest(request *models.GroupJoinRequest) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if request.Status == "" {
+		request.Status = models.JoinRequestStatusPending
+	}
+
+	// คำขอ pending ซ้ำ (เทียบเท่า partial unique index)
+	if request.Status == models.JoinRequestStatusPending {
+		for _, existing := range r.store.joinRequests {
+			if existing.ConversationID == request.ConversationID &&
+				existing.UserID == request.UserID &&
+				existing.Status == models.JoinRequestStatusPending {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+	}
+
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	r.store.joinRequests[request.ID] = copyJoinRequest(request)
+	return nil
+}
+
+func (r *groupInviteRepository) FindJoinRequestByID(id uuid.UUID) (*models.GroupJoinRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	request, ok := r.store.joinRequests[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyJoinRequest(request), nil
+}
+
+func (r *groupInviteRepository) FindPendingJoinRequest(conversationID, userID uuid.UUID) (*models.GroupJoinRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, request := range r.store.joinRequests {
+		if request.ConversationID == conversationID &&
+			request.UserID == userID &&
+			request.Status == models.JoinRequestStatusPending {
+			return copyJoinRequest(request), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *groupInviteRepository) ListJoinRequests(conversationID uuid.UUID, status string) ([]*models.GroupJoinRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var requests []*models.GroupJoinRequest
+	for _, request := range r.store.joinRequests {
+		if request.ConversationID != conversationID {
+			continue
+		}
+		if status != "" && request.Status != status {
+			continue
+		}
+		c := copyJoinRequest(request)
+		if u, ok := r.store.users[c.UserID]; ok {
+			c.User = copyUser(u)
+		}
+		requests = append(requests, c)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (r *groupInviteRepository) ReviewJoinRequest(id uuid.UUID, status string, reviewedBy uuid.UUID, at time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	request, ok := r.store.joinRequests[id]
+	if !ok || request.Status != models.JoinRequestStatusPending {
+		return false, nil
+	}
+	reviewedAt := at
+	request.Status = status
+	request.ReviewedBy = &reviewedBy
+	request.ReviewedAt = &reviewedAt
+	return true, nil
+}
+
+func (r *groupInviteRepository) ApproveJoinRequest(request *models.GroupJoinRequest, member *models.ConversationMember, reviewedBy uuid.UUID, at time.Time) (bool, bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.joinRequests[request.ID]
+	if !ok || stored.Status != models.JoinRequestStatusPending {
+		return false, false, nil
+	}
+
+	// ผู้ขออาจถูกเพิ่มเข้ากลุ่มด้วยวิธีอื่นระหว่างรออนุมัติ
+	_, isMember := r.store.members[memberKey{member.ConversationID, member.UserID}]
+	if !isMember && request.InviteID != nil {
+		// นับการใช้ลิงก์ตอนอนุมัติ ตรวจเฉพาะ max_uses
+		invite, ok := r.store.groupInvites[*request.InviteID]
+		if ok {
+			if invite.MaxUses > 0 && invite.UseCount >= invite.MaxUses {
+				return false, false, errors.New("invite link has reached its usage limit")
+			}
+			invite.UseCount++
+		}
+	}
+
+	reviewedAt := at
+	stored.Status = models.JoinRequestStatusApproved
+	stored.ReviewedBy = &reviewedBy
+	stored.ReviewedAt = &reviewedAt
+	if isMember {
+		return true, false, nil
+	}
+	r.store.addMemberLocked(member)
+	return true, true, nil
+}
+
+func copyGroupInvite(i *models.GroupInvite) *models.GroupInvite {
+	c := *i
+	if i.ExpiresAt != nil {
+		expiresAt := *i.ExpiresAt
+		c.ExpiresAt = &expiresAt
+	}
+	if i.RevokedAt != nil {
+		revokedAt := *i.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+	if i.RevokedBy != nil {
+		revokedBy := *i.RevokedBy
+		c.RevokedBy = &revokedBy
+	}
+	c.Conversation = nil
+	c.Creator = nil
+	return &c
+}
+
+func copyJoinRequest(r *models.GroupJoinRequest) *models.GroupJoinRequest {
+	c := *r
+	if r.InviteID != nil {
+		inviteID := *r.InviteID
+		c.InviteID = &inviteID
+	}
+	if r.ReviewedBy != nil {
+		reviewedBy := *r.ReviewedBy
+		c.ReviewedBy = &reviewedBy
+	}
+	if r.ReviewedAt != nil {
+		reviewedAt := *r.ReviewedAt
+		c.ReviewedAt = &reviewedAt
+	}
+	c.User = nil
+	return &c
+}
@@ -0,0 +1,212 @@
+// infrastructure/persistence/memory/message_mention_repository.go
+package memory
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type messageMentionRepository struct {
+	store *Store
+}
+
+// NewMessageMentionRepository สร้าง MessageMentionRepository ที่เก็บข้อมูลใน Store
+func NewMessageMentionRepository(store *Store) repository.MessageMentionRepository {
+	return &messageMentionRepository{store: store}
+}
+
+func (r *messageMentionRepository) Create(mention *models.MessageMention) error {
+	return r.CreateBatch([]*models.MessageMention{mention})
+}
+
+func (r *messageMentionRepository) CreateBatch(mentions []*models.MessageMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, mention := range mentions {
+		if mention.ID == uuid.Nil {
+			mention.ID = uuid.New()
+		}
+		if mention.CreatedAt.IsZero() {
+			mention.CreatedAt = now
+		}
+		r.store.mentions = append(r.store.mentions, copyMention(mention))
+	}
+	return nil
+}
+
+// GetByUserID ดึง mention ของผู้ใช้แบบ cursor (before = ล่าสุดก่อน, after = เก่าก่อน)
+func (r *messageMentionRepository) GetByUserID(
+	userID uuid.UUID,
+	limit int,
+	cursor *string,
+	direction string,
+) ([]*models.MessageMention, *string, bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var cursorMention *models.MessageMention
+	if cursor != nil && *cursor != "" {
+		cursorID, err := uuid.Parse(*cursor)
+		if err != nil {
+			return nil, nil, false, errors.New("invalid cursor")
+		}
+		for _, m := range r.store.mentions {
+			if m.ID == cursorID {
+				cursorMention = m
+				break
+			}
+		}
+		if cursorMention == nil {
+			return nil, nil, false, errors.New("cursor not found")
+		}
+	}
+
+	mentions := make([]*models.MessageMention, 0)
+	for _, m := range r.store.mentions {
+		if m.MentionedUserID != userID {
+			continue
+		}
+		if cursorMention != nil {
+			if direction == "after" && !mentionBefore(cursorMention, m) {
+				continue
+			}
+			if direction != "after" && !mentionBefore(m, cursorMention) {
+				continue
+			}
+		}
+		mentions = append(mentions, copyMention(m))
+	}
+
+	sort.Slice(mentions, func(i, j int) bool {
+		if direction == "after" {
+			return mentionBefore(mentions[i], mentions[j])
+		}
+		return mentionBefore(mentions[j], mentions[i])
+	})
+
+	hasMore := len(mentions) > limit
+	if hasMore {
+		mentions = mentions[:limit]
+	}
+
+	for _, m := range mentions {
+		if msg, ok := r.store.messages[m.MessageID]; ok {
+			m.Message = copyMessage(msg)
+			if msg.SenderID != nil {
+				if u, ok := r.store.users[*msg.SenderID]; ok {
+					m.Message.Sender = copyUser(u)
+				}
+			}
+			if conv, ok := r.store.conversations[msg.ConversationID]; ok {
+				m.Message.Conversation = copyConversation(conv)
+			}
+		}
+	}
+
+	var nextCursor *string
+	if len(mentions) > 0 {
+		lastID := mentions[len(mentions)-1].ID.String()
+		nextCursor = &lastID
+	}
+
+	return mentions, nextCursor, hasMore, nil
+}
+
+func (r *messageMentionRepository) DeleteByMessageID(messageID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	mentions := r.store.mentions[:0]
+	for _, m := range r.store.mentions {
+		if m.MessageID != messageID {
+			mentions = append(mentions, m)
+		}
+	}
+	r.store.mentions = mentions
+	return nil
+}
+
+func (r *messageMentionRepository) GetByMessageID(messageID uuid.UUID) ([]*models.MessageMention, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	mentions := make([]*models.MessageMention, 0)
+	for _, m := range r.store.mentions {
+		if m.MessageID != messageID {
+			continue
+		}
+		c := copyMention(m)
+		if u, ok := r.store.users[m.MentionedUserID]; ok {
+			c.MentionedUser = copyUser(u)
+		}
+		mentions = append(mentions, c)
+	}
+	return mentions, nil
+}
+
+// CountUnreadMentionsByConversation นับ mention ในการสนทนาที่ผู้อื่นส่งหลัง lastReadAt
+func (r *messageMentionRepository) CountUnreadMentionsByConversation(
+	conversationID uuid.UUID,
+	userID uuid.UUID,
+	lastReadAt *time.Time,
+) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, m := range r.store.mentions {
+		if m.MentionedUserID != userID {
+			continue
+		}
+		msg, ok := r.store.messages[m.MessageID]
+		if !ok || msg.ConversationID != conversationID || !isOtherSender(msg, userID) {
+			continue
+		}
+		if lastReadAt != nil && !msg.CreatedAt.After(*lastReadAt) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *messageMentionRepository) CheckLastMessageHasMention(
+	messageID uuid.UUID,
+	userID uuid.UUID,
+) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, m := range r.store.mentions {
+		if m.MessageID == messageID && m.MentionedUserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func copyMention(m *models.MessageMention) *models.MessageMention {
+	c := *m
+	c.Message = nil
+	c.MentionedUser = nil
+	return &c
+}
+
+// mentionBefore เทียบลำดับ (created_at, id) แบบเดียวกับ cursor ใน postgres repository
+func mentionBefore(a, b *models.MessageMention) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
@@ -0,0 +1,142 @@
+// infrastructure/persistence/memory/message_reaction_repository.go
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type messageReactionRepository struct {
+	store *Store
+}
+
+// NewMessageReactionRepository สร้าง MessageReactionRepository ที่เก็บข้อมูลใน Store
+func NewMessageReactionRepository(store *Store) repository.MessageReactionRepository {
+	return &messageReactionRepository{store: store}
+}
+
+func (r *messageReactionRepository) Create(ctx context.Context, reaction *models.MessageReaction) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// (message_id, user_id, emoji) ซ้ำ = ไม่เพิ่ม (เทียบเท่า ON CONFLICT DO NOTHING)
+	for _, existing := range r.store.reactions {
+		if existing.MessageID == reaction.MessageID && existing.UserID == reaction.UserID && existing.Emoji == reaction.Emoji {
+			return false, nil
+		}
+	}
+
+	if reaction.ID == uuid.Nil {
+		reaction.ID = uuid.New()
+	}
+	if reaction.CreatedAt.IsZero() {
+		reaction.CreatedAt = time.Now()
+	}
+	r.store.reactions = append(r.store.reactions, copyReaction(reaction))
+	return true, nil
+}
+
+func (r *messageReactionRepository) Delete(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	kept := r.store.reactions[:0]
+	for _, reaction := range r.store.reactions {
+		if reaction.MessageID == messageID && reaction.UserID == userID && reaction.Emoji == emoji {
+			continue
+		}
+		kept = append(kept, reaction)
+	}
+	r.store.reactions = kept
+	return nil
+}
+
+func (r *messageReactionRepository) Exists(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, reaction := range r.store.reactions {
+		if reaction.MessageID == messageID && reaction.UserID == userID && reaction.Emoji == emoji {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *messageReactionRepository) CountByUser(ctx context.Context, messageID, userID uuid.UUID) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, reaction := range r.store.reactions {
+		if reaction.MessageID == messageID && reaction.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetByMessageID reactions ของข้อความ เก่าสุดก่อน (เก็บตามลำดับที่เพิ่มอยู่แล้ว)
+func (r *messageReactionRepository) GetByMessageID(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error) {
+	return r.GetByMessageIDs(ctx, []uuid.UUID{messageID})
+}
+
+func (r *messageReactionRepository) GetByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]*models.MessageReaction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[uuid.UUID]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		wanted[id] = true
+	}
+
+	reactions := make([]*models.MessageReaction, 0)
+	for _, reaction := range r.store.reactions {
+		if wanted[reaction.MessageID] {
+			reactions = append(reactions, copyReaction(reaction))
+		}
+	}
+	return reactions, nil
+}
+
+func (r *messageReactionRepository) GetByMessageIDWithUsers(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error) {
+	reactions, err := r.GetByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, reaction := range reactions {
+		if u, ok := r.store.users[reaction.UserID]; ok {
+			reaction.User = copyUser(u)
+		}
+	}
+	return reactions, nil
+}
+
+func (r *messageReactionRepository) DeleteAllByMessageID(ctx context.Context, messageID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	kept := r.store.reactions[:0]
+	for _, reaction := range r.store.reactions {
+		if reaction.MessageID != messageID {
+			kept = append(kept, reaction)
+		}
+	}
+	r.store.reactions = kept
+	return nil
+}
+
+func copyReaction(r *models.MessageReaction) *models.MessageReaction {
+	c := *r
+	c.Message = nil
+	c.User = nil
+	return &c
+}
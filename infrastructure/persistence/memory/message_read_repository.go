@@ -0,0 +1,199 @@
+// infrastructure/persistence/memory/message_read_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type messageReadRepository struct {
+	store *Store
+}
+
+// NewMessageReadRepository สร้าง MessageReadRepository ที่เก็บข้อมูลใน Store
+func NewMessageReadRepository(store *Store) repository.MessageReadRepository {
+	return &messageReadRepository{store: store}
+}
+
+// AdvanceWatermark เลื่อน watermark ของสมาชิกไปข้างหน้า (ไม่ถอยหลัง)
+func (r *messageReadRepository) AdvanceWatermark(conversationID, userID, messageID uuid.UUID, sequence int64, readAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.advanceWatermarkLocked(conversationID, userID, messageID, sequence, readAt), nil
+}
+
+// CountUnread จำนวนข้อความที่ยังไม่ได้อ่าน (last_message_seq - last_read_seq - deleted_unread)
+func (r *messageReadRepository) CountUnread(conversationID, userID uuid.UUID) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	conv, ok := r.store.conversations[conversationID]
+	if !ok {
+		return 0, nil
+	}
+	member, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return 0, nil
+	}
+	return unreadBetween(conv.LastMessageSeq, member.LastReadSeq+member.DeletedUnread), nil
+}
+
+// GetUnreadCounts จำนวนข้อความที่ยังไม่ได้อ่านของทุกการสนทนาที่ใช้งานอยู่ (เฉพาะที่มากกว่า 0)
+func (r *messageReadRepository) GetUnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int)
+	for key, member := range r.store.members {
+		if key.userID != userID {
+			continue
+		}
+		conv, ok := r.store.conversations[key.conversationID]
+		if !ok || !conv.IsActive {
+			continue
+		}
+		if unread := unreadBetween(conv.LastMessageSeq, member.LastReadSeq+member.DeletedUnread); unread > 0 {
+			counts[key.conversationID] = unread
+		}
+	}
+	return counts, nil
+}
+
+// GetByMessageID สมาชิกที่ watermark ผ่านข้อความแล้ว
+func (r *messageReadRepository) GetByMessageID(messageID uuid.UUID) ([]*models.MessageRead, error) {
+	return r.store.readsOf(messageID), nil
+}
+
+// GetUnreadMessageIDs ดึง ID ข้อความของผู้อื่นหลัง watermark (ไม่รวมข้อความใน thread)
+func (r *messageReadRepository) GetUnreadMessageIDs(conversationID, userID uuid.UUID) ([]uuid.UUID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var watermark int64
+	if member, ok := r.store.members[memberKey{conversationID, userID}]; ok {
+		watermark = member.LastReadSeq
+	}
+
+	messages := make([]*models.Message, 0)
+	for _, m := range r.store.messages {
+		if m.ConversationID == conversationID && isOtherSender(m, userID) &&
+			!m.IsDeleted && m.ThreadRootID == nil && m.Sequence > watermark {
+			messages = append(messages, m)
+		}
+	}
+	sortMessagesAsc(messages)
+
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+// CountReads นับจำนวนสมาชิกที่อ่านข้อความแล้ว
+func (r *messageReadRepository) CountReads(messageID uuid.UUID) (int, error) {
+	return len(r.store.readsOf(messageID)), nil
+}
+
+// ============ Store helpers ============
+
+func unreadBetween(lastMessageSeq, lastReadSeq int64) int {
+	if lastMessageSeq <= lastReadSeq {
+		return 0
+	}
+	return int(lastMessageSeq - lastReadSeq)
+}
+
+// assignSequenceLocked กำหนด Sequence ให้ข้อความใหม่ (ต้องถือ lock อยู่แล้ว)
+// ข้อความผู้ใช้ใน timeline +1, ข้อความระบบใช้ค่าล่าสุด, ข้อความใน thread เป็น 0
+func (s *Store) assignSequenceLocked(message *models.Message) {
+	message.Sequence = 0
+	if message.ThreadRootID != nil {
+		return
+	}
+	conv, ok := s.conversations[message.ConversationID]
+	if !ok {
+		return
+	}
+	if message.SenderID != nil {
+		conv.LastMessageSeq++
+	}
+	message.Sequence = conv.LastMessageSeq
+}
+
+// advanceWatermarkLocked เลื่อน watermark ถ้า sequence ใหม่มากกว่าเดิม (ต้องถือ lock อยู่แล้ว)
+func (s *Store) advanceWatermarkLocked(conversationID, userID, messageID uuid.UUID, sequence int64, readAt time.Time) bool {
+	member, ok := s.members[memberKey{conversationID, userID}]
+	if !ok || sequence <= member.LastReadSeq {
+		return false
+	}
+	id := messageID
+	at := readAt
+	member.LastReadMessageID = &id
+	member.LastReadSeq = sequence
+	member.LastReadAt = &at
+
+	// นับใหม่เฉพาะข้อความที่ถูกลบหลัง watermark ใหม่
+	member.DeletedUnread = 0
+	for _, m := range s.messages {
+		if m.ConversationID == conversationID && countsAsUnread(m) && m.IsDeleted && m.Sequence > sequence {
+			member.DeletedUnread++
+		}
+	}
+	return true
+}
+
+// countDeletedUnreadLocked หักข้อความที่เพิ่งถูกลบออกจาก unread ของสมาชิกที่ยังไม่ได้อ่าน (ต้องถือ lock อยู่แล้ว)
+func (s *Store) countDeletedUnreadLocked(m *models.Message) {
+	if !countsAsUnread(m) {
+		return
+	}
+	for key, member := range s.members {
+		if key.conversationID == m.ConversationID && member.LastReadSeq < m.Sequence {
+			member.DeletedUnread++
+		}
+	}
+}
+
+// countsAsUnread ข้อความผู้ใช้ใน timeline (ข้อความที่เพิ่ม last_message_seq)
+func countsAsUnread(m *models.Message) bool {
+	return m.SenderID != nil && m.ThreadRootID == nil && m.Sequence > 0
+}
+
+func (s *Store) hasReadLocked(messageID, userID uuid.UUID) bool {
+	m, ok := s.messages[messageID]
+	if !ok || m.Sequence == 0 {
+		return false
+	}
+	member, ok := s.members[memberKey{m.ConversationID, userID}]
+	return ok && member.LastReadSeq >= m.Sequence
+}
+
+// readsOf สร้างรายการการอ่านจาก watermark ของสมาชิก เรียงตาม read_at
+func (s *Store) readsOf(messageID uuid.UUID) []*models.MessageRead {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reads := make([]*models.MessageRead, 0)
+	m, ok := s.messages[messageID]
+	if !ok || m.Sequence == 0 {
+		return reads
+	}
+	for key, member := range s.members {
+		if key.conversationID != m.ConversationID || member.LastReadSeq < m.Sequence {
+			continue
+		}
+		read := &models.MessageRead{ID: member.ID, MessageID: messageID, UserID: key.userID}
+		if member.LastReadAt != nil {
+			read.ReadAt = *member.LastReadAt
+		}
+		reads = append(reads, read)
+	}
+	sort.SliceStable(reads, func(i, j int) bool { return reads[i].ReadAt.Before(reads[j].ReadAt) })
+	return reads
+}
@@ -0,0 +1,848 @@
+// infrastructure/persistence/memory/message_repository.go
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
+	"gorm.io/gorm"
+)
+
+type messageRepository struct {
+	store *Store
+}
+
+// NewMessageRepository สร้าง MessageRepository ที่เก็บข้อมูลใน Store
+func NewMessageRepository(store *Store) repository.MessageRepository {
+	return &messageRepository{store: store}
+}
+
+// GetByID ดึงข้อความตาม ID (ไม่พบคืน nil, nil)
+func (r *messageRepository) GetByID(id uuid.UUID) (*models.Message, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.messages[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyMessage(m), nil
+}
+
+// GetMessagesByConversationID ดึงข้อความใน timeline หลัก (ล่าสุดตาม limit/offset เรียงเก่าไปใหม่)
+func (r *messageRepository) GetMessagesByConversationID(conversationID uuid.UUID, limit, offset int) ([]*models.Message, int64, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.ThreadRootID == nil
+	})
+	total := int64(len(messages))
+
+	reverseMessages(messages)
+	page := paginate(messages, limit, offset)
+	reverseMessages(page)
+
+	return page, total, nil
+}
+
+// Create สร้างข้อความ พร้อม search document และเวลาหมดอายุตาม TTL ของการสนทนา
+func (r *messageRepository) Create(message *models.Message) error {
+	return r.BulkCreate([]*models.Message{message})
+}
+
+func (r *messageRepository) BulkCreate(messages []*models.Message) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.bulkCreateLocked(messages)
+}
+
+func (r *messageRepository) CreateWithSlowMode(messages []*models.Message, senderID uuid.UUID, interval time.Duration, now time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if len(messages) == 0 {
+		return true, nil
+	}
+	m, ok := r.store.members[memberKey{messages[0].ConversationID, senderID}]
+	if !ok || !slowModeSlotFree(m, now, interval) {
+		return false, nil
+	}
+	if err := r.bulkCreateLocked(messages); err != nil {
+		return false, err
+	}
+	sentAt := now
+	m.LastSentAt = &sentAt
+	return true, nil
+}
+
+func (r *messageRepository) bulkCreateLocked(messages []*models.Message) error {
+	for _, message := range messages {
+		if message.ID == uuid.Nil {
+			message.ID = uuid.New()
+		}
+		if _, ok := r.store.messages[message.ID]; ok {
+			return errors.New("duplicate key value violates unique constraint \"messages_pkey\"")
+		}
+	}
+
+	now := time.Now()
+	for _, message := range messages {
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = now
+		}
+		if message.UpdatedAt.IsZero() {
+			message.UpdatedAt = now
+		}
+		if message.SenderType == "" {
+			message.SenderType = "user"
+		}
+		if message.Status == "" {
+			message.Status = "sent"
+		}
+		message.SearchDocument = textsearch.Document(textsearch.Field{Text: message.Content})
+
+		if message.ExpiresAt == nil {
+			if conv, ok := r.store.conversations[message.ConversationID]; ok && conv.MessageTTLSeconds > 0 {
+				expiresAt := message.CreatedAt.Add(time.Duration(conv.MessageTTLSeconds) * time.Second)
+				message.ExpiresAt = &expiresAt
+			}
+		}
+
+		r.store.assignSequenceLocked(message)
+		r.store.messages[message.ID] = copyMessage(message)
+		if message.SenderID != nil && message.Sequence > 0 {
+			r.store.advanceWatermarkLocked(message.ConversationID, *message.SenderID, message.ID, message.Sequence, message.CreatedAt)
+		}
+	}
+	return nil
+}
+
+// Update อัปเดตเฉพาะ field ที่ไม่ใช่ zero value (เหมือน GORM Updates(struct))
+func (r *messageRepository) Update(message *models.Message) error {
+	if message.Content != "" {
+		message.SearchDocument = textsearch.Document(textsearch.Field{Text: message.Content})
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.messages[message.ID]
+	if !ok {
+		return nil
+	}
+
+	updated := copyMessage(stored)
+	src := reflect.ValueOf(copyMessage(message)).Elem()
+	dst := reflect.ValueOf(updated).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Field(i)
+		if isAssociation(field.Type()) || field.IsZero() {
+			continue
+		}
+		dst.Field(i).Set(field)
+	}
+	r.store.messages[message.ID] = updated
+	return nil
+}
+
+// UpdateFields อัปเดตตามชื่อ column (รองรับค่าว่าง/nil)
+func (r *messageRepository) UpdateFields(messageID uuid.UUID, updates map[string]interface{}) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.messages[messageID]
+	if !ok {
+		return nil
+	}
+
+	updated := copyMessage(stored)
+	for column, value := range updates {
+		if err := setMessageColumn(updated, column, value); err != nil {
+			return err
+		}
+	}
+	if _, ok := updates["content"]; ok {
+		updated.SearchDocument = textsearch.Document(textsearch.Field{Text: updated.Content})
+	}
+	r.store.messages[messageID] = updated
+	return nil
+}
+
+// MarkDeleted อัปเดต fields ของการลบเฉพาะเมื่อข้อความยังไม่ถูกลบ
+func (r *messageRepository) MarkDeleted(messageID uuid.UUID, updates map[string]interface{}) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.messages[messageID]
+	if !ok || stored.IsDeleted {
+		return false, nil
+	}
+
+	updated := copyMessage(stored)
+	for column, value := range updates {
+		if err := setMessageColumn(updated, column, value); err != nil {
+			return false, err
+		}
+	}
+	if _, ok := updates["content"]; ok {
+		updated.SearchDocument = textsearch.Document(textsearch.Field{Text: updated.Content})
+	}
+	r.store.messages[messageID] = updated
+	r.store.countDeletedUnreadLocked(updated)
+	return true, nil
+}
+
+// Delete ลบข้อความแบบ soft delete
+func (r *messageRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	m, ok := r.store.messages[id]
+	if !ok {
+		return errors.New("message not found")
+	}
+	m.IsDeleted = true
+	m.Content = ""
+	m.SearchDocument = ""
+	m.MediaURL = ""
+	m.MediaThumbnailURL = ""
+	m.Metadata = map[string]interface{}{}
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *messageRepository) CreateEditHistory(history *models.MessageEditHistory) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c := *history
+	c.Message = nil
+	c.Editor = nil
+	r.store.editHistory = append(r.store.editHistory, &c)
+	return nil
+}
+
+// GetEditHistory ดึงประวัติการแก้ไข (ล่าสุดก่อน)
+func (r *messageRepository) GetEditHistory(messageID uuid.UUID) ([]*models.MessageEditHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	history := make([]*models.MessageEditHistory, 0)
+	for _, h := range r.store.editHistory {
+		if h.MessageID == messageID {
+			c := *h
+			history = append(history, &c)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].EditedAt.After(history[j].EditedAt) })
+	return history, nil
+}
+
+func (r *messageRepository) CreateDeleteHistory(history *models.MessageDeleteHistory) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c := *history
+	c.Message = nil
+	c.Deleter = nil
+	r.store.deleteHistory = append(r.store.deleteHistory, &c)
+	return nil
+}
+
+// GetDeleteHistory ดึงประวัติการลบ (ล่าสุดก่อน)
+func (r *messageRepository) GetDeleteHistory(messageID uuid.UUID) ([]*models.MessageDeleteHistory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	history := make([]*models.MessageDeleteHistory, 0)
+	for _, h := range r.store.deleteHistory {
+		if h.MessageID == messageID {
+			c := *h
+			history = append(history, &c)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].DeletedAt.After(history[j].DeletedAt) })
+	return history, nil
+}
+
+// MarkAsRead เลื่อน read watermark ของผู้ใช้ไปที่ข้อความ
+func (r *messageRepository) MarkAsRead(messageID, userID uuid.UUID, readAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.messages[messageID]; ok && m.Sequence > 0 {
+		r.store.advanceWatermarkLocked(m.ConversationID, userID, m.ID, m.Sequence, readAt)
+	}
+	return nil
+}
+
+func (r *messageRepository) GetReads(messageID uuid.UUID) ([]*models.MessageRead, error) {
+	return r.store.readsOf(messageID), nil
+}
+
+func (r *messageRepository) IsMessageRead(messageID, userID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.hasReadLocked(messageID, userID), nil
+}
+
+// MarkAllAsRead เลื่อน read watermark ไปที่ข้อความล่าสุดใน timeline
+func (r *messageRepository) MarkAllAsRead(conversationID, userID uuid.UUID, readAt time.Time) error {
+	latest := r.store.lastMessage(conversationID, func(m *models.Message) bool {
+		return m.ThreadRootID == nil && m.Sequence > 0
+	})
+	if latest == nil {
+		return nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.advanceWatermarkLocked(conversationID, userID, latest.ID, latest.Sequence, readAt)
+	return nil
+}
+
+func (r *messageRepository) IsSender(messageID, userID uuid.UUID) (bool, error) {
+	message, _ := r.GetByID(messageID)
+	if message == nil {
+		return false, errors.New("message not found")
+	}
+	return message.SenderID != nil && *message.SenderID == userID, nil
+}
+
+// IsConversationAdmin ตรวจ is_admin ของสมาชิก (ไม่ใช่สมาชิกคืน false, nil)
+func (r *messageRepository) IsConversationAdmin(conversationID, userID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m, ok := r.store.members[memberKey{conversationID, userID}]
+	if !ok {
+		return false, nil
+	}
+	return m.IsAdmin, nil
+}
+
+func (r *messageRepository) UpdateConversationLastMessage(conversationID uuid.UUID, lastMessageText string, lastMessageAt time.Time, messageID uuid.UUID) error {
+	r.store.setLastMessage(conversationID, messageID, lastMessageText, lastMessageAt)
+	return nil
+}
+
+func (r *messageRepository) GetLastMessageByConversation(conversationID uuid.UUID) (*models.Message, error) {
+	return r.store.lastMessage(conversationID, func(m *models.Message) bool {
+		return m.ThreadRootID == nil
+	}), nil
+}
+
+func (r *messageRepository) GetLastNonDeletedMessageByConversation(conversationID uuid.UUID) (*models.Message, error) {
+	return r.store.lastMessage(conversationID, func(m *models.Message) bool {
+		return m.ThreadRootID == nil && !m.IsDeleted
+	}), nil
+}
+
+// GetMessagesBefore ดึงข้อความที่เก่ากว่าข้อความที่ระบุ (เรียงเก่าไปใหม่)
+func (r *messageRepository) GetMessagesBefore(conversationID, messageID uuid.UUID, limit int) ([]*models.Message, error) {
+	target, _ := r.GetByID(messageID)
+	if target == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.ThreadRootID == nil && messageBefore(m, target)
+	})
+	reverseMessages(messages)
+	page := paginate(messages, limit, 0)
+	reverseMessages(page)
+	return page, nil
+}
+
+// GetMessagesAfter ดึงข้อความที่ใหม่กว่าข้อความที่ระบุ (เรียงเก่าไปใหม่)
+func (r *messageRepository) GetMessagesAfter(conversationID, messageID uuid.UUID, limit int) ([]*models.Message, error) {
+	target, _ := r.GetByID(messageID)
+	if target == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.ThreadRootID == nil && messageBefore(target, m)
+	})
+	return paginate(messages, limit, 0), nil
+}
+
+func (r *messageRepository) CountAllMessages(conversationID uuid.UUID) (int64, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.ThreadRootID == nil
+	})
+	return int64(len(messages)), nil
+}
+
+func (r *messageRepository) GetMessagesAfterTime(conversationID uuid.UUID, afterTime time.Time, excludeUserID uuid.UUID) ([]*models.Message, error) {
+	return r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.CreatedAt.After(afterTime) &&
+			isOtherSender(m, excludeUserID) && !m.IsDeleted && m.ThreadRootID == nil
+	}), nil
+}
+
+func (r *messageRepository) GetAllUnreadMessages(conversationID uuid.UUID, excludeUserID uuid.UUID) ([]*models.Message, error) {
+	return r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && isOtherSender(m, excludeUserID) &&
+			!m.IsDeleted && m.ThreadRootID == nil
+	}), nil
+}
+
+// GetMessageTypeSummary นับ image/video/file รวมไฟล์ใน album
+func (r *messageRepository) GetMessageTypeSummary(conversationID uuid.UUID) (map[string]int64, error) {
+	summary := make(map[string]int64)
+	for _, m := range r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && !m.IsDeleted
+	}) {
+		switch m.MessageType {
+		case "image", "video", "file":
+			summary[m.MessageType]++
+		case "album":
+			for _, file := range albumFiles(m.AlbumFiles) {
+				if fileType, ok := file["file_type"].(string); ok {
+					summary[fileType]++
+				}
+			}
+		}
+	}
+	return summary, nil
+}
+
+func (r *messageRepository) CountMessagesWithLinks(conversationID uuid.UUID) (int64, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && !m.IsDeleted && hasLinks(m)
+	})
+	return int64(len(messages)), nil
+}
+
+// GetMediaByType ดึงข้อความตามประเภท media ("link" = ข้อความที่มีลิงก์) ล่าสุดก่อน
+func (r *messageRepository) GetMediaByType(conversationID uuid.UUID, messageType string, limit, offset int) ([]*models.Message, int64, error) {
+	if messageType == "link" {
+		messages := r.filter(func(m *models.Message) bool {
+			return m.ConversationID == conversationID && !m.IsDeleted && hasLinks(m)
+		})
+		reverseMessages(messages)
+		return paginate(messages, limit, offset), int64(len(messages)), nil
+	}
+
+	var total int64
+	messages := r.filter(func(m *models.Message) bool {
+		if m.ConversationID != conversationID || m.IsDeleted {
+			return false
+		}
+		if m.MessageType == messageType {
+			total++
+			return true
+		}
+		if m.MessageType != "album" {
+			return false
+		}
+		matched := false
+		for _, file := range albumFiles(m.AlbumFiles) {
+			if file["file_type"] == messageType {
+				total++
+				matched = true
+			}
+		}
+		return matched
+	})
+	reverseMessages(messages)
+	return paginate(messages, limit, offset), total, nil
+}
+
+func (r *messageRepository) PinMessage(messageID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.messages[messageID]; ok {
+		now := time.Now()
+		pinnedBy := userID
+		m.IsPinned = true
+		m.PinnedBy = &pinnedBy
+		m.PinnedAt = &now
+	}
+	return nil
+}
+
+func (r *messageRepository) UnpinMessage(messageID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.messages[messageID]; ok {
+		m.IsPinned = false
+		m.PinnedBy = nil
+		m.PinnedAt = nil
+	}
+	return nil
+}
+
+// GetPinnedMessages ดึงข้อความที่ปักหมุด (ปักหมุดล่าสุดก่อน)
+func (r *messageRepository) GetPinnedMessages(conversationID uuid.UUID, limit, offset int) ([]*models.Message, int64, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && m.IsPinned && !m.IsDeleted
+	})
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].PinnedAt != nil && messages[j].PinnedAt != nil && messages[i].PinnedAt.After(*messages[j].PinnedAt)
+	})
+	r.attachSenders(messages)
+	return paginate(messages, limit, offset), int64(len(messages)), nil
+}
+
+func (r *messageRepository) FindByDateRange(conversationID uuid.UUID, startDate, endDate time.Time, limit int) ([]*models.Message, int64, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ConversationID == conversationID && !m.IsDeleted &&
+			!m.CreatedAt.Before(startDate) && m.CreatedAt.Before(endDate)
+	})
+	r.attachSenders(messages)
+	return paginate(messages, limit, 0), int64(len(messages)), nil
+}
+
+// SearchMessages ค้นหาแบบ substring (ไม่สนตัวพิมพ์) แทน full-text search ของ PostgreSQL
+// sortBy "relevance" ใช้ cursor เป็น offset และเรียงล่าสุดก่อน
+func (r *messageRepository) SearchMessages(searchQuery string, conversationID *uuid.UUID, userID uuid.UUID, limit int, cursor *string, direction string, sortBy string) ([]*models.Message, *string, bool, error) {
+	query := strings.ToLower(strings.TrimSpace(searchQuery))
+	if query == "" {
+		return []*models.Message{}, nil, false, nil
+	}
+
+	r.store.mu.RLock()
+	visible := make(map[uuid.UUID]bool)
+	for key, m := range r.store.members {
+		if key.userID == userID && !m.IsHidden {
+			visible[key.conversationID] = true
+		}
+	}
+	r.store.mu.RUnlock()
+
+	messages := r.filter(func(m *models.Message) bool {
+		if m.IsDeleted || !strings.Contains(strings.ToLower(m.Content), query) {
+			return false
+		}
+		if conversationID != nil {
+			return m.ConversationID == *conversationID
+		}
+		return visible[m.ConversationID]
+	})
+
+	if sortBy == "relevance" {
+		offset := 0
+		if cursor != nil && *cursor != "" {
+			parsed, err := strconv.Atoi(*cursor)
+			if err != nil || parsed < 0 {
+				return nil, nil, false, errors.New("invalid cursor")
+			}
+			offset = parsed
+		}
+		reverseMessages(messages)
+		page := paginate(messages, limit, offset)
+		hasMore := offset+len(page) < len(messages)
+
+		var nextCursor *string
+		if hasMore {
+			next := strconv.Itoa(offset + len(page))
+			nextCursor = &next
+		}
+		r.attachSenders(page)
+		return page, nextCursor, hasMore, nil
+	}
+
+	if cursor != nil && *cursor != "" {
+		cursorID, err := uuid.Parse(*cursor)
+		if err != nil {
+			return nil, nil, false, errors.New("invalid cursor")
+		}
+		cursorMsg, _ := r.GetByID(cursorID)
+		if cursorMsg == nil {
+			return nil, nil, false, errors.New("cursor message not found")
+		}
+
+		filtered := messages[:0]
+		for _, m := range messages {
+			if (direction == "after" && messageBefore(cursorMsg, m)) || (direction != "after" && messageBefore(m, cursorMsg)) {
+				filtered = append(filtered, m)
+			}
+		}
+		messages = filtered
+	}
+
+	if direction != "after" {
+		reverseMessages(messages)
+	}
+	hasMore := len(messages) > limit
+	page := paginate(messages, limit, 0)
+	if direction != "after" {
+		reverseMessages(page)
+	}
+
+	var nextCursor *string
+	if len(page) > 0 {
+		lastID := page[len(page)-1].ID.String()
+		nextCursor = &lastID
+	}
+	r.attachSenders(page)
+	return page, nextCursor, hasMore, nil
+}
+
+// GetMessagesByAlbumID ดึงข้อความใน album เรียงตาม album_position
+func (r *messageRepository) GetMessagesByAlbumID(albumID string) ([]*models.Message, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		id, _ := m.Metadata["album_id"].(string)
+		return id == albumID
+	})
+	sort.SliceStable(messages, func(i, j int) bool {
+		pi, _ := toInt(messages[i].Metadata["album_position"])
+		pj, _ := toInt(messages[j].Metadata["album_position"])
+		return pi < pj
+	})
+	return messages, nil
+}
+
+// GetThreadReplies ดึงข้อความตอบกลับใน thread ต่อจาก afterID (เรียงเก่าไปใหม่)
+func (r *messageRepository) GetThreadReplies(rootID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Message, error) {
+	var cursor *models.Message
+	if afterID != nil {
+		cursor, _ = r.GetByID(*afterID)
+		if cursor == nil || cursor.ThreadRootID == nil || *cursor.ThreadRootID != rootID {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ThreadRootID != nil && *m.ThreadRootID == rootID && (cursor == nil || messageBefore(cursor, m))
+	})
+	return paginate(messages, limit, 0), nil
+}
+
+func (r *messageRepository) IncrementThreadReplyCount(rootID uuid.UUID, replyAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if m, ok := r.store.messages[rootID]; ok {
+		m.ThreadReplyCount++
+		m.ThreadLastReplyAt = &replyAt
+	}
+	return nil
+}
+
+// FindExpiredMessages ดึงข้อความที่หมดอายุแล้วแต่ยังไม่ถูกลบ (หมดอายุก่อนมาก่อน)
+func (r *messageRepository) FindExpiredMessages(before time.Time, limit int) ([]*models.Message, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		return m.ExpiresAt != nil && !m.ExpiresAt.After(before) && !m.IsDeleted
+	})
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].ExpiresAt.Before(*messages[j].ExpiresAt) })
+	return paginate(messages, limit, 0), nil
+}
+
+func (r *messageRepository) IsMediaURLReferenced(mediaURL string) (bool, error) {
+	messages := r.filter(func(m *models.Message) bool {
+		if m.IsDeleted {
+			return false
+		}
+		if m.MediaURL == mediaURL || m.MediaThumbnailURL == mediaURL {
+			return true
+		}
+		for _, file := range albumFiles(m.AlbumFiles) {
+			if file["media_url"] == mediaURL || file["media_thumbnail_url"] == mediaURL {
+				return true
+			}
+		}
+		return false
+	})
+	return len(messages) > 0, nil
+}
+
+// filter คืนสำเนาข้อความที่ตรงเงื่อนไข เรียงตาม created_at, id จากเก่าไปใหม่
+func (r *messageRepository) filter(match func(*models.Message) bool) []*models.Message {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	messages := make([]*models.Message, 0)
+	for _, m := range r.store.messages {
+		if match(m) {
+			messages = append(messages, copyMessage(m))
+		}
+	}
+	sortMessagesAsc(messages)
+	return messages
+}
+
+// attachSenders เติม Sender ให้เหมือน Preload("Sender")
+func (r *messageRepository) attachSenders(messages []*models.Message) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, m := range messages {
+		if m.SenderID == nil {
+			continue
+		}
+		if u, ok := r.store.users[*m.SenderID]; ok {
+			m.Sender = copyUser(u)
+		}
+	}
+}
+
+// ============ Store helpers ============
+
+// lastMessage ข้อความล่าสุดของการสนทนาที่ตรงเงื่อนไข (ไม่พบคืน nil)
+func (s *Store) lastMessage(conversationID uuid.UUID, match func(*models.Message) bool) *models.Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var last *models.Message
+	for _, m := range s.messages {
+		if m.ConversationID != conversationID || !match(m) {
+			continue
+		}
+		if last == nil || messageBefore(last, m) {
+			last = m
+		}
+	}
+	if last == nil {
+		return nil
+	}
+	return copyMessage(last)
+}
+
+// setMessageColumn ตั้งค่า column ของข้อความตามชื่อ column ใน database
+func setMessageColumn(m *models.Message, column string, value interface{}) error {
+	switch column {
+	case "content":
+		v, _ := value.(string)
+		m.Content = v
+	case "media_url":
+		v, _ := value.(string)
+		m.MediaURL = v
+	case "media_thumbnail_url":
+		v, _ := value.(string)
+		m.MediaThumbnailURL = v
+	case "message_type":
+		v, _ := value.(string)
+		m.MessageType = v
+	case "status":
+		v, _ := value.(string)
+		m.Status = v
+	case "metadata":
+		v, err := toJSONB(value)
+		if err != nil {
+			return err
+		}
+		m.Metadata = v
+	case "mentions":
+		v, err := toJSONB(value)
+		if err != nil {
+			return err
+		}
+		m.Mentions = v
+	case "album_files":
+		m.AlbumFiles = value
+	case "is_deleted":
+		v, _ := value.(bool)
+		m.IsDeleted = v
+	case "is_edited":
+		v, _ := value.(bool)
+		m.IsEdited = v
+	case "is_pinned":
+		v, _ := value.(bool)
+		m.IsPinned = v
+	case "edit_count":
+		v, ok := toInt(value)
+		if !ok {
+			return fmt.Errorf("memory: invalid edit_count value %T", value)
+		}
+		m.EditCount = v
+	case "thread_reply_count":
+		v, ok := toInt(value)
+		if !ok {
+			return fmt.Errorf("memory: invalid thread_reply_count value %T", value)
+		}
+		m.ThreadReplyCount = v
+	case "created_at":
+		v, err := toTimePtr(value)
+		if err != nil || v == nil {
+			return fmt.Errorf("memory: invalid created_at value %T", value)
+		}
+		m.CreatedAt = *v
+	case "updated_at":
+		v, err := toTimePtr(value)
+		if err != nil || v == nil {
+			return fmt.Errorf("memory: invalid updated_at value %T", value)
+		}
+		m.UpdatedAt = *v
+	case "delivered_at":
+		v, err := toTimePtr(value)
+		if err != nil {
+			return err
+		}
+		m.DeliveredAt = v
+	case "read_at":
+		v, err := toTimePtr(value)
+		if err != nil {
+			return err
+		}
+		m.ReadAt = v
+	case "expires_at":
+		v, err := toTimePtr(value)
+		if err != nil {
+			return err
+		}
+		m.ExpiresAt = v
+	case "pinned_at":
+		v, err := toTimePtr(value)
+		if err != nil {
+			return err
+		}
+		m.PinnedAt = v
+	case "pinned_by":
+		v, err := toUUIDPtr(value)
+		if err != nil {
+			return err
+		}
+		m.PinnedBy = v
+	case "search_document":
+		v, _ := value.(string)
+		m.SearchDocument = v
+	default:
+		return fmt.Errorf("memory: unsupported message column %q", column)
+	}
+	return nil
+}
+
+// isAssociation ตรวจว่าเป็น field association (pointer ไปยัง struct ของ models หรือ slice ของ pointer)
+func isAssociation(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return t.Elem().Kind() == reflect.Struct && t.Elem().PkgPath() == reflect.TypeOf(models.Message{}).PkgPath()
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Ptr
+	default:
+		return false
+	}
+}
+
+func hasLinks(m *models.Message) bool {
+	links, ok := m.Metadata["links"]
+	if !ok || links == nil {
+		return false
+	}
+	v := reflect.ValueOf(links)
+	if v.Kind() == reflect.Slice {
+		return v.Len() > 0
+	}
+	return true
+}
+
+func reverseMessages(messages []*models.Message) {
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+}
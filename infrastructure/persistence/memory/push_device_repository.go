@@ -0,0 +1,108 @@
+// infrastructure/persistence/memory/push_device_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type pushDeviceRepository struct {
+	store *Store
+}
+
+// NewPushDeviceRepository สร้าง PushDeviceRepository ที่เก็บข้อมูลใน Store
+func NewPushDeviceRepository(store *Store) repository.PushDeviceRepository {
+	return &pushDeviceRepository{store: store}
+}
+
+func (r *pushDeviceRepository) Upsert(device *models.PushDevice) error {
+	now := time.Now()
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// token ซ้ำ: อัปเดตแถวเดิม (เทียบเท่า ON CONFLICT (token))
+	for _, existing := range r.store.pushDevices {
+		if existing.Token == device.Token {
+			device.ID = existing.ID
+			device.CreatedAt = existing.CreatedAt
+			device.LastUsedAt = existing.LastUsedAt
+			break
+		}
+	}
+
+	if device.ID == uuid.Nil {
+		device.ID = uuid.New()
+	}
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	device.UpdatedAt = now
+
+	r.store.pushDevices[device.ID] = copyPushDevice(device)
+	return nil
+}
+
+func (r *pushDeviceRepository) FindByID(id uuid.UUID) (*models.PushDevice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	d, ok := r.store.pushDevices[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyPushDevice(d), nil
+}
+
+func (r *pushDeviceRepository) FindByUserID(userID uuid.UUID) ([]*models.PushDevice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var devices []*models.PushDevice
+	for _, d := range r.store.pushDevices {
+		if d.UserID == userID {
+			devices = append(devices, copyPushDevice(d))
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+	})
+	return devices, nil
+}
+
+func (r *pushDeviceRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.pushDevices, id)
+	return nil
+}
+
+func (r *pushDeviceRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if d, ok := r.store.pushDevices[id]; ok {
+		usedAt := at
+		d.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+func copyPushDevice(d *models.PushDevice) *models.PushDevice {
+	c := *d
+	if d.SessionID != nil {
+		sessionID := *d.SessionID
+		c.SessionID = &sessionID
+	}
+	if d.LastUsedAt != nil {
+		lastUsedAt := *d.LastUsedAt
+		c.LastUsedAt = &lastUsedAt
+	}
+	c.User = nil
+	return &c
+}
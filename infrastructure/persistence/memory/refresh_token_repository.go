@@ -0,0 +1,114 @@
+// infrastructure/persistence/memory/refresh_token_repository.go
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	store *Store
+}
+
+// NewRefreshTokenRepository สร้าง RefreshTokenRepository ที่เก็บข้อมูลใน Store
+func NewRefreshTokenRepository(store *Store) repository.RefreshTokenRepository {
+	return &refreshTokenRepository{store: store}
+}
+
+func (r *refreshTokenRepository) Create(refreshToken *models.RefreshToken) error {
+	if refreshToken.ID == uuid.Nil {
+		refreshToken.ID = uuid.New()
+	}
+	if refreshToken.CreatedAt.IsZero() {
+		refreshToken.CreatedAt = time.Now()
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.refreshTokens[refreshToken.ID] = copyRefreshToken(refreshToken)
+	return nil
+}
+
+// FindByTokenHash ค้นหา token จาก hash (รวม token ที่ถูกยกเลิกแล้ว)
+func (r *refreshTokenRepository) FindByTokenHash(tokenHash string) (*models.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, t := range r.store.refreshTokens {
+		if t.TokenHash == tokenHash {
+			return copyRefreshToken(t), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *refreshTokenRepository) RevokeByUserID(userID uuid.UUID) error {
+	return r.revoke(func(t *models.RefreshToken) bool { return t.UserID == userID })
+}
+
+func (r *refreshTokenRepository) MarkRotated(id uuid.UUID, rotatedAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.refreshTokens[id]
+	if !ok || t.Revoked {
+		return false, nil
+	}
+	at := rotatedAt
+	t.Revoked = true
+	t.RotatedAt = &at
+	return true, nil
+}
+
+func (r *refreshTokenRepository) RevokeBySessionID(sessionID uuid.UUID) error {
+	return r.revoke(func(t *models.RefreshToken) bool {
+		return t.SessionID != nil && *t.SessionID == sessionID
+	})
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.revoke(func(t *models.RefreshToken) bool { return t.FamilyID == familyID })
+}
+
+func (r *refreshTokenRepository) DeleteExpired(before time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, t := range r.store.refreshTokens {
+		if t.ExpiresAt.Before(before) {
+			delete(r.store.refreshTokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) revoke(match func(*models.RefreshToken) bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, t := range r.store.refreshTokens {
+		if match(t) {
+			t.Revoked = true
+		}
+	}
+	return nil
+}
+
+func copyRefreshToken(t *models.RefreshToken) *models.RefreshToken {
+	c := *t
+	if t.SessionID != nil {
+		sessionID := *t.SessionID
+		c.SessionID = &sessionID
+	}
+	if t.RotatedAt != nil {
+		rotatedAt := *t.RotatedAt
+		c.RotatedAt = &rotatedAt
+	}
+	c.User = nil
+	return &c
+}
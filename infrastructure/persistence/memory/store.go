@@ -0,0 +1,264 @@
+// infrastructure/persistence/memory/store.go
+
+// Package memory เป็น implementation ของ domain/repository ที่เก็บข้อมูลในหน่วยความจำ
+// ใช้สำหรับการทดสอบ service โดยไม่ต้องมี PostgreSQL
+// พฤติกรรมเลียนแบบ repository ใน infrastructure/persistence/postgres ให้มากที่สุด
+// รวมถึงการคืนค่าเมื่อไม่พบข้อมูล (nil, nil หรือ gorm.ErrRecordNotFound ตามแต่ละเมธอด)
+// ทุกเมธอดคืนสำเนาของข้อมูล การแก้ไข struct ที่ได้รับจะไม่มีผลจนกว่าจะเรียก Update
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+)
+
+// memberKey key ของ conversation_members (conversation_id, user_id)
+type memberKey struct {
+	conversationID uuid.UUID
+	userID         uuid.UUID
+}
+
+// Store เก็บข้อมูลทุกตารางที่ repository ในหน่วยความจำใช้ร่วมกัน
+// repository ที่สร้างจาก Store เดียวกันเห็นข้อมูลชุดเดียวกัน (เช่นเดียวกับการใช้ database เดียวกัน)
+type Store struct {
+	mu sync.RWMutex
+
+	users         map[uuid.UUID]*models.User
+	friendships   map[uuid.UUID]*models.UserFriendship
+	conversations map[uuid.UUID]*models.Conversation
+	members       map[memberKey]*models.ConversationMember
+	messages      map[uuid.UUID]*models.Message
+	editHistory   []*models.MessageEditHistory
+	deleteHistory []*models.MessageDeleteHistory
+	mentions      []*models.MessageMention
+	reactions     []*models.MessageReaction
+	refreshTokens map[uuid.UUID]*models.RefreshToken
+	sessions      map[uuid.UUID]*models.UserSession
+
+	verificationTokens map[uuid.UUID]*models.VerificationToken
+	twoFactors         map[uuid.UUID]*models.UserTwoFactor
+	recoveryCodes      map[uuid.UUID]*models.UserRecoveryCode
+	pushDevices        map[uuid.UUID]*models.PushDevice
+	fileUploads        map[uuid.UUID]*models.FileUpload
+	groupInvites       map[uuid.UUID]*models.GroupInvite
+	joinRequests       map[uuid.UUID]*models.GroupJoinRequest
+	groupBans          map[memberKey]*models.GroupBan
+}
+
+// NewStore สร้าง Store ว่างตัวใหม่
+func NewStore() *Store {
+	return &Store{
+		users:         make(map[uuid.UUID]*models.User),
+		friendships:   make(map[uuid.UUID]*models.UserFriendship),
+		conversations: make(map[uuid.UUID]*models.Conversation),
+		members:       make(map[memberKey]*models.ConversationMember),
+		messages:      make(map[uuid.UUID]*models.Message),
+		refreshTokens: make(map[uuid.UUID]*models.RefreshToken),
+		sessions:      make(map[uuid.UUID]*models.UserSession),
+
+		verificationTokens: make(map[uuid.UUID]*models.VerificationToken),
+		twoFactors:         make(map[uuid.UUID]*models.UserTwoFactor),
+		recoveryCodes:      make(map[uuid.UUID]*models.UserRecoveryCode),
+		pushDevices:        make(map[uuid.UUID]*models.PushDevice),
+		fileUploads:        make(map[uuid.UUID]*models.FileUpload),
+		groupInvites:       make(map[uuid.UUID]*models.GroupInvite),
+		joinRequests:       make(map[uuid.UUID]*models.GroupJoinRequest),
+		groupBans:          make(map[memberKey]*models.GroupBan),
+	}
+}
+
+// ============ Copy helpers ============
+
+func cloneJSONB(j types.JSONB) types.JSONB {
+	if j == nil {
+		return nil
+	}
+	c := make(types.JSONB, len(j))
+	for k, v := range j {
+		c[k] = v
+	}
+	return c
+}
+
+func copyUser(u *models.User) *models.User {
+	c := *u
+	c.Settings = cloneJSONB(u.Settings)
+	c.ConversationMembers = nil
+	c.CreatedConversations = nil
+	c.Messages = nil
+	c.MessageReads = nil
+	c.FavoriteStickers = nil
+	c.FriendshipsAsUser = nil
+	c.FriendshipsAsFriend = nil
+	c.RecentStickers = nil
+	c.StickerSets = nil
+	c.RefreshTokens = nil
+	return &c
+}
+
+func copyFriendship(f *models.UserFriendship) *models.UserFriendship {
+	c := *f
+	c.User = nil
+	c.Friend = nil
+	return &c
+}
+
+func copyConversation(conv *models.Conversation) *models.Conversation {
+	c := *conv
+	c.Metadata = cloneJSONB(conv.Metadata)
+	c.Permissions = cloneJSONB(conv.Permissions)
+	c.Creator = nil
+	c.Members = nil
+	c.Messages = nil
+	return &c
+}
+
+func copyMember(m *models.ConversationMember) *models.ConversationMember {
+	c := *m
+	c.NotificationSettings = cloneJSONB(m.NotificationSettings)
+	c.Conversation = nil
+	c.User = nil
+	return &c
+}
+
+func copyMessage(m *models.Message) *models.Message {
+	c := *m
+	c.Metadata = cloneJSONB(m.Metadata)
+	c.Mentions = cloneJSONB(m.Mentions)
+	c.ForwardedFrom = cloneJSONB(m.ForwardedFrom)
+	c.Conversation = nil
+	c.Sender = nil
+	c.ReplyTo = nil
+	c.Reads = nil
+	c.EditHistory = nil
+	c.DeleteHistory = nil
+	c.Pinner = nil
+	return &c
+}
+
+// ============ Value helpers ============
+
+// toJSONB แปลงค่าที่ส่งมาใน map ของ UpdateFields/UpdateConversation เป็น JSONB
+func toJSONB(v interface{}) (types.JSONB, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case types.JSONB:
+		return cloneJSONB(val), nil
+	case map[string]interface{}:
+		return cloneJSONB(types.JSONB(val)), nil
+	case string:
+		var j types.JSONB
+		if err := json.Unmarshal([]byte(val), &j); err != nil {
+			return nil, err
+		}
+		return j, nil
+	default:
+		return nil, fmt.Errorf("unsupported jsonb value %T", v)
+	}
+}
+
+// toInt แปลงตัวเลขทุกชนิด (รวม float64 จาก JSON) เป็น int
+func toInt(v interface{}) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int32:
+		return int(val), true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case json.Number:
+		n, err := val.Int64()
+		return int(n), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toTimePtr แปลงค่าเวลา (time.Time, *time.Time หรือ nil)
+func toTimePtr(v interface{}) (*time.Time, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &val, nil
+	case *time.Time:
+		return val, nil
+	default:
+		return nil, fmt.Errorf("unsupported time value %T", v)
+	}
+}
+
+// toUUIDPtr แปลงค่า uuid (uuid.UUID, *uuid.UUID หรือ nil)
+func toUUIDPtr(v interface{}) (*uuid.UUID, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case uuid.UUID:
+		return &val, nil
+	case *uuid.UUID:
+		return val, nil
+	default:
+		return nil, fmt.Errorf("unsupported uuid value %T", v)
+	}
+}
+
+// albumFiles แปลง AlbumFiles (เก็บเป็น jsonb) เป็น slice ของ map เพื่ออ่าน file_type / media_url
+func albumFiles(v interface{}) []map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var files []map[string]interface{}
+	if err := json.Unmarshal(raw, &files); err != nil {
+		return nil
+	}
+	return files
+}
+
+// sortMessagesAsc เรียงข้อความตาม created_at, id จากเก่าไปใหม่
+func sortMessagesAsc(messages []*models.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		return messageBefore(messages[i], messages[j])
+	})
+}
+
+// messageBefore เทียบลำดับ (created_at, id) แบบเดียวกับ cursor ใน postgres repository
+func messageBefore(a, b *models.Message) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+// isOtherSender เลียนแบบ "sender_id != ?" ใน SQL (ข้อความที่ไม่มีผู้ส่งไม่ผ่านเงื่อนไข)
+func isOtherSender(m *models.Message, userID uuid.UUID) bool {
+	return m.SenderID != nil && *m.SenderID != userID
+}
+
+// paginate ตัด slice ตาม limit/offset (limit <= 0 = ไม่จำกัด)
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,176 @@
+// infrastructure/persistence/memory/two_factor_repository.go
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	store *Store
+}
+
+// NewTwoFactorRepository สร้าง TwoFactorRepository ที่เก็บข้อมูลใน Store
+func NewTwoFactorRepository(store *Store) repository.TwoFactorRepository {
+	return &twoFactorRepository{store: store}
+}
+
+func (r *twoFactorRepository) FindByUserID(userID uuid.UUID) (*models.UserTwoFactor, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	t, ok := r.store.twoFactors[userID]
+	if !ok {
+		return nil, nil
+	}
+	return copyTwoFactor(t), nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *models.UserTwoFactor) error {
+	now := time.Now()
+	if twoFactor.CreatedAt.IsZero() {
+		twoFactor.CreatedAt = now
+	}
+	twoFactor.UpdatedAt = now
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.twoFactors[twoFactor.UserID] = copyTwoFactor(twoFactor)
+	return nil
+}
+
+func (r *twoFactorRepository) Delete(userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.twoFactors, userID)
+	for id, c := range r.store.recoveryCodes {
+		if c.UserID == userID {
+			delete(r.store.recoveryCodes, id)
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) MarkStepUsed(userID uuid.UUID, step int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.twoFactors[userID]
+	if !ok || t.LastUsedStep >= step {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	t.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *twoFactorRepository) UpdateFailures(userID uuid.UUID, failedAttempts int, lockedUntil *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.twoFactors[userID]
+	if !ok {
+		return nil
+	}
+	t.FailedAttempts = failedAttempts
+	t.LockedUntil = nil
+	if lockedUntil != nil {
+		until := *lockedUntil
+		t.LockedUntil = &until
+	}
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *twoFactorRepository) IncrementFailures(userID uuid.UUID) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.twoFactors[userID]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	t.FailedAttempts++
+	t.UpdatedAt = time.Now()
+	return t.FailedAttempts, nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []*models.UserRecoveryCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, c := range r.store.recoveryCodes {
+		if c.UserID == userID {
+			delete(r.store.recoveryCodes, id)
+		}
+	}
+
+	now := time.Now()
+	for _, code := range codes {
+		if code.ID == uuid.Nil {
+			code.ID = uuid.New()
+		}
+		if code.CreatedAt.IsZero() {
+			code.CreatedAt = now
+		}
+		code.UserID = userID
+		r.store.recoveryCodes[code.ID] = copyRecoveryCode(code)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, c := range r.store.recoveryCodes {
+		if c.UserID == userID && c.CodeHash == codeHash && c.UsedAt == nil {
+			at := usedAt
+			c.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, c := range r.store.recoveryCodes {
+		if c.UserID == userID && c.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func copyTwoFactor(t *models.UserTwoFactor) *models.UserTwoFactor {
+	c := *t
+	if t.EnabledAt != nil {
+		enabledAt := *t.EnabledAt
+		c.EnabledAt = &enabledAt
+	}
+	if t.LockedUntil != nil {
+		lockedUntil := *t.LockedUntil
+		c.LockedUntil = &lockedUntil
+	}
+	c.User = nil
+	return &c
+}
+
+func copyRecoveryCode(rc *models.UserRecoveryCode) *models.UserRecoveryCode {
+	c := *rc
+	if rc.UsedAt != nil {
+		usedAt := *rc.UsedAt
+		c.UsedAt = &usedAt
+	}
+	return &c
+}
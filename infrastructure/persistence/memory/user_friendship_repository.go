@@ -0,0 +1,154 @@
+// infrastructure/persistence/memory/user_friendship_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type userFriendshipRepository struct {
+	store *Store
+}
+
+// NewUserFriendshipRepository สร้าง UserFriendshipRepository ที่เก็บข้อมูลใน Store
+func NewUserFriendshipRepository(store *Store) repository.UserFriendshipRepository {
+	return &userFriendshipRepository{store: store}
+}
+
+func (r *userFriendshipRepository) Create(userFriendship *models.UserFriendship) error {
+	if userFriendship.ID == uuid.Nil {
+		userFriendship.ID = uuid.New()
+	}
+	if userFriendship.RequestedAt.IsZero() {
+		userFriendship.RequestedAt = time.Now()
+	}
+	if userFriendship.UpdatedAt.IsZero() {
+		userFriendship.UpdatedAt = time.Now()
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.friendships[userFriendship.ID] = copyFriendship(userFriendship)
+	return nil
+}
+
+func (r *userFriendshipRepository) FindByID(id uuid.UUID) (*models.UserFriendship, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	f, ok := r.store.friendships[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyFriendship(f), nil
+}
+
+func (r *userFriendshipRepository) Update(userFriendship *models.UserFriendship) error {
+	userFriendship.UpdatedAt = time.Now()
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.friendships[userFriendship.ID] = copyFriendship(userFriendship)
+	return nil
+}
+
+func (r *userFriendshipRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.friendships, id)
+	return nil
+}
+
+func (r *userFriendshipRepository) FindPendingRequestsByUserID(userID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return f.UserID == userID && f.Status == "pending"
+	}), nil
+}
+
+func (r *userFriendshipRepository) FindPendingRequestsByFriendID(friendID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return f.FriendID == friendID && f.Status == "pending"
+	}), nil
+}
+
+func (r *userFriendshipRepository) FindAcceptedFriendships(userID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return (f.UserID == userID || f.FriendID == userID) && f.Status == "accepted"
+	}), nil
+}
+
+func (r *userFriendshipRepository) FindByUserIDAndFriendID(userID, friendID uuid.UUID) (*models.UserFriendship, error) {
+	friendships := r.filter(func(f *models.UserFriendship) bool {
+		return f.UserID == userID && f.FriendID == friendID
+	})
+	if len(friendships) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return friendships[0], nil
+}
+
+func (r *userFriendshipRepository) FindByUserIDOrFriendID(userID, friendID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return (f.UserID == userID && f.FriendID == friendID) || (f.UserID == friendID && f.FriendID == userID)
+	}), nil
+}
+
+func (r *userFriendshipRepository) UpdateStatus(id uuid.UUID, status string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if f, ok := r.store.friendships[id]; ok {
+		f.Status = status
+		f.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *userFriendshipRepository) DeleteByUserIDAndFriendID(userID, friendID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, f := range r.store.friendships {
+		if (f.UserID == userID && f.FriendID == friendID) || (f.UserID == friendID && f.FriendID == userID) {
+			delete(r.store.friendships, id)
+		}
+	}
+	return nil
+}
+
+func (r *userFriendshipRepository) FindBlockedUsers(userID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return f.UserID == userID && f.Status == "blocked"
+	}), nil
+}
+
+func (r *userFriendshipRepository) FindBlockedByUsers(userID uuid.UUID) ([]*models.UserFriendship, error) {
+	return r.filter(func(f *models.UserFriendship) bool {
+		return f.FriendID == userID && f.Status == "blocked"
+	}), nil
+}
+
+// filter คืนความสัมพันธ์ที่ตรงเงื่อนไข เรียงตามเวลาที่ส่งคำขอ
+func (r *userFriendshipRepository) filter(match func(*models.UserFriendship) bool) []*models.UserFriendship {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	friendships := make([]*models.UserFriendship, 0)
+	for _, f := range r.store.friendships {
+		if match(f) {
+			friendships = append(friendships, copyFriendship(f))
+		}
+	}
+	sort.Slice(friendships, func(i, j int) bool {
+		return friendships[i].RequestedAt.Before(friendships[j].RequestedAt)
+	})
+	return friendships
+}
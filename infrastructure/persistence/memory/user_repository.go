@@ -0,0 +1,132 @@
+// infrastructure/persistence/memory/user_repository.go
+package memory
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+// NewUserRepository สร้าง UserRepository ที่เก็บข้อมูลใน Store
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+// Create สร้างผู้ใช้ใหม่ (username และ email ต้องไม่ซ้ำ เช่นเดียวกับ unique constraint)
+func (r *userRepository) Create(user *models.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	if user.Status == "" {
+		user.Status = "active"
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[user.ID]; ok {
+		return errors.New("duplicate key value violates unique constraint \"users_pkey\"")
+	}
+	for _, u := range r.store.users {
+		if u.Username == user.Username {
+			return errors.New("duplicate key value violates unique constraint \"users_username_key\"")
+		}
+		if user.Email != "" && u.Email == user.Email {
+			return errors.New("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+
+	r.store.users[user.ID] = copyUser(user)
+	return nil
+}
+
+// FindByUsername ค้นหาผู้ใช้จาก username
+func (r *userRepository) FindByUsername(username string) (*models.User, error) {
+	return r.findFirst(func(u *models.User) bool { return u.Username == username })
+}
+
+// FindByID ค้นหาผู้ใช้จาก ID
+func (r *userRepository) FindByID(id uuid.UUID) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return copyUser(u), nil
+}
+
+// FindByEmail ค้นหาผู้ใช้จาก email
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	users := r.filter(func(u *models.User) bool { return u.Email == email })
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// Update บันทึกข้อมูลผู้ใช้ทั้งหมด (เหมือน Save)
+func (r *userRepository) Update(user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.users[user.ID] = copyUser(user)
+	return nil
+}
+
+// SearchUsers ค้นหาผู้ใช้ที่ active จาก username หรือ display_name (ไม่สนตัวพิมพ์)
+func (r *userRepository) SearchUsers(query string, limit, offset int) ([]*models.User, int, error) {
+	q := strings.ToLower(query)
+	users := r.filter(func(u *models.User) bool {
+		return u.Status == "active" &&
+			(strings.Contains(strings.ToLower(u.Username), q) || strings.Contains(strings.ToLower(u.DisplayName), q))
+	})
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return paginate(users, limit, offset), len(users), nil
+}
+
+// SearchUsersExact ค้นหาผู้ใช้ที่ username หรือ display_name ตรงกันทุกตัวอักษร
+func (r *userRepository) SearchUsersExact(query string, limit, offset int) ([]*models.User, int64, error) {
+	users := r.filter(func(u *models.User) bool {
+		return u.Username == query || u.DisplayName == query
+	})
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.After(users[j].CreatedAt) })
+
+	return paginate(users, limit, offset), int64(len(users)), nil
+}
+
+func (r *userRepository) findFirst(match func(*models.User) bool) (*models.User, error) {
+	users := r.filter(match)
+	if len(users) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return users[0], nil
+}
+
+func (r *userRepository) filter(match func(*models.User) bool) []*models.User {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]*models.User, 0)
+	for _, u := range r.store.users {
+		if match(u) {
+			users = append(users, copyUser(u))
+		}
+	}
+	return users
+}
@@ -0,0 +1,129 @@
+// infrastructure/persistence/memory/user_session_repository.go
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type userSessionRepository struct {
+	store *Store
+}
+
+// NewUserSessionRepository สร้าง UserSessionRepository ที่เก็บข้อมูลใน Store
+func NewUserSessionRepository(store *Store) repository.UserSessionRepository {
+	return &userSessionRepository{store: store}
+}
+
+func (r *userSessionRepository) Create(session *models.UserSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = now
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.sessions[session.ID] = copySession(session)
+	return nil
+}
+
+func (r *userSessionRepository) FindByID(id uuid.UUID) (*models.UserSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	s, ok := r.store.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return copySession(s), nil
+}
+
+func (r *userSessionRepository) FindActiveByUserID(userID uuid.UUID, now time.Time) ([]*models.UserSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sessions := make([]*models.UserSession, 0)
+	for _, s := range r.store.sessions {
+		if s.UserID == userID && s.RevokedAt == nil && s.ExpiresAt.After(now) {
+			sessions = append(sessions, copySession(s))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (r *userSessionRepository) Touch(id uuid.UUID, ipAddress string, usedAt, expiresAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if s, ok := r.store.sessions[id]; ok {
+		s.LastUsedAt = usedAt
+		s.ExpiresAt = expiresAt
+		if ipAddress != "" {
+			s.IPAddress = ipAddress
+		}
+	}
+	return nil
+}
+
+func (r *userSessionRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if s, ok := r.store.sessions[id]; ok && s.RevokedAt == nil {
+		at := revokedAt
+		s.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *userSessionRepository) RevokeAllByUserID(userID, exceptID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0)
+	for id, s := range r.store.sessions {
+		if s.UserID != userID || s.RevokedAt != nil || id == exceptID {
+			continue
+		}
+		at := revokedAt
+		s.RevokedAt = &at
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *userSessionRepository) DeleteExpired(before time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, s := range r.store.sessions {
+		if s.ExpiresAt.Before(before) {
+			delete(r.store.sessions, id)
+		}
+	}
+	return nil
+}
+
+func copySession(s *models.UserSession) *models.UserSession {
+	c := *s
+	if s.RevokedAt != nil {
+		revokedAt := *s.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+	c.User = nil
+	return &c
+}
@@ -0,0 +1,107 @@
+// infrastructure/persistence/memory/verification_token_repository.go
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+)
+
+type verificationTokenRepository struct {
+	store *Store
+}
+
+// NewVerificationTokenRepository สร้าง VerificationTokenRepository ที่เก็บข้อมูลใน Store
+func NewVerificationTokenRepository(store *Store) repository.VerificationTokenRepository {
+	return &verificationTokenRepository{store: store}
+}
+
+func (r *verificationTokenRepository) Create(token *models.VerificationToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.verificationTokens[token.ID] = copyVerificationToken(token)
+	return nil
+}
+
+func (r *verificationTokenRepository) FindByTokenHash(purpose, tokenHash string) (*models.VerificationToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, t := range r.store.verificationTokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash {
+			return copyVerificationToken(t), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *verificationTokenRepository) MarkUsed(id uuid.UUID, usedAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t, ok := r.store.verificationTokens[id]
+	if !ok || t.UsedAt != nil {
+		return false, nil
+	}
+	at := usedAt
+	t.UsedAt = &at
+	return true, nil
+}
+
+func (r *verificationTokenRepository) InvalidateByUserID(userID uuid.UUID, purpose string, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, t := range r.store.verificationTokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			usedAt := at
+			t.UsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (r *verificationTokenRepository) CountIssuedSince(userID uuid.UUID, purpose string, since time.Time) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, t := range r.store.verificationTokens {
+		if t.UserID == userID && t.Purpose == purpose && !t.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *verificationTokenRepository) DeleteExpired(before time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, t := range r.store.verificationTokens {
+		if t.ExpiresAt.Before(before) {
+			delete(r.store.verificationTokens, id)
+		}
+	}
+	return nil
+}
+
+func copyVerificationToken(t *models.VerificationToken) *models.VerificationToken {
+	c := *t
+	if t.UsedAt != nil {
+		usedAt := *t.UsedAt
+		c.UsedAt = &usedAt
+	}
+	c.User = nil
+	return &c
+}
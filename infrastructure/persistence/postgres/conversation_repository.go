@@ -11,6 +11,7 @@ import (
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
 	"github.com/thizplus/gofiber-chat-api/domain/types"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type conversationRepository struct {
@@ -228,6 +229,50 @@ func (r *conversationRepository) SetMuteStatus(conversationID, userID uuid.UUID,
 	return nil
 }
 
+// SetSendRestriction จำกัดการส่งข้อความของสมาชิกถึงเวลาที่กำหนด (nil = ยกเลิก)
+func (r *conversationRepository) SetSendRestriction(conversationID, userID uuid.UUID, until *time.Time) error {
+	result := r.db.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("send_restricted_until", until)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("conversation member not found")
+	}
+	return nil
+}
+
+// ClaimSlowModeSlot บันทึก last_sent_at เมื่อข้อความล่าสุดเก่ากว่า interval (ป้องกันการส่งพร้อมกันหลุด slow mode)
+func (r *conversationRepository) ClaimSlowModeSlot(conversationID, userID uuid.UUID, now time.Time, interval time.Duration) (bool, error) {
+	return claimSlowModeSlot(r.db, conversationID, userID, now, interval)
+}
+
+// claimSlowModeSlot ตรวจและบันทึก last_sent_at ใน statement เดียว (ใช้ร่วมกับการสร้างข้อความใน transaction)
+func claimSlowModeSlot(db *gorm.DB, conversationID, userID uuid.UUID, now time.Time, interval time.Duration) (bool, error) {
+	result := db.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("last_sent_at IS NULL OR last_sent_at <= ?", now.Add(-interval)).
+		Update("last_sent_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateNotificationSettings บันทึกการตั้งค่าการแจ้งเตือนของสมาชิก
+func (r *conversationRepository) UpdateNotificationSettings(conversationID, userID uuid.UUID, settings types.JSONB) error {
+	result := r.db.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("notification_settings", settings)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("conversation member not found")
+	}
+	return nil
+}
+
 // SetHiddenStatus กำหนดสถานะการซ่อนการสนทนา
 func (r *conversationRepository) SetHiddenStatus(conversationID, userID uuid.UUID, isHidden bool) error {
 	updates := map[string]interface{}{
@@ -294,6 +339,82 @@ func (r *conversationRepository) RemoveMember(conversationID, userID uuid.UUID)
 	return nil
 }
 
+// LeaveGroup ลบสมาชิก โอน owner และปิดกลุ่มใน transaction เดียว
+// ล็อกแถวการสนทนาก่อน (AddMember ที่ทำพร้อมกันต้องรอเพราะ foreign key ขอ KEY SHARE ของแถวนี้)
+// แล้วล็อกแถวสมาชิกทั้งหมด (SELECT ... FOR UPDATE) ทำให้การออกพร้อมกันเห็นรายชื่อล่าสุดเสมอ
+func (r *conversationRepository) LeaveGroup(conversationID, userID uuid.UUID, successor func(remaining []*models.ConversationMember) *models.ConversationMember) (*uuid.UUID, bool, error) {
+	var newOwnerID *uuid.UUID
+	deactivated := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var conversation models.Conversation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&conversation, "id = ?", conversationID).Error; err != nil {
+			return err
+		}
+
+		var members []*models.ConversationMember
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("conversation_id = ?", conversationID).
+			Find(&members).Error; err != nil {
+			return err
+		}
+
+		var leaving *models.ConversationMember
+		remaining := make([]*models.ConversationMember, 0, len(members))
+		for _, m := range members {
+			if m.UserID == userID {
+				leaving = m
+			} else {
+				remaining = append(remaining, m)
+			}
+		}
+		if leaving == nil {
+			return errors.New("conversation member not found")
+		}
+
+		// โอนความเป็นเจ้าของก่อนลบ เพื่อไม่ให้กลุ่มไม่มี owner แม้เพียงชั่วขณะ
+		if leaving.Role == models.RoleOwner && len(remaining) > 0 {
+			next := successor(remaining)
+			if err := tx.Model(&models.ConversationMember{}).
+				Where("id = ?", next.ID).
+				Updates(map[string]interface{}{"role": models.RoleOwner, "is_admin": true}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Conversation{}).
+				Where("id = ?", conversationID).
+				Update("creator_id", next.UserID).Error; err != nil {
+				return err
+			}
+			id := next.UserID
+			newOwnerID = &id
+		}
+
+		if err := tx.Delete(&models.ConversationMember{}, "id = ?", leaving.ID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.ConversationMember{}).
+			Where("conversation_id = ?", conversationID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			if err := tx.Model(&models.Conversation{}).
+				Where("id = ?", conversationID).
+				Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+			deactivated = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return newOwnerID, deactivated, nil
+}
+
 // UpdateMemberAdmin อัพเดตสถานะแอดมินของสมาชิก
 func (r *conversationRepository) UpdateMemberAdmin(conversationID, userID uuid.UUID, isAdmin bool) error {
 	result := r.db.Model(&models.ConversationMember{}).
@@ -800,13 +921,15 @@ func (r *conversationRepository) GetUserConversationsWithFilter(userID uuid.UUID
 }
 
 // Update อัปเดตการสนทนาทั้งหมด
+// last_message_seq ถูกเลื่อนโดย MessageRepository เท่านั้น จึงไม่เขียนทับด้วยค่าที่โหลดมาก่อนหน้า
 func (r *conversationRepository) Update(conversation *models.Conversation) error {
-	return r.db.Save(conversation).Error
+	return r.db.Omit("last_message_seq").Save(conversation).Error
 }
 
 // UpdateMember อัปเดตข้อมูลสมาชิก
+// read watermark ถูกเลื่อนผ่าน MessageReadRepository เท่านั้น จึงไม่เขียนทับที่นี่
 func (r *conversationRepository) UpdateMember(member *models.ConversationMember) error {
-	return r.db.Save(member).Error
+	return r.db.Omit("last_read_message_id", "last_read_seq", "deleted_unread", "last_sent_at").Save(member).Error
 }
 
 // UnhideForAllMembers ยกเลิกการซ่อนการสนทนาสำหรับสมาชิกทุกคน
@@ -38,6 +38,21 @@ func (r *fileUploadRepository) FindByID(id uuid.UUID) (*models.FileUpload, error
 	return &upload, nil
 }
 
+// FindByURL finds a completed file upload by its public URL
+func (r *fileUploadRepository) FindByURL(url string) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	err := r.db.Where("url = ? AND status = ?", url, models.FileUploadStatusCompleted).
+		Order("completed_at DESC").
+		First(&upload).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("file upload not found")
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
 // FindByUserID finds all file uploads by user ID
 func (r *fileUploadRepository) FindByUserID(userID uuid.UUID, limit, offset int) ([]*models.FileUpload, error) {
 	var uploads []*models.FileUpload
@@ -0,0 +1,72 @@
+// infrastructure/persistence/postgres/group_ban_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type groupBanRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupBanRepository(db *gorm.DB) repository.GroupBanRepository {
+	return &groupBanRepository{db: db}
+}
+
+func (r *groupBanRepository) Upsert(ban *models.GroupBan) error {
+	if ban.ID == uuid.Nil {
+		ban.ID = uuid.New()
+	}
+	if ban.CreatedAt.IsZero() {
+		ban.CreatedAt = time.Now()
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"banned_by", "reason", "expires_at", "created_at"}),
+	}).Create(ban).Error
+	if err != nil {
+		return err
+	}
+
+	// ON CONFLICT ไม่คืน id ของแถวเดิม ต้องอ่านกลับมา
+	return r.db.Where("conversation_id = ? AND user_id = ?", ban.ConversationID, ban.UserID).First(ban).Error
+}
+
+func (r *groupBanRepository) FindActive(conversationID, userID uuid.UUID, now time.Time) (*models.GroupBan, error) {
+	var ban models.GroupBan
+	err := r.db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		First(&ban).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ban, nil
+}
+
+func (r *groupBanRepository) ListActive(conversationID uuid.UUID, now time.Time) ([]*models.GroupBan, error) {
+	var bans []*models.GroupBan
+	err := r.db.Where("conversation_id = ?", conversationID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Preload("User").
+		Order("created_at DESC").
+		Find(&bans).Error
+	return bans, err
+}
+
+func (r *groupBanRepository) Delete(conversationID, userID uuid.UUID, now time.Time) (bool, error) {
+	result := r.db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Delete(&models.GroupBan{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,195 @@
+// infrastructure/persistence/postgres/group_invite_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type groupInviteRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupInviteRepository(db *gorm.DB) repository.GroupInviteRepository {
+	return &groupInviteRepository{db: db}
+}
+
+func (r *groupInviteRepository) CreateInvite(invite *models.GroupInvite) error {
+	if invite.ID == uuid.Nil {
+		invite.ID = uuid.New()
+	}
+	if invite.CreatedAt.IsZero() {
+		invite.CreatedAt = time.Now()
+	}
+	return r.db.Create(invite).Error
+}
+
+func (r *groupInviteRepository) FindInviteByToken(token string) (*models.GroupInvite, error) {
+	var invite models.GroupInvite
+	if err := r.db.Where("token = ?", token).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *groupInviteRepository) FindInviteByID(id uuid.UUID) (*models.GroupInvite, error) {
+	var invite models.GroupInvite
+	if err := r.db.Where("id = ?", id).First(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *groupInviteRepository) ListInvites(conversationID uuid.UUID) ([]*models.GroupInvite, error) {
+	var invites []*models.GroupInvite
+	err := r.db.Where("conversation_id = ?", conversationID).
+		Order("created_at DESC").
+		Find(&invites).Error
+	return invites, err
+}
+
+func (r *groupInviteRepository) RevokeInvite(id, revokedBy uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.GroupInvite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": at,
+			"revoked_by": revokedBy,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *groupInviteRepository) JoinWithInvite(inviteID uuid.UUID, member *models.ConversationMember, now time.Time) (bool, error) {
+	joined := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// ตรวจเงื่อนไขและเพิ่ม use_count ใน statement เดียว เพื่อไม่ให้การใช้พร้อมกันเกิน max_uses
+		result := tx.Model(&models.GroupInvite{}).
+			Where("id = ? AND revoked_at IS NULL", inviteID).
+			Where("expires_at IS NULL OR expires_at > ?", now).
+			Where("max_uses = 0 OR use_count < max_uses").
+			Update("use_count", gorm.Expr("use_count + 1"))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		// เพิ่มสมาชิกไม่สำเร็จ (เช่นเป็นสมาชิกอยู่แล้ว) = rollback การนับ
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		joined = true
+		return nil
+	})
+	return joined, err
+}
+
+func (r *groupInviteRepository) CreateJoinRequest(request *models.GroupJoinRequest) error {
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	if request.Status == "" {
+		request.Status = models.JoinRequestStatusPending
+	}
+	return r.db.Create(request).Error
+}
+
+func (r *groupInviteRepository) FindJoinRequestByID(id uuid.UUID) (*models.GroupJoinRequest, error) {
+	var request models.GroupJoinRequest
+	if err := r.db.Where("id = ?", id).First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *groupInviteRepository) FindPendingJoinRequest(conversationID, userID uuid.UUID) (*models.GroupJoinRequest, error) {
+	var request models.GroupJoinRequest
+	err := r.db.Where("conversation_id = ? AND user_id = ? AND status = ?",
+		conversationID, userID, models.JoinRequestStatusPending).
+		First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *groupInviteRepository) ListJoinRequests(conversationID uuid.UUID, status string) ([]*models.GroupJoinRequest, error) {
+	var requests []*models.GroupJoinRequest
+	query := r.db.Where("conversation_id = ?", conversationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Preload("User").Order("created_at ASC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *groupInviteRepository) ReviewJoinRequest(id uuid.UUID, status string, reviewedBy uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&models.GroupJoinRequest{}).
+		Where("id = ? AND status = ?", id, models.JoinRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewedBy,
+			"reviewed_at": at,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *groupInviteRepository) ApproveJoinRequest(request *models.GroupJoinRequest, member *models.ConversationMember, reviewedBy uuid.UUID, at time.Time) (bool, bool, error) {
+	approved, joined := false, false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.GroupJoinRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.JoinRequestStatusPending).
+			Updates(map[string]interface{}{
+				"status":      models.JoinRequestStatusApproved,
+				"reviewed_by": reviewedBy,
+				"reviewed_at": at,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		approved = true
+
+		// ผู้ขออาจถูกเพิ่มเข้ากลุ่มด้วยวิธีอื่นระหว่างรออนุมัติ
+		var count int64
+		if err := tx.Model(&models.ConversationMember{}).
+			Where("conversation_id = ? AND user_id = ?", member.ConversationID, member.UserID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		// นับการใช้ลิงก์ตอนอนุมัติ ตรวจเฉพาะ max_uses
+		if request.InviteID != nil {
+			result := tx.Model(&models.GroupInvite{}).
+				Where("id = ?", *request.InviteID).
+				Where("max_uses = 0 OR use_count < max_uses").
+				Update("use_count", gorm.Expr("use_count + 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("invite link has reached its usage limit")
+			}
+		}
+
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		joined = true
+		return nil
+	})
+	if err != nil {
+		return false, false, err
+	}
+	return approved, joined, nil
+}
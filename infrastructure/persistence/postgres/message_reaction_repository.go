@@ -0,0 +1,108 @@
+// infrastructure/persistence/postgres/message_reaction_repository.go
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type messageReactionRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageReactionRepository creates a new message reaction repository
+func NewMessageReactionRepository(db *gorm.DB) repository.MessageReactionRepository {
+	return &messageReactionRepository{db: db}
+}
+
+// Create inserts a reaction, returning false if the same reaction already exists
+func (r *messageReactionRepository) Create(ctx context.Context, reaction *models.MessageReaction) (bool, error) {
+	// A concurrent identical reaction hits the unique index; keep the existing row
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "message_id"}, {Name: "user_id"}, {Name: "emoji"}},
+			DoNothing: true,
+		}).
+		Create(reaction)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete deletes a reaction by message_id, user_id and emoji
+func (r *messageReactionRepository) Delete(ctx context.Context, messageID, userID uuid.UUID, emoji string) error {
+	return r.db.WithContext(ctx).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&models.MessageReaction{}).Error
+}
+
+// Exists checks if a user already reacted with an emoji
+func (r *messageReactionRepository) Exists(ctx context.Context, messageID, userID uuid.UUID, emoji string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MessageReaction{}).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountByUser counts the reactions a user has on a message
+func (r *messageReactionRepository) CountByUser(ctx context.Context, messageID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MessageReaction{}).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetByMessageID gets all reactions on a message
+func (r *messageReactionRepository) GetByMessageID(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error) {
+	var reactions []*models.MessageReaction
+	err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("created_at ASC").
+		Find(&reactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// GetByMessageIDs gets all reactions on the given messages in one query
+func (r *messageReactionRepository) GetByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]*models.MessageReaction, error) {
+	var reactions []*models.MessageReaction
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("message_id IN ?", messageIDs).
+		Order("created_at ASC").
+		Find(&reactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// GetByMessageIDWithUsers gets all reactions on a message including the reacting users
+func (r *messageReactionRepository) GetByMessageIDWithUsers(ctx context.Context, messageID uuid.UUID) ([]*models.MessageReaction, error) {
+	var reactions []*models.MessageReaction
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("message_id = ?", messageID).
+		Order("created_at ASC").
+		Find(&reactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// DeleteAllByMessageID deletes all reactions for a message
+func (r *messageReactionRepository) DeleteAllByMessageID(ctx context.Context, messageID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Delete(&models.MessageReaction{}).Error
+}
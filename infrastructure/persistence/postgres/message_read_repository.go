@@ -2,6 +2,8 @@
 package postgres
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
@@ -9,6 +11,7 @@ import (
 )
 
 // messageReadRepository เป็น implementation ของ MessageReadRepository
+// สถานะการอ่านคำนวณจาก read watermark ใน conversation_members (ตาราง message_reads ไม่ถูกเขียนแล้ว)
 type messageReadRepository struct {
 	db *gorm.DB
 }
@@ -20,47 +23,69 @@ func NewMessageReadRepository(db *gorm.DB) repository.MessageReadRepository {
 	}
 }
 
-// CreateRead สร้างบันทึกการอ่านข้อความ (ป้องกันการซ้ำซ้อน)
-func (r *messageReadRepository) CreateRead(read *models.MessageRead) error {
-	// ใช้ Raw SQL เพื่อทำ "INSERT ... ON CONFLICT DO NOTHING"
-	return r.db.Exec(`
-    INSERT INTO message_reads (id, message_id, user_id, read_at)
-    VALUES ($1, $2, $3, $4)
-    ON CONFLICT (message_id, user_id) DO NOTHING
-`, read.ID, read.MessageID, read.UserID, read.ReadAt).Error
+// AdvanceWatermark เลื่อน watermark ของสมาชิกไปข้างหน้าเท่านั้น
+func (r *messageReadRepository) AdvanceWatermark(conversationID, userID, messageID uuid.UUID, sequence int64, readAt time.Time) (bool, error) {
+	result := advanceReadWatermark(r.db, conversationID, userID, messageID, sequence, readAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
-// GetByMessageID ดึงรายการการอ่านของข้อความ
-func (r *messageReadRepository) GetByMessageID(messageID uuid.UUID) ([]*models.MessageRead, error) {
-	var reads []*models.MessageRead
-	err := r.db.Where("message_id = ?", messageID).
-		Order("read_at ASC").
-		Find(&reads).Error
+// CountUnread คำนวณจาก sequence ของการสนทนาและ watermark ของสมาชิก (O(1))
+// ข้อความที่ถูกลบหรือหมดอายุหลัง watermark ถูกหักออกด้วย deleted_unread
+func (r *messageReadRepository) CountUnread(conversationID, userID uuid.UUID) (int, error) {
+	var unread int64
+	err := r.db.Raw(`
+		SELECT GREATEST(c.last_message_seq - cm.last_read_seq - cm.deleted_unread, 0)
+		FROM conversation_members cm
+		JOIN conversations c ON c.id = cm.conversation_id
+		WHERE cm.conversation_id = ? AND cm.user_id = ?
+	`, conversationID, userID).Scan(&unread).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(unread), nil
+}
 
+// GetUnreadCounts ดึงจำนวนที่ยังไม่ได้อ่านของทุกการสนทนาใน query เดียว
+func (r *messageReadRepository) GetUnreadCounts(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ConversationID uuid.UUID
+		Unread         int64
+	}
+	err := r.db.Raw(`
+		SELECT cm.conversation_id, c.last_message_seq - cm.last_read_seq - cm.deleted_unread AS unread
+		FROM conversation_members cm
+		JOIN conversations c ON c.id = cm.conversation_id
+		WHERE cm.user_id = ? AND c.is_active = true AND c.last_message_seq > cm.last_read_seq + cm.deleted_unread
+	`, userID).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
 
-	return reads, nil
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.ConversationID] = int(row.Unread)
+	}
+	return counts, nil
+}
+
+// GetByMessageID ดึงรายการการอ่านของข้อความ (สมาชิกที่ watermark ผ่านข้อความแล้ว)
+func (r *messageReadRepository) GetByMessageID(messageID uuid.UUID) ([]*models.MessageRead, error) {
+	return messageReadsFromWatermarks(r.db, messageID)
 }
 
 // GetUnreadMessageIDs ดึงรายการ ID ของข้อความที่ยังไม่ได้อ่าน
 func (r *messageReadRepository) GetUnreadMessageIDs(conversationID, userID uuid.UUID) ([]uuid.UUID, error) {
-	// ดึงข้อความทั้งหมดในการสนทนาที่ไม่ได้ส่งโดยผู้ใช้นี้
-	subQuery := r.db.Model(&models.Message{}).
-		Select("id").
-		Where("conversation_id = ? AND sender_id != ? AND is_deleted = ?",
-			conversationID, userID, false)
-
-	// ดึงข้อความที่ผู้ใช้ยังไม่ได้อ่าน
 	var unreadMessageIDs []uuid.UUID
 
 	err := r.db.Model(&models.Message{}).
-		Select("messages.id").
-		Where("messages.id IN (?)", subQuery).
-		Joins("LEFT JOIN message_reads ON messages.id = message_reads.message_id AND message_reads.user_id = ?", userID).
-		Where("message_reads.id IS NULL").
-		Pluck("messages.id", &unreadMessageIDs).Error
+		Where(`conversation_id = ? AND sender_id != ? AND is_deleted = ? AND thread_root_id IS NULL AND sequence > COALESCE(
+			(SELECT last_read_seq FROM conversation_members WHERE conversation_id = ? AND user_id = ?), 0)`,
+			conversationID, userID, false, conversationID, userID).
+		Order("sequence ASC, created_at ASC").
+		Pluck("id", &unreadMessageIDs).Error
 
 	if err != nil {
 		return nil, err
@@ -69,22 +94,102 @@ func (r *messageReadRepository) GetUnreadMessageIDs(conversationID, userID uuid.
 	return unreadMessageIDs, nil
 }
 
-// DeleteRead ลบบันทึกการอ่านข้อความ
-func (r *messageReadRepository) DeleteRead(messageID, userID uuid.UUID) error {
-	return r.db.Where("message_id = ? AND user_id = ?", messageID, userID).
-		Delete(&models.MessageRead{}).Error
-}
-
 // CountReads นับจำนวนการอ่านของข้อความ
 func (r *messageReadRepository) CountReads(messageID uuid.UUID) (int, error) {
-	var count int64
-	err := r.db.Model(&models.MessageRead{}).
-		Where("message_id = ?", messageID).
-		Count(&count).Error
-
+	reads, err := messageReadsFromWatermarks(r.db, messageID)
 	if err != nil {
 		return 0, err
 	}
+	return len(reads), nil
+}
+
+// ============ watermark helpers (ใช้ร่วมกับ messageRepository) ============
+
+// advanceReadWatermark อัปเดต watermark เมื่อ sequence ใหม่มากกว่าเดิม
+// deleted_unread ถูกนับใหม่เฉพาะข้อความที่ถูกลบหลัง watermark ใหม่ (ข้อความที่ผ่านไปแล้วไม่ต้องหักอีก)
+func advanceReadWatermark(db *gorm.DB, conversationID, userID, messageID uuid.UUID, sequence int64, readAt time.Time) *gorm.DB {
+	return db.Model(&models.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_seq < ?", conversationID, userID, sequence).
+		Updates(map[string]interface{}{
+			"last_read_seq":        sequence,
+			"last_read_message_id": messageID,
+			"last_read_at":         readAt,
+			"deleted_unread": gorm.Expr(`(SELECT COUNT(*) FROM messages
+				WHERE conversation_id = ? AND is_deleted = true AND sender_id IS NOT NULL
+					AND thread_root_id IS NULL AND sequence > ?)`, conversationID, sequence),
+		})
+}
+
+// countDeletedUnread เพิ่ม deleted_unread ของสมาชิกที่ยังไม่ได้อ่านข้อความที่เพิ่งถูกลบ
+// เฉพาะข้อความผู้ใช้ใน timeline (ข้อความระบบและ thread ไม่ได้เพิ่ม last_message_seq)
+func countDeletedUnread(tx *gorm.DB, messageID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE conversation_members cm SET deleted_unread = cm.deleted_unread + 1
+		FROM messages m
+		WHERE m.id = ? AND m.sender_id IS NOT NULL AND m.thread_root_id IS NULL AND m.sequence > 0
+			AND cm.conversation_id = m.conversation_id AND cm.last_read_seq < m.sequence
+	`, messageID).Error
+}
 
-	return int(count), nil
+// assignMessageSequences กำหนด Sequence ก่อน insert (ต้องเรียกใน transaction เดียวกับการ insert)
+// UPDATE ... RETURNING ล็อกแถวของการสนทนาไว้จนจบ transaction ข้อความที่ส่งพร้อมกันจึงได้ค่าไม่ซ้ำ
+func assignMessageSequences(tx *gorm.DB, messages []*models.Message) error {
+	for _, message := range messages {
+		if message.ThreadRootID != nil {
+			message.Sequence = 0
+			continue
+		}
+
+		query := "SELECT last_message_seq FROM conversations WHERE id = ?"
+		if message.SenderID != nil {
+			query = "UPDATE conversations SET last_message_seq = last_message_seq + 1 WHERE id = ? RETURNING last_message_seq"
+		}
+		if err := tx.Raw(query, message.ConversationID).Scan(&message.Sequence).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceSenderWatermarks ผู้ส่งถือว่าอ่านข้อความของตัวเองแล้ว (และทุกข้อความก่อนหน้า)
+func advanceSenderWatermarks(tx *gorm.DB, messages []*models.Message) error {
+	for _, message := range messages {
+		if message.SenderID == nil || message.Sequence == 0 || message.ThreadRootID != nil {
+			continue
+		}
+		if err := advanceReadWatermark(tx, message.ConversationID, *message.SenderID, message.ID, message.Sequence, message.CreatedAt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// messageReadsFromWatermarks สร้างรายการการอ่านจากสมาชิกที่ watermark >= sequence ของข้อความ
+// ข้อความใน thread ไม่มี sequence จึงไม่มีรายการการอ่าน
+func messageReadsFromWatermarks(db *gorm.DB, messageID uuid.UUID) ([]*models.MessageRead, error) {
+	var members []*models.ConversationMember
+	err := db.Raw(`
+		SELECT cm.*
+		FROM messages m
+		JOIN conversation_members cm ON cm.conversation_id = m.conversation_id
+		WHERE m.id = ? AND m.thread_root_id IS NULL AND m.sequence > 0 AND cm.last_read_seq >= m.sequence
+		ORDER BY cm.last_read_at ASC NULLS FIRST
+	`, messageID).Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reads := make([]*models.MessageRead, 0, len(members))
+	for _, member := range members {
+		read := &models.MessageRead{
+			ID:        member.ID,
+			MessageID: messageID,
+			UserID:    member.UserID,
+		}
+		if member.LastReadAt != nil {
+			read.ReadAt = *member.LastReadAt
+		}
+		reads = append(reads, read)
+	}
+	return reads, nil
 }
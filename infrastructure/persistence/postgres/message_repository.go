@@ -2,14 +2,18 @@
 package postgres
 
 import (
+	"encoding/json"
 	"errors"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type messageRepository struct {
@@ -38,13 +42,13 @@ func (r *messageRepository) GetByID(id uuid.UUID) (*models.Message, error) {
 // GetMessagesByConversationID ดึงข้อความทั้งหมดในการสนทนา
 func (r *messageRepository) GetMessagesByConversationID(conversationID uuid.UUID, limit, offset int) ([]*models.Message, int64, error) {
 	var count int64
-	if err := r.db.Model(&models.Message{}).Where("conversation_id = ?", conversationID).Count(&count).Error; err != nil {
+	if err := r.db.Model(&models.Message{}).Where("conversation_id = ? AND thread_root_id IS NULL", conversationID).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
 	var messages []*models.Message
-	// Fetch ข้อความล่าสุดก่อน (DESC) แล้วค่อย reverse เป็น ASC
-	if err := r.db.Where("conversation_id = ?", conversationID).
+	// Fetch ข้อความล่าสุดก่อน (DESC) แล้วค่อย reverse เป็น ASC (ไม่รวมข้อความใน thread)
+	if err := r.db.Where("conversation_id = ? AND thread_root_id IS NULL", conversationID).
 		Order("created_at DESC"). // ดึงข้อความล่าสุดก่อน
 		Limit(limit).
 		Offset(offset).
@@ -60,14 +64,95 @@ func (r *messageRepository) GetMessagesByConversationID(conversationID uuid.UUID
 	return messages, count, nil
 }
 
-// Create สร้างข้อความใหม่
+// Create สร้างข้อความใหม่ พร้อม sequence และ read watermark ของผู้ส่ง
 func (r *messageRepository) Create(message *models.Message) error {
-	return r.db.Create(message).Error
+	return r.BulkCreate([]*models.Message{message})
 }
 
 // BulkCreate สร้างหลายข้อความพร้อมกัน (สำหรับ Album/Bulk Upload)
 func (r *messageRepository) BulkCreate(messages []*models.Message) error {
-	return r.db.CreateInBatches(messages, 100).Error
+	_, err := r.createMessages(messages, nil)
+	return err
+}
+
+// CreateWithSlowMode จอง slot ของ slow mode แล้วสร้างข้อความใน transaction เดียว (สร้างไม่สำเร็จ = ไม่เสีย slot)
+func (r *messageRepository) CreateWithSlowMode(messages []*models.Message, senderID uuid.UUID, interval time.Duration, now time.Time) (bool, error) {
+	if len(messages) == 0 {
+		return true, nil
+	}
+	conversationID := messages[0].ConversationID
+	return r.createMessages(messages, func(tx *gorm.DB) (bool, error) {
+		return claimSlowModeSlot(tx, conversationID, senderID, now, interval)
+	})
+}
+
+// createMessages สร้างข้อความใน transaction เดียว โดยเรียก claim ก่อน (nil = ไม่ต้องจอง) คืนค่า false ถ้าจองไม่ได้
+func (r *messageRepository) createMessages(messages []*models.Message, claim func(tx *gorm.DB) (bool, error)) (bool, error) {
+	for _, message := range messages {
+		message.SearchDocument = messageSearchDocument(message.Content)
+	}
+	if err := r.applyMessageExpiry(messages); err != nil {
+		return false, err
+	}
+
+	claimed := true
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if claim != nil {
+			ok, err := claim(tx)
+			if err != nil || !ok {
+				claimed = false
+				return err
+			}
+		}
+		if err := assignMessageSequences(tx, messages); err != nil {
+			return err
+		}
+		if err := tx.CreateInBatches(messages, 100).Error; err != nil {
+			return err
+		}
+		return advanceSenderWatermarks(tx, messages)
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// applyMessageExpiry กำหนด ExpiresAt ตาม message_ttl_seconds ของการสนทนา (ข้อความหายไปอัตโนมัติ)
+func (r *messageRepository) applyMessageExpiry(messages []*models.Message) error {
+	ttls := make(map[uuid.UUID]int)
+	for _, message := range messages {
+		if message.ExpiresAt != nil {
+			continue
+		}
+
+		ttl, ok := ttls[message.ConversationID]
+		if !ok {
+			if err := r.db.Model(&models.Conversation{}).
+				Where("id = ?", message.ConversationID).
+				Select("message_ttl_seconds").
+				Scan(&ttl).Error; err != nil {
+				return err
+			}
+			ttls[message.ConversationID] = ttl
+		}
+		if ttl <= 0 {
+			continue
+		}
+
+		createdAt := message.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		expiresAt := createdAt.Add(time.Duration(ttl) * time.Second)
+		message.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// messageSearchDocument สร้าง tsvector literal สำหรับ full-text search ของข้อความ
+func messageSearchDocument(content string) string {
+	return textsearch.Document(textsearch.Field{Text: content})
 }
 
 // GetMessagesByAlbumID ดึงข้อความทั้งหมดในอัลบั้มเดียวกัน
@@ -84,14 +169,48 @@ func (r *messageRepository) GetMessagesByAlbumID(albumID string) ([]*models.Mess
 func (r *messageRepository) Update(message *models.Message) error {
 	// ใช้ Updates แทน Save เพื่อหลีกเลี่ยง nil pointer ใน AlbumFiles
 	// Updates จะอัปเดตเฉพาะ non-zero fields
+	if message.Content != "" {
+		message.SearchDocument = messageSearchDocument(message.Content)
+	}
 	return r.db.Model(&models.Message{}).Where("id = ?", message.ID).Updates(message).Error
 }
 
 // UpdateFields อัพเดตเฉพาะ fields ที่ระบุ
 func (r *messageRepository) UpdateFields(messageID uuid.UUID, updates map[string]interface{}) error {
+	// ถ้ามีการแก้ไข content ต้องสร้าง search document ใหม่ด้วย
+	if content, ok := updates["content"]; ok {
+		text, _ := content.(string)
+		updates["search_document"] = messageSearchDocument(text)
+	}
 	return r.db.Model(&models.Message{}).Where("id = ?", messageID).Updates(updates).Error
 }
 
+// MarkDeleted soft delete แบบ atomic (UPDATE ... WHERE is_deleted = false)
+// ทำให้การลบข้อความเดียวกันพร้อมกันหลาย instance มีผู้ชนะเพียงรายเดียว
+// ผู้ชนะหักข้อความออกจาก unread ของสมาชิกที่ยังไม่ได้อ่านใน transaction เดียวกัน
+func (r *messageRepository) MarkDeleted(messageID uuid.UUID, updates map[string]interface{}) (bool, error) {
+	if content, ok := updates["content"]; ok {
+		text, _ := content.(string)
+		updates["search_document"] = messageSearchDocument(text)
+	}
+
+	marked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Message{}).
+			Where("id = ? AND is_deleted = ?", messageID, false).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		marked = true
+		return countDeletedUnread(tx, messageID)
+	})
+	if err != nil {
+		return false, err
+	}
+	return marked, nil
+}
+
 // Delete ลบข้อความ (soft delete)
 func (r *messageRepository) Delete(id uuid.UUID) error {
 	result := r.db.Model(&models.Message{}).
@@ -99,6 +218,7 @@ func (r *messageRepository) Delete(id uuid.UUID) error {
 		Updates(map[string]interface{}{
 			"is_deleted":          true,
 			"content":             nil,
+			"search_document":     "",
 			"media_url":           nil,
 			"media_thumbnail_url": nil,
 			"metadata":            "{}",
@@ -146,93 +266,47 @@ func (r *messageRepository) GetDeleteHistory(messageID uuid.UUID) ([]*models.Mes
 	return history, nil
 }
 
-// MarkAsRead ทำเครื่องหมายว่าข้อความถูกอ่านแล้ว
+// MarkAsRead เลื่อน read watermark ของผู้ใช้ไปที่ข้อความ
 func (r *messageRepository) MarkAsRead(messageID, userID uuid.UUID, readAt time.Time) error {
-	// ตรวจสอบว่ามีการอ่านแล้วหรือไม่
-	var count int64
-	if err := r.db.Model(&models.MessageRead{}).
-		Where("message_id = ? AND user_id = ?", messageID, userID).
-		Count(&count).Error; err != nil {
+	message, err := r.GetByID(messageID)
+	if err != nil || message == nil || message.Sequence == 0 {
 		return err
 	}
-
-	if count > 0 {
-		return nil // มีการอ่านแล้ว ไม่ต้องทำอะไร
-	}
-
-	// สร้างบันทึกการอ่าน
-	read := models.MessageRead{
-		ID:        uuid.New(),
-		MessageID: messageID,
-		UserID:    userID,
-		ReadAt:    readAt,
-	}
-
-	return r.db.Create(&read).Error
+	return advanceReadWatermark(r.db, message.ConversationID, userID, message.ID, message.Sequence, readAt).Error
 }
 
-// GetReads ดึงรายการการอ่านข้อความ
+// GetReads ดึงรายการการอ่านข้อความ (สมาชิกที่ watermark ผ่านข้อความแล้ว)
 func (r *messageRepository) GetReads(messageID uuid.UUID) ([]*models.MessageRead, error) {
-	var reads []*models.MessageRead
-	if err := r.db.Where("message_id = ?", messageID).
-		Order("read_at ASC").
-		Find(&reads).Error; err != nil {
-		return nil, err
-	}
-	return reads, nil
+	return messageReadsFromWatermarks(r.db, messageID)
 }
 
 // IsMessageRead ตรวจสอบว่าข้อความถูกอ่านโดยผู้ใช้แล้วหรือไม่
 func (r *messageRepository) IsMessageRead(messageID, userID uuid.UUID) (bool, error) {
 	var count int64
-	if err := r.db.Model(&models.MessageRead{}).
-		Where("message_id = ? AND user_id = ?", messageID, userID).
-		Count(&count).Error; err != nil {
+	if err := r.db.Raw(`
+		SELECT COUNT(*)
+		FROM messages m
+		JOIN conversation_members cm ON cm.conversation_id = m.conversation_id AND cm.user_id = ?
+		WHERE m.id = ? AND m.sequence > 0 AND cm.last_read_seq >= m.sequence
+	`, userID, messageID).Scan(&count).Error; err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-// MarkAllAsRead ทำเครื่องหมายว่าข้อความทั้งหมดในการสนทนาถูกอ่านแล้ว
+// MarkAllAsRead เลื่อน read watermark ไปที่ข้อความล่าสุดของการสนทนา
 func (r *messageRepository) MarkAllAsRead(conversationID, userID uuid.UUID, readAt time.Time) error {
-	// ดึงรายการข้อความที่ยังไม่ได้อ่าน
-	rows, err := r.db.Raw(`
-		SELECT m.id 
-		FROM messages m 
-		LEFT JOIN message_reads mr ON m.id = mr.message_id AND mr.user_id = ? 
-		WHERE m.conversation_id = ? AND m.sender_id != ? AND mr.id IS NULL AND m.is_deleted = false
-	`, userID, conversationID, userID).Rows()
-
+	var latest models.Message
+	err := r.db.Where("conversation_id = ? AND thread_root_id IS NULL AND sequence > 0", conversationID).
+		Order("sequence DESC, created_at DESC").
+		First(&latest).Error
 	if err != nil {
-		return err
-	}
-	defer rows.Close()
-
-	var messageIDs []uuid.UUID
-	for rows.Next() {
-		var id uuid.UUID
-		if err := rows.Scan(&id); err != nil {
-			return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
 		}
-		messageIDs = append(messageIDs, id)
-	}
-
-	if len(messageIDs) == 0 {
-		return nil // ไม่มีข้อความที่ต้องมาร์ค
-	}
-
-	// สร้างบันทึกการอ่านเป็นชุด
-	reads := make([]models.MessageRead, 0, len(messageIDs))
-	for _, messageID := range messageIDs {
-		reads = append(reads, models.MessageRead{
-			ID:        uuid.New(),
-			MessageID: messageID,
-			UserID:    userID,
-			ReadAt:    readAt,
-		})
+		return err
 	}
-
-	return r.db.CreateInBatches(reads, 100).Error
+	return advanceReadWatermark(r.db, conversationID, userID, latest.ID, latest.Sequence, readAt).Error
 }
 
 // IsSender ตรวจสอบว่าผู้ใช้เป็นผู้ส่งข้อความหรือไม่
@@ -276,7 +350,7 @@ func (r *messageRepository) UpdateConversationLastMessage(conversationID uuid.UU
 // GetLastMessageByConversation ดึงข้อความล่าสุดของการสนทนา
 func (r *messageRepository) GetLastMessageByConversation(conversationID uuid.UUID) (*models.Message, error) {
 	var message models.Message
-	err := r.db.Where("conversation_id = ?", conversationID).
+	err := r.db.Where("conversation_id = ? AND thread_root_id IS NULL", conversationID).
 		Order("created_at DESC").
 		First(&message).Error
 
@@ -292,7 +366,7 @@ func (r *messageRepository) GetLastMessageByConversation(conversationID uuid.UUI
 // GetLastNonDeletedMessageByConversation ดึงข้อความล่าสุดที่ไม่ถูกลบของการสนทนา
 func (r *messageRepository) GetLastNonDeletedMessageByConversation(conversationID uuid.UUID) (*models.Message, error) {
 	var message models.Message
-	err := r.db.Where("conversation_id = ? AND is_deleted = ?", conversationID, false).
+	err := r.db.Where("conversation_id = ? AND is_deleted = ? AND thread_root_id IS NULL", conversationID, false).
 		Order("created_at DESC").
 		First(&message).Error
 
@@ -316,7 +390,7 @@ func (r *messageRepository) GetMessagesBefore(conversationID, messageID uuid.UUI
 
 	// ดึงข้อความที่เก่ากว่าข้อความเป้าหมาย โดยเรียงจากใหม่ไปเก่า (DESC) ก่อน
 	// ใช้ composite cursor (created_at + id) เพื่อป้องกัน overlap เมื่อมี messages ที่มี timestamp เดียวกัน
-	if err := r.db.Where("conversation_id = ? AND (created_at < ? OR (created_at = ? AND id < ?))",
+	if err := r.db.Where("conversation_id = ? AND thread_root_id IS NULL AND (created_at < ? OR (created_at = ? AND id < ?))",
 		conversationID, targetMessage.CreatedAt, targetMessage.CreatedAt, messageID).
 		Order("created_at DESC, id DESC"). // Query DESC ก่อน
 		Limit(limit).
@@ -343,7 +417,7 @@ func (r *messageRepository) GetMessagesAfter(conversationID, messageID uuid.UUID
 
 	// ดึงข้อความที่ใหม่กว่าข้อความเป้าหมาย โดยเรียงจากเก่าไปใหม่ (ASC)
 	// ใช้ composite cursor (created_at + id) เพื่อป้องกัน overlap เมื่อมี messages ที่มี timestamp เดียวกัน
-	if err := r.db.Where("conversation_id = ? AND (created_at > ? OR (created_at = ? AND id > ?))",
+	if err := r.db.Where("conversation_id = ? AND thread_root_id IS NULL AND (created_at > ? OR (created_at = ? AND id > ?))",
 		conversationID, targetMessage.CreatedAt, targetMessage.CreatedAt, messageID).
 		Order("created_at ASC, id ASC"). // ✅ Query ASC (เก่า → ใหม่)
 		Limit(limit).
@@ -357,7 +431,7 @@ func (r *messageRepository) GetMessagesAfter(conversationID, messageID uuid.UUID
 // CountAllMessages นับจำนวนข้อความทั้งหมดในการสนทนา
 func (r *messageRepository) CountAllMessages(conversationID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Message{}).Where("conversation_id = ?", conversationID).Count(&count).Error
+	err := r.db.Model(&models.Message{}).Where("conversation_id = ? AND thread_root_id IS NULL", conversationID).Count(&count).Error
 	return count, err
 }
 
@@ -365,8 +439,8 @@ func (r *messageRepository) CountAllMessages(conversationID uuid.UUID) (int64, e
 func (r *messageRepository) GetMessagesAfterTime(conversationID uuid.UUID, afterTime time.Time, excludeUserID uuid.UUID) ([]*models.Message, error) {
 	var messages []*models.Message
 
-	// ดึงข้อความที่สร้างหลังเวลาที่กำหนด ไม่ใช่ของผู้ใช้ที่กำหนด และไม่ถูกลบ
-	err := r.db.Where("conversation_id = ? AND created_at > ? AND sender_id != ? AND is_deleted = ?",
+	// ดึงข้อความที่สร้างหลังเวลาที่กำหนด ไม่ใช่ของผู้ใช้ที่กำหนด และไม่ถูกลบ (ไม่รวมข้อความใน thread)
+	err := r.db.Where("conversation_id = ? AND created_at > ? AND sender_id != ? AND is_deleted = ? AND thread_root_id IS NULL",
 		conversationID, afterTime, excludeUserID, false).
 		Find(&messages).Error
 
@@ -376,8 +450,8 @@ func (r *messageRepository) GetMessagesAfterTime(conversationID uuid.UUID, after
 func (r *messageRepository) GetAllUnreadMessages(conversationID uuid.UUID, excludeUserID uuid.UUID) ([]*models.Message, error) {
 	var messages []*models.Message
 
-	// ดึงข้อความทั้งหมดในการสนทนาที่ไม่ใช่ของผู้ใช้ที่กำหนด และไม่ถูกลบ
-	err := r.db.Where("conversation_id = ? AND sender_id != ? AND is_deleted = ?",
+	// ดึงข้อความทั้งหมดในการสนทนาที่ไม่ใช่ของผู้ใช้ที่กำหนด และไม่ถูกลบ (ไม่รวมข้อความใน thread)
+	err := r.db.Where("conversation_id = ? AND sender_id != ? AND is_deleted = ? AND thread_root_id IS NULL",
 		conversationID, excludeUserID, false).
 		Find(&messages).Error
 
@@ -643,6 +717,7 @@ func (r *messageRepository) FindByDateRange(conversationID uuid.UUID, startDate,
 
 // SearchMessages ค้นหาข้อความโดยใช้ full-text search (CURSOR-BASED)
 // userID ใช้สำหรับ filter เฉพาะ conversations ที่ user เป็นสมาชิก
+// sortBy = "recent" (cursor เป็น message ID) หรือ "relevance" (cursor เป็น offset)
 func (r *messageRepository) SearchMessages(
 	searchQuery string,
 	conversationID *uuid.UUID,
@@ -650,14 +725,20 @@ func (r *messageRepository) SearchMessages(
 	limit int,
 	cursor *string,
 	direction string,
+	sortBy string,
 ) ([]*models.Message, *string, bool, error) {
 	var messages []*models.Message
 
-	// สร้าง base query
-	// ใช้ ILIKE แทน full-text search เพื่อรองรับภาษาไทย (ไม่มี word boundaries)
+	// แปลงคำค้นหาเป็น tsquery (ภาษาไทยใช้ bigram phrase, ภาษาอื่นใช้ prefix)
+	tsQuery := textsearch.Query(searchQuery)
+	if tsQuery == "" {
+		return messages, nil, false, nil
+	}
+
+	// สร้าง base query (ใช้ GIN index บน content_tsvector)
 	baseQuery := r.db.Model(&models.Message{}).
 		Where("is_deleted = ?", false).
-		Where("content ILIKE ?", "%"+searchQuery+"%")
+		Where("content_tsvector @@ ?::tsquery", tsQuery)
 
 	// Filter by conversation if specified
 	if conversationID != nil {
@@ -671,6 +752,46 @@ func (r *messageRepository) SearchMessages(
 		)
 	}
 
+	// เรียงตามความเกี่ยวข้อง (offset-based cursor)
+	if sortBy == "relevance" {
+		offset := 0
+		if cursor != nil && *cursor != "" {
+			parsed, err := strconv.Atoi(*cursor)
+			if err != nil || parsed < 0 {
+				return nil, nil, false, errors.New("invalid cursor")
+			}
+			offset = parsed
+		}
+
+		if err := baseQuery.
+			Preload("Sender").
+			Preload("Conversation").
+			Preload("ReplyTo").
+			Order(clause.Expr{
+				SQL:                "ts_rank(content_tsvector, ?::tsquery) DESC, created_at DESC, id DESC",
+				Vars:               []interface{}{tsQuery},
+				WithoutParentheses: true,
+			}).
+			Offset(offset).
+			Limit(limit + 1).
+			Find(&messages).Error; err != nil {
+			return nil, nil, false, err
+		}
+
+		hasMore := len(messages) > limit
+		if hasMore {
+			messages = messages[:limit]
+		}
+
+		var nextCursor *string
+		if hasMore {
+			next := strconv.Itoa(offset + len(messages))
+			nextCursor = &next
+		}
+
+		return messages, nextCursor, hasMore, nil
+	}
+
 	// Apply cursor pagination
 	if cursor != nil && *cursor != "" {
 		cursorID, err := uuid.Parse(*cursor)
@@ -739,3 +860,67 @@ func (r *messageRepository) SearchMessages(
 	return messages, nextCursor, hasMore, nil
 }
 
+// GetThreadReplies ดึงข้อความตอบกลับใน thread เรียงจากเก่าไปใหม่
+// afterID เป็น cursor (ID ของข้อความสุดท้ายที่ได้รับ) ถ้าเป็น nil จะเริ่มจากข้อความแรก
+func (r *messageRepository) GetThreadReplies(rootID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Message, error) {
+	query := r.db.Where("thread_root_id = ?", rootID)
+
+	if afterID != nil {
+		var cursor models.Message
+		if err := r.db.Select("id", "created_at").First(&cursor, "id = ? AND thread_root_id = ?", *afterID, rootID).Error; err != nil {
+			return nil, err
+		}
+		// composite cursor (created_at + id) เหมือน GetMessagesAfter
+		query = query.Where("created_at > ? OR (created_at = ? AND id > ?)",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	var messages []*models.Message
+	if err := query.Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// IncrementThreadReplyCount เพิ่มจำนวนข้อความตอบกลับและเวลาตอบกลับล่าสุดของ root message
+func (r *messageRepository) IncrementThreadReplyCount(rootID uuid.UUID, replyAt time.Time) error {
+	return r.db.Model(&models.Message{}).
+		Where("id = ?", rootID).
+		UpdateColumns(map[string]interface{}{
+			"thread_reply_count":   gorm.Expr("thread_reply_count + 1"),
+			"thread_last_reply_at": replyAt,
+		}).Error
+}
+
+// FindExpiredMessages ดึงข้อความที่หมดอายุแล้วและยังไม่ถูกลบ (เก่าสุดก่อน)
+func (r *messageRepository) FindExpiredMessages(before time.Time, limit int) ([]*models.Message, error) {
+	var messages []*models.Message
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at <= ? AND is_deleted = ?", before, false).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// IsMediaURLReferenced ตรวจสอบว่ายังมีข้อความที่ไม่ถูกลบใช้ไฟล์นี้อยู่หรือไม่ (เช่น ข้อความที่ถูก forward)
+func (r *messageRepository) IsMediaURLReferenced(mediaURL string) (bool, error) {
+	albumMedia, err := json.Marshal([]map[string]string{{"media_url": mediaURL}})
+	if err != nil {
+		return false, err
+	}
+	albumThumbnail, err := json.Marshal([]map[string]string{{"media_thumbnail_url": mediaURL}})
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = r.db.Model(&models.Message{}).
+		Where("is_deleted = ?", false).
+		Where("media_url = ? OR media_thumbnail_url = ? OR album_files @> ?::jsonb OR album_files @> ?::jsonb",
+			mediaURL, mediaURL, string(albumMedia), string(albumThumbnail)).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type noteRepository struct {
@@ -21,9 +23,18 @@ func NewNoteRepository(db *gorm.DB) repository.NoteRepository {
 
 // Create สร้างบันทึกใหม่
 func (r *noteRepository) Create(note *models.Note) error {
+	note.SearchDocument = noteSearchDocument(note)
 	return r.db.Create(note).Error
 }
 
+// noteSearchDocument สร้าง tsvector literal ของบันทึก (title มีน้ำหนักมากกว่า content)
+func noteSearchDocument(note *models.Note) string {
+	return textsearch.Document(
+		textsearch.Field{Text: note.Title, Weight: textsearch.WeightA},
+		textsearch.Field{Text: note.Content, Weight: textsearch.WeightB},
+	)
+}
+
 // GetByID ดึงข้อมูลบันทึกตาม ID และตรวจสอบเจ้าของ
 func (r *noteRepository) GetByID(id, userID uuid.UUID) (*models.Note, error) {
 	var note models.Note
@@ -42,6 +53,7 @@ func (r *noteRepository) GetByID(id, userID uuid.UUID) (*models.Note, error) {
 // Update อัปเดตข้อมูลบันทึก
 func (r *noteRepository) Update(note *models.Note) error {
 	note.UpdatedAt = time.Now()
+	note.SearchDocument = noteSearchDocument(note)
 	return r.db.Save(note).Error
 }
 
@@ -102,14 +114,19 @@ func (r *noteRepository) FindPinnedByUserID(userID uuid.UUID, limit, offset int)
 	return notes, total, nil
 }
 
-// SearchNotes ค้นหาบันทึกด้วย full-text search
+// SearchNotes ค้นหาบันทึกด้วย full-text search (รองรับภาษาไทย) เรียงตามความเกี่ยวข้อง
 func (r *noteRepository) SearchNotes(userID uuid.UUID, searchQuery string, limit, offset int) ([]*models.Note, int64, error) {
 	var notes []*models.Note
 	var total int64
 
+	tsQuery := textsearch.Query(searchQuery)
+	if tsQuery == "" {
+		return notes, 0, nil
+	}
+
 	baseQuery := r.db.Model(&models.Note{}).
 		Where("user_id = ?", userID).
-		Where("content_tsvector @@ plainto_tsquery('english', ?)", searchQuery)
+		Where("content_tsvector @@ ?::tsquery", tsQuery)
 
 	// นับจำนวนทั้งหมด
 	if err := baseQuery.Count(&total).Error; err != nil {
@@ -118,7 +135,11 @@ func (r *noteRepository) SearchNotes(userID uuid.UUID, searchQuery string, limit
 
 	// ดึงข้อมูล
 	err := baseQuery.
-		Order("updated_at DESC").
+		Order(clause.Expr{
+			SQL:                "ts_rank(content_tsvector, ?::tsquery) DESC, updated_at DESC",
+			Vars:               []interface{}{tsQuery},
+			WithoutParentheses: true,
+		}).
 		Limit(limit).
 		Offset(offset).
 		Find(&notes).Error
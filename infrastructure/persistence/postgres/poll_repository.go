@@ -0,0 +1,215 @@
+// infrastructure/persistence/postgres/poll_repository.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+	"gorm.io/gorm"
+)
+
+type pollRepository struct {
+	db *gorm.DB
+}
+
+// NewPollRepository creates a new poll repository
+func NewPollRepository(db *gorm.DB) repository.PollRepository {
+	return &pollRepository{db: db}
+}
+
+// Create creates a poll and its options
+func (r *pollRepository) Create(ctx context.Context, poll *models.Poll) error {
+	return r.db.WithContext(ctx).Create(poll).Error
+}
+
+// GetByID gets a poll by ID
+func (r *pollRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Poll, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// GetByMessageID gets the poll of a message
+func (r *pollRepository) GetByMessageID(ctx context.Context, messageID uuid.UUID) (*models.Poll, error) {
+	return r.findOne(ctx, "message_id = ?", messageID)
+}
+
+// GetByMessageIDs gets the polls of several messages
+func (r *pollRepository) GetByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) ([]*models.Poll, error) {
+	var polls []*models.Poll
+	if len(messageIDs) == 0 {
+		return polls, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("message_id IN ?", messageIDs).
+		Find(&polls).Error
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+func (r *pollRepository) findOne(ctx context.Context, query string, args ...interface{}) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where(query, args...).
+		First(&poll).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// SetVotes replaces a user's votes in one transaction.
+// The poll row is locked so that votes cannot race with Close.
+func (r *pollRepository) SetVotes(ctx context.Context, pollID, userID uuid.UUID, optionIDs []uuid.UUID) (bool, error) {
+	open := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if open, err = lockOpenPoll(tx, pollID); err != nil || !open {
+			return err
+		}
+
+		if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).
+			Delete(&models.PollVote{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		votes := make([]*models.PollVote, len(optionIDs))
+		for i, optionID := range optionIDs {
+			votes[i] = &models.PollVote{
+				ID:        uuid.New(),
+				PollID:    pollID,
+				OptionID:  optionID,
+				UserID:    userID,
+				CreatedAt: now,
+			}
+		}
+		if len(votes) == 0 {
+			return nil
+		}
+		return tx.Create(&votes).Error
+	})
+	return open, err
+}
+
+// DeleteVotes removes all of a user's votes on a poll
+func (r *pollRepository) DeleteVotes(ctx context.Context, pollID, userID uuid.UUID) (bool, error) {
+	open := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if open, err = lockOpenPoll(tx, pollID); err != nil || !open {
+			return err
+		}
+
+		return tx.Where("poll_id = ? AND user_id = ?", pollID, userID).
+			Delete(&models.PollVote{}).Error
+	})
+	return open, err
+}
+
+// lockOpenPoll locks the poll row if it still accepts votes
+func lockOpenPoll(tx *gorm.DB, pollID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := tx.Model(&models.Poll{}).
+		Where("id = ? AND closed_at IS NULL AND (closes_at IS NULL OR closes_at > ?)", pollID, now).
+		Update("updated_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetVotes gets all votes on a poll
+func (r *pollRepository) GetVotes(ctx context.Context, pollID uuid.UUID) ([]*models.PollVote, error) {
+	var votes []*models.PollVote
+	err := r.db.WithContext(ctx).
+		Where("poll_id = ?", pollID).
+		Order("created_at ASC").
+		Find(&votes).Error
+	if err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// GetVotesByPollIDs gets all votes on several polls
+func (r *pollRepository) GetVotesByPollIDs(ctx context.Context, pollIDs []uuid.UUID) ([]*models.PollVote, error) {
+	var votes []*models.PollVote
+	if len(pollIDs) == 0 {
+		return votes, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("poll_id IN ?", pollIDs).
+		Order("created_at ASC").
+		Find(&votes).Error
+	if err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// Close marks a poll as closed if it is still open and stores its final results.
+// The close update locks the poll row, so votes cannot change before the snapshot
+// is taken, and a failed snapshot leaves the poll open for the next attempt.
+func (r *pollRepository) Close(ctx context.Context, pollID uuid.UUID, closedBy *uuid.UUID, closedAt time.Time, snapshot func(votes []*models.PollVote) types.JSONB) (bool, error) {
+	closed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Poll{}).
+			Where("id = ? AND closed_at IS NULL", pollID).
+			Updates(map[string]interface{}{
+				"closed_at":  closedAt,
+				"closed_by":  closedBy,
+				"updated_at": closedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		var votes []*models.PollVote
+		if err := tx.Where("poll_id = ?", pollID).
+			Order("created_at ASC").
+			Find(&votes).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Poll{}).
+			Where("id = ?", pollID).
+			Update("final_results", snapshot(votes)).Error; err != nil {
+			return err
+		}
+		closed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return closed, nil
+}
+
+// FindDueForClose finds open polls whose close time has passed
+func (r *pollRepository) FindDueForClose(ctx context.Context, now time.Time, limit int) ([]*models.Poll, error) {
+	var polls []*models.Poll
+	err := r.db.WithContext(ctx).
+		Where("closed_at IS NULL AND closes_at IS NOT NULL AND closes_at <= ?", now).
+		Order("closes_at ASC").
+		Limit(limit).
+		Find(&polls).Error
+	if err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
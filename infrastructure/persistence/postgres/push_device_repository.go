@@ -0,0 +1,70 @@
+// infrastructure/persistence/postgres/push_device_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pushDeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewPushDeviceRepository(db *gorm.DB) repository.PushDeviceRepository {
+	return &pushDeviceRepository{db: db}
+}
+
+func (r *pushDeviceRepository) Upsert(device *models.PushDevice) error {
+	now := time.Now()
+	if device.ID == uuid.Nil {
+		device.ID = uuid.New()
+	}
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now
+	}
+	device.UpdatedAt = now
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_id", "session_id", "provider", "p256dh", "auth", "platform", "device_name", "updated_at",
+		}),
+	}).Create(device).Error
+	if err != nil {
+		return err
+	}
+
+	// ON CONFLICT ไม่คืน id ของแถวเดิม ต้องอ่านกลับมา
+	return r.db.Where("token = ?", device.Token).First(device).Error
+}
+
+func (r *pushDeviceRepository) FindByID(id uuid.UUID) (*models.PushDevice, error) {
+	var device models.PushDevice
+	if err := r.db.Where("id = ?", id).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *pushDeviceRepository) FindByUserID(userID uuid.UUID) ([]*models.PushDevice, error) {
+	var devices []*models.PushDevice
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&devices).Error
+	return devices, err
+}
+
+func (r *pushDeviceRepository) Delete(id uuid.UUID) error {
+	return r.db.Where("id = ?", id).Delete(&models.PushDevice{}).Error
+}
+
+func (r *pushDeviceRepository) MarkUsed(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.PushDevice{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -29,9 +29,9 @@ func (r *refreshTokenRepository) Create(refreshToken *models.RefreshToken) error
 	return r.db.Create(refreshToken).Error
 }
 
-func (r *refreshTokenRepository) FindByToken(token string) (*models.RefreshToken, error) {
+func (r *refreshTokenRepository) FindByTokenHash(tokenHash string) (*models.RefreshToken, error) {
 	var refreshToken models.RefreshToken
-	err := r.db.Where("token = ? AND revoked = ?", token, false).First(&refreshToken).Error
+	err := r.db.Where("token_hash = ?", tokenHash).First(&refreshToken).Error
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +44,32 @@ func (r *refreshTokenRepository) RevokeByUserID(userID uuid.UUID) error {
 		Update("revoked", true).Error
 }
 
+// MarkRotated ใช้ revoked = false เป็นเงื่อนไข เพื่อให้มีเพียง request เดียวที่หมุน token ได้
+func (r *refreshTokenRepository) MarkRotated(id uuid.UUID, rotatedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked = ?", id, false).
+		Updates(map[string]interface{}{
+			"revoked":    true,
+			"rotated_at": rotatedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenRepository) RevokeBySessionID(sessionID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND revoked = ?", sessionID, false).
+		Update("revoked", true).Error
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Update("revoked", true).Error
+}
+
 func (r *refreshTokenRepository) DeleteExpired(before time.Time) error {
 	return r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{}).Error
 }
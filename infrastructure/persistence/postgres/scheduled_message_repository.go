@@ -144,11 +144,12 @@ func (r *scheduledMessageRepository) FindByConversationAndUser(conversationID, u
 func (r *scheduledMessageRepository) FindPendingMessages(beforeTime time.Time, limit int) ([]*models.ScheduledMessage, error) {
 	var scheduledMsgs []*models.ScheduledMessage
 
+	// pending ที่ถึงเวลา (รวมเวลา retry) และ processing ที่ lease หมดอายุ (instance เดิม crash)
 	err := r.db.Preload("Conversation").
 		Preload("Sender").
-		Where("status = ?", "pending").
-		Where("scheduled_at <= ?", beforeTime).
-		Order("scheduled_at ASC").
+		Where("(status = ? AND COALESCE(next_attempt_at, scheduled_at) <= ?) OR (status = ? AND locked_until <= ?)",
+			"pending", beforeTime, "processing", beforeTime).
+		Order("COALESCE(next_attempt_at, scheduled_at) ASC").
 		Limit(limit).
 		Find(&scheduledMsgs).Error
 
@@ -159,6 +160,51 @@ func (r *scheduledMessageRepository) FindPendingMessages(beforeTime time.Time, l
 	return scheduledMsgs, nil
 }
 
+// FindBySeriesID ดึงทุกรอบของข้อความตั้งเวลาแบบซ้ำ (ล่าสุดก่อน)
+func (r *scheduledMessageRepository) FindBySeriesID(seriesID uuid.UUID, limit, offset int) ([]*models.ScheduledMessage, int64, error) {
+	var scheduledMsgs []*models.ScheduledMessage
+	var total int64
+
+	// นับจำนวนทั้งหมด
+	if err := r.db.Model(&models.ScheduledMessage{}).
+		Where("series_id = ?", seriesID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ดึงข้อมูล
+	err := r.db.Preload("Message").
+		Where("series_id = ?", seriesID).
+		Order("scheduled_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&scheduledMsgs).Error
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return scheduledMsgs, total, nil
+}
+
+// FindUpcomingInSeries ดึงรอบถัดไปของ series ที่ยังไม่ได้ส่ง (pending หรือ paused)
+func (r *scheduledMessageRepository) FindUpcomingInSeries(seriesID uuid.UUID) (*models.ScheduledMessage, error) {
+	var scheduledMsg models.ScheduledMessage
+	err := r.db.Where("series_id = ?", seriesID).
+		Where("status IN ?", []string{"pending", "paused"}).
+		Order("scheduled_at DESC").
+		First(&scheduledMsg).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &scheduledMsg, nil
+}
+
 // UpdateStatus อัปเดตสถานะของข้อความที่กำหนดเวลาส่ง
 func (r *scheduledMessageRepository) UpdateStatus(id uuid.UUID, status string, sentAt *time.Time, messageID *uuid.UUID, errorReason string) error {
 	updates := map[string]interface{}{
@@ -178,6 +224,12 @@ func (r *scheduledMessageRepository) UpdateStatus(id uuid.UUID, status string, s
 		updates["error_reason"] = errorReason
 	}
 
+	// ปล่อย lease เมื่อเปลี่ยนเป็นสถานะอื่น
+	if status != "processing" {
+		updates["locked_by"] = ""
+		updates["locked_until"] = nil
+	}
+
 	return r.db.Model(&models.ScheduledMessage{}).
 		Where("id = ?", id).
 		Updates(updates).Error
@@ -187,9 +239,91 @@ func (r *scheduledMessageRepository) UpdateStatus(id uuid.UUID, status string, s
 func (r *scheduledMessageRepository) CancelScheduledMessage(id uuid.UUID) error {
 	return r.db.Model(&models.ScheduledMessage{}).
 		Where("id = ?", id).
-		Where("status = ?", "pending").
+		Where("status IN ?", []string{"pending", "paused"}).
 		Updates(map[string]interface{}{
 			"status":     "cancelled",
 			"updated_at": time.Now(),
 		}).Error
 }
+
+// PauseScheduledMessage หยุดข้อความที่ยัง pending (ไม่แตะข้อความที่ instance อื่น claim ไปแล้ว)
+func (r *scheduledMessageRepository) PauseScheduledMessage(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&models.ScheduledMessage{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(map[string]interface{}{
+			"status":     "paused",
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// Claim เปลี่ยนสถานะเป็น processing แบบ atomic (UPDATE ... WHERE) เพื่อให้มีเพียง instance เดียวที่ส่งข้อความ
+func (r *scheduledMessageRepository) Claim(id uuid.UUID, owner string, leaseUntil time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.ScheduledMessage{}).
+		Where("id = ?", id).
+		Where("(status = ? AND COALESCE(next_attempt_at, scheduled_at) <= ?) OR (status = ? AND locked_until <= ?)",
+			"pending", now, "processing", now).
+		Updates(map[string]interface{}{
+			"status":       "processing",
+			"locked_by":    owner,
+			"locked_until": leaseUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"updated_at":   now,
+		})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ScheduleRetry ปล่อย claim และตั้งเวลาส่งใหม่ (เฉพาะ instance ที่ถือ claim อยู่)
+func (r *scheduledMessageRepository) ScheduleRetry(id uuid.UUID, owner string, nextAttemptAt time.Time, errorReason string) error {
+	return r.db.Model(&models.ScheduledMessage{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, "processing", owner).
+		Updates(map[string]interface{}{
+			"status":          "pending",
+			"next_attempt_at": nextAttemptAt,
+			"error_reason":    errorReason,
+			"locked_by":       "",
+			"locked_until":    nil,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// CompleteClaim บันทึกผลการส่งและปล่อย claim (เฉพาะ instance ที่ยังถือ claim อยู่)
+func (r *scheduledMessageRepository) CompleteClaim(id uuid.UUID, owner string, status string, sentAt *time.Time, messageID *uuid.UUID, errorReason string) (bool, error) {
+	updates := map[string]interface{}{
+		"status":       status,
+		"locked_by":    "",
+		"locked_until": nil,
+		"updated_at":   time.Now(),
+	}
+
+	if sentAt != nil {
+		updates["sent_at"] = sentAt
+	}
+
+	if messageID != nil {
+		updates["message_id"] = messageID
+	}
+
+	if errorReason != "" {
+		updates["error_reason"] = errorReason
+	}
+
+	result := r.db.Model(&models.ScheduledMessage{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, "processing", owner).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
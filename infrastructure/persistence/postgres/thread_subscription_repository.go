@@ -0,0 +1,61 @@
+// infrastructure/persistence/postgres/thread_subscription_repository.go
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type threadSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewThreadSubscriptionRepository creates a new thread subscription repository
+func NewThreadSubscriptionRepository(db *gorm.DB) repository.ThreadSubscriptionRepository {
+	return &threadSubscriptionRepository{db: db}
+}
+
+// Subscribe creates a subscription, ignoring duplicates
+func (r *threadSubscriptionRepository) Subscribe(ctx context.Context, subscription *models.ThreadSubscription) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "message_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).
+		Create(subscription).Error
+}
+
+// Unsubscribe deletes a subscription by message_id and user_id
+func (r *threadSubscriptionRepository) Unsubscribe(ctx context.Context, messageID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Delete(&models.ThreadSubscription{}).Error
+}
+
+// IsSubscribed checks if a user follows a thread
+func (r *threadSubscriptionRepository) IsSubscribed(ctx context.Context, messageID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ThreadSubscription{}).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetSubscriberIDs gets the IDs of users following a thread.
+// Users who have left the conversation are skipped.
+func (r *threadSubscriptionRepository) GetSubscriberIDs(ctx context.Context, messageID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&models.ThreadSubscription{}).
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = thread_subscriptions.conversation_id AND conversation_members.user_id = thread_subscriptions.user_id").
+		Where("thread_subscriptions.message_id = ?", messageID).
+		Pluck("thread_subscriptions.user_id", &userIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
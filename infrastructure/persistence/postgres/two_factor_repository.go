@@ -0,0 +1,132 @@
+// infrastructure/persistence/postgres/two_factor_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) repository.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) FindByUserID(userID uuid.UUID) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *models.UserTwoFactor) error {
+	now := time.Now()
+	if twoFactor.CreatedAt.IsZero() {
+		twoFactor.CreatedAt = now
+	}
+	twoFactor.UpdatedAt = now
+
+	return r.db.Save(twoFactor).Error
+}
+
+func (r *twoFactorRepository) Delete(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// MarkStepUsed ใช้ last_used_step < step เป็นเงื่อนไข เพื่อให้โค้ดหนึ่งใช้ได้เพียงครั้งเดียวแม้มี request พร้อมกัน
+func (r *twoFactorRepository) MarkStepUsed(userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Updates(map[string]interface{}{
+			"last_used_step": step,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) UpdateFailures(userID uuid.UUID, failedAttempts int, lockedUntil *time.Time) error {
+	return r.db.Model(&models.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_attempts": failedAttempts,
+			"locked_until":    lockedUntil,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// IncrementFailures ใช้ UPDATE ... RETURNING เพื่อให้การเดาโค้ดพร้อมกันหลาย request นับครบทุกครั้ง
+func (r *twoFactorRepository) IncrementFailures(userID uuid.UUID) (int, error) {
+	var attempts []int
+	err := r.db.Raw(
+		"UPDATE user_two_factors SET failed_attempts = failed_attempts + 1, updated_at = ? WHERE user_id = ? RETURNING failed_attempts",
+		time.Now(), userID,
+	).Scan(&attempts).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(attempts) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return attempts[0], nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []*models.UserRecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, code := range codes {
+			if code.ID == uuid.Nil {
+				code.ID = uuid.New()
+			}
+			if code.CreatedAt.IsZero() {
+				code.CreatedAt = now
+			}
+			code.UserID = userID
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uuid.UUID, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -2,6 +2,7 @@
 package postgres
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -120,6 +121,9 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &user, nil
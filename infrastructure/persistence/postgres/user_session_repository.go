@@ -0,0 +1,104 @@
+// infrastructure/persistence/postgres/user_session_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type userSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository สร้าง repository สำหรับ session ต่ออุปกรณ์
+func NewUserSessionRepository(db *gorm.DB) repository.UserSessionRepository {
+	return &userSessionRepository{db: db}
+}
+
+func (r *userSessionRepository) Create(session *models.UserSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	now := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = now
+	}
+
+	return r.db.Create(session).Error
+}
+
+func (r *userSessionRepository) FindByID(id uuid.UUID) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *userSessionRepository) FindActiveByUserID(userID uuid.UUID, now time.Time) ([]*models.UserSession, error) {
+	var sessions []*models.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *userSessionRepository) Touch(id uuid.UUID, ipAddress string, usedAt, expiresAt time.Time) error {
+	updates := map[string]interface{}{
+		"last_used_at": usedAt,
+		"expires_at":   expiresAt,
+	}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *userSessionRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	return r.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *userSessionRepository) RevokeAllByUserID(userID, exceptID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptID != uuid.Nil {
+			query = query.Where("id <> ?", exceptID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.UserSession{}).
+			Where("id IN ?", ids).
+			Update("revoked_at", revokedAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *userSessionRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.UserSession{}).Error
+}
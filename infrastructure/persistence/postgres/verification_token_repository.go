@@ -0,0 +1,73 @@
+// infrastructure/persistence/postgres/verification_token_repository.go
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"gorm.io/gorm"
+)
+
+type verificationTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationTokenRepository สร้าง repository สำหรับโค้ดรีเซ็ตรหัสผ่านและยืนยันอีเมล
+func NewVerificationTokenRepository(db *gorm.DB) repository.VerificationTokenRepository {
+	return &verificationTokenRepository{db: db}
+}
+
+func (r *verificationTokenRepository) Create(token *models.VerificationToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	return r.db.Create(token).Error
+}
+
+func (r *verificationTokenRepository) FindByTokenHash(purpose, tokenHash string) (*models.VerificationToken, error) {
+	var token models.VerificationToken
+	err := r.db.Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed ใช้ used_at IS NULL เป็นเงื่อนไข เพื่อให้มีเพียง request เดียวที่ใช้โค้ดได้
+func (r *verificationTokenRepository) MarkUsed(id uuid.UUID, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.VerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *verificationTokenRepository) InvalidateByUserID(userID uuid.UUID, purpose string, at time.Time) error {
+	return r.db.Model(&models.VerificationToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+func (r *verificationTokenRepository) CountIssuedSince(userID uuid.UUID, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.VerificationToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *verificationTokenRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.VerificationToken{}).Error
+}
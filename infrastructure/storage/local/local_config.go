@@ -0,0 +1,18 @@
+// infrastructure/storage/local/local_config.go
+package local
+
+// LocalConfig เก็บการตั้งค่าสำหรับการเก็บไฟล์บน filesystem ของ server
+type LocalConfig struct {
+	RootDir         string // โฟลเดอร์ที่เก็บไฟล์ (default: ./uploads)
+	BaseURL         string // URL ของ route ที่ให้บริการไฟล์ เช่น http://localhost:8080/storage
+	SigningKey      string // key สำหรับลงชื่อ presigned URL (HMAC-SHA256)
+	PrivateDownload bool   // true = ดาวน์โหลดได้เฉพาะ URL ที่มีลายเซ็น
+}
+
+// GetRootDir คืนค่าโฟลเดอร์ที่เก็บไฟล์ (default: ./uploads)
+func (c *LocalConfig) GetRootDir() string {
+	if c.RootDir != "" {
+		return c.RootDir
+	}
+	return "./uploads"
+}
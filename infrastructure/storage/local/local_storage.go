@@ -0,0 +1,316 @@
+// infrastructure/storage/local/local_storage.go
+package local
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// localStorage จัดการการเก็บไฟล์บน filesystem ของ server
+// presigned URL ชี้มาที่ route /storage ของ server เอง และลงชื่อด้วย HMAC-SHA256
+type localStorage struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	private    bool
+}
+
+var (
+	_ service.SignedFileServer = (*localStorage)(nil)
+	_ service.PrivateStorage   = (*localStorage)(nil)
+)
+
+// NewLocalStorage สร้าง FileStorageService ที่เก็บไฟล์ใน RootDir
+func NewLocalStorage(cfg *LocalConfig) (service.FileStorageService, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("local storage base URL is required")
+	}
+
+	root, err := filepath.Abs(cfg.GetRootDir())
+	if err != nil {
+		return nil, fmt.Errorf("invalid local storage root: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage root: %w", err)
+	}
+
+	signingKey := []byte(cfg.SigningKey)
+	if len(signingKey) == 0 {
+		// ไม่มี key: สุ่มใหม่ทุกครั้งที่เริ่ม server (URL ที่ออกไปแล้วจะใช้ไม่ได้หลัง restart)
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+		log.Println("LOCAL_STORAGE_SIGNING_KEY is not set, presigned URLs will not survive a restart")
+	}
+
+	return &localStorage{
+		root:       root,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		signingKey: signingKey,
+		private:    cfg.PrivateDownload,
+	}, nil
+}
+
+// UploadImage อัปโหลดรูปภาพ
+func (l *localStorage) UploadImage(file *multipart.FileHeader, folder string) (*service.FileUploadResult, error) {
+	return l.uploadFile(file, folder, "image")
+}
+
+// UploadFile อัปโหลดไฟล์ทั่วไป
+func (l *localStorage) UploadFile(file *multipart.FileHeader, folder string) (*service.FileUploadResult, error) {
+	return l.uploadFile(file, folder, "auto")
+}
+
+// uploadFile ตั้งชื่อไฟล์แบบเดียวกับ R2 (ชื่อเดิม_uuid8.ext) แล้วเขียนลง disk
+func (l *localStorage) uploadFile(file *multipart.FileHeader, folder string, resourceType string) (*service.FileUploadResult, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	ext := filepath.Ext(file.Filename)
+	nameWithoutExt := strings.TrimSuffix(filepath.Base(file.Filename), ext)
+	uniqueID := uuid.New().String()[:8]
+	filename := fmt.Sprintf("%s_%s%s", nameWithoutExt, uniqueID, ext)
+
+	filePath := filename
+	if folder != "" {
+		filePath = path.Join(folder, filename)
+	}
+	filePath, err = cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := l.WriteFile(filePath, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.FileUploadResult{
+		URL:          l.GetPublicURL(filePath),
+		Path:         filePath,
+		PublicID:     filePath,
+		ResourceType: resourceType,
+		Format:       strings.TrimPrefix(ext, "."),
+		Size:         int(size),
+		Metadata:     map[string]string{},
+	}, nil
+}
+
+// DeleteFile ลบไฟล์ (ไฟล์ที่ไม่มีอยู่แล้วถือว่าลบสำเร็จ เหมือน DeleteObject ของ S3)
+func (l *localStorage) DeleteFile(filePath string) error {
+	fullPath, err := l.fullPath(filePath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// GetPublicURL สร้าง URL สำหรับไฟล์ (ถ้าเปิด PrivateDownload ต้องใช้ GeneratePresignedDownloadURL แทน)
+func (l *localStorage) GetPublicURL(filePath string) string {
+	return fmt.Sprintf("%s/%s", l.baseURL, filePath)
+}
+
+// GeneratePresignedUploadURL สร้าง URL สำหรับ PUT ไฟล์ตรงมาที่ server
+// client ต้องส่ง header Content-Type ให้ตรงกับ contentType ที่ลงชื่อไว้
+func (l *localStorage) GeneratePresignedUploadURL(filePath string, contentType string, expiry time.Duration) (*service.PresignedURLResult, error) {
+	clean, err := cleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	return &service.PresignedURLResult{
+		URL:       l.signedURL("PUT", clean, contentType, expiresAt),
+		Path:      clean,
+		ExpiresAt: expiresAt,
+		Method:    "PUT",
+		Fields:    map[string]string{},
+	}, nil
+}
+
+// GeneratePresignedDownloadURL สร้าง URL สำหรับ GET ไฟล์ที่หมดอายุตาม expiry
+func (l *localStorage) GeneratePresignedDownloadURL(filePath string, expiry time.Duration) (string, error) {
+	clean, err := cleanPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	return l.signedURL("GET", clean, "", time.Now().Add(expiry)), nil
+}
+
+// ============ SignedFileServer ============
+
+// VerifySignedURL ตรวจลายเซ็นและเวลาหมดอายุของ URL
+func (l *localStorage) VerifySignedURL(method, filePath, contentType, expires, signature string) error {
+	clean, err := cleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || signature == "" {
+		return errors.New("invalid signature")
+	}
+
+	expected := l.sign(method, clean, contentType, expiresAt)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("invalid signature")
+	}
+	if time.Now().Unix() > expiresAt {
+		return errors.New("signed url has expired")
+	}
+	return nil
+}
+
+// RequiresSignedDownload ดาวน์โหลดต้องมีลายเซ็นหรือไม่
+func (l *localStorage) RequiresSignedDownload() bool {
+	return l.private
+}
+
+// KeepsFilesPrivate ดาวน์โหลดต้องมีลายเซ็น (LOCAL_STORAGE_PRIVATE) หรือไม่
+func (l *localStorage) KeepsFilesPrivate() bool {
+	return l.private
+}
+
+// OpenFile เปิดไฟล์เพื่ออ่าน
+func (l *localStorage) OpenFile(filePath string) (io.ReadCloser, int64, error) {
+	fullPath, err := l.fullPath(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, errors.New("file not found")
+		}
+		return nil, 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, 0, errors.New("file not found")
+	}
+	return f, info.Size(), nil
+}
+
+// WriteFile เขียนไฟล์ลงไฟล์ชั่วคราวก่อนแล้วค่อย rename เพื่อไม่ให้ผู้อ่านเห็นไฟล์ที่เขียนไม่ครบ
+func (l *localStorage) WriteFile(filePath string, r io.Reader) (int64, error) {
+	fullPath, err := l.fullPath(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	dir := filepath.Dir(fullPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	return size, nil
+}
+
+// GetObject เปิดไฟล์เพื่ออ่าน (service.ObjectStore)
+func (l *localStorage) GetObject(filePath string) (io.ReadCloser, error) {
+	f, _, err := l.OpenFile(filePath)
+	return f, err
+}
+
+// PutObject เขียนไฟล์ทับ path เดิม (service.ObjectStore)
+func (l *localStorage) PutObject(filePath string, contentType string, data []byte) error {
+	_, err := l.WriteFile(filePath, bytes.NewReader(data))
+	return err
+}
+
+// ============ helpers ============
+
+// signedURL สร้าง URL ที่มี expires และ signature ใน query string
+func (l *localStorage) signedURL(method, clean, contentType string, expiresAt time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", l.sign(method, clean, contentType, expiresAt.Unix()))
+
+	escaped := (&url.URL{Path: clean}).EscapedPath()
+	return l.baseURL + "/" + escaped + "?" + query.Encode()
+}
+
+// sign HMAC-SHA256 ของ method, path, content type และเวลาหมดอายุ (base64url)
+func (l *localStorage) sign(method, clean, contentType string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d", strings.ToUpper(method), clean, contentType, expiresAt)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// fullPath แปลง path ใน storage เป็น path บน disk (ต้องอยู่ใต้ root เสมอ)
+func (l *localStorage) fullPath(filePath string) (string, error) {
+	clean, err := cleanPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
+
+// cleanPath ทำ path ให้อยู่ในรูปแบบเดียวกันและปฏิเสธ path ที่พยายามออกนอก root
+func cleanPath(filePath string) (string, error) {
+	p := strings.ReplaceAll(filePath, "\\", "/")
+	if p == "" || strings.ContainsRune(p, 0) {
+		return "", errors.New("invalid file path")
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", errors.New("invalid file path")
+		}
+	}
+
+	clean := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if clean == "" || clean == "." {
+		return "", errors.New("invalid file path")
+	}
+
+	// ไฟล์ชั่วคราวระหว่างเขียน (.upload-*) ไม่ให้เข้าถึงจากภายนอก
+	if strings.HasPrefix(path.Base(clean), ".upload-") {
+		return "", errors.New("invalid file path")
+	}
+	return clean, nil
+}
@@ -0,0 +1,122 @@
+// infrastructure/storage/local/local_storage_test.go
+package local_test
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/local"
+)
+
+func newLocalStorage(t *testing.T, private bool) (service.FileStorageService, service.SignedFileServer) {
+	t.Helper()
+
+	storage, err := local.NewLocalStorage(&local.LocalConfig{
+		RootDir:         t.TempDir(),
+		BaseURL:         "http://localhost:8080/storage/",
+		SigningKey:      "test-signing-key",
+		PrivateDownload: private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage, storage.(service.SignedFileServer)
+}
+
+// verifyURL ตรวจ URL ที่ได้จาก GeneratePresigned* แบบเดียวกับที่ route /storage ทำ
+func verifyURL(server service.SignedFileServer, method, rawURL, contentType string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	path := strings.TrimPrefix(u.Path, "/storage/")
+	return server.VerifySignedURL(method, path, contentType, u.Query().Get("expires"), u.Query().Get("signature"))
+}
+
+func TestLocalStorage_PresignedUploadAndDownload(t *testing.T) {
+	storage, server := newLocalStorage(t, true)
+
+	upload, err := storage.GeneratePresignedUploadURL("chat/photo 1.jpg", "image/jpeg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Method != "PUT" || upload.Path != "chat/photo 1.jpg" {
+		t.Fatalf("unexpected presigned upload: %+v", upload)
+	}
+	if err := verifyURL(server, "PUT", upload.URL, "image/jpeg"); err != nil {
+		t.Fatalf("expected upload URL to verify: %v", err)
+	}
+
+	// ลายเซ็นผูกกับ method และ content type
+	if err := verifyURL(server, "PUT", upload.URL, "text/html"); err == nil || err.Error() != "invalid signature" {
+		t.Fatalf("expected content type mismatch to be rejected, got %v", err)
+	}
+	if err := verifyURL(server, "GET", upload.URL, ""); err == nil {
+		t.Fatal("expected upload signature to be rejected for download")
+	}
+
+	if _, err := server.WriteFile(upload.Path, strings.NewReader("jpeg-bytes")); err != nil {
+		t.Fatal(err)
+	}
+
+	download, err := storage.GeneratePresignedDownloadURL(upload.Path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyURL(server, "GET", download, ""); err != nil {
+		t.Fatalf("expected download URL to verify: %v", err)
+	}
+	if !server.RequiresSignedDownload() {
+		t.Fatal("expected private download to require a signature")
+	}
+
+	file, size, err := server.OpenFile(upload.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(file)
+	file.Close()
+	if string(content) != "jpeg-bytes" || size != int64(len(content)) {
+		t.Fatalf("unexpected file content %q (size %d)", content, size)
+	}
+
+	if err := storage.DeleteFile(upload.Path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.OpenFile(upload.Path); err == nil || err.Error() != "file not found" {
+		t.Fatalf("expected deleted file to be gone, got %v", err)
+	}
+	// ลบซ้ำไม่ถือว่าผิดพลาด
+	if err := storage.DeleteFile(upload.Path); err != nil {
+		t.Fatalf("expected deleting a missing file to succeed: %v", err)
+	}
+}
+
+func TestLocalStorage_RejectsExpiredAndTraversal(t *testing.T) {
+	storage, server := newLocalStorage(t, false)
+
+	expired, err := storage.GeneratePresignedDownloadURL("a.txt", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyURL(server, "GET", expired, ""); err == nil || err.Error() != "signed url has expired" {
+		t.Fatalf("expected expired URL to be rejected, got %v", err)
+	}
+
+	for _, path := range []string{"../secret.txt", "chat/../../etc/passwd", "..\\win.ini", ""} {
+		if _, err := server.WriteFile(path, strings.NewReader("x")); err == nil {
+			t.Fatalf("expected %q to be rejected", path)
+		}
+		if _, err := storage.GeneratePresignedUploadURL(path, "text/plain", time.Minute); err == nil {
+			t.Fatalf("expected presign for %q to be rejected", path)
+		}
+	}
+
+	if got := storage.GetPublicURL("chat/a.png"); got != "http://localhost:8080/storage/chat/a.png" {
+		t.Fatalf("unexpected public URL %s", got)
+	}
+}
@@ -211,3 +211,47 @@ func (r *r2Storage) GeneratePresignedDownloadURL(path string, expiry time.Durati
 
 	return presignedReq.URL, nil
 }
+
+// GetObject เปิดไฟล์จาก R2 เพื่ออ่าน (timeout นับรวมเวลาอ่านจนกว่าจะ Close)
+func (r *r2Storage) GetObject(path string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(r.ctx, 60*time.Second)
+
+	output, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.config.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to get file from R2: %w", err)
+	}
+
+	return &objectBody{ReadCloser: output.Body, cancel: cancel}, nil
+}
+
+// PutObject เขียนไฟล์ลง R2 ทับ path เดิม
+func (r *r2Storage) PutObject(path string, contentType string, data []byte) error {
+	ctx, cancel := context.WithTimeout(r.ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := r.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(r.config.Bucket),
+		Key:         aws.String(path),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload to R2: %w", err)
+	}
+	return nil
+}
+
+// objectBody ยกเลิก context ของ request เมื่อปิด body
+type objectBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *objectBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
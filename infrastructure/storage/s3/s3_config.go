@@ -0,0 +1,54 @@
+// infrastructure/storage/s3/s3_config.go
+package s3
+
+import (
+	"net/url"
+	"strings"
+)
+
+// S3Config เก็บการตั้งค่าสำหรับ storage ที่รองรับ S3 API (AWS S3, MinIO, Wasabi ฯลฯ)
+type S3Config struct {
+	Endpoint        string // Custom endpoint เช่น http://localhost:9000 สำหรับ MinIO (ว่าง = AWS S3)
+	Region          string // Region (default: us-east-1)
+	Bucket          string // Bucket name
+	AccessKeyID     string // ว่าง = ใช้ credential chain ของ AWS (env, shared config, IAM role)
+	SecretAccessKey string
+	UsePathStyle    bool   // true = endpoint/bucket/key (MinIO ส่วนใหญ่ต้องใช้), false = bucket.endpoint/key
+	PublicURL       string // URL สำหรับเข้าถึงไฟล์ (ว่าง = สร้างจาก endpoint และ bucket)
+	ACL             string // Canned ACL ตอนอัปโหลด เช่น public-read (ว่าง = ตาม bucket policy)
+	Private         bool   // true = bucket ไม่เปิด public ต้องดาวน์โหลดผ่าน presigned URL
+}
+
+// GetRegion คืนค่า region (default: us-east-1)
+func (c *S3Config) GetRegion() string {
+	if c.Region != "" {
+		return c.Region
+	}
+	return "us-east-1"
+}
+
+// GetPublicURL คืน URL ฐานของไฟล์ใน bucket
+// path-style: <endpoint>/<bucket>, virtual-hosted: <scheme>://<bucket>.<host>
+func (c *S3Config) GetPublicURL() string {
+	if c.PublicURL != "" {
+		return strings.TrimSuffix(c.PublicURL, "/")
+	}
+
+	if c.Endpoint == "" {
+		if c.UsePathStyle {
+			return "https://s3." + c.GetRegion() + ".amazonaws.com/" + c.Bucket
+		}
+		return "https://" + c.Bucket + ".s3." + c.GetRegion() + ".amazonaws.com"
+	}
+
+	endpoint := strings.TrimSuffix(c.Endpoint, "/")
+	if c.UsePathStyle {
+		return endpoint + "/" + c.Bucket
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint + "/" + c.Bucket
+	}
+	u.Host = c.Bucket + "." + u.Host
+	return u.String()
+}
@@ -0,0 +1,287 @@
+// infrastructure/storage/s3/s3_storage.go
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// s3Storage จัดการการเก็บไฟล์ด้วย storage ที่รองรับ S3 API
+type s3Storage struct {
+	client    *awss3.Client
+	config    *S3Config
+	publicURL string
+	ctx       context.Context
+}
+
+// NewS3Storage สร้าง FileStorageService ที่ใช้ AWS S3 หรือ S3-compatible storage (MinIO)
+func NewS3Storage(cfg *S3Config) (service.FileStorageService, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if cfg.ACL != "" && !isValidACL(cfg.ACL) {
+		return nil, fmt.Errorf("unsupported s3 ACL: %s", cfg.ACL)
+	}
+
+	ctx := context.Background()
+
+	options := []func(*config.LoadOptions) error{
+		config.WithRegion(cfg.GetRegion()),
+	}
+	if cfg.AccessKeyID != "" {
+		options = append(options, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.AccessKeyID,
+			cfg.SecretAccessKey,
+			"",
+		)))
+	}
+
+	awsConfig, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 config: %w", err)
+	}
+
+	client := awss3.NewFromConfig(awsConfig, func(o *awss3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+
+		// S3-compatible storage หลายตัวยังไม่รองรับ checksum แบบใหม่ของ SDK (และทำให้ presigned URL ใช้ไม่ได้)
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+
+	return &s3Storage{
+		client:    client,
+		config:    cfg,
+		publicURL: cfg.GetPublicURL(),
+		ctx:       ctx,
+	}, nil
+}
+
+// UploadImage อัปโหลดรูปภาพ
+func (s *s3Storage) UploadImage(file *multipart.FileHeader, folder string) (*service.FileUploadResult, error) {
+	return s.uploadFile(file, folder, "image")
+}
+
+// UploadFile อัปโหลดไฟล์ทั่วไป
+func (s *s3Storage) UploadFile(file *multipart.FileHeader, folder string) (*service.FileUploadResult, error) {
+	return s.uploadFile(file, folder, "auto")
+}
+
+// uploadFile ตั้งชื่อไฟล์แบบเดียวกับ R2 (ชื่อเดิม_uuid8.ext) แล้วอัปโหลดไปยัง bucket
+func (s *s3Storage) uploadFile(file *multipart.FileHeader, folder string, resourceType string) (*service.FileUploadResult, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	ext := filepath.Ext(file.Filename)
+	nameWithoutExt := strings.TrimSuffix(file.Filename, ext)
+	uniqueID := uuid.New().String()[:8]
+	filename := fmt.Sprintf("%s_%s%s", nameWithoutExt, uniqueID, ext)
+
+	var path string
+	if folder != "" {
+		path = filepath.Join(folder, filename)
+	} else {
+		path = filename
+	}
+	path = filepath.ToSlash(path)
+
+	contentType := file.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+
+	// multipart.File เป็น io.ReadSeeker จึงส่งตรงได้โดยไม่ต้องอ่านเข้า buffer
+	input := &awss3.PutObjectInput{
+		Bucket:        aws.String(s.config.Bucket),
+		Key:           aws.String(path),
+		Body:          src,
+		ContentLength: aws.Int64(file.Size),
+		ContentType:   aws.String(contentType),
+	}
+	if acl := s.objectACL(); acl != "" {
+		input.ACL = acl
+	}
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return nil, fmt.Errorf("failed to upload to S3: %w", err)
+	}
+
+	return &service.FileUploadResult{
+		URL:          s.GetPublicURL(path),
+		Path:         path,
+		PublicID:     path,
+		ResourceType: resourceType,
+		Format:       strings.TrimPrefix(ext, "."),
+		Size:         int(file.Size),
+		Metadata:     map[string]string{},
+	}, nil
+}
+
+// DeleteFile ลบไฟล์จาก bucket
+func (s *s3Storage) DeleteFile(path string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.client.DeleteObject(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file from S3: %w", err)
+	}
+
+	return nil
+}
+
+// GetPublicURL สร้าง URL ของไฟล์
+// bucket แบบ private ใช้ URL นี้เป็นตัวอ้างอิงเท่านั้น การดาวน์โหลดต้องใช้ GeneratePresignedDownloadURL
+func (s *s3Storage) GetPublicURL(path string) string {
+	return fmt.Sprintf("%s/%s", s.publicURL, path)
+}
+
+// GeneratePresignedUploadURL สร้าง presigned URL สำหรับให้ client PUT ไฟล์ตรงไปยัง bucket
+func (s *s3Storage) GeneratePresignedUploadURL(path string, contentType string, expiry time.Duration) (*service.PresignedURLResult, error) {
+	presignClient := awss3.NewPresignClient(s.client)
+	expiresAt := time.Now().Add(expiry)
+
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+
+	input := &awss3.PutObjectInput{
+		Bucket:      aws.String(s.config.Bucket),
+		Key:         aws.String(path),
+		ContentType: aws.String(contentType),
+	}
+	fields := map[string]string{}
+	if acl := s.objectACL(); acl != "" {
+		// ACL ถูกลงชื่อไว้ใน URL client ต้องส่ง header x-amz-acl ค่าเดียวกัน
+		input.ACL = acl
+		fields["x-amz-acl"] = string(acl)
+	}
+
+	presignedReq, err := presignClient.PresignPutObject(ctx, input, awss3.WithPresignExpires(expiry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate presigned upload URL: %w", err)
+	}
+
+	return &service.PresignedURLResult{
+		URL:       presignedReq.URL,
+		Path:      path,
+		ExpiresAt: expiresAt,
+		Method:    presignedReq.Method,
+		Fields:    fields,
+	}, nil
+}
+
+// GeneratePresignedDownloadURL สร้าง presigned URL สำหรับ download ไฟล์
+func (s *s3Storage) GeneratePresignedDownloadURL(path string, expiry time.Duration) (string, error) {
+	presignClient := awss3.NewPresignClient(s.client)
+
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+
+	presignedReq, err := presignClient.PresignGetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(path),
+	}, awss3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned download URL: %w", err)
+	}
+
+	return presignedReq.URL, nil
+}
+
+// GetObject เปิดไฟล์จาก bucket เพื่ออ่าน (timeout นับรวมเวลาอ่านจนกว่าจะ Close)
+func (s *s3Storage) GetObject(path string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 60*time.Second)
+
+	output, err := s.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to get file from S3: %w", err)
+	}
+
+	return &objectBody{ReadCloser: output.Body, cancel: cancel}, nil
+}
+
+// PutObject เขียนไฟล์ลง bucket ทับ path เดิม (ใช้ ACL เดียวกับการอัปโหลดปกติ)
+func (s *s3Storage) PutObject(path string, contentType string, data []byte) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+
+	input := &awss3.PutObjectInput{
+		Bucket:        aws.String(s.config.Bucket),
+		Key:           aws.String(path),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(contentType),
+	}
+	if acl := s.objectACL(); acl != "" {
+		input.ACL = acl
+	}
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	return nil
+}
+
+// KeepsFilesPrivate bucket ตั้งเป็น private (S3_PRIVATE) หรือไม่
+func (s *s3Storage) KeepsFilesPrivate() bool {
+	return s.config.Private
+}
+
+// objectACL canned ACL ที่ใช้ตอนอัปโหลด (bucket แบบ private ไม่ตั้ง ACL ให้ object เป็น public)
+func (s *s3Storage) objectACL() types.ObjectCannedACL {
+	if s.config.Private {
+		return ""
+	}
+	return types.ObjectCannedACL(s.config.ACL)
+}
+
+func isValidACL(acl string) bool {
+	for _, v := range types.ObjectCannedACL("").Values() {
+		if string(v) == acl {
+			return true
+		}
+	}
+	return false
+}
+
+// objectBody ยกเลิก context ของ request เมื่อปิด body
+type objectBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *objectBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,81 @@
+// infrastructure/storage/s3/s3_storage_test.go
+package s3_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/s3"
+)
+
+func TestS3Config_PublicURL(t *testing.T) {
+	cases := []struct {
+		config s3.S3Config
+		want   string
+	}{
+		{s3.S3Config{Bucket: "chat", Region: "ap-southeast-1"}, "https://chat.s3.ap-southeast-1.amazonaws.com"},
+		{s3.S3Config{Bucket: "chat", UsePathStyle: true}, "https://s3.us-east-1.amazonaws.com/chat"},
+		{s3.S3Config{Bucket: "chat", Endpoint: "http://localhost:9000/", UsePathStyle: true}, "http://localhost:9000/chat"},
+		{s3.S3Config{Bucket: "chat", Endpoint: "https://s3.example.com"}, "https://chat.s3.example.com"},
+		{s3.S3Config{Bucket: "chat", PublicURL: "https://cdn.example.com/"}, "https://cdn.example.com"},
+	}
+	for _, c := range cases {
+		if got := c.config.GetPublicURL(); got != c.want {
+			t.Errorf("GetPublicURL(%+v) = %s, want %s", c.config, got, c.want)
+		}
+	}
+}
+
+// presigned URL สร้างได้โดยไม่ต้องต่อ network
+func TestS3Storage_PresignedURLsForMinIO(t *testing.T) {
+	storage, err := s3.NewS3Storage(&s3.S3Config{
+		Endpoint:        "http://localhost:9000",
+		Bucket:          "chat",
+		AccessKeyID:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+		UsePathStyle:    true,
+		ACL:             "public-read",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upload, err := storage.GeneratePresignedUploadURL("chat/a.png", "image/png", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(upload.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "localhost:9000" || u.Path != "/chat/chat/a.png" || upload.Method != "PUT" {
+		t.Fatalf("expected a path-style PUT URL, got %s %s", upload.Method, upload.URL)
+	}
+	if u.Query().Get("X-Amz-Expires") != "600" {
+		t.Fatalf("expected 600s expiry, got %s", u.Query().Get("X-Amz-Expires"))
+	}
+	if strings.Contains(strings.ToLower(upload.URL), "checksum") {
+		t.Fatalf("presigned URL must not require SDK checksums: %s", upload.URL)
+	}
+	if upload.Fields["x-amz-acl"] != "public-read" {
+		t.Fatalf("expected the signed ACL header to be returned, got %v", upload.Fields)
+	}
+
+	download, err := storage.GeneratePresignedDownloadURL("chat/a.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(download, "http://localhost:9000/chat/chat/a.png?") {
+		t.Fatalf("unexpected download URL %s", download)
+	}
+
+	if got := storage.GetPublicURL("chat/a.png"); got != "http://localhost:9000/chat/chat/a.png" {
+		t.Fatalf("unexpected public URL %s", got)
+	}
+
+	if _, err := s3.NewS3Storage(&s3.S3Config{Bucket: "chat", ACL: "world-writable"}); err == nil {
+		t.Fatal("expected unknown ACL to be rejected")
+	}
+}
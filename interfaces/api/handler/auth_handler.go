@@ -2,20 +2,24 @@
 package handler
 
 import (
+	"log"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
 )
 
 type AuthHandler struct {
-	authService service.AuthService
+	authService         service.AuthService
+	verificationService service.VerificationService
 }
 
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, verificationService service.VerificationService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:         authService,
+		verificationService: verificationService,
 	}
 }
 
@@ -35,6 +39,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		input["password"],
 		input["email"],
 		input["display_name"],
+		deviceInfo(c, input),
 	)
 
 	if err != nil {
@@ -44,6 +49,15 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
+	// ส่งอีเมลยืนยันแบบ background (การลงทะเบียนสำเร็จแม้ส่งอีเมลไม่ได้ ผู้ใช้ขอส่งใหม่ได้)
+	if user.Email != "" && h.verificationService != nil {
+		go func() {
+			if err := h.verificationService.SendEmailVerification(user.ID); err != nil {
+				log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+			}
+		}()
+	}
+
 	// ส่งข้อมูลกลับ
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success":       true,
@@ -75,9 +89,21 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	user, accessToken, refreshToken, err := h.authService.Login(
 		input["username"],
 		input["password"],
+		deviceInfo(c, input),
 	)
 
 	if err != nil {
+		// เปิด 2FA ไว้: ส่ง challenge token ให้ยืนยันโค้ดที่ /auth/login/2fa
+		if twoFactorErr, ok := err.(*service.TwoFactorRequiredError); ok {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"success":             true,
+				"message":             "Two-factor authentication required",
+				"two_factor_required": true,
+				"challenge_token":     twoFactorErr.ChallengeToken,
+				"expires_at":          twoFactorErr.ExpiresAt,
+			})
+		}
+
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
 			"message": err.Error(),
@@ -100,6 +126,43 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	})
 }
 
+// LoginTwoFactor เข้าสู่ระบบขั้นที่สองด้วย challenge token และโค้ด 2FA (หรือโค้ดสำรอง)
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var input map[string]string
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data: " + err.Error(),
+		})
+	}
+
+	user, accessToken, refreshToken, err := h.authService.VerifyTwoFactorLogin(
+		input["challenge_token"],
+		input["code"],
+		deviceInfo(c, input),
+	)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success":       true,
+		"message":       "Login successful",
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"user": fiber.Map{
+			"id":           user.ID,
+			"username":     user.Username,
+			"email":        user.Email,
+			"display_name": user.DisplayName,
+			"status":       user.Status,
+		},
+	})
+}
+
 // Logout จัดการการออกจากระบบ
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// ดึง userUUID จาก context
@@ -125,8 +188,8 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		})
 	}
 
-	// เรียกใช้ service เพื่อทำการ logout ด้วย UUID
-	if err := h.authService.Logout(userUUID); err != nil {
+	// เรียกใช้ service เพื่อทำการ logout เฉพาะ session ของอุปกรณ์นี้
+	if err := h.authService.Logout(userUUID, middleware.GetSessionID(c)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"message": "Error logging out: " + err.Error(),
@@ -166,7 +229,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	}
 
 	// เรียกใช้ service
-	accessToken, newRefreshToken, err := h.authService.RefreshToken(refreshTokenString)
+	accessToken, newRefreshToken, err := h.authService.RefreshToken(refreshTokenString, c.IP())
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -210,6 +273,7 @@ func (h *AuthHandler) GetCurrentUser(c *fiber.Ctx) error {
 			"id":                user.ID,
 			"username":          user.Username,
 			"email":             user.Email,
+			"email_verified":    user.EmailVerifiedAt != nil,
 			"display_name":      user.DisplayName,
 			"profile_image_url": user.ProfileImageURL,
 			"bio":               user.Bio,
@@ -219,3 +283,13 @@ func (h *AuthHandler) GetCurrentUser(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// deviceInfo ข้อมูลอุปกรณ์สำหรับสร้าง session (device_name/platform จาก body, IP/User-Agent จาก request)
+func deviceInfo(c *fiber.Ctx, input map[string]string) dto.DeviceInfo {
+	return dto.DeviceInfo{
+		DeviceName: input["device_name"],
+		Platform:   input["platform"],
+		IPAddress:  c.IP(),
+		UserAgent:  c.Get("User-Agent"),
+	}
+}
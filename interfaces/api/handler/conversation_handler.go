@@ -9,6 +9,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/repository"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/domain/types"
@@ -646,6 +647,19 @@ func (h *ConversationHandler) UpdateConversation(c *fiber.Ctx) error {
 		oldConversation = nil
 	}
 
+	// ตรวจสอบสิทธิ์แก้ไขชื่อ/ไอคอนตาม permission policy ของกลุ่ม
+	if oldConversation != nil && oldConversation.Type == "group" {
+		member, err := h.conversationRepo.GetMember(conversationID, userID)
+		if err == nil && member != nil && !oldConversation.MemberCan(member, models.PermissionUpdateInfo) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success":    false,
+				"error_code": "PERMISSION_DENIED",
+				"permission": string(models.PermissionUpdateInfo),
+				"message":    "you do not have permission to change group info in this conversation",
+			})
+		}
+	}
+
 	// รับข้อมูลที่ต้องการอัปเดต
 	var input struct {
 		Title   string `json:"title"`
@@ -922,6 +936,28 @@ func (h *ConversationHandler) DeleteConversation(c *fiber.Ctx) error {
 		return err
 	}
 
+	// กลุ่ม: ออกจากกลุ่มจริง (ลบสมาชิก, โอน owner อัตโนมัติ)
+	if conversation, err := h.conversationRepo.GetByID(conversationID); err == nil && conversation != nil && conversation.Type == "group" {
+		result, err := h.conversationService.LeaveGroup(conversationID, userID)
+		if err != nil {
+			return leaveGroupErrorResponse(c, err)
+		}
+
+		notifyMemberLeft(h.notificationService, h.groupActivityService, result)
+
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Left conversation successfully",
+			"data": fiber.Map{
+				"conversation_id":   conversationID.String(),
+				"action":            "left",
+				"message":           "Left conversation successfully",
+				"new_owner_id":      result.NewOwnerID,
+				"group_deactivated": result.GroupDeactivated,
+			},
+		})
+	}
+
 	action, err := h.conversationService.DeleteConversation(conversationID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -1135,6 +1171,78 @@ func (h *ConversationHandler) TransferOwnership(c *fiber.Ctx) error {
 	})
 }
 
+// SetMessageTTL ตั้งค่าข้อความหายไปอัตโนมัติ (มีผลกับข้อความใหม่)
+// PATCH /conversations/:conversationId/message-ttl
+func (h *ConversationHandler) SetMessageTTL(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var input dto.ConversationMessageTTLRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data: " + err.Error(),
+		})
+	}
+	if input.TTLSeconds == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "ttl_seconds is required",
+		})
+	}
+
+	oldTTL, err := h.conversationService.SetMessageTTL(conversationID, userID, *input.TTLSeconds)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "conversation not found":
+			statusCode = fiber.StatusNotFound
+		case "user is not a member of this conversation",
+			"only owner or admin can change message ttl":
+			statusCode = fiber.StatusForbidden
+		case "message ttl must be between 1 hour and 365 days":
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	if oldTTL != *input.TTLSeconds {
+		// ส่ง WebSocket notification แจ้งสมาชิกทุกคน
+		h.notificationService.NotifyConversationUpdated(conversationID, types.JSONB{
+			"conversation_id":     conversationID.String(),
+			"message_ttl_seconds": *input.TTLSeconds,
+		})
+
+		// บันทึก activity log
+		if err := h.groupActivityService.LogMessageTTLChanged(conversationID, userID, oldTTL, *input.TTLSeconds); err != nil {
+			fmt.Printf("Failed to log message ttl change: %v\n", err)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Message TTL updated successfully",
+		"data": fiber.Map{
+			"conversation_id":     conversationID.String(),
+			"message_ttl_seconds": *input.TTLSeconds,
+		},
+	})
+}
+
 // GetActivities ดึง activity log ของ conversation
 // GET /conversations/:conversationId/activities
 func (h *ConversationHandler) GetActivities(c *fiber.Ctx) error {
@@ -1248,3 +1356,91 @@ func (h *ConversationHandler) GetMessagesByDate(c *fiber.Ctx) error {
 
 //for business conversation
 
+// GetNotificationSettings ดึงการตั้งค่าการแจ้งเตือนของผู้ใช้ในการสนทนา
+// GET /api/v1/conversations/:conversationId/notification-settings
+func (h *ConversationHandler) GetNotificationSettings(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	settings, err := h.conversationService.GetNotificationSettings(conversationID, userID)
+	if err != nil {
+		return c.Status(notificationSettingsErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// UpdateNotificationSettings ตั้งค่าการแจ้งเตือน (all / mentions / none, ปิดเสียงชั่วคราว, keyword)
+// PUT /api/v1/conversations/:conversationId/notification-settings
+func (h *ConversationHandler) UpdateNotificationSettings(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var input dto.NotificationSettingsRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data: " + err.Error(),
+		})
+	}
+
+	settings, err := h.conversationService.UpdateNotificationSettings(conversationID, userID, &input)
+	if err != nil {
+		return c.Status(notificationSettingsErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	// sync การตั้งค่าไปยังอุปกรณ์อื่นของผู้ใช้
+	h.notificationService.NotifyConversationUpdatedToUser(userID, fiber.Map{
+		"conversation_id":       conversationID.String(),
+		"notification_settings": settings,
+	})
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Notification settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// notificationSettingsErrorStatus maps notification settings errors to HTTP status codes
+func notificationSettingsErrorStatus(err error) int {
+	switch err.Error() {
+	case "you are not a member of this conversation":
+		return fiber.StatusForbidden
+	case "invalid notification level",
+		"keyword is too long",
+		"too many keywords":
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
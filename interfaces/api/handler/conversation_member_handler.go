@@ -2,11 +2,17 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/thizplus/gofiber-chat-api/domain/dto"
 	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
 )
 
 // ConversationMemberHandler จัดการคำขอสำหรับการจัดการสมาชิกในการสนทนา
@@ -85,6 +91,10 @@ func (h *ConversationMemberHandler) AddConversationMember(c *fiber.Ctx) error {
 	// 5. เรียกใช้ service
 	memberDTO, err := h.memberService.AddMember(userID, conversationID, newMemberID)
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		// จัดการรหัสสถานะตามข้อผิดพลาด
 		statusCode := fiber.StatusInternalServerError
 		switch err.Error() {
@@ -92,7 +102,7 @@ func (h *ConversationMemberHandler) AddConversationMember(c *fiber.Ctx) error {
 			statusCode = fiber.StatusConflict
 		case "user to add not found":
 			statusCode = fiber.StatusNotFound
-		case "only admins can add members", "you are not a member of this conversation":
+		case "you are not a member of this conversation":
 			statusCode = fiber.StatusForbidden
 		case "cannot add members to direct conversation":
 			statusCode = fiber.StatusBadRequest
@@ -184,10 +194,14 @@ func (h *ConversationMemberHandler) BulkAddConversationMembers(c *fiber.Ctx) err
 	// 5. เรียกใช้ service
 	addedMembers, failedMembers, err := h.memberService.BulkAddMembers(userID, conversationID, memberIDs)
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		// จัดการรหัสสถานะตามข้อผิดพลาด
 		statusCode := fiber.StatusInternalServerError
 		switch err.Error() {
-		case "only admins can add members", "you are not a member of this conversation":
+		case "you are not a member of this conversation":
 			statusCode = fiber.StatusForbidden
 		case "cannot add members to direct conversation":
 			statusCode = fiber.StatusBadRequest
@@ -344,6 +358,22 @@ func (h *ConversationMemberHandler) RemoveConversationMember(c *fiber.Ctx) error
 		})
 	}
 
+	// ออกจากกลุ่มด้วยตัวเอง
+	if targetUserID == userID {
+		result, err := h.memberService.LeaveGroup(conversationID, userID)
+		if err != nil {
+			return leaveGroupErrorResponse(c, err)
+		}
+
+		notifyMemberLeft(h.notificationService, h.groupActivityService, result)
+
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Left conversation successfully",
+			"data":    result,
+		})
+	}
+
 	// 4. เรียกใช้ service
 	err = h.memberService.RemoveMember(userID, conversationID, targetUserID)
 	if err != nil {
@@ -575,3 +605,213 @@ func (h *ConversationMemberHandler) ChangeRole(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetPermissions ดึง permission policy ของกลุ่ม พร้อมสิทธิ์ของผู้ใช้เอง
+// GET /conversations/:conversationId/permissions
+func (h *ConversationMemberHandler) GetPermissions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized",
+		})
+	}
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid conversation ID",
+		})
+	}
+
+	policy, err := h.memberService.GetPermissionPolicy(conversationID, userID)
+	if err != nil {
+		statusCode := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "conversation not found":
+			statusCode = fiber.StatusNotFound
+		case "user is not a member of this conversation":
+			statusCode = fiber.StatusForbidden
+		case "permissions are only available for group conversations":
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Permissions retrieved successfully",
+		"data":    policy,
+	})
+}
+
+// UpdatePermissions เปลี่ยน role ขั้นต่ำของสิทธิ์ในกลุ่ม (owner เท่านั้น)
+// PUT /conversations/:conversationId/permissions
+func (h *ConversationMemberHandler) UpdatePermissions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Locals("userID").(string))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized",
+		})
+	}
+
+	conversationID, err := uuid.Parse(c.Params("conversationId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid conversation ID",
+		})
+	}
+
+	var input dto.UpdatePermissionPolicyRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data: " + err.Error(),
+		})
+	}
+
+	policy, oldValues, err := h.memberService.UpdatePermissionPolicy(conversationID, userID, &input)
+	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
+		statusCode := fiber.StatusInternalServerError
+		switch {
+		case err.Error() == "conversation not found":
+			statusCode = fiber.StatusNotFound
+		case err.Error() == "user is not a member of this conversation":
+			statusCode = fiber.StatusForbidden
+		case err.Error() == "permissions are only available for group conversations",
+			err.Error() == "no permissions to update",
+			errors.Is(err, models.ErrUnknownPermission),
+			errors.Is(err, models.ErrPermissionNotConfigurable),
+			errors.Is(err, models.ErrInvalidPermissionRole):
+			statusCode = fiber.StatusBadRequest
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	if len(oldValues) > 0 {
+		newValues := make(map[string]string, len(oldValues))
+		for permission := range oldValues {
+			newValues[permission] = policy.Permissions[permission]
+		}
+
+		// ส่ง WebSocket notification แจ้งสมาชิกทุกคน
+		h.notificationService.NotifyConversationUpdated(conversationID, types.JSONB{
+			"conversation_id": conversationID.String(),
+			"permissions":     policy.Permissions,
+		})
+
+		// บันทึก activity log
+		if err := h.groupActivityService.LogPermissionsChanged(conversationID, userID, oldValues, newValues); err != nil {
+			println("⚠️ [UpdatePermissions] Failed to log activity:", err.Error())
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Permissions updated successfully",
+		"data":    policy,
+	})
+}
+
+// notifyMemberLeft แจ้งสมาชิกและบันทึก activity เมื่อมีคนออกจากกลุ่ม (รวมถึงการโอน owner อัตโนมัติ)
+func notifyMemberLeft(notificationService service.NotificationService, groupActivityService service.GroupActivityService, result *dto.LeaveGroupResultDTO) {
+	// แจ้งสมาชิกที่เหลือ และผู้ที่ออกเพื่อหยุดรับ event ของกลุ่ม
+	notificationService.NotifyUserRemovedFromConversation(result.UserID, result.ConversationID)
+
+	if result.NewOwnerID != nil {
+		notificationService.NotifyOwnershipTransferred(result.ConversationID, result.UserID, *result.NewOwnerID)
+		if err := groupActivityService.LogOwnershipTransferred(result.ConversationID, result.UserID, *result.NewOwnerID); err != nil {
+			println("⚠️ [LeaveGroup] Failed to log ownership transfer:", err.Error())
+		}
+	}
+
+	if err := groupActivityService.LogMemberLeft(result.ConversationID, result.UserID); err != nil {
+		println("⚠️ [LeaveGroup] Failed to log activity:", err.Error())
+	}
+}
+
+// leaveGroupErrorResponse แปลง error ของการออกจากกลุ่มเป็น response
+func leaveGroupErrorResponse(c *fiber.Ctx, err error) error {
+	statusCode := fiber.StatusInternalServerError
+	switch err.Error() {
+	case "conversation not found":
+		statusCode = fiber.StatusNotFound
+	case "you are not a member of this conversation":
+		statusCode = fiber.StatusForbidden
+	case "only group conversations can be left":
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
+
+// asPermissionError ตรวจว่า error มาจาก permission policy ของการสนทนาหรือไม่
+func asPermissionError(err error) (*service.PermissionError, bool) {
+	var permErr *service.PermissionError
+	if errors.As(err, &permErr) {
+		return permErr, true
+	}
+	return nil, false
+}
+
+// permissionDeniedResponse ตอบ 403 พร้อม error_code และสิทธิ์ที่ขาด
+func permissionDeniedResponse(c *fiber.Ctx, permErr *service.PermissionError) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success":    false,
+		"error_code": "PERMISSION_DENIED",
+		"permission": string(permErr.Permission),
+		"message":    permErr.Message,
+	})
+}
+
+// asSendRestrictionError ตรวจว่า error มาจากการถูกจำกัดการส่งข้อความหรือ slow mode หรือไม่
+func asSendRestrictionError(err error) (*service.SendRestrictionError, bool) {
+	var restrictErr *service.SendRestrictionError
+	if errors.As(err, &restrictErr) {
+		return restrictErr, true
+	}
+	return nil, false
+}
+
+// sendRestrictedResponse ตอบ 403 (ถูกจำกัดการส่ง) หรือ 429 (slow mode) พร้อมเวลาที่ส่งได้อีกครั้ง
+func sendRestrictedResponse(c *fiber.Ctx, restrictErr *service.SendRestrictionError) error {
+	if restrictErr.Reason == service.SendRestrictionSlowMode {
+		retryAfter := int(math.Ceil(time.Until(restrictErr.Until).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"success":     false,
+			"error_code":  "SLOW_MODE",
+			"retry_after": retryAfter,
+			"until":       restrictErr.Until,
+			"message":     restrictErr.Message,
+		})
+	}
+
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success":    false,
+		"error_code": "SEND_RESTRICTED",
+		"until":      restrictErr.Until,
+		"message":    restrictErr.Message,
+	})
+}
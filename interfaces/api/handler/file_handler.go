@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,13 +29,15 @@ const (
 type FileHandler struct {
 	storageService   service.FileStorageService
 	fileUploadRepo   repository.FileUploadRepository
+	imageService     service.ImageService
 }
 
 // NewFileHandler สร้าง FileHandler ใหม่
-func NewFileHandler(storageService service.FileStorageService, fileUploadRepo repository.FileUploadRepository) *FileHandler {
+func NewFileHandler(storageService service.FileStorageService, fileUploadRepo repository.FileUploadRepository, imageService service.ImageService) *FileHandler {
 	return &FileHandler{
 		storageService:   storageService,
 		fileUploadRepo:   fileUploadRepo,
+		imageService:     imageService,
 	}
 }
 
@@ -61,6 +64,29 @@ func (h *FileHandler) UploadImage(c *fiber.Ctx) error {
 		})
 	}
 
+	// ลบ metadata, หมุนภาพ, สร้าง thumbnail/medium และ blurhash
+	// ไฟล์ที่ลบ metadata ไม่ได้ (เช่น HEIC) ถูกลบทิ้งและปฏิเสธ เพื่อไม่ให้พิกัด GPS หลุดออกไป
+	if h.imageService != nil {
+		userID, _ := middleware.GetUserUUID(c)
+		processed, err := h.imageService.ProcessUploadedImage(userID, file.Filename, file.Header.Get("Content-Type"), result)
+		if err != nil {
+			if status := imageProcessingErrorStatus(result.Path, err); status != 0 {
+				h.storageService.DeleteFile(result.Path)
+				return c.Status(status).JSON(fiber.Map{
+					"success": false,
+					"message": "ไม่สามารถประมวลผลรูปภาพได้: " + err.Error(),
+				})
+			}
+		} else {
+			result.Width = processed.Width
+			result.Height = processed.Height
+			result.Size = int(processed.Size)
+			result.Metadata["thumbnail_url"] = processed.ThumbnailURL
+			result.Metadata["medium_url"] = processed.MediumURL
+			result.Metadata["blurhash"] = processed.BlurHash
+		}
+	}
+
 	// ส่งผลลัพธ์กลับไป
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -293,8 +319,11 @@ func (h *FileHandler) PrepareUpload(c *fiber.Ctx) error {
 	uniqueID := uuid.New().String()[:8]
 	filename := nameWithoutExt + "_" + uniqueID + ext
 
-	// สร้าง path
+	// สร้าง path (รูปภาพพักไว้ใน staging จนกว่าจะยืนยันและลบ metadata แล้ว)
 	path := filepath.ToSlash(filepath.Join(req.Folder, filename))
+	if h.imageService != nil && strings.HasPrefix(req.ContentType, "image/") {
+		path = h.imageService.StagingPath(path)
+	}
 
 	// สร้าง presigned URL
 	result, err := h.storageService.GeneratePresignedUploadURL(path, req.ContentType, DefaultPresignedExpiry)
@@ -410,6 +439,22 @@ func (h *FileHandler) ConfirmUpload(c *fiber.Ctx) error {
 		})
 	}
 
+	// รูปภาพ: ผ่าน image pipeline ก่อนยืนยัน เพื่อให้ URL ที่เปิดเผยชี้ไปที่ไฟล์ที่ลบ metadata แล้วเท่านั้น
+	// รูปที่ลบ metadata ไม่ได้ถูกลบทิ้งและ mark เป็น failed
+	if h.imageService != nil && strings.HasPrefix(upload.ContentType, "image/") {
+		if _, err := h.imageService.ProcessConfirmedUpload(upload); err != nil {
+			if status := imageProcessingErrorStatus(upload.Path, err); status != 0 {
+				h.fileUploadRepo.UpdateStatus(uploadID, models.FileUploadStatusFailed)
+				h.storageService.DeleteFile(upload.Path)
+
+				return c.Status(status).JSON(fiber.Map{
+					"success": false,
+					"message": "Image processing failed: " + err.Error(),
+				})
+			}
+		}
+	}
+
 	// Get public URL
 	publicURL := h.storageService.GetPublicURL(upload.Path)
 
@@ -429,14 +474,33 @@ func (h *FileHandler) ConfirmUpload(c *fiber.Ctx) error {
 		"success": true,
 		"message": "Upload confirmed successfully",
 		"data": fiber.Map{
-			"id":           upload.ID,
-			"url":          upload.URL,
-			"path":         upload.Path,
-			"filename":     upload.Filename,
-			"content_type": upload.ContentType,
-			"size":         upload.Size,
-			"status":       upload.Status,
-			"uploaded_at":  upload.CompletedAt,
+			"id":            upload.ID,
+			"url":           upload.URL,
+			"path":          upload.Path,
+			"filename":      upload.Filename,
+			"content_type":  upload.ContentType,
+			"size":          upload.Size,
+			"status":        upload.Status,
+			"uploaded_at":   upload.CompletedAt,
+			"width":         upload.Width,
+			"height":        upload.Height,
+			"thumbnail_url": upload.ThumbnailURL,
+			"medium_url":    upload.MediumURL,
+			"blurhash":      upload.BlurHash,
 		},
 	})
 }
+
+// imageProcessingErrorStatus บันทึกข้อผิดพลาดของ image pipeline และคืน HTTP status ที่ใช้ปฏิเสธไฟล์
+// คืน 0 เมื่อ storage ไม่รองรับ (Cloudinary ประมวลผลรูปเองและลบ metadata ให้แล้ว) ซึ่งใช้ไฟล์เดิมได้
+// ไฟล์ที่ไม่ใช่ jpeg/png/gif/webp (เช่น HEIC) ลบ metadata ไม่ได้จึงได้ 415
+func imageProcessingErrorStatus(path string, err error) int {
+	switch err.Error() {
+	case "image processing is not supported by storage":
+		return 0
+	case "unsupported image format":
+		return fiber.StatusUnsupportedMediaType
+	}
+	log.Printf("Image processing failed for %s: %v", path, err)
+	return fiber.StatusUnprocessableEntity
+}
@@ -0,0 +1,311 @@
+// interfaces/api/handler/group_invite_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+	"github.com/thizplus/gofiber-chat-api/pkg/utils"
+)
+
+// GroupInviteHandler handles group invite link and join request HTTP requests
+type GroupInviteHandler struct {
+	inviteService        service.GroupInviteService
+	notificationService  service.NotificationService
+	groupActivityService service.GroupActivityService
+}
+
+// NewGroupInviteHandler creates a new group invite handler
+func NewGroupInviteHandler(
+	inviteService service.GroupInviteService,
+	notificationService service.NotificationService,
+	groupActivityService service.GroupActivityService,
+) *GroupInviteHandler {
+	return &GroupInviteHandler{
+		inviteService:        inviteService,
+		notificationService:  notificationService,
+		groupActivityService: groupActivityService,
+	}
+}
+
+// CreateInvite creates a shareable invite link for a group
+// POST /api/v1/conversations/:conversationId/invites
+func (h *GroupInviteHandler) CreateInvite(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreateGroupInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	invite, err := h.inviteService.CreateInvite(conversationID, userID, &req)
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	if err := h.groupActivityService.LogInviteCreated(conversationID, userID, invite); err != nil {
+		println("⚠️ [CreateInvite] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Invite link created successfully",
+		"data":    invite,
+	})
+}
+
+// ListInvites lists all invite links of a group, including inactive ones
+// GET /api/v1/conversations/:conversationId/invites
+func (h *GroupInviteHandler) ListInvites(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	invites, err := h.inviteService.ListInvites(conversationID, userID)
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Invite links retrieved successfully",
+		"data":    invites,
+	})
+}
+
+// RevokeInvite revokes an invite link
+// DELETE /api/v1/conversations/:conversationId/invites/:inviteId
+func (h *GroupInviteHandler) RevokeInvite(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	inviteID, err := utils.ParseUUIDParam(c, "inviteId")
+	if err != nil {
+		return err
+	}
+
+	invite, err := h.inviteService.RevokeInvite(conversationID, inviteID, userID)
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	if err := h.groupActivityService.LogInviteRevoked(conversationID, userID, inviteID); err != nil {
+		println("⚠️ [RevokeInvite] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Invite link revoked successfully",
+		"data":    invite,
+	})
+}
+
+// JoinByToken joins a group through an invite link, or queues a join request
+// when the link requires approval
+// POST /api/v1/invites/:token/join
+func (h *GroupInviteHandler) JoinByToken(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	token := c.Params("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invite token is required",
+		})
+	}
+
+	result, err := h.inviteService.JoinByToken(token, userID)
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	if result.Status == dto.JoinStatusPending {
+		h.notificationService.NotifyJoinRequestCreated(result.ConversationID, result.JoinRequest)
+		if err := h.groupActivityService.LogJoinRequested(result.ConversationID, userID, result.JoinRequest.ID); err != nil {
+			println("⚠️ [JoinByToken] Failed to log activity:", err.Error())
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"success": true,
+			"message": "Join request sent, waiting for approval",
+			"data":    result,
+		})
+	}
+
+	h.notificationService.NotifyUserAddedToConversation(result.ConversationID, userID)
+	if err := h.groupActivityService.LogMemberJoined(result.ConversationID, userID, result.InviteID); err != nil {
+		println("⚠️ [JoinByToken] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Joined group successfully",
+		"data":    result,
+	})
+}
+
+// ListJoinRequests lists join requests of a group (?status=pending|approved|rejected|all, default pending)
+// GET /api/v1/conversations/:conversationId/join-requests
+func (h *GroupInviteHandler) ListJoinRequests(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	requests, err := h.inviteService.ListJoinRequests(conversationID, userID, c.Query("status"))
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Join requests retrieved successfully",
+		"data":    requests,
+	})
+}
+
+// ApproveJoinRequest approves a pending join request and adds the requester to the group
+// POST /api/v1/conversations/:conversationId/join-requests/:requestId/approve
+func (h *GroupInviteHandler) ApproveJoinRequest(c *fiber.Ctx) error {
+	return h.reviewJoinRequest(c, true)
+}
+
+// RejectJoinRequest rejects a pending join request
+// POST /api/v1/conversations/:conversationId/join-requests/:requestId/reject
+func (h *GroupInviteHandler) RejectJoinRequest(c *fiber.Ctx) error {
+	return h.reviewJoinRequest(c, false)
+}
+
+func (h *GroupInviteHandler) reviewJoinRequest(c *fiber.Ctx, approve bool) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	requestID, err := utils.ParseUUIDParam(c, "requestId")
+	if err != nil {
+		return err
+	}
+
+	var request *dto.GroupJoinRequestDTO
+	if approve {
+		request, err = h.inviteService.ApproveJoinRequest(conversationID, requestID, userID)
+	} else {
+		request, err = h.inviteService.RejectJoinRequest(conversationID, requestID, userID)
+	}
+	if err != nil {
+		return groupInviteErrorResponse(c, err)
+	}
+
+	h.notificationService.NotifyJoinRequestReviewed(request.UserID, request)
+	if approve {
+		h.notificationService.NotifyUserAddedToConversation(conversationID, request.UserID)
+	}
+	if err := h.groupActivityService.LogJoinRequestReviewed(conversationID, userID, request.UserID, request.ID, approve); err != nil {
+		println("⚠️ [ReviewJoinRequest] Failed to log activity:", err.Error())
+	}
+
+	message := "Join request rejected"
+	if approve {
+		message = "Join request approved"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    request,
+	})
+}
+
+// groupInviteErrorResponse maps group invite service errors to HTTP responses
+func groupInviteErrorResponse(c *fiber.Ctx, err error) error {
+	if permErr, ok := asPermissionError(err); ok {
+		return permissionDeniedResponse(c, permErr)
+	}
+
+	statusCode := fiber.StatusInternalServerError
+	switch err.Error() {
+	case "conversation not found",
+		"invite not found",
+		"join request not found":
+		statusCode = fiber.StatusNotFound
+	case "user is not a member of this conversation",
+		"you are banned from this group":
+		statusCode = fiber.StatusForbidden
+	case "you are already a member of this conversation",
+		"you already have a pending request to join this group",
+		"join request has already been reviewed",
+		"user is banned from this group":
+		statusCode = fiber.StatusConflict
+	case "group is no longer available",
+		"invite link has been revoked",
+		"invite link has expired",
+		"invite link has reached its usage limit":
+		statusCode = fiber.StatusGone
+	case "invite links are only available for group conversations",
+		"max_uses cannot be negative",
+		"expires_at must be in the future",
+		"invalid join request status":
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
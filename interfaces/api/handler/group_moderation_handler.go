@@ -0,0 +1,316 @@
+// interfaces/api/handler/group_moderation_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/domain/types"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+	"github.com/thizplus/gofiber-chat-api/pkg/utils"
+)
+
+// GroupModerationHandler handles group ban, send restriction and slow mode HTTP requests
+type GroupModerationHandler struct {
+	moderationService    service.GroupModerationService
+	notificationService  service.NotificationService
+	groupActivityService service.GroupActivityService
+}
+
+// NewGroupModerationHandler creates a new group moderation handler
+func NewGroupModerationHandler(
+	moderationService service.GroupModerationService,
+	notificationService service.NotificationService,
+	groupActivityService service.GroupActivityService,
+) *GroupModerationHandler {
+	return &GroupModerationHandler{
+		moderationService:    moderationService,
+		notificationService:  notificationService,
+		groupActivityService: groupActivityService,
+	}
+}
+
+// BanMember bans a user from a group, removing them if they are still a member
+// POST /api/v1/conversations/:conversationId/bans
+func (h *GroupModerationHandler) BanMember(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.BanMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := h.moderationService.BanMember(conversationID, userID, &req)
+	if err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	if result.RemovedMember {
+		h.notificationService.NotifyUserRemovedFromConversation(req.UserID, conversationID)
+	}
+	if err := h.groupActivityService.LogMemberBanned(conversationID, userID, result.Ban, result.RemovedMember); err != nil {
+		println("⚠️ [BanMember] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "User banned successfully",
+		"data":    result,
+	})
+}
+
+// UnbanMember lifts a ban so the user can be added or join again
+// DELETE /api/v1/conversations/:conversationId/bans/:userId
+func (h *GroupModerationHandler) UnbanMember(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	targetID, err := utils.ParseUUIDParam(c, "userId")
+	if err != nil {
+		return err
+	}
+
+	if err := h.moderationService.UnbanMember(conversationID, userID, targetID); err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	if err := h.groupActivityService.LogMemberUnbanned(conversationID, userID, targetID); err != nil {
+		println("⚠️ [UnbanMember] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "User unbanned successfully",
+	})
+}
+
+// ListBans lists the active bans of a group
+// GET /api/v1/conversations/:conversationId/bans
+func (h *GroupModerationHandler) ListBans(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	bans, err := h.moderationService.ListBans(conversationID, userID)
+	if err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Bans retrieved successfully",
+		"data":    bans,
+	})
+}
+
+// RestrictMember stops a member from sending messages until the given time
+// PUT /api/v1/conversations/:conversationId/members/:userId/restriction
+func (h *GroupModerationHandler) RestrictMember(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	targetID, err := utils.ParseUUIDParam(c, "userId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.RestrictMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	restriction, err := h.moderationService.RestrictMember(conversationID, userID, targetID, req.Until)
+	if err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	h.notificationService.NotifyConversationUpdatedToUser(targetID, types.JSONB{
+		"conversation_id":       conversationID.String(),
+		"send_restricted_until": restriction.SendRestrictedUntil,
+	})
+	if err := h.groupActivityService.LogMemberRestricted(conversationID, userID, targetID, req.Until); err != nil {
+		println("⚠️ [RestrictMember] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Member restricted successfully",
+		"data":    restriction,
+	})
+}
+
+// UnrestrictMember lifts a member's send restriction
+// DELETE /api/v1/conversations/:conversationId/members/:userId/restriction
+func (h *GroupModerationHandler) UnrestrictMember(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	targetID, err := utils.ParseUUIDParam(c, "userId")
+	if err != nil {
+		return err
+	}
+
+	restriction, err := h.moderationService.UnrestrictMember(conversationID, userID, targetID)
+	if err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	h.notificationService.NotifyConversationUpdatedToUser(targetID, types.JSONB{
+		"conversation_id":       conversationID.String(),
+		"send_restricted_until": nil,
+	})
+	if err := h.groupActivityService.LogMemberUnrestricted(conversationID, userID, targetID); err != nil {
+		println("⚠️ [UnrestrictMember] Failed to log activity:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Member restriction removed successfully",
+		"data":    restriction,
+	})
+}
+
+// SetSlowMode sets the minimum interval between messages from each member (0 disables it)
+// PUT /api/v1/conversations/:conversationId/slow-mode
+func (h *GroupModerationHandler) SetSlowMode(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.SlowModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+	if req.Seconds == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "seconds is required",
+		})
+	}
+
+	oldSeconds, err := h.moderationService.SetSlowMode(conversationID, userID, *req.Seconds)
+	if err != nil {
+		return groupModerationErrorResponse(c, err)
+	}
+
+	if oldSeconds != *req.Seconds {
+		h.notificationService.NotifyConversationUpdated(conversationID, types.JSONB{
+			"conversation_id":   conversationID.String(),
+			"slow_mode_seconds": *req.Seconds,
+		})
+		if err := h.groupActivityService.LogSlowModeChanged(conversationID, userID, oldSeconds, *req.Seconds); err != nil {
+			println("⚠️ [SetSlowMode] Failed to log activity:", err.Error())
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Slow mode updated successfully",
+		"data": fiber.Map{
+			"conversation_id":   conversationID.String(),
+			"slow_mode_seconds": *req.Seconds,
+		},
+	})
+}
+
+// groupModerationErrorResponse maps group moderation service errors to HTTP responses
+func groupModerationErrorResponse(c *fiber.Ctx, err error) error {
+	if permErr, ok := asPermissionError(err); ok {
+		return permissionDeniedResponse(c, permErr)
+	}
+
+	statusCode := fiber.StatusInternalServerError
+	switch err.Error() {
+	case "conversation not found",
+		"user not found",
+		"member not found",
+		"ban not found":
+		statusCode = fiber.StatusNotFound
+	case "user is not a member of this conversation",
+		"you can only moderate members with a lower role":
+		statusCode = fiber.StatusForbidden
+	case "member is not restricted":
+		statusCode = fiber.StatusConflict
+	case "moderation is only available for group conversations",
+		"user_id is required",
+		"reason must be at most 500 characters",
+		"expires_at must be in the future",
+		"until must be in the future",
+		"you cannot moderate yourself",
+		"slow mode must be between 0 and 3600 seconds":
+		statusCode = fiber.StatusBadRequest
+	}
+
+	return c.Status(statusCode).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,120 @@
+// interfaces/api/handler/local_storage_handler.go
+package handler
+
+import (
+	"bytes"
+	"mime"
+	"net/url"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// LocalStorageHandler serves files and presigned uploads for the local storage driver
+type LocalStorageHandler struct {
+	storage service.SignedFileServer
+}
+
+// NewLocalStorageHandler creates a new local storage handler
+func NewLocalStorageHandler(storage service.SignedFileServer) *LocalStorageHandler {
+	return &LocalStorageHandler{storage: storage}
+}
+
+// ServeFile streams a stored file (signature required when private download is enabled)
+// GET /storage/*
+func (h *LocalStorageHandler) ServeFile(c *fiber.Ctx) error {
+	filePath, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "invalid file path",
+		})
+	}
+
+	signed := c.Query("signature") != ""
+	if signed || h.storage.RequiresSignedDownload() {
+		if err := h.storage.VerifySignedURL(fiber.MethodGet, filePath, "", c.Query("expires"), c.Query("signature")); err != nil {
+			return c.Status(localStorageErrorStatus(err)).JSON(fiber.Map{
+				"success": false,
+				"message": err.Error(),
+			})
+		}
+	}
+
+	file, size, err := h.storage.OpenFile(filePath)
+	if err != nil {
+		return c.Status(localStorageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+
+	// ไฟล์ที่ผู้ใช้อัปโหลดเสิร์ฟจาก origin เดียวกับ API ห้ามให้ browser รันเป็นหน้าเว็บ
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentSecurityPolicy, "sandbox")
+
+	if signed {
+		c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	}
+
+	return c.SendStream(file, int(size))
+}
+
+// UploadFile stores the request body at a presigned upload URL
+// PUT /storage/*
+func (h *LocalStorageHandler) UploadFile(c *fiber.Ctx) error {
+	filePath, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "invalid file path",
+		})
+	}
+
+	// Content-Type ต้องตรงกับที่ลงชื่อไว้ตอนสร้าง URL
+	if err := h.storage.VerifySignedURL(fiber.MethodPut, filePath, c.Get(fiber.HeaderContentType), c.Query("expires"), c.Query("signature")); err != nil {
+		return c.Status(localStorageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	size, err := h.storage.WriteFile(filePath, bytes.NewReader(c.Body()))
+	if err != nil {
+		return c.Status(localStorageErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "File uploaded successfully",
+		"data": fiber.Map{
+			"path": filePath,
+			"size": size,
+		},
+	})
+}
+
+// localStorageErrorStatus maps local storage errors to HTTP status codes
+func localStorageErrorStatus(err error) int {
+	switch err.Error() {
+	case "invalid file path":
+		return fiber.StatusBadRequest
+	case "invalid signature", "signed url has expired":
+		return fiber.StatusForbidden
+	case "file not found":
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
@@ -15,9 +15,13 @@ import (
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/domain/types"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
 	"github.com/thizplus/gofiber-chat-api/pkg/utils"
 )
 
+// ความยาว (ตัวอักษร) ของ snippet ในผลการค้นหา
+const searchSnippetLength = 120
+
 // MessageHandler โครงสร้างของ Handler สำหรับจัดการข้อความ
 type MessageHandler struct {
 	messageService            service.MessageService
@@ -25,6 +29,8 @@ type MessageHandler struct {
 	conversationMemberService service.ConversationMemberService
 	conversationRepo          repository.ConversationRepository
 	userFriendshipService     service.UserFriendshipService
+	mediaAccess               service.MediaAccessService
+	conversationService       service.ConversationService
 }
 
 // NewMessageHandler สร้าง Handler ใหม่
@@ -34,6 +40,8 @@ func NewMessageHandler(
 	conversationMemberService service.ConversationMemberService,
 	conversationRepo repository.ConversationRepository,
 	userFriendshipService service.UserFriendshipService,
+	mediaAccess service.MediaAccessService,
+	conversationService service.ConversationService,
 ) *MessageHandler {
 	return &MessageHandler{
 		messageService:            messageService,
@@ -41,7 +49,19 @@ func NewMessageHandler(
 		conversationMemberService: conversationMemberService,
 		conversationRepo:          conversationRepo,
 		userFriendshipService:     userFriendshipService,
+		mediaAccess:               mediaAccess,
+		conversationService:       conversationService,
+	}
+}
+
+// messageResponse แปลงข้อความที่เพิ่งส่งหรือแก้ไขเป็น DTO แบบเดียวกับตอนดึงข้อความ
+// (โหมด private media: path ของไฟล์แนบถูกแทนด้วย URL ที่มีอายุ)
+func (h *MessageHandler) messageResponse(message *models.Message, userID uuid.UUID) interface{} {
+	messageDTO, err := h.conversationService.ConvertToMessageDTO(message, userID)
+	if err != nil {
+		return message
 	}
+	return messageDTO
 }
 
 // BlockError custom error type สำหรับ block errors with error code
@@ -192,6 +212,13 @@ func (h *MessageHandler) SendTextMessage(c *fiber.Ctx) error {
 	// เรียกใช้ service
 	message, err := h.messageService.SendTextMessage(conversationID, userID, input.Content, metadata)
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาดเพื่อกำหนด status code ที่เหมาะสม
 		if err.Error() == "user is not a member of this conversation" {
@@ -218,7 +245,7 @@ func (h *MessageHandler) SendTextMessage(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Message sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -299,6 +326,13 @@ func (h *MessageHandler) SendStickerMessage(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาด
 		if err.Error() == "user is not a member of this conversation" {
@@ -318,7 +352,7 @@ func (h *MessageHandler) SendStickerMessage(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Sticker sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -397,6 +431,13 @@ func (h *MessageHandler) SendImageMessage(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาด
 		if err.Error() == "user is not a member of this conversation" {
@@ -416,7 +457,7 @@ func (h *MessageHandler) SendImageMessage(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Image sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -497,6 +538,13 @@ func (h *MessageHandler) SendFileMessage(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาด
 		if err.Error() == "user is not a member of this conversation" {
@@ -516,7 +564,7 @@ func (h *MessageHandler) SendFileMessage(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "File sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -593,6 +641,13 @@ func (h *MessageHandler) SendBulkMessages(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		fmt.Printf("❌ [SendBulkMessages] Service error: %v\n", err)
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาด
@@ -615,7 +670,7 @@ func (h *MessageHandler) SendBulkMessages(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Album sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -718,7 +773,7 @@ func (h *MessageHandler) EditMessage(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Message updated successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -888,6 +943,13 @@ func (h *MessageHandler) ReplyToMessage(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		// ตรวจสอบประเภทข้อผิดพลาด
 		if err.Error() == "message not found" {
@@ -909,7 +971,7 @@ func (h *MessageHandler) ReplyToMessage(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
 		"message": "Reply sent successfully",
-		"data":    message,
+		"data":    h.messageResponse(message, userID),
 	})
 }
 
@@ -935,11 +997,14 @@ func (h *MessageHandler) PinMessage(c *fiber.Ctx) error {
 
 	// ปักหมุดข้อความ
 	if err := h.messageService.PinMessage(messageID, conversationID, userID); err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "message not found" {
 			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "user is not a member of this conversation" ||
-			err.Error() == "only owner/admin can pin messages in group conversations" {
+		} else if err.Error() == "user is not a member of this conversation" {
 			statusCode = fiber.StatusForbidden
 		}
 
@@ -983,11 +1048,14 @@ func (h *MessageHandler) UnpinMessage(c *fiber.Ctx) error {
 
 	// ยกเลิกการปักหมุด
 	if err := h.messageService.UnpinMessage(messageID, conversationID, userID); err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "message not found" {
 			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "user is not a member of this conversation" ||
-			err.Error() == "only owner/admin or the user who pinned can unpin messages" {
+		} else if err.Error() == "user is not a member of this conversation" {
 			statusCode = fiber.StatusForbidden
 		}
 
@@ -1107,6 +1175,11 @@ func (h *MessageHandler) SearchMessages(c *fiber.Ctx) error {
 		direction = "before"
 	}
 
+	sortBy := c.Query("sort", "recent") // "recent" | "relevance"
+	if sortBy != "recent" && sortBy != "relevance" {
+		sortBy = "recent"
+	}
+
 	// ค้นหาข้อความ
 	messages, nextCursor, hasMore, err := h.messageService.SearchMessages(
 		query,
@@ -1115,6 +1188,7 @@ func (h *MessageHandler) SearchMessages(c *fiber.Ctx) error {
 		limit,
 		cursorPtr,
 		direction,
+		sortBy,
 	)
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
@@ -1132,12 +1206,16 @@ func (h *MessageHandler) SearchMessages(c *fiber.Ctx) error {
 
 	// แปลง messages เป็น DTOs พร้อมข้อมูล Conversation (Telegram-style)
 	messageDTOs := h.convertSearchResultsToDTO(messages, userID)
+	for _, msgDTO := range messageDTOs {
+		msgDTO.Highlight = textsearch.Highlight(msgDTO.Content, query, searchSnippetLength)
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
 			"messages": messageDTOs,
 			"query":    query,
+			"sort":     sortBy,
 			"cursor":   nextCursor,
 			"has_more": hasMore,
 		},
@@ -1166,6 +1244,9 @@ func (h *MessageHandler) convertSearchResultsToDTO(messages []*models.Message, u
 			IsEdited:          msg.IsEdited,
 			EditCount:         msg.EditCount,
 			ReplyToID:         msg.ReplyToID,
+			ThreadRootID:      msg.ThreadRootID,
+			ThreadReplyCount:  msg.ThreadReplyCount,
+			ThreadLastReplyAt: msg.ThreadLastReplyAt,
 			IsForwarded:       msg.IsForwarded,
 		}
 
@@ -1217,6 +1298,11 @@ func (h *MessageHandler) convertSearchResultsToDTO(messages []*models.Message, u
 			msgDTO.Conversation = convDTO
 		}
 
+		// แปลง path ของไฟล์เป็น URL ที่มีอายุ (โหมด private media)
+		if h.mediaAccess != nil {
+			h.mediaAccess.ResolveMessageMedia(msgDTO, userID)
+		}
+
 		result = append(result, msgDTO)
 	}
 
@@ -1285,17 +1371,21 @@ func (h *MessageHandler) ForwardMessages(c *fiber.Ctx) error {
 		}
 	}
 
-	// นับจำนวนข้อความที่ส่งต่อสำเร็จ
+	// นับจำนวนข้อความที่ส่งต่อสำเร็จ และแปลงเป็น DTO แบบเดียวกับตอนดึงข้อความ
 	totalForwarded := 0
-	for _, messages := range results {
+	forwarded := make(map[uuid.UUID][]interface{}, len(results))
+	for conversationID, messages := range results {
 		totalForwarded += len(messages)
+		for _, message := range messages {
+			forwarded[conversationID] = append(forwarded[conversationID], h.messageResponse(message, userID))
+		}
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Messages forwarded successfully",
 		"data": fiber.Map{
-			"forwarded_messages": results,
+			"forwarded_messages": forwarded,
 			"total_forwarded":    totalForwarded,
 		},
 	})
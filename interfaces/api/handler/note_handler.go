@@ -8,6 +8,7 @@ import (
 	"github.com/thizplus/gofiber-chat-api/domain/port"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+	"github.com/thizplus/gofiber-chat-api/pkg/textsearch"
 	"github.com/thizplus/gofiber-chat-api/pkg/utils"
 )
 
@@ -395,11 +396,21 @@ func (h *NoteHandler) SearchNotes(c *fiber.Ctx) error {
 		})
 	}
 
+	// snippet ของแต่ละบันทึก (key = note id)
+	highlights := make(map[string]fiber.Map, len(notes))
+	for _, note := range notes {
+		highlights[note.ID.String()] = fiber.Map{
+			"title":   textsearch.Highlight(note.Title, query, 0),
+			"content": textsearch.Highlight(note.Content, query, searchSnippetLength),
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"notes": notes,
-			"query": query,
+			"notes":      notes,
+			"highlights": highlights,
+			"query":      query,
 			"pagination": fiber.Map{
 				"total":  total,
 				"limit":  limit,
@@ -68,12 +68,15 @@ func (h *PinnedMessageHandler) PinMessage(c *fiber.Ctx) error {
 	ctx := c.Context()
 	pinnedDTO, err := h.pinnedService.PinMessage(ctx, conversationID, messageID, userID, req.PinType)
 	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		switch err.Error() {
 		case "message not found":
 			statusCode = fiber.StatusNotFound
-		case "user is not a member of this conversation",
-			"only owner/admin can create public pins in group conversations":
+		case "user is not a member of this conversation":
 			statusCode = fiber.StatusForbidden
 		case "message is already pinned with this type",
 			"maximum public pins limit reached (5)",
@@ -133,10 +136,13 @@ func (h *PinnedMessageHandler) UnpinMessage(c *fiber.Ctx) error {
 	// Unpin message
 	ctx := c.Context()
 	if err := h.pinnedService.UnpinMessage(ctx, conversationID, messageID, userID, pinType); err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+
 		statusCode := fiber.StatusInternalServerError
 		switch err.Error() {
-		case "user is not a member of this conversation",
-			"only owner/admin can remove public pins in group conversations":
+		case "user is not a member of this conversation":
 			statusCode = fiber.StatusForbidden
 		}
 
@@ -0,0 +1,215 @@
+// interfaces/api/handler/poll_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+	"github.com/thizplus/gofiber-chat-api/pkg/utils"
+)
+
+// PollHandler handles poll HTTP requests
+type PollHandler struct {
+	pollService service.PollService
+}
+
+// NewPollHandler creates a new poll handler
+func NewPollHandler(pollService service.PollService) *PollHandler {
+	return &PollHandler{pollService: pollService}
+}
+
+// CreatePoll sends a poll message to a conversation
+// POST /api/v1/conversations/:conversationId/polls
+func (h *PollHandler) CreatePoll(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	conversationID, err := utils.ParseUUIDParam(c, "conversationId")
+	if err != nil {
+		return err
+	}
+
+	var req dto.CreatePollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := h.pollService.CreatePoll(c.Context(), conversationID, userID, &req)
+	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
+		return c.Status(pollErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Poll created successfully",
+		"data":    result,
+	})
+}
+
+// GetPoll gets a poll with its current tally
+// GET /api/v1/polls/:pollId
+func (h *PollHandler) GetPoll(c *fiber.Ctx) error {
+	userID, pollID, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.pollService.GetPoll(c.Context(), pollID, userID)
+	if err != nil {
+		return c.Status(pollErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Poll retrieved successfully",
+		"data":    result,
+	})
+}
+
+// Vote votes on a poll, replacing previous choices
+// POST /api/v1/polls/:pollId/votes
+func (h *PollHandler) Vote(c *fiber.Ctx) error {
+	userID, pollID, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	var req dto.PollVoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := h.pollService.Vote(c.Context(), pollID, userID, req.OptionIDs)
+	if err != nil {
+		return c.Status(pollErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Vote recorded successfully",
+		"data":    result,
+	})
+}
+
+// RetractVote removes the user's votes on a poll
+// DELETE /api/v1/polls/:pollId/votes
+func (h *PollHandler) RetractVote(c *fiber.Ctx) error {
+	userID, pollID, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.pollService.RetractVote(c.Context(), pollID, userID)
+	if err != nil {
+		return c.Status(pollErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Vote retracted successfully",
+		"data":    result,
+	})
+}
+
+// ClosePoll closes a poll and returns the final results
+// POST /api/v1/polls/:pollId/close
+func (h *PollHandler) ClosePoll(c *fiber.Ctx) error {
+	userID, pollID, err := h.parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.pollService.ClosePoll(c.Context(), pollID, userID)
+	if err != nil {
+		return c.Status(pollErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Poll closed successfully",
+		"data":    result,
+	})
+}
+
+// parseRequest reads the current user and the pollId path parameter.
+// The returned error is the already-written response.
+func (h *PollHandler) parseRequest(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	pollID, err := uuid.Parse(c.Params("pollId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid poll ID",
+		})
+	}
+
+	return userID, pollID, nil
+}
+
+// pollErrorStatus maps poll service errors to HTTP status codes
+func pollErrorStatus(err error) int {
+	switch err.Error() {
+	case "poll not found":
+		return fiber.StatusNotFound
+	case "user is not a member of this conversation",
+		"only the poll creator or an admin can close this poll":
+		return fiber.StatusForbidden
+	case "poll is closed",
+		"poll is already closed":
+		return fiber.StatusConflict
+	case "poll question is required",
+		"poll question is too long",
+		"poll must have between 2 and 10 options",
+		"poll options must not be empty",
+		"poll option is too long",
+		"poll options must be unique",
+		"poll close time must be in the future",
+		"at least one option is required",
+		"invalid poll option",
+		"poll allows only one option":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
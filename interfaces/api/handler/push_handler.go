@@ -0,0 +1,147 @@
+// interfaces/api/handler/push_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// PushHandler handles push notification device registration
+type PushHandler struct {
+	pushService service.PushService
+}
+
+// NewPushHandler creates a new push handler
+func NewPushHandler(pushService service.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+// GetVAPIDPublicKey returns the application server key for Web Push subscriptions
+// GET /api/v1/push/vapid-public-key
+func (h *PushHandler) GetVAPIDPublicKey(c *fiber.Ctx) error {
+	key := h.pushService.VAPIDPublicKey()
+	if key == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": "Web push is not configured",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "VAPID public key retrieved successfully",
+		"data": fiber.Map{
+			"public_key": key,
+		},
+	})
+}
+
+// RegisterDevice registers a device token or Web Push subscription for the current session
+// POST /api/v1/push/devices
+func (h *PushHandler) RegisterDevice(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	var input dto.RegisterPushDeviceRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data: " + err.Error(),
+		})
+	}
+
+	device, err := h.pushService.RegisterDevice(userID, middleware.GetSessionID(c), &input)
+	if err != nil {
+		return c.Status(pushErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Push device registered successfully",
+		"data":    device,
+	})
+}
+
+// ListDevices lists the push devices of the current user
+// GET /api/v1/push/devices
+func (h *PushHandler) ListDevices(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	devices, err := h.pushService.ListDevices(userID, middleware.GetSessionID(c))
+	if err != nil {
+		return c.Status(pushErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Push devices retrieved successfully",
+		"data":    devices,
+	})
+}
+
+// UnregisterDevice removes a push device of the current user
+// DELETE /api/v1/push/devices/:deviceId
+func (h *PushHandler) UnregisterDevice(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	deviceID, err := uuid.Parse(c.Params("deviceId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid device ID",
+		})
+	}
+
+	if err := h.pushService.UnregisterDevice(userID, deviceID); err != nil {
+		return c.Status(pushErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Push device removed successfully",
+	})
+}
+
+// pushErrorStatus maps push service errors to HTTP status codes
+func pushErrorStatus(err error) int {
+	switch err.Error() {
+	case "push device not found":
+		return fiber.StatusNotFound
+	case "push provider is not configured",
+		"device token is required",
+		"device token is too long",
+		"web push subscription requires endpoint and keys",
+		"invalid web push endpoint":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,157 @@
+// interfaces/api/handler/reaction_handler.go
+package handler
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// ReactionHandler handles message reaction HTTP requests
+type ReactionHandler struct {
+	reactionService service.ReactionService
+}
+
+// NewReactionHandler creates a new reaction handler
+func NewReactionHandler(reactionService service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{reactionService: reactionService}
+}
+
+// AddReaction adds a reaction to a message
+// POST /api/v1/messages/:messageId/reactions
+func (h *ReactionHandler) AddReaction(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	var req dto.AddReactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := h.reactionService.AddReaction(c.Context(), messageID, userID, req.Emoji)
+	if err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Reaction added successfully",
+		"data":    result,
+	})
+}
+
+// RemoveReaction removes the user's reaction from a message
+// DELETE /api/v1/messages/:messageId/reactions/:emoji
+func (h *ReactionHandler) RemoveReaction(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	// emoji มาจาก path ซึ่งถูก percent-encode
+	emoji, err := url.PathUnescape(c.Params("emoji"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid emoji",
+		})
+	}
+
+	result, err := h.reactionService.RemoveReaction(c.Context(), messageID, userID, emoji)
+	if err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Reaction removed successfully",
+		"data":    result,
+	})
+}
+
+// GetReactions lists reactions on a message
+// GET /api/v1/messages/:messageId/reactions
+func (h *ReactionHandler) GetReactions(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	result, err := h.reactionService.GetReactions(c.Context(), messageID, userID)
+	if err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Reactions retrieved successfully",
+		"data":    result,
+	})
+}
+
+// reactionErrorStatus maps reaction service errors to HTTP status codes
+func reactionErrorStatus(err error) int {
+	switch err.Error() {
+	case "message not found", "reaction not found":
+		return fiber.StatusNotFound
+	case "user is not a member of this conversation":
+		return fiber.StatusForbidden
+	case "emoji is required",
+		"emoji is too long",
+		"cannot react to deleted message",
+		"maximum reactions per message reached":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
@@ -2,9 +2,12 @@
 package handler
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/models"
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
 	"github.com/thizplus/gofiber-chat-api/pkg/utils"
@@ -45,6 +48,11 @@ func (h *ScheduledMessageHandler) ScheduleMessage(c *fiber.Ctx) error {
 		MediaURL    string                 `json:"media_url"`
 		Metadata    map[string]interface{} `json:"metadata"`
 		ScheduledAt string                 `json:"scheduled_at"` // RFC3339 format
+
+		// Recurring (ถ้ามี recurrence_rule จะเป็นข้อความตั้งเวลาแบบซ้ำ และ scheduled_at เป็นเวลาเริ่มต้น)
+		RecurrenceRule  string     `json:"recurrence_rule"`  // cron expression เช่น "0 9 * * 1-5"
+		Timezone        string     `json:"timezone"`         // IANA timezone เช่น "Asia/Bangkok"
+		RecurrenceUntil *time.Time `json:"recurrence_until"` // RFC3339 format
 	}
 
 	if err := c.BodyParser(&input); err != nil {
@@ -59,31 +67,50 @@ func (h *ScheduledMessageHandler) ScheduleMessage(c *fiber.Ctx) error {
 		input.MessageType = "text"
 	}
 
-	// Parse scheduled_at
-	scheduledAt, err := time.Parse(time.RFC3339, input.ScheduledAt)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid scheduled_at format (use RFC3339): " + err.Error(),
-		})
+	// Parse scheduled_at (ไม่บังคับสำหรับข้อความแบบซ้ำ)
+	var scheduledAt time.Time
+	if input.ScheduledAt != "" || input.RecurrenceRule == "" {
+		scheduledAt, err = time.Parse(time.RFC3339, input.ScheduledAt)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid scheduled_at format (use RFC3339): " + err.Error(),
+			})
+		}
 	}
 
 	// Schedule the message
-	scheduledMsg, err := h.scheduledMessageService.ScheduleMessage(
-		conversationID,
-		userID,
-		input.MessageType,
-		input.Content,
-		input.MediaURL,
-		input.Metadata,
-		scheduledAt,
-	)
+	var scheduledMsg *models.ScheduledMessage
+	if input.RecurrenceRule != "" {
+		scheduledMsg, err = h.scheduledMessageService.ScheduleRecurringMessage(
+			conversationID,
+			userID,
+			input.MessageType,
+			input.Content,
+			input.MediaURL,
+			input.Metadata,
+			input.RecurrenceRule,
+			input.Timezone,
+			scheduledAt,
+			input.RecurrenceUntil,
+		)
+	} else {
+		scheduledMsg, err = h.scheduledMessageService.ScheduleMessage(
+			conversationID,
+			userID,
+			input.MessageType,
+			input.Content,
+			input.MediaURL,
+			input.Metadata,
+			scheduledAt,
+		)
+	}
 
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "user is not a member of this conversation" {
 			statusCode = fiber.StatusForbidden
-		} else if err.Error() == "scheduled_at must be in the future" {
+		} else if err.Error() == "scheduled_at must be in the future" || isRecurrenceError(err) {
 			statusCode = fiber.StatusBadRequest
 		}
 
@@ -332,3 +359,180 @@ func (h *ScheduledMessageHandler) UpdateScheduledTime(c *fiber.Ctx) error {
 		"data":    scheduledMsg,
 	})
 }
+
+// GetSeries ดึงทุกรอบของข้อความตั้งเวลาแบบซ้ำ
+func (h *ScheduledMessageHandler) GetSeries(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	seriesID, err := utils.ParseUUIDParam(c, "seriesId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid series ID: " + err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit > 100 {
+		limit = 100
+	}
+	offset := c.QueryInt("offset", 0)
+
+	occurrences, total, err := h.scheduledMessageService.GetSeries(seriesID, userID, limit, offset)
+	if err != nil {
+		return c.Status(scheduledSeriesErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"scheduled_messages": occurrences,
+			"pagination": fiber.Map{
+				"total":  total,
+				"limit":  limit,
+				"offset": offset,
+			},
+		},
+	})
+}
+
+// PauseSeries หยุดข้อความตั้งเวลาแบบซ้ำชั่วคราว
+func (h *ScheduledMessageHandler) PauseSeries(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	seriesID, err := utils.ParseUUIDParam(c, "seriesId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid series ID: " + err.Error(),
+		})
+	}
+
+	occurrence, err := h.scheduledMessageService.PauseSeries(seriesID, userID)
+	if err != nil {
+		return c.Status(scheduledSeriesErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scheduled series paused successfully",
+		"data":    occurrence,
+	})
+}
+
+// ResumeSeries ให้ข้อความตั้งเวลาแบบซ้ำกลับมาทำงานต่อ
+func (h *ScheduledMessageHandler) ResumeSeries(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	seriesID, err := utils.ParseUUIDParam(c, "seriesId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid series ID: " + err.Error(),
+		})
+	}
+
+	occurrence, err := h.scheduledMessageService.ResumeSeries(seriesID, userID)
+	if err != nil {
+		return c.Status(scheduledSeriesErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scheduled series resumed successfully",
+		"data":    occurrence,
+	})
+}
+
+// UpdateSeries แก้ไขข้อความตั้งเวลาแบบซ้ำ (มีผลตั้งแต่รอบถัดไป)
+func (h *ScheduledMessageHandler) UpdateSeries(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	seriesID, err := utils.ParseUUIDParam(c, "seriesId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid series ID: " + err.Error(),
+		})
+	}
+
+	var input dto.UpdateScheduledSeriesRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body: " + err.Error(),
+		})
+	}
+
+	occurrence, err := h.scheduledMessageService.UpdateSeries(seriesID, userID, &input)
+	if err != nil {
+		return c.Status(scheduledSeriesErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scheduled series updated successfully",
+		"data":    occurrence,
+	})
+}
+
+// scheduledSeriesErrorStatus แปลง error ของ series เป็น HTTP status
+func scheduledSeriesErrorStatus(err error) int {
+	switch err.Error() {
+	case "scheduled series not found":
+		return fiber.StatusNotFound
+	case "unauthorized to access this scheduled series", "unauthorized to update this scheduled series":
+		return fiber.StatusForbidden
+	case "series is already paused", "series is not paused":
+		return fiber.StatusBadRequest
+	}
+	if isRecurrenceError(err) {
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// isRecurrenceError ตรวจสอบ error จากการตรวจ recurrence rule / timezone
+func isRecurrenceError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "invalid recurrence rule") ||
+		msg == "invalid timezone" ||
+		msg == "recurrence rule has no upcoming occurrence" ||
+		msg == "recurrence_until must be in the future"
+}
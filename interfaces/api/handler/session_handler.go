@@ -0,0 +1,113 @@
+// interfaces/api/handler/session_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SessionHandler handles per-device session HTTP requests
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListSessions lists the active sessions of the current user
+// GET /api/v1/auth/sessions
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	sessions, err := h.sessionService.ListSessions(userID, middleware.GetSessionID(c))
+	if err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Sessions retrieved successfully",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession revokes one session and closes its WebSocket connections
+// DELETE /api/v1/auth/sessions/:sessionId
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	sessionID, err := uuid.Parse(c.Params("sessionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid session ID",
+		})
+	}
+
+	if err := h.sessionService.RevokeSession(userID, sessionID); err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions revokes every session except the current one
+// DELETE /api/v1/auth/sessions
+func (h *SessionHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	count, err := h.sessionService.RevokeOtherSessions(userID, middleware.GetSessionID(c))
+	if err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Other sessions revoked successfully",
+		"data":    dto.RevokeSessionsDTO{RevokedCount: count},
+	})
+}
+
+// sessionErrorStatus maps session service errors to HTTP status codes
+func sessionErrorStatus(err error) int {
+	switch err.Error() {
+	case "session not found":
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
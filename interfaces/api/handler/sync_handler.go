@@ -0,0 +1,64 @@
+// interfaces/api/handler/sync_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SyncHandler handles event sync HTTP requests
+type SyncHandler struct {
+	syncService service.SyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService service.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// GetEvents returns the user's WebSocket events after a sequence number
+// GET /api/v1/sync/events?since=<seq>&limit=<n>
+func (h *SyncHandler) GetEvents(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	var req dto.SyncEventsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid query parameters",
+		})
+	}
+
+	result, err := h.syncService.GetEventsSince(c.Context(), userID, req.Since, req.Limit)
+	if err != nil {
+		return c.Status(syncErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Events retrieved successfully",
+		"data":    result,
+	})
+}
+
+// syncErrorStatus maps sync service errors to HTTP status codes
+func syncErrorStatus(err error) int {
+	switch err.Error() {
+	case "since must not be negative":
+		return fiber.StatusBadRequest
+	case "event sync is not available":
+		return fiber.StatusServiceUnavailable
+	}
+	return fiber.StatusInternalServerError
+}
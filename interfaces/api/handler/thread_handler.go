@@ -0,0 +1,184 @@
+// interfaces/api/handler/thread_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// ThreadHandler handles message thread HTTP requests
+type ThreadHandler struct {
+	threadService service.ThreadService
+}
+
+// NewThreadHandler creates a new thread handler
+func NewThreadHandler(threadService service.ThreadService) *ThreadHandler {
+	return &ThreadHandler{threadService: threadService}
+}
+
+// GetThread lists replies in the thread of a message
+// GET /api/v1/messages/:messageId/thread?cursor=<reply id>&limit=50
+func (h *ThreadHandler) GetThread(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	limit := c.QueryInt("limit", 0)
+
+	result, err := h.threadService.GetThreadReplies(c.Context(), messageID, userID, c.Query("cursor"), limit)
+	if err != nil {
+		return c.Status(threadErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Thread retrieved successfully",
+		"data":    result,
+	})
+}
+
+// ReplyInThread posts a reply in the thread of a message
+// POST /api/v1/messages/:messageId/thread
+func (h *ThreadHandler) ReplyInThread(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	var req dto.ThreadReplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+		})
+	}
+
+	result, err := h.threadService.ReplyInThread(
+		c.Context(),
+		messageID,
+		userID,
+		req.MessageType,
+		req.Content,
+		req.MediaURL,
+		req.ThumbnailURL,
+		req.Metadata,
+	)
+	if err != nil {
+		if permErr, ok := asPermissionError(err); ok {
+			return permissionDeniedResponse(c, permErr)
+		}
+		if restrictErr, ok := asSendRestrictionError(err); ok {
+			return sendRestrictedResponse(c, restrictErr)
+		}
+
+		return c.Status(threadErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Thread reply sent successfully",
+		"data":    result,
+	})
+}
+
+// FollowThread follows the thread of a message
+// POST /api/v1/messages/:messageId/thread/follow
+func (h *ThreadHandler) FollowThread(c *fiber.Ctx) error {
+	return h.setFollow(c, true)
+}
+
+// UnfollowThread unfollows the thread of a message
+// DELETE /api/v1/messages/:messageId/thread/follow
+func (h *ThreadHandler) UnfollowThread(c *fiber.Ctx) error {
+	return h.setFollow(c, false)
+}
+
+// setFollow handles both follow and unfollow requests
+func (h *ThreadHandler) setFollow(c *fiber.Ctx, follow bool) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	messageID, err := uuid.Parse(c.Params("messageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid message ID",
+		})
+	}
+
+	var result *dto.ThreadFollowDTO
+	message := "Thread followed successfully"
+	if follow {
+		result, err = h.threadService.FollowThread(c.Context(), messageID, userID)
+	} else {
+		result, err = h.threadService.UnfollowThread(c.Context(), messageID, userID)
+		message = "Thread unfollowed successfully"
+	}
+	if err != nil {
+		return c.Status(threadErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// threadErrorStatus maps thread service errors to HTTP status codes
+func threadErrorStatus(err error) int {
+	switch err.Error() {
+	case "message not found":
+		return fiber.StatusNotFound
+	case "user is not a member of this conversation":
+		return fiber.StatusForbidden
+	case "cannot reply to deleted message",
+		"invalid message type",
+		"invalid cursor",
+		"message content is required",
+		"sticker URL is required",
+		"media URL is required":
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,196 @@
+// interfaces/api/handler/two_factor_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// TwoFactorHandler handles TOTP two-factor authentication HTTP requests
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// GetStatus returns whether two-factor authentication is enabled for the current user
+// GET /api/v1/auth/2fa
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	status, err := h.twoFactorService.GetStatus(userID)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// BeginEnrollment creates a new TOTP secret and otpauth URI (not active until confirmed)
+// POST /api/v1/auth/2fa/enroll
+func (h *TwoFactorHandler) BeginEnrollment(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	enrollment, err := h.twoFactorService.BeginEnrollment(userID)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Scan the QR code with your authenticator app, then confirm with a code",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmEnrollment enables two-factor authentication and returns the first set of recovery codes
+// POST /api/v1/auth/2fa/confirm
+func (h *TwoFactorHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	var input dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrollment(userID, input.Code)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Two-factor authentication enabled. Store the recovery codes somewhere safe, they will not be shown again",
+		"data": fiber.Map{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// Disable turns off two-factor authentication (requires a current code or recovery code)
+// POST /api/v1/auth/2fa/disable
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	var input dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	if err := h.twoFactorService.Disable(userID, input.Code); err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes with a new set
+// POST /api/v1/auth/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	var input dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID, input.Code)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Recovery codes regenerated, previous codes no longer work",
+		"data": fiber.Map{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+// twoFactorErrorStatus maps two-factor errors to HTTP status codes
+func twoFactorErrorStatus(err error) int {
+	switch err.Error() {
+	case "code is required",
+		"challenge token and code are required",
+		"two-factor enrollment not started",
+		"two-factor authentication is not enabled":
+		return fiber.StatusBadRequest
+	case "invalid two-factor code",
+		"invalid or expired challenge":
+		return fiber.StatusUnauthorized
+	case "two-factor authentication already enabled":
+		return fiber.StatusConflict
+	case "too many failed attempts, try again later":
+		return fiber.StatusTooManyRequests
+	case "user not found":
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
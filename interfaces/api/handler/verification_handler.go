@@ -0,0 +1,135 @@
+// interfaces/api/handler/verification_handler.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/domain/dto"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// VerificationHandler handles password reset and email verification HTTP requests
+type VerificationHandler struct {
+	verificationService service.VerificationService
+}
+
+// NewVerificationHandler creates a new verification handler
+func NewVerificationHandler(verificationService service.VerificationService) *VerificationHandler {
+	return &VerificationHandler{verificationService: verificationService}
+}
+
+// RequestPasswordReset sends a password reset link to the email (always succeeds for unknown emails)
+// POST /api/v1/auth/reset-password
+func (h *VerificationHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	var input dto.PasswordResetRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	if err := h.verificationService.RequestPasswordReset(input.Email); err != nil {
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ConfirmPasswordReset sets a new password using the code from the email
+// POST /api/v1/auth/reset-password/confirm
+func (h *VerificationHandler) ConfirmPasswordReset(c *fiber.Ctx) error {
+	var input dto.ConfirmPasswordResetRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	if err := h.verificationService.ResetPassword(input.Token, input.NewPassword); err != nil {
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Password has been reset, please log in again",
+	})
+}
+
+// SendEmailVerification sends a verification link to the current user's email
+// POST /api/v1/auth/verify-email/send
+func (h *VerificationHandler) SendEmailVerification(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserUUID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"message": "Unauthorized: " + err.Error(),
+		})
+	}
+
+	if err := h.verificationService.SendEmailVerification(userID); err != nil {
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Verification email sent",
+	})
+}
+
+// VerifyEmail verifies the email address using the code from the email
+// POST /api/v1/auth/verify-email
+func (h *VerificationHandler) VerifyEmail(c *fiber.Ctx) error {
+	var input dto.VerifyEmailRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request data",
+		})
+	}
+
+	if err := h.verificationService.VerifyEmail(input.Token); err != nil {
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Email verified successfully",
+	})
+}
+
+// verificationErrorStatus maps verification service errors to HTTP status codes
+func verificationErrorStatus(err error) int {
+	switch err.Error() {
+	case "email is required",
+		"token is required",
+		"password must be at least 8 characters",
+		"invalid or expired token",
+		"user has no email":
+		return fiber.StatusBadRequest
+	case "email already verified":
+		return fiber.StatusConflict
+	case "user not found":
+		return fiber.StatusNotFound
+	case "failed to send email":
+		return fiber.StatusBadGateway
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
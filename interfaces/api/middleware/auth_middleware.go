@@ -12,9 +12,42 @@ import (
 	"github.com/google/uuid"
 )
 
+// SessionChecker ตรวจว่า session ใน access token ยังใช้งานได้ (ยังไม่ถูกเพิกถอน)
+type SessionChecker func(sessionID uuid.UUID) (bool, error)
+
+// sessionChecker ตั้งค่าครั้งเดียวตอนเริ่มแอป (nil = ไม่ตรวจ session)
+var sessionChecker SessionChecker
+
+// SetSessionChecker ตั้งค่าตัวตรวจ session ที่ Protected และ WebSocket ใช้ปฏิเสธ token ของ session ที่ถูกเพิกถอน
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// CheckSession ตรวจ session ของ token (token รุ่นเก่าที่ไม่มี sid ถือว่าผ่าน)
+func CheckSession(sessionID uuid.UUID) error {
+	if sessionChecker == nil || sessionID == uuid.Nil {
+		return nil
+	}
+
+	active, err := sessionChecker(sessionID)
+	if err != nil {
+		return fmt.Errorf("error validating session: %w", err)
+	}
+	if !active {
+		return fmt.Errorf("session has been revoked")
+	}
+	return nil
+}
+
 // Protected เป็น middleware สำหรับป้องกันเส้นทางที่ต้องการการยืนยันตัวตน
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// route group หลายตัวใช้ prefix เดียวกัน (เช่น /conversations) จึง Use(Protected()) ซ้ำใน request เดียว
+		// ตรวจ token และ session แล้วก็ไม่ต้อง query session ซ้ำ
+		if authenticated, _ := c.Locals("authenticated").(bool); authenticated {
+			return c.Next()
+		}
+
 		// ดึงค่า JWT secret
 		jwtSecret := os.Getenv("JWT_SECRET")
 		if jwtSecret == "" {
@@ -86,8 +119,19 @@ func Protected() fiber.Handler {
 					})
 				}
 			}
+
+			// session ของอุปกรณ์ (token รุ่นเก่าไม่มี sid)
+			sessionID := sessionIDFromClaims(claims)
+			if err := CheckSession(sessionID); err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error":   true,
+					"message": err.Error(),
+				})
+			}
+			c.Locals("sessionID", sessionID)
 		}
 
+		c.Locals("authenticated", true)
 		return c.Next()
 	}
 }
@@ -98,6 +142,25 @@ func GetUserIDString(c *fiber.Ctx) (string, bool) {
 	return userID, ok
 }
 
+// GetSessionID ดึง session ID ของ access token (uuid.Nil ถ้าเป็น token รุ่นเก่าที่ไม่มี session)
+func GetSessionID(c *fiber.Ctx) uuid.UUID {
+	sessionID, _ := c.Locals("sessionID").(uuid.UUID)
+	return sessionID
+}
+
+// sessionIDFromClaims อ่าน sid จาก claims (ไม่มีหรือไม่ถูกต้อง = uuid.Nil)
+func sessionIDFromClaims(claims jwt.MapClaims) uuid.UUID {
+	sid, ok := claims["sid"].(string)
+	if !ok {
+		return uuid.Nil
+	}
+	sessionID, err := uuid.Parse(sid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return sessionID
+}
+
 // GetUserUUID ดึง user UUID จาก context
 func GetUserUUID(c *fiber.Ctx) (uuid.UUID, error) {
 	// ลองดึง UUID โดยตรงจาก context ก่อน
@@ -153,8 +216,46 @@ func ValidateTokenStringToUUID(tokenString string) (uuid.UUID, error) {
 	return uuid.Parse(userIDStr)
 }
 
+// ValidateTokenSession ตรวจสอบ token และส่งคืน user UUID พร้อม session ID (uuid.Nil ถ้าไม่มี sid)
+// ใช้กับการเชื่อมต่อ WebSocket ซึ่งต้องผูก client กับ session ของอุปกรณ์
+func ValidateTokenSession(tokenString string) (uuid.UUID, uuid.UUID, error) {
+	claims, err := parseTokenClaims(tokenString)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	userIDStr, ok := claims["id"].(string)
+	if !ok {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("user ID not found in token claims")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	sessionID := sessionIDFromClaims(claims)
+	if err := CheckSession(sessionID); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return userID, sessionID, nil
+}
+
 // ValidateTokenString ตรวจสอบ token และส่งคืน string (เพื่อความเข้ากันได้กับโค้ดเดิม)
 func ValidateTokenString(tokenString string) (string, error) {
+	claims, err := parseTokenClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	if userID, ok := claims["id"].(string); ok {
+		return userID, nil
+	}
+	return "", fmt.Errorf("user ID not found in token claims")
+}
+
+// parseTokenClaims ตรวจสอบลายเซ็นและวันหมดอายุของ token แล้วคืน claims
+func parseTokenClaims(tokenString string) (jwt.MapClaims, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "default-jwt-secret-for-development-only"
@@ -169,18 +270,15 @@ func ValidateTokenString(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// ตรวจสอบว่า token ถูกต้องและดึงข้อมูล claims
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if userID, ok := claims["id"].(string); ok {
-			return userID, nil
-		}
-		return "", fmt.Errorf("user ID not found in token claims")
+		return claims, nil
 	}
 
-	return "", fmt.Errorf("invalid token")
+	return nil, fmt.Errorf("invalid token")
 }
 
 // GenerateJWTFromUUID สร้าง JWT token จาก UUID
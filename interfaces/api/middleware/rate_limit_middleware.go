@@ -0,0 +1,27 @@
+// middleware/rate_limit_middleware.go
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimitByIP จำกัดจำนวน request ต่อ IP ในช่วงเวลา window (นับแยกในแต่ละ instance)
+// ใช้กับ endpoint สาธารณะที่ส่งอีเมลหรือใช้ทรัพยากรมาก เช่น ขอรีเซ็ตรหัสผ่าน
+func RateLimitByIP(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"message": "Too many requests, please try again later",
+			})
+		},
+	})
+}
@@ -2,21 +2,42 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
 	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
 )
 
 // SetupAuthRoutes กำหนดเส้นทางสำหรับการยืนยันตัวตน
-func SetupAuthRoutes(router fiber.Router, authHandler *handler.AuthHandler) {
+func SetupAuthRoutes(router fiber.Router, authHandler *handler.AuthHandler, sessionHandler *handler.SessionHandler, verificationHandler *handler.VerificationHandler, twoFactorHandler *handler.TwoFactorHandler) {
+	// จำกัดการขอรีเซ็ตรหัสผ่านต่อ IP (จำนวนต่ออีเมลจำกัดใน VerificationService)
+	passwordResetLimit := middleware.RateLimitByIP(5, 15*time.Minute)
+
 	// เส้นทางที่ไม่ต้องการการยืนยันตัวตน
 	authRoutes := router.Group("/auth")
-	authRoutes.Post("/register", authHandler.Register)          // [success] 1.1 การลงทะเบียนสร้างผู้ใช้ใหม่ [Y]
-	authRoutes.Post("/login", authHandler.Login)                // [success] 1.2 การเข้าสู่ระบบ [Y]
-	authRoutes.Post("/refresh-token", authHandler.RefreshToken) // [success] 1.4 การต่ออายุ Token [Y]
-	// authRoutes.Post("/reset-password", authHandler.ResetPassword) // [pendding]
+	authRoutes.Post("/register", authHandler.Register)                                               // [success] 1.1 การลงทะเบียนสร้างผู้ใช้ใหม่ [Y]
+	authRoutes.Post("/login", authHandler.Login)                                                     // [success] 1.2 การเข้าสู่ระบบ [Y]
+	authRoutes.Post("/login/2fa", authHandler.LoginTwoFactor)                                        // เข้าสู่ระบบขั้นที่สองด้วยโค้ด 2FA
+	authRoutes.Post("/refresh-token", authHandler.RefreshToken)                                      // [success] 1.4 การต่ออายุ Token [Y]
+	authRoutes.Post("/reset-password", passwordResetLimit, verificationHandler.RequestPasswordReset) // ขอลิงก์รีเซ็ตรหัสผ่านทางอีเมล
+	authRoutes.Post("/reset-password/confirm", verificationHandler.ConfirmPasswordReset)             // ตั้งรหัสผ่านใหม่ด้วยโค้ดจากอีเมล
+	authRoutes.Post("/verify-email", verificationHandler.VerifyEmail)                                // ยืนยันอีเมลด้วยโค้ดจากอีเมล
 
 	// เส้นทางที่ต้องการการยืนยันตัวตน
-	authRoutes.Get("/user", middleware.Protected(), authHandler.GetCurrentUser) // [success] 1.3 การดึงข้อมูลผู้ใช้ปัจจุบัน [Y]
-	authRoutes.Post("/logout", middleware.Protected(), authHandler.Logout)      // [success] 1.5 การออกจากระบบ [Y]
+	authRoutes.Get("/user", middleware.Protected(), authHandler.GetCurrentUser)                              // [success] 1.3 การดึงข้อมูลผู้ใช้ปัจจุบัน [Y]
+	authRoutes.Post("/logout", middleware.Protected(), authHandler.Logout)                                   // [success] 1.5 การออกจากระบบ [Y]
+	authRoutes.Post("/verify-email/send", middleware.Protected(), verificationHandler.SendEmailVerification) // ส่งอีเมลยืนยันอีกครั้ง
+
+	// Two-factor authentication (TOTP)
+	authRoutes.Get("/2fa", middleware.Protected(), twoFactorHandler.GetStatus)                               // สถานะ 2FA
+	authRoutes.Post("/2fa/enroll", middleware.Protected(), twoFactorHandler.BeginEnrollment)                 // เริ่มลงทะเบียน (otpauth URI)
+	authRoutes.Post("/2fa/confirm", middleware.Protected(), twoFactorHandler.ConfirmEnrollment)              // ยืนยันด้วยโค้ดและรับโค้ดสำรอง
+	authRoutes.Post("/2fa/disable", middleware.Protected(), twoFactorHandler.Disable)                        // ปิด 2FA
+	authRoutes.Post("/2fa/recovery-codes", middleware.Protected(), twoFactorHandler.RegenerateRecoveryCodes) // สร้างโค้ดสำรองชุดใหม่
+
+	// Session ของอุปกรณ์
+	authRoutes.Get("/sessions", middleware.Protected(), sessionHandler.ListSessions)                // รายการอุปกรณ์ที่ยังเข้าสู่ระบบอยู่
+	authRoutes.Delete("/sessions", middleware.Protected(), sessionHandler.RevokeOtherSessions)      // ออกจากระบบทุกอุปกรณ์ยกเว้นเครื่องนี้
+	authRoutes.Delete("/sessions/:sessionId", middleware.Protected(), sessionHandler.RevokeSession) // ออกจากระบบอุปกรณ์ที่ระบุ
 }
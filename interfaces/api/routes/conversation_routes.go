@@ -28,8 +28,11 @@ func SetupConversationRoutes(
 	conversations.Get("/:conversationId/messages", conversationHandler.GetConversationMessages) // [succcess] 8.4 การดึงข้อความในการสนทนา [Y]
 	conversations.Patch("/:conversationId/pin", conversationHandler.TogglePinConversation)      // [success] 8.5 การเปลี่ยนสถานะปักหมุดของการสนทนา [Y]
 	conversations.Patch("/:conversationId/mute", conversationHandler.ToggleMuteConversation)    // [success] 8.6 การเปลี่ยนสถานะการปิดเสียงของการสนทนา [Y]
+	conversations.Get("/:conversationId/notification-settings", conversationHandler.GetNotificationSettings)    // การตั้งค่าการแจ้งเตือน
+	conversations.Put("/:conversationId/notification-settings", conversationHandler.UpdateNotificationSettings) // ตั้งค่าการแจ้งเตือน (all / mentions / none, ปิดเสียงชั่วคราว, keyword)
 	conversations.Patch("/:conversationId/hide", conversationHandler.HideConversation)          // การซ่อน/แสดงการสนทนา
 	conversations.Delete("/:conversationId", conversationHandler.DeleteConversation)            // การลบการสนทนา (smart delete)
+	conversations.Patch("/:conversationId/message-ttl", conversationHandler.SetMessageTTL)      // ตั้งค่าข้อความหายไปอัตโนมัติ
 
 	// Media Gallery & Jump to Message
 	conversations.Get("/:conversationId/media/summary", conversationHandler.GetMediaSummary)      // ดึงสรุปจำนวน media และ link
@@ -46,6 +49,8 @@ func SetupConversationRoutes(
 	// การจัดการ role และ ownership
 	conversations.Patch("/:conversationId/members/:userId/role", conversationMemberHandler.ChangeRole)           // เปลี่ยน role ของสมาชิก (owner/admin/member)
 	conversations.Post("/:conversationId/transfer-ownership", conversationHandler.TransferOwnership)             // โอนความเป็นเจ้าของกลุ่มให้สมาชิกคนอื่น
+	conversations.Get("/:conversationId/permissions", conversationMemberHandler.GetPermissions)                  // ดึง permission policy ของกลุ่ม
+	conversations.Put("/:conversationId/permissions", conversationMemberHandler.UpdatePermissions)               // ตั้งค่าสิทธิ์ของกลุ่ม (owner เท่านั้น)
 
 	// Group Activity Log
 	conversations.Get("/:conversationId/activities", conversationHandler.GetActivities) // ดึง activity log ของกลุ่ม
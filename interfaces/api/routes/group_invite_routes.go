@@ -0,0 +1,27 @@
+// interfaces/api/routes/group_invite_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupGroupInviteRoutes sets up routes for group invite links and join requests
+func SetupGroupInviteRoutes(router fiber.Router, groupInviteHandler *handler.GroupInviteHandler) {
+	conversations := router.Group("/conversations")
+	conversations.Use(middleware.Protected())
+
+	conversations.Get("/:conversationId/invites", groupInviteHandler.ListInvites)
+	conversations.Post("/:conversationId/invites", groupInviteHandler.CreateInvite)
+	conversations.Delete("/:conversationId/invites/:inviteId", groupInviteHandler.RevokeInvite)
+
+	conversations.Get("/:conversationId/join-requests", groupInviteHandler.ListJoinRequests)
+	conversations.Post("/:conversationId/join-requests/:requestId/approve", groupInviteHandler.ApproveJoinRequest)
+	conversations.Post("/:conversationId/join-requests/:requestId/reject", groupInviteHandler.RejectJoinRequest)
+
+	invites := router.Group("/invites")
+	invites.Use(middleware.Protected())
+
+	invites.Post("/:token/join", groupInviteHandler.JoinByToken)
+}
@@ -0,0 +1,23 @@
+// interfaces/api/routes/group_moderation_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupGroupModerationRoutes sets up routes for group bans, send restrictions and slow mode
+func SetupGroupModerationRoutes(router fiber.Router, groupModerationHandler *handler.GroupModerationHandler) {
+	conversations := router.Group("/conversations")
+	conversations.Use(middleware.Protected())
+
+	conversations.Get("/:conversationId/bans", groupModerationHandler.ListBans)
+	conversations.Post("/:conversationId/bans", groupModerationHandler.BanMember)
+	conversations.Delete("/:conversationId/bans/:userId", groupModerationHandler.UnbanMember)
+
+	conversations.Put("/:conversationId/members/:userId/restriction", groupModerationHandler.RestrictMember)
+	conversations.Delete("/:conversationId/members/:userId/restriction", groupModerationHandler.UnrestrictMember)
+
+	conversations.Put("/:conversationId/slow-mode", groupModerationHandler.SetSlowMode)
+}
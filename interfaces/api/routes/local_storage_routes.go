@@ -0,0 +1,16 @@
+// interfaces/api/routes/local_storage_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+)
+
+// SetupLocalStorageRoutes sets up routes for files kept by the local storage driver
+// ไม่ใช้ middleware.Protected เพราะสิทธิ์มาจากลายเซ็นใน URL (LOCAL_STORAGE_BASE_URL ต้องชี้มาที่ /storage)
+func SetupLocalStorageRoutes(router fiber.Router, localStorageHandler *handler.LocalStorageHandler) {
+	storage := router.Group("/storage")
+
+	storage.Get("/*", localStorageHandler.ServeFile)
+	storage.Put("/*", localStorageHandler.UploadFile)
+}
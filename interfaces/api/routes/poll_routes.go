@@ -0,0 +1,24 @@
+// interfaces/api/routes/poll_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupPollRoutes sets up routes for polls
+func SetupPollRoutes(router fiber.Router, pollHandler *handler.PollHandler) {
+	conversations := router.Group("/conversations")
+	conversations.Use(middleware.Protected())
+
+	conversations.Post("/:conversationId/polls", pollHandler.CreatePoll)
+
+	polls := router.Group("/polls")
+	polls.Use(middleware.Protected())
+
+	polls.Get("/:pollId", pollHandler.GetPoll)
+	polls.Post("/:pollId/votes", pollHandler.Vote)
+	polls.Delete("/:pollId/votes", pollHandler.RetractVote)
+	polls.Post("/:pollId/close", pollHandler.ClosePoll)
+}
@@ -0,0 +1,21 @@
+// interfaces/api/routes/push_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupPushRoutes sets up routes for push notification devices
+func SetupPushRoutes(router fiber.Router, pushHandler *handler.PushHandler) {
+	push := router.Group("/push")
+
+	// VAPID public key ไม่ใช่ความลับ (service worker ต้องใช้ก่อน subscribe)
+	push.Get("/vapid-public-key", pushHandler.GetVAPIDPublicKey)
+
+	devices := push.Group("/devices", middleware.Protected())
+	devices.Get("/", pushHandler.ListDevices)
+	devices.Post("/", pushHandler.RegisterDevice)
+	devices.Delete("/:deviceId", pushHandler.UnregisterDevice)
+}
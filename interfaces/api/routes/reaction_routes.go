@@ -0,0 +1,18 @@
+// interfaces/api/routes/reaction_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupReactionRoutes sets up routes for message reactions
+func SetupReactionRoutes(router fiber.Router, reactionHandler *handler.ReactionHandler) {
+	messages := router.Group("/messages")
+	messages.Use(middleware.Protected())
+
+	messages.Get("/:messageId/reactions", reactionHandler.GetReactions)
+	messages.Post("/:messageId/reactions", reactionHandler.AddReaction)
+	messages.Delete("/:messageId/reactions/:emoji", reactionHandler.RemoveReaction)
+}
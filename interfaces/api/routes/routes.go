@@ -28,8 +28,24 @@ func SetupRoutes(
 	searchHandler *handler.SearchHandler,
 	presenceHandler *handler.PresenceHandler,
 	pinnedMessageHandler *handler.PinnedMessageHandler,
+	reactionHandler *handler.ReactionHandler,
+	syncHandler *handler.SyncHandler,
+	threadHandler *handler.ThreadHandler,
+	pollHandler *handler.PollHandler,
+	groupInviteHandler *handler.GroupInviteHandler,
+	groupModerationHandler *handler.GroupModerationHandler,
+	sessionHandler *handler.SessionHandler,
+	verificationHandler *handler.VerificationHandler,
+	twoFactorHandler *handler.TwoFactorHandler,
+	pushHandler *handler.PushHandler,
+	localStorageHandler *handler.LocalStorageHandler,
 
 ) {
+	// ไฟล์ของ local storage driver (nil เมื่อใช้ cloud storage)
+	if localStorageHandler != nil {
+		SetupLocalStorageRoutes(app, localStorageHandler)
+	}
+
 	// สร้าง API group
 	api := app.Group("/api/v1")
 
@@ -42,7 +58,7 @@ func SetupRoutes(
 	})
 
 	// กำหนดเส้นทางต่างๆ
-	SetupAuthRoutes(api, authHandler)
+	SetupAuthRoutes(api, authHandler, sessionHandler, verificationHandler, twoFactorHandler)
 	SetupFileRoutes(api, fileHandler)
 
 
@@ -63,5 +79,12 @@ func SetupRoutes(
 	SetupSearchRoutes(api, searchHandler)
 	SetupPresenceRoutes(api, presenceHandler)
 	SetupPinnedMessageRoutes(api, pinnedMessageHandler)
+	SetupReactionRoutes(api, reactionHandler)
+	SetupSyncRoutes(api, syncHandler)
+	SetupThreadRoutes(api, threadHandler)
+	SetupPollRoutes(api, pollHandler)
+	SetupGroupInviteRoutes(api, groupInviteHandler)
+	SetupGroupModerationRoutes(api, groupModerationHandler)
+	SetupPushRoutes(api, pushHandler)
 
 }
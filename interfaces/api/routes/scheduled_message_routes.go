@@ -13,6 +13,12 @@ func SetupScheduledMessageRoutes(router fiber.Router, scheduledMessageHandler *h
 	scheduledMessages := router.Group("/scheduled-messages")
 	scheduledMessages.Use(middleware.Protected())
 
+	// Recurring series
+	scheduledMessages.Get("/series/:seriesId", scheduledMessageHandler.GetSeries)                                          // ดึงทุกรอบของข้อความแบบซ้ำ
+	scheduledMessages.Put("/series/:seriesId", scheduledMessageHandler.UpdateSeries)                                       // แก้ไขข้อความแบบซ้ำ
+	scheduledMessages.Post("/series/:seriesId/pause", scheduledMessageHandler.PauseSeries)                                 // หยุดชั่วคราว
+	scheduledMessages.Post("/series/:seriesId/resume", scheduledMessageHandler.ResumeSeries)                               // ทำงานต่อ
+
 	// CRUD operations
 	scheduledMessages.Get("/", scheduledMessageHandler.GetUserScheduledMessages)                                           // ดึงรายการข้อความที่กำหนดเวลาส่งของผู้ใช้
 	scheduledMessages.Get("/:id", scheduledMessageHandler.GetScheduledMessage)                                              // ดึงข้อมูลข้อความที่กำหนดเวลาส่ง
@@ -0,0 +1,16 @@
+// interfaces/api/routes/sync_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupSyncRoutes sets up routes for WebSocket event sync
+func SetupSyncRoutes(router fiber.Router, syncHandler *handler.SyncHandler) {
+	sync := router.Group("/sync")
+	sync.Use(middleware.Protected())
+
+	sync.Get("/events", syncHandler.GetEvents)
+}
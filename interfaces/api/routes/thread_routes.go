@@ -0,0 +1,19 @@
+// interfaces/api/routes/thread_routes.go
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/handler"
+	"github.com/thizplus/gofiber-chat-api/interfaces/api/middleware"
+)
+
+// SetupThreadRoutes sets up routes for message threads
+func SetupThreadRoutes(router fiber.Router, threadHandler *handler.ThreadHandler) {
+	messages := router.Group("/messages")
+	messages.Use(middleware.Protected())
+
+	messages.Get("/:messageId/thread", threadHandler.GetThread)
+	messages.Post("/:messageId/thread", threadHandler.ReplyInThread)
+	messages.Post("/:messageId/thread/follow", threadHandler.FollowThread)
+	messages.Delete("/:messageId/thread/follow", threadHandler.UnfollowThread)
+}
@@ -2,6 +2,7 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"time"
@@ -9,6 +10,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// เวลาสูงสุดในการบันทึก event log ก่อนส่ง broadcast
+const eventLogTimeout = 2 * time.Second
+
 // broadcastMessage sends a message to specified clients
 func (h *Hub) broadcastMessage(msg *BroadcastMessage) {
 	data, err := json.Marshal(WSResponse{
@@ -21,20 +25,88 @@ func (h *Hub) broadcastMessage(msg *BroadcastMessage) {
 		return
 	}
 
+	// Session ถูกเพิกถอน: ส่งแจ้งเตือนแล้วปิดเฉพาะการเชื่อมต่อของ session นั้น
+	if msg.SessionID != nil {
+		for _, userID := range msg.UserIDs {
+			h.disconnectSession(userID, *msg.SessionID, data)
+		}
+		return
+	}
+
 	// Broadcast to specific users
 	if len(msg.UserIDs) > 0 {
 		for _, userID := range msg.UserIDs {
-			h.sendToUser(userID, data, msg.ExcludeID)
+			h.sendToUser(userID, withSeq(data, msg.Type, msg.Data, msg.Seqs[userID]), msg.ExcludeID)
+		}
+
+		// ผู้ใช้ออก/ถูกลบจากการสนทนา: หยุดส่ง broadcast ของห้องนั้นให้ทุกการเชื่อมต่อของผู้ใช้
+		if msg.Type == TypeConversationUserRemoved {
+			if convID, ok := conversationIDOf(msg.Data); ok {
+				for _, userID := range msg.UserIDs {
+					h.unsubscribeUserFromConversation(userID, convID)
+				}
+			}
 		}
 	}
 
 
 	// Broadcast to conversation
 	if msg.ConvID != nil {
-		h.broadcastToConversation(*msg.ConvID, msg.Type, msg.Data, msg.ExcludeID)
+		h.broadcastToConversation(*msg.ConvID, msg.Type, msg.Data, msg.ExcludeID, msg.Seqs)
+	}
+
+	// Broadcast to user status subscribers
+	if msg.StatusOf != nil {
+		h.sendToStatusSubscribers(*msg.StatusOf, data, msg.ExcludeID)
 	}
 }
 
+// sendToStatusSubscribers sends a status event to every local client subscribed to a user's status
+func (h *Hub) sendToStatusSubscribers(userID uuid.UUID, data []byte, excludeID *uuid.UUID) {
+	h.userStatusSubsMux.RLock()
+	subscriberIDs := append([]uuid.UUID(nil), h.userStatusSubs[userID]...)
+	h.userStatusSubsMux.RUnlock()
+
+	for _, clientID := range subscriberIDs {
+		if excludeID != nil && clientID == *excludeID {
+			continue
+		}
+
+		h.clientsMux.RLock()
+		client, ok := h.clients[clientID]
+		h.clientsMux.RUnlock()
+
+		if ok {
+			select {
+			case client.Send <- data:
+			default:
+				go func() {
+					h.unregister <- client
+				}()
+			}
+		}
+	}
+}
+
+// withSeq คืนค่า response ที่มี sequence ของผู้รับ (ใช้ response เดิมถ้าไม่มี sequence)
+func withSeq(response []byte, msgType MessageType, data interface{}, seq int64) []byte {
+	if seq == 0 {
+		return response
+	}
+
+	sequenced, err := json.Marshal(WSResponse{
+		Type:      msgType,
+		Data:      data,
+		Timestamp: time.Now(),
+		Success:   true,
+		Seq:       seq,
+	})
+	if err != nil {
+		return response
+	}
+	return sequenced
+}
+
 // sendToUser sends a message to all connections of a user
 func (h *Hub) sendToUser(userID uuid.UUID, data []byte, excludeID *uuid.UUID) {
 	h.userConnectionsMux.RLock()
@@ -63,9 +135,33 @@ func (h *Hub) sendToUser(userID uuid.UUID, data []byte, excludeID *uuid.UUID) {
 	}
 }
 
+// disconnectSession ส่ง payload ให้ client ของ session แล้ว unregister
+// WritePump จะส่ง payload ที่ค้างอยู่ใน Send ก่อนปิดการเชื่อมต่อ
+func (h *Hub) disconnectSession(userID, sessionID uuid.UUID, data []byte) {
+	h.userConnectionsMux.RLock()
+	clientIDs := append([]uuid.UUID(nil), h.userConnections[userID]...)
+	h.userConnectionsMux.RUnlock()
+
+	for _, clientID := range clientIDs {
+		h.clientsMux.RLock()
+		client, ok := h.clients[clientID]
+		h.clientsMux.RUnlock()
+
+		if !ok || client.SessionID != sessionID {
+			continue
+		}
+
+		select {
+		case client.Send <- data:
+		default:
+		}
+		h.unregisterClient(client)
+	}
+}
 
 // broadcastToConversation sends a message to all members of a conversation
-func (h *Hub) broadcastToConversation(convID uuid.UUID, msgType MessageType, data interface{}, excludeID *uuid.UUID) {
+// seqs คือ sequence ของผู้รับแต่ละคน (อาจเป็น nil)
+func (h *Hub) broadcastToConversation(convID uuid.UUID, msgType MessageType, data interface{}, excludeID *uuid.UUID, seqs map[uuid.UUID]int64) {
 	// Get conversation subscribers
 	h.conversationSubsMux.RLock()
 	subscriberIDs := h.conversationSubs[convID]
@@ -81,6 +177,9 @@ func (h *Hub) broadcastToConversation(convID uuid.UUID, msgType MessageType, dat
 		return
 	}
 
+	// response แยกตามผู้ใช้ (sequence ของแต่ละคนต่างกัน)
+	userResponses := make(map[uuid.UUID][]byte)
+
 	// Send to each subscriber
 	for _, clientID := range subscriberIDs {
 		if excludeID != nil && clientID == *excludeID {
@@ -92,8 +191,14 @@ func (h *Hub) broadcastToConversation(convID uuid.UUID, msgType MessageType, dat
 		h.clientsMux.RUnlock()
 
 		if ok {
+			payload, cached := userResponses[client.UserID]
+			if !cached {
+				payload = withSeq(response, msgType, data, seqs[client.UserID])
+				userResponses[client.UserID] = payload
+			}
+
 			select {
-			case client.Send <- response:
+			case client.Send <- payload:
 			default:
 				go func() {
 					h.unregister <- client
@@ -171,6 +276,58 @@ func (h *Hub) removeClientFromAllConversations(clientID uuid.UUID) {
 	}
 }
 
+// unsubscribeUserFromConversation removes all connections of a user from a conversation's subscribers
+func (h *Hub) unsubscribeUserFromConversation(userID, convID uuid.UUID) {
+	h.userConnectionsMux.RLock()
+	clientIDs := append([]uuid.UUID(nil), h.userConnections[userID]...)
+	h.userConnectionsMux.RUnlock()
+
+	h.conversationSubsMux.Lock()
+	defer h.conversationSubsMux.Unlock()
+
+	subscribers, exists := h.conversationSubs[convID]
+	if !exists {
+		return
+	}
+
+	updatedSubscribers := make([]uuid.UUID, len(subscribers))
+	copy(updatedSubscribers, subscribers)
+	for _, clientID := range clientIDs {
+		h.removeClientFromSlice(&updatedSubscribers, clientID)
+	}
+
+	if len(updatedSubscribers) == 0 {
+		delete(h.conversationSubs, convID)
+	} else {
+		h.conversationSubs[convID] = updatedSubscribers
+	}
+}
+
+// conversationIDOf อ่าน conversation_id จากข้อมูล event (ทั้งจาก service โดยตรงและที่ผ่าน backplane มาเป็น JSON)
+func conversationIDOf(data interface{}) (uuid.UUID, bool) {
+	if raw, ok := data.(json.RawMessage); ok {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return uuid.Nil, false
+		}
+		data = decoded
+	}
+
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return uuid.Nil, false
+	}
+	switch v := fields["conversation_id"].(type) {
+	case uuid.UUID:
+		return v, true
+	case string:
+		id, err := uuid.Parse(v)
+		return id, err == nil
+	default:
+		return uuid.Nil, false
+	}
+}
+
 // เพิ่มฟังก์ชันใหม่ใน hub.go
 func (h *Hub) removeClientFromAllUserStatusSubscriptions(clientID uuid.UUID) {
 	h.userStatusSubsMux.Lock()
@@ -208,9 +365,125 @@ func (h *Hub) NotifyBroadcast(msg *BroadcastMessage) {
 		log.Printf("Broadcast channel full, dropping message type %s", msg.Type)
 	}
 }
+
+// DeliverFromBackplane ส่ง broadcast ที่มาจาก instance อื่นให้ client บน instance นี้
+// (ไม่ publish ซ้ำไปที่ backplane)
+func (h *Hub) DeliverFromBackplane(msg *BroadcastMessage) {
+	h.invalidateMembers(msg)
+	h.NotifyBroadcast(msg)
+}
+
+// publish ส่ง broadcast ให้ client บน instance นี้ และกระจายต่อผ่าน backplane (ถ้ามี)
+// event ที่ต้องมี sequence ถูกส่งเข้าคิวของ runPublisher เพื่อให้ผู้เรียกไม่ต้องรอ event log
+// และลำดับการส่งตรงกับลำดับ sequence
+func (h *Hub) publish(msg *BroadcastMessage) {
+	if h == nil || msg == nil {
+		return
+	}
+
+	if h.syncService == nil || isEphemeralEvent(msg.Type) {
+		h.deliver(msg)
+		return
+	}
+
+	select {
+	case h.publishQueue <- msg:
+	default:
+		log.Printf("Publish queue full, sending message type %s without sequence", msg.Type)
+		h.deliver(msg)
+	}
+}
+
+// runPublisher กำหนด sequence แล้วส่ง broadcast ทีละรายการตามลำดับที่เข้าคิว
+// sequence ถูกกำหนดที่ instance ต้นทางเพียงครั้งเดียว แล้วส่งต่อไปพร้อมกับ message
+func (h *Hub) runPublisher(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-h.publishQueue:
+			h.invalidateMembers(msg)
+			h.assignSequences(msg)
+			h.deliver(msg)
+		}
+	}
+}
+
+// deliver ส่ง broadcast ให้ client บน instance นี้และ instance อื่นผ่าน backplane
+func (h *Hub) deliver(msg *BroadcastMessage) {
+	h.NotifyBroadcast(msg)
+	if h.backplane != nil {
+		h.backplane.Publish(msg)
+	}
+}
+
+// invalidateMembers ล้าง cache สมาชิกของการสนทนาเมื่อมี event เปลี่ยนสมาชิก
+// (ทำก่อนกำหนด sequence เพื่อให้สมาชิกใหม่ได้รับ event ของตัวเอง)
+func (h *Hub) invalidateMembers(msg *BroadcastMessage) {
+	if h == nil || h.syncService == nil {
+		return
+	}
+
+	switch msg.Type {
+	case "conversation.user_added", TypeConversationUserRemoved, TypeConversationJoin, TypeConversationLeave, "conversation.deleted":
+	default:
+		return
+	}
+
+	if msg.ConvID != nil {
+		h.syncService.InvalidateConversationMembers(*msg.ConvID)
+	} else if convID, ok := conversationIDOf(msg.Data); ok {
+		h.syncService.InvalidateConversationMembers(convID)
+	}
+}
+
+// assignSequences บันทึก event ลง event log ของผู้รับทุกคน (รวมถึงคนที่ offline)
+// และเก็บ sequence ที่ได้ไว้ใน msg.Seqs
+func (h *Hub) assignSequences(msg *BroadcastMessage) {
+	if h.syncService == nil || isEphemeralEvent(msg.Type) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogTimeout)
+	defer cancel()
+
+	var (
+		seqs map[uuid.UUID]int64
+		err  error
+	)
+	switch {
+	case msg.ConvID != nil:
+		seqs, err = h.syncService.RecordConversationEvent(ctx, *msg.ConvID, string(msg.Type), msg.Data)
+	case len(msg.UserIDs) > 0:
+		seqs, err = h.syncService.RecordUserEvent(ctx, msg.UserIDs, string(msg.Type), msg.Data)
+	default:
+		return
+	}
+	if err != nil {
+		// ยังส่ง event แบบ realtime ได้ เพียงแต่ไม่มี sequence
+		log.Printf("Failed to record event type %s: %v", msg.Type, err)
+		return
+	}
+
+	msg.Seqs = seqs
+}
+
+// isEphemeralEvent event ชั่วคราว (typing, presence) ไม่ต้องบันทึกและไม่ต้อง sync ย้อนหลัง
+func isEphemeralEvent(msgType MessageType) bool {
+	switch msgType {
+	case TypeMessageTyping, TypeTypingStart, TypeTypingStop, TypeUserTyping,
+		TypeUserOnline, TypeUserOffline, TypeUserStatus,
+		TypePing, TypePong,
+		TypeSessionRevoked,
+		"conversation.user_active":
+		return true
+	}
+	return false
+}
+
 func (h *Hub) BroadcastToConversation(conversationID uuid.UUID, msgType MessageType, data interface{}) {
 
-	h.NotifyBroadcast(&BroadcastMessage{
+	h.publish(&BroadcastMessage{
 		Type:   msgType,
 		Data:   data,
 		ConvID: &conversationID,
@@ -219,7 +492,7 @@ func (h *Hub) BroadcastToConversation(conversationID uuid.UUID, msgType MessageT
 
 // BroadcastToUsers ส่งข้อความไปยังผู้ใช้หลายคน
 func (h *Hub) BroadcastToUsers(userIDs []uuid.UUID, msgType MessageType, data interface{}) {
-	h.NotifyBroadcast(&BroadcastMessage{
+	h.publish(&BroadcastMessage{
 		Type:    msgType,
 		Data:    data,
 		UserIDs: userIDs,
@@ -228,7 +501,7 @@ func (h *Hub) BroadcastToUsers(userIDs []uuid.UUID, msgType MessageType, data in
 
 // BroadcastToBusiness ส่งข้อความไปยังธุรกิจ
 func (h *Hub) BroadcastToBusiness(businessID uuid.UUID, msgType MessageType, data interface{}) {
-	h.NotifyBroadcast(&BroadcastMessage{
+	h.publish(&BroadcastMessage{
 		Type:       msgType,
 		Data:       data,
 		BusinessID: &businessID,
@@ -237,9 +510,21 @@ func (h *Hub) BroadcastToBusiness(businessID uuid.UUID, msgType MessageType, dat
 
 // BroadcastToUser ส่งข้อความไปยังผู้ใช้คนเดียว
 func (h *Hub) BroadcastToUser(userID uuid.UUID, msgType MessageType, data interface{}) {
-	h.NotifyBroadcast(&BroadcastMessage{
+	h.publish(&BroadcastMessage{
 		Type:    msgType,
 		Data:    data,
 		UserIDs: []uuid.UUID{userID},
 	})
 }
+
+// DisconnectSession แจ้ง session.revoked แล้วปิดการเชื่อมต่อของ session นั้นทุก instance
+func (h *Hub) DisconnectSession(userID, sessionID uuid.UUID) {
+	h.publish(&BroadcastMessage{
+		Type: TypeSessionRevoked,
+		Data: map[string]interface{}{
+			"session_id": sessionID.String(),
+		},
+		UserIDs:   []uuid.UUID{userID},
+		SessionID: &sessionID,
+	})
+}
@@ -36,6 +36,9 @@ func (h *Hub) registerHandlers() {
 
 	// Status handlers
 	h.handlers[string(TypePing)] = &PingHandler{hub: h}
+
+	// Event sync
+	h.handlers[string(TypeSync)] = &SyncHandler{hub: h}
 }
 
 // MessageSendHandler handles sending messages
@@ -79,6 +82,13 @@ func (h *MessageSendHandler) Handle(ctx context.Context, client *Client, data js
 		return fmt.Errorf("user is not a member of this conversation")
 	}
 
+	// Check the group's permission policy, send restrictions and slow mode
+	if h.hub.conversationMemberService != nil {
+		if err := h.hub.conversationMemberService.CheckSendAllowed(msgData.ConversationID, client.UserID, msgData.MessageType); err != nil {
+			return err
+		}
+	}
+
 	// Check block status before sending message
 	if h.hub.conversationMemberService != nil && h.hub.userFriendshipService != nil {
 		members, _, err := h.hub.conversationMemberService.GetMembers(client.UserID, msgData.ConversationID, 1, 1000)
@@ -1016,6 +1026,53 @@ func (h *PingHandler) ValidateData(data json.RawMessage) error {
 	return nil
 }
 
+// SyncHandler ส่ง event ที่ผู้ใช้พลาดไปหลัง sequence ที่ระบุ (ใช้ตอน reconnect)
+type SyncHandler struct {
+	hub *Hub
+}
+
+type SyncData struct {
+	Since int64 `json:"since"`
+	Limit int   `json:"limit,omitempty"`
+}
+
+func (h *SyncHandler) Handle(ctx context.Context, client *Client, data json.RawMessage) error {
+	var syncData SyncData
+	if err := json.Unmarshal(data, &syncData); err != nil {
+		return fmt.Errorf("invalid sync data: %w", err)
+	}
+
+	if h.hub.syncService == nil {
+		return fmt.Errorf("sync service unavailable")
+	}
+
+	result, err := h.hub.syncService.GetEventsSince(ctx, client.UserID, syncData.Since, syncData.Limit)
+	if err != nil {
+		log.Printf("Error syncing events for user %s: %v", client.UserID, err)
+		return err
+	}
+
+	h.hub.sendToClient(client, WSResponse{
+		Type:      TypeSync,
+		Data:      result,
+		Timestamp: time.Now(),
+		Success:   true,
+	})
+
+	return nil
+}
+
+func (h *SyncHandler) ValidateData(data json.RawMessage) error {
+	var syncData SyncData
+	if err := json.Unmarshal(data, &syncData); err != nil {
+		return err
+	}
+	if syncData.Since < 0 {
+		return fmt.Errorf("since must not be negative")
+	}
+	return nil
+}
+
 // UserStatusHandler handles user status
 // SubscribeUserStatusHandler จัดการการลงทะเบียนติดตามสถานะผู้ใช้
 type SubscribeUserStatusHandler struct {
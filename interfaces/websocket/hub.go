@@ -45,11 +45,23 @@ type Hub struct {
 	presenceService           service.PresenceService
 	userRepo                  repository.UserRepository // 🆕 เพิ่มสำหรับ typing user info
 
+	// Backplane สำหรับกระจาย broadcast ไปยัง instance อื่น (nil = single node)
+	backplane Backplane
+
+	// ID ของ instance นี้ในการนับการเชื่อมต่อของผู้ใช้ทั้ง cluster (presence)
+	nodeID string
+
+	// SyncService สำหรับกำหนด sequence และบันทึก event log ของผู้ใช้ (nil = ไม่บันทึก)
+	syncService service.SyncService
+
 	// Channels
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan *BroadcastMessage
 
+	// คิวของ broadcast ที่ต้องกำหนด sequence (goroutine เดียวประมวลผลตามลำดับ)
+	publishQueue chan *BroadcastMessage
+
 	// Statistics
 	startTime       time.Time
 	totalMessages   int64
@@ -95,6 +107,7 @@ func (r *RateLimiter) Allow() bool {
 type Client struct {
 	ID                   uuid.UUID
 	UserID               uuid.UUID
+	SessionID            uuid.UUID  // session ของอุปกรณ์ (uuid.Nil = token รุ่นเก่าที่ไม่มี session)
 	BusinessID           *uuid.UUID // If connected as business
 	ActiveConversationID *uuid.UUID // เพิ่ม field นี้
 	Conn                 *websocket.Conn
@@ -130,12 +143,13 @@ const (
 	TypeUserTyping       MessageType = "user_typing"     // 🆕 เพิ่ม (broadcast)
 
 	// Conversation events
-	TypeConversationCreate MessageType = "conversation.create"
-	TypeConversationUpdate MessageType = "conversation.update"
-	TypeConversationActive MessageType = "conversation.active"
-	TypeConversationsLoad  MessageType = "conversation.load"
-	TypeConversationJoin   MessageType = "conversation.join"
-	TypeConversationLeave  MessageType = "conversation.leave"
+	TypeConversationCreate      MessageType = "conversation.create"
+	TypeConversationUpdate      MessageType = "conversation.update"
+	TypeConversationActive      MessageType = "conversation.active"
+	TypeConversationsLoad       MessageType = "conversation.load"
+	TypeConversationJoin        MessageType = "conversation.join"
+	TypeConversationLeave       MessageType = "conversation.leave"
+	TypeConversationUserRemoved MessageType = "conversation.user_removed"
 
 	// Business events
 
@@ -167,6 +181,12 @@ const (
 	TypeNoteCreate MessageType = "note.create"
 	TypeNoteUpdate MessageType = "note.update"
 	TypeNoteDelete MessageType = "note.delete"
+
+	// Event sync (ขอ event ที่พลาดไประหว่างหลุดการเชื่อมต่อ)
+	TypeSync MessageType = "sync"
+
+	// Session ถูกเพิกถอน (ส่งก่อนปิดการเชื่อมต่อของ session นั้น)
+	TypeSessionRevoked MessageType = "session.revoked"
 )
 
 // WebSocket message structure
@@ -185,6 +205,7 @@ type WSResponse struct {
 	RequestID string      `json:"request_id,omitempty"`
 	Success   bool        `json:"success"`
 	Error     string      `json:"error,omitempty"`
+	Seq       int64       `json:"seq,omitempty"` // sequence ของ event สำหรับผู้รับ (0 = ไม่ได้บันทึกใน event log)
 }
 
 // BroadcastMessage for sending messages to multiple clients
@@ -195,6 +216,15 @@ type BroadcastMessage struct {
 	BusinessID *uuid.UUID
 	ConvID     *uuid.UUID
 	ExcludeID  *uuid.UUID // Exclude specific client
+	SessionID  *uuid.UUID // ส่งเฉพาะ client ของ session นี้แล้วปิดการเชื่อมต่อ (ใช้กับ TypeSessionRevoked)
+	StatusOf   *uuid.UUID // ส่งให้ client ที่ subscribe สถานะของผู้ใช้นี้ (user.online / user.offline / user.status)
+	Seqs       map[uuid.UUID]int64 // userID -> sequence ที่กำหนดให้ event นี้
+}
+
+// Backplane กระจาย BroadcastMessage ไปยัง API instance อื่นๆ
+// instance ปลายทางจะส่งต่อให้ client ของตัวเองผ่าน DeliverFromBackplane
+type Backplane interface {
+	Publish(msg *BroadcastMessage)
 }
 
 // MessageHandler interface for handling different message types
@@ -225,6 +255,8 @@ func NewHub(
 		register:                  make(chan *Client),
 		unregister:                make(chan *Client),
 		broadcast:                 make(chan *BroadcastMessage, 1000), // Buffer size
+		publishQueue:              make(chan *BroadcastMessage, 1000),
+		nodeID:                    uuid.New().String(),
 		startTime:                 time.Now(),
 		totalMessages:        0,
 	}
@@ -246,6 +278,8 @@ func (h *Hub) Run(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	go h.runPublisher(ctx)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -267,6 +301,7 @@ func (h *Hub) Run(ctx context.Context) {
 		case <-ticker.C:
 			log.Println("WebSocket Hub: Checking alive clients")
 			h.checkAliveClients()
+			h.refreshPresence()
 		}
 	}
 }
@@ -325,6 +360,14 @@ func (h *Hub) getConnectionDistribution() map[string]int {
 	return distribution
 }
 
+// IsUserConnected reports whether the user has at least one live connection on this instance
+func (h *Hub) IsUserConnected(userID uuid.UUID) bool {
+	h.userConnectionsMux.RLock()
+	defer h.userConnectionsMux.RUnlock()
+
+	return len(h.userConnections[userID]) > 0
+}
+
 // IncrementMessageCount increments total message count (thread-safe)
 func (h *Hub) IncrementMessageCount() {
 	h.messagesSentMux.Lock()
@@ -354,10 +397,14 @@ func (h *Hub) registerClient(client *Client) {
 
 	// แจ้งเตือนสถานะออนไลน์ให้กับผู้ที่ subscribe
 	if isFirstConnection {
-		// Update presence in Redis and Database
+		// Update presence in Redis and Database (ผู้ใช้อาจเชื่อมต่ออยู่กับ instance อื่นแล้ว)
+		cameOnline := true
 		if h.presenceService != nil {
-			if err := h.presenceService.SetUserOnline(client.UserID); err != nil {
+			first, err := h.presenceService.UserConnected(client.UserID, h.nodeID)
+			if err != nil {
 				log.Printf("Error setting user online: %v", err)
+			} else {
+				cameOnline = first
 			}
 		}
 
@@ -369,35 +416,9 @@ func (h *Hub) registerClient(client *Client) {
 			"timestamp": now.Format(time.RFC3339),
 		}
 
-		// 1. แจ้งไปยังผู้ใช้ทุกคนที่ subscribe สถานะของผู้ใช้นี้
-		h.userStatusSubsMux.RLock()
-		subscriberIDs := h.userStatusSubs[client.UserID]
-		h.userStatusSubsMux.RUnlock()
-
-		for _, subClientID := range subscriberIDs {
-			h.clientsMux.RLock()
-			subClient, ok := h.clients[subClientID]
-			h.clientsMux.RUnlock()
-
-			if ok && subClientID != client.ID {
-				log.Printf("Notifying client %s that user %s is online", subClientID, client.UserID)
-
-				// ส่ง event แบบเก่า (backward compatible)
-				h.sendToClient(subClient, WSResponse{
-					Type:      TypeUserOnline,  // "user.online"
-					Data:      statusData,
-					Timestamp: now,
-					Success:   true,
-				})
-
-				// ส่ง event แบบใหม่ (ตาม spec)
-				h.sendToClient(subClient, WSResponse{
-					Type:      TypeUserStatus,  // "user.status"
-					Data:      statusData,
-					Timestamp: now,
-					Success:   true,
-				})
-			}
+		// 1. แจ้งไปยังผู้ใช้ทุกคนที่ subscribe สถานะของผู้ใช้นี้ (ทุก instance ผ่าน backplane)
+		if cameOnline {
+			h.publishUserStatus(client.UserID, TypeUserOnline, statusData, &client.ID)
 		}
 
 		// 2. แจ้งสถานะของตัวเองกลับไปที่ client เพื่อให้รู้ว่าเชื่อมต่อสำเร็จ
@@ -500,12 +521,15 @@ func (h *Hub) unregisterClient(client *Client) {
 	// Remove from all user status subscriptions
 	h.removeClientFromAllUserStatusSubscriptions(client.ID)
 
-	// แจ้งเตือนสถานะออฟไลน์
+	// แจ้งเตือนสถานะออฟไลน์ (เฉพาะเมื่อไม่มีการเชื่อมต่อเหลือบน instance ใดเลย)
 	if isLastConnection {
 		// Update presence in Redis and Database
 		if h.presenceService != nil {
-			if err := h.presenceService.SetUserOffline(userID); err != nil {
+			offline, err := h.presenceService.UserDisconnected(userID, h.nodeID)
+			if err != nil {
 				log.Printf("Error setting user offline: %v", err)
+			} else if !offline {
+				return
 			}
 		}
 
@@ -518,36 +542,27 @@ func (h *Hub) unregisterClient(client *Client) {
 			"timestamp": now.Format(time.RFC3339),
 		}
 
-		// แจ้งไปยังผู้ใช้ทุกคนที่ subscribe สถานะของผู้ใช้นี้
-		h.userStatusSubsMux.RLock()
-		subscriberIDs := h.userStatusSubs[userID]
-		h.userStatusSubsMux.RUnlock()
-
-		for _, subClientID := range subscriberIDs {
-			h.clientsMux.RLock()
-			subClient, ok := h.clients[subClientID]
-			h.clientsMux.RUnlock()
+		// แจ้งไปยังผู้ใช้ทุกคนที่ subscribe สถานะของผู้ใช้นี้ (ทุก instance ผ่าน backplane)
+		h.publishUserStatus(userID, TypeUserOffline, statusData, nil)
+	}
+}
 
-			if ok {
-				log.Printf("Notifying client %s that user %s is offline", subClientID, userID)
+// refreshPresence ต่ออายุ presence ของผู้ใช้ที่ยังเชื่อมต่อกับ instance นี้
+// instance ที่หยุดทำงานโดยไม่ได้ unregister จะหมดอายุเองตาม TTL ของ presence
+func (h *Hub) refreshPresence() {
+	if h.presenceService == nil {
+		return
+	}
 
-				// ส่ง event แบบเก่า (backward compatible)
-				h.sendToClient(subClient, WSResponse{
-					Type:      TypeUserOffline,  // "user.offline"
-					Data:      statusData,
-					Timestamp: now,
-					Success:   true,
-				})
+	h.userConnectionsMux.RLock()
+	userIDs := make([]uuid.UUID, 0, len(h.userConnections))
+	for userID := range h.userConnections {
+		userIDs = append(userIDs, userID)
+	}
+	h.userConnectionsMux.RUnlock()
 
-				// ส่ง event แบบใหม่ (ตาม spec)
-				h.sendToClient(subClient, WSResponse{
-					Type:      TypeUserStatus,  // "user.status"
-					Data:      statusData,
-					Timestamp: now,
-					Success:   true,
-				})
-			}
-		}
+	if err := h.presenceService.RefreshConnections(userIDs, h.nodeID); err != nil {
+		log.Printf("Error refreshing presence: %v", err)
 	}
 }
 
@@ -792,6 +807,18 @@ func (h *Hub) SetPresenceService(presenceService service.PresenceService) {
 	log.Println("PresenceService has been set in WebSocket Hub")
 }
 
+// SetBackplane ตั้งค่า backplane สำหรับ multi-instance fan-out (ต้องทำก่อนเริ่ม Hub)
+func (h *Hub) SetBackplane(backplane Backplane) {
+	h.backplane = backplane
+	log.Println("Backplane has been set in WebSocket Hub")
+}
+
+// SetSyncService ตั้งค่า SyncService สำหรับ event sequence และ event log
+func (h *Hub) SetSyncService(syncService service.SyncService) {
+	h.syncService = syncService
+	log.Println("SyncService has been set in WebSocket Hub")
+}
+
 // ปรับปรุง subscribeToUserStatus ใน hub.go
 func (h *Hub) subscribeToUserStatus(clientID, targetUserID uuid.UUID) {
 	h.userStatusSubsMux.Lock()
@@ -824,8 +851,13 @@ func (h *Hub) subscribeToUserStatus(clientID, targetUserID uuid.UUID) {
 	h.clientsMux.RUnlock()
 
 	if ok {
-		// ตรวจสอบว่าผู้ใช้เป้าหมายออนไลน์อยู่หรือไม่
+		// ตรวจสอบว่าผู้ใช้เป้าหมายออนไลน์อยู่หรือไม่ (อาจเชื่อมต่ออยู่กับ instance อื่น)
 		isOnline := h.isUserOnline(targetUserID)
+		if !isOnline && h.presenceService != nil {
+			if online, err := h.presenceService.IsUserOnline(targetUserID); err == nil {
+				isOnline = online
+			}
+		}
 
 		statusType := TypeUserOffline
 		if isOnline {
@@ -848,6 +880,19 @@ func (h *Hub) subscribeToUserStatus(clientID, targetUserID uuid.UUID) {
 	}
 }
 
+// publishUserStatus แจ้งสถานะของผู้ใช้ให้ client ที่ subscribe ไว้ทุก instance
+// ส่งทั้ง event แบบเก่า (user.online / user.offline) และแบบใหม่ (user.status) ตาม spec
+func (h *Hub) publishUserStatus(userID uuid.UUID, legacyType MessageType, statusData map[string]interface{}, excludeID *uuid.UUID) {
+	for _, msgType := range []MessageType{legacyType, TypeUserStatus} {
+		h.publish(&BroadcastMessage{
+			Type:      msgType,
+			Data:      statusData,
+			StatusOf:  &userID,
+			ExcludeID: excludeID,
+		})
+	}
+}
+
 // ยกเลิกการ subscribe สถานะผู้ใช้
 func (h *Hub) unsubscribeFromUserStatus(clientID, targetUserID uuid.UUID) {
 	h.userStatusSubsMux.Lock()
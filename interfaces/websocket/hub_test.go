@@ -0,0 +1,189 @@
+// interfaces/websocket/hub_test.go
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// memoryPresence นับการเชื่อมต่อของผู้ใช้ต่อ node แบบเดียวกับ presence service ที่ใช้ Redis ร่วมกันทั้ง cluster
+type memoryPresence struct {
+	mu    sync.Mutex
+	nodes map[uuid.UUID]map[string]bool
+}
+
+func newMemoryPresence() *memoryPresence {
+	return &memoryPresence{nodes: make(map[uuid.UUID]map[string]bool)}
+}
+
+func (p *memoryPresence) UserConnected(userID uuid.UUID, nodeID string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	first := len(p.nodes[userID]) == 0
+	if p.nodes[userID] == nil {
+		p.nodes[userID] = make(map[string]bool)
+	}
+	p.nodes[userID][nodeID] = true
+	return first, nil
+}
+
+func (p *memoryPresence) UserDisconnected(userID uuid.UUID, nodeID string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.nodes[userID], nodeID)
+	if len(p.nodes[userID]) > 0 {
+		return false, nil
+	}
+	delete(p.nodes, userID)
+	return true, nil
+}
+
+func (p *memoryPresence) RefreshConnections(userIDs []uuid.UUID, nodeID string) error {
+	return nil
+}
+
+func (p *memoryPresence) UpdateLastActive(userID uuid.UUID) error { return nil }
+
+func (p *memoryPresence) IsUserOnline(userID uuid.UUID) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.nodes[userID]) > 0, nil
+}
+
+func (p *memoryPresence) nodeCount(userID uuid.UUID) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.nodes[userID])
+}
+
+func (p *memoryPresence) GetUserPresence(userID uuid.UUID) (*service.UserPresence, error) {
+	online, _ := p.IsUserOnline(userID)
+	return &service.UserPresence{UserID: userID, IsOnline: online}, nil
+}
+
+func (p *memoryPresence) GetMultipleUserPresence(userIDs []uuid.UUID) (map[uuid.UUID]*service.UserPresence, error) {
+	result := make(map[uuid.UUID]*service.UserPresence, len(userIDs))
+	for _, userID := range userIDs {
+		result[userID], _ = p.GetUserPresence(userID)
+	}
+	return result, nil
+}
+
+func (p *memoryPresence) GetOnlineUsers() ([]uuid.UUID, error) { return nil, nil }
+
+func (p *memoryPresence) GetOnlineFriends(userID uuid.UUID) ([]*service.UserPresence, error) {
+	return nil, nil
+}
+
+// linkedBackplane ส่ง broadcast ไปยัง hub อีกตัวในโปรเซสเดียวกัน (แทน Redis pub/sub)
+type linkedBackplane struct {
+	peer *Hub
+}
+
+func (b *linkedBackplane) Publish(msg *BroadcastMessage) {
+	b.peer.DeliverFromBackplane(msg)
+}
+
+const testMarker MessageType = "test.marker"
+
+func newTestClient(hub *Hub, userID uuid.UUID) *Client {
+	return &Client{ID: uuid.New(), UserID: userID, Hub: hub, Send: make(chan []byte, 64), IsAlive: true}
+}
+
+// receivedUntilMarker รอให้ via ทำงานในคิวเสร็จ ส่ง marker ผ่าน via (รวม backplane) แล้วคืนชนิดของ event ที่ client ได้รับก่อน marker
+// event ที่ via broadcast ก่อนหน้าจึงไปถึง client แล้วทั้งหมด
+func receivedUntilMarker(t *testing.T, via *Hub, client *Client) []MessageType {
+	t.Helper()
+
+	// Run รับ register ถัดไปหลังจากจัดการ register/unregister ก่อนหน้าเสร็จแล้วเท่านั้น
+	via.register <- newTestClient(via, uuid.New())
+	via.deliver(&BroadcastMessage{Type: testMarker, Data: map[string]interface{}{}, UserIDs: []uuid.UUID{client.UserID}})
+
+	var types []MessageType
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case data := <-client.Send:
+			var response WSResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			if response.Type == testMarker {
+				return types
+			}
+			types = append(types, response.Type)
+		case <-timeout:
+			t.Fatal("timed out waiting for marker")
+			return nil
+		}
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestUserStaysOnlineWhileConnectedToAnotherNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	presence := newMemoryPresence()
+	nodeA := NewHub(nil, nil, nil, nil, nil)
+	nodeB := NewHub(nil, nil, nil, nil, nil)
+	nodeA.SetPresenceService(presence)
+	nodeB.SetPresenceService(presence)
+	nodeA.SetBackplane(&linkedBackplane{peer: nodeB})
+	nodeB.SetBackplane(&linkedBackplane{peer: nodeA})
+	go nodeA.Run(ctx)
+	go nodeB.Run(ctx)
+
+	userID := uuid.New()
+	watcher := newTestClient(nodeB, uuid.New())
+	nodeB.register <- watcher
+	waitFor(t, func() bool { return nodeB.isUserOnline(watcher.UserID) })
+	nodeB.subscribeToUserStatus(watcher.ID, userID)
+
+	onA := newTestClient(nodeA, userID)
+	onB := newTestClient(nodeB, userID)
+	nodeA.register <- onA
+	nodeB.register <- onB
+	receivedUntilMarker(t, nodeA, watcher)
+	receivedUntilMarker(t, nodeB, watcher)
+
+	// node A ปิดการเชื่อมต่อสุดท้ายของตัวเอง แต่ผู้ใช้ยังเชื่อมต่ออยู่กับ node B
+	nodeA.unregister <- onA
+	for _, eventType := range receivedUntilMarker(t, nodeA, watcher) {
+		if eventType == TypeUserOffline || eventType == TypeUserStatus {
+			t.Fatalf("user must stay online while connected to node B, got %s", eventType)
+		}
+	}
+	if presence.nodeCount(userID) != 1 {
+		t.Fatal("presence must stay online while connected to node B")
+	}
+
+	// การเชื่อมต่อสุดท้ายใน cluster ปิด: แจ้ง offline
+	nodeB.unregister <- onB
+	offline := false
+	for _, eventType := range receivedUntilMarker(t, nodeB, watcher) {
+		offline = offline || eventType == TypeUserOffline
+	}
+	if !offline || presence.nodeCount(userID) != 0 {
+		t.Fatal("expected user.offline once no node holds a connection")
+	}
+}
@@ -57,7 +57,7 @@ func RegisterWebSocketRoutes(app *fiber.App, hub *Hub, authHandler fiber.Handler
 		}
 
 		// Validate token
-		userUUID, err := middleware.ValidateTokenStringToUUID(token)
+		userUUID, sessionID, err := middleware.ValidateTokenSession(token)
 		if err != nil {
 			log.Printf("Token validation error: %v", err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -71,6 +71,7 @@ func RegisterWebSocketRoutes(app *fiber.App, hub *Hub, authHandler fiber.Handler
 		// Store user info in locals
 		c.Locals("userID", userUUID.String())
 		c.Locals("userUUID", userUUID)
+		c.Locals("sessionID", sessionID)
 
 		return c.Next()
 	}, websocket.New(func(c *websocket.Conn) {
@@ -87,10 +88,14 @@ func RegisterWebSocketRoutes(app *fiber.App, hub *Hub, authHandler fiber.Handler
 
 		log.Printf("WebSocket connection established for user: %s", userUUID.String())
 
+		// session ของอุปกรณ์ (ใช้ปิดการเชื่อมต่อเมื่อ session ถูกเพิกถอน)
+		sessionID, _ := c.Locals("sessionID").(uuid.UUID)
+
 		// สร้าง client
 		client := &Client{
 			ID:           uuid.New(),
 			UserID:       userUUID,
+			SessionID:    sessionID,
 			Conn:         c,
 			Send:         make(chan []byte, 256),
 			Hub:          hub,
@@ -0,0 +1,380 @@
+// internal/testsupport/fake_websocket_adapter.go
+
+// Package testsupport รวม test double ที่ใช้ร่วมกันในการทดสอบ (ไม่ถูก import จาก production code)
+package testsupport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+)
+
+// WebSocketEvent event หนึ่งรายการที่ FakeWebSocketAdapter บันทึกไว้
+// ใช้ชื่อ type เดียวกับที่ WebSocketAdapter ส่งให้ client จริง
+type WebSocketEvent struct {
+	Type           string
+	ConversationID uuid.UUID   // ปลายทางเป็นการสนทนา (uuid.Nil ถ้าไม่ใช่)
+	BusinessID     uuid.UUID   // ปลายทางเป็นธุรกิจ (uuid.Nil ถ้าไม่ใช่)
+	UserIDs        []uuid.UUID // ปลายทางเป็นผู้ใช้
+	Data           interface{}
+}
+
+// FakeWebSocketAdapter implements port.WebSocketPort โดยบันทึก event ไว้ในหน่วยความจำแทนการส่งผ่าน Hub
+// ใช้ในการทดสอบ service เพื่อตรวจว่ามีการแจ้งเตือนอะไรออกไปบ้าง
+type FakeWebSocketAdapter struct {
+	mu        sync.Mutex
+	events    []WebSocketEvent
+	connected map[uuid.UUID]bool
+}
+
+var _ port.WebSocketPort = (*FakeWebSocketAdapter)(nil)
+
+// NewFakeWebSocketAdapter สร้าง FakeWebSocketAdapter ตัวใหม่
+func NewFakeWebSocketAdapter() *FakeWebSocketAdapter {
+	return &FakeWebSocketAdapter{}
+}
+
+// Events คืนสำเนาของ event ทั้งหมดตามลำดับที่ส่ง
+func (a *FakeWebSocketAdapter) Events() []WebSocketEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	events := make([]WebSocketEvent, len(a.events))
+	copy(events, a.events)
+	return events
+}
+
+// EventsOfType คืน event ที่มี type ตรงกัน
+func (a *FakeWebSocketAdapter) EventsOfType(eventType string) []WebSocketEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var events []WebSocketEvent
+	for _, e := range a.events {
+		if e.Type == eventType {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// EventsForUser คืน event ที่ส่งตรงถึงผู้ใช้ (ไม่รวม event ที่ส่งให้ทั้งการสนทนา)
+func (a *FakeWebSocketAdapter) EventsForUser(userID uuid.UUID) []WebSocketEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var events []WebSocketEvent
+	for _, e := range a.events {
+		for _, id := range e.UserIDs {
+			if id == userID {
+				events = append(events, e)
+				break
+			}
+		}
+	}
+	return events
+}
+
+// Reset ล้าง event ที่บันทึกไว้
+func (a *FakeWebSocketAdapter) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = nil
+}
+
+func (a *FakeWebSocketAdapter) record(event WebSocketEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+}
+
+func (a *FakeWebSocketAdapter) toConversation(conversationID uuid.UUID, eventType string, data interface{}) {
+	a.record(WebSocketEvent{Type: eventType, ConversationID: conversationID, Data: data})
+}
+
+func (a *FakeWebSocketAdapter) toUsers(userIDs []uuid.UUID, eventType string, data interface{}) {
+	ids := make([]uuid.UUID, len(userIDs))
+	copy(ids, userIDs)
+	a.record(WebSocketEvent{Type: eventType, UserIDs: ids, Data: data})
+}
+
+func (a *FakeWebSocketAdapter) toBusiness(businessID uuid.UUID, eventType string, data interface{}) {
+	a.record(WebSocketEvent{Type: eventType, BusinessID: businessID, Data: data})
+}
+
+// BroadcastToUser ส่งข้อความไปยังผู้ใช้คนใดคนหนึ่ง
+func (a *FakeWebSocketAdapter) BroadcastToUser(userID uuid.UUID, messageType string, data interface{}) {
+	a.toUsers([]uuid.UUID{userID}, messageType, data)
+}
+
+// =========== Message Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastNewMessage(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.receive", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageRead(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.read", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageReadAll(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.read_all", message)
+}
+
+func (a *FakeWebSocketAdapter) SendMessageReadToSender(senderID uuid.UUID, message interface{}) {
+	a.BroadcastToUser(senderID, "message.read", message)
+}
+
+func (a *FakeWebSocketAdapter) SendMessageReadAllToUser(userID uuid.UUID, message interface{}) {
+	a.BroadcastToUser(userID, "message.read_all", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageDelivered(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.delivered", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageEdited(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.updated", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageReply(conversationID uuid.UUID, message interface{}) {
+	a.toConversation(conversationID, "message.reply", message)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageDeleted(conversationID uuid.UUID, messageID uuid.UUID) {
+	a.toConversation(conversationID, "message.delete", map[string]interface{}{
+		"message_id": messageID,
+		"deleted_at": time.Now(),
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageReaction(conversationID uuid.UUID, reaction interface{}) {
+	a.toConversation(conversationID, "message.reaction", reaction)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastThreadReply(userIDs []uuid.UUID, reply interface{}) {
+	a.toUsers(userIDs, "thread.reply", reply)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastPollUpdated(conversationID uuid.UUID, poll interface{}) {
+	a.toConversation(conversationID, "poll.updated", poll)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastJoinRequestCreated(adminIDs []uuid.UUID, request interface{}) {
+	a.toUsers(adminIDs, "join_request.created", request)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastJoinRequestReviewed(userID uuid.UUID, request interface{}) {
+	a.toUsers([]uuid.UUID{userID}, "join_request.reviewed", request)
+}
+
+// =========== Conversation Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastConversationCreated(userIDs []uuid.UUID, conversation interface{}) error {
+	a.toUsers(userIDs, "conversation.create", conversation)
+	return nil
+}
+
+func (a *FakeWebSocketAdapter) BroadcastConversationUpdated(conversationID uuid.UUID, update interface{}) {
+	a.toConversation(conversationID, "conversation.update", update)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastConversationDeleted(conversationID uuid.UUID, memberIDs []uuid.UUID) {
+	a.toUsers(memberIDs, "conversation.deleted", map[string]interface{}{
+		"conversation_id": conversationID,
+		"deleted_at":      time.Now(),
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastUserAddedToConversation(conversationID uuid.UUID, userID uuid.UUID) {
+	a.toConversation(conversationID, "conversation.user_added", map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	})
+	a.BroadcastToUser(userID, "conversation.create", map[string]interface{}{
+		"conversation_id": conversationID,
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastUserRemovedFromConversation(userID, conversationID uuid.UUID) {
+	data := map[string]interface{}{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	}
+	a.toConversation(conversationID, "conversation.user_removed", data)
+	a.BroadcastToUser(userID, "conversation.user_removed", data)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastNewConversation(userID uuid.UUID, conversation interface{}) error {
+	a.BroadcastToUser(userID, "conversation.create", conversation)
+	return nil
+}
+
+// =========== Member Role / Activity Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastMemberRoleChanged(conversationID uuid.UUID, data interface{}) {
+	a.toConversation(conversationID, "conversation.member_role_changed", data)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastOwnershipTransferred(conversationID uuid.UUID, data interface{}) {
+	a.toConversation(conversationID, "conversation.ownership_transferred", data)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastNewActivity(conversationID uuid.UUID, activity interface{}) {
+	a.toConversation(conversationID, "conversation.activity.new", activity)
+}
+
+// =========== Business / Profile Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastBusinessBroadcast(userIDs []uuid.UUID, broadcast interface{}) {
+	a.toUsers(userIDs, "business.broadcast", broadcast)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastBusinessNewFollower(businessID, followerID uuid.UUID) {
+	a.toBusiness(businessID, "business.new_follower", map[string]interface{}{
+		"business_id": businessID,
+		"follower_id": followerID,
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastBusinessWelcomeMessage(userID, businessID uuid.UUID, message interface{}) {
+	a.BroadcastToUser(userID, "business.welcome", map[string]interface{}{
+		"business_id": businessID,
+		"message":     message,
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastBusinessFollowStatusChanged(businessID, userID uuid.UUID, isFollowing bool) {
+	data := map[string]interface{}{
+		"business_id":  businessID,
+		"user_id":      userID,
+		"is_following": isFollowing,
+	}
+	a.toBusiness(businessID, "business.follow_status_changed", data)
+	a.BroadcastToUser(userID, "user.follow_status_changed", data)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastBusinessStatusChanged(businessID uuid.UUID, status string) {
+	a.toBusiness(businessID, "business.status", map[string]interface{}{
+		"business_id": businessID,
+		"status":      status,
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastProfileUpdate(businessID, userID uuid.UUID, profile interface{}) {
+	a.toBusiness(businessID, "profile.update", profile)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastProfileUpdateTags(businessID uuid.UUID, userID uuid.UUID, payload interface{}) {
+	a.toBusiness(businessID, "profile.tag_update", payload)
+}
+
+// =========== Friend / User Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastFriendRequestReceived(userID uuid.UUID, request interface{}) error {
+	a.BroadcastToUser(userID, "friend_request.received", request)
+	return nil
+}
+
+func (a *FakeWebSocketAdapter) BroadcastFriendRequestAccepted(userID uuid.UUID, friendship interface{}) error {
+	a.BroadcastToUser(userID, "friend_request.accepted", friendship)
+	return nil
+}
+
+func (a *FakeWebSocketAdapter) BroadcastFriendRequestRejected(userID uuid.UUID, friendship interface{}) error {
+	a.BroadcastToUser(userID, "friend_request.rejected", friendship)
+	return nil
+}
+
+func (a *FakeWebSocketAdapter) BroadcastFriendRemoved(userID, friendID uuid.UUID) {
+	a.BroadcastToUser(friendID, "friend.removed", map[string]interface{}{"user_id": userID.String()})
+	a.BroadcastToUser(userID, "friend.removed", map[string]interface{}{"user_id": friendID.String()})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastUserBlocked(blockerID, blockedID uuid.UUID) {
+	data := map[string]interface{}{
+		"blocker_id":      blockerID.String(),
+		"blocked_user_id": blockedID.String(),
+	}
+	a.BroadcastToUser(blockerID, "user.blocked", data)
+	a.BroadcastToUser(blockedID, "user.blocked_by", data)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastUserUnblocked(unblockerID, unblockedID uuid.UUID) {
+	data := map[string]interface{}{
+		"unblocker_id":      unblockerID.String(),
+		"unblocked_user_id": unblockedID.String(),
+	}
+	a.BroadcastToUser(unblockerID, "user.unblocked", data)
+	a.BroadcastToUser(unblockedID, "user.unblocked_by", data)
+}
+
+// =========== General Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastNotification(userIDs []uuid.UUID, notification interface{}) {
+	a.toUsers(userIDs, "notification", notification)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastAlert(userID uuid.UUID, alert interface{}) {
+	a.BroadcastToUser(userID, "alert", alert)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastSystemMessage(userIDs []uuid.UUID, message interface{}) {
+	a.toUsers(userIDs, "system.message", message)
+}
+
+// =========== Note / Pin Notifications ===========
+
+func (a *FakeWebSocketAdapter) BroadcastNoteCreated(conversationID uuid.UUID, note interface{}) {
+	a.toConversation(conversationID, "note.create", note)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastNoteUpdated(conversationID uuid.UUID, note interface{}) {
+	a.toConversation(conversationID, "note.update", note)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastNoteDeleted(conversationID uuid.UUID, noteID uuid.UUID, userID uuid.UUID) {
+	a.toConversation(conversationID, "note.delete", map[string]interface{}{
+		"note_id": noteID,
+		"user_id": userID,
+	})
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessagePinned(conversationID uuid.UUID, pinnedMessage interface{}) {
+	a.toConversation(conversationID, "message.pinned", pinnedMessage)
+}
+
+func (a *FakeWebSocketAdapter) BroadcastMessageUnpinned(conversationID uuid.UUID, messageID uuid.UUID, userID uuid.UUID) {
+	a.toConversation(conversationID, "message.unpinned", map[string]interface{}{
+		"message_id": messageID,
+		"user_id":    userID,
+	})
+}
+
+// DisconnectSession บันทึก session.revoked ให้ผู้ใช้ (ไม่มีการเชื่อมต่อจริงให้ปิด)
+func (a *FakeWebSocketAdapter) DisconnectSession(userID, sessionID uuid.UUID) {
+	a.toUsers([]uuid.UUID{userID}, "session.revoked", map[string]interface{}{
+		"session_id": sessionID.String(),
+	})
+}
+
+// SetUserConnected กำหนดว่าผู้ใช้มี WebSocket เชื่อมต่ออยู่หรือไม่ (ค่าเริ่มต้นคือไม่ได้เชื่อมต่อ)
+func (a *FakeWebSocketAdapter) SetUserConnected(userID uuid.UUID, connected bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.connected == nil {
+		a.connected = make(map[uuid.UUID]bool)
+	}
+	a.connected[userID] = connected
+}
+
+// IsUserConnected คืนค่าที่กำหนดไว้ด้วย SetUserConnected
+func (a *FakeWebSocketAdapter) IsUserConnected(userID uuid.UUID) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.connected[userID]
+}
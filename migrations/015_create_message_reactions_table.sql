@@ -0,0 +1,20 @@
+-- migrations/015_create_message_reactions_table.sql
+-- Create message_reactions table for emoji reactions on messages
+
+CREATE TABLE IF NOT EXISTS message_reactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    -- Unique Constraint: a user can use each emoji once per message
+    CONSTRAINT idx_message_reactions_unique UNIQUE (message_id, user_id, emoji)
+);
+
+-- Create Indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_message_reactions_message_id ON message_reactions(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_reactions_conversation_id ON message_reactions(conversation_id);
+
+COMMENT ON TABLE message_reactions IS 'Stores emoji reactions on messages';
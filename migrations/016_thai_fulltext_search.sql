@@ -0,0 +1,53 @@
+-- migrations/016_thai_fulltext_search.sql
+-- Thai-aware full-text search for messages and notes
+--
+-- to_tsvector('english', ...) cannot split Thai text (no spaces between words).
+-- The application now builds the tsvector itself (pkg/textsearch):
+--   - Thai runs are indexed as character bigrams with consecutive positions
+--     and queried with the phrase operator (<->)
+--   - other words are lower-cased and queried by prefix
+-- The literal is stored in search_document and cast to content_tsvector by a trigger.
+-- Existing rows are backfilled by database.SetupFullTextSearch on startup.
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_document TEXT;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS search_document TEXT;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS content_tsvector tsvector;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS content_tsvector tsvector;
+
+CREATE INDEX IF NOT EXISTS idx_messages_content_tsvector ON messages USING GIN (content_tsvector);
+CREATE INDEX IF NOT EXISTS idx_notes_fulltext ON notes USING GIN (content_tsvector);
+
+-- messages
+CREATE OR REPLACE FUNCTION messages_search_document_update() RETURNS trigger AS $$
+BEGIN
+  NEW.content_tsvector := COALESCE(NEW.search_document, '')::tsvector;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tsvector_update ON messages;
+DROP TRIGGER IF EXISTS tsvectorupdate ON messages;
+DROP TRIGGER IF EXISTS search_document_update ON messages;
+CREATE TRIGGER search_document_update
+  BEFORE INSERT OR UPDATE OF search_document ON messages
+  FOR EACH ROW
+  EXECUTE FUNCTION messages_search_document_update();
+
+-- notes
+CREATE OR REPLACE FUNCTION notes_search_document_update() RETURNS trigger AS $$
+BEGIN
+  NEW.content_tsvector := COALESCE(NEW.search_document, '')::tsvector;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tsvectorupdate ON notes;
+DROP TRIGGER IF EXISTS search_document_update ON notes;
+CREATE TRIGGER search_document_update
+  BEFORE INSERT OR UPDATE OF search_document ON notes
+  FOR EACH ROW
+  EXECUTE FUNCTION notes_search_document_update();
+
+COMMENT ON COLUMN messages.search_document IS 'tsvector literal built by the application (Thai bigrams + words)';
+COMMENT ON COLUMN notes.search_document IS 'tsvector literal built by the application (title weight A, content weight B)';
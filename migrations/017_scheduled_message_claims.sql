@@ -0,0 +1,19 @@
+-- migrations/017_scheduled_message_claims.sql
+-- Add retry and lease columns so scheduled messages are sent exactly once across instances
+
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS attempts INTEGER DEFAULT 0;
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS locked_by VARCHAR(100);
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+-- Index for finding messages that are due (including retries)
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_due
+    ON scheduled_messages((COALESCE(next_attempt_at, scheduled_at))) WHERE status = 'pending';
+
+-- Index for finding expired leases
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_lease
+    ON scheduled_messages(locked_until) WHERE status = 'processing';
+
+COMMENT ON COLUMN scheduled_messages.status IS 'Status: pending, processing, sent, cancelled, failed';
+COMMENT ON COLUMN scheduled_messages.attempts IS 'Number of send attempts so far';
+COMMENT ON COLUMN scheduled_messages.locked_until IS 'Lease expiry of the instance currently sending the message';
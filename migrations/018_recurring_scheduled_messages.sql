@@ -0,0 +1,14 @@
+-- migrations/018_recurring_scheduled_messages.sql
+-- Add recurrence columns so scheduled messages can repeat on a cron schedule
+
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS series_id UUID;
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS recurrence_rule VARCHAR(100);
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS recurrence_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_series_id ON scheduled_messages(series_id);
+
+COMMENT ON COLUMN scheduled_messages.status IS 'Status: pending, processing, paused, sent, cancelled, failed';
+COMMENT ON COLUMN scheduled_messages.series_id IS 'Groups occurrences of a recurring scheduled message';
+COMMENT ON COLUMN scheduled_messages.recurrence_rule IS 'Cron expression (minute hour day-of-month month day-of-week)';
+COMMENT ON COLUMN scheduled_messages.timezone IS 'IANA timezone used to evaluate recurrence_rule';
@@ -0,0 +1,27 @@
+-- migrations/019_message_threads.sql
+-- Add thread columns to messages and thread_subscriptions for per-user thread follow
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_root_id UUID REFERENCES messages(id) ON DELETE CASCADE;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_reply_count INTEGER DEFAULT 0;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_last_reply_at TIMESTAMP WITH TIME ZONE;
+
+-- Thread replies are paged by (created_at, id) under their root
+CREATE INDEX IF NOT EXISTS idx_messages_thread_root_id ON messages(thread_root_id, created_at, id) WHERE thread_root_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS thread_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    -- Unique Constraint: a user follows a thread once
+    CONSTRAINT idx_thread_subscriptions_unique UNIQUE (message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_subscriptions_conversation_id ON thread_subscriptions(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_thread_subscriptions_user_id ON thread_subscriptions(user_id);
+
+COMMENT ON COLUMN messages.thread_root_id IS 'Root message of the thread; thread replies are hidden from the main timeline';
+COMMENT ON COLUMN messages.thread_reply_count IS 'Number of replies in the thread rooted at this message';
+COMMENT ON TABLE thread_subscriptions IS 'Users following a message thread (receive thread.reply events)';
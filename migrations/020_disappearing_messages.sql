@@ -0,0 +1,11 @@
+-- migrations/020_disappearing_messages.sql
+-- Add per-conversation message TTL and per-message expiry for disappearing messages
+
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS message_ttl_seconds INTEGER DEFAULT 0;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+-- The sweeper looks up expired messages that are not deleted yet
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL AND is_deleted = false;
+
+COMMENT ON COLUMN conversations.message_ttl_seconds IS 'New messages are deleted after this many seconds (0 = disabled)';
+COMMENT ON COLUMN messages.expires_at IS 'When the message disappears; set from conversations.message_ttl_seconds at creation';
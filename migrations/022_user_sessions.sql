@@ -0,0 +1,26 @@
+-- migrations/022_user_sessions.sql
+-- Per-device sessions: each login gets its own session and refresh tokens are scoped to it
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_name VARCHAR(100),
+    platform VARCHAR(20),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Session list only shows active sessions
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_active ON user_sessions(user_id, last_used_at DESC) WHERE revoked_at IS NULL;
+
+-- Existing refresh tokens keep session_id NULL and are moved into a session on their next refresh
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES user_sessions(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+COMMENT ON TABLE user_sessions IS 'Login session per device; revoking a session revokes its refresh tokens and closes its WebSocket connections';
+COMMENT ON COLUMN refresh_tokens.session_id IS 'Session the token belongs to; refresh rotates only the tokens of this session';
@@ -0,0 +1,31 @@
+-- migrations/023_refresh_token_families.sql
+-- Refresh token families: tokens are stored as SHA-256 hashes and a rotated-out token
+-- presented again revokes its whole family (see authService.RefreshToken)
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS token_hash CHAR(64);
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP WITH TIME ZONE;
+
+-- Hash existing plain-text tokens; each existing token becomes its own family
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'refresh_tokens' AND column_name = 'token'
+    ) THEN
+        UPDATE refresh_tokens
+        SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex')
+        WHERE token_hash IS NULL AND token IS NOT NULL;
+
+        ALTER TABLE refresh_tokens DROP COLUMN token;
+    END IF;
+END $$;
+
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'SHA-256 (hex) of the refresh token; the token itself is never stored';
+COMMENT ON COLUMN refresh_tokens.family_id IS 'All tokens rotated from the same login; reuse of a rotated token revokes the family';
+COMMENT ON COLUMN refresh_tokens.rotated_at IS 'Set when the token was replaced by rotation; presenting it again is treated as theft';
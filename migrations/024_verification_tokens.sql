@@ -0,0 +1,22 @@
+-- migrations/024_verification_tokens.sql
+-- Single-use codes sent by email for password reset and email verification (only the SHA-256 hash is stored)
+
+CREATE TABLE IF NOT EXISTS verification_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_tokens_token_hash ON verification_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_user_id ON verification_tokens(user_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON TABLE verification_tokens IS 'Password reset and email verification codes; a code can be used once and issuing a new one invalidates older unused codes';
+COMMENT ON COLUMN verification_tokens.email IS 'Address the code was sent to; verification fails if the user changed their email since';
+COMMENT ON COLUMN users.email_verified_at IS 'NULL until the user confirms their email address';
@@ -0,0 +1,29 @@
+-- migrations/025_two_factor.sql
+-- Optional TOTP two-factor authentication (RFC 6238) with single-use recovery codes
+
+CREATE TABLE IF NOT EXISTS user_two_factors (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN DEFAULT FALSE,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT DEFAULT 0,
+    failed_attempts INTEGER DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+COMMENT ON TABLE user_two_factors IS 'TOTP settings per user; a row with enabled = false is an enrollment that has not been confirmed with a code yet';
+COMMENT ON COLUMN user_two_factors.last_used_step IS 'Last accepted 30-second time step; codes for this step or older are rejected to prevent replay';
+COMMENT ON COLUMN user_two_factors.locked_until IS 'Set after repeated wrong codes; verification is refused until this time';
+COMMENT ON TABLE user_recovery_codes IS 'One-time recovery codes (SHA-256 hash only); regenerating replaces the whole set';
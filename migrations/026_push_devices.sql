@@ -0,0 +1,27 @@
+-- migrations/026_push_devices.sql
+-- Push notification devices (Web Push subscriptions, FCM and APNs tokens) for users without a live WebSocket
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES user_sessions(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    token TEXT NOT NULL,
+    p256dh VARCHAR(255),
+    auth VARCHAR(255),
+    platform VARCHAR(20),
+    device_name VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_devices_token ON push_devices(token);
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
+CREATE INDEX IF NOT EXISTS idx_push_devices_session_id ON push_devices(session_id);
+
+COMMENT ON TABLE push_devices IS 'Devices that receive push notifications while the user has no WebSocket connection';
+COMMENT ON COLUMN push_devices.provider IS 'webpush, fcm or apns';
+COMMENT ON COLUMN push_devices.token IS 'FCM/APNs device token, or the subscription endpoint for Web Push; registering a known token moves it to the new user';
+COMMENT ON COLUMN push_devices.p256dh IS 'Web Push only: subscription public key used to encrypt the payload (RFC 8291)';
+COMMENT ON COLUMN push_devices.session_id IS 'Session that registered the device; pushes stop when the session is revoked';
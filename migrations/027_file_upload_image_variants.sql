@@ -0,0 +1,16 @@
+-- migrations/027_file_upload_image_variants.sql
+-- Image pipeline results (dimensions, thumbnail/medium variants, blurhash placeholder) stored on the upload record
+
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS width INTEGER DEFAULT 0;
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS height INTEGER DEFAULT 0;
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS medium_url TEXT;
+ALTER TABLE file_uploads ADD COLUMN IF NOT EXISTS blur_hash VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_file_uploads_url ON file_uploads(url);
+
+COMMENT ON COLUMN file_uploads.width IS 'Image width after EXIF auto-orientation; 0 for non-images or images the pipeline could not decode';
+COMMENT ON COLUMN file_uploads.thumbnail_url IS 'Server-generated thumbnail (longest side 320px); used as the message thumbnail when the client sends none';
+COMMENT ON COLUMN file_uploads.medium_url IS 'Server-generated medium variant (longest side 1280px); empty when the original is already smaller';
+COMMENT ON COLUMN file_uploads.blur_hash IS 'BlurHash placeholder string rendered by clients while the image loads';
+COMMENT ON INDEX idx_file_uploads_url IS 'Lookup of pipeline results by media URL when sending an image message';
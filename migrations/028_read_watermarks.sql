@@ -0,0 +1,45 @@
+-- migrations/028_read_watermarks.sql
+-- Per-member read watermarks replace one message_reads row per message.
+-- User-sent timeline messages get an increasing per-conversation sequence; a member has read every message
+-- whose sequence is <= their last_read_seq, so unread count = conversations.last_message_seq - last_read_seq.
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS sequence BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS last_message_seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS last_read_message_id UUID;
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS last_read_seq BIGINT NOT NULL DEFAULT 0;
+
+-- Backfill: number user messages in timeline order; system messages share the sequence of the message before them
+WITH numbered AS (
+    SELECT id,
+           SUM(CASE WHEN sender_id IS NOT NULL THEN 1 ELSE 0 END)
+               OVER (PARTITION BY conversation_id ORDER BY created_at, id) AS seq
+    FROM messages
+    WHERE thread_root_id IS NULL
+)
+UPDATE messages m SET sequence = numbered.seq
+FROM numbered
+WHERE m.id = numbered.id;
+
+UPDATE conversations c SET last_message_seq = COALESCE(
+    (SELECT MAX(m.sequence) FROM messages m WHERE m.conversation_id = c.id), 0);
+
+-- Watermark = newest message the member had read: covered by last_read_at, or by an existing message_reads row
+UPDATE conversation_members cm SET last_read_seq = w.seq, last_read_message_id = w.message_id
+FROM (
+    SELECT DISTINCT ON (cm2.id) cm2.id AS member_id, m.sequence AS seq, m.id AS message_id
+    FROM conversation_members cm2
+    JOIN messages m ON m.conversation_id = cm2.conversation_id AND m.thread_root_id IS NULL AND m.sequence > 0
+    WHERE (cm2.last_read_at IS NOT NULL AND m.created_at <= cm2.last_read_at)
+       OR m.sender_id = cm2.user_id
+       OR EXISTS (SELECT 1 FROM message_reads mr WHERE mr.message_id = m.id AND mr.user_id = cm2.user_id)
+    ORDER BY cm2.id, m.sequence DESC, m.created_at DESC
+) w
+WHERE cm.id = w.member_id;
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_sequence ON messages(conversation_id, sequence);
+
+COMMENT ON COLUMN messages.sequence IS 'Per-conversation read watermark sequence: +1 for each user timeline message, system messages reuse the previous value, thread replies are 0';
+COMMENT ON COLUMN conversations.last_message_seq IS 'Sequence of the newest user timeline message; unread count = last_message_seq - conversation_members.last_read_seq';
+COMMENT ON COLUMN conversation_members.last_read_message_id IS 'Read watermark: newest message the member has read (everything at or before it counts as read)';
+COMMENT ON COLUMN conversation_members.last_read_seq IS 'Sequence of last_read_message_id; only moves forward';
+COMMENT ON TABLE message_reads IS 'Deprecated: read state is derived from conversation_members read watermarks (migration 028); kept for history only';
@@ -0,0 +1,6 @@
+-- migrations/029_conversation_permissions.sql
+-- Per-group permission policy: minimum member role for sending, sending media, pinning, adding members, inviting and editing group info
+
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS permissions JSONB DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN conversations.permissions IS 'Minimum role per permission, e.g. {"send_message": "admin"} for announcement-only groups; missing keys use the defaults (send: member, pin/add/invite/edit info: admin)';
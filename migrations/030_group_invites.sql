@@ -0,0 +1,38 @@
+-- migrations/030_group_invites.sql
+-- Shareable group invite links (optional expiry / max uses / approval) and the join requests they create
+
+CREATE TABLE IF NOT EXISTS group_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    require_approval BOOLEAN DEFAULT FALSE,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_invites_conversation_id ON group_invites(conversation_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS group_join_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invite_id UUID REFERENCES group_invites(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_join_requests_conversation ON group_join_requests(conversation_id, status, created_at);
+
+-- A user has at most one pending request per group
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_join_requests_pending
+    ON group_join_requests(conversation_id, user_id) WHERE status = 'pending';
+
+COMMENT ON COLUMN group_invites.max_uses IS '0 = unlimited';
+COMMENT ON COLUMN group_invites.require_approval IS 'Joining through this link creates a pending group_join_requests row instead of adding the member';
@@ -0,0 +1,25 @@
+-- migrations/031_member_moderation.sql
+-- Member moderation: group ban list, per-member send restriction, slow mode
+
+CREATE TABLE IF NOT EXISTS group_bans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    banned_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(500),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_bans_conversation_user ON group_bans(conversation_id, user_id);
+
+ALTER TABLE conversation_members
+    ADD COLUMN IF NOT EXISTS send_restricted_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS last_sent_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE conversations
+    ADD COLUMN IF NOT EXISTS slow_mode_seconds INTEGER DEFAULT 0;
+
+COMMENT ON COLUMN group_bans.expires_at IS 'NULL = permanent ban';
+COMMENT ON COLUMN conversation_members.last_sent_at IS 'Last message time, maintained only while slow mode is on';
+COMMENT ON COLUMN conversations.slow_mode_seconds IS 'Minimum seconds between messages for non-admin members, 0 = off';
@@ -0,0 +1,4 @@
+-- migrations/032_system_message_deletes.sql
+-- Expired (disappearing) messages are deleted by the system, so the delete history has no actor
+
+ALTER TABLE message_delete_history ALTER COLUMN deleted_by DROP NOT NULL;
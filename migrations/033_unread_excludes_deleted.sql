@@ -0,0 +1,10 @@
+-- migrations/033_unread_excludes_deleted.sql
+-- Unread counts subtract messages after the member's read watermark that were deleted or expired
+
+ALTER TABLE conversation_members ADD COLUMN IF NOT EXISTS deleted_unread BIGINT NOT NULL DEFAULT 0;
+
+UPDATE conversation_members cm SET deleted_unread = (
+    SELECT COUNT(*) FROM messages m
+    WHERE m.conversation_id = cm.conversation_id AND m.is_deleted = true AND m.sender_id IS NOT NULL
+        AND m.thread_root_id IS NULL AND m.sequence > cm.last_read_seq
+);
@@ -62,6 +62,9 @@ func SetupApp(container *di.Container) *fiber.App {
 		})
 	})
 
+	// access token ของ session ที่ถูกเพิกถอนใช้ไม่ได้ทั้ง REST และ WebSocket
+	middleware.SetSessionChecker(container.SessionService.IsSessionActive)
+
 	// กำหนดเส้นทางทั้งหมด (ไม่แก้ไข - ใช้แบบเดิม)
 	routes.SetupRoutes(
 		app,
@@ -80,6 +83,17 @@ func SetupApp(container *di.Container) *fiber.App {
 		container.SearchHandler,
 		container.PresenceHandler,
 		container.PinnedMessageHandler,
+		container.ReactionHandler,
+		container.SyncHandler,
+		container.ThreadHandler,
+		container.PollHandler,
+		container.GroupInviteHandler,
+		container.GroupModerationHandler,
+		container.SessionHandler,
+		container.VerificationHandler,
+		container.TwoFactorHandler,
+		container.PushHandler,
+		container.LocalStorageHandler,
 	)
 
 	// เพิ่ม WebSocket routes แยกต่างหาก (หลังจาก SetupRoutes)
@@ -0,0 +1,40 @@
+// pkg/configs/mailer_config.go
+package configs
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/adapter"
+)
+
+// SetupMailer สร้าง MailerPort ตาม environment (MAIL_DRIVER=smtp หรือ log)
+// ต้องกำหนด MAIL_DRIVER เสมอ เพื่อไม่ให้ production ใช้ log driver โดยไม่ตั้งใจ (อีเมลรีเซ็ตรหัสผ่านจะไม่ถูกส่ง)
+func SetupMailer() (port.MailerPort, error) {
+	driver := os.Getenv("MAIL_DRIVER")
+	if driver == "" {
+		return nil, fmt.Errorf("MAIL_DRIVER is not set (supported: smtp, log)")
+	}
+
+	log.Printf("Setting up mailer with driver: %s", driver)
+
+	switch driver {
+	case "smtp":
+		return adapter.NewSMTPMailer(adapter.SMTPMailerConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+			FromName: os.Getenv("MAIL_FROM_NAME"),
+		})
+
+	case "log":
+		return adapter.NewLogMailer(os.Getenv("MAIL_LOG_FILE")), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s (supported: smtp, log)", driver)
+	}
+}
@@ -0,0 +1,64 @@
+// pkg/configs/push_config.go
+package configs
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/thizplus/gofiber-chat-api/domain/port"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/adapter"
+)
+
+// SetupPushProviders สร้าง push provider ตาม environment
+// provider ที่ไม่ได้ตั้งค่าจะถูกข้าม (ไม่มี provider เลย = ปิด push)
+func SetupPushProviders() ([]port.PushProvider, error) {
+	var providers []port.PushProvider
+
+	if privateKey := os.Getenv("VAPID_PRIVATE_KEY"); privateKey != "" {
+		webPush, err := adapter.NewWebPushProvider(adapter.WebPushConfig{
+			PublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+			PrivateKey: privateKey,
+			Subject:    os.Getenv("VAPID_SUBJECT"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("web push: %w", err)
+		}
+		providers = append(providers, webPush)
+	}
+
+	if credentialsFile := os.Getenv("FCM_CREDENTIALS_FILE"); credentialsFile != "" {
+		fcm, err := adapter.NewFCMProvider(adapter.FCMConfig{
+			CredentialsFile: credentialsFile,
+			ProjectID:       os.Getenv("FCM_PROJECT_ID"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fcm: %w", err)
+		}
+		providers = append(providers, fcm)
+	}
+
+	if keyFile := os.Getenv("APNS_KEY_FILE"); keyFile != "" {
+		production, _ := strconv.ParseBool(os.Getenv("APNS_PRODUCTION"))
+		apns, err := adapter.NewAPNsProvider(adapter.APNsConfig{
+			KeyFile:    keyFile,
+			KeyID:      os.Getenv("APNS_KEY_ID"),
+			TeamID:     os.Getenv("APNS_TEAM_ID"),
+			Topic:      os.Getenv("APNS_TOPIC"),
+			Production: production,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("apns: %w", err)
+		}
+		providers = append(providers, apns)
+	}
+
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	log.Printf("Push providers enabled: %v", names)
+
+	return providers, nil
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/thizplus/gofiber-chat-api/domain/service"
 	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/cloudinary"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/local"
 	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/r2"
+	"github.com/thizplus/gofiber-chat-api/infrastructure/storage/s3"
 )
 
 // SetupStorageService สร้าง FileStorageService ตาม environment
@@ -41,17 +43,40 @@ func SetupStorageService() (service.FileStorageService, error) {
 			Region:          os.Getenv("R2_REGION"),
 		})
 
-	// ในอนาคตอาจเพิ่ม case อื่นๆ เช่น "s3" หรือ "local"
-	// case "s3":
-	//     return s3.NewS3Storage(&s3.S3Config{
-	//         ...
-	//     })
-	// case "local":
-	//     return local.NewLocalStorage(&local.LocalConfig{
-	//         ...
-	//     })
+	case "local":
+		return local.NewLocalStorage(&local.LocalConfig{
+			RootDir:         os.Getenv("LOCAL_STORAGE_ROOT"),
+			BaseURL:         localStorageBaseURL(),
+			SigningKey:      os.Getenv("LOCAL_STORAGE_SIGNING_KEY"),
+			PrivateDownload: os.Getenv("LOCAL_STORAGE_PRIVATE") == "true",
+		})
+
+	case "s3":
+		return s3.NewS3Storage(&s3.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			UsePathStyle:    os.Getenv("S3_USE_PATH_STYLE") == "true",
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+			ACL:             os.Getenv("S3_ACL"),
+			Private:         os.Getenv("S3_PRIVATE") == "true",
+		})
 
 	default:
-		return nil, fmt.Errorf("unsupported storage type: %s (supported: cloudinary, r2)", storageType)
+		return nil, fmt.Errorf("unsupported storage type: %s (supported: cloudinary, r2, s3, local)", storageType)
+	}
+}
+
+// localStorageBaseURL URL ของ route /storage (default: http://localhost:$PORT/storage)
+func localStorageBaseURL() string {
+	if baseURL := os.Getenv("LOCAL_STORAGE_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3000"
 	}
+	return "http://localhost:" + port + "/storage"
 }
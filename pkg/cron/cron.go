@@ -0,0 +1,244 @@
+// pkg/cron/cron.go
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// ฝัง tz database เพื่อให้ LoadLocation ใช้ได้แม้ image ไม่มี /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// Schedule cron expression แบบ 5 ช่อง: minute hour day-of-month month day-of-week
+// รองรับ *, รายการ (1,2), ช่วง (1-5), step (*/15, 1-30/5), ชื่อเดือน/วัน (JAN, MON)
+// และ descriptor @yearly @monthly @weekly @daily @hourly
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// true เมื่อช่องนั้นเป็น * (ใช้ตัดสินว่า day-of-month / day-of-week เป็น OR หรือไม่)
+	domStar bool
+	dowStar bool
+}
+
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 = วันอาทิตย์ เช่นเดียวกับ 0
+	dowField = field{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ค้นหาเวลาถัดไปได้ไม่เกินกี่ปี (กัน loop ไม่รู้จบ เช่น 0 0 30 2 *)
+const maxSearchYears = 5
+
+// Parse แปลง cron expression เป็น Schedule
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+
+	// 7 และ 0 คือวันอาทิตย์
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+		s.dow &^= 1 << 7
+	}
+
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return s, nil
+}
+
+// Next คืนเวลาถัดไปที่ตรงกับ schedule หลัง after (ไม่รวม after)
+// เวลาถูกคำนวณตาม location ของ after คืนค่า zero time ถ้าไม่พบภายใน 5 ปี
+//
+// การค้นหาทำบนเวลาแบบ wall clock จึงไม่ย้อนกลับไปเวลาที่ผ่านมาแล้ว:
+//   - ช่วงเวลาที่ซ้ำเมื่อ DST สิ้นสุด (fall back) ทำงานเพียงครั้งเดียว
+//   - เวลาที่ไม่มีอยู่จริงเมื่อ DST เริ่ม (spring forward) ถูกเลื่อนไปหลังช่วงที่ข้าม เช่น 02:30 → 03:30
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	wall := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, time.UTC).Add(time.Minute)
+	limit := wall.AddDate(maxSearchYears, 0, 0)
+
+	for wall.Before(limit) {
+		if s.month&(1<<uint(wall.Month())) == 0 {
+			wall = time.Date(wall.Year(), wall.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(wall) {
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(wall.Hour())) == 0 {
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.minute&(1<<uint(wall.Minute())) == 0 {
+			wall = wall.Add(time.Minute)
+			continue
+		}
+
+		t := inLocation(wall, loc)
+		if !t.After(after) {
+			wall = wall.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// inLocation แปลงเวลา wall clock (เก็บเป็น UTC) เป็นเวลาใน loc
+// เวลาที่ซ้ำ (fall back) ได้ช่วงแรก เวลาที่ไม่มีอยู่จริง (spring forward) ตีความด้วย offset ก่อนช่วงที่ข้าม
+// จึงเลื่อนไปหลังช่วงนั้นตามขนาดของช่วง
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	guess := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	_, offsetBefore := guess.Add(-12 * time.Hour).Zone()
+	_, offsetAfter := guess.Add(12 * time.Hour).Zone()
+
+	var result time.Time
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if t.Hour() == wall.Hour() && t.Minute() == wall.Minute() && (result.IsZero() || t.Before(result)) {
+			result = t
+		}
+	}
+	if result.IsZero() {
+		return wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	}
+	return result
+}
+
+// dayMatches ตรวจ day-of-month / day-of-week
+// ถ้าระบุทั้งสองช่อง ใช้ OR ตามพฤติกรรมของ cron มาตรฐาน
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField แปลงหนึ่งช่องของ cron expression เป็น bitset
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("empty value in %q", expr)
+		}
+
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		var lo, hi int
+		switch {
+		case part == "*" || part == "?":
+			lo, hi = f.min, f.max
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(part)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/10" หมายถึงเริ่มที่ 5 ทุก 10
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value แปลงตัวเลขหรือชื่อ (JAN, MON) และตรวจช่วง
+func (f field) value(s string) (int, error) {
+	if f.names != nil {
+		if v, ok := f.names[strings.ToLower(s)]; ok {
+			return v, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,188 @@
+// pkg/cron/cron_test.go
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestNext(t *testing.T) {
+	bangkok := mustLoad(t, "Asia/Bangkok")
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2025, 6, 1, 10, 0, 30, 0, bangkok), time.Date(2025, 6, 1, 10, 1, 0, 0, bangkok)},
+		{"step minutes", "*/15 * * * *", time.Date(2025, 6, 1, 10, 15, 0, 0, bangkok), time.Date(2025, 6, 1, 10, 30, 0, 0, bangkok)},
+		{"daily rolls to next day", "0 9 * * *", time.Date(2025, 6, 1, 9, 0, 0, 0, bangkok), time.Date(2025, 6, 2, 9, 0, 0, 0, bangkok)},
+		{"month names", "0 0 1 JAN *", time.Date(2025, 6, 1, 0, 0, 0, 0, bangkok), time.Date(2026, 1, 1, 0, 0, 0, 0, bangkok)},
+		{"weekday range", "0 8 * * MON-FRI", time.Date(2025, 6, 6, 8, 0, 0, 0, bangkok), time.Date(2025, 6, 9, 8, 0, 0, 0, bangkok)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2025, 6, 2, 0, 0, 0, 0, bangkok), time.Date(2025, 6, 8, 0, 0, 0, 0, bangkok)},
+		{"descriptor", "@monthly", time.Date(2025, 6, 15, 0, 0, 0, 0, bangkok), time.Date(2025, 7, 1, 0, 0, 0, 0, bangkok)},
+		{"leap day", "0 0 29 2 *", time.Date(2025, 3, 1, 0, 0, 0, 0, bangkok), time.Date(2028, 2, 29, 0, 0, 0, 0, bangkok)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext_DayOfMonthOrDayOfWeek(t *testing.T) {
+	utc := time.UTC
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		// ระบุทั้งสองช่อง: วันที่ 10 หรือวันศุกร์ (มิ.ย. 2025: ศุกร์ที่ 6, 13)
+		{"both set, weekday first", "0 0 10 * 5", time.Date(2025, 6, 1, 0, 0, 0, 0, utc), time.Date(2025, 6, 6, 0, 0, 0, 0, utc)},
+		{"both set, day of month first", "0 0 10 * 5", time.Date(2025, 6, 7, 0, 0, 0, 0, utc), time.Date(2025, 6, 10, 0, 0, 0, 0, utc)},
+		{"both set, weekday after day of month", "0 0 10 * 5", time.Date(2025, 6, 10, 0, 0, 0, 0, utc), time.Date(2025, 6, 13, 0, 0, 0, 0, utc)},
+		// ช่องใดช่องหนึ่งเป็น * : ใช้ AND
+		{"day of week star", "0 0 10 * *", time.Date(2025, 6, 1, 0, 0, 0, 0, utc), time.Date(2025, 6, 10, 0, 0, 0, 0, utc)},
+		{"day of month star", "0 0 * * 5", time.Date(2025, 6, 7, 0, 0, 0, 0, utc), time.Date(2025, 6, 13, 0, 0, 0, 0, utc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext_DSTFallBackFiresOnce(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+
+	// 2 พ.ย. 2025 เวลา 01:00-02:00 เกิดซ้ำสองครั้ง (EDT แล้ว EST)
+	s, err := Parse("30 1 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := s.Next(time.Date(2025, 11, 2, 0, 0, 0, 0, ny))
+	if want := time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC); !first.Equal(want) {
+		t.Fatalf("first = %s, want %s (01:30 EDT)", first, want)
+	}
+
+	second := s.Next(first)
+	if want := time.Date(2025, 11, 3, 6, 30, 0, 0, time.UTC); !second.Equal(want) {
+		t.Fatalf("second = %s, want %s (next day 01:30 EST)", second, want)
+	}
+}
+
+func TestNext_DSTFallBackMinuteSteps(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+
+	s, err := Parse("*/30 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ชั่วโมงที่ซ้ำไม่ถูกนับซ้ำ: จาก 01:30 EDT ไป 02:00 EST
+	after := time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC).In(ny) // 01:30 EDT
+	got := s.Next(after)
+	if want := time.Date(2025, 11, 2, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("Next = %s, want %s (02:00 EST)", got.In(ny), want.In(ny))
+	}
+
+	// ผลลัพธ์ต้องเพิ่มขึ้นเสมอและไม่มีเวลา wall clock ซ้ำ
+	seen := make(map[string]bool)
+	cur := time.Date(2025, 11, 1, 23, 0, 0, 0, ny)
+	for i := 0; i < 12; i++ {
+		next := s.Next(cur)
+		if !next.After(cur) {
+			t.Fatalf("Next(%s) = %s is not after input", cur, next)
+		}
+		key := next.In(ny).Format("2006-01-02 15:04")
+		if seen[key] {
+			t.Fatalf("wall clock time %s returned twice", key)
+		}
+		seen[key] = true
+		cur = next
+	}
+}
+
+func TestNext_DSTSpringForward(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+
+	// 9 มี.ค. 2025 เวลา 02:00-03:00 ไม่มีอยู่จริง
+	s, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Next(time.Date(2025, 3, 8, 2, 30, 0, 0, ny))
+	if want := time.Date(2025, 3, 9, 3, 30, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("Next = %s, want %s (shifted past the gap)", got, want)
+	}
+
+	got = s.Next(got)
+	if want := time.Date(2025, 3, 10, 2, 30, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestNext_Impossible(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("expected zero time, got %s", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"* * * FOO *",
+		"@every 5m",
+	}
+
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", spec)
+		}
+	}
+}
@@ -2,7 +2,10 @@
 package di
 
 import (
+	"errors"
 	"log"
+	"os"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/thizplus/gofiber-chat-api/application/serviceimpl"
@@ -36,10 +39,27 @@ type Container struct {
 	ScheduledMessageRepo       repository.ScheduledMessageRepository
 	NoteRepo                   repository.NoteRepository
 	PinnedMessageRepo          repository.PinnedMessageRepository
+	MessageReactionRepo        repository.MessageReactionRepository
+	ThreadSubscriptionRepo     repository.ThreadSubscriptionRepository
+	PollRepo                   repository.PollRepository
+	GroupInviteRepo            repository.GroupInviteRepository
+	GroupBanRepo               repository.GroupBanRepository
+	UserSessionRepo            repository.UserSessionRepository
+	VerificationTokenRepo      repository.VerificationTokenRepository
+	TwoFactorRepo              repository.TwoFactorRepository
+	PushDeviceRepo             repository.PushDeviceRepository
 
 	// WebSocket Components
-	WebSocketHub  *websocket.Hub
-	WebSocketPort port.WebSocketPort
+	WebSocketHub       *websocket.Hub
+	WebSocketPort      port.WebSocketPort
+	WebSocketBackplane *adapter.RedisBackplane
+	EventLog           port.EventLogPort
+
+	// Mail
+	Mailer port.MailerPort
+
+	// Push
+	PushProviders []port.PushProvider
 
 	// Services
 	StorageService                service.FileStorageService
@@ -57,6 +77,18 @@ type Container struct {
 	ScheduledMessageService       service.ScheduledMessageService
 	NoteService                   service.NoteService
 	PinnedMessageService          service.PinnedMessageService
+	ReactionService               service.ReactionService
+	SyncService                   service.SyncService
+	ThreadService                 service.ThreadService
+	PollService                   service.PollService
+	GroupInviteService            service.GroupInviteService
+	GroupModerationService        service.GroupModerationService
+	SessionService                service.SessionService
+	VerificationService           service.VerificationService
+	TwoFactorService              service.TwoFactorService
+	PushService                   service.PushService
+	ImageService                  service.ImageService
+	MediaAccessService            service.MediaAccessService
 
 	// Handlers
 	AuthHandler                   *handler.AuthHandler
@@ -74,18 +106,33 @@ type Container struct {
 	ScheduledMessageHandler       *handler.ScheduledMessageHandler
 	NoteHandler                   *handler.NoteHandler
 	PinnedMessageHandler          *handler.PinnedMessageHandler
+	ReactionHandler               *handler.ReactionHandler
+	SyncHandler                   *handler.SyncHandler
+	ThreadHandler                 *handler.ThreadHandler
+	PollHandler                   *handler.PollHandler
+	GroupInviteHandler            *handler.GroupInviteHandler
+	GroupModerationHandler        *handler.GroupModerationHandler
+	SessionHandler                *handler.SessionHandler
+	VerificationHandler           *handler.VerificationHandler
+	TwoFactorHandler              *handler.TwoFactorHandler
+	PushHandler                   *handler.PushHandler
+	LocalStorageHandler           *handler.LocalStorageHandler
 
 	// Scheduler & Background Jobs
 	RedisClient                    *redis.Client
 	FileCleanupScheduler           *scheduler.FileCleanupScheduler
+	DisappearingMessageSweeper     *scheduler.DisappearingMessageSweeper
+	PollCloser                     *scheduler.PollCloser
 	ScheduledMessageProcessor      *scheduler.ScheduledMessageProcessor
 }
 
 // NewContainer สร้าง container ใหม่พร้อมกับ dependencies ทั้งหมด
-func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisClient *redis.Client) (*Container, error) {
+func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisClient *redis.Client, mailer port.MailerPort, pushProviders []port.PushProvider) (*Container, error) {
 	container := &Container{
 		StorageService: storageService,
 		RedisClient:    redisClient,
+		Mailer:         mailer,
+		PushProviders:  pushProviders,
 	}
 
 	// สร้าง repositories
@@ -104,16 +151,39 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 	container.ScheduledMessageRepo = postgres.NewScheduledMessageRepository(db)
 	container.NoteRepo = postgres.NewNoteRepository(db)
 	container.PinnedMessageRepo = postgres.NewPinnedMessageRepository(db)
+	container.MessageReactionRepo = postgres.NewMessageReactionRepository(db)
+	container.ThreadSubscriptionRepo = postgres.NewThreadSubscriptionRepository(db)
+	container.PollRepo = postgres.NewPollRepository(db)
+	container.GroupInviteRepo = postgres.NewGroupInviteRepository(db)
+	container.GroupBanRepo = postgres.NewGroupBanRepository(db)
+	container.UserSessionRepo = postgres.NewUserSessionRepository(db)
+	container.VerificationTokenRepo = postgres.NewVerificationTokenRepository(db)
+	container.TwoFactorRepo = postgres.NewTwoFactorRepository(db)
+	container.PushDeviceRepo = postgres.NewPushDeviceRepository(db)
 
 	log.Println("เชื่อมต่อกับบริการจัดเก็บไฟล์สำเร็จ")
 
-	// สร้าง basic services
-	container.AuthService = serviceimpl.NewAuthService(
-		container.UserRepo,
-		container.RefreshTokenRepo,
-		container.TokenBlacklistRepo,
+	// สร้าง MediaAccessService (โหมด private media ต้องใช้ storage ที่เก็บไฟล์แบบ private และออก presigned download URL ได้)
+	privateMedia := os.Getenv("STORAGE_PRIVATE_MEDIA") == "true"
+	if privateMedia {
+		if err := checkPrivateMediaStorage(storageService); err != nil {
+			return nil, err
+		}
+	}
+	mediaURLTTL := serviceimpl.DefaultMediaURLTTL
+	if v := os.Getenv("MEDIA_URL_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			mediaURLTTL = d
+		}
+	}
+	container.MediaAccessService = serviceimpl.NewMediaAccessService(
+		container.StorageService,
+		container.ConversationRepo,
+		privateMedia,
+		mediaURLTTL,
 	)
 
+	// สร้าง basic services
 	container.UserService = serviceimpl.NewUserService(container.UserRepo)
 	container.UserFriendshipService = serviceimpl.NewUserFriendshipService(
 		container.UserFriendshipRepo,
@@ -124,11 +194,28 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 		container.UserRepo,
 		container.MessageRepo,
 		container.MessageMentionRepo,
+		container.MessageReactionRepo,
+		container.PollRepo,
+		container.MediaAccessService,
 	)
 	container.ConversationMemberService = serviceimpl.NewConversationMemberService(
 		container.ConversationRepo,
 		container.UserRepo,
 		container.MessageRepo,
+		container.GroupBanRepo,
+	)
+	container.GroupInviteService = serviceimpl.NewGroupInviteService(
+		container.GroupInviteRepo,
+		container.ConversationRepo,
+		container.UserRepo,
+		container.MessageRepo,
+		container.GroupBanRepo,
+	)
+	container.GroupModerationService = serviceimpl.NewGroupModerationService(
+		container.GroupBanRepo,
+		container.ConversationRepo,
+		container.UserRepo,
+		container.MessageRepo,
 	)
 
 	container.MessageReadService = serviceimpl.NewMessageReadService(
@@ -138,6 +225,12 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 	)
 
 
+	// image pipeline ทำงานได้เมื่อ storage อ่าน/เขียนไฟล์ตรงได้ (R2, S3, local)
+	container.ImageService = serviceimpl.NewImageService(
+		container.StorageService,
+		container.FileUploadRepo,
+	)
+
 	container.StickerService = serviceimpl.NewStickerService(
 		container.StickerRepo,
 		container.StorageService,
@@ -165,15 +258,72 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 		container.UserRepo, // 🆕 เพิ่ม UserRepo สำหรับ typing user info
 	)
 
+	// สร้าง Redis backplane เพื่อให้ broadcast ไปถึง client บนทุก instance
+	if redisClient != nil {
+		container.WebSocketBackplane = adapter.NewRedisBackplane(redisClient, container.WebSocketHub)
+		container.WebSocketHub.SetBackplane(container.WebSocketBackplane)
+
+		// event log สำหรับ sequence ของผู้ใช้และการ sync หลัง reconnect
+		container.EventLog = adapter.NewRedisEventLog(redisClient)
+	}
+
+	// สร้าง SyncService และตั้งค่าใน Hub เพื่อกำหนด sequence ให้ทุก event
+	container.SyncService = serviceimpl.NewSyncService(
+		container.EventLog,
+		container.ConversationRepo,
+	)
+	container.WebSocketHub.SetSyncService(container.SyncService)
+
 	// สร้าง WebSocketAdapter
 	container.WebSocketPort = adapter.NewWebSocketAdapter(container.WebSocketHub)
 
+	// สร้าง SessionService (หลังจาก WebSocketPort เพื่อปิดการเชื่อมต่อของ session ที่ถูกเพิกถอน)
+	container.SessionService = serviceimpl.NewSessionService(
+		container.UserSessionRepo,
+		container.RefreshTokenRepo,
+		container.WebSocketPort,
+	)
+
+	// สร้าง VerificationService (รีเซ็ตรหัสผ่านจะเพิกถอนทุก session ผ่าน SessionService)
+	container.VerificationService = serviceimpl.NewVerificationService(
+		container.UserRepo,
+		container.VerificationTokenRepo,
+		container.RefreshTokenRepo,
+		container.SessionService,
+		container.Mailer,
+		os.Getenv("FRONTEND_URL"),
+	)
+
+	// สร้าง TwoFactorService (ต้องสร้างก่อน AuthService เพื่อตรวจ 2FA ตอนเข้าสู่ระบบ)
+	container.TwoFactorService = serviceimpl.NewTwoFactorService(
+		container.TwoFactorRepo,
+		container.UserRepo,
+		os.Getenv("TOTP_ISSUER"),
+	)
+
 	// สร้าง PinnedMessageService (หลังจาก WebSocketPort เพื่อให้ส่ง realtime events ได้)
 	container.PinnedMessageService = serviceimpl.NewPinnedMessageService(
 		container.PinnedMessageRepo,
 		container.MessageRepo,
 		container.ConversationRepo,
 		container.WebSocketPort,
+		container.MediaAccessService,
+	)
+
+	// สร้าง PushService (หลังจาก WebSocketPort และ SessionService เพื่อตรวจว่าผู้รับออฟไลน์และ session ยังใช้งานได้)
+	pushCollapseWindow := 5 * time.Second
+	if v := os.Getenv("PUSH_COLLAPSE_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			pushCollapseWindow = d
+		}
+	}
+	container.PushService = serviceimpl.NewPushService(
+		container.PushDeviceRepo,
+		container.WebSocketPort,
+		container.PresenceService,
+		container.SessionService,
+		container.PushProviders,
+		pushCollapseWindow,
 	)
 
 	// สร้าง NotificationService
@@ -182,11 +332,42 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 		container.UserRepo,
 		container.MessageRepo,
 		container.ConversationRepo,
+		container.PushService,
+		container.MediaAccessService,
 	)
 
 	// ตั้งค่า NotificationService ใน Hub
 	container.WebSocketHub.SetNotificationService(container.NotificationService)
 
+	// สร้าง AuthService (ต้องสร้างหลัง SessionService และ NotificationService เพื่อจัดการ refresh token ที่ถูกใช้ซ้ำ)
+	container.AuthService = serviceimpl.NewAuthService(
+		container.UserRepo,
+		container.RefreshTokenRepo,
+		container.TokenBlacklistRepo,
+		container.UserSessionRepo,
+		container.SessionService,
+		container.NotificationService,
+		container.TwoFactorService,
+	)
+
+	// สร้าง ReactionService (ต้องสร้างหลัง NotificationService เพื่อส่ง message.reaction)
+	container.ReactionService = serviceimpl.NewReactionService(
+		container.MessageReactionRepo,
+		container.MessageRepo,
+		container.ConversationRepo,
+		container.NotificationService,
+	)
+
+	// สร้าง ThreadService (ต้องสร้างหลัง NotificationService เพื่อส่ง thread.reply)
+	container.ThreadService = serviceimpl.NewThreadService(
+		container.MessageRepo,
+		container.ConversationRepo,
+		container.ThreadSubscriptionRepo,
+		container.ConversationService,
+		container.NotificationService,
+		container.MediaAccessService,
+	)
+
 	// สร้าง GroupActivityService (ต้องสร้างหลัง NotificationService)
 	container.GroupActivityService = serviceimpl.NewGroupActivityService(
 		container.GroupActivityRepo,
@@ -202,6 +383,18 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 		container.UserRepo,
 		container.NotificationService,
 		container.MessageMentionRepo,
+		container.ImageService,
+		container.MediaAccessService,
+	)
+
+	// สร้าง PollService (ต้องสร้างหลัง MessageService เพื่อสร้างข้อความโพล)
+	container.PollService = serviceimpl.NewPollService(
+		container.PollRepo,
+		container.MessageRepo,
+		container.ConversationRepo,
+		container.MessageService,
+		container.ConversationService,
+		container.NotificationService,
 	)
 
 	// สร้าง ScheduledMessageService (ต้องสร้างหลัง MessageService และ NotificationService)
@@ -213,13 +406,13 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 	)
 
 	// สร้าง handlers
-	container.AuthHandler = handler.NewAuthHandler(container.AuthService)
+	container.AuthHandler = handler.NewAuthHandler(container.AuthService, container.VerificationService)
 	container.UserHandler = handler.NewUserHandler(container.UserService, container.AuthService, container.StorageService)
-	container.FileHandler = handler.NewFileHandler(container.StorageService, container.FileUploadRepo)
+	container.FileHandler = handler.NewFileHandler(container.StorageService, container.FileUploadRepo, container.ImageService)
 	container.UserFriendshipHandler = handler.NewUserFriendshipHandler(container.UserFriendshipService, container.UserService, container.ConversationMemberService, container.NotificationService)
 	container.ConversationHandler = handler.NewConversationHandler(container.ConversationService, container.NotificationService, container.MessageReadService, container.GroupActivityService, container.ConversationRepo, container.MessageService)
 	container.ConversationMemberHandler = handler.NewConversationMemberHandler(container.ConversationMemberService, container.NotificationService, container.GroupActivityService)
-	container.MessageHandler = handler.NewMessageHandler(container.MessageService, container.NotificationService, container.ConversationMemberService, container.ConversationRepo, container.UserFriendshipService)
+	container.MessageHandler = handler.NewMessageHandler(container.MessageService, container.NotificationService, container.ConversationMemberService, container.ConversationRepo, container.UserFriendshipService, container.MediaAccessService, container.ConversationService)
 	container.MessageReadHandler = handler.NewMessageReadHandler(container.MessageReadService, container.NotificationService, container.MessageRepo)
 	container.MentionHandler = handler.NewMentionHandler(container.MessageMentionRepo)
 	container.StickerHandler = handler.NewStickerHandler(container.StickerService)
@@ -228,6 +421,20 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 	container.ScheduledMessageHandler = handler.NewScheduledMessageHandler(container.ScheduledMessageService)
 	container.NoteHandler = handler.NewNoteHandler(container.NoteService, container.WebSocketPort)
 	container.PinnedMessageHandler = handler.NewPinnedMessageHandler(container.PinnedMessageService)
+	container.ReactionHandler = handler.NewReactionHandler(container.ReactionService)
+	container.SyncHandler = handler.NewSyncHandler(container.SyncService)
+	container.ThreadHandler = handler.NewThreadHandler(container.ThreadService)
+	container.PollHandler = handler.NewPollHandler(container.PollService)
+	container.GroupInviteHandler = handler.NewGroupInviteHandler(container.GroupInviteService, container.NotificationService, container.GroupActivityService)
+	container.GroupModerationHandler = handler.NewGroupModerationHandler(container.GroupModerationService, container.NotificationService, container.GroupActivityService)
+	container.SessionHandler = handler.NewSessionHandler(container.SessionService)
+	container.VerificationHandler = handler.NewVerificationHandler(container.VerificationService)
+	container.TwoFactorHandler = handler.NewTwoFactorHandler(container.TwoFactorService)
+	container.PushHandler = handler.NewPushHandler(container.PushService)
+	if fileServer, ok := container.StorageService.(service.SignedFileServer); ok {
+		// local storage: server เป็นผู้รับ/ส่งไฟล์เองผ่าน /storage
+		container.LocalStorageHandler = handler.NewLocalStorageHandler(fileServer)
+	}
 
 	// สร้าง background jobs
 	container.FileCleanupScheduler = scheduler.NewFileCleanupScheduler(
@@ -235,6 +442,14 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 		container.StorageService,
 	)
 
+	container.DisappearingMessageSweeper = scheduler.NewDisappearingMessageSweeper(
+		container.MessageRepo,
+		container.MessageService,
+		container.StorageService,
+	)
+
+	container.PollCloser = scheduler.NewPollCloser(container.PollService)
+
 	container.ScheduledMessageProcessor = scheduler.NewScheduledMessageProcessor(
 		container.ScheduledMessageService,
 	)
@@ -245,3 +460,13 @@ func NewContainer(db *gorm.DB, storageService service.FileStorageService, redisC
 
 	return container, nil
 }
+
+// checkPrivateMediaStorage ไม่ยอมเริ่ม server ถ้าเปิด STORAGE_PRIVATE_MEDIA แต่ไฟล์ยังเปิด public อยู่
+// (r2, cloudinary หรือ s3/local ที่ไม่ได้ตั้ง S3_PRIVATE / LOCAL_STORAGE_PRIVATE) เพราะ URL ถาวรจะยังเปิดไฟล์ได้
+func checkPrivateMediaStorage(storageService service.FileStorageService) error {
+	private, ok := storageService.(service.PrivateStorage)
+	if !ok || !private.KeepsFilesPrivate() {
+		return errors.New("STORAGE_PRIVATE_MEDIA requires STORAGE_TYPE=s3 with S3_PRIVATE=true or STORAGE_TYPE=local with LOCAL_STORAGE_PRIVATE=true")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// pkg/imageproc/imageproc.go
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // ลงทะเบียน gif decoder ให้ image.Decode
+	"image/jpeg"
+	"image/png"
+)
+
+// pipeline ประมวลผลรูปฝั่ง server ด้วย stdlib ล้วน (jpeg, png, gif):
+// ลบ metadata ที่อาจมีพิกัด GPS, หมุนภาพตาม EXIF orientation, ย่อเป็น thumbnail/medium และคำนวณ blurhash
+
+// ErrUnsupportedFormat รูปแบบไฟล์ที่ decode ไม่ได้ (เช่น webp, heic)
+var ErrUnsupportedFormat = errors.New("imageproc: unsupported image format")
+
+// ErrImageTooLarge จำนวน pixel เกินที่กำหนด (กัน decompression bomb)
+var ErrImageTooLarge = errors.New("imageproc: image dimensions exceed limit")
+
+// Options ขนาดของ variant และคุณภาพการ encode
+type Options struct {
+	ThumbnailSize int // ด้านยาวสุดของ thumbnail (pixel)
+	MediumSize    int // ด้านยาวสุดของ medium (pixel)
+	JPEGQuality   int
+	MaxPixels     int // จำนวน pixel สูงสุดที่ยอม decode
+}
+
+// DefaultOptions ค่าที่ใช้กับรูปในแชท
+func DefaultOptions() Options {
+	return Options{
+		ThumbnailSize: 320,
+		MediumSize:    1280,
+		JPEGQuality:   82,
+		MaxPixels:     50_000_000,
+	}
+}
+
+// Variant ไฟล์รูปที่ encode แล้ว
+type Variant struct {
+	Data        []byte
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string // นามสกุลไฟล์รวมจุด เช่น ".jpg"
+}
+
+// Result ผลลัพธ์ของ Process
+type Result struct {
+	Width    int // ขนาดหลังหมุนตาม orientation แล้ว
+	Height   int
+	Format   string // jpeg, png, gif
+	BlurHash string
+
+	// Original ไฟล์ต้นฉบับที่ลบ metadata และหมุนแล้ว (nil = ไฟล์เดิมไม่มีอะไรต้องแก้)
+	Original  *Variant
+	Thumbnail *Variant
+	// Medium nil เมื่อรูปเล็กกว่า MediumSize อยู่แล้ว
+	Medium *Variant
+}
+
+// Process ประมวลผลรูปจาก bytes ของไฟล์ต้นฉบับ
+func Process(data []byte, opts Options) (*Result, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, fmt.Errorf("imageproc: failed to read image header: %w", err)
+	}
+	if opts.MaxPixels > 0 && cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imageproc: failed to decode image: %w", err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = JPEGOrientation(data)
+	}
+
+	img := Orient(toRGBA(decoded), orientation)
+	bounds := img.Bounds()
+	componentsX, componentsY := blurHashComponents(bounds.Dx(), bounds.Dy())
+
+	result := &Result{
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+		Format:   format,
+		BlurHash: BlurHash(Fit(img, 64), componentsX, componentsY),
+	}
+
+	// ต้นฉบับ: ถ้าไม่ต้องหมุนให้ตัด metadata ทิ้งแบบ lossless (คง ICC profile และคุณภาพเดิม)
+	// ต้องหมุนหรือโครงสร้างไฟล์แปลกจนตัดไม่ได้ ค่อย encode ใหม่ซึ่งไม่มี metadata ติดไปเลย
+	reencode := orientation != 1
+	if !reencode {
+		stripped, changed, err := StripMetadata(data, format)
+		if err != nil {
+			reencode = true
+		} else if changed {
+			result.Original = &Variant{
+				Data:        stripped,
+				Width:       result.Width,
+				Height:      result.Height,
+				ContentType: "image/" + format,
+				Ext:         formatExt(format),
+			}
+		}
+	}
+	if reencode {
+		if result.Original, err = encode(img, format, 92); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Thumbnail, err = encode(Fit(img, opts.ThumbnailSize), "", opts.JPEGQuality); err != nil {
+		return nil, err
+	}
+
+	if max(result.Width, result.Height) > opts.MediumSize {
+		if result.Medium, err = encode(Fit(img, opts.MediumSize), "", opts.JPEGQuality); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// encode เข้ารหัสรูปตาม format ("" = jpeg ถ้ารูปทึบ, png ถ้ามีส่วนโปร่งใส)
+func encode(img *image.RGBA, format string, quality int) (*Variant, error) {
+	if format == "" || format == "gif" {
+		format = "jpeg"
+		if !img.Opaque() {
+			format = "png"
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imageproc: failed to encode %s: %w", format, err)
+	}
+
+	bounds := img.Bounds()
+	return &Variant{
+		Data:        buf.Bytes(),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		ContentType: "image/" + format,
+		Ext:         formatExt(format),
+	}, nil
+}
+
+// formatExt นามสกุลไฟล์ของ format
+func formatExt(format string) string {
+	switch format {
+	case "jpeg":
+		return ".jpg"
+	default:
+		return "." + format
+	}
+}
+
+// blurHashComponents จำนวน component ตามสัดส่วนภาพ (ด้านยาวได้ 4 ด้านสั้นได้ 3)
+func blurHashComponents(width, height int) (int, int) {
+	if height > width {
+		return 3, 4
+	}
+	return 4, 3
+}
@@ -0,0 +1,238 @@
+package imageproc_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/thizplus/gofiber-chat-api/pkg/imageproc"
+)
+
+// exifSegment APP1 ที่มี orientation และ pointer ไป GPS IFD พร้อมละติจูดปลอม
+func exifSegment(orientation uint16) []byte {
+	tiff := new(bytes.Buffer)
+	le := binary.LittleEndian
+	tiff.WriteString("II")
+	binary.Write(tiff, le, uint16(42))
+	binary.Write(tiff, le, uint32(8))
+
+	// IFD0: orientation + GPSInfo
+	binary.Write(tiff, le, uint16(2))
+	binary.Write(tiff, le, []uint16{0x0112, 3})
+	binary.Write(tiff, le, uint32(1))
+	binary.Write(tiff, le, []uint16{orientation, 0})
+	binary.Write(tiff, le, []uint16{0x8825, 4})
+	binary.Write(tiff, le, uint32(1))
+	binary.Write(tiff, le, uint32(8+2+24+4))
+	binary.Write(tiff, le, uint32(0))
+
+	// GPS IFD: GPSLatitudeRef = "N"
+	binary.Write(tiff, le, uint16(1))
+	binary.Write(tiff, le, []uint16{0x0001, 2})
+	binary.Write(tiff, le, uint32(2))
+	tiff.WriteString("N\x00\x00\x00")
+	binary.Write(tiff, le, uint32(0))
+	tiff.WriteString("GPS-SECRET-LOCATION")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// withSegment แทรก segment ต่อจาก SOI
+func withSegment(jpegData, segment []byte) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func splitImage(w, h int, left, right color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.SetRGBA(x, y, left)
+			} else {
+				img.SetRGBA(x, y, right)
+			}
+		}
+	}
+	return img
+}
+
+func TestProcess_OrientsAndStripsEXIF(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, splitImage(64, 32, red, blue), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	data := withSegment(buf.Bytes(), exifSegment(6))
+
+	if got := imageproc.JPEGOrientation(data); got != 6 {
+		t.Fatalf("orientation = %d, want 6", got)
+	}
+
+	result, err := imageproc.Process(data, imageproc.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// หมุนตามเข็ม 90 องศา: 64x32 -> 32x64 โดยครึ่งซ้าย (แดง) ขึ้นไปอยู่ด้านบน
+	if result.Width != 32 || result.Height != 64 {
+		t.Fatalf("dimensions = %dx%d, want 32x64", result.Width, result.Height)
+	}
+	if result.Original == nil {
+		t.Fatal("expected rewritten original")
+	}
+	if bytes.Contains(result.Original.Data, []byte("GPS-SECRET-LOCATION")) || bytes.Contains(result.Original.Data, []byte("Exif")) {
+		t.Fatal("original still contains EXIF data")
+	}
+
+	decoded, err := jpeg.Decode(bytes.NewReader(result.Original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := decoded.Bounds(); b.Dx() != 32 || b.Dy() != 64 {
+		t.Fatalf("decoded original = %dx%d, want 32x64", b.Dx(), b.Dy())
+	}
+	if r, _, bl, _ := decoded.At(16, 8).RGBA(); r>>8 < 200 || bl>>8 > 60 {
+		t.Fatalf("top should be red, got r=%d b=%d", r>>8, bl>>8)
+	}
+	if r, _, bl, _ := decoded.At(16, 56).RGBA(); bl>>8 < 200 || r>>8 > 60 {
+		t.Fatalf("bottom should be blue, got r=%d b=%d", r>>8, bl>>8)
+	}
+
+	if result.Thumbnail == nil || result.Thumbnail.Width != 32 || result.Thumbnail.Height != 64 {
+		t.Fatalf("small image should not be upscaled, got %+v", result.Thumbnail)
+	}
+	if result.Medium != nil {
+		t.Fatal("medium variant should be skipped for small images")
+	}
+	if len(result.BlurHash) != 28 {
+		t.Fatalf("blurhash %q length = %d, want 28 for 3x4 components", result.BlurHash, len(result.BlurHash))
+	}
+}
+
+// pngChunk สร้าง chunk พร้อม CRC
+func pngChunk(chunkType string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk, uint32(len(payload)))
+	copy(chunk[4:], chunkType)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestProcess_ResizesAndStripsPNGText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, splitImage(2000, 1000, color.RGBA{0, 128, 0, 255}, color.RGBA{255, 255, 255, 255})); err != nil {
+		t.Fatal(err)
+	}
+
+	// แทรก tEXt ต่อจาก IHDR (signature 8 + IHDR 25 bytes)
+	data := append([]byte{}, buf.Bytes()[:33]...)
+	data = append(data, pngChunk("tEXt", []byte("Location\x0013.7563,100.5018"))...)
+	data = append(data, buf.Bytes()[33:]...)
+
+	result, err := imageproc.Process(data, imageproc.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Width != 2000 || result.Height != 1000 || result.Format != "png" {
+		t.Fatalf("got %dx%d %s", result.Width, result.Height, result.Format)
+	}
+	if result.Original == nil || !bytes.Equal(result.Original.Data, buf.Bytes()) {
+		t.Fatal("text chunk should be removed losslessly")
+	}
+	if result.Thumbnail.Width != 320 || result.Thumbnail.Height != 160 || result.Thumbnail.ContentType != "image/jpeg" {
+		t.Fatalf("thumbnail = %dx%d %s", result.Thumbnail.Width, result.Thumbnail.Height, result.Thumbnail.ContentType)
+	}
+	if result.Medium == nil || result.Medium.Width != 1280 || result.Medium.Height != 640 {
+		t.Fatalf("medium = %+v", result.Medium)
+	}
+
+	// ไฟล์ที่ไม่มี metadata ไม่ต้องเขียนใหม่
+	clean, err := imageproc.Process(buf.Bytes(), imageproc.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clean.Original != nil {
+		t.Fatal("clean png should not be rewritten")
+	}
+}
+
+func TestProcess_RejectsUnsupportedFormat(t *testing.T) {
+	if _, err := imageproc.Process([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), imageproc.DefaultOptions()); err != imageproc.ErrUnsupportedFormat {
+		t.Fatalf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+// webpChunk สร้าง RIFF chunk (ขนาดคี่เติม padding)
+func webpChunk(fourCC string, payload []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestStripMetadata_WebPRemovesEXIFAndXMP(t *testing.T) {
+	vp8x := webpChunk("VP8X", []byte{0x0C, 0, 0, 0, 9, 0, 0, 9, 0, 0}) // flags: EXIF | XMP
+	bitstream := webpChunk("VP8L", []byte{0x2F, 1, 2, 3, 4})
+	data := webpFile(vp8x, bitstream, webpChunk("EXIF", exifSegment(1)[10:]), webpChunk("XMP ", []byte("<x:xmpmeta/>")))
+
+	if got := imageproc.DetectContainer(data); got != "webp" {
+		t.Fatalf("DetectContainer = %q, want webp", got)
+	}
+
+	stripped, changed, err := imageproc.StripMetadata(data, "webp")
+	if err != nil || !changed {
+		t.Fatalf("StripMetadata = changed %v, err %v", changed, err)
+	}
+
+	wantVP8X := webpChunk("VP8X", []byte{0x00, 0, 0, 0, 9, 0, 0, 9, 0, 0})
+	if want := webpFile(wantVP8X, bitstream); !bytes.Equal(stripped, want) {
+		t.Fatalf("stripped webp mismatch\n got: %x\nwant: %x", stripped, want)
+	}
+
+	if _, changed, err := imageproc.StripMetadata(stripped, "webp"); err != nil || changed {
+		t.Fatalf("clean webp should be unchanged (changed %v, err %v)", changed, err)
+	}
+}
+
+func TestStripMetadata_HEIFIsNotRemovable(t *testing.T) {
+	data := append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...)
+	if got := imageproc.DetectContainer(data); got != "heif" {
+		t.Fatalf("DetectContainer = %q, want heif", got)
+	}
+	if _, _, err := imageproc.StripMetadata(data, "heif"); err != imageproc.ErrMetadataNotRemovable {
+		t.Fatalf("err = %v, want ErrMetadataNotRemovable", err)
+	}
+}
+
+func TestBlurHash_SolidColor(t *testing.T) {
+	img := splitImage(32, 32, color.RGBA{255, 0, 0, 255}, color.RGBA{255, 0, 0, 255})
+
+	hash := imageproc.BlurHash(img, 4, 3)
+	// "L" = 4x3 components, DC ของสีแดงล้วน (0xFF0000) ในฐาน 83 = "TI:j"
+	if len(hash) != 28 || hash[0] != 'L' || hash[2:6] != "TI:j" {
+		t.Fatalf("unexpected blurhash %q", hash)
+	}
+}
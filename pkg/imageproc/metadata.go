@@ -0,0 +1,286 @@
+// pkg/imageproc/metadata.go
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// errMalformed โครงสร้างไฟล์ไม่ตรงตาม spec (ให้ Process ถอยไป encode ใหม่แทน)
+var errMalformed = errors.New("imageproc: malformed image structure")
+
+var (
+	exifHeader   = []byte("Exif\x00\x00")
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// JPEG markers ที่ใช้
+const (
+	markerSOI   = 0xD8
+	markerSOS   = 0xDA
+	markerAPP1  = 0xE1 // EXIF และ XMP (มีพิกัด GPS ได้ทั้งคู่)
+	markerAPP13 = 0xED // Photoshop IRB / IPTC (มีข้อมูลสถานที่ได้)
+)
+
+const exifTagOrientation = 0x0112
+
+// jpegSegment segment หนึ่งก่อนถึง SOS (start คือตำแหน่ง byte 0xFF ของ marker)
+type jpegSegment struct {
+	marker byte
+	start  int
+	end    int
+	data   []byte // payload หลัง length field
+}
+
+// jpegSegments แยก segment ของ JPEG ตั้งแต่หลัง SOI จนถึง SOS คืน offset ของ SOS
+func jpegSegments(data []byte) ([]jpegSegment, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, 0, errMalformed
+	}
+
+	var segments []jpegSegment
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, 0, errMalformed
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // fill byte
+			pos++
+			continue
+		}
+		if marker == markerSOS {
+			return segments, pos, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, errMalformed
+		}
+		segments = append(segments, jpegSegment{
+			marker: marker,
+			start:  pos,
+			end:    end,
+			data:   data[pos+4 : end],
+		})
+		pos = end
+	}
+	return nil, 0, errMalformed
+}
+
+// JPEGOrientation อ่านค่า EXIF orientation (1-8) คืน 1 ถ้าไม่มีหรืออ่านไม่ได้
+func JPEGOrientation(data []byte) int {
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return 1
+	}
+	for _, seg := range segments {
+		if seg.marker == markerAPP1 && bytes.HasPrefix(seg.data, exifHeader) {
+			return exifOrientation(seg.data[len(exifHeader):])
+		}
+	}
+	return 1
+}
+
+// exifOrientation อ่าน tag orientation จาก IFD0 ของ TIFF structure
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifTagOrientation {
+			continue
+		}
+		// type SHORT ค่าอยู่ใน 2 byte แรกของ value field
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 1
+		}
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+	return 1
+}
+
+// ErrMetadataNotRemovable ไฟล์อยู่ใน container ที่ตัด metadata ไม่ได้ (เช่น HEIC/AVIF)
+var ErrMetadataNotRemovable = errors.New("imageproc: cannot remove metadata from this format")
+
+// DetectContainer ระบุ container ของไฟล์ที่ stdlib decode ไม่ได้จาก magic bytes
+// webp (RIFF), heif (ISO BMFF: heic, heif, avif) หรือ "" ถ้าไม่รู้จัก
+func DetectContainer(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return "heif"
+	default:
+		return ""
+	}
+}
+
+// StripMetadata ตัด metadata ที่อาจระบุตัวตนหรือสถานที่ออกแบบ lossless โดยไม่ decode ภาพ
+// jpeg: ตัด APP1 (EXIF/XMP) และ APP13 (IPTC), png: ตัด eXIf และ text chunks, webp: ตัด EXIF และ XMP chunks
+// heif: คืน ErrMetadataNotRemovable (EXIF เป็น item ที่ถูกอ้างอิงจาก box อื่น ตัดทิ้งอย่างปลอดภัยไม่ได้)
+// changed = false เมื่อไม่มีอะไรต้องตัด (ใช้ไฟล์เดิมได้เลย)
+func StripMetadata(data []byte, format string) ([]byte, bool, error) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	case "webp":
+		return stripWebP(data)
+	case "heif":
+		return nil, false, ErrMetadataNotRemovable
+	default:
+		return data, false, nil
+	}
+}
+
+// stripJPEG ตัด APP1/APP13 ออก segment อื่น (JFIF, ICC profile, ตาราง quantization) และข้อมูลภาพคงเดิม
+func stripJPEG(data []byte) ([]byte, bool, error) {
+	segments, sos, err := jpegSegments(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, markerSOI)
+	changed := false
+	for _, seg := range segments {
+		if seg.marker == markerAPP1 || seg.marker == markerAPP13 {
+			changed = true
+			continue
+		}
+		out = append(out, data[seg.start:seg.end]...)
+	}
+	if !changed {
+		return data, false, nil
+	}
+	return append(out, data[sos:]...), true, nil
+}
+
+// pngMetadataChunks chunk ที่เก็บ EXIF หรือข้อความอิสระ (XMP อยู่ใน iTXt)
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+}
+
+// stripPNG ตัด metadata chunks ออก (แต่ละ chunk มี CRC ของตัวเอง จึงไม่ต้องคำนวณใหม่)
+func stripPNG(data []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, false, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	changed := false
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, false, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) || end < pos {
+			return nil, false, errMalformed
+		}
+
+		chunkType := string(data[pos+4 : pos+8])
+		if pngMetadataChunks[chunkType] {
+			changed = true
+		} else {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	if !changed {
+		return data, false, nil
+	}
+	return out, true, nil
+}
+
+// VP8X flags ที่บอกว่ามี EXIF / XMP chunk
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP ตัด EXIF และ XMP chunks ออก ล้าง flag ใน VP8X และคำนวณขนาด RIFF ใหม่
+func stripWebP(data []byte) ([]byte, bool, error) {
+	if DetectContainer(data) != "webp" {
+		return nil, false, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	changed := false
+	vp8x := -1
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, false, errMalformed
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // chunk ขนาดคี่มี padding 1 byte
+		if end > len(data) {
+			return nil, false, errMalformed
+		}
+
+		switch string(data[pos : pos+4]) {
+		case "EXIF", "XMP ":
+			changed = true
+		case "VP8X":
+			if size < 1 {
+				return nil, false, errMalformed
+			}
+			vp8x = len(out) + 8
+			out = append(out, data[pos:end]...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if !changed {
+		return data, false, nil
+	}
+
+	if vp8x >= 0 {
+		out[vp8x] &^= webpFlagEXIF | webpFlagXMP
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true, nil
+}
@@ -0,0 +1,188 @@
+// pkg/scheduler/disappearing_message_sweeper.go
+package scheduler
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/models"
+	"github.com/thizplus/gofiber-chat-api/domain/repository"
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// DisappearingMessageSweeper ลบข้อความที่หมดอายุตามการตั้งค่าข้อความหายไปอัตโนมัติของการสนทนา
+type DisappearingMessageSweeper struct {
+	messageRepo    repository.MessageRepository
+	messageService service.MessageService
+	storageService service.FileStorageService
+	interval       time.Duration
+	batchSize      int
+}
+
+// NewDisappearingMessageSweeper สร้าง sweeper ใหม่
+func NewDisappearingMessageSweeper(
+	messageRepo repository.MessageRepository,
+	messageService service.MessageService,
+	storageService service.FileStorageService,
+) *DisappearingMessageSweeper {
+	return &DisappearingMessageSweeper{
+		messageRepo:    messageRepo,
+		messageService: messageService,
+		storageService: storageService,
+		interval:       1 * time.Minute, // ทำงานทุก 1 นาที
+		batchSize:      200,             // จำนวนข้อความสูงสุดต่อรอบการดึง
+	}
+}
+
+// Start เริ่มการทำงานของ sweeper
+func (s *DisappearingMessageSweeper) Start(ctx context.Context) {
+	log.Println("Disappearing message sweeper started")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	// รันทันทีครั้งแรก
+	s.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Disappearing message sweeper stopped")
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep ลบข้อความที่หมดอายุทีละ batch จนหมด
+func (s *DisappearingMessageSweeper) sweep(ctx context.Context) {
+	deletedCount := 0
+	errorCount := 0
+
+	for ctx.Err() == nil {
+		messages, err := s.messageRepo.FindExpiredMessages(time.Now(), s.batchSize)
+		if err != nil {
+			log.Printf("Error finding expired messages: %v", err)
+			return
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		batchErrors := 0
+		for _, message := range messages {
+			// เก็บ URL ของไฟล์ไว้ก่อน เพราะ soft delete จะล้างข้อมูล media
+			mediaURLs := messageMediaURLs(message)
+
+			if err := s.messageService.DeleteExpiredMessage(message.ID); err != nil {
+				log.Printf("Error deleting expired message %s: %v", message.ID, err)
+				batchErrors++
+				continue
+			}
+			deletedCount++
+
+			s.cleanupMedia(mediaURLs)
+		}
+
+		errorCount += batchErrors
+		// ทุกข้อความใน batch ล้มเหลว รอบถัดไปจะได้ชุดเดิม หยุดไว้ก่อน
+		if batchErrors == len(messages) || len(messages) < s.batchSize {
+			break
+		}
+	}
+
+	if deletedCount > 0 || errorCount > 0 {
+		log.Printf("Disappearing message sweep completed: %d deleted, %d errors", deletedCount, errorCount)
+	}
+}
+
+// cleanupMedia ลบไฟล์ใน storage ที่ไม่มีข้อความอื่นอ้างอิงแล้ว (เช่น ไม่ได้ถูก forward ไปที่อื่น)
+func (s *DisappearingMessageSweeper) cleanupMedia(mediaURLs []string) {
+	if s.storageService == nil {
+		return
+	}
+
+	for _, mediaURL := range mediaURLs {
+		referenced, err := s.messageRepo.IsMediaURLReferenced(mediaURL)
+		if err != nil {
+			log.Printf("Error checking media references for %s: %v", mediaURL, err)
+			continue
+		}
+		if referenced {
+			continue
+		}
+
+		path, ok := storagePathFromURL(s.storageService, mediaURL)
+		if !ok {
+			// ไฟล์ไม่ได้อยู่ใน storage ของเรา (เช่น URL ภายนอกหรือ sticker)
+			continue
+		}
+
+		if err := s.storageService.DeleteFile(path); err != nil {
+			log.Printf("Error deleting file %s: %v", path, err)
+		}
+	}
+}
+
+// messageMediaURLs รวบรวม URL ของไฟล์ทั้งหมดในข้อความ (media, thumbnail, medium variant และไฟล์ในอัลบั้ม)
+func messageMediaURLs(message *models.Message) []string {
+	// sticker ใช้ไฟล์ร่วมกันทั้งระบบ ห้ามลบ
+	if message.MessageType == "sticker" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+
+	add(message.MediaURL)
+	add(message.MediaThumbnailURL)
+	if mediumURL, ok := message.Metadata["medium_url"].(string); ok {
+		add(mediumURL)
+	}
+
+	if files, ok := message.AlbumFiles.([]interface{}); ok {
+		for _, file := range files {
+			if fileMap, ok := file.(map[string]interface{}); ok {
+				if url, ok := fileMap["media_url"].(string); ok {
+					add(url)
+				}
+				if url, ok := fileMap["media_thumbnail_url"].(string); ok {
+					add(url)
+				}
+			}
+		}
+	}
+
+	return urls
+}
+
+// storagePathFromURL แปลง public URL กลับเป็น path ใน storage
+// ค่าที่ไม่มี scheme คือ path ที่บันทึกไว้ในโหมด private media อยู่แล้ว
+func storagePathFromURL(storageService service.FileStorageService, mediaURL string) (string, bool) {
+	if mediaURL != "" && !strings.Contains(mediaURL, "://") {
+		return mediaURL, true
+	}
+
+	prefix := storageService.GetPublicURL("")
+	if prefix == "" || !strings.HasPrefix(mediaURL, prefix) {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(mediaURL, prefix)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,57 @@
+// pkg/scheduler/poll_closer.go
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/thizplus/gofiber-chat-api/domain/service"
+)
+
+// PollCloser ปิดโพลที่ถึงเวลาปิด และบันทึกผลลัพธ์สุดท้าย
+type PollCloser struct {
+	pollService service.PollService
+	interval    time.Duration
+}
+
+// NewPollCloser สร้าง closer ใหม่
+func NewPollCloser(pollService service.PollService) *PollCloser {
+	return &PollCloser{
+		pollService: pollService,
+		interval:    30 * time.Second, // ทำงานทุก 30 วินาที
+	}
+}
+
+// Start เริ่มการทำงานของ closer
+func (c *PollCloser) Start(ctx context.Context) {
+	log.Println("Poll closer started")
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	// รันทันทีครั้งแรก
+	c.closeDue(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Poll closer stopped")
+			return
+		case <-ticker.C:
+			c.closeDue(ctx)
+		}
+	}
+}
+
+// closeDue ปิดโพลที่หมดเวลาแล้ว
+func (c *PollCloser) closeDue(ctx context.Context) {
+	closed, err := c.pollService.CloseExpiredPolls(ctx)
+	if err != nil {
+		log.Printf("Error closing expired polls: %v", err)
+		return
+	}
+	if closed > 0 {
+		log.Printf("Poll closer: closed %d polls", closed)
+	}
+}
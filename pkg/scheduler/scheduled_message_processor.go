@@ -71,7 +71,7 @@ func (p *ScheduledMessageProcessor) loadPendingMessages() {
 
 	// สร้าง timers สำหรับแต่ละ message
 	for _, msg := range messages {
-		p.timerManager.Schedule(msg.ID, msg.ScheduledAt)
+		p.timerManager.Schedule(msg.ID, msg.DueAt())
 	}
 
 	log.Printf("[ScheduledMessageProcessor] Loaded %d pending scheduled messages", len(messages))
@@ -80,6 +80,7 @@ func (p *ScheduledMessageProcessor) loadPendingMessages() {
 // processFallback ตรวจสอบ messages ที่ตกค้าง (safety net)
 func (p *ScheduledMessageProcessor) processFallback() {
 	// หา messages ที่ควรส่งแล้วแต่ยัง pending อยู่ (อาจเกิดจาก server restart)
+	// รวมถึง messages ที่ instance อื่น claim ไว้แล้ว crash (lease หมดอายุ)
 	messages, err := p.scheduledMessageService.GetPendingMessagesForProcessor(time.Now(), 100)
 	if err != nil {
 		log.Printf("[ScheduledMessageProcessor] Fallback error: %v", err)
@@ -95,7 +96,7 @@ func (p *ScheduledMessageProcessor) processFallback() {
 	for _, msg := range messages {
 		// ถ้ายังไม่มี timer ให้สร้างใหม่ (จะส่งทันทีเพราะเวลาผ่านไปแล้ว)
 		if !p.timerManager.Has(msg.ID) {
-			p.timerManager.Schedule(msg.ID, msg.ScheduledAt)
+			p.timerManager.Schedule(msg.ID, msg.DueAt())
 		}
 	}
 }
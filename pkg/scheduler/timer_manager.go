@@ -55,17 +55,18 @@ func (tm *TimerManager) Schedule(messageID uuid.UUID, scheduledAt time.Time) {
 	}
 
 	// สร้าง timer ใหม่
-	timer := time.AfterFunc(duration, func() {
+	// ลบ timer ออกก่อนเรียก callback เพราะ callback อาจ schedule ใหม่ (retry)
+	entry := &ScheduledTimer{
+		MessageID:   messageID,
+		ScheduledAt: scheduledAt,
+	}
+	entry.Timer = time.AfterFunc(duration, func() {
 		log.Printf("[TimerManager] Timer fired for message %s", messageID)
+		tm.remove(messageID, entry)
 		tm.callback(messageID)
-		tm.remove(messageID)
 	})
 
-	tm.timers[messageID] = &ScheduledTimer{
-		MessageID:   messageID,
-		ScheduledAt: scheduledAt,
-		Timer:       timer,
-	}
+	tm.timers[messageID] = entry
 
 	log.Printf("[TimerManager] Scheduled message %s for %s (in %v)", messageID, scheduledAt.Format(time.RFC3339), duration)
 }
@@ -85,10 +86,13 @@ func (tm *TimerManager) Cancel(messageID uuid.UUID) bool {
 }
 
 // remove ลบ timer ออกจาก map (internal use)
-func (tm *TimerManager) remove(messageID uuid.UUID) {
+// ลบเฉพาะเมื่อยังเป็น timer ตัวเดิม เพื่อไม่ให้ timer ที่ fire แล้วลบ timer ที่ schedule มาแทน
+func (tm *TimerManager) remove(messageID uuid.UUID, entry *ScheduledTimer) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
-	delete(tm.timers, messageID)
+	if tm.timers[messageID] == entry {
+		delete(tm.timers, messageID)
+	}
 }
 
 // Reschedule เปลี่ยนเวลา
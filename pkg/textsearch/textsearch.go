@@ -0,0 +1,296 @@
+// pkg/textsearch/textsearch.go
+package textsearch
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ภาษาไทยไม่มีช่องว่างระหว่างคำ parser ของ PostgreSQL จึงตัดคำไม่ได้
+// package นี้แปลงข้อความเป็น lexeme เอง:
+//   - ช่วงตัวอักษรไทย → character bigram พร้อมตำแหน่งต่อเนื่อง (ค้นด้วย phrase operator <->)
+//   - คำอื่นๆ (อังกฤษ ตัวเลข ฯลฯ) → คำตัวพิมพ์เล็กทั้งคำ (ค้นแบบ prefix)
+// ผลลัพธ์เป็น tsvector / tsquery literal ที่ cast ใน SQL ได้โดยไม่ผ่าน text parser
+
+// Weight ของ lexeme ใน tsvector
+const (
+	WeightA = 'A'
+	WeightB = 'B'
+	WeightC = 'C'
+	WeightD = 'D'
+)
+
+const (
+	maxPosition           = 16383 // ตำแหน่งสูงสุดที่ tsvector รองรับ
+	maxPositionsPerLexeme = 256   // จำนวนตำแหน่งสูงสุดต่อ lexeme
+	maxWordRunes          = 64    // คำที่ยาวกว่านี้จะถูกตัด
+	maxQuerySegments      = 16    // จำนวนส่วนสูงสุดของคำค้นหา
+)
+
+type segmentKind int
+
+const (
+	segmentWord segmentKind = iota
+	segmentThai
+)
+
+type segment struct {
+	kind  segmentKind
+	runes []rune
+}
+
+// Field ข้อความหนึ่งส่วนพร้อม weight สำหรับสร้าง document
+type Field struct {
+	Text   string
+	Weight byte
+}
+
+// Document สร้าง tsvector literal จากข้อความหลายส่วน
+// คืนค่า "" เมื่อไม่มี lexeme
+func Document(fields ...Field) string {
+	type entry struct {
+		lexeme    string
+		positions []string
+	}
+
+	var entries []*entry
+	index := make(map[string]*entry)
+	pos := 0
+
+	add := func(lexeme string, weight byte) {
+		if pos < maxPosition {
+			pos++
+		}
+		e, ok := index[lexeme]
+		if !ok {
+			e = &entry{lexeme: lexeme}
+			index[lexeme] = e
+			entries = append(entries, e)
+		}
+		if len(e.positions) >= maxPositionsPerLexeme {
+			return
+		}
+		p := strconv.Itoa(pos)
+		if weight != 0 && weight != WeightD {
+			p += string(weight)
+		}
+		e.positions = append(e.positions, p)
+	}
+
+	for _, field := range fields {
+		for _, seg := range segments(field.Text) {
+			for _, lexeme := range seg.lexemes() {
+				add(lexeme, field.Weight)
+			}
+		}
+		// เว้นตำแหน่งระหว่าง field เพื่อไม่ให้ phrase ข้าม field
+		if pos < maxPosition {
+			pos++
+		}
+	}
+
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(quote(e.lexeme))
+		b.WriteByte(':')
+		b.WriteString(strings.Join(e.positions, ","))
+	}
+	return b.String()
+}
+
+// Query สร้าง tsquery literal จากคำค้นหาของผู้ใช้
+// ทุกส่วนต้องตรงกัน (AND) คืนค่า "" เมื่อไม่มีคำที่ค้นหาได้
+func Query(text string) string {
+	segs := segments(text)
+	if len(segs) > maxQuerySegments {
+		segs = segs[:maxQuerySegments]
+	}
+
+	parts := make([]string, 0, len(segs))
+	for _, seg := range segs {
+		lexemes := seg.lexemes()
+		switch {
+		case len(lexemes) == 0:
+			continue
+		case seg.kind == segmentWord || len(seg.runes) == 1:
+			// คำเดียว / ตัวอักษรไทยตัวเดียว → prefix match
+			parts = append(parts, quote(lexemes[0])+":*")
+		default:
+			quoted := make([]string, len(lexemes))
+			for i, l := range lexemes {
+				quoted[i] = quote(l)
+			}
+			parts = append(parts, "("+strings.Join(quoted, " <-> ")+")")
+		}
+	}
+	return strings.Join(parts, " & ")
+}
+
+// Highlight ตัดข้อความรอบจุดที่ตรงกับคำค้นหาแรก และครอบคำที่ตรงด้วย <mark></mark>
+// ข้อความส่วนอื่นถูก escape เป็น HTML แล้ว maxRunes คือความยาวโดยประมาณของ snippet
+func Highlight(text, query string, maxRunes int) string {
+	if text == "" {
+		return ""
+	}
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// หาช่วงที่ตรงกับแต่ละส่วนของคำค้นหา
+	type span struct{ start, end int }
+	var spans []span
+	for _, seg := range segments(query) {
+		needle := seg.runes
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); {
+			if runesEqual(lower[i:i+len(needle)], needle) {
+				spans = append(spans, span{i, i + len(needle)})
+				i += len(needle)
+				continue
+			}
+			i++
+		}
+	}
+
+	if len(spans) == 0 {
+		return html.EscapeString(truncateRunes(runes, 0, maxRunes))
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := []span{spans[0]}
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	// กำหนดหน้าต่าง snippet รอบจุดที่ตรงแรก
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		start = merged[0].start - maxRunes/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for _, s := range merged {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		from, to := max(s.start, start), min(s.end, end)
+		b.WriteString(html.EscapeString(string(runes[cursor:from])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString("</mark>")
+		cursor = to
+	}
+	b.WriteString(html.EscapeString(string(runes[cursor:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// lexemes แปลง segment เป็นรายการ lexeme ตามลำดับ
+func (s segment) lexemes() []string {
+	if len(s.runes) == 0 {
+		return nil
+	}
+	if s.kind == segmentWord || len(s.runes) == 1 {
+		return []string{string(s.runes)}
+	}
+	out := make([]string, 0, len(s.runes)-1)
+	for i := 0; i+1 < len(s.runes); i++ {
+		out = append(out, string(s.runes[i:i+2]))
+	}
+	return out
+}
+
+// segments แยกข้อความเป็นช่วงภาษาไทยและคำทั่วไป (ตัวพิมพ์เล็ก)
+func segments(text string) []segment {
+	var out []segment
+	var current []rune
+	kind := segmentWord
+
+	flush := func() {
+		if len(current) > 0 {
+			if kind == segmentWord && len(current) > maxWordRunes {
+				current = current[:maxWordRunes]
+			}
+			out = append(out, segment{kind: kind, runes: current})
+			current = nil
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Thai, r):
+			if kind != segmentThai {
+				flush()
+				kind = segmentThai
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || (len(current) > 0 && unicode.Is(unicode.Mn, r)):
+			if kind != segmentWord {
+				flush()
+				kind = segmentWord
+			}
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// quote ครอบ lexeme ด้วย single quote ตามรูปแบบ tsvector/tsquery literal
+func quote(lexeme string) string {
+	lexeme = strings.ReplaceAll(lexeme, `\`, `\\`)
+	lexeme = strings.ReplaceAll(lexeme, `'`, `''`)
+	return "'" + lexeme + "'"
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func truncateRunes(runes []rune, start, maxRunes int) string {
+	if maxRunes <= 0 || len(runes)-start <= maxRunes {
+		return string(runes[start:])
+	}
+	return string(runes[start:start+maxRunes]) + "…"
+}
@@ -0,0 +1,153 @@
+// pkg/textsearch/textsearch_test.go
+package textsearch
+
+import (
+	"testing"
+)
+
+func TestSegments_ThaiAndLatin(t *testing.T) {
+	segs := segments("ราคา100บาท Hello, World!")
+
+	want := []struct {
+		kind segmentKind
+		text string
+	}{
+		{segmentThai, "ราคา"},
+		{segmentWord, "100"},
+		{segmentThai, "บาท"},
+		{segmentWord, "hello"},
+		{segmentWord, "world"},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments, want %d", len(segs), len(want))
+	}
+	for i, w := range want {
+		if segs[i].kind != w.kind || string(segs[i].runes) != w.text {
+			t.Errorf("segment %d = (%d, %q), want (%d, %q)", i, segs[i].kind, string(segs[i].runes), w.kind, w.text)
+		}
+	}
+}
+
+func TestSegments_TruncatesLongWords(t *testing.T) {
+	long := make([]rune, maxWordRunes+10)
+	for i := range long {
+		long[i] = 'a'
+	}
+
+	segs := segments(string(long))
+	if len(segs) != 1 || len(segs[0].runes) != maxWordRunes {
+		t.Fatalf("expected one segment of %d runes, got %+v", maxWordRunes, segs)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		want   string
+	}{
+		{
+			name:   "latin word and thai bigrams share positions",
+			fields: []Field{{Text: "Hello สวัสดี", Weight: WeightA}},
+			want:   "'hello':1A 'สว':2A 'วั':3A 'ัส':4A 'สด':5A 'ดี':6A",
+		},
+		{
+			name:   "gap between fields",
+			fields: []Field{{Text: "a", Weight: WeightA}, {Text: "b", Weight: WeightB}},
+			want:   "'a':1A 'b':3B",
+		},
+		{
+			name:   "weight D has no suffix and repeats collect positions",
+			fields: []Field{{Text: "x X", Weight: WeightD}},
+			want:   "'x':1,2",
+		},
+		{
+			name:   "single thai character",
+			fields: []Field{{Text: "ก"}},
+			want:   "'ก':1",
+		},
+		{
+			name:   "no lexemes",
+			fields: []Field{{Text: " ... !!"}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Document(tt.fields...); got != tt.want {
+				t.Errorf("Document() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"latin prefix", "Hel", "'hel':*"},
+		{"thai phrase", "สวัสดี", "('สว' <-> 'วั' <-> 'ัส' <-> 'สด' <-> 'ดี')"},
+		{"mixed terms are ANDed", "Hello สวัสดี", "'hello':* & ('สว' <-> 'วั' <-> 'ัส' <-> 'สด' <-> 'ดี')"},
+		{"single thai character is a prefix", "ก", "'ก':*"},
+		{"punctuation splits words", "it's", "'it':* & 's':*"},
+		{"nothing searchable", "  !! ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Query(tt.text); got != tt.want {
+				t.Errorf("Query(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_LimitsSegments(t *testing.T) {
+	text := ""
+	for i := 0; i < maxQuerySegments+5; i++ {
+		text += "w "
+	}
+
+	got := Query(text)
+	want := "'w':*"
+	for i := 1; i < maxQuerySegments; i++ {
+		want += " & 'w':*"
+	}
+	if got != want {
+		t.Errorf("Query() = %q, want %q", got, want)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote(`a'b\`); got != `'a''b\\'` {
+		t.Errorf("quote() = %s", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		query    string
+		maxRunes int
+		want     string
+	}{
+		{"latin case-insensitive", "Hello World", "world", 0, "Hello <mark>World</mark>"},
+		{"thai substring", "ไปกินข้าวกัน", "ข้าว", 0, "ไปกิน<mark>ข้าว</mark>กัน"},
+		{"escapes html", "<b>hi</b>", "hi", 0, "&lt;b&gt;<mark>hi</mark>&lt;/b&gt;"},
+		{"no match truncates", "abcdefghij", "zzz", 4, "abcd…"},
+		{"window around match", "aaaaaaaaaa target bbbbbbbbbb", "target", 12, "…aa <mark>target</mark> bb…"},
+		{"empty text", "", "x", 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.query, tt.maxRunes); got != tt.want {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}